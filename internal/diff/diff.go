@@ -0,0 +1,345 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package diff computes and prints differences between Kubernetes manifests and
+other texts of a release.
+
+Manifests are compared resource by resource, matching resources by their
+group, kind, namespace and name, so that reordering templates does not show up
+as a change. The values of Secrets are redacted.
+*/
+package diff
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// Change describes how a resource changed between two manifests.
+type Change string
+
+const (
+	// Added means the resource only exists in the new manifest
+	Added Change = "added"
+	// Removed means the resource only exists in the old manifest
+	Removed Change = "removed"
+	// Modified means the resource exists in both manifests with different content
+	Modified Change = "modified"
+)
+
+// ResourceDiff is the difference of a single resource between two manifests.
+type ResourceDiff struct {
+	// Key identifies the resource, e.g. "default, web, Deployment (apps)"
+	Key    string `json:"key"`
+	Change Change `json:"change"`
+	// Old is the resource in the old manifest, empty if it was added
+	Old string `json:"old,omitempty"`
+	// New is the resource in the new manifest, empty if it was removed
+	New string `json:"new,omitempty"`
+	// Secret is true if the resource is a Secret, whose values are redacted
+	Secret bool `json:"secret,omitempty"`
+}
+
+// Options control how differences are printed.
+type Options struct {
+	// Context is the number of unchanged lines shown around each change
+	Context int
+	// NoColor disables colorized output
+	NoColor bool
+	// HideSecrets leaves out Secrets, as their content is hidden from the
+	// manifests rendered with --hide-secret
+	HideSecrets bool
+}
+
+// DefaultContext is the number of unchanged lines shown around each change
+// when no other value is requested.
+const DefaultContext = 3
+
+// Manifests compares two multi-document manifests and returns the resources
+// that were added, removed or modified, ordered by key. Unchanged resources
+// are not returned. The values of the data and stringData of Secrets are
+// redacted, showing only which keys were added, removed or changed.
+func Manifests(oldManifest, newManifest string) []ResourceDiff {
+	oldResources := parse(oldManifest)
+	newResources := parse(newManifest)
+
+	var diffs []ResourceDiff
+	for key, o := range oldResources {
+		n, ok := newResources[key]
+		switch {
+		case !ok:
+			diffs = append(diffs, ResourceDiff{Key: key, Change: Removed, Old: o.doc, Secret: o.secret})
+		case o.doc != n.doc:
+			diffs = append(diffs, ResourceDiff{Key: key, Change: Modified, Old: o.doc, New: n.doc, Secret: o.secret || n.secret})
+		}
+	}
+	for key, n := range newResources {
+		if _, ok := oldResources[key]; !ok {
+			diffs = append(diffs, ResourceDiff{Key: key, Change: Added, New: n.doc, Secret: n.secret})
+		}
+	}
+	for i, d := range diffs {
+		if d.Secret {
+			diffs[i].Old, diffs[i].New = redactSecret(d.Old, d.New)
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+	return diffs
+}
+
+// Write prints the differences as unified diffs, one per resource.
+func Write(out io.Writer, diffs []ResourceDiff, opts Options) error {
+	p := newPrinter(out, opts)
+	for _, d := range diffs {
+		if d.Secret && opts.HideSecrets {
+			continue
+		}
+		if err := p.unified(d.Key+" has been "+string(d.Change), d.Old, d.New); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Text prints a unified diff of two versions of a text, such as the values or
+// the notes of a release, headed by the title. Nothing is printed if the
+// versions are equal.
+func Text(out io.Writer, title, oldText, newText string, opts Options) error {
+	oldText = strings.TrimSuffix(oldText, "\n")
+	newText = strings.TrimSuffix(newText, "\n")
+	if oldText == newText {
+		return nil
+	}
+	return newPrinter(out, opts).unified(title, oldText, newText)
+}
+
+// printer prints unified diffs, colorizing their lines.
+type printer struct {
+	out     io.Writer
+	context int
+	header  *color.Color
+	added   *color.Color
+	removed *color.Color
+	hunk    *color.Color
+}
+
+func newPrinter(out io.Writer, opts Options) *printer {
+	p := &printer{
+		out:     out,
+		context: opts.Context,
+		header:  color.New(color.FgYellow),
+		added:   color.New(color.FgGreen),
+		removed: color.New(color.FgRed),
+		hunk:    color.New(color.FgCyan),
+	}
+	if p.context < 0 {
+		p.context = DefaultContext
+	}
+	if opts.NoColor {
+		for _, c := range []*color.Color{p.header, p.added, p.removed, p.hunk} {
+			c.DisableColor()
+		}
+	}
+	return p
+}
+
+// unified prints the title followed by the unified diff of two texts.
+func (p *printer) unified(title, oldText, newText string) error {
+	if _, err := p.header.Fprintln(p.out, title+":"); err != nil {
+		return err
+	}
+	text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:       splitLines(oldText),
+		B:       splitLines(newText),
+		Context: p.context,
+	})
+	if err != nil {
+		return err
+	}
+	for line := range strings.SplitSeq(strings.TrimSuffix(text, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			_, err = p.hunk.Fprintln(p.out, line)
+		case strings.HasPrefix(line, "+"):
+			_, err = p.added.Fprintln(p.out, line)
+		case strings.HasPrefix(line, "-"):
+			_, err = p.removed.Fprintln(p.out, line)
+		default:
+			_, err = fmt.Fprintln(p.out, line)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitLines splits a resource into lines for difflib, which expects every
+// line to end with a newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return difflib.SplitLines(s)
+}
+
+type resourceID struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+}
+
+// isSecret returns whether the resource is a core Secret.
+func (id resourceID) isSecret() bool {
+	return id.APIVersion == "v1" && id.Kind == "Secret"
+}
+
+// parsedResource is a resource of a manifest.
+type parsedResource struct {
+	doc    string
+	secret bool
+}
+
+// parse splits a manifest into its resources, keyed by resource identity.
+// Documents that cannot be identified are keyed by their position. Documents
+// holding only comments, such as Secrets hidden with --hide-secret, are left
+// out.
+func parse(manifest string) map[string]parsedResource {
+	docs := releaseutil.SplitManifests(manifest)
+	names := make([]string, 0, len(docs))
+	for name := range docs {
+		names = append(names, name)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(names))
+
+	resources := make(map[string]parsedResource, len(docs))
+	for i, name := range names {
+		doc := strings.TrimSpace(docs[name])
+		if doc == "" {
+			continue
+		}
+		var content any
+		if err := yaml.Unmarshal([]byte(doc), &content); err == nil && content == nil {
+			continue
+		}
+		var id resourceID
+		key := fmt.Sprintf("document %d", i)
+		if err := yaml.Unmarshal([]byte(doc), &id); err == nil && id.Kind != "" {
+			key = resourceKey(id)
+		}
+		resources[key] = parsedResource{doc: doc, secret: id.isSecret()}
+	}
+	return resources
+}
+
+const (
+	// redacted replaces the values of Secrets
+	redacted = "<redacted>"
+	// redactedChanged replaces the values of Secrets that changed
+	redactedChanged = "<redacted, changed>"
+)
+
+// redactSecret redacts the values of the data and stringData of the old and
+// new versions of a Secret, either of which may be empty. Values that changed
+// are marked as such in the new version, so that the diff still shows them.
+func redactSecret(oldDoc, newDoc string) (string, string) {
+	oldSecret, oldOK := unmarshalSecret(oldDoc)
+	newSecret, newOK := unmarshalSecret(newDoc)
+	if !oldOK || !newOK {
+		return redactAll(oldDoc), redactAll(newDoc)
+	}
+	for _, field := range []string{"data", "stringData"} {
+		oldValues, _ := oldSecret[field].(map[string]any)
+		newValues, _ := newSecret[field].(map[string]any)
+		for k, v := range newValues {
+			if old, ok := oldValues[k]; ok && !reflect.DeepEqual(old, v) {
+				newValues[k] = redactedChanged
+			} else {
+				newValues[k] = redacted
+			}
+		}
+		for k := range oldValues {
+			oldValues[k] = redacted
+		}
+	}
+	return marshalSecret(oldDoc, oldSecret), marshalSecret(newDoc, newSecret)
+}
+
+// unmarshalSecret unmarshals a Secret. An empty document is a nil Secret.
+func unmarshalSecret(doc string) (map[string]any, bool) {
+	if doc == "" {
+		return nil, true
+	}
+	var secret map[string]any
+	if err := yaml.Unmarshal([]byte(doc), &secret); err != nil {
+		return nil, false
+	}
+	return secret, true
+}
+
+// marshalSecret marshals a redacted Secret, keeping the comments heading the
+// original document, such as its source template.
+func marshalSecret(doc string, secret map[string]any) string {
+	if secret == nil {
+		return ""
+	}
+	data, err := yaml.Marshal(secret)
+	if err != nil {
+		return redactAll(doc)
+	}
+	var b strings.Builder
+	for line := range strings.SplitSeq(doc, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		b.WriteString(line + "\n")
+	}
+	b.Write(data)
+	return strings.TrimSpace(b.String())
+}
+
+// redactAll replaces a Secret that cannot be parsed.
+func redactAll(doc string) string {
+	if doc == "" {
+		return ""
+	}
+	return "# The Secret output has been redacted"
+}
+
+func resourceKey(id resourceID) string {
+	kind := id.Kind
+	if group, _, ok := strings.Cut(id.APIVersion, "/"); ok {
+		kind = fmt.Sprintf("%s (%s)", id.Kind, group)
+	}
+	ns := id.Metadata.Namespace
+	if ns == "" {
+		ns = "-"
+	}
+	return fmt.Sprintf("%s, %s, %s", ns, id.Metadata.Name, kind)
+}
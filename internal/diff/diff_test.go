@@ -0,0 +1,221 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	configMap = `---
+# Source: chart/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+`
+	configMapChanged = `---
+# Source: chart/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: changed
+`
+	deployment = `---
+# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+`
+)
+
+const (
+	secret = `---
+# Source: chart/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+data:
+  password: aHVudGVyMg==
+  user: YWRtaW4=
+`
+	secretChanged = `---
+# Source: chart/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: creds
+data:
+  password: c3dvcmRmaXNo
+  user: YWRtaW4=
+stringData:
+  token: abc
+`
+	hiddenSecret = `---
+# Source: chart/templates/secret.yaml
+# HIDDEN: The Secret output has been suppressed
+`
+)
+
+func TestManifestsRedactsSecrets(t *testing.T) {
+	diffs := Manifests(secret, secretChanged)
+	require.Len(t, diffs, 1)
+	assert.True(t, diffs[0].Secret)
+	assert.Equal(t, "# Source: chart/templates/secret.yaml\napiVersion: v1\ndata:\n  password: <redacted>\n  user: <redacted>\nkind: Secret\nmetadata:\n  name: creds", diffs[0].Old)
+	assert.Equal(t, "# Source: chart/templates/secret.yaml\napiVersion: v1\ndata:\n  password: <redacted, changed>\n  user: <redacted>\nkind: Secret\nmetadata:\n  name: creds\nstringData:\n  token: <redacted>", diffs[0].New)
+
+	added := Manifests("", secret)
+	require.Len(t, added, 1)
+	assert.Equal(t, Added, added[0].Change)
+	assert.NotContains(t, added[0].New, "aHVudGVyMg==")
+}
+
+func TestWriteHideSecrets(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, Manifests(secret+configMap, hiddenSecret+configMapChanged), Options{NoColor: true, HideSecrets: true}))
+	assert.Equal(t, `-, config, ConfigMap has been modified:
+@@ -7 +7 @@
+-  key: value
++  key: changed
+`, out.String())
+}
+
+func TestManifests(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		expect   []ResourceDiff
+	}{
+		{
+			name: "unchanged",
+			old:  configMap + deployment,
+			new:  deployment + configMap,
+		},
+		{
+			name: "added",
+			old:  configMap,
+			new:  configMap + deployment,
+			expect: []ResourceDiff{
+				{Key: "prod, web, Deployment (apps)", Change: Added, New: "# Source: chart/templates/deployment.yaml\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: prod"},
+			},
+		},
+		{
+			name: "removed",
+			old:  configMap + deployment,
+			new:  configMap,
+			expect: []ResourceDiff{
+				{Key: "prod, web, Deployment (apps)", Change: Removed, Old: "# Source: chart/templates/deployment.yaml\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: prod"},
+			},
+		},
+		{
+			name: "modified",
+			old:  configMap,
+			new:  configMapChanged,
+			expect: []ResourceDiff{
+				{
+					Key:    "-, config, ConfigMap",
+					Change: Modified,
+					Old:    "# Source: chart/templates/cm.yaml\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\ndata:\n  key: value",
+					New:    "# Source: chart/templates/cm.yaml\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\ndata:\n  key: changed",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, Manifests(tt.old, tt.new))
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	// Color detection is disabled when not writing to a terminal
+	noColor := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	tests := []struct {
+		name   string
+		opts   Options
+		expect string
+	}{
+		{
+			name: "no context",
+			opts: Options{NoColor: true},
+			expect: `-, config, ConfigMap has been modified:
+@@ -7 +7 @@
+-  key: value
++  key: changed
+`,
+		},
+		{
+			name: "default context",
+			opts: Options{Context: -1, NoColor: true},
+			expect: `-, config, ConfigMap has been modified:
+@@ -4,4 +4,4 @@
+ metadata:
+   name: config
+ data:
+-  key: value
++  key: changed
+`,
+		},
+		{
+			name: "color",
+			opts: Options{},
+			expect: "\x1b[33m-, config, ConfigMap has been modified:\x1b[0m\n" +
+				"\x1b[36m@@ -7 +7 @@\x1b[0m\n" +
+				"\x1b[31m-  key: value\x1b[0m\n" +
+				"\x1b[32m+  key: changed\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, Write(&out, Manifests(configMap, configMapChanged), tt.opts))
+			assert.Equal(t, tt.expect, out.String())
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Text(&out, "Values have been modified", "image: nginx\n", "image: nginx\n", Options{NoColor: true}))
+	assert.Empty(t, out.String())
+
+	require.NoError(t, Text(&out, "Values have been modified", "image: nginx\nreplicas: 1\n", "image: nginx\nreplicas: 3\n", Options{Context: -1, NoColor: true}))
+	assert.Equal(t, `Values have been modified:
+@@ -1,2 +1,2 @@
+ image: nginx
+-replicas: 1
++replicas: 3
+`, out.String())
+}
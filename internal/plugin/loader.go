@@ -22,6 +22,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 
 	extism "github.com/extism/go-sdk"
 	"github.com/tetratelabs/wazero"
@@ -156,6 +157,30 @@ func LoadDir(dirname string) (Plugin, error) {
 	return pm.CreatePlugin(dirname, m)
 }
 
+// LoadWasmFile loads a standalone Wasm module as a plugin of the given type,
+// run by the extism/v1 runtime.
+func LoadWasmFile(wasmFile string, pluginType string) (Plugin, error) {
+	if _, ok := pluginTypesIndex[pluginType]; !ok {
+		return nil, fmt.Errorf("unknown plugin type %q", pluginType)
+	}
+	m := &Metadata{
+		APIVersion: "v1",
+		Name:       strings.TrimSuffix(filepath.Base(wasmFile), filepath.Ext(wasmFile)),
+		Type:       pluginType,
+		Runtime:    "extism/v1",
+	}
+
+	pm, err := newPrototypePluginManager()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create plugin manager: %w", err)
+	}
+	rt, ok := pm.runtimes[m.Runtime].(*RuntimeExtismV1)
+	if !ok {
+		return nil, fmt.Errorf("unsupported plugin runtime type: %q", m.Runtime)
+	}
+	return rt.CreatePluginFromWasmFile(wasmFile, m)
+}
+
 func LogIgnorePluginLoadErrorFilterFunc(pluginYAML string, err error) error {
 	slog.Warn("failed to load plugin (ignoring)", slog.String("plugin_yaml", pluginYAML), slog.Any("error", err))
 	return nil
@@ -39,7 +39,7 @@ type Metadata struct {
 	// Name is the name of the plugin
 	Name string
 
-	// Type of plugin (eg, cli/v1, getter/v1, postrenderer/v1)
+	// Type of plugin (eg, cli/v1, getter/v1, postrenderer/v1, storage/v1)
 	Type string
 
 	// Runtime specifies the runtime type (subprocess, wasm)
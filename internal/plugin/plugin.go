@@ -49,6 +49,12 @@ type PluginHook interface { //nolint:revive
 	InvokeHook(event string) error
 }
 
+// PluginServer allows plugins to run as a long-lived process, serving the messages written to the returned connection
+// instead of being invoked once per message (e.g. storage plugins)
+type PluginServer interface { //nolint:revive
+	Start(ctx context.Context) (io.ReadWriteCloser, error)
+}
+
 // Input defines the input message and parameters to be passed to the plugin
 type Input struct {
 	// Message represents the type-elided value to be passed to the plugin.
@@ -115,14 +115,33 @@ func (r *RuntimeExtismV1) CreatePlugin(pluginDir string, metadata *Metadata) (Pl
 	return &ExtismV1PluginRuntime{
 		metadata: *metadata,
 		dir:      pluginDir,
+		wasmFile: wasmFile,
 		rc:       rc,
 		r:        r,
 	}, nil
 }
 
+// CreatePluginFromWasmFile creates a plugin from a standalone Wasm module,
+// which is not part of a plugin directory. The plugin has no access to the
+// network nor to the host filesystem.
+func (r *RuntimeExtismV1) CreatePluginFromWasmFile(wasmFile string, metadata *Metadata) (Plugin, error) {
+	if _, err := os.Stat(wasmFile); err != nil {
+		return nil, fmt.Errorf("failed to stat wasm module %q: %w", wasmFile, err)
+	}
+
+	return &ExtismV1PluginRuntime{
+		metadata: *metadata,
+		dir:      filepath.Dir(wasmFile),
+		wasmFile: wasmFile,
+		rc:       &RuntimeConfigExtismV1{},
+		r:        r,
+	}, nil
+}
+
 type ExtismV1PluginRuntime struct {
 	metadata Metadata
 	dir      string
+	wasmFile string
 	rc       *RuntimeConfigExtismV1
 	r        *RuntimeExtismV1
 }
@@ -154,7 +173,7 @@ func (p *ExtismV1PluginRuntime) Invoke(ctx context.Context, input *Input) (*Outp
 		tmpDir = tmpDirInner
 	}
 
-	manifest, err := buildManifest(p.dir, tmpDir, p.rc)
+	manifest, err := buildManifest(p.wasmFile, tmpDir, p.rc)
 	if err != nil {
 		return nil, err
 	}
@@ -212,9 +231,7 @@ func (p *ExtismV1PluginRuntime) Invoke(ctx context.Context, input *Input) (*Outp
 	return output, nil
 }
 
-func buildManifest(pluginDir string, tmpDir string, rc *RuntimeConfigExtismV1) (extism.Manifest, error) {
-	wasmFile := filepath.Join(pluginDir, ExtismV1WasmBinaryFilename)
-
+func buildManifest(wasmFile string, tmpDir string, rc *RuntimeConfigExtismV1) (extism.Manifest, error) {
 	allowedHosts := rc.AllowedHosts
 	if allowedHosts == nil {
 		allowedHosts = []string{}
@@ -118,7 +118,7 @@ func TestBuildManifest(t *testing.T) {
 		Timeout:      5000,
 	}
 
-	manifest, err := buildManifest("/path/to/plugin", "/tmp/foo", rc)
+	manifest, err := buildManifest("/path/to/plugin/plugin.wasm", "/tmp/foo", rc)
 	require.NoError(t, err)
 	assert.Equal(t, expected, manifest)
 }
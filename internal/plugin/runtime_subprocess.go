@@ -87,6 +87,7 @@ type SubprocessPluginRuntime struct {
 }
 
 var _ Plugin = (*SubprocessPluginRuntime)(nil)
+var _ PluginServer = (*SubprocessPluginRuntime)(nil)
 
 func (r *SubprocessPluginRuntime) Dir() string {
 	return r.pluginDir
@@ -277,3 +278,68 @@ func (r *SubprocessPluginRuntime) runPostrenderer(ctx context.Context, input *In
 		},
 	}, nil
 }
+
+// Start starts the plugin command as a long-lived process. The returned
+// connection writes to the stdin of the plugin and reads from its stdout.
+// Closing the connection closes the stdin of the plugin and waits for it to
+// exit.
+func (r *SubprocessPluginRuntime) Start(ctx context.Context) (io.ReadWriteCloser, error) {
+	env := ParseEnv(os.Environ())
+	maps.Insert(env, maps.All(r.EnvVars))
+	env["HELM_PLUGIN_NAME"] = r.metadata.Name
+	env["HELM_PLUGIN_DIR"] = r.pluginDir
+
+	command, args, err := PrepareCommands(r.RuntimeConfig.PlatformCommand, true, []string{}, env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare plugin command: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = FormatEnv(env)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("starting plugin command", slog.String("pluginName", r.metadata.Name), slog.String("command", cmd.String()))
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin %q: %w", r.metadata.Name, err)
+	}
+	return &subprocessConn{
+		Reader:     stdout,
+		Writer:     stdin,
+		stdin:      stdin,
+		cmd:        cmd,
+		pluginName: r.metadata.Name,
+	}, nil
+}
+
+// subprocessConn is the connection to a plugin started by Start
+type subprocessConn struct {
+	io.Reader
+	io.Writer
+	stdin      io.Closer
+	cmd        *exec.Cmd
+	pluginName string
+}
+
+func (c *subprocessConn) Close() error {
+	if err := c.stdin.Close(); err != nil {
+		return err
+	}
+	if err := c.cmd.Wait(); err != nil {
+		if eerr, ok := err.(*exec.ExitError); ok {
+			return &InvokeExecError{
+				Err:      fmt.Errorf("plugin %q exited with error", c.pluginName),
+				ExitCode: eerr.ExitCode(),
+			}
+		}
+		return err
+	}
+	return nil
+}
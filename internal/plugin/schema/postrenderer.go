@@ -18,6 +18,7 @@ package schema
 
 import (
 	"bytes"
+	"encoding/json"
 )
 
 // InputMessagePostRendererV1 implements Input.Message
@@ -31,6 +32,48 @@ type OutputMessagePostRendererV1 struct {
 	Manifests *bytes.Buffer `json:"manifests"`
 }
 
+// postRendererV1JSON is the JSON form of the post-renderer messages, as passed
+// to Wasm plugins. A bytes.Buffer has no exported fields, so the manifests are
+// encoded as a string.
+type postRendererV1JSON struct {
+	Manifests string   `json:"manifests"`
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+}
+
+func (m InputMessagePostRendererV1) MarshalJSON() ([]byte, error) {
+	return json.Marshal(postRendererV1JSON{Manifests: bufferString(m.Manifests), ExtraArgs: m.ExtraArgs})
+}
+
+func (m *InputMessagePostRendererV1) UnmarshalJSON(data []byte) error {
+	var v postRendererV1JSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	m.Manifests = bytes.NewBufferString(v.Manifests)
+	m.ExtraArgs = v.ExtraArgs
+	return nil
+}
+
+func (m OutputMessagePostRendererV1) MarshalJSON() ([]byte, error) {
+	return json.Marshal(postRendererV1JSON{Manifests: bufferString(m.Manifests)})
+}
+
+func (m *OutputMessagePostRendererV1) UnmarshalJSON(data []byte) error {
+	var v postRendererV1JSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	m.Manifests = bytes.NewBufferString(v.Manifests)
+	return nil
+}
+
+func bufferString(b *bytes.Buffer) string {
+	if b == nil {
+		return ""
+	}
+	return b.String()
+}
+
 type ConfigPostRendererV1 struct{}
 
 func (c *ConfigPostRendererV1) Validate() error {
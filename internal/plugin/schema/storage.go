@@ -0,0 +1,34 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	storageplugin "helm.sh/helm/v4/pkg/storage/driver/plugin"
+)
+
+// InputMessageStorageV1 implements Input.Message. Storage plugins run as a
+// long-lived process serving the protocol of the
+// helm.sh/helm/v4/pkg/storage/driver/plugin package.
+type InputMessageStorageV1 = storageplugin.Request
+
+type OutputMessageStorageV1 = storageplugin.Response
+
+type ConfigStorageV1 struct{}
+
+func (c *ConfigStorageV1) Validate() error {
+	return nil
+}
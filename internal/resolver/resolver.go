@@ -23,6 +23,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -206,6 +207,70 @@ func (r *Resolver) Resolve(reqs []*chart.Dependency, repoNames map[string]string
 	}, nil
 }
 
+// Versions returns the versions available for a dependency, newest first.
+//
+// Charts in the charts/ directory have no other versions, so no versions are
+// returned for them. A chart referenced with "file://" only has the version
+// found on disk.
+func (r *Resolver) Versions(d *chart.Dependency, repoName string) ([]*semver.Version, error) {
+	if d.Repository == "" {
+		return nil, nil
+	}
+
+	var versions []string
+	switch {
+	case strings.HasPrefix(d.Repository, "file://"):
+		chartpath, err := GetLocalPath(d.Repository, r.chartpath)
+		if err != nil {
+			return nil, err
+		}
+		ch, err := loader.LoadDir(chartpath)
+		if err != nil {
+			return nil, err
+		}
+		versions = []string{ch.Metadata.Version}
+	case registry.IsOCI(d.Repository):
+		if r.registryClient == nil {
+			return nil, fmt.Errorf("unable to list the versions of %s, missing registry client", d.Name)
+		}
+		ref := fmt.Sprintf("%s/%s", strings.TrimPrefix(d.Repository, registry.OCIScheme+"://"), d.Name)
+		tags, err := r.registryClient.Tags(ref)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve list of tags for repository %s: %w", d.Repository, err)
+		}
+		versions = tags
+	default:
+		if repoName == "" {
+			return nil, fmt.Errorf("no repository found for %s", d.Repository)
+		}
+		repoIndex, err := repo.LoadIndexFile(filepath.Join(r.cachepath, helmpath.CacheIndexFile(repoName)))
+		if err != nil {
+			return nil, fmt.Errorf("no cached repository for %s found. (try 'helm repo update'): %w", repoName, err)
+		}
+		vs, ok := repoIndex.Entries[d.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s chart not found in repo %s", d.Name, d.Repository)
+		}
+		for _, ver := range vs {
+			if len(ver.URLs) > 0 {
+				versions = append(versions, ver.Version)
+			}
+		}
+	}
+
+	result := make([]*semver.Version, 0, len(versions))
+	for _, ver := range versions {
+		v, err := semver.NewVersion(ver)
+		if err != nil {
+			// Not a legit entry.
+			continue
+		}
+		result = append(result, v)
+	}
+	sort.Sort(sort.Reverse(semver.Collection(result)))
+	return result, nil
+}
+
 // HashReq generates a hash of the dependencies.
 //
 // This should be used only to compare against another hash generated by this
@@ -174,6 +174,29 @@ func TestResolve(t *testing.T) {
 	}
 }
 
+func TestVersions(t *testing.T) {
+	registryClient, _ := registry.NewClient()
+	r := New("testdata/chartpath", "testdata/repository", registryClient)
+
+	versions, err := r.Versions(&chart.Dependency{Name: "alpine", Repository: "http://example.com", Version: "^0.1.0"}, "kubernetes-charts")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "0.2.0", versions[0].Original())
+	assert.Equal(t, "0.1.0", versions[1].Original())
+
+	versions, err = r.Versions(&chart.Dependency{Name: "base", Repository: "file://base", Version: "0.1.0"}, "")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, "0.1.0", versions[0].Original())
+
+	versions, err = r.Versions(&chart.Dependency{Name: "localdependency", Version: "0.1.0"}, "")
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+
+	_, err = r.Versions(&chart.Dependency{Name: "redis", Repository: "http://example.com", Version: "1.0.0"}, "kubernetes-charts")
+	assert.Error(t, err)
+}
+
 func TestHashReq(t *testing.T) {
 	expect := "sha256:fb239e836325c5fa14b29d1540a13b7d3ba13151b67fe719f820e0ef6d66aaaf"
 
@@ -0,0 +1,250 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// Status of a chart in a dependency tree.
+const (
+	// StatusOK is the status of a dependency found at a version satisfying its constraint.
+	StatusOK = "ok"
+	// StatusMissing is the status of a dependency not found in the charts/ directory.
+	StatusMissing = "missing"
+	// StatusWrongVersion is the status of a dependency found at a version not satisfying its constraint.
+	StatusWrongVersion = "wrong version"
+	// StatusUndeclared is the status of a chart in the charts/ directory that is not a declared dependency.
+	StatusUndeclared = "undeclared"
+)
+
+// Kind of a conflict in a dependency tree.
+const (
+	// ConflictVersion is a chart included at different versions.
+	ConflictVersion = "version"
+	// ConflictAlias is a version of a chart included more than once under different names.
+	ConflictAlias = "alias"
+)
+
+// Tree is the resolution tree of the dependencies of a chart, including the
+// dependencies of its subcharts.
+type Tree struct {
+	Chart     *TreeNode   `json:"chart"`
+	Conflicts []*Conflict `json:"conflicts"`
+}
+
+// TreeNode is a chart in a dependency tree.
+type TreeNode struct {
+	Name  string `json:"name"`
+	Alias string `json:"alias,omitempty"`
+	// Version is the version of the chart included, empty when it is missing.
+	Version string `json:"version,omitempty"`
+	// Constraint is the version or version range the parent chart declares.
+	Constraint   string      `json:"constraint,omitempty"`
+	Repository   string      `json:"repository,omitempty"`
+	Status       string      `json:"status"`
+	Dependencies []*TreeNode `json:"dependencies,omitempty"`
+
+	path string
+}
+
+// Path returns the names of the charts from the root chart to this chart,
+// separated by slashes. Aliases are used for the charts that have one.
+func (n *TreeNode) Path() string {
+	return n.path
+}
+
+// Conflict is a chart included in a dependency tree in incompatible ways.
+type Conflict struct {
+	Chart string `json:"chart"`
+	Kind  string `json:"kind"`
+	// Paths are the paths of the charts in conflict.
+	Paths []string `json:"paths"`
+	// Versions are the versions of the charts at Paths.
+	Versions []string `json:"versions"`
+}
+
+func (c *Conflict) String() string {
+	charts := make([]string, len(c.Paths))
+	for i, p := range c.Paths {
+		charts[i] = fmt.Sprintf("%s (%s)", c.Versions[i], p)
+	}
+	if c.Kind == ConflictVersion {
+		return fmt.Sprintf("%s is included at different versions: %s", c.Chart, strings.Join(charts, ", "))
+	}
+	return fmt.Sprintf("%s is included more than once under different names: %s", c.Chart, strings.Join(charts, ", "))
+}
+
+// NewTree returns the dependency tree of a chart loaded with its charts/
+// directory, and detects the charts it includes at different versions, and
+// the versions of charts it includes under different names.
+func NewTree(c *chart.Chart) *Tree {
+	root := &TreeNode{
+		Name:    c.Name(),
+		Version: c.Metadata.Version,
+		Status:  StatusOK,
+		path:    c.Name(),
+	}
+	addDependencies(root, c)
+	return &Tree{Chart: root, Conflicts: conflicts(root)}
+}
+
+// addDependencies adds the dependencies of c, and the charts in its charts/
+// directory that it does not declare, to node.
+func addDependencies(node *TreeNode, c *chart.Chart) {
+	used := make(map[*chart.Chart]bool)
+	for _, d := range c.Metadata.Dependencies {
+		child := &TreeNode{
+			Name:       d.Name,
+			Alias:      d.Alias,
+			Constraint: d.Version,
+			Repository: d.Repository,
+			Status:     StatusMissing,
+			path:       path.Join(node.path, cmp.Or(d.Alias, d.Name)),
+		}
+		if sub := findSubchart(c, d); sub != nil {
+			used[sub] = true
+			child.Version = sub.Metadata.Version
+			child.Status = StatusOK
+			if !satisfies(d.Version, sub.Metadata.Version) {
+				child.Status = StatusWrongVersion
+			}
+			addDependencies(child, sub)
+		}
+		node.Dependencies = append(node.Dependencies, child)
+	}
+
+	for _, sub := range c.Dependencies() {
+		if used[sub] {
+			continue
+		}
+		child := &TreeNode{
+			Name:    sub.Name(),
+			Version: sub.Metadata.Version,
+			Status:  StatusUndeclared,
+			path:    path.Join(node.path, sub.Name()),
+		}
+		addDependencies(child, sub)
+		node.Dependencies = append(node.Dependencies, child)
+	}
+}
+
+// findSubchart returns the chart in the charts/ directory of c for the
+// dependency d, preferring a chart at a version satisfying its constraint.
+func findSubchart(c *chart.Chart, d *chart.Dependency) *chart.Chart {
+	var found *chart.Chart
+	for _, sub := range c.Dependencies() {
+		if sub.Name() != d.Name {
+			continue
+		}
+		if satisfies(d.Version, sub.Metadata.Version) {
+			return sub
+		}
+		if found == nil {
+			found = sub
+		}
+	}
+	return found
+}
+
+// satisfies reports whether version satisfies constraint. An empty
+// constraint is satisfied by any version.
+func satisfies(constraint, version string) bool {
+	if constraint == "" || constraint == version {
+		return true
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return c.Check(v)
+}
+
+// conflicts returns the charts of the tree under root that are included at
+// different versions or under different names.
+func conflicts(root *TreeNode) []*Conflict {
+	var names []string
+	included := make(map[string][]*TreeNode)
+	var walk func(n *TreeNode)
+	walk = func(n *TreeNode) {
+		for _, child := range n.Dependencies {
+			if child.Status != StatusMissing {
+				if _, ok := included[child.Name]; !ok {
+					names = append(names, child.Name)
+				}
+				included[child.Name] = append(included[child.Name], child)
+			}
+			walk(child)
+		}
+	}
+	walk(root)
+
+	result := []*Conflict{}
+	for _, name := range names {
+		nodes := included[name]
+		var versions []string
+		for _, n := range nodes {
+			if !slices.Contains(versions, n.Version) {
+				versions = append(versions, n.Version)
+			}
+		}
+		if len(versions) > 1 {
+			result = append(result, newConflict(name, ConflictVersion, nodes))
+		}
+
+		// The same version of a chart included under different names is
+		// rendered more than once.
+		for _, version := range versions {
+			var same []*TreeNode
+			var aliases []string
+			for _, n := range nodes {
+				if n.Version != version {
+					continue
+				}
+				same = append(same, n)
+				if alias := cmp.Or(n.Alias, n.Name); !slices.Contains(aliases, alias) {
+					aliases = append(aliases, alias)
+				}
+			}
+			if len(aliases) > 1 {
+				result = append(result, newConflict(name, ConflictAlias, same))
+			}
+		}
+	}
+	return result
+}
+
+func newConflict(name, kind string, nodes []*TreeNode) *Conflict {
+	c := &Conflict{Chart: name, Kind: kind}
+	for _, n := range nodes {
+		c.Paths = append(c.Paths, n.path)
+		c.Versions = append(c.Versions, n.Version)
+	}
+	return c
+}
@@ -0,0 +1,90 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestNewTree(t *testing.T) {
+	newChart := func(name, version string, deps ...*chart.Dependency) *chart.Chart {
+		return &chart.Chart{Metadata: &chart.Metadata{Name: name, Version: version, Dependencies: deps}}
+	}
+
+	common1 := newChart("common", "1.0.0")
+	common2 := newChart("common", "2.0.0")
+	db := newChart("db", "1.0.0", &chart.Dependency{Name: "common", Version: "^2.0.0"})
+	db.SetDependencies(common2)
+	web := newChart("web", "1.0.0", &chart.Dependency{Name: "common", Version: "^1.0.0"})
+	web.SetDependencies(common1)
+	root := newChart("root", "0.1.0",
+		&chart.Dependency{Name: "db", Version: "~1.0.0"},
+		&chart.Dependency{Name: "web", Alias: "frontend", Version: "^1.0.0"},
+		&chart.Dependency{Name: "web", Alias: "backend", Version: "^1.0.0"},
+		&chart.Dependency{Name: "cache", Version: "1.0.0"},
+	)
+	root.SetDependencies(db, web, newChart("cache", "2.0.0"))
+
+	tree := NewTree(root)
+	require.Len(t, tree.Chart.Dependencies, 4)
+	assert.Equal(t, "root", tree.Chart.Path())
+
+	frontend := tree.Chart.Dependencies[1]
+	assert.Equal(t, "root/frontend", frontend.Path())
+	assert.Equal(t, StatusOK, frontend.Status)
+	require.Len(t, frontend.Dependencies, 1)
+	assert.Equal(t, "root/frontend/common", frontend.Dependencies[0].Path())
+	assert.Equal(t, "1.0.0", frontend.Dependencies[0].Version)
+
+	cache := tree.Chart.Dependencies[3]
+	assert.Equal(t, "2.0.0", cache.Version)
+	assert.Equal(t, StatusWrongVersion, cache.Status)
+
+	expected := []*Conflict{
+		{
+			Chart:    "common",
+			Kind:     ConflictVersion,
+			Paths:    []string{"root/db/common", "root/frontend/common", "root/backend/common"},
+			Versions: []string{"2.0.0", "1.0.0", "1.0.0"},
+		},
+		{
+			Chart:    "web",
+			Kind:     ConflictAlias,
+			Paths:    []string{"root/frontend", "root/backend"},
+			Versions: []string{"1.0.0", "1.0.0"},
+		},
+	}
+	assert.Equal(t, expected, tree.Conflicts)
+}
+
+func TestNewTreeWithoutConflicts(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{
+		Name:         "root",
+		Version:      "0.1.0",
+		Dependencies: []*chart.Dependency{{Name: "missing", Version: "1.0.0"}},
+	}}
+
+	tree := NewTree(c)
+	require.Len(t, tree.Chart.Dependencies, 1)
+	assert.Equal(t, StatusMissing, tree.Chart.Dependencies[0].Status)
+	assert.Empty(t, tree.Conflicts)
+}
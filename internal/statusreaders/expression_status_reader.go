@@ -0,0 +1,294 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusreaders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/fluxcd/cli-utils/pkg/kstatus/polling/engine"
+	"github.com/fluxcd/cli-utils/pkg/kstatus/polling/event"
+	"github.com/fluxcd/cli-utils/pkg/kstatus/polling/statusreaders"
+	"github.com/fluxcd/cli-utils/pkg/kstatus/status"
+	"github.com/fluxcd/cli-utils/pkg/object"
+)
+
+// celCostLimit bounds the cost of evaluating a CEL readiness expression.
+const celCostLimit = 1000000
+
+// ReadinessExpression is a user supplied readiness condition for resources of
+// a kind. It has the form KIND[.GROUP]:JSONPATH[OPERATOR VALUE], for example
+//
+//	Certificate.cert-manager.io:.status.conditions[?(@.type=="Ready")].status==True
+//
+// OPERATOR is either "==" or "!=". Without an operator, a resource is ready as
+// soon as the JSONPath query returns a non-empty value.
+//
+// Alternatively, the condition is a CEL expression of the form
+// KIND[.GROUP]:cel:EXPRESSION that evaluates to a bool, with the resource
+// available as self, for example
+//
+//	Certificate:cel:self.status.conditions.exists(c, c.type == "Ready" && c.status == "True")
+//
+// Fields missing from a resource make a CEL expression evaluate to not ready.
+type ReadinessExpression struct {
+	// Kind is the kind of resources the expression applies to
+	Kind string
+	// Group is the API group of resources the expression applies to. When
+	// empty, the expression applies to resources of the kind in any group.
+	Group string
+	// Path is the JSONPath query evaluated against each resource
+	Path string
+	// Operator is "==", "!=" or empty
+	Operator string
+	// Value is compared with the results of the query
+	Value string
+	// CEL is the CEL expression evaluated against each resource. When set,
+	// Path, Operator and Value are empty.
+	CEL string
+
+	query   *jsonpath.JSONPath
+	program cel.Program
+}
+
+// ParseReadinessExpression parses a readiness expression. See ReadinessExpression for the syntax.
+func ParseReadinessExpression(expr string) (*ReadinessExpression, error) {
+	kind, path, ok := strings.Cut(expr, ":")
+	if !ok || kind == "" || strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("invalid readiness expression %q: must be of the form KIND[.GROUP]:JSONPATH[==VALUE]", expr)
+	}
+
+	e := &ReadinessExpression{}
+	e.Kind, e.Group, _ = strings.Cut(kind, ".")
+
+	if celExpr, ok := strings.CutPrefix(path, "cel:"); ok {
+		e.CEL = strings.TrimSpace(celExpr)
+		if e.CEL == "" {
+			return nil, fmt.Errorf("invalid readiness expression %q: missing CEL expression", expr)
+		}
+		program, err := compileCEL(e.CEL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid readiness expression %q: %w", expr, err)
+		}
+		e.program = program
+		return e, nil
+	}
+
+	// The operator is the last one outside of brackets, as filters such as
+	// [?(@.type=="Ready")] contain operators of their own.
+	depth, opIndex := 0, -1
+	for i := 0; i < len(path)-1; i++ {
+		switch path[i] {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case '=', '!':
+			if depth == 0 && path[i+1] == '=' {
+				opIndex = i
+			}
+		}
+	}
+	e.Path = strings.TrimSpace(path)
+	if opIndex >= 0 {
+		e.Path = strings.TrimSpace(path[:opIndex])
+		e.Operator = path[opIndex : opIndex+2]
+		e.Value = strings.Trim(strings.TrimSpace(path[opIndex+2:]), `"'`)
+		if e.Path == "" {
+			return nil, fmt.Errorf("invalid readiness expression %q: missing JSONPath", expr)
+		}
+	}
+
+	query := e.Path
+	if !strings.HasPrefix(query, "{") {
+		query = "{" + query + "}"
+	}
+	e.query = jsonpath.New(kind).AllowMissingKeys(true)
+	if err := e.query.Parse(query); err != nil {
+		return nil, fmt.Errorf("invalid readiness expression %q: %w", expr, err)
+	}
+	return e, nil
+}
+
+// compileCEL compiles a CEL readiness expression, which must evaluate to a bool.
+func compileCEL(expr string) (cel.Program, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("self", cel.MapType(cel.StringType, cel.DynType)),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expr)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+		return nil, fmt.Errorf("must evaluate to a bool, not %s", t)
+	}
+	return env.Program(ast, cel.CostLimit(celCostLimit))
+}
+
+// Supports returns whether the expression applies to resources of the given group and kind.
+func (e *ReadinessExpression) Supports(gk schema.GroupKind) bool {
+	return gk.Kind == e.Kind && (e.Group == "" || gk.Group == e.Group)
+}
+
+// Ready evaluates the expression against a resource.
+func (e *ReadinessExpression) Ready(u *unstructured.Unstructured) (bool, error) {
+	if e.program != nil {
+		return e.readyCEL(u)
+	}
+	results, err := e.query.FindResults(u.UnstructuredContent())
+	if err != nil {
+		return false, err
+	}
+	var values []string
+	for _, r := range results {
+		for _, v := range r {
+			if v.CanInterface() && v.Interface() != nil {
+				values = append(values, fmt.Sprint(v.Interface()))
+			}
+		}
+	}
+	if len(values) == 0 {
+		return false, nil
+	}
+	for _, v := range values {
+		switch e.Operator {
+		case "==":
+			if v != e.Value {
+				return false, nil
+			}
+		case "!=":
+			if v == e.Value {
+				return false, nil
+			}
+		default:
+			if v == "" {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// readyCEL evaluates the CEL expression against a resource.
+func (e *ReadinessExpression) readyCEL(u *unstructured.Unstructured) (bool, error) {
+	out, _, err := e.program.Eval(map[string]any{"self": u.UnstructuredContent()})
+	if err != nil {
+		// Resources are not ready until the fields they are checked for are set
+		if strings.HasPrefix(err.Error(), "no such key") {
+			return false, nil
+		}
+		return false, err
+	}
+	ready, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("must evaluate to a bool, not %s", out.Type())
+	}
+	return ready, nil
+}
+
+func (e *ReadinessExpression) String() string {
+	kind := e.Kind
+	if e.Group != "" {
+		kind += "." + e.Group
+	}
+	if e.CEL != "" {
+		return kind + ":cel:" + e.CEL
+	}
+	return kind + ":" + e.Path + e.Operator + e.Value
+}
+
+type expressionStatusReader struct {
+	genericStatusReader engine.StatusReader
+	expressions         []*ReadinessExpression
+}
+
+// NewExpressionStatusReader returns a status reader that considers resources
+// ready once all readiness expressions applying to their kind are satisfied.
+func NewExpressionStatusReader(mapper meta.RESTMapper, expressions ...*ReadinessExpression) engine.StatusReader {
+	r := &expressionStatusReader{expressions: expressions}
+	r.genericStatusReader = statusreaders.NewGenericStatusReader(mapper, r.conditions)
+	return r
+}
+
+func (r *expressionStatusReader) Supports(gk schema.GroupKind) bool {
+	for _, e := range r.expressions {
+		if e.Supports(gk) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *expressionStatusReader) ReadStatus(ctx context.Context, reader engine.ClusterReader, resource object.ObjMetadata) (*event.ResourceStatus, error) {
+	return r.genericStatusReader.ReadStatus(ctx, reader, resource)
+}
+
+func (r *expressionStatusReader) ReadStatusForObject(ctx context.Context, reader engine.ClusterReader, resource *unstructured.Unstructured) (*event.ResourceStatus, error) {
+	return r.genericStatusReader.ReadStatusForObject(ctx, reader, resource)
+}
+
+func (r *expressionStatusReader) conditions(u *unstructured.Unstructured) (*status.Result, error) {
+	gk := u.GroupVersionKind().GroupKind()
+	var errs []error
+	for _, e := range r.expressions {
+		if !e.Supports(gk) {
+			continue
+		}
+		ready, err := e.Ready(u)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("evaluating readiness expression %q: %w", e, err))
+			continue
+		}
+		if !ready {
+			message := fmt.Sprintf("Waiting for readiness expression %q", e)
+			return &status.Result{
+				Status:  status.InProgressStatus,
+				Message: message,
+				Conditions: []status.Condition{
+					{
+						Type:    status.ConditionReconciling,
+						Status:  corev1.ConditionTrue,
+						Reason:  "ReadinessExpressionPending",
+						Message: message,
+					},
+				},
+			}, nil
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &status.Result{
+		Status:     status.CurrentStatus,
+		Message:    "Readiness expressions satisfied",
+		Conditions: []status.Condition{},
+	}, nil
+}
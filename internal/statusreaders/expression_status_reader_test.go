@@ -0,0 +1,216 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusreaders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/fluxcd/cli-utils/pkg/kstatus/status"
+)
+
+func TestParseReadinessExpression(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		expr     string
+		kind     string
+		group    string
+		path     string
+		operator string
+		value    string
+		cel      string
+		wantErr  bool
+	}{
+		{
+			expr:     `Certificate:.status.conditions[?(@.type=="Ready")].status==True`,
+			kind:     "Certificate",
+			path:     `.status.conditions[?(@.type=="Ready")].status`,
+			operator: "==",
+			value:    "True",
+		},
+		{
+			expr:     `Certificate.cert-manager.io:.status.phase != "Pending"`,
+			kind:     "Certificate",
+			group:    "cert-manager.io",
+			path:     ".status.phase",
+			operator: "!=",
+			value:    "Pending",
+		},
+		{
+			expr: `Service:{.status.loadBalancer.ingress[0].ip}`,
+			kind: "Service",
+			path: "{.status.loadBalancer.ingress[0].ip}",
+		},
+		{
+			expr:  `Certificate.cert-manager.io:cel:self.status.conditions.exists(c, c.type == "Ready")`,
+			kind:  "Certificate",
+			group: "cert-manager.io",
+			cel:   `self.status.conditions.exists(c, c.type == "Ready")`,
+		},
+		{expr: "Certificate", wantErr: true},
+		{expr: "Certificate:cel:", wantErr: true},
+		{expr: "Certificate:cel:self.status.(", wantErr: true},
+		{expr: "Certificate:cel:size(self.metadata)", wantErr: true},
+		{expr: ":.status.phase", wantErr: true},
+		{expr: "Certificate:==True", wantErr: true},
+		{expr: "Certificate:.status[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+			e, err := ParseReadinessExpression(tt.expr)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.kind, e.Kind)
+			assert.Equal(t, tt.group, e.Group)
+			assert.Equal(t, tt.path, e.Path)
+			assert.Equal(t, tt.operator, e.Operator)
+			assert.Equal(t, tt.value, e.Value)
+			assert.Equal(t, tt.cel, e.CEL)
+		})
+	}
+}
+
+func TestReadinessExpressionSupports(t *testing.T) {
+	t.Parallel()
+	anyGroup, err := ParseReadinessExpression("Certificate:.status.phase")
+	require.NoError(t, err)
+	withGroup, err := ParseReadinessExpression("Certificate.cert-manager.io:.status.phase")
+	require.NoError(t, err)
+
+	certManager := schema.GroupKind{Group: "cert-manager.io", Kind: "Certificate"}
+	other := schema.GroupKind{Group: "example.com", Kind: "Certificate"}
+
+	assert.True(t, anyGroup.Supports(certManager))
+	assert.True(t, anyGroup.Supports(other))
+	assert.True(t, withGroup.Supports(certManager))
+	assert.False(t, withGroup.Supports(other))
+	assert.False(t, anyGroup.Supports(schema.GroupKind{Kind: "Secret"}))
+}
+
+func TestExpressionConditions(t *testing.T) {
+	t.Parallel()
+	certificate := func(conditions ...any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata":   map[string]any{"name": "web"},
+			"status":     map[string]any{"conditions": conditions},
+		}}
+	}
+	ready := func(s string) any {
+		return map[string]any{"type": "Ready", "status": s}
+	}
+
+	tests := []struct {
+		name           string
+		exprs          []string
+		obj            *unstructured.Unstructured
+		expectedStatus status.Status
+	}{
+		{
+			name:           "condition met",
+			exprs:          []string{`Certificate:.status.conditions[?(@.type=="Ready")].status==True`},
+			obj:            certificate(ready("True")),
+			expectedStatus: status.CurrentStatus,
+		},
+		{
+			name:           "condition not met",
+			exprs:          []string{`Certificate:.status.conditions[?(@.type=="Ready")].status==True`},
+			obj:            certificate(ready("False")),
+			expectedStatus: status.InProgressStatus,
+		},
+		{
+			name:           "missing field",
+			exprs:          []string{`Certificate:.status.conditions[?(@.type=="Ready")].status==True`},
+			obj:            certificate(),
+			expectedStatus: status.InProgressStatus,
+		},
+		{
+			name:           "not equal",
+			exprs:          []string{`Certificate:.status.conditions[?(@.type=="Ready")].status!=False`},
+			obj:            certificate(ready("True")),
+			expectedStatus: status.CurrentStatus,
+		},
+		{
+			name:           "exists",
+			exprs:          []string{`Certificate:.status.conditions[0].type`},
+			obj:            certificate(ready("False")),
+			expectedStatus: status.CurrentStatus,
+		},
+		{
+			name:           "CEL condition met",
+			exprs:          []string{`Certificate:cel:self.status.conditions.exists(c, c.type == "Ready" && c.status == "True")`},
+			obj:            certificate(ready("True")),
+			expectedStatus: status.CurrentStatus,
+		},
+		{
+			name:           "CEL condition not met",
+			exprs:          []string{`Certificate:cel:self.status.conditions.exists(c, c.type == "Ready" && c.status == "True")`},
+			obj:            certificate(ready("False")),
+			expectedStatus: status.InProgressStatus,
+		},
+		{
+			name:           "CEL missing field",
+			exprs:          []string{`Certificate:cel:self.status.observedGeneration >= 1`},
+			obj:            certificate(ready("True")),
+			expectedStatus: status.InProgressStatus,
+		},
+		{
+			name: "all expressions must be met",
+			exprs: []string{
+				`Certificate:.status.conditions[?(@.type=="Ready")].status==True`,
+				`Certificate:.metadata.name==api`,
+			},
+			obj:            certificate(ready("True")),
+			expectedStatus: status.InProgressStatus,
+		},
+		{
+			name: "expressions for other kinds are ignored",
+			exprs: []string{
+				`Certificate:.status.conditions[?(@.type=="Ready")].status==True`,
+				`Issuer:.metadata.name==api`,
+			},
+			obj:            certificate(ready("True")),
+			expectedStatus: status.CurrentStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var exprs []*ReadinessExpression
+			for _, s := range tt.exprs {
+				e, err := ParseReadinessExpression(s)
+				require.NoError(t, err)
+				exprs = append(exprs, e)
+			}
+			r := &expressionStatusReader{expressions: exprs}
+			result, err := r.conditions(tt.obj)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, result.Status)
+		})
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
@@ -47,12 +48,14 @@ import (
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/engine"
+	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/kube"
 	"helm.sh/helm/v4/pkg/postrenderer"
 	"helm.sh/helm/v4/pkg/registry"
 	ri "helm.sh/helm/v4/pkg/release"
 	release "helm.sh/helm/v4/pkg/release/v1"
 	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+	"helm.sh/helm/v4/pkg/renderhook"
 	"helm.sh/helm/v4/pkg/storage"
 	"helm.sh/helm/v4/pkg/storage/driver"
 )
@@ -87,6 +90,11 @@ const (
 	// DryRunServer, or server-side dry-run, indicates the client will send
 	// calls to the APIServer with the dry-run parameter to prevent persisting changes
 	DryRunServer DryRunStrategy = "server"
+
+	// DryRunServerAdmission is a server-side dry-run that also submits the
+	// rendered resources to the APIServer with the dry-run parameter, so that
+	// admission webhooks and policies validate them without persisting changes
+	DryRunServerAdmission DryRunStrategy = "server-admission"
 )
 
 // PostRenderStrategy determines how hooks and regular templates are passed
@@ -136,6 +144,26 @@ type Configuration struct {
 	// CustomTemplateFuncs is defined by users to provide custom template funcs
 	CustomTemplateFuncs template.FuncMap
 
+	// RenderCache optionally stores rendered templates, to skip rendering a
+	// chart again with the same values.
+	RenderCache *engine.RenderCache
+
+	// RenderProfile optionally records where the time rendering templates is
+	// spent.
+	RenderProfile *engine.RenderProfile
+
+	// LookupFixtures optionally backs the `lookup` template function with
+	// local objects when rendering does not talk to the cluster.
+	LookupFixtures *engine.LookupFixtures
+
+	// RenderWarnings optionally collects the warnings templates emit with the
+	// `warn` template function. Without it, warnings are logged.
+	RenderWarnings *engine.RenderWarnings
+
+	// RenderHooks optionally run local commands or HTTP endpoints before
+	// the templates of a chart are rendered and on the rendered manifests.
+	RenderHooks *renderhook.Hooks
+
 	// HookOutputFunc called with container name and returns and expects writer that will receive the log output.
 	HookOutputFunc func(namespace, pod, container string) io.Writer
 
@@ -270,13 +298,30 @@ func splitAndDeannotate(postrendered, fallbackPrefix string) (map[string]string,
 	return reconstructed, nil
 }
 
+// preRender runs the pre-render hooks on the values of the chart, and returns
+// the render values with the values the hooks returned.
+func (cfg *Configuration) preRender(ctx context.Context, ch *chart.Chart, values common.Values, releaseName string) (common.Values, error) {
+	chartValues, err := values.Table("Values")
+	if err != nil {
+		chartValues = common.Values{}
+	}
+	hooked, err := cfg.RenderHooks.RunPreRender(ctx, releaseName, ch.Metadata, chartValues)
+	if err != nil {
+		return nil, err
+	}
+	rendered := make(common.Values, len(values))
+	maps.Copy(rendered, values)
+	rendered["Values"] = common.Values(hooked)
+	return rendered, nil
+}
+
 // renderResources renders the templates in a chart
 //
 // TODO: This function is badly in need of a refactor.
 // TODO: As part of the refactor the duplicate code in cmd/helm/template.go should be removed
 //
 //	This code has to do with writing files to disk.
-func (cfg *Configuration) renderResources(ctx context.Context, ch *chart.Chart, values common.Values, releaseName, outputDir string, subNotes, useReleaseName, includeCrds bool, pr postrenderer.PostRenderer, interactWithRemote, enableDNS, hideSecret bool, postRenderStrategy PostRenderStrategy) ([]*release.Hook, *bytes.Buffer, string, error) {
+func (cfg *Configuration) renderResources(ctx context.Context, ch *chart.Chart, values common.Values, releaseName, outputDir string, outputDirLayout OutputDirLayout, subNotes, useReleaseName, includeCrds bool, pr postrenderer.PostRenderer, interactWithRemote, enableDNS, hideSecret bool, postRenderStrategy PostRenderStrategy, renderOnly []string) ([]*release.Hook, *bytes.Buffer, string, error) {
 	var hs []*release.Hook
 	b := bytes.NewBuffer(nil)
 
@@ -291,6 +336,12 @@ func (cfg *Configuration) renderResources(ctx context.Context, ch *chart.Chart,
 		}
 	}
 
+	if cfg.RenderHooks.Has(renderhook.PreRender) {
+		if values, err = cfg.preRender(ctx, ch, values, releaseName); err != nil {
+			return hs, b, "", err
+		}
+	}
+
 	var files map[string]string
 	var err2 error
 
@@ -305,12 +356,20 @@ func (cfg *Configuration) renderResources(ctx context.Context, ch *chart.Chart,
 		e := engine.New(restConfig)
 		e.EnableDNS = enableDNS
 		e.CustomTemplateFuncs = cfg.CustomTemplateFuncs
+		e.Templates = renderOnly
+		e.Profile = cfg.RenderProfile
+		e.Warnings = cfg.RenderWarnings
 
 		files, err2 = e.RenderWithContext(ctx, ch, values)
 	} else {
 		var e engine.Engine
 		e.EnableDNS = enableDNS
 		e.CustomTemplateFuncs = cfg.CustomTemplateFuncs
+		e.Cache = cfg.RenderCache
+		e.Templates = renderOnly
+		e.Profile = cfg.RenderProfile
+		e.LookupFixtures = cfg.LookupFixtures
+		e.Warnings = cfg.RenderWarnings
 
 		files, err2 = e.RenderWithContext(ctx, ch, values)
 	}
@@ -459,6 +518,21 @@ func (cfg *Configuration) renderResources(ctx context.Context, ch *chart.Chart,
 		}
 	}
 
+	if cfg.RenderHooks.Has(renderhook.PostRender) {
+		merged, err := annotateAndMerge(files)
+		if err != nil {
+			return hs, b, notes, fmt.Errorf("error merging manifests: %w", err)
+		}
+		postRendered, err := cfg.RenderHooks.RunPostRender(ctx, releaseName, bytes.NewBufferString(merged))
+		if err != nil {
+			return hs, b, notes, err
+		}
+		files, err = splitAndDeannotate(postRendered.String(), "")
+		if err != nil {
+			return hs, b, notes, fmt.Errorf("error while parsing the output of the post-render hooks: %w", err)
+		}
+	}
+
 	// Sort hooks, manifests, and partials. Only hooks and manifests are returned,
 	// as partials are not used after renderer.Render. Empty manifests are also
 	// removed here.
@@ -486,11 +560,13 @@ func (cfg *Configuration) renderResources(ctx context.Context, ch *chart.Chart,
 			if outputDir == "" {
 				fmt.Fprintf(b, "---\n# Source: %s\n%s\n", crd.Filename, string(crd.File.Data[:]))
 			} else {
-				err = writeToFile(outputDir, crd.Filename, string(crd.File.Data[:]), fileWritten[crd.Filename])
-				if err != nil {
-					return hs, b, "", err
+				for _, f := range outputFiles(outputDirLayout, crd.Filename, string(crd.File.Data[:])) {
+					err = writeToFile(outputDir, f.name, crd.Filename, f.content, fileWritten[f.name])
+					if err != nil {
+						return hs, b, "", err
+					}
+					fileWritten[f.name] = true
 				}
-				fileWritten[crd.Filename] = true
 			}
 		}
 	}
@@ -511,11 +587,15 @@ func (cfg *Configuration) renderResources(ctx context.Context, ch *chart.Chart,
 			// output dir is only used by `helm template`. In the next major
 			// release, we should move this logic to template only as it is not
 			// used by install or upgrade
-			err = writeToFile(newDir, m.Name, m.Content, fileWritten[m.Name])
+			name := m.Name
+			if m.Head != nil && m.Head.Metadata != nil {
+				name = OutputFileName(outputDirLayout, m.Name, m.Head.Kind, m.Head.Metadata.Name)
+			}
+			err = writeToFile(newDir, name, m.Name, m.Content, fileWritten[name])
 			if err != nil {
 				return hs, b, "", err
 			}
-			fileWritten[m.Name] = true
+			fileWritten[name] = true
 		}
 	}
 
@@ -558,9 +638,15 @@ func (cfg *Configuration) getCapabilities() (*common.Capabilities, error) {
 			return nil, fmt.Errorf("could not get apiVersions from Kubernetes: %w", err)
 		}
 	}
+	// Orphaned API services were already warned about above
+	apiResources, err := GetAPIResources(dc)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
 
 	cfg.Capabilities = &common.Capabilities{
-		APIVersions: apiVersions,
+		APIVersions:  apiVersions,
+		APIResources: apiResources,
 		KubeVersion: common.KubeVersion{
 			Version: kubeVersion.GitVersion,
 			Major:   kubeVersion.Major,
@@ -648,6 +734,46 @@ func GetVersionSet(client discovery.ServerResourcesInterface) (common.VersionSet
 	return common.VersionSet(versions), nil
 }
 
+// GetAPIResources retrieves the resources served by the Kubernetes API, sorted
+// by API version and resource name. Subresources, such as "deployments/scale",
+// are left out.
+func GetAPIResources(client discovery.ServerResourcesInterface) (common.APIResources, error) {
+	_, lists, err := client.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("could not get API resources from Kubernetes: %w", err)
+	}
+
+	var resources common.APIResources
+	for _, list := range lists {
+		gv, perr := schema.ParseGroupVersion(list.GroupVersion)
+		if perr != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			resources = append(resources, common.APIResource{
+				Group:      gv.Group,
+				Version:    gv.Version,
+				Resource:   r.Name,
+				Kind:       r.Kind,
+				Namespaced: r.Namespaced,
+				Verbs:      slices.Clone([]string(r.Verbs)),
+			})
+		}
+	}
+	slices.SortFunc(resources, func(a, b common.APIResource) int {
+		if c := strings.Compare(a.GroupVersion(), b.GroupVersion()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Resource, b.Resource)
+	})
+	// err is a group discovery error, the resources of the other groups are
+	// still valid
+	return resources, err
+}
+
 // recordRelease with an update operation in case reuse has been set.
 func (cfg *Configuration) recordRelease(r *release.Release) {
 	if err := cfg.Releases.Update(r); err != nil {
@@ -674,6 +800,13 @@ func (cfg *Configuration) Init(getter genericclioptions.RESTClientGetter, namesp
 	switch helmDriver {
 	case "secret", "secrets", "":
 		d := driver.NewSecrets(newSecretClient(lazyClient))
+		switch compression := os.Getenv("HELM_DRIVER_SECRETS_COMPRESSION"); compression {
+		case "", "gzip":
+		case "zstd":
+			d.Zstd = true
+		default:
+			return fmt.Errorf("unknown Secret storage driver compression %q", compression)
+		}
 		d.SetLogger(cfg.Logger().Handler())
 		store = storage.Init(d)
 	case "configmap", "configmaps":
@@ -697,7 +830,14 @@ func (cfg *Configuration) Init(getter genericclioptions.RESTClientGetter, namesp
 		d.SetNamespace(namespace)
 		store = storage.Init(d)
 	case "sql":
-		d, err := driver.NewSQL(
+		// Only programs registering a MySQL database/sql driver can set
+		// HELM_DRIVER_SQL_DIALECT=mysql, the helm binary does not register one
+		dialect := os.Getenv("HELM_DRIVER_SQL_DIALECT")
+		if dialect == "" {
+			dialect = driver.SQLDialectPostgres
+		}
+		d, err := driver.NewSQLWithDialect(
+			dialect,
 			os.Getenv("HELM_DRIVER_SQL_CONNECTION_STRING"),
 			namespace,
 		)
@@ -707,7 +847,27 @@ func (cfg *Configuration) Init(getter genericclioptions.RESTClientGetter, namesp
 		d.SetLogger(cfg.Logger().Handler())
 		store = storage.Init(d)
 	default:
-		return fmt.Errorf("unknown driver %q", helmDriver)
+		name, ok := strings.CutPrefix(helmDriver, "plugin:")
+		if !ok {
+			return fmt.Errorf("unknown driver %q", helmDriver)
+		}
+		pluginsDir := os.Getenv("HELM_PLUGINS")
+		if pluginsDir == "" {
+			pluginsDir = helmpath.DataPath("plugins")
+		}
+		d, err := driver.NewPlugin(filepath.SplitList(pluginsDir), name, namespace)
+		if err != nil {
+			return fmt.Errorf("unable to instantiate plugin driver: %w", err)
+		}
+		d.SetLogger(cfg.Logger().Handler())
+		store = storage.Init(d)
+	}
+
+	// Releases stored in memory are local to this process and need no lock
+	if helmDriver != "memory" {
+		locker := storage.NewLeaseLocker(newLeaseClient(lazyClient))
+		locker.SetLogger(cfg.Logger().Handler())
+		store.Locker = locker
 	}
 
 	cfg.RESTClientGetter = getter
@@ -732,10 +892,35 @@ func determineReleaseSSApplyMethod(serverSideApply bool) release.ApplyMethod {
 
 // isDryRun returns true if the strategy is set to run as a DryRun
 func isDryRun(strategy DryRunStrategy) bool {
-	return strategy == DryRunClient || strategy == DryRunServer
+	return strategy == DryRunClient || strategy == DryRunServer || strategy == DryRunServerAdmission
 }
 
 // interactWithServer determine whether or not to interact with a remote Kubernetes server
 func interactWithServer(strategy DryRunStrategy) bool {
-	return strategy == DryRunNone || strategy == DryRunServer
+	return strategy == DryRunNone || strategy == DryRunServer || strategy == DryRunServerAdmission
+}
+
+// admissionDryRun submits the resources and hooks of a release to the
+// APIServer using a server-side apply dry-run, so that they go through
+// admission. Conflicts are only reported if the release would be applied with
+// server-side apply without forcing conflicts.
+func (cfg *Configuration) admissionDryRun(rel *release.Release, resources kube.ResourceList, serverSideApply, forceConflicts bool) error {
+	all := append(kube.ResourceList{}, resources...)
+	for _, h := range rel.Hooks {
+		hookResources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
+		if err != nil {
+			return fmt.Errorf("unable to build hook %s: %w", h.Path, err)
+		}
+		all = append(all, hookResources...)
+	}
+	if len(all) == 0 {
+		return nil
+	}
+	if _, err := cfg.KubeClient.Create(
+		all,
+		kube.ClientCreateOptionServerSideApply(true, forceConflicts || !serverSideApply),
+		kube.ClientCreateOptionDryRun(true)); err != nil {
+		return fmt.Errorf("rejected by server-side dry-run: %w", err)
+	}
+	return nil
 }
@@ -22,12 +22,14 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	fakeclientset "k8s.io/client-go/kubernetes/fake"
 
 	"helm.sh/helm/v4/internal/logging"
@@ -38,6 +40,7 @@ import (
 	"helm.sh/helm/v4/pkg/registry"
 	rcommon "helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/renderhook"
 	"helm.sh/helm/v4/pkg/storage"
 	"helm.sh/helm/v4/pkg/storage/driver"
 )
@@ -384,6 +387,34 @@ func TestGetVersionSet(t *testing.T) {
 	}
 }
 
+func TestGetAPIResources(t *testing.T) {
+	client := fakeclientset.NewClientset()
+	client.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+				{Name: "deployments/scale", Kind: "Scale", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "namespaces", Kind: "Namespace"},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+			},
+		},
+	}
+
+	resources, err := GetAPIResources(client.Discovery())
+	require.NoError(t, err)
+	assert.Equal(t, common.APIResources{
+		{Group: "apps", Version: "v1", Resource: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"get", "list"}},
+		{Version: "v1", Resource: "configmaps", Kind: "ConfigMap", Namespaced: true},
+		{Version: "v1", Resource: "namespaces", Kind: "Namespace"},
+	}, resources)
+}
+
 // Mock PostRenderer for testing
 type mockPostRenderer struct {
 	shouldError bool
@@ -1823,8 +1854,8 @@ func TestRenderResources_PostRenderer_Success(t *testing.T) {
 	values := map[string]any{}
 
 	hooks, buf, notes, err := cfg.renderResources(
-		t.Context(), ch, values, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategyCombined,
+		t.Context(), ch, values, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategyCombined, nil,
 	)
 
 	assert.NoError(t, err)
@@ -1870,8 +1901,8 @@ func TestRenderResources_PostRenderer_Error(t *testing.T) {
 	values := map[string]any{}
 
 	_, _, _, err := cfg.renderResources(
-		t.Context(), ch, values, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategyCombined,
+		t.Context(), ch, values, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategyCombined, nil,
 	)
 
 	assert.Error(t, err)
@@ -1898,8 +1929,8 @@ func TestRenderResources_PostRenderer_MergeError(t *testing.T) {
 	values := map[string]any{}
 
 	_, _, _, err := cfg.renderResources(
-		t.Context(), ch, values, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategyCombined,
+		t.Context(), ch, values, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategyCombined, nil,
 	)
 
 	assert.Error(t, err)
@@ -1920,8 +1951,8 @@ func TestRenderResources_PostRenderer_SplitError(t *testing.T) {
 	values := map[string]any{}
 
 	_, _, _, err := cfg.renderResources(
-		t.Context(), ch, values, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategyCombined,
+		t.Context(), ch, values, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategyCombined, nil,
 	)
 
 	assert.Error(t, err)
@@ -1941,8 +1972,8 @@ func TestRenderResources_PostRenderer_Integration(t *testing.T) {
 	values := map[string]any{}
 
 	hooks, buf, notes, err := cfg.renderResources(
-		t.Context(), ch, values, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategyCombined,
+		t.Context(), ch, values, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategyCombined, nil,
 	)
 
 	assert.NoError(t, err)
@@ -1980,8 +2011,8 @@ func TestRenderResources_NoPostRenderer(t *testing.T) {
 	values := map[string]any{}
 
 	hooks, buf, notes, err := cfg.renderResources(
-		t.Context(), ch, values, "test-release", "", false, false, false,
-		nil, false, false, false, PostRenderStrategyCombined,
+		t.Context(), ch, values, "test-release", "", "", false, false, false,
+		nil, false, false, false, PostRenderStrategyCombined, nil,
 	)
 
 	assert.NoError(t, err)
@@ -1990,6 +2021,40 @@ func TestRenderResources_NoPostRenderer(t *testing.T) {
 	assert.Empty(t, notes)
 }
 
+func TestRenderResources_RenderHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+	hooks, err := renderhook.Parse([]byte(`hooks:
+- name: replicas
+  events: [pre-render]
+  command: sh
+  args: ["-c", "cat > /dev/null; echo 'replicas: 3'"]
+- name: label
+  events: [post-render]
+  command: sed
+  args: ["s/name: web/name: web-checked/"]
+`))
+	require.NoError(t, err)
+	cfg := actionConfigFixture(t)
+	cfg.RenderHooks = hooks
+
+	ch := buildChartWithTemplates([]*common.File{{
+		Name: "templates/cm.yaml",
+		Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\ndata:\n  replicas: \"{{ .Values.replicas }}\"\n"),
+	}})
+	values := common.Values{"Values": common.Values{"replicas": 1}}
+
+	_, buf, _, err := cfg.renderResources(
+		t.Context(), ch, values, "test-release", "", "", false, false, false,
+		nil, false, false, false, PostRenderStrategyCombined, nil,
+	)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "name: web-checked")
+	assert.Contains(t, buf.String(), `replicas: "3"`)
+	assert.Equal(t, common.Values{"replicas": 1}, values["Values"], "the values of the caller are not modified")
+}
+
 func TestRenderResources_PostRenderer_DuplicateResourceInHookAndTemplate(t *testing.T) {
 	cfg := actionConfigFixture(t)
 
@@ -2041,8 +2106,8 @@ spec:
 	}
 
 	hooks, buf, _, err := cfg.renderResources(
-		t.Context(), ch, nil, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategySeparate,
+		t.Context(), ch, nil, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategySeparate, nil,
 	)
 
 	assert.NoError(t, err)
@@ -2083,8 +2148,8 @@ metadata:
 	}
 
 	_, _, _, err := cfg.renderResources(
-		t.Context(), ch, nil, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategyCombined,
+		t.Context(), ch, nil, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategyCombined, nil,
 	)
 
 	assert.NoError(t, err)
@@ -2119,8 +2184,8 @@ metadata:
 	}
 
 	_, _, _, err := cfg.renderResources(
-		t.Context(), ch, nil, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategy(""),
+		t.Context(), ch, nil, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategy(""), nil,
 	)
 
 	assert.NoError(t, err)
@@ -2153,8 +2218,8 @@ metadata:
 	}
 
 	_, _, _, err := cfg.renderResources(
-		t.Context(), ch, nil, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategySeparate,
+		t.Context(), ch, nil, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategySeparate, nil,
 	)
 
 	assert.NoError(t, err)
@@ -2187,8 +2252,8 @@ metadata:
 	}
 
 	_, _, _, err := cfg.renderResources(
-		t.Context(), ch, nil, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategySeparate,
+		t.Context(), ch, nil, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategySeparate, nil,
 	)
 
 	assert.NoError(t, err)
@@ -2221,8 +2286,8 @@ metadata:
 	}
 
 	hooks, manifestDoc, _, err := cfg.renderResources(
-		t.Context(), ch, nil, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategyNoHooks,
+		t.Context(), ch, nil, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategyNoHooks, nil,
 	)
 
 	assert.NoError(t, err)
@@ -2258,8 +2323,8 @@ metadata:
 	}
 
 	_, _, _, err := cfg.renderResources(
-		t.Context(), ch, nil, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategyNoHooks,
+		t.Context(), ch, nil, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategyNoHooks, nil,
 	)
 
 	assert.NoError(t, err)
@@ -2280,8 +2345,8 @@ metadata:
 	mockPR := &mockPostRenderer{}
 
 	_, _, _, err := cfg.renderResources(
-		t.Context(), ch, nil, "test-release", "", false, false, false,
-		mockPR, false, false, false, PostRenderStrategy("bogus"),
+		t.Context(), ch, nil, "test-release", "", "", false, false, false,
+		mockPR, false, false, false, PostRenderStrategy("bogus"), nil,
 	)
 
 	assert.Error(t, err)
@@ -2305,3 +2370,43 @@ func TestInteractWithServer(t *testing.T) {
 	assert.False(t, interactWithServer(DryRunClient))
 	assert.True(t, interactWithServer(DryRunServer))
 }
+
+func TestAdmissionDryRun(t *testing.T) {
+	denied := errors.New(`admission webhook "policy.example.com" denied the request`)
+	resource := pruneInfo("config", "spaced", nil, nil)
+
+	tests := []struct {
+		name      string
+		resources kube.ResourceList
+		hooks     bool
+		wantErr   bool
+	}{
+		{name: "nothing to submit"},
+		{name: "rejected resource", resources: kube.ResourceList{resource}, wantErr: true},
+		{name: "rejected hook", hooks: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := actionConfigFixture(t)
+			rel := releaseStub()
+			rel.Hooks = nil
+			failer := &kubefake.FailingKubeClient{
+				PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard},
+				CreateError:        denied,
+			}
+			if tt.hooks {
+				rel.Hooks = []*release.Hook{{Path: "templates/hook.yaml", Manifest: "kind: ConfigMap"}}
+				failer.DummyResources = kube.ResourceList{resource}
+			}
+			cfg.KubeClient = failer
+
+			err := cfg.admissionDryRun(rel, tt.resources, true, false)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, denied)
+				assert.ErrorContains(t, err, "rejected by server-side dry-run")
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
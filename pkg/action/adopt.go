@@ -0,0 +1,211 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/registry"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/secrets"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// Adopt is the action for adopting resources deployed without Helm into a
+// new release.
+//
+// It provides the implementation of 'helm adopt'.
+type Adopt struct {
+	cfg *Configuration
+
+	ChartPathOptions
+
+	// Namespace is the namespace of the release
+	Namespace string
+	// Description is the description of the adopted revision
+	Description string
+	LockTimeout time.Duration
+	// SkipSchemaValidation disables JSON schema validation of the values
+	SkipSchemaValidation bool
+	// SecretProviders resolve the references to secrets in the values
+	SecretProviders secrets.Providers
+	// SecretReferences limits the references resolved like
+	// Install.SecretReferences.
+	SecretReferences map[string]any
+	// AllowDrift adopts resources whose live state differs from the rendered
+	// chart. The differences are kept until the next upgrade of the release.
+	AllowDrift bool
+}
+
+// NewAdopt creates a new Adopt object with the given configuration.
+func NewAdopt(cfg *Configuration) *Adopt {
+	a := &Adopt{
+		cfg: cfg,
+	}
+	a.registryClient = cfg.RegistryClient
+
+	return a
+}
+
+// SetRegistryClient sets the registry client to use when fetching charts.
+func (a *Adopt) SetRegistryClient(client *registry.Client) {
+	a.registryClient = client
+}
+
+// Run renders the chart and matches the rendered resources with the live
+// resources of the cluster. If every resource exists, can be adopted and,
+// unless AllowDrift is set, matches the chart, the live resources are labeled
+// and annotated as belonging to the release and revision 1 of the release is
+// recorded. Apart from their metadata, the resources are not changed and no
+// hooks are run.
+func (a *Adopt) Run(ctx context.Context, name string, ch ci.Charter, vals map[string]any) (*release.Release, error) {
+	if err := a.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, fmt.Errorf("release name %q: %w", name, err)
+	}
+	patcher, ok := a.cfg.KubeClient.(kube.InterfacePatchMetadata)
+	if !ok {
+		return nil, errors.New("adopting resources is not supported by the Kubernetes client")
+	}
+
+	ctx, unlock, err := a.cfg.Releases.Lock(ctx, name, "adopt", a.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	history, err := a.cfg.Releases.History(name)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, err
+	}
+	if len(history) > 0 {
+		return nil, errNameInUse
+	}
+
+	rel, err := a.render(ctx, name, ch, vals)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := a.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build kubernetes objects from release manifest: %w", err)
+	}
+	if err := a.match(resources, rel); err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{appManagedByLabel: appManagedByHelm}
+	annotations := map[string]string{
+		helmReleaseNameAnnotation:      rel.Name,
+		helmReleaseNamespaceAnnotation: rel.Namespace,
+	}
+	if err := patcher.PatchMetadata(resources, labels, annotations); err != nil {
+		return nil, err
+	}
+
+	description := a.Description
+	if description == "" {
+		description = "Adoption complete"
+	}
+	rel.SetStatus(rcommon.StatusDeployed, description)
+	if err := a.cfg.Releases.Create(rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// render renders the chart as revision 1 of the release, without creating
+// any resources.
+func (a *Adopt) render(ctx context.Context, name string, ch ci.Charter, vals map[string]any) (*release.Release, error) {
+	inst := NewInstall(a.cfg)
+	inst.ReleaseName = name
+	inst.Namespace = a.Namespace
+	inst.DryRunStrategy = DryRunServer
+	// The resources exist: their ownership is checked when they are matched
+	inst.TakeOwnership = true
+	inst.DisableHooks = true
+	inst.SkipSchemaValidation = a.SkipSchemaValidation
+	inst.SecretProviders = a.SecretProviders
+	inst.SecretReferences = a.SecretReferences
+
+	reli, err := inst.RunWithContext(ctx, ch, vals)
+	if err != nil {
+		return nil, err
+	}
+	return releaserToV1Release(reli)
+}
+
+// match checks that every resource of the release exists in the cluster, is
+// not owned by another release or tool and, unless AllowDrift is set, that
+// its live state matches the rendered chart.
+func (a *Adopt) match(resources kube.ResourceList, rel *release.Release) error {
+	existing, err := adoptExisting(resources, rel.Name, rel.Namespace)
+	if err != nil {
+		return err
+	}
+	found := make(map[string]bool, len(existing))
+	for _, info := range existing {
+		found[resourceString(info)] = true
+	}
+	var missing []string
+	for _, info := range resources {
+		if !found[resourceString(info)] {
+			missing = append(missing, resourceString(info))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("resources of the chart are missing from the cluster: %s", strings.Join(missing, ", "))
+	}
+
+	if a.AllowDrift {
+		return nil
+	}
+	differ, ok := a.cfg.KubeClient.(kube.InterfaceDrift)
+	if !ok {
+		return errors.New("detecting drift is not supported by the Kubernetes client")
+	}
+	drift, err := differ.Drift(resources)
+	if err != nil {
+		return err
+	}
+	var drifted []string
+	for _, rd := range drift {
+		if len(rd.Fields) == 0 {
+			continue
+		}
+		paths := make([]string, 0, len(rd.Fields))
+		for _, f := range rd.Fields {
+			paths = append(paths, f.Path)
+		}
+		drifted = append(drifted, fmt.Sprintf("%s %q (%s)", rd.Kind, rd.Name, strings.Join(paths, ", ")))
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("live resources differ from the chart: %s", strings.Join(drifted, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+)
+
+func adoptAction(t *testing.T, resources kube.ResourceList) *Adopt {
+	t.Helper()
+	client := NewAdopt(actionConfigFixtureWithDummyResources(t, resources))
+	client.Namespace = "spaced"
+	return client
+}
+
+func TestAdopt(t *testing.T) {
+	client := adoptAction(t, kube.ResourceList{newDeploymentWithOwner("web", "spaced", nil, nil)})
+
+	rel, err := client.Run(t.Context(), "adopted", buildChart(), map[string]any{"replicas": 2})
+	require.NoError(t, err)
+	assert.Equal(t, 1, rel.Version)
+
+	reli, err := client.cfg.Releases.Get("adopted", 1)
+	require.NoError(t, err)
+	stored, err := releaserToV1Release(reli)
+	require.NoError(t, err)
+	assert.Equal(t, rcommon.StatusDeployed, stored.Info.Status)
+	assert.Equal(t, "Adoption complete", stored.Info.Description)
+	assert.Equal(t, "spaced", stored.Namespace)
+	assert.Equal(t, map[string]any{"replicas": 2}, stored.Config)
+
+	_, err = client.Run(t.Context(), "adopted", buildChart(), nil)
+	assert.ErrorIs(t, err, errNameInUse)
+}
+
+func TestAdopt_Missing(t *testing.T) {
+	client := adoptAction(t, kube.ResourceList{newMissingDeployment("web", "spaced")})
+
+	_, err := client.Run(t.Context(), "adopted", buildChart(), nil)
+	assert.ErrorContains(t, err, `resources of the chart are missing from the cluster: Deployment "web" in namespace "spaced"`)
+	_, err = client.cfg.Releases.Get("adopted", 1)
+	assert.Error(t, err)
+}
+
+func TestAdopt_OwnedByAnotherRelease(t *testing.T) {
+	annotations := map[string]string{
+		helmReleaseNameAnnotation:      "other",
+		helmReleaseNamespaceAnnotation: "spaced",
+	}
+	client := adoptAction(t, kube.ResourceList{newDeploymentWithOwner("web", "spaced", nil, annotations)})
+
+	_, err := client.Run(t.Context(), "adopted", buildChart(), nil)
+	assert.ErrorContains(t, err, "cannot be adopted by the current release")
+}
+
+func TestAdopt_Drift(t *testing.T) {
+	client := adoptAction(t, kube.ResourceList{newDeploymentWithOwner("web", "spaced", nil, nil)})
+	client.cfg.KubeClient.(*kubefake.FailingKubeClient).ResourceDrift = []kube.ResourceDrift{{
+		Kind:      "Deployment",
+		Namespace: "spaced",
+		Name:      "web",
+		Fields:    []kube.FieldDrift{{Path: "spec.replicas", Type: kube.FieldModified, Desired: 1, Live: 3}},
+	}}
+
+	_, err := client.Run(t.Context(), "adopted", buildChart(), nil)
+	assert.ErrorContains(t, err, `live resources differ from the chart: Deployment "web" (spec.replicas)`)
+
+	client.AllowDrift = true
+	_, err = client.Run(t.Context(), "adopted", buildChart(), nil)
+	assert.NoError(t, err)
+}
+
+func TestAdopt_PatchMetadataError(t *testing.T) {
+	client := adoptAction(t, kube.ResourceList{newDeploymentWithOwner("web", "spaced", nil, nil)})
+	client.cfg.KubeClient.(*kubefake.FailingKubeClient).PatchMetadataError = assert.AnError
+
+	_, err := client.Run(t.Context(), "adopted", buildChart(), nil)
+	assert.ErrorIs(t, err, assert.AnError)
+	_, err = client.cfg.Releases.Get("adopted", 1)
+	assert.Error(t, err)
+}
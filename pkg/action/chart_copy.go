@@ -0,0 +1,56 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// ChartCopy is the action for copying a chart between OCI registries.
+//
+// It provides the implementation of 'helm chart copy'.
+type ChartCopy struct {
+	cfg *Configuration
+
+	// SkipReferrers only copies the chart, without the signatures, SBOMs and
+	// other artifacts attached to it
+	SkipReferrers bool
+}
+
+// NewChartCopy creates a new ChartCopy object with the given configuration.
+func NewChartCopy(cfg *Configuration) *ChartCopy {
+	return &ChartCopy{
+		cfg: cfg,
+	}
+}
+
+// Run copies the chart at the OCI reference src to dst.
+func (a *ChartCopy) Run(src, dst string) (*registry.CopyResult, error) {
+	for _, ref := range []string{src, dst} {
+		if !registry.IsOCI(ref) {
+			return nil, fmt.Errorf("%s is not an OCI reference", ref)
+		}
+	}
+	return a.cfg.RegistryClient.Copy(
+		strings.TrimPrefix(src, registry.OCIScheme+"://"),
+		strings.TrimPrefix(dst, registry.OCIScheme+"://"),
+		registry.CopyOptWithReferrers(!a.SkipReferrers),
+	)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/internal/diff"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+// ChartDiff is the action for comparing two charts.
+//
+// It provides the implementation of 'helm chart diff'.
+type ChartDiff struct {
+	cfg *Configuration
+
+	// Manifests compares the manifests rendered from the charts instead of
+	// their contents
+	Manifests bool
+	// Namespace is the namespace the manifests are rendered for
+	Namespace string
+	// Context is the number of unchanged lines shown around each change
+	Context int
+	// NoColor disables colorized output
+	NoColor bool
+}
+
+// ChartDiffResult is the difference between two charts.
+type ChartDiffResult struct {
+	// Chart is the difference of the contents of the charts, unless the
+	// manifests are compared
+	Chart *chartutil.ChartDiff `json:"chart,omitempty"`
+	// Manifests are the resources of the rendered manifests that were added,
+	// removed or modified, if the manifests are compared
+	Manifests []diff.ResourceDiff `json:"manifests,omitempty"`
+}
+
+// Changed reports whether the charts differ.
+func (r *ChartDiffResult) Changed() bool {
+	if r.Chart != nil {
+		return r.Chart.Changed()
+	}
+	return len(r.Manifests) > 0
+}
+
+// NewChartDiff creates a new ChartDiff object with the given configuration.
+func NewChartDiff(cfg *Configuration) *ChartDiff {
+	return &ChartDiff{
+		cfg:     cfg,
+		Context: diff.DefaultContext,
+	}
+}
+
+// Run compares the charts, archives or directories, at the given paths. If
+// the manifests are compared, they are rendered with the given values, the
+// way 'helm template' does, and the values of Secrets are redacted.
+func (d *ChartDiff) Run(oldPath, newPath string, vals map[string]any) (*ChartDiffResult, error) {
+	oldChart, err := loadChartV2(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newChart, err := loadChartV2(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.Manifests {
+		chartDiff, err := chartutil.DiffCharts(oldChart, newChart)
+		if err != nil {
+			return nil, err
+		}
+		return &ChartDiffResult{Chart: chartDiff}, nil
+	}
+
+	oldManifest, err := d.render(oldChart, vals)
+	if err != nil {
+		return nil, fmt.Errorf("rendering %s: %w", oldPath, err)
+	}
+	newManifest, err := d.render(newChart, vals)
+	if err != nil {
+		return nil, fmt.Errorf("rendering %s: %w", newPath, err)
+	}
+	return &ChartDiffResult{Manifests: diff.Manifests(oldManifest, newManifest)}, nil
+}
+
+// Write prints the differences of the charts as unified diffs.
+func (d *ChartDiff) Write(out io.Writer, result *ChartDiffResult) error {
+	opts := diff.Options{Context: d.Context, NoColor: d.NoColor}
+	if result.Chart == nil {
+		return diff.Write(out, result.Manifests, opts)
+	}
+
+	oldMetadata, newMetadata, err := metadataText(result.Chart.Metadata)
+	if err != nil {
+		return err
+	}
+	if err := diff.Text(out, "Chart.yaml has been modified", oldMetadata, newMetadata, opts); err != nil {
+		return err
+	}
+	var files []diff.ResourceDiff
+	for _, f := range result.Chart.Files {
+		if f.Binary {
+			if _, err := fmt.Fprintf(out, "Binary file %s has been %s\n", f.Name, f.Change); err != nil {
+				return err
+			}
+			continue
+		}
+		files = append(files, diff.ResourceDiff{Key: f.Name, Change: diff.Change(f.Change), Old: f.Old, New: f.New})
+	}
+	return diff.Write(out, files, opts)
+}
+
+// render renders the manifests of a chart without a cluster.
+func (d *ChartDiff) render(ch *chart.Chart, vals map[string]any) (string, error) {
+	inst := NewInstall(d.cfg)
+	inst.ReleaseName = "release-name"
+	inst.Namespace = d.Namespace
+	inst.DryRunStrategy = DryRunClient
+	inst.Replace = true // Skip the name check
+	inst.IncludeCRDs = true
+
+	reli, err := inst.Run(ch, vals)
+	if err != nil {
+		return "", err
+	}
+	rel, err := releaserToV1Release(reli)
+	if err != nil {
+		return "", err
+	}
+	return rel.Manifest, nil
+}
+
+// loadChartV2 loads a chart, which must have the apiVersion v1 or v2.
+func loadChartV2(path string) (*chart.Chart, error) {
+	chrt, err := loader.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	switch c := chrt.(type) {
+	case *chart.Chart:
+		return c, nil
+	case chart.Chart:
+		return &c, nil
+	default:
+		return nil, errors.New("invalid chart apiVersion")
+	}
+}
+
+// metadataText returns the changed fields of two Chart.yaml files as YAML.
+func metadataText(changes []chartutil.MetadataChange) (string, string, error) {
+	var oldText, newText strings.Builder
+	for _, c := range changes {
+		if c.Change != chartutil.DiffAdded {
+			data, err := yaml.Marshal(map[string]any{c.Field: c.Old})
+			if err != nil {
+				return "", "", err
+			}
+			oldText.Write(data)
+		}
+		if c.Change != chartutil.DiffRemoved {
+			data, err := yaml.Marshal(map[string]any{c.Field: c.New})
+			if err != nil {
+				return "", "", err
+			}
+			newText.Write(data)
+		}
+	}
+	return oldText.String(), newText.String(), nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestChartDiff(t *testing.T) {
+	client := NewChartDiff(actionConfigFixture(t))
+	client.NoColor = true
+
+	result, err := client.Run("testdata/charts/compressedchart-0.1.0.tgz", "testdata/charts/compressedchart-0.2.0.tgz", nil)
+	require.NoError(t, err)
+	require.NotNil(t, result.Chart)
+	assert.True(t, result.Changed())
+	assert.Equal(t, []chartutil.MetadataChange{
+		{Field: "version", Change: chartutil.DiffModified, Old: "0.1.0", New: "0.2.0"},
+	}, result.Chart.Metadata)
+
+	var out bytes.Buffer
+	require.NoError(t, client.Write(&out, result))
+	assert.Contains(t, out.String(), "Chart.yaml has been modified:\n@@ -1 +1 @@\n-version: 0.1.0\n+version: 0.2.0\n")
+
+	result, err = client.Run("testdata/charts/compressedchart-0.1.0.tgz", "testdata/charts/compressedchart-0.1.0.tgz", nil)
+	require.NoError(t, err)
+	assert.False(t, result.Changed())
+
+	_, err = client.Run("testdata/charts/compressedchart-0.1.0.tgz", "testdata/charts/no-such-chart.tgz", nil)
+	assert.Error(t, err)
+}
+
+func TestChartDiffManifests(t *testing.T) {
+	client := NewChartDiff(actionConfigFixture(t))
+	client.Manifests = true
+
+	result, err := client.Run("testdata/charts/chart-with-schema", "testdata/charts/chart-with-schema", nil)
+	require.NoError(t, err)
+	assert.Nil(t, result.Chart)
+	assert.False(t, result.Changed())
+}
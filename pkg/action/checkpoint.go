@@ -0,0 +1,151 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// isResumable returns whether rel is an install that did not complete and
+// recorded a checkpoint. An install left pending, for example because Helm was
+// interrupted, can be resumed as well.
+func isResumable(rel *release.Release) bool {
+	st := rel.Info.Status
+	return rel.Info.Checkpoint != nil && (st == rcommon.StatusFailed || st == rcommon.StatusPendingInstall)
+}
+
+// resumableRelease returns the latest revision of the release if it can be
+// resumed, or nil if the release does not exist.
+func (i *Install) resumableRelease() (*release.Release, error) {
+	h, err := i.cfg.Releases.History(i.ReleaseName)
+	if err != nil || len(h) < 1 {
+		return nil, nil
+	}
+	hl, err := releaseListToV1List(h)
+	if err != nil {
+		return nil, err
+	}
+	releaseutil.Reverse(hl, releaseutil.SortByRevision)
+	if !isResumable(hl[0]) {
+		return nil, fmt.Errorf("release %q has no failed install to resume", i.ReleaseName)
+	}
+	return hl[0], nil
+}
+
+// infoKind returns the kind of a resource, or an empty string if the resource
+// has no object.
+func infoKind(info *resource.Info) string {
+	if info.Object == nil {
+		return ""
+	}
+	return info.Object.GetObjectKind().GroupVersionKind().Kind
+}
+
+// checkpointKey identifies a resource in an install checkpoint.
+func checkpointKey(info *resource.Info) string {
+	return fmt.Sprintf("%s/%s/%s", infoKind(info), info.Namespace, info.Name)
+}
+
+// kindWaves splits resources into runs of consecutive resources of the same
+// kind. As resources are sorted in install order, the waves are applied one
+// after the other, like the Kubernetes client does.
+func kindWaves(resources kube.ResourceList) []kube.ResourceList {
+	var waves []kube.ResourceList
+	var kind string
+	for n, info := range resources {
+		k := infoKind(info)
+		if n == 0 || k != kind {
+			waves = append(waves, kube.ResourceList{})
+			kind = k
+		}
+		waves[len(waves)-1].Append(info)
+	}
+	return waves
+}
+
+// applyWithCheckpoints applies resources wave by wave and records the applied
+// resources in the release checkpoint after each wave. Resources already
+// recorded in the checkpoint, by the install being resumed, are skipped.
+// Resources of toBeAdopted are updated, all others are created.
+func (i *Install) applyWithCheckpoints(rel *release.Release, toBeAdopted, resources kube.ResourceList) error {
+	for _, wave := range kindWaves(resources) {
+		if rel.Info.Checkpoint != nil {
+			wave = wave.Filter(func(info *resource.Info) bool {
+				return !slices.Contains(rel.Info.Checkpoint.Applied, checkpointKey(info))
+			})
+			if len(wave) == 0 {
+				continue
+			}
+		}
+		var existing kube.ResourceList
+		if len(toBeAdopted) > 0 {
+			existing = wave.Intersect(toBeAdopted)
+		}
+
+		// Adopted resources are updated, and the other resources of the wave
+		// are created with the given parallelism
+		if len(existing) > 0 {
+			updateThreeWayMergeForUnstructured := (i.TakeOwnership || i.AdoptExisting) && !i.ServerSideApply // Use three-way merge when taking ownership (and not using server-side apply)
+			if _, err := i.cfg.KubeClient.Update(
+				existing,
+				existing,
+				kube.ClientUpdateOptionForceReplace(i.ForceReplace),
+				kube.ClientUpdateOptionServerSideApply(i.ServerSideApply, i.ForceConflicts),
+				kube.ClientUpdateOptionThreeWayMergeForUnstructured(updateThreeWayMergeForUnstructured),
+				kube.ClientUpdateOptionUpgradeClientSideFieldManager(true)); err != nil {
+				return err
+			}
+		}
+		if created := wave.Difference(existing); len(created) > 0 {
+			if _, err := i.cfg.KubeClient.Create(
+				created,
+				kube.ClientCreateOptionServerSideApply(i.ServerSideApply, i.ForceConflicts),
+				kube.ClientCreateOptionParallelism(i.Parallelism)); err != nil {
+				return err
+			}
+		}
+
+		if rel.Info.Checkpoint == nil {
+			continue
+		}
+		for _, info := range wave {
+			if key := checkpointKey(info); !slices.Contains(rel.Info.Checkpoint.Applied, key) {
+				rel.Info.Checkpoint.Applied = append(rel.Info.Checkpoint.Applied, key)
+			}
+		}
+		i.recordCheckpoint(rel)
+	}
+	return nil
+}
+
+// recordCheckpoint stores the progress of the install. A checkpoint that cannot
+// be stored only means that a resumed install redoes more work, so the error is
+// logged rather than failing the install.
+func (i *Install) recordCheckpoint(rel *release.Release) {
+	if err := i.recordRelease(rel); err != nil {
+		i.cfg.Logger().Warn("failed to record install checkpoint", slog.Any("error", err))
+	}
+}
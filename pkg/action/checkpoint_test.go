@@ -0,0 +1,170 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func checkpointInfo(kind, name string) *resource.Info {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind(kind)
+	obj.SetName(name)
+	return &resource.Info{
+		Name:      name,
+		Namespace: "spaced",
+		Object:    obj,
+		Mapping:   &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: kind}},
+	}
+}
+
+func TestKindWaves(t *testing.T) {
+	a := checkpointInfo("ConfigMap", "a")
+	b := checkpointInfo("ConfigMap", "b")
+	c := checkpointInfo("Service", "c")
+	d := checkpointInfo("ConfigMap", "d")
+
+	assert.Empty(t, kindWaves(nil))
+	assert.Equal(t, []kube.ResourceList{{a, b}, {c}, {d}}, kindWaves(kube.ResourceList{a, b, c, d}))
+}
+
+func TestApplyWithCheckpoints(t *testing.T) {
+	instAction := installAction(t)
+	rel := releaseStub()
+	rel.Info.Checkpoint = &release.Checkpoint{}
+	require.NoError(t, instAction.cfg.Releases.Create(rel))
+
+	resources := kube.ResourceList{
+		checkpointInfo("ConfigMap", "a"),
+		checkpointInfo("ConfigMap", "b"),
+		checkpointInfo("Service", "c"),
+	}
+	require.NoError(t, instAction.applyWithCheckpoints(rel, nil, resources))
+
+	stored, err := instAction.cfg.Releases.Get(rel.Name, rel.Version)
+	require.NoError(t, err)
+	storedRel, err := releaserToV1Release(stored)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ConfigMap/spaced/a", "ConfigMap/spaced/b", "Service/spaced/c"}, storedRel.Info.Checkpoint.Applied)
+}
+
+func TestApplyWithCheckpointsSkipsApplied(t *testing.T) {
+	instAction := installAction(t)
+	var out bytes.Buffer
+	instAction.cfg.KubeClient.(*kubefake.FailingKubeClient).Out = &out
+	rel := releaseStub()
+	rel.Info.Checkpoint = &release.Checkpoint{Applied: []string{"ConfigMap/spaced/a", "ConfigMap/spaced/b"}}
+	require.NoError(t, instAction.cfg.Releases.Create(rel))
+
+	resources := kube.ResourceList{
+		checkpointInfo("ConfigMap", "a"),
+		checkpointInfo("ConfigMap", "b"),
+		checkpointInfo("Service", "c"),
+	}
+	require.NoError(t, instAction.applyWithCheckpoints(rel, nil, resources))
+
+	assert.Equal(t, resources[2].String()+"\n", out.String())
+	assert.Equal(t, []string{"ConfigMap/spaced/a", "ConfigMap/spaced/b", "Service/spaced/c"}, rel.Info.Checkpoint.Applied)
+}
+
+func TestApplyWithCheckpointsAdoption(t *testing.T) {
+	instAction := installAction(t)
+	var out bytes.Buffer
+	failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.Out = &out
+	failer.CreateError = errors.New("create failed")
+	rel := releaseStub()
+
+	// Adopted resources are updated, the others of their wave are created
+	adopted := checkpointInfo("ConfigMap", "a")
+	resources := kube.ResourceList{adopted, checkpointInfo("ConfigMap", "b")}
+	err := instAction.applyWithCheckpoints(rel, kube.ResourceList{adopted}, resources)
+	assert.EqualError(t, err, "create failed")
+	assert.Equal(t, adopted.String()+"\n", out.String())
+}
+
+func TestInstallRelease_NoCheckpointWithoutResume(t *testing.T) {
+	instAction := installAction(t)
+	instAction.cfg.KubeClient.(*kubefake.FailingKubeClient).WaitError = errors.New("deployment not ready")
+
+	resi, err := instAction.Run(buildChart(), map[string]any{})
+	require.Error(t, err)
+	res, err := releaserToV1Release(resi)
+	require.NoError(t, err)
+	assert.Nil(t, res.Info.Checkpoint)
+}
+
+func TestInstallRelease_Resume(t *testing.T) {
+	instAction := installAction(t)
+	failer := instAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.WaitError = errors.New("deployment not ready")
+	instAction.Resume = true
+
+	resi, err := instAction.Run(buildChart(), map[string]any{})
+	require.Error(t, err)
+	res, err := releaserToV1Release(resi)
+	require.NoError(t, err)
+	assert.Equal(t, rcommon.StatusFailed, res.Info.Status)
+	require.NotNil(t, res.Info.Checkpoint)
+	assert.True(t, res.Info.Checkpoint.PreInstallHooks)
+
+	// Without --resume the name is still in use
+	instAction.Resume = false
+	_, err = instAction.Run(buildChart(), map[string]any{})
+	assert.ErrorContains(t, err, "cannot reuse a name that is still in use")
+
+	failer.WaitError = nil
+	instAction.Resume = true
+	resi, err = instAction.Run(buildChart(), map[string]any{})
+	require.NoError(t, err)
+	res, err = releaserToV1Release(resi)
+	require.NoError(t, err)
+	assert.Equal(t, rcommon.StatusDeployed, res.Info.Status)
+	assert.Equal(t, 1, res.Version)
+	assert.Nil(t, res.Info.Checkpoint)
+
+	// A deployed release cannot be resumed
+	_, err = instAction.Run(buildChart(), map[string]any{})
+	assert.ErrorContains(t, err, "cannot reuse a name that is still in use")
+}
+
+func TestInstallRelease_ResumeWithoutRelease(t *testing.T) {
+	instAction := installAction(t)
+	instAction.Resume = true
+
+	resi, err := instAction.Run(buildChart(), map[string]any{})
+	require.NoError(t, err)
+	res, err := releaserToV1Release(resi)
+	require.NoError(t, err)
+	assert.Equal(t, rcommon.StatusDeployed, res.Info.Status)
+	assert.Equal(t, 1, res.Version)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/vcs"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// gitStarterPrefix prefixes the URLs of the git repositories of starters.
+const gitStarterPrefix = "git+"
+
+// Create is the action for creating a new chart.
+//
+// It provides the implementation of 'helm create'.
+type Create struct {
+	Settings *cli.EnvSettings // TODO: refactor this out of pkg/action
+
+	// Starter is the starter the chart is scaffolded from: the name of a
+	// starter in StarterDir, the path of a starter, the OCI reference of a
+	// starter chart, e.g. oci://example.com/starters/web:1.0.0, or the git
+	// repository of a starter, e.g.
+	// git+https://example.com/starters.git//web?ref=v1.0.0.
+	Starter string
+	// StarterDir is the directory of the starters referenced by name.
+	StarterDir string
+	// StarterValues are the values of the parameters of the starter.
+	StarterValues map[string]string
+	// Prompt asks for the values of the parameters of the starter that
+	// StarterValues do not set. Without it, they take their defaults.
+	Prompt func(chartutil.StarterParameter) (string, error)
+	// Type is the built-in scaffold of the chart, when no starter is set.
+	Type string
+
+	cfg *Configuration
+}
+
+// NewCreate creates a new Create object with the given configuration.
+func NewCreate(cfg *Configuration) *Create {
+	return &Create{cfg: cfg}
+}
+
+// Run creates the chart at the given path, and returns its directory.
+func (c *Create) Run(path string) (string, error) {
+	name := filepath.Base(path)
+	if c.Starter == "" {
+		if len(c.StarterValues) > 0 {
+			return "", errors.New("starter values need a starter")
+		}
+		return chartutil.CreateScaffold(name, filepath.Dir(path), c.Type)
+	}
+	if c.Type != "" {
+		return "", errors.New("a chart is created either from a starter or from a type of scaffold, not both")
+	}
+
+	starter, cleanup, err := c.ResolveStarter()
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	params, err := c.starterParameters(starter)
+	if err != nil {
+		return "", err
+	}
+	cfile := &chart.Metadata{
+		Name:        name,
+		Description: "A Helm chart for Kubernetes",
+		Type:        "application",
+		Version:     "0.1.0",
+		AppVersion:  "0.1.0",
+		APIVersion:  chart.APIVersionV2,
+	}
+	if err := chartutil.CreateFromWithParameters(cfile, filepath.Dir(path), starter, params); err != nil {
+		return "", err
+	}
+	return filepath.Abs(path)
+}
+
+// starterParameters returns the values of the parameters of a starter.
+func (c *Create) starterParameters(starter string) (map[string]string, error) {
+	declared, err := chartutil.LoadStarterParameters(starter)
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]string, len(declared))
+	for _, p := range declared {
+		if value, ok := c.StarterValues[p.Name]; ok {
+			params[p.Name] = value
+			continue
+		}
+		if c.Prompt == nil {
+			continue
+		}
+		value, err := c.Prompt(p)
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			params[p.Name] = value
+		}
+	}
+	for name, value := range c.StarterValues {
+		if _, ok := params[name]; !ok {
+			// Left for CreateFromWithParameters to reject.
+			params[name] = value
+		}
+	}
+	return params, nil
+}
+
+// ResolveStarter returns the local path of the starter, fetching remote
+// starters into a temporary directory removed by the returned function.
+func (c *Create) ResolveStarter() (string, func(), error) {
+	noop := func() {}
+	switch {
+	case registry.IsOCI(c.Starter):
+		return c.pullStarter()
+	case isGitStarter(c.Starter):
+		return cloneStarter(c.Starter)
+	case filepath.IsAbs(c.Starter):
+		// If path is absolute, we don't want to prefix it with helm starters folder
+		return c.Starter, noop, nil
+	}
+	return filepath.Join(c.StarterDir, c.Starter), noop, nil
+}
+
+// pullStarter pulls the starter chart from an OCI registry.
+func (c *Create) pullStarter() (string, func(), error) {
+	dir, err := os.MkdirTemp("", "helm-starter-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	pull := NewPull(WithConfig(c.cfg))
+	pull.Settings = c.Settings
+	pull.DestDir = dir
+	if _, err := pull.Run(c.Starter); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("unable to pull starter %s: %w", c.Starter, err)
+	}
+	archives, err := filepath.Glob(filepath.Join(dir, "*.tgz"))
+	if err != nil || len(archives) != 1 {
+		cleanup()
+		return "", nil, fmt.Errorf("unable to pull starter %s: no chart archive", c.Starter)
+	}
+	return archives[0], cleanup, nil
+}
+
+// isGitStarter returns whether a starter is in a git repository.
+func isGitStarter(starter string) bool {
+	return strings.HasPrefix(starter, gitStarterPrefix)
+}
+
+// parseGitStarter splits the reference of a starter in a git repository,
+// git+URL[//DIR][?ref=REF], into the URL of the repository, the directory of
+// the starter in the repository, and the branch or tag to check out.
+func parseGitStarter(starter string) (repo, dir, ref string) {
+	repo = strings.TrimPrefix(starter, gitStarterPrefix)
+	if i := strings.LastIndex(repo, "?ref="); i >= 0 {
+		repo, ref = repo[:i], repo[i+len("?ref="):]
+	}
+	start := 0
+	if i := strings.Index(repo, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	if i := strings.Index(repo[start:], "//"); i >= 0 {
+		repo, dir = repo[:start+i], repo[start+i+len("//"):]
+	}
+	return repo, dir, ref
+}
+
+// cloneStarter clones the git repository of a starter.
+func cloneStarter(starter string) (string, func(), error) {
+	remote, dir, ref := parseGitStarter(starter)
+	tmp, err := os.MkdirTemp("", "helm-starter-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+
+	local := filepath.Join(tmp, "repo")
+	repo, err := vcs.NewGitRepo(remote, local)
+	if err == nil {
+		err = repo.Get()
+	}
+	if err == nil && ref != "" {
+		err = repo.UpdateVersion(ref)
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("unable to clone starter %s: %w", starter, err)
+	}
+
+	path := filepath.Join(local, filepath.FromSlash(dir))
+	if rel, err := filepath.Rel(local, path); err != nil || strings.HasPrefix(rel, "..") {
+		cleanup()
+		return "", nil, fmt.Errorf("invalid starter directory %q", dir)
+	}
+	return path, cleanup, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestParseGitStarter(t *testing.T) {
+	tests := []struct {
+		starter, repo, dir, ref string
+	}{
+		{"git+https://example.com/starters.git", "https://example.com/starters.git", "", ""},
+		{"git+https://example.com/starters.git//web", "https://example.com/starters.git", "web", ""},
+		{"git+https://example.com/starters.git?ref=v1.0.0", "https://example.com/starters.git", "", "v1.0.0"},
+		{"git+https://example.com/starters.git//charts/web?ref=main", "https://example.com/starters.git", "charts/web", "main"},
+		{"git+git@example.com:starters.git//web", "git@example.com:starters.git", "web", ""},
+	}
+	for _, tt := range tests {
+		repo, dir, ref := parseGitStarter(tt.starter)
+		assert.Equal(t, tt.repo, repo, tt.starter)
+		assert.Equal(t, tt.dir, dir, tt.starter)
+		assert.Equal(t, tt.ref, ref, tt.starter)
+	}
+}
+
+func TestCreateRunType(t *testing.T) {
+	client := NewCreate(actionConfigFixture(t))
+	client.Type = chartutil.ScaffoldCronJob
+
+	dir, err := client.Run(filepath.Join(t.TempDir(), "foo"))
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, chartutil.CronJobName))
+
+	client.Starter = "web"
+	_, err = client.Run(filepath.Join(t.TempDir(), "foo"))
+	assert.Error(t, err)
+}
+
+func TestCreateRunStarterParameters(t *testing.T) {
+	starterDir := t.TempDir()
+	starter := filepath.Join(starterDir, "web")
+	for name, content := range map[string]string{
+		chartutil.ChartfileName:   "apiVersion: v2\nname: web\nversion: 0.1.0\n",
+		chartutil.ValuesfileName:  "image: <IMAGE>\nreplicas: <REPLICAS>\n",
+		chartutil.StarterfileName: "parameters:\n  - name: IMAGE\n  - name: REPLICAS\n    default: \"1\"\n",
+	} {
+		require.NoError(t, os.MkdirAll(starter, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(starter, name), []byte(content), 0o644))
+	}
+
+	client := NewCreate(actionConfigFixture(t))
+	client.Starter = "web"
+	client.StarterDir = starterDir
+	client.StarterValues = map[string]string{"IMAGE": "nginx"}
+	var prompted []string
+	client.Prompt = func(p chartutil.StarterParameter) (string, error) {
+		prompted = append(prompted, p.Name)
+		return "3", nil
+	}
+
+	dir, err := client.Run(filepath.Join(t.TempDir(), "foo"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"REPLICAS"}, prompted)
+	values, err := os.ReadFile(filepath.Join(dir, chartutil.ValuesfileName))
+	require.NoError(t, err)
+	assert.Equal(t, "image: nginx\nreplicas: 3\n", string(values))
+
+	client.Starter = ""
+	_, err = client.Run(filepath.Join(t.TempDir(), "foo"))
+	assert.EqualError(t, err, "starter values need a starter")
+}
@@ -19,13 +19,16 @@ package action
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/gosuri/uitable"
 
+	"helm.sh/helm/v4/internal/resolver"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
 )
@@ -45,12 +48,15 @@ type Dependency struct {
 	CaFile                string
 	InsecureSkipTLSVerify bool
 	PlainHTTP             bool
+	// Parallel is the number of charts downloaded at the same time
+	Parallel int
 }
 
 // NewDependency creates a new Dependency object with the given configuration.
 func NewDependency() *Dependency {
 	return &Dependency{
 		ColumnWidth: 80,
+		Parallel:    1,
 	}
 }
 
@@ -72,6 +78,75 @@ func (d *Dependency) List(chartpath string, out io.Writer) error {
 	return nil
 }
 
+// Tree executes 'helm dependency tree'.
+//
+// It returns the dependency tree of the chart, including the dependencies of
+// its subcharts, along with the charts included at different versions or under
+// different names.
+func (d *Dependency) Tree(chartpath string) (*resolver.Tree, error) {
+	c, err := loader.Load(chartpath)
+	if err != nil {
+		return nil, err
+	}
+	return resolver.NewTree(c), nil
+}
+
+// LinkLocalDependencies loads the dependencies of a chart that have a
+// "file://" repository from their directory, in place of the subcharts of the
+// same name in its charts/ directory.
+//
+// This renders charts developed together with their current sources, without
+// running 'helm dependency update' after every change. The dependencies of the
+// loaded charts are linked the same way. The charts on disk are not changed.
+func LinkLocalDependencies(c *chart.Chart, chartpath string) error {
+	return linkLocalDependencies(c, chartpath, nil)
+}
+
+func linkLocalDependencies(c *chart.Chart, chartpath string, parents []string) error {
+	abs, err := filepath.Abs(chartpath)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(parents, abs) {
+		return fmt.Errorf("chart %s depends on itself through local dependencies", abs)
+	}
+	parents = append(parents, abs)
+
+	linked := make(map[string]bool)
+	for _, d := range c.Metadata.Dependencies {
+		// Aliases of a dependency share its subchart.
+		if !strings.HasPrefix(d.Repository, "file://") || linked[d.Name] {
+			continue
+		}
+		depPath, err := resolver.GetLocalPath(d.Repository, chartpath)
+		if err != nil {
+			return err
+		}
+		sub, err := loader.LoadDir(depPath)
+		if err != nil {
+			return fmt.Errorf("failed to load local dependency %s: %w", d.Name, err)
+		}
+		if sub.Name() != d.Name {
+			return fmt.Errorf("local dependency %s at %s is a chart named %s", d.Name, depPath, sub.Name())
+		}
+		if constraint, err := semver.NewConstraint(d.Version); err == nil {
+			if v, err := semver.NewVersion(sub.Metadata.Version); err == nil && !constraint.Check(v) {
+				slog.Warn("local dependency does not satisfy its version constraint", "chart", d.Name, "version", sub.Metadata.Version, "constraint", d.Version)
+			}
+		}
+		if err := linkLocalDependencies(sub, depPath, parents); err != nil {
+			return err
+		}
+
+		deps := slices.DeleteFunc(slices.Clone(c.Dependencies()), func(s *chart.Chart) bool {
+			return s.Name() == d.Name
+		})
+		c.SetDependencies(append(deps, sub)...)
+		linked[d.Name] = true
+	}
+	return nil
+}
+
 // dependencyStatus returns a string describing the status of a dependency viz a viz the parent chart.
 func (d *Dependency) dependencyStatus(chartpath string, dep *chart.Dependency, parent *chart.Chart) string {
 	filename := fmt.Sprintf("%s-%s.tgz", dep.Name, "*")
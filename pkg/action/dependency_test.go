@@ -26,6 +26,7 @@ import (
 
 	"helm.sh/helm/v4/internal/test"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 )
 
@@ -150,3 +151,63 @@ func TestStatArchiveForStatus(t *testing.T) {
 	}
 	is.Equal("ok", statArchiveForStatus(where, dep))
 }
+
+func TestLinkLocalDependencies(t *testing.T) {
+	dir := t.TempDir()
+	save := func(c *chart.Chart) {
+		t.Helper()
+		if err := chartutil.SaveDir(c, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newChart := func(name, version string, deps ...*chart.Dependency) *chart.Chart {
+		return &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: name, Version: version, Dependencies: deps}}
+	}
+
+	// The parent has an outdated copy of sub in charts/, and sub depends on
+	// a chart that is only in its directory.
+	parent := newChart("parent", "0.1.0",
+		&chart.Dependency{Name: "sub", Alias: "one", Version: "^0.1.0", Repository: "file://../sub"},
+		&chart.Dependency{Name: "sub", Alias: "two", Version: "^0.1.0", Repository: "file://../sub"},
+	)
+	parent.SetDependencies(newChart("sub", "0.1.0"))
+	save(parent)
+	save(newChart("sub", "0.1.1", &chart.Dependency{Name: "leaf", Version: "1.0.0", Repository: "file://../leaf"}))
+	save(newChart("leaf", "1.0.0"))
+
+	c, err := loader.LoadDir(filepath.Join(dir, "parent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkLocalDependencies(c, filepath.Join(dir, "parent")); err != nil {
+		t.Fatal(err)
+	}
+	deps := c.Dependencies()
+	if len(deps) != 1 {
+		t.Fatalf("expected 1 subchart, got %d", len(deps))
+	}
+	assert.Equal(t, "0.1.1", deps[0].Metadata.Version)
+	assert.Same(t, c, deps[0].Parent())
+	if len(deps[0].Dependencies()) != 1 {
+		t.Fatalf("expected the dependency of sub to be linked")
+	}
+	assert.Equal(t, "leaf", deps[0].Dependencies()[0].Name())
+
+	// A chart depending on itself.
+	save(newChart("cycle", "0.1.0", &chart.Dependency{Name: "cycle", Version: "0.1.0", Repository: "file://."}))
+	c, err = loader.LoadDir(filepath.Join(dir, "cycle"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = LinkLocalDependencies(c, filepath.Join(dir, "cycle"))
+	assert.ErrorContains(t, err, "depends on itself")
+
+	// A dependency named differently than the chart in its directory.
+	save(newChart("misnamed", "0.1.0", &chart.Dependency{Name: "other", Version: "1.0.0", Repository: "file://../leaf"}))
+	c, err = loader.LoadDir(filepath.Join(dir, "misnamed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = LinkLocalDependencies(c, filepath.Join(dir, "misnamed"))
+	assert.ErrorContains(t, err, "local dependency other")
+}
@@ -0,0 +1,191 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// APIDeprecationCheck controls the check of rendered manifests for Kubernetes
+// APIs that are deprecated or removed in the version of the target cluster.
+type APIDeprecationCheck string
+
+const (
+	// APIDeprecationCheckOff disables the check.
+	APIDeprecationCheckOff APIDeprecationCheck = "off"
+	// APIDeprecationCheckWarn logs a warning for each deprecated or removed API.
+	APIDeprecationCheckWarn APIDeprecationCheck = "warn"
+	// APIDeprecationCheckError fails the operation when an API has been
+	// removed, and logs a warning for each deprecated API.
+	APIDeprecationCheckError APIDeprecationCheck = "error"
+)
+
+// ErrRemovedAPIs is returned when a manifest uses APIs removed in the version
+// of the target cluster.
+var ErrRemovedAPIs = errors.New("manifest uses Kubernetes APIs removed in the target cluster version")
+
+// deprecatedAPI describes a Kubernetes API version of a kind that has been
+// deprecated, and the version it has been or will be removed in.
+type deprecatedAPI struct {
+	apiVersion   string
+	kind         string
+	deprecatedIn string
+	removedIn    string
+	replacement  string
+}
+
+// deprecatedAPIs lists the deprecated Kubernetes APIs, following the
+// Kubernetes deprecated API migration guide.
+var deprecatedAPIs = []deprecatedAPI{
+	{"extensions/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "NetworkPolicy", "1.9", "1.16", "networking.k8s.io/v1"},
+	{"extensions/v1beta1", "PodSecurityPolicy", "1.10", "1.16", "policy/v1beta1"},
+	{"extensions/v1beta1", "Ingress", "1.14", "1.22", "networking.k8s.io/v1"},
+	{"apps/v1beta1", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta1", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "Deployment", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "StatefulSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "DaemonSet", "1.9", "1.16", "apps/v1"},
+	{"apps/v1beta2", "ReplicaSet", "1.9", "1.16", "apps/v1"},
+	{"networking.k8s.io/v1beta1", "Ingress", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "IngressClass", "1.19", "1.22", "networking.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "MutatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "ValidatingWebhookConfiguration", "1.16", "1.22", "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "1.16", "1.22", "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "APIService", "1.19", "1.22", "apiregistration.k8s.io/v1"},
+	{"authentication.k8s.io/v1beta1", "TokenReview", "1.19", "1.22", "authentication.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "LocalSubjectAccessReview", "1.19", "1.22", "authorization.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SelfSubjectAccessReview", "1.19", "1.22", "authorization.k8s.io/v1"},
+	{"authorization.k8s.io/v1beta1", "SubjectAccessReview", "1.19", "1.22", "authorization.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "CertificateSigningRequest", "1.19", "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "Lease", "1.19", "1.22", "coordination.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRole", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "ClusterRoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "Role", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "RoleBinding", "1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "PriorityClass", "1.14", "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIDriver", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSINode", "1.17", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "StorageClass", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "VolumeAttachment", "1.19", "1.22", "storage.k8s.io/v1"},
+	{"batch/v1beta1", "CronJob", "1.21", "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "EndpointSlice", "1.21", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "Event", "1.21", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "HorizontalPodAutoscaler", "1.22", "1.25", "autoscaling/v2"},
+	{"policy/v1beta1", "PodDisruptionBudget", "1.21", "1.25", "policy/v1"},
+	{"policy/v1beta1", "PodSecurityPolicy", "1.21", "1.25", ""},
+	{"node.k8s.io/v1beta1", "RuntimeClass", "1.20", "1.25", "node.k8s.io/v1"},
+	{"autoscaling/v2beta2", "HorizontalPodAutoscaler", "1.23", "1.26", "autoscaling/v2"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "FlowSchema", "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "PriorityLevelConfiguration", "1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "CSIStorageCapacity", "1.24", "1.27", "storage.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "FlowSchema", "1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "PriorityLevelConfiguration", "1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "FlowSchema", "1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "PriorityLevelConfiguration", "1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// apiDeprecationCheck checks the manifest and the hooks of a release for
+// Kubernetes APIs that are deprecated or removed in the version of the target
+// cluster.
+func (cfg *Configuration) apiDeprecationCheck(mode APIDeprecationCheck, rel *release.Release) error {
+	if mode == "" || mode == APIDeprecationCheckOff {
+		return nil
+	}
+	caps, err := cfg.getCapabilities()
+	if err != nil {
+		return err
+	}
+
+	manifests := []string{rel.Manifest}
+	for _, h := range rel.Hooks {
+		manifests = append(manifests, h.Manifest)
+	}
+	deprecated, removed, err := findDeprecatedAPIs(strings.Join(manifests, "\n---\n"), caps.KubeVersion)
+	if err != nil {
+		return fmt.Errorf("unable to check for deprecated APIs: %w", err)
+	}
+
+	if mode == APIDeprecationCheckError && len(removed) > 0 {
+		return fmt.Errorf("%w:\n  %s", ErrRemovedAPIs, strings.Join(removed, "\n  "))
+	}
+	for _, p := range slices.Concat(removed, deprecated) {
+		cfg.Logger().Warn("API deprecation check", slog.String("problem", p))
+	}
+	return nil
+}
+
+// findDeprecatedAPIs returns a description of each resource of a manifest
+// that uses an API deprecated, or removed, in the given Kubernetes version.
+func findDeprecatedAPIs(manifest string, kubeVersion common.KubeVersion) (deprecated, removed []string, err error) {
+	cluster, err := semver.NewVersion(kubeVersion.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid Kubernetes version %q: %w", kubeVersion.Version, err)
+	}
+	// Pre-release suffixes of managed clusters must not make the version compare lower
+	cluster = semver.New(cluster.Major(), cluster.Minor(), 0, "", "")
+
+	docs := releaseutil.SplitManifests(manifest)
+	keys := slices.Collect(maps.Keys(docs))
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+	for _, key := range keys {
+		doc := docs[key]
+		var head struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &head); err != nil || head.APIVersion == "" || head.Kind == "" {
+			continue
+		}
+		for _, api := range deprecatedAPIs {
+			if api.apiVersion != head.APIVersion || api.kind != head.Kind {
+				continue
+			}
+			resource := fmt.Sprintf("%s %s %q", head.APIVersion, head.Kind, head.Metadata.Name)
+			replacement := "and has no replacement"
+			if api.replacement != "" {
+				replacement = "use " + api.replacement + " instead"
+			}
+			switch {
+			case !cluster.LessThan(semver.MustParse(api.removedIn)):
+				removed = append(removed, fmt.Sprintf("%s: removed in Kubernetes v%s, %s", resource, api.removedIn, replacement))
+			case !cluster.LessThan(semver.MustParse(api.deprecatedIn)):
+				deprecated = append(deprecated, fmt.Sprintf("%s: deprecated in Kubernetes v%s and removed in v%s, %s", resource, api.deprecatedIn, api.removedIn, replacement))
+			}
+			break
+		}
+	}
+	return deprecated, removed, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
+)
+
+const deprecatedManifest = `apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: web
+---
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`
+
+func TestFindDeprecatedAPIs(t *testing.T) {
+	tests := []struct {
+		name        string
+		kubeVersion string
+		deprecated  []string
+		removed     []string
+	}{
+		{
+			name:        "old cluster",
+			kubeVersion: "v1.15.0",
+			deprecated: []string{
+				`extensions/v1beta1 Deployment "web": deprecated in Kubernetes v1.9 and removed in v1.16, use apps/v1 instead`,
+			},
+		},
+		{
+			name:        "removed and deprecated",
+			kubeVersion: "v1.21.3",
+			deprecated: []string{
+				`networking.k8s.io/v1beta1 Ingress "web": deprecated in Kubernetes v1.19 and removed in v1.22, use networking.k8s.io/v1 instead`,
+				`batch/v1beta1 CronJob "backup": deprecated in Kubernetes v1.21 and removed in v1.25, use batch/v1 instead`,
+			},
+			removed: []string{
+				`extensions/v1beta1 Deployment "web": removed in Kubernetes v1.16, use apps/v1 instead`,
+			},
+		},
+		{
+			name:        "managed cluster version",
+			kubeVersion: "v1.25.0-gke.100",
+			removed: []string{
+				`extensions/v1beta1 Deployment "web": removed in Kubernetes v1.16, use apps/v1 instead`,
+				`networking.k8s.io/v1beta1 Ingress "web": removed in Kubernetes v1.22, use networking.k8s.io/v1 instead`,
+				`batch/v1beta1 CronJob "backup": removed in Kubernetes v1.25, use batch/v1 instead`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeVersion, err := common.ParseKubeVersion(tt.kubeVersion)
+			require.NoError(t, err)
+			deprecated, removed, err := findDeprecatedAPIs(deprecatedManifest, *kubeVersion)
+			require.NoError(t, err)
+			assert.Equal(t, tt.deprecated, deprecated)
+			assert.Equal(t, tt.removed, removed)
+		})
+	}
+}
+
+func TestUpgradeRelease_APIDeprecationCheck(t *testing.T) {
+	// The test capabilities are those of Kubernetes v1.20
+	chrt := buildChart()
+	chrt.Templates = append(chrt.Templates, &common.File{Name: "templates/deprecated", ModTime: time.Now(), Data: []byte(deprecatedManifest)})
+
+	tests := []struct {
+		check   APIDeprecationCheck
+		wantErr bool
+	}{
+		{check: APIDeprecationCheckOff},
+		{check: APIDeprecationCheckWarn},
+		{check: APIDeprecationCheckError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.check), func(t *testing.T) {
+			upAction := upgradeAction(t)
+			rel := releaseStub()
+			rel.Info.Status = rcommon.StatusDeployed
+			require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+			upAction.APIDeprecationCheck = tt.check
+			_, err := upAction.Run(rel.Name, chrt, map[string]any{})
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrRemovedAPIs)
+				assert.ErrorContains(t, err, `extensions/v1beta1 Deployment "web": removed in Kubernetes v1.16`)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
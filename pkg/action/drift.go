@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"errors"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// Drift is the action for detecting the drift of the resources of a release
+// from the manifest stored with the release.
+//
+// It provides the implementation of 'helm drift'.
+type Drift struct {
+	cfg *Configuration
+
+	// Version is the revision whose manifest the live resources are compared
+	// with. Zero compares them with the latest revision.
+	Version int
+}
+
+// NewDrift creates a new Drift object with the given configuration.
+func NewDrift(cfg *Configuration) *Drift {
+	return &Drift{
+		cfg: cfg,
+	}
+}
+
+// Run compares the resources of the given release with their live state and
+// returns the ones that drifted: resources missing from the cluster, and
+// resources whose fields were modified, removed or added by other field
+// managers.
+func (d *Drift) Run(name string) ([]kube.ResourceDrift, error) {
+	if err := d.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	differ, ok := d.cfg.KubeClient.(kube.InterfaceDrift)
+	if !ok {
+		return nil, errors.New("detecting drift is not supported by the Kubernetes client")
+	}
+
+	reli, err := d.cfg.releaseContent(name, d.Version)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := releaserToV1Release(reli)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := d.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return nil, err
+	}
+	drift, err := differ.Drift(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	drifted := make([]kube.ResourceDrift, 0, len(drift))
+	for _, rd := range drift {
+		if rd.Drifted() {
+			drifted = append(drifted, rd)
+		}
+	}
+	return drifted, nil
+}
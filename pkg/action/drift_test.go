@@ -0,0 +1,71 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+)
+
+func TestDriftRun(t *testing.T) {
+	config := actionConfigFixture(t)
+	failingKubeClient := kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+	modified := kube.ResourceDrift{
+		Kind:      "Deployment",
+		Namespace: "default",
+		Name:      "web",
+		Fields: []kube.FieldDrift{{
+			Path:    "spec.replicas",
+			Type:    kube.FieldModified,
+			Desired: int64(3),
+			Live:    int64(5),
+		}},
+	}
+	missing := kube.ResourceDrift{Kind: "Service", Namespace: "default", Name: "web", Missing: true}
+	failingKubeClient.ResourceDrift = []kube.ResourceDrift{
+		modified,
+		{Kind: "ConfigMap", Namespace: "default", Name: "web"},
+		missing,
+	}
+	config.KubeClient = &failingKubeClient
+
+	releaseName := "test-release"
+	require.NoError(t, configureReleaseContent(config, releaseName))
+
+	client := NewDrift(config)
+	drift, err := client.Run(releaseName)
+	require.NoError(t, err)
+	assert.Equal(t, []kube.ResourceDrift{modified, missing}, drift)
+
+	_, err = client.Run("missing")
+	assert.Error(t, err)
+
+	failingKubeClient.DriftError = errors.New("drift error")
+	_, err = client.Run(releaseName)
+	assert.ErrorContains(t, err, "drift error")
+
+	failingKubeClient.ConnectionError = errors.New("connection refused")
+	_, err = client.Run(releaseName)
+	assert.ErrorContains(t, err, "connection refused")
+}
@@ -0,0 +1,122 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"io"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/internal/diff"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// GetDiff is the action for comparing two revisions of a release.
+//
+// It provides the implementation of 'helm get diff'.
+type GetDiff struct {
+	cfg *Configuration
+
+	// Context is the number of unchanged lines shown around each change
+	Context int
+	// NoColor disables colorized output
+	NoColor bool
+}
+
+// NewGetDiff creates a new GetDiff object with the given configuration.
+func NewGetDiff(cfg *Configuration) *GetDiff {
+	return &GetDiff{
+		cfg:     cfg,
+		Context: diff.DefaultContext,
+	}
+}
+
+// Run executes 'helm get diff' against the given release, writing the
+// differences of the manifests, the user supplied values and the notes of the
+// two revisions to out. The values of Secrets in the manifests are redacted.
+// It reports whether the revisions differ.
+func (g *GetDiff) Run(out io.Writer, name string, from, to int) (bool, error) {
+	if err := g.cfg.KubeClient.IsReachable(); err != nil {
+		return false, err
+	}
+	if from <= 0 || to <= 0 {
+		return false, errInvalidRevision
+	}
+
+	oldRelease, err := g.revision(name, from)
+	if err != nil {
+		return false, err
+	}
+	newRelease, err := g.revision(name, to)
+	if err != nil {
+		return false, err
+	}
+
+	oldValues, err := valuesText(oldRelease)
+	if err != nil {
+		return false, err
+	}
+	newValues, err := valuesText(newRelease)
+	if err != nil {
+		return false, err
+	}
+
+	changes := diff.Manifests(oldRelease.Manifest, newRelease.Manifest)
+	changed := len(changes) > 0 || oldValues != newValues || releaseNotes(oldRelease) != releaseNotes(newRelease)
+
+	opts := diff.Options{Context: g.Context, NoColor: g.NoColor}
+	if err := diff.Write(out, changes, opts); err != nil {
+		return false, err
+	}
+	if err := diff.Text(out, "Values have been modified", oldValues, newValues, opts); err != nil {
+		return false, err
+	}
+	if err := diff.Text(out, "Notes have been modified", releaseNotes(oldRelease), releaseNotes(newRelease), opts); err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+func (g *GetDiff) revision(name string, version int) (*release.Release, error) {
+	reli, err := g.cfg.releaseContent(name, version)
+	if err != nil {
+		return nil, fmt.Errorf("revision %d: %w", version, err)
+	}
+	return releaserToV1Release(reli)
+}
+
+// valuesText returns the user supplied values of a release as YAML, or an
+// empty string if there are none.
+func valuesText(rel *release.Release) (string, error) {
+	if len(rel.Config) == 0 {
+		return "", nil
+	}
+	data, err := yaml.Marshal(rel.Config)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// releaseNotes returns the notes of a release.
+func releaseNotes(rel *release.Release) string {
+	if rel.Info == nil {
+		return ""
+	}
+	return rel.Info.Notes
+}
@@ -0,0 +1,75 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release/common"
+)
+
+func TestGetDiffRun(t *testing.T) {
+	config := actionConfigFixture(t)
+
+	rel1 := namedReleaseStub("get-diff", common.StatusSuperseded)
+	rel1.Version = 1
+	rel1.Manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\ndata:\n  image: nginx:1.25\n"
+	rel1.Config = map[string]any{"image": "nginx:1.25"}
+	rel1.Info.Notes = "Enjoy nginx"
+	require.NoError(t, config.Releases.Create(rel1))
+
+	rel2 := namedReleaseStub("get-diff", common.StatusDeployed)
+	rel2.Version = 2
+	rel2.Manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\ndata:\n  image: nginx:1.26\n"
+	rel2.Config = map[string]any{"image": "nginx:1.26"}
+	rel2.Info.Notes = "Enjoy nginx"
+	require.NoError(t, config.Releases.Create(rel2))
+
+	client := NewGetDiff(config)
+	client.Context = 0
+	client.NoColor = true
+
+	var out bytes.Buffer
+	changed, err := client.Run(&out, "get-diff", 1, 2)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, `-, cm, ConfigMap has been modified:
+@@ -6 +6 @@
+-  image: nginx:1.25
++  image: nginx:1.26
+Values have been modified:
+@@ -1 +1 @@
+-image: nginx:1.25
++image: nginx:1.26
+`, out.String())
+
+	out.Reset()
+	changed, err = client.Run(&out, "get-diff", 2, 2)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, out.String())
+
+	_, err = client.Run(&out, "get-diff", 1, 3)
+	assert.ErrorContains(t, err, "revision 3")
+
+	_, err = client.Run(&out, "get-diff", 0, 2)
+	assert.ErrorIs(t, err, errInvalidRevision)
+}
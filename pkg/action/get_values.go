@@ -43,16 +43,7 @@ func NewGetValues(cfg *Configuration) *GetValues {
 
 // Run executes 'helm get values' against the given release.
 func (g *GetValues) Run(name string) (map[string]any, error) {
-	if err := g.cfg.KubeClient.IsReachable(); err != nil {
-		return nil, err
-	}
-
-	reli, err := g.cfg.releaseContent(name, g.Version)
-	if err != nil {
-		return nil, err
-	}
-
-	rel, err := releaserToV1Release(reli)
+	rel, err := g.release(name)
 	if err != nil {
 		return nil, err
 	}
@@ -68,6 +59,49 @@ func (g *GetValues) Run(name string) (map[string]any, error) {
 	return rel.Config, nil
 }
 
+// RunWithTrace executes 'helm get values --trace' against the given release,
+// returning the values together with the sources that set them.
+//
+// The values of releases installed before values were traced are traced as
+// user supplied.
+func (g *GetValues) RunWithTrace(name string) (map[string]any, util.ValuesTrace, error) {
+	rel, err := g.release(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userTrace := util.ValuesTrace(rel.ValuesTrace)
+	if userTrace == nil {
+		userTrace = util.ValuesTrace{}
+		userTrace.Record(rel.Config, "user supplied")
+	}
+	if !g.AllValues {
+		return rel.Config, userTrace, nil
+	}
+
+	vals, err := util.CoalesceValues(rel.Chart, rel.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	trace, err := util.TraceValues(rel.Chart, rel.Config, userTrace)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vals, trace, nil
+}
+
+func (g *GetValues) release(name string) (*rspb.Release, error) {
+	if err := g.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	reli, err := g.cfg.releaseContent(name, g.Version)
+	if err != nil {
+		return nil, err
+	}
+	return releaserToV1Release(reli)
+}
+
 // releaserToV1Release is a helper function to convert a v1 release passed by interface
 // into the type object.
 func releaserToV1Release(rel release.Releaser) (*rspb.Release, error) {
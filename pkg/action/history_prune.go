@@ -0,0 +1,68 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/storage"
+)
+
+// HistoryPrune is the action for deleting revisions from the history of a
+// release.
+//
+// It provides the implementation of 'helm history --prune'.
+type HistoryPrune struct {
+	cfg *Configuration
+
+	// Retention selects the revisions to delete
+	Retention storage.RetentionPolicy
+	// LockTimeout is how long to wait for another operation on the release to
+	// release its lock
+	LockTimeout time.Duration
+}
+
+// NewHistoryPrune creates a new HistoryPrune object with the given configuration.
+func NewHistoryPrune(cfg *Configuration) *HistoryPrune {
+	return &HistoryPrune{
+		cfg: cfg,
+	}
+}
+
+// Run deletes the revisions of the release the retention policy does not keep.
+// It returns the deleted revisions, from the most recent to the oldest one.
+func (h *HistoryPrune) Run(name string) ([]release.Releaser, error) {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, fmt.Errorf("release name is invalid: %s", name)
+	}
+	if err := h.Retention.Validate(); err != nil {
+		return nil, err
+	}
+
+	_, unlock, err := h.cfg.Releases.Lock(context.Background(), name, "prune", h.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	h.cfg.Logger().Debug("pruning history of release", "release", name)
+	return h.cfg.Releases.Prune(name, h.Retention)
+}
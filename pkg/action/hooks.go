@@ -19,8 +19,11 @@ import (
 	"bytes"
 	"fmt"
 	"log"
+	"log/slog"
 	"slices"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"helm.sh/helm/v4/pkg/kube"
@@ -58,108 +61,376 @@ func shutdownNoOp() error {
 func (cfg *Configuration) execHookWithDelayedShutdown(rl *release.Release, hook release.HookEvent,
 	waitStrategy kube.WaitStrategy, waitOptions []kube.WaitOption, timeout time.Duration,
 	serverSideApply bool) (ExecuteShutdownFunc, error) {
-	executingHooks := []*release.Hook{}
+	executingHooks := hooksForEvent(rl, hook)
 
-	for _, h := range rl.Hooks {
-		for _, e := range h.Events {
-			if e == hook {
-				executingHooks = append(executingHooks, h)
+	if hooksHaveDependencies(executingHooks) {
+		return cfg.execHookGraph(rl, hook, executingHooks, waitStrategy, waitOptions, timeout, serverSideApply, 0)
+	}
+
+	var mu sync.Mutex
+	for i, h := range executingHooks {
+		failed, err := cfg.runHook(rl, hook, h, waitStrategy, waitOptions, timeout, serverSideApply, &mu)
+		if err == nil {
+			continue
+		}
+		if !failed {
+			return shutdownNoOp, err
+		}
+		// If a hook is failed, check the annotation of the hook to determine whether the hook should be deleted
+		// under failed condition. If so, then clear the corresponding resource object in the hook
+		return func() error {
+			if errDeleting := cfg.deleteHookByPolicy(h, release.HookFailed, waitStrategy, waitOptions, timeout); errDeleting != nil {
+				// We log the error here as we want to propagate the hook failure upwards to the release object.
+				log.Printf("error deleting the hook resource on hook failure: %v", errDeleting)
 			}
+
+			// If a hook is failed, check the annotation of the previous successful hooks to determine whether the hooks
+			// should be deleted under succeeded condition.
+			if err := cfg.deleteHooksByPolicy(executingHooks[0:i], release.HookSucceeded, waitStrategy, waitOptions, timeout); err != nil {
+				return err
+			}
+			return err
+		}, err
+	}
+
+	return cfg.succeededHooksShutdown(rl, executingHooks, waitStrategy, waitOptions, timeout), nil
+}
+
+// hooksForEvent returns the hooks of the release for the given hook event,
+// sorted by weight.
+func hooksForEvent(rl *release.Release, hook release.HookEvent) []*release.Hook {
+	hooks := []*release.Hook{}
+	for _, h := range rl.Hooks {
+		if slices.Contains(h.Events, hook) {
+			hooks = append(hooks, h)
 		}
 	}
 
 	// hooke are pre-ordered by kind, so keep order stable
-	sort.Stable(hookByWeight(executingHooks))
+	sort.Stable(hookByWeight(hooks))
+	return hooks
+}
 
-	for i, h := range executingHooks {
+// succeededHooksShutdown returns the function outputting the logs of the
+// succeeded hooks and deleting them according to their policies.
+func (cfg *Configuration) succeededHooksShutdown(rl *release.Release, hooks []*release.Hook,
+	waitStrategy kube.WaitStrategy, waitOptions []kube.WaitOption, timeout time.Duration) ExecuteShutdownFunc {
+	return func() error {
+		// If all hooks are successful, check the annotation of each hook to determine whether the hook should be deleted
+		// or output should be logged under succeeded condition. If so, then clear the corresponding resource object in each hook
+		for _, v := range slices.Backward(hooks) {
+			h := v
+			if err := cfg.outputLogsByPolicy(h, rl.Namespace, release.HookOutputOnSucceeded); err != nil {
+				// We log here as we still want to attempt hook resource deletion even if output logging fails.
+				log.Printf("error outputting logs for hook failure: %v", err)
+			}
+			if err := cfg.deleteHookByPolicy(h, release.HookSucceeded, waitStrategy, waitOptions, timeout); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// runHook creates the resources of a hook and watches them until they have
+// completed, running the hook again as many times as its retries if it fails.
+// It returns whether the hook ran and failed, as opposed to failing to run.
+// mu guards the execution records of the hooks of the release.
+func (cfg *Configuration) runHook(rl *release.Release, hook release.HookEvent, h *release.Hook,
+	waitStrategy kube.WaitStrategy, waitOptions []kube.WaitOption, timeout time.Duration,
+	serverSideApply bool, mu *sync.Mutex) (bool, error) {
+	if h.Timeout > 0 {
+		timeout = h.Timeout
+	}
+
+	// Hooks updated in place keep their resources from the previous run, so
+	// that e.g. the history of a CronJob is not lost.
+	if h.UpgradePolicy != release.HookUpgradePatch {
 		// Set default delete policy to before-hook-creation
 		cfg.hookSetDeletePolicy(h)
 
 		if err := cfg.deleteHookByPolicy(h, release.HookBeforeHookCreation, waitStrategy, waitOptions, timeout); err != nil {
-			return shutdownNoOp, err
+			return false, err
 		}
+	}
 
-		resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), true)
-		if err != nil {
-			return shutdownNoOp, fmt.Errorf("unable to build kubernetes object for %s hook %s: %w", hook, h.Path, err)
+	resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), true)
+	if err != nil {
+		return false, fmt.Errorf("unable to build kubernetes object for %s hook %s: %w", hook, h.Path, err)
+	}
+	// Hook resources carry the ownership metadata of the release, so that
+	// those left behind by hooks removed from the chart can be pruned
+	if err := resources.Visit(setMetadataVisitor(rl.Name, rl.Namespace, true)); err != nil {
+		return false, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		failed, err := cfg.runHookOnce(rl, hook, h, resources, waitStrategy, waitOptions, timeout, serverSideApply, mu)
+		if err == nil || attempt > h.Retries {
+			if failed {
+				// If a hook is failed, check the annotation of the hook to determine if we should copy the logs client side
+				if errOutputting := cfg.outputLogsByPolicy(h, rl.Namespace, release.HookOutputOnFailed); errOutputting != nil {
+					// We log the error here as we want to propagate the hook failure upwards to the release object.
+					log.Printf("error outputting logs for hook failure: %v", errOutputting)
+				}
+			}
+			return failed, err
 		}
 
-		// Record the time at which the hook was applied to the cluster
-		h.LastRun = release.HookExecution{
-			StartedAt: time.Now(),
-			Phase:     release.HookPhaseRunning,
+		cfg.Logger().Warn("hook failed, retrying",
+			"hook", h.Path,
+			"attempt", attempt,
+			"retries", h.Retries,
+			slog.Any("error", err))
+		// CustomResourceDefinitions are never deleted, their creation is
+		// retried as is.
+		if h.Kind != "CustomResourceDefinition" {
+			if err := cfg.deleteHookResources(h, waitStrategy, waitOptions, timeout); err != nil {
+				return false, err
+			}
 		}
-		cfg.recordRelease(rl)
+	}
+}
+
+// runHookOnce creates the resources of a hook and watches them until they
+// have completed. It returns whether the hook ran and failed, as opposed to
+// failing to run.
+func (cfg *Configuration) runHookOnce(rl *release.Release, hook release.HookEvent, h *release.Hook, resources kube.ResourceList,
+	waitStrategy kube.WaitStrategy, waitOptions []kube.WaitOption, timeout time.Duration,
+	serverSideApply bool, mu *sync.Mutex) (bool, error) {
+	// Record the time at which the hook was applied to the cluster
+	mu.Lock()
+	h.LastRun = release.HookExecution{
+		StartedAt: time.Now(),
+		Phase:     release.HookPhaseRunning,
+	}
+	cfg.recordRelease(rl)
+
+	// As long as the implementation of WatchUntilReady does not panic, HookPhaseFailed or HookPhaseSucceeded
+	// should always be set by this function. If we fail to do that for any reason, then HookPhaseUnknown is
+	// the most appropriate value to surface.
+	h.LastRun.Phase = release.HookPhaseUnknown
+	mu.Unlock()
+
+	// Create hook resources, or update them in place if the hook asks to
+	if err := cfg.applyHookResources(h, resources, serverSideApply); err != nil {
+		mu.Lock()
+		h.LastRun.CompletedAt = time.Now()
+		h.LastRun.Phase = release.HookPhaseFailed
+		mu.Unlock()
+		return false, fmt.Errorf("warning: Hook %s %s failed: %w", hook, h.Path, err)
+	}
 
-		// As long as the implementation of WatchUntilReady does not panic, HookPhaseFailed or HookPhaseSucceeded
-		// should always be set by this function. If we fail to do that for any reason, then HookPhaseUnknown is
-		// the most appropriate value to surface.
-		h.LastRun.Phase = release.HookPhaseUnknown
+	var waiter kube.Waiter
+	var err error
+	if c, supportsOptions := cfg.KubeClient.(kube.InterfaceWaitOptions); supportsOptions {
+		waiter, err = c.GetWaiterWithOptions(waitStrategy, waitOptions...)
+	} else {
+		waiter, err = cfg.KubeClient.GetWaiter(waitStrategy)
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to get waiter: %w", err)
+	}
+	// Watch hook resources until they have completed
+	err = waiter.WatchUntilReady(resources, timeout)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Note the time of success/failure
+	h.LastRun.CompletedAt = time.Now()
+	// Mark hook as succeeded or failed
+	if err != nil {
+		h.LastRun.Phase = release.HookPhaseFailed
+		return true, err
+	}
+	h.LastRun.Phase = release.HookPhaseSucceeded
+	return false, nil
+}
 
-		// Create hook resources
-		if _, err := cfg.KubeClient.Create(
+// applyHookResources creates the resources of a hook. The resources of a hook
+// with the patch upgrade policy are updated in place instead, and created only
+// if they do not exist.
+func (cfg *Configuration) applyHookResources(h *release.Hook, resources kube.ResourceList, serverSideApply bool) error {
+	if h.UpgradePolicy == release.HookUpgradePatch {
+		_, err := cfg.KubeClient.Update(
 			resources,
-			kube.ClientCreateOptionServerSideApply(serverSideApply, false)); err != nil {
-			h.LastRun.CompletedAt = time.Now()
-			h.LastRun.Phase = release.HookPhaseFailed
-			return shutdownNoOp, fmt.Errorf("warning: Hook %s %s failed: %w", hook, h.Path, err)
-		}
+			resources,
+			kube.ClientUpdateOptionServerSideApply(serverSideApply, false),
+			kube.ClientUpdateOptionThreeWayMergeForUnstructured(false))
+		return err
+	}
+	_, err := cfg.KubeClient.Create(
+		resources,
+		kube.ClientCreateOptionServerSideApply(serverSideApply, false))
+	return err
+}
 
-		var waiter kube.Waiter
-		if c, supportsOptions := cfg.KubeClient.(kube.InterfaceWaitOptions); supportsOptions {
-			waiter, err = c.GetWaiterWithOptions(waitStrategy, waitOptions...)
-		} else {
-			waiter, err = cfg.KubeClient.GetWaiter(waitStrategy)
+// hooksHaveDependencies returns whether one of the hooks declares the hooks
+// it depends on.
+func hooksHaveDependencies(hooks []*release.Hook) bool {
+	for _, h := range hooks {
+		if len(h.DependsOn) > 0 {
+			return true
 		}
-		if err != nil {
-			return shutdownNoOp, fmt.Errorf("unable to get waiter: %w", err)
+	}
+	return false
+}
+
+// hookDependencies returns the hooks each hook waits for: the hooks it
+// depends on, and the hooks of lower weight. The hooks must be sorted by
+// weight.
+func hookDependencies(hook release.HookEvent, hooks []*release.Hook) (map[*release.Hook][]*release.Hook, error) {
+	byName := map[string][]*release.Hook{}
+	for _, h := range hooks {
+		byName[h.Name] = append(byName[h.Name], h)
+	}
+
+	deps := map[*release.Hook][]*release.Hook{}
+	for i, h := range hooks {
+		for _, lower := range hooks[:i] {
+			if lower.Weight < h.Weight {
+				deps[h] = append(deps[h], lower)
+			}
 		}
-		// Watch hook resources until they have completed
-		err = waiter.WatchUntilReady(resources, timeout)
-		// Note the time of success/failure
-		h.LastRun.CompletedAt = time.Now()
-		// Mark hook as succeeded or failed
-		if err != nil {
-			h.LastRun.Phase = release.HookPhaseFailed
-			// If a hook is failed, check the annotation of the hook to determine if we should copy the logs client side
-			if errOutputting := cfg.outputLogsByPolicy(h, rl.Namespace, release.HookOutputOnFailed); errOutputting != nil {
-				// We log the error here as we want to propagate the hook failure upwards to the release object.
-				log.Printf("error outputting logs for hook failure: %v", errOutputting)
+		for _, name := range h.DependsOn {
+			dependencies, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("%s hook %s depends on unknown hook %q", hook, h.Path, name)
 			}
-			// If a hook is failed, check the annotation of the hook to determine whether the hook should be deleted
-			// under failed condition. If so, then clear the corresponding resource object in the hook
-			return func() error {
-				if errDeleting := cfg.deleteHookByPolicy(h, release.HookFailed, waitStrategy, waitOptions, timeout); errDeleting != nil {
-					// We log the error here as we want to propagate the hook failure upwards to the release object.
-					log.Printf("error deleting the hook resource on hook failure: %v", errDeleting)
+			for _, d := range dependencies {
+				if d != h && !slices.Contains(deps[h], d) {
+					deps[h] = append(deps[h], d)
 				}
+			}
+		}
+	}
 
-				// If a hook is failed, check the annotation of the previous successful hooks to determine whether the hooks
-				// should be deleted under succeeded condition.
-				if err := cfg.deleteHooksByPolicy(executingHooks[0:i], release.HookSucceeded, waitStrategy, waitOptions, timeout); err != nil {
-					return err
-				}
+	// Reject cycles, which would never run.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[*release.Hook]int{}
+	var path []string
+	var visit func(h *release.Hook) error
+	visit = func(h *release.Hook) error {
+		switch state[h] {
+		case visiting:
+			return fmt.Errorf("%s hooks have a dependency cycle: %s", hook, strings.Join(append(path, h.Name), " -> "))
+		case visited:
+			return nil
+		}
+		state[h] = visiting
+		path = append(path, h.Name)
+		for _, d := range deps[h] {
+			if err := visit(d); err != nil {
 				return err
-			}, err
+			}
 		}
-		h.LastRun.Phase = release.HookPhaseSucceeded
+		path = path[:len(path)-1]
+		state[h] = visited
+		return nil
 	}
+	for _, h := range hooks {
+		if err := visit(h); err != nil {
+			return nil, err
+		}
+	}
+	return deps, nil
+}
 
-	return func() error {
-		// If all hooks are successful, check the annotation of each hook to determine whether the hook should be deleted
-		// or output should be logged under succeeded condition. If so, then clear the corresponding resource object in each hook
-		for _, v := range slices.Backward(executingHooks) {
-			h := v
-			if err := cfg.outputLogsByPolicy(h, rl.Namespace, release.HookOutputOnSucceeded); err != nil {
-				// We log here as we still want to attempt hook resource deletion even if output logging fails.
-				log.Printf("error outputting logs for hook failure: %v", err)
+// execHookGraph executes the hooks of an event as a graph: a hook runs once
+// the hooks it depends on and the hooks of lower weight have succeeded, so
+// that independent hooks run in parallel, at most limit at a time unless
+// limit is zero. No hook is started after a hook fails.
+func (cfg *Configuration) execHookGraph(rl *release.Release, hook release.HookEvent, hooks []*release.Hook,
+	waitStrategy kube.WaitStrategy, waitOptions []kube.WaitOption, timeout time.Duration,
+	serverSideApply bool, limit int) (ExecuteShutdownFunc, error) {
+	deps, err := hookDependencies(hook, hooks)
+	if err != nil {
+		return shutdownNoOp, err
+	}
+
+	type hookResult struct {
+		hook   *release.Hook
+		failed bool
+		err    error
+	}
+	var mu sync.Mutex
+	results := make(chan hookResult)
+	started := map[*release.Hook]bool{}
+	succeeded := map[*release.Hook]bool{}
+	var succeededHooks, failedHooks []*release.Hook
+	var errs []error
+	running := 0
+	for {
+		if len(errs) == 0 {
+			for _, h := range hooks {
+				if limit > 0 && running >= limit {
+					break
+				}
+				if started[h] || !allHooksSucceeded(deps[h], succeeded) {
+					continue
+				}
+				started[h] = true
+				running++
+				go func() {
+					failed, err := cfg.runHook(rl, hook, h, waitStrategy, waitOptions, timeout, serverSideApply, &mu)
+					results <- hookResult{hook: h, failed: failed, err: err}
+				}()
 			}
-			if err := cfg.deleteHookByPolicy(h, release.HookSucceeded, waitStrategy, waitOptions, timeout); err != nil {
-				return err
+		}
+		if running == 0 {
+			break
+		}
+
+		r := <-results
+		running--
+		switch {
+		case r.err == nil:
+			succeeded[r.hook] = true
+			succeededHooks = append(succeededHooks, r.hook)
+		case r.failed:
+			failedHooks = append(failedHooks, r.hook)
+			errs = append(errs, r.err)
+		default:
+			errs = append(errs, r.err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return cfg.succeededHooksShutdown(rl, succeededHooks, waitStrategy, waitOptions, timeout), nil
+	}
+	err = errs[0]
+	if len(errs) > 1 {
+		err = joinErrors(errs, "; ")
+	}
+	if len(failedHooks) == 0 {
+		return shutdownNoOp, err
+	}
+	return func() error {
+		for _, h := range failedHooks {
+			if errDeleting := cfg.deleteHookByPolicy(h, release.HookFailed, waitStrategy, waitOptions, timeout); errDeleting != nil {
+				// We log the error here as we want to propagate the hook failure upwards to the release object.
+				log.Printf("error deleting the hook resource on hook failure: %v", errDeleting)
 			}
 		}
-		return nil
-	}, nil
+		if err := cfg.deleteHooksByPolicy(succeededHooks, release.HookSucceeded, waitStrategy, waitOptions, timeout); err != nil {
+			return err
+		}
+		return err
+	}, err
+}
+
+// allHooksSucceeded returns whether all of the hooks have succeeded.
+func allHooksSucceeded(hooks []*release.Hook, succeeded map[*release.Hook]bool) bool {
+	for _, h := range hooks {
+		if !succeeded[h] {
+			return false
+		}
+	}
+	return true
 }
 
 // hookByWeight is a sorter for hooks
@@ -183,31 +454,36 @@ func (cfg *Configuration) deleteHookByPolicy(h *release.Hook, policy release.Hoo
 		return nil
 	}
 	if cfg.hookHasDeletePolicy(h, policy) {
-		resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
-		if err != nil {
-			return fmt.Errorf("unable to build kubernetes object for deleting hook %s: %w", h.Path, err)
-		}
-		_, errs := cfg.KubeClient.Delete(resources, metav1.DeletePropagationBackground)
-		if len(errs) > 0 {
-			return joinErrors(errs, "; ")
-		}
-
-		var waiter kube.Waiter
-		if c, supportsOptions := cfg.KubeClient.(kube.InterfaceWaitOptions); supportsOptions {
-			waiter, err = c.GetWaiterWithOptions(waitStrategy, waitOptions...)
-		} else {
-			waiter, err = cfg.KubeClient.GetWaiter(waitStrategy)
-		}
-		if err != nil {
-			return err
-		}
-		if err := waiter.WaitForDelete(resources, timeout); err != nil {
-			return err
-		}
+		return cfg.deleteHookResources(h, waitStrategy, waitOptions, timeout)
 	}
 	return nil
 }
 
+// deleteHookResources deletes the resources of a hook and waits until they
+// are deleted.
+func (cfg *Configuration) deleteHookResources(h *release.Hook,
+	waitStrategy kube.WaitStrategy, waitOptions []kube.WaitOption, timeout time.Duration) error {
+	resources, err := cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
+	if err != nil {
+		return fmt.Errorf("unable to build kubernetes object for deleting hook %s: %w", h.Path, err)
+	}
+	_, errs := cfg.KubeClient.Delete(resources, metav1.DeletePropagationBackground)
+	if len(errs) > 0 {
+		return joinErrors(errs, "; ")
+	}
+
+	var waiter kube.Waiter
+	if c, supportsOptions := cfg.KubeClient.(kube.InterfaceWaitOptions); supportsOptions {
+		waiter, err = c.GetWaiterWithOptions(waitStrategy, waitOptions...)
+	} else {
+		waiter, err = cfg.KubeClient.GetWaiter(waitStrategy)
+	}
+	if err != nil {
+		return err
+	}
+	return waiter.WaitForDelete(resources, timeout)
+}
+
 // deleteHooksByPolicy deletes all hooks if the hook policy instructs it to
 func (cfg *Configuration) deleteHooksByPolicy(hooks []*release.Hook, policy release.HookDeletePolicy,
 	waitStrategy kube.WaitStrategy, waitOptions []kube.WaitOption, timeout time.Duration) error {
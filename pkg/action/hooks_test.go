@@ -23,11 +23,14 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
@@ -257,6 +260,7 @@ func (*HookFailingKubeClient) Build(reader io.Reader, _ bool) (kube.ResourceList
 	return kube.ResourceList{{
 		Name:      configMap.Name,
 		Namespace: configMap.Namespace,
+		Object:    configMap,
 	}}, nil
 }
 
@@ -492,3 +496,212 @@ data:
 	// Verify that WaitOptions were passed to GetWaiter
 	is.NotEmpty(failer.RecordedWaitOptions, "WaitOptions should be passed to GetWaiter")
 }
+
+// hookGraphKubeClient records the creation of hook resources. Its waiter
+// fails the first attempts of the hooks in failures, and makes the hooks in
+// waitFor wait until the named hook has been created.
+type hookGraphKubeClient struct {
+	kubefake.PrintingKubeClient
+	mu       sync.Mutex
+	created  []string
+	updated  []string
+	deleted  []string
+	waitFor  map[string]string
+	failures map[string]int
+}
+
+func (*hookGraphKubeClient) Build(reader io.Reader, _ bool) (kube.ResourceList, error) {
+	return (&HookFailingKubeClient{}).Build(reader, false)
+}
+
+func (c *hookGraphKubeClient) Create(resources kube.ResourceList, _ ...kube.ClientCreateOption) (*kube.Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, res := range resources {
+		c.created = append(c.created, res.Name)
+	}
+	return &kube.Result{Created: resources}, nil
+}
+
+func (c *hookGraphKubeClient) Update(_, targets kube.ResourceList, _ ...kube.ClientUpdateOption) (*kube.Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, res := range targets {
+		c.updated = append(c.updated, res.Name)
+	}
+	return &kube.Result{Updated: targets}, nil
+}
+
+func (c *hookGraphKubeClient) Delete(resources kube.ResourceList, _ metav1.DeletionPropagation) (*kube.Result, []error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, res := range resources {
+		c.deleted = append(c.deleted, res.Name)
+	}
+	return &kube.Result{Deleted: resources}, nil
+}
+
+func (c *hookGraphKubeClient) wasCreated(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Contains(c.created, name)
+}
+
+func (c *hookGraphKubeClient) GetWaiterWithOptions(strategy kube.WaitStrategy, opts ...kube.WaitOption) (kube.Waiter, error) {
+	waiter, _ := c.PrintingKubeClient.GetWaiterWithOptions(strategy, opts...)
+	return &hookGraphKubeWaiter{PrintingKubeWaiter: waiter.(*kubefake.PrintingKubeWaiter), client: c}, nil
+}
+
+type hookGraphKubeWaiter struct {
+	*kubefake.PrintingKubeWaiter
+	client *hookGraphKubeClient
+}
+
+func (w *hookGraphKubeWaiter) WatchUntilReady(resources kube.ResourceList, _ time.Duration) error {
+	for _, res := range resources {
+		if other, ok := w.client.waitFor[res.Name]; ok {
+			deadline := time.Now().Add(5 * time.Second)
+			for !w.client.wasCreated(other) {
+				if time.Now().After(deadline) {
+					return fmt.Errorf("hook %s was not run in parallel with %s", other, res.Name)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+		w.client.mu.Lock()
+		failures := w.client.failures[res.Name]
+		if failures > 0 {
+			w.client.failures[res.Name]--
+		}
+		w.client.mu.Unlock()
+		if failures > 0 {
+			return &HookFailedError{}
+		}
+	}
+	return nil
+}
+
+func graphHook(name string, weight int, dependsOn ...string) *release.Hook {
+	return &release.Hook{
+		Name:      name,
+		Kind:      "ConfigMap",
+		Path:      "templates/" + name + ".yaml",
+		Manifest:  fmt.Sprintf("kind: ConfigMap\nmetadata:\n  name: %s\n", name),
+		Weight:    weight,
+		Events:    []release.HookEvent{release.HookPreInstall},
+		DependsOn: dependsOn,
+	}
+}
+
+func hookGraphConfig(client kube.Interface) *Configuration {
+	return &Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   client,
+		Capabilities: common.DefaultCapabilities,
+	}
+}
+
+func TestExecHookGraph(t *testing.T) {
+	client := &hookGraphKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard},
+		waitFor:            map[string]string{"migrate": "seed-cache"},
+	}
+	rel := &release.Release{
+		Name:      "graph",
+		Namespace: "default",
+		Hooks: []*release.Hook{
+			graphHook("notify", 0, "migrate", "seed-cache"),
+			graphHook("migrate", 0),
+			graphHook("seed-cache", 0),
+			graphHook("cleanup", 5),
+		},
+	}
+
+	err := hookGraphConfig(client).execHook(rel, release.HookPreInstall, kube.StatusWatcherStrategy, nil, time.Minute, false)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"migrate", "seed-cache"}, client.created[:2])
+	assert.Equal(t, []string{"notify", "cleanup"}, client.created[2:])
+	for _, h := range rel.Hooks {
+		assert.Equal(t, release.HookPhaseSucceeded, h.LastRun.Phase, h.Name)
+	}
+}
+
+func TestExecHookGraph_Failure(t *testing.T) {
+	client := &hookGraphKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard},
+		failures:           map[string]int{"migrate": 1},
+	}
+	rel := &release.Release{
+		Name:      "graph",
+		Namespace: "default",
+		Hooks: []*release.Hook{
+			graphHook("migrate", 0),
+			graphHook("notify", 0, "migrate"),
+		},
+	}
+
+	err := hookGraphConfig(client).execHook(rel, release.HookPreInstall, kube.StatusWatcherStrategy, nil, time.Minute, false)
+	assert.EqualError(t, err, "Hook failed!")
+	assert.Equal(t, []string{"migrate"}, client.created)
+	assert.Equal(t, release.HookPhaseFailed, rel.Hooks[0].LastRun.Phase)
+	assert.Equal(t, release.HookPhase(""), rel.Hooks[1].LastRun.Phase)
+}
+
+func TestExecHook_Retries(t *testing.T) {
+	client := &hookGraphKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard},
+		failures:           map[string]int{"flaky": 2},
+	}
+	flaky := graphHook("flaky", 0)
+	flaky.Retries = 2
+	rel := &release.Release{Name: "retries", Namespace: "default", Hooks: []*release.Hook{flaky}}
+
+	err := hookGraphConfig(client).execHook(rel, release.HookPreInstall, kube.StatusWatcherStrategy, nil, time.Minute, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"flaky", "flaky", "flaky"}, client.created)
+	assert.Equal(t, release.HookPhaseSucceeded, flaky.LastRun.Phase)
+
+	client.created = nil
+	client.failures["flaky"] = 3
+	err = hookGraphConfig(client).execHook(rel, release.HookPreInstall, kube.StatusWatcherStrategy, nil, time.Minute, false)
+	assert.EqualError(t, err, "Hook failed!")
+	assert.Len(t, client.created, 3)
+	assert.Equal(t, release.HookPhaseFailed, flaky.LastRun.Phase)
+}
+
+func TestExecHook_UpgradePolicyPatch(t *testing.T) {
+	client := &hookGraphKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+	recreated := graphHook("recreated", 0)
+	patched := graphHook("patched", 1)
+	patched.UpgradePolicy = release.HookUpgradePatch
+	rel := &release.Release{Name: "patch", Namespace: "default", Hooks: []*release.Hook{recreated, patched}}
+
+	err := hookGraphConfig(client).execHook(rel, release.HookPreInstall, kube.StatusWatcherStrategy, nil, time.Minute, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"recreated"}, client.deleted)
+	assert.Equal(t, []string{"recreated"}, client.created)
+	assert.Equal(t, []string{"patched"}, client.updated)
+	assert.Equal(t, release.HookPhaseSucceeded, patched.LastRun.Phase)
+	// Only the recreated hook gets the default delete policy.
+	assert.Equal(t, []release.HookDeletePolicy{release.HookBeforeHookCreation}, recreated.DeletePolicies)
+	assert.Empty(t, patched.DeletePolicies)
+}
+
+func TestHookDependencies(t *testing.T) {
+	_, err := hookDependencies(release.HookPreInstall, []*release.Hook{graphHook("seed", 0, "migrate")})
+	assert.EqualError(t, err, `pre-install hook templates/seed.yaml depends on unknown hook "migrate"`)
+
+	_, err = hookDependencies(release.HookPreInstall, []*release.Hook{
+		graphHook("migrate", 0, "seed"),
+		graphHook("seed", 0, "migrate"),
+	})
+	assert.EqualError(t, err, "pre-install hooks have a dependency cycle: migrate -> seed -> migrate")
+
+	// Dependencies on hooks of higher weight are cycles too.
+	_, err = hookDependencies(release.HookPreInstall, []*release.Hook{
+		graphHook("migrate", 0, "seed"),
+		graphHook("seed", 1),
+	})
+	assert.ErrorContains(t, err, "dependency cycle")
+}
@@ -38,6 +38,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/cli-runtime/pkg/resource"
 	"sigs.k8s.io/yaml"
 
@@ -48,6 +49,7 @@ import (
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/engine"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
@@ -58,10 +60,19 @@ import (
 	release "helm.sh/helm/v4/pkg/release/v1"
 	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
 	"helm.sh/helm/v4/pkg/repo/v1"
+	"helm.sh/helm/v4/pkg/secrets"
+	"helm.sh/helm/v4/pkg/signing"
 	"helm.sh/helm/v4/pkg/storage"
 	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
+// maxNameAttempts is how many release names are generated before giving up on
+// finding one that is not in use.
+const maxNameAttempts = 10
+
+// errNameInUse is returned when the release name belongs to an existing release.
+var errNameInUse = errors.New("cannot reuse a name that is still in use")
+
 // notesFileSuffix that we want to treat specially. It goes through the templating engine
 // but it's not a YAML file (resource) hence can't have hooks, etc. And the user actually
 // wants to see this file after rendering in the status command. However, it must be a suffix
@@ -86,9 +97,15 @@ type Install struct {
 	// ServerSideApply when true (default) will enable changes to be applied via Kubernetes server-side apply
 	// see: https://kubernetes.io/docs/reference/using-api/server-side-apply/
 	ServerSideApply bool
+	// Parallelism limits the number of resources created concurrently. Zero
+	// means no limit.
+	Parallelism     int
 	CreateNamespace bool
 	// DryRunStrategy can be set to prepare, but not execute the operation and whether or not to interact with the remote cluster
 	DryRunStrategy DryRunStrategy
+	// Preflight checks that the resources requested by the release fit into the
+	// namespace quotas and the cluster nodes before installing it
+	Preflight PreflightMode
 	// HideSecret can be set to true when DryRun is enabled in order to hide
 	// Kubernetes Secrets in the output. It cannot be used outside of DryRun.
 	HideSecret       bool
@@ -100,6 +117,7 @@ type Install struct {
 	Devel            bool
 	DependencyUpdate bool
 	Timeout          time.Duration
+	LockTimeout      time.Duration
 	Namespace        string
 	ReleaseName      string
 	GenerateName     bool
@@ -107,19 +125,40 @@ type Install struct {
 	Description      string
 	OutputDir        string
 	// RollbackOnFailure enables rolling back (uninstalling) the release on failure if set
-	RollbackOnFailure        bool
-	SkipCRDs                 bool
-	SubNotes                 bool
-	HideNotes                bool
-	SkipSchemaValidation     bool
+	RollbackOnFailure    bool
+	SkipCRDs             bool
+	SubNotes             bool
+	HideNotes            bool
+	SkipSchemaValidation bool
+	// StrictValues fails the install when the user supplied values contain
+	// keys that no template of the chart references.
+	StrictValues             bool
 	DisableOpenAPIValidation bool
 	IncludeCRDs              bool
 	Labels                   map[string]string
+	// ValuesTrace records which file or flag set each of the values passed to
+	// Run. It is stored with the release for 'helm get values --trace'.
+	ValuesTrace map[string]string
+	// Profile is the name of a values profile of the chart, such as "prod" for
+	// profiles/prod.yaml, overriding the chart's default values.
+	Profile string
+	// SecretProviders resolve the references to secrets in the values, such
+	// as vault://secret/data/db#password, for rendering. The release is stored
+	// with the references rather than the secrets. If nil, no reference is
+	// resolved.
+	SecretProviders secrets.Providers
+	// SecretReferences, if set, limits the references resolved to those it
+	// holds at the same keys as the values, such as the values set with
+	// --set-secret. Otherwise every reference in the values is resolved.
+	SecretReferences map[string]any
 	// KubeVersion allows specifying a custom kubernetes version to use and
 	// APIVersions allows a manual set of supported API Versions to be passed
 	// (for things like templating).
 	KubeVersion *common.KubeVersion
 	APIVersions common.VersionSet
+	// APIResources are the resources added to Capabilities.APIResources, and
+	// their versions to Capabilities.APIVersions, in client only mode.
+	APIResources common.APIResources
 	// Used by helm template to render charts with .Release.IsUpgrade. Ignored if Dry-Run is false
 	IsUpgrade bool
 	// Enable DNS lookups when rendering templates
@@ -127,18 +166,57 @@ type Install struct {
 	// Used by helm template to add the release as part of OutputDir path
 	// OutputDir/<ReleaseName>
 	UseReleaseName bool
+	// OutputDirLayout controls how the manifests written to OutputDir are
+	// split into files.
+	OutputDirLayout OutputDirLayout
 	// TakeOwnership will ignore the check for helm annotations and take ownership of the resources.
 	TakeOwnership bool
-	PostRenderer  postrenderer.PostRenderer
+	// AdoptExisting takes ownership of resources that already exist in the
+	// cluster and are not managed by Helm or another tool, for example
+	// resources created with kubectl. TakeOwnership also adopts resources
+	// managed by another tool or owned by another release.
+	AdoptExisting bool
+	// Resume continues a failed install of the release from its last
+	// checkpoint instead of starting over. Installs record checkpoints only
+	// when Resume is set, so a failed install can be resumed if it was run
+	// with Resume as well.
+	Resume       bool
+	PostRenderer postrenderer.PostRenderer
 	// PostRenderStrategy controls how hooks and regular templates are passed
 	// to the configured post-renderer. See PostRenderStrategy for the
 	// available modes. Defaults to PostRenderStrategyCombined.
 	PostRenderStrategy PostRenderStrategy
+	// ChangedValuesOnly renders only the templates referencing the user
+	// supplied values, which are taken to be the values that changed.
+	ChangedValuesOnly bool
+	// DevDependencies is used by helm template to load the dependencies with
+	// a "file://" repository from their directory instead of charts/. See
+	// LinkLocalDependencies.
+	DevDependencies bool
+	// Locked is used by the install and template commands to fail unless the
+	// dependencies of the chart match its lock file. See
+	// downloader.Manager.VerifyLock.
+	Locked bool
+	// OnRenderError, if set, is called when rendering the chart fails, with an
+	// evaluator of the chart templates and values for inspecting the failure.
+	OnRenderError func(ev *engine.Evaluator, err error)
 	// Lock to control raceconditions when the process receives a SIGTERM
 	Lock           sync.Mutex
 	goroutineCount atomic.Int32
 }
 
+// debugRenderError passes the render error to OnRenderError with an evaluator
+// configured like the engine that failed.
+func (i *Install) debugRenderError(ctx context.Context, chrt *chart.Chart, values common.Values, renderErr error) {
+	e := engine.Engine{EnableDNS: i.EnableDNS, CustomTemplateFuncs: i.cfg.CustomTemplateFuncs, LookupFixtures: i.cfg.LookupFixtures}
+	ev, err := e.NewEvaluator(ctx, chrt, values)
+	if err != nil {
+		i.cfg.Logger().Debug("unable to inspect render error", slog.Any("error", err))
+		return
+	}
+	i.OnRenderError(ev, renderErr)
+}
+
 // ChartPathOptions captures common options used for controlling chart paths
 type ChartPathOptions struct {
 	CaFile                string // --ca-file
@@ -154,6 +232,19 @@ type ChartPathOptions struct {
 	Verify                bool   // --verify
 	Version               string // --version
 
+	// VerifyKeyless verifies the Sigstore keyless signature of the chart,
+	// which must have been issued to CertificateIdentity by the
+	// CertificateOIDCIssuer, against the TrustedRoot file (--verify-keyless,
+	// --certificate-identity, --certificate-oidc-issuer, --trusted-root)
+	VerifyKeyless         bool
+	CertificateIdentity   string
+	CertificateOIDCIssuer string
+	TrustedRoot           string
+
+	// CacheMode controls when charts are read from the content cache
+	// (--use-cache, --no-cache)
+	CacheMode downloader.CacheMode
+
 	// registryClient provides a registry client but is not added with
 	// options from a flag
 	registryClient *registry.Client
@@ -305,16 +396,42 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 		return nil, errors.New("hiding Kubernetes secrets requires a dry-run mode")
 	}
 
+	if err := i.generateName(chrt, vals); err != nil {
+		i.cfg.Logger().Error("release name generation failed", slog.Any("error", err))
+		return nil, fmt.Errorf("release name generation failed: %w", err)
+	}
+
+	if !isDryRun(i.DryRunStrategy) {
+		lockCtx, unlock, err := i.cfg.Releases.Lock(ctx, i.ReleaseName, "install", i.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+		ctx = lockCtx
+	}
+
 	if err := i.availableName(); err != nil {
 		i.cfg.Logger().Error("release name check failed", slog.Any("error", err))
 		return nil, fmt.Errorf("release name check failed: %w", err)
 	}
 
+	if i.Profile != "" {
+		if err := chartutil.ApplyProfile(chrt, i.Profile); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
 		i.cfg.Logger().Error("chart dependencies processing failed", slog.Any("error", err))
 		return nil, fmt.Errorf("chart dependencies processing failed: %w", err)
 	}
 
+	if i.StrictValues {
+		if err := checkUnknownValues(chrt, vals); err != nil {
+			return nil, err
+		}
+	}
+
 	// Pre-install anything in the crd/ directory. We do this before Helm
 	// contacts the upstream server and builds the capabilities object.
 	if crds := chrt.CRDObjects(); interactWithServer(i.DryRunStrategy) && !i.SkipCRDs && len(crds) > 0 {
@@ -334,12 +451,14 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 			i.cfg.Capabilities.KubeVersion = *i.KubeVersion
 		}
 		i.cfg.Capabilities.APIVersions = append(i.cfg.Capabilities.APIVersions, i.APIVersions...)
+		i.cfg.Capabilities.APIVersions = append(i.cfg.Capabilities.APIVersions, i.APIResources.Versions()...)
+		i.cfg.Capabilities.APIResources = i.APIResources
 		i.cfg.KubeClient = &kubefake.PrintingKubeClient{Out: io.Discard}
 
 		mem := driver.NewMemory()
 		mem.SetNamespace(i.Namespace)
 		i.cfg.Releases = storage.Init(mem)
-	} else if interactWithServer(i.DryRunStrategy) && len(i.APIVersions) > 0 {
+	} else if interactWithServer(i.DryRunStrategy) && (len(i.APIVersions) > 0 || len(i.APIResources) > 0) {
 		i.cfg.Logger().Debug("API Version list given outside of client only mode, this list will be ignored")
 	}
 
@@ -363,7 +482,11 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 		IsInstall: !isUpgrade,
 		IsUpgrade: isUpgrade,
 	}
-	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(chrt, vals, options, caps, i.SkipSchemaValidation)
+	resolvedVals, err := resolveSecrets(ctx, i.SecretProviders, i.SecretReferences, vals)
+	if err != nil {
+		return nil, err
+	}
+	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(chrt, resolvedVals, options, caps, i.SkipSchemaValidation)
 	if err != nil {
 		return nil, err
 	}
@@ -374,14 +497,41 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 
 	rel := i.createRelease(chrt, vals, i.Labels)
 
+	var resumed *release.Release
+	if i.Resume && !isDryRun(i.DryRunStrategy) {
+		if resumed, err = i.resumableRelease(); err != nil {
+			return nil, err
+		}
+	}
+	if resumed != nil {
+		i.cfg.Logger().Info("resuming install from checkpoint", "release", rel.Name, "revision", resumed.Version, "applied", len(resumed.Info.Checkpoint.Applied))
+		rel.Version = resumed.Version
+		rel.Info.FirstDeployed = resumed.Info.FirstDeployed
+		rel.Info.Checkpoint = resumed.Info.Checkpoint
+	} else if i.Resume {
+		// Record checkpoints only for installs that can be resumed, as each
+		// checkpoint is a write to the storage
+		rel.Info.Checkpoint = &release.Checkpoint{}
+	}
+
+	var renderOnly []string
+	if i.ChangedValuesOnly {
+		if renderOnly, err = engine.AffectedTemplates(chrt, vals); err != nil {
+			return nil, err
+		}
+	}
+
 	var manifestDoc *bytes.Buffer
-	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(ctx, chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, interactWithServer(i.DryRunStrategy), i.EnableDNS, i.HideSecret, i.PostRenderStrategy)
+	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(ctx, chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.OutputDirLayout, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, interactWithServer(i.DryRunStrategy), i.EnableDNS, i.HideSecret, i.PostRenderStrategy, renderOnly)
 	// Even for errors, attach this if available
 	if manifestDoc != nil {
 		rel.Manifest = manifestDoc.String()
 	}
 	// Check error from render
 	if err != nil {
+		if i.OnRenderError != nil {
+			i.debugRenderError(ctx, chrt, valuesToRender, err)
+		}
 		rel.SetStatus(rcommon.StatusFailed, "failed to render resource: "+err.Error())
 		// Return a release with partial data so that the client can show debugging information.
 		return rel, err
@@ -409,14 +559,34 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 	// deleting the release because the manifest will be pointing at that
 	// resource
 	if interactWithServer(i.DryRunStrategy) && !isUpgrade && len(resources) > 0 {
-		if i.TakeOwnership {
+		switch {
+		case i.TakeOwnership:
 			toBeAdopted, err = requireAdoption(resources)
-		} else {
+		case i.AdoptExisting:
+			toBeAdopted, err = adoptExisting(resources, rel.Name, rel.Namespace)
+		default:
 			toBeAdopted, err = existingResourceConflict(resources, rel.Name, rel.Namespace)
 		}
 		if err != nil {
 			return nil, fmt.Errorf("unable to continue with install: %w", err)
 		}
+		if i.AdoptExisting {
+			for _, info := range toBeAdopted {
+				i.cfg.Logger().Info("adopting existing resource into release", "resource", resourceString(info), "release", rel.Name)
+			}
+		}
+	}
+
+	if interactWithServer(i.DryRunStrategy) {
+		if err := i.cfg.preflight(ctx, i.Preflight, "", rel.Manifest, i.Namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	if i.DryRunStrategy == DryRunServerAdmission {
+		if err := i.cfg.admissionDryRun(rel, resources, i.ServerSideApply, i.ForceConflicts); err != nil {
+			return nil, fmt.Errorf("unable to continue with install: %w", err)
+		}
 	}
 
 	// Bail out here if it is a dry run
@@ -461,8 +631,14 @@ func (i *Install) RunWithContext(ctx context.Context, ch ci.Charter, vals map[st
 		}
 	}
 
-	// Store the release in history before continuing. We always know that this is a create operation
-	if err := i.cfg.Releases.Create(rel); err != nil {
+	// Store the release in history before continuing. This is a create operation
+	// unless a failed install is resumed.
+	if resumed != nil {
+		err = i.cfg.Releases.Update(rel)
+	} else {
+		err = i.cfg.Releases.Create(rel)
+	}
+	if err != nil {
 		// We could try to recover gracefully here, but since nothing has been installed
 		// yet, this is probably safer than trying to continue when we know storage is
 		// not working.
@@ -505,31 +681,21 @@ func (i *Install) getGoroutineCount() int32 {
 
 func (i *Install) performInstall(rel *release.Release, toBeAdopted kube.ResourceList, resources kube.ResourceList) (*release.Release, error) {
 	var err error
-	// pre-install hooks
-	if !i.DisableHooks {
+	// pre-install hooks, unless they completed before the install was resumed
+	if !i.DisableHooks && (rel.Info.Checkpoint == nil || !rel.Info.Checkpoint.PreInstallHooks) {
 		if err := i.cfg.execHook(rel, release.HookPreInstall, i.WaitStrategy, i.WaitOptions, i.Timeout, i.ServerSideApply); err != nil {
 			return rel, fmt.Errorf("failed pre-install: %w", err)
 		}
+		if rel.Info.Checkpoint != nil {
+			rel.Info.Checkpoint.PreInstallHooks = true
+			i.recordCheckpoint(rel)
+		}
 	}
 
 	// At this point, we can do the install. Note that before we were detecting whether to
 	// do an update, but it's not clear whether we WANT to do an update if the reuse is set
 	// to true, since that is basically an upgrade operation.
-	if len(toBeAdopted) == 0 && len(resources) > 0 {
-		_, err = i.cfg.KubeClient.Create(
-			resources,
-			kube.ClientCreateOptionServerSideApply(i.ServerSideApply, false))
-	} else if len(resources) > 0 {
-		updateThreeWayMergeForUnstructured := i.TakeOwnership && !i.ServerSideApply // Use three-way merge when taking ownership (and not using server-side apply)
-		_, err = i.cfg.KubeClient.Update(
-			toBeAdopted,
-			resources,
-			kube.ClientUpdateOptionForceReplace(i.ForceReplace),
-			kube.ClientUpdateOptionServerSideApply(i.ServerSideApply, i.ForceConflicts),
-			kube.ClientUpdateOptionThreeWayMergeForUnstructured(updateThreeWayMergeForUnstructured),
-			kube.ClientUpdateOptionUpgradeClientSideFieldManager(true))
-	}
-	if err != nil {
+	if err := i.applyWithCheckpoints(rel, toBeAdopted, resources); err != nil {
 		return rel, err
 	}
 
@@ -558,6 +724,7 @@ func (i *Install) performInstall(rel *release.Release, toBeAdopted kube.Resource
 		}
 	}
 
+	rel.Info.Checkpoint = nil
 	if len(i.Description) > 0 {
 		rel.SetStatus(rcommon.StatusDeployed, i.Description)
 	} else {
@@ -605,6 +772,7 @@ func (i *Install) failRelease(rel *release.Release, err error) (*release.Release
 //   - too long
 //   - already in use, and not deleted
 //   - used by a deleted release, and i.Replace is false
+//   - used by a failed install, and neither i.Replace nor i.Resume is set
 func (i *Install) availableName() error {
 	start := i.ReleaseName
 
@@ -632,7 +800,10 @@ func (i *Install) availableName() error {
 	if st := rel.Info.Status; i.Replace && (st == rcommon.StatusUninstalled || st == rcommon.StatusFailed) {
 		return nil
 	}
-	return errors.New("cannot reuse a name that is still in use")
+	if i.Resume && isResumable(rel) {
+		return nil
+	}
+	return errNameInUse
 }
 
 func releaseListToV1List(ls []ri.Releaser) ([]*release.Release, error) {
@@ -662,10 +833,11 @@ func (i *Install) createRelease(chrt *chart.Chart, rawVals map[string]any, label
 	ts := i.cfg.Now()
 
 	r := &release.Release{
-		Name:      i.ReleaseName,
-		Namespace: i.Namespace,
-		Chart:     chrt,
-		Config:    rawVals,
+		Name:        i.ReleaseName,
+		Namespace:   i.Namespace,
+		Chart:       chrt,
+		Config:      rawVals,
+		ValuesTrace: i.ValuesTrace,
 		Info: &release.Info{
 			FirstDeployed: ts,
 			LastDeployed:  ts,
@@ -717,7 +889,7 @@ func (i *Install) replaceRelease(rel *release.Release) error {
 }
 
 // write the <data> to <output-dir>/<name>. <appendData> controls if the file is created or content will be appended
-func writeToFile(outputDir string, name string, data string, appendData bool) error {
+func writeToFile(outputDir string, name string, source string, data string, appendData bool) error {
 	outfileName := strings.Join([]string{outputDir, name}, string(filepath.Separator))
 
 	err := ensureDirectoryForFile(outfileName)
@@ -732,7 +904,7 @@ func writeToFile(outputDir string, name string, data string, appendData bool) er
 
 	defer f.Close()
 
-	_, err = fmt.Fprintf(f, "---\n# Source: %s\n%s\n", name, data)
+	_, err = fmt.Fprintf(f, "---\n# Source: %s\n%s\n", source, data)
 
 	if err != nil {
 		return err
@@ -762,7 +934,8 @@ func ensureDirectoryForFile(file string) error {
 
 // NameAndChart returns the name and chart that should be used.
 //
-// This will read the flags and handle name generation if necessary.
+// This will read the flags and handle name generation if necessary. A name
+// template is rendered again by Run, with the chart and values of the release.
 func (i *Install) NameAndChart(args []string) (string, string, error) {
 	flagsNotSet := func() error {
 		if i.GenerateName {
@@ -807,8 +980,32 @@ func (i *Install) NameAndChart(args []string) (string, string, error) {
 	return fmt.Sprintf("%s-%d", base, time.Now().Unix()), args[0], nil
 }
 
+// NameTemplateData is the data a release name template is rendered with.
+type NameTemplateData struct {
+	// ChartName is the name of the installed chart.
+	ChartName string
+	// ChartVersion is the version of the installed chart.
+	ChartVersion string
+	// AppVersion is the version of the application packaged by the chart.
+	AppVersion string
+	// Namespace is the namespace the release is installed in.
+	Namespace string
+	// Values are the values supplied by the user, without the chart defaults.
+	Values map[string]any
+}
+
 // TemplateName renders a name template, returning the name or an error.
 func TemplateName(nameTemplate string) (string, error) {
+	return renderNameTemplate(nameTemplate, nil)
+}
+
+// TemplateNameWithData renders a name template with the chart, namespace and
+// values of the release, returning the name or an error.
+func TemplateNameWithData(nameTemplate string, data NameTemplateData) (string, error) {
+	return renderNameTemplate(nameTemplate, data)
+}
+
+func renderNameTemplate(nameTemplate string, data any) (string, error) {
 	if nameTemplate == "" {
 		return "", nil
 	}
@@ -818,13 +1015,64 @@ func TemplateName(nameTemplate string) (string, error) {
 		return "", err
 	}
 	var b bytes.Buffer
-	if err := t.Execute(&b, nil); err != nil {
+	if err := t.Execute(&b, data); err != nil {
 		return "", err
 	}
 
 	return b.String(), nil
 }
 
+// generateName sets the release name from the name template, or makes the
+// generated name unique. As a generated name can collide with an existing
+// release, up to maxNameAttempts names are tried. A name template rendering a
+// name that was already tried always renders the same name, so it is not
+// rendered again.
+func (i *Install) generateName(chrt *chart.Chart, vals map[string]any) error {
+	if i.NameTemplate == "" && !i.GenerateName {
+		return nil
+	}
+
+	generated := i.ReleaseName
+	if generated == "" {
+		generated = fmt.Sprintf("%s-%d", chrt.Name(), time.Now().Unix())
+	}
+	data := NameTemplateData{
+		ChartName: chrt.Name(),
+		Namespace: i.Namespace,
+		Values:    vals,
+	}
+	if chrt.Metadata != nil {
+		data.ChartVersion = chrt.Metadata.Version
+		data.AppVersion = chrt.Metadata.AppVersion
+	}
+
+	tried := make(map[string]bool, maxNameAttempts)
+	for attempt := range maxNameAttempts {
+		name := generated
+		switch {
+		case i.NameTemplate != "":
+			var err error
+			if name, err = TemplateNameWithData(i.NameTemplate, data); err != nil {
+				return fmt.Errorf("unable to render name template: %w", err)
+			}
+			if tried[name] {
+				return fmt.Errorf("release name %q rendered from the name template: %w", name, errNameInUse)
+			}
+		case attempt > 0:
+			name = fmt.Sprintf("%s-%s", generated, rand.String(5))
+		}
+		tried[name] = true
+
+		i.ReleaseName = name
+		err := i.availableName()
+		if !errors.Is(err, errNameInUse) {
+			return err
+		}
+		i.cfg.Logger().Debug("generated release name is in use", "release", name)
+	}
+	return fmt.Errorf("unable to generate a release name that is not in use after %d attempts", maxNameAttempts)
+}
+
 // CheckDependencies checks the dependencies for a chart.
 func CheckDependencies(ch ci.Charter, reqs []ci.Dependency) error {
 	ac, err := ci.NewAccessor(ch)
@@ -877,6 +1125,26 @@ func urlEqual(u1, u2 *url.URL) bool {
 	return u1.Scheme == u2.Scheme && u1.Hostname() == u2.Hostname() && portOrDefault(u1) == portOrDefault(u2)
 }
 
+// KeylessVerifier returns the verifier of Sigstore keyless signatures
+// configured by the options.
+func (c *ChartPathOptions) KeylessVerifier() (*signing.Verifier, error) {
+	if c.TrustedRoot == "" {
+		return nil, errors.New("a Sigstore trusted root file is required to verify keyless signatures")
+	}
+	if c.CertificateIdentity == "" || c.CertificateOIDCIssuer == "" {
+		return nil, errors.New("the certificate identity and OIDC issuer are required to verify keyless signatures")
+	}
+	root, err := signing.LoadTrustedRoot(c.TrustedRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &signing.Verifier{
+		TrustedRoot:           root,
+		CertificateIdentity:   c.CertificateIdentity,
+		CertificateOIDCIssuer: c.CertificateOIDCIssuer,
+	}, nil
+}
+
 // LocateChart looks for a chart directory in known places, and returns either the full path or an error.
 //
 // This does not ensure that the chart is well-formed; only that the requested filename exists.
@@ -906,6 +1174,15 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 					return "", err
 				}
 			}
+			if c.VerifyKeyless {
+				verifier, err := c.KeylessVerifier()
+				if err != nil {
+					return "", err
+				}
+				if _, err := verifier.VerifyFile(abs, abs+signing.BundleExt); err != nil {
+					return "", fmt.Errorf("keyless verification of %s failed: %w", name, err)
+				}
+			}
 			return abs, nil
 		}
 		if filepath.IsAbs(name) || strings.HasPrefix(name, ".") {
@@ -927,6 +1204,7 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 		RepositoryConfig: settings.RepositoryConfig,
 		RepositoryCache:  settings.RepositoryCache,
 		ContentCache:     settings.ContentCache,
+		CacheMode:        c.CacheMode,
 		RegistryClient:   c.registryClient,
 	}
 
@@ -937,6 +1215,13 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 	if c.Verify {
 		dl.Verify = downloader.VerifyAlways
 	}
+	if c.VerifyKeyless {
+		verifier, err := c.KeylessVerifier()
+		if err != nil {
+			return "", err
+		}
+		dl.KeylessVerifier = verifier
+	}
 	if c.RepoURL != "" {
 		chartURL, err := repo.FindChartInRepoURL(
 			c.RepoURL,
@@ -991,3 +1276,12 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 	}
 	return lname, nil
 }
+
+// resolveSecrets returns a copy of vals with the references to secrets
+// resolved by the providers: only those refs holds, if it is set.
+func resolveSecrets(ctx context.Context, providers secrets.Providers, refs, vals map[string]any) (map[string]any, error) {
+	if refs != nil {
+		return providers.ResolveValuesAt(ctx, vals, refs)
+	}
+	return providers.ResolveValues(ctx, vals)
+}
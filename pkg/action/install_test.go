@@ -54,6 +54,7 @@ import (
 	"helm.sh/helm/v4/pkg/registry"
 	rcommon "helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/secrets"
 	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
@@ -367,6 +368,58 @@ func TestInstallRelease_WithNotes(t *testing.T) {
 	is.Equal(rel.Info.Notes, "note here")
 }
 
+type fakeSecretResolver map[string]string
+
+func (r fakeSecretResolver) Resolve(_ context.Context, ref *url.URL) (string, error) {
+	secret, ok := r[ref.String()]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return secret, nil
+}
+
+func TestInstallRelease_SecretReferences(t *testing.T) {
+	is := assert.New(t)
+	instAction := installAction(t)
+	instAction.SecretProviders = secrets.Providers{
+		{Schemes: []string{"vault"}, Resolver: fakeSecretResolver{"vault://secret/data/db#password": "hunter2"}},
+	}
+	ch := buildChartWithTemplates([]*common.File{
+		{Name: "templates/secret", ModTime: time.Now(), Data: []byte("password: {{ .Values.password }}")},
+	})
+	vals := map[string]any{"password": "vault://secret/data/db#password"}
+
+	resi, err := instAction.Run(ch, vals)
+	is.NoError(err)
+	rel, err := releaserToV1Release(resi)
+	is.NoError(err)
+	is.Contains(rel.Manifest, "password: hunter2")
+	is.Equal(map[string]any{"password": "vault://secret/data/db#password"}, rel.Config)
+
+	instAction = installAction(t)
+	instAction.SecretProviders = secrets.Providers{
+		{Schemes: []string{"vault"}, Resolver: fakeSecretResolver{}},
+	}
+	_, err = instAction.Run(ch, vals)
+	is.ErrorContains(err, "failed to resolve secret vault://secret/data/db#password")
+
+	// With SecretReferences, only the references it holds are resolved
+	instAction = installAction(t)
+	instAction.SecretProviders = secrets.Providers{
+		{Schemes: []string{"vault"}, Resolver: fakeSecretResolver{"vault://secret/data/db#password": "hunter2"}},
+	}
+	instAction.SecretReferences = map[string]any{"password": "vault://secret/data/db#password"}
+	ch = buildChartWithTemplates([]*common.File{
+		{Name: "templates/secret", ModTime: time.Now(), Data: []byte("password: {{ .Values.password }}\ntoken: {{ .Values.token }}")},
+	})
+	resi, err = instAction.Run(ch, map[string]any{"password": "vault://secret/data/db#password", "token": "vault://secret/data/api#token"})
+	is.NoError(err)
+	rel, err = releaserToV1Release(resi)
+	is.NoError(err)
+	is.Contains(rel.Manifest, "password: hunter2")
+	is.Contains(rel.Manifest, "token: vault://secret/data/api#token")
+}
+
 func TestInstallRelease_WithNotesRendered(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
@@ -438,7 +491,7 @@ func TestInstallRelease_WithChartAndDependencyAllNotes(t *testing.T) {
 }
 
 func TestInstallRelease_DryRunClient(t *testing.T) {
-	for _, dryRunStrategy := range []DryRunStrategy{DryRunClient, DryRunServer} {
+	for _, dryRunStrategy := range []DryRunStrategy{DryRunClient, DryRunServer, DryRunServerAdmission} {
 		is := assert.New(t)
 		instAction := installAction(t)
 		instAction.DryRunStrategy = dryRunStrategy
@@ -541,7 +594,7 @@ func TestInstallReleaseIncorrectTemplate_DryRun(t *testing.T) {
 	_, err := instAction.Run(buildChart(withSampleIncludingIncorrectTemplates()), vals)
 	expectedErr := `hello/templates/incorrect:1:10
   executing "hello/templates/incorrect" at <.Values.bad.doh>:
-    nil pointer evaluating interface {}.doh`
+    .Values.bad is nil (evaluating .Values.bad.doh)`
 	if err == nil {
 		t.Fatalf("Install should fail containing error: %s", expectedErr)
 	}
@@ -923,6 +976,11 @@ func TestNameAndChart(t *testing.T) {
 	is.Equal("cannot set --generate-name and also specify a name", err.Error())
 
 	instAction.GenerateName = false
+	instAction.NameTemplate = `{{ "foo" | upper }}-bar`
+	name, _, err = instAction.NameAndChart([]string{chartName})
+	is.NoError(err)
+	is.Equal("FOO-bar", name)
+
 	instAction.NameTemplate = "{{ . }}"
 	_, _, err = instAction.NameAndChart([]string{"foo", chartName})
 	if err == nil {
@@ -1006,6 +1064,70 @@ func TestNameAndChartGenerateName(t *testing.T) {
 	}
 }
 
+func TestInstallRelease_GeneratedName(t *testing.T) {
+	tests := []struct {
+		name         string
+		releaseName  string
+		generateName bool
+		nameTemplate string
+		existing     string
+		wantName     string
+		wantPrefix   string
+		wantErr      string
+	}{
+		{
+			name:         "template with chart, namespace and values",
+			nameTemplate: `{{ .ChartName }}-{{ .Namespace }}-{{ .Values.env }}-{{ .ChartVersion | replace "." "-" }}`,
+			wantName:     "hello-spaced-prod-0-1-0",
+		},
+		{
+			name:         "generated name without name from arguments",
+			generateName: true,
+			wantPrefix:   "hello-",
+		},
+		{
+			name:         "generated name in use is regenerated",
+			releaseName:  "hello-1",
+			generateName: true,
+			existing:     "hello-1",
+			wantPrefix:   "hello-1-",
+		},
+		{
+			name:         "template always rendering a name in use",
+			nameTemplate: "{{ .ChartName }}",
+			existing:     "hello",
+			wantErr:      `release name "hello" rendered from the name template: cannot reuse a name that is still in use`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instAction := installAction(t)
+			instAction.ReleaseName = tt.releaseName
+			instAction.GenerateName = tt.generateName
+			instAction.NameTemplate = tt.nameTemplate
+			if tt.existing != "" {
+				rel := releaseStub()
+				rel.Name = tt.existing
+				require.NoError(t, instAction.cfg.Releases.Create(rel))
+			}
+
+			resi, err := instAction.Run(buildChart(), map[string]any{"env": "prod"})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			res, err := releaserToV1Release(resi)
+			require.NoError(t, err)
+			if tt.wantName != "" {
+				assert.Equal(t, tt.wantName, res.Name)
+			}
+			assert.True(t, strings.HasPrefix(res.Name, tt.wantPrefix), "name %q", res.Name)
+			assert.NotEqual(t, tt.existing, res.Name)
+		})
+	}
+}
+
 func TestInstallWithLabels(t *testing.T) {
 	is := assert.New(t)
 	instAction := installAction(t)
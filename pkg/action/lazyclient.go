@@ -20,6 +20,7 @@ import (
 	"context"
 	"sync"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -27,6 +28,8 @@ import (
 	applycorev1 "k8s.io/client-go/applyconfigurations/core/v1"
 	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"helm.sh/helm/v4/pkg/storage"
 )
 
 // lazyClient is a workaround to deal with Kubernetes having an unstable client API.
@@ -195,3 +198,40 @@ func (c *configMapClient) Apply(ctx context.Context, configMap *applycorev1.Conf
 	}
 	return c.client.CoreV1().ConfigMaps(c.namespace).Apply(ctx, configMap, opts)
 }
+
+// leaseClient implements a storage.LeaseClient
+type leaseClient struct{ *lazyClient }
+
+var _ storage.LeaseClient = (*leaseClient)(nil)
+
+func newLeaseClient(lc *lazyClient) *leaseClient {
+	return &leaseClient{lazyClient: lc}
+}
+
+func (l *leaseClient) Get(ctx context.Context, name string, opts metav1.GetOptions) (*coordinationv1.Lease, error) {
+	if err := l.init(); err != nil {
+		return nil, err
+	}
+	return l.client.CoordinationV1().Leases(l.namespace).Get(ctx, name, opts)
+}
+
+func (l *leaseClient) Create(ctx context.Context, lease *coordinationv1.Lease, opts metav1.CreateOptions) (*coordinationv1.Lease, error) {
+	if err := l.init(); err != nil {
+		return nil, err
+	}
+	return l.client.CoordinationV1().Leases(l.namespace).Create(ctx, lease, opts)
+}
+
+func (l *leaseClient) Update(ctx context.Context, lease *coordinationv1.Lease, opts metav1.UpdateOptions) (*coordinationv1.Lease, error) {
+	if err := l.init(); err != nil {
+		return nil, err
+	}
+	return l.client.CoordinationV1().Leases(l.namespace).Update(ctx, lease, opts)
+}
+
+func (l *leaseClient) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	if err := l.init(); err != nil {
+		return err
+	}
+	return l.client.CoordinationV1().Leases(l.namespace).Delete(ctx, name, opts)
+}
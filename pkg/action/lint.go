@@ -38,6 +38,14 @@ type Lint struct {
 	Quiet                bool
 	SkipSchemaValidation bool
 	KubeVersion          *common.KubeVersion
+	// RuleConfig overrides the severity of lint rules by ID, over the
+	// .helmlintrc file of each chart.
+	RuleConfig support.RuleConfig
+	// Registry holds the lint rules. It defaults to lint.DefaultRegistry.
+	Registry *support.Registry
+	// KubeLint checks the rendered resources against the best practices of
+	// Kubernetes.
+	KubeLint bool
 }
 
 // LintResult is the result of Lint
@@ -60,7 +68,7 @@ func (l *Lint) Run(paths []string, vals map[string]any) *LintResult {
 	}
 	result := &LintResult{}
 	for _, path := range paths {
-		linter, err := lintChart(path, vals, l.Namespace, l.KubeVersion, l.SkipSchemaValidation)
+		linter, err := lintChart(path, vals, l.Namespace, l.KubeVersion, l.SkipSchemaValidation, l.linterOptions()...)
 		if err != nil {
 			result.Errors = append(result.Errors, err)
 			continue
@@ -77,6 +85,33 @@ func (l *Lint) Run(paths []string, vals map[string]any) *LintResult {
 	return result
 }
 
+// linterOptions returns the linter options configuring the lint rules.
+func (l *Lint) linterOptions() []lint.LinterOption {
+	options := []lint.LinterOption{lint.WithRuleConfig(l.RuleConfig), lint.WithKubeLint(l.KubeLint)}
+	if l.Registry != nil {
+		options = append(options, lint.WithRegistry(l.Registry))
+	}
+	return options
+}
+
+// registry returns the registry of the lint rules.
+func (l *Lint) registry() *support.Registry {
+	if l.Registry == nil {
+		return lint.DefaultRegistry
+	}
+	return l.Registry
+}
+
+// Rules returns the lint rules, sorted by ID.
+func (l *Lint) Rules() []support.Rule {
+	return l.registry().Rules()
+}
+
+// ValidateRuleConfig checks that a configuration only sets known lint rules.
+func (l *Lint) ValidateRuleConfig(config support.RuleConfig) error {
+	return l.registry().Validate(config)
+}
+
 // HasWarningsOrErrors checks is LintResult has any warnings or errors
 func HasWarningsOrErrors(result *LintResult) bool {
 	for _, msg := range result.Messages {
@@ -87,7 +122,7 @@ func HasWarningsOrErrors(result *LintResult) bool {
 	return len(result.Errors) > 0
 }
 
-func lintChart(path string, vals map[string]any, namespace string, kubeVersion *common.KubeVersion, skipSchemaValidation bool) (support.Linter, error) {
+func lintChart(path string, vals map[string]any, namespace string, kubeVersion *common.KubeVersion, skipSchemaValidation bool, options ...lint.LinterOption) (support.Linter, error) {
 	var chartPath string
 	linter := support.Linter{}
 
@@ -130,7 +165,9 @@ func lintChart(path string, vals map[string]any, namespace string, kubeVersion *
 		chartPath,
 		vals,
 		namespace,
-		lint.WithKubeVersion(kubeVersion),
-		lint.WithSkipSchemaValidation(skipSchemaValidation),
+		append([]lint.LinterOption{
+			lint.WithKubeVersion(kubeVersion),
+			lint.WithSkipSchemaValidation(skipSchemaValidation),
+		}, options...)...,
 	), nil
 }
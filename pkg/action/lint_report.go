@@ -0,0 +1,283 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	"helm.sh/helm/v4/pkg/cli/output"
+)
+
+// LintFormat is a format of the report of linting charts.
+type LintFormat string
+
+// The formats of the report of linting charts.
+const (
+	// LintFormatJSON writes the messages of each chart as JSON.
+	LintFormatJSON LintFormat = "json"
+	// LintFormatSARIF writes a SARIF 2.1.0 log, for the code scanning of CI
+	// systems.
+	LintFormatSARIF LintFormat = "sarif"
+	// LintFormatGitHub writes GitHub Actions workflow commands, which
+	// annotate the files of a pull request with the messages.
+	LintFormatGitHub LintFormat = "github"
+)
+
+// LintFormats are the formats of the report of linting charts.
+var LintFormats = []LintFormat{LintFormatJSON, LintFormatSARIF, LintFormatGitHub}
+
+// LintChartResult is the result of linting the chart at a path.
+type LintChartResult struct {
+	Path   string
+	Result *LintResult
+}
+
+// lintJSONChart is the result of linting a chart written as JSON.
+type lintJSONChart struct {
+	Chart    string            `json:"chart"`
+	Messages []lintJSONMessage `json:"messages"`
+	Errors   []string          `json:"errors,omitempty"`
+}
+
+type lintJSONMessage struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Rule     string `json:"rule,omitempty"`
+	Message  string `json:"message"`
+}
+
+// WriteReport writes the results of linting charts in the given format.
+func (l *Lint) WriteReport(out io.Writer, format LintFormat, report []LintChartResult) error {
+	switch format {
+	case LintFormatJSON:
+		return output.EncodeJSON(out, jsonLintReport(report))
+	case LintFormatSARIF:
+		return output.EncodeJSON(out, sarifLintReport(report, l.registry()))
+	case LintFormatGitHub:
+		return writeGitHubLintReport(out, report)
+	}
+	return fmt.Errorf("unknown lint report format %q", format)
+}
+
+// jsonLintReport returns the results of linting charts written as JSON.
+func jsonLintReport(report []LintChartResult) []lintJSONChart {
+	charts := []lintJSONChart{}
+	for _, c := range report {
+		chart := lintJSONChart{Chart: c.Path, Messages: []lintJSONMessage{}}
+		for _, msg := range c.Result.Messages {
+			chart.Messages = append(chart.Messages, lintJSONMessage{
+				Severity: support.SeverityName(msg.Severity),
+				Path:     msg.Path,
+				Rule:     msg.RuleID,
+				Message:  msg.Err.Error(),
+			})
+		}
+		for _, err := range chartErrors(c.Result) {
+			chart.Errors = append(chart.Errors, err.Error())
+		}
+		charts = append(charts, chart)
+	}
+	return charts
+}
+
+// chartErrors returns the errors of a chart that failed to lint. The errors
+// of the failed rules are already in its messages.
+func chartErrors(result *LintResult) []error {
+	if len(result.Messages) != 0 {
+		return nil
+	}
+	return result.Errors
+}
+
+// The SARIF 2.1.0 log of helm lint, for the code scanning of CI systems. See
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	ShortDescription     sarifMessage      `json:"shortDescription"`
+	DefaultConfiguration sarifRuleSettings `json:"defaultConfiguration"`
+}
+
+type sarifRuleSettings struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifLintReport returns the SARIF log of the results of linting charts,
+// describing the rules that failed.
+func sarifLintReport(report []LintChartResult, registry *support.Registry) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "helm lint",
+			InformationURI: "https://helm.sh/docs/helm/helm_lint/",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	described := map[string]bool{}
+	for _, c := range report {
+		for _, msg := range c.Result.Messages {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    msg.RuleID,
+				Level:     sarifLevel(msg.Severity),
+				Message:   sarifMessage{Text: msg.Err.Error()},
+				Locations: []sarifLocation{sarifFileLocation(c.Path, msg.Path)},
+			})
+			if msg.RuleID == "" || described[msg.RuleID] {
+				continue
+			}
+			described[msg.RuleID] = true
+			if rule, ok := registry.Lookup(msg.RuleID); ok {
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+					ID:                   rule.ID,
+					ShortDescription:     sarifMessage{Text: rule.Description},
+					DefaultConfiguration: sarifRuleSettings{Level: sarifLevel(rule.Severity)},
+				})
+			}
+		}
+		for _, err := range chartErrors(c.Result) {
+			run.Results = append(run.Results, sarifResult{
+				Level:     sarifLevel(support.ErrorSev),
+				Message:   sarifMessage{Text: err.Error()},
+				Locations: []sarifLocation{sarifFileLocation(c.Path, "")},
+			})
+		}
+	}
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+}
+
+// sarifLevel returns the SARIF level of a severity.
+func sarifLevel(severity int) string {
+	switch severity {
+	case support.ErrorSev:
+		return "error"
+	case support.WarningSev:
+		return "warning"
+	}
+	return "note"
+}
+
+// sarifFileLocation returns the location of a file of a chart.
+func sarifFileLocation(chartPath, path string) sarifLocation {
+	return sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: chartFile(chartPath, path)}}}
+}
+
+// chartFile returns the slash-separated path of a file of a chart. The paths
+// of the messages are relative to the chart, unless absolute.
+func chartFile(chartPath, path string) string {
+	file := chartPath
+	if filepath.IsAbs(path) {
+		file = path
+	} else if path != "" {
+		file = filepath.Join(chartPath, path)
+	}
+	return strings.TrimSuffix(filepath.ToSlash(file), "/")
+}
+
+// writeGitHubLintReport writes the messages of linting charts as GitHub
+// Actions workflow commands. See
+// https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions.
+func writeGitHubLintReport(out io.Writer, report []LintChartResult) error {
+	for _, c := range report {
+		for _, msg := range c.Result.Messages {
+			title := "helm lint"
+			if msg.RuleID != "" {
+				title += ": " + msg.RuleID
+			}
+			if err := writeGitHubCommand(out, msg.Severity, chartFile(c.Path, msg.Path), title, msg.Err.Error()); err != nil {
+				return err
+			}
+		}
+		for _, err := range chartErrors(c.Result) {
+			if err := writeGitHubCommand(out, support.ErrorSev, chartFile(c.Path, ""), "helm lint", err.Error()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeGitHubCommand writes the workflow command annotating a file.
+func writeGitHubCommand(out io.Writer, severity int, file, title, message string) error {
+	command := "notice"
+	switch severity {
+	case support.ErrorSev:
+		command = "error"
+	case support.WarningSev:
+		command = "warning"
+	}
+	_, err := fmt.Fprintf(out, "::%s file=%s,title=%s::%s\n", command, githubPropertyEscaper.Replace(file), githubPropertyEscaper.Replace(title), githubDataEscaper.Replace(message))
+	return err
+}
+
+// The escaping of the data and the properties of workflow commands.
+var (
+	githubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
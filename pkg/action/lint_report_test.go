@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+func lintReportFixture() []LintChartResult {
+	return []LintChartResult{{
+		Path: "charts/web",
+		Result: &LintResult{Messages: []support.Message{
+			{Severity: support.WarningSev, Path: "templates/deployment.yaml", Err: errors.New("container \"web\" has no cpu, memory limit\n50% of the time"), RuleID: "kube-resource-limits"},
+			{Severity: support.InfoSev, Path: "Chart.yaml", Err: errors.New("icon is recommended"), RuleID: "chart-icon"},
+		}},
+	}, {
+		Path:   "charts/missing",
+		Result: &LintResult{Errors: []error{errors.New("unable to check Chart.yaml file in chart")}},
+	}}
+}
+
+func TestLintWriteReportGitHub(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, NewLint().WriteReport(&out, LintFormatGitHub, lintReportFixture()))
+
+	expected := "::warning file=charts/web/templates/deployment.yaml,title=helm lint%3A kube-resource-limits::container \"web\" has no cpu, memory limit%0A50%25 of the time\n" +
+		"::notice file=charts/web/Chart.yaml,title=helm lint%3A chart-icon::icon is recommended\n" +
+		"::error file=charts/missing,title=helm lint::unable to check Chart.yaml file in chart\n"
+	assert.Equal(t, expected, out.String())
+}
+
+func TestLintWriteReportSARIF(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, NewLint().WriteReport(&out, LintFormatSARIF, lintReportFixture()))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(out.Bytes(), &log))
+	require.Len(t, log.Runs, 1)
+
+	var rules []string
+	for _, rule := range log.Runs[0].Tool.Driver.Rules {
+		rules = append(rules, rule.ID)
+	}
+	assert.Equal(t, []string{"kube-resource-limits", "chart-icon"}, rules)
+
+	results := log.Runs[0].Results
+	require.Len(t, results, 3)
+	assert.Equal(t, "warning", results[0].Level)
+	assert.Equal(t, "note", results[1].Level)
+	assert.Equal(t, "error", results[2].Level)
+	assert.Empty(t, results[2].RuleID)
+	assert.Equal(t, "charts/web/templates/deployment.yaml", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+func TestLintWriteReportUnknownFormat(t *testing.T) {
+	err := NewLint().WriteReport(&bytes.Buffer{}, LintFormat("xml"), nil)
+	assert.EqualError(t, err, `unknown lint report format "xml"`)
+}
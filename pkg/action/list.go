@@ -17,14 +17,21 @@ limitations under the License.
 package action
 
 import (
+	"errors"
+	"fmt"
 	"path"
 	"regexp"
+	"strings"
+	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"k8s.io/apimachinery/pkg/labels"
 
+	"helm.sh/helm/v4/pkg/chart/common/util"
 	ri "helm.sh/helm/v4/pkg/release"
 	release "helm.sh/helm/v4/pkg/release/v1"
 	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
 // ListStates represents zero or more status codes that a list item may have set
@@ -135,6 +142,23 @@ type List struct {
 	Failed       bool
 	Pending      bool
 	Selector     string
+	// ChunkSize is the maximum number of stored releases retrieved per
+	// request by the storage drivers listing releases in chunks, such as
+	// the Secret and ConfigMap drivers. Zero retrieves them all at once.
+	ChunkSize int64
+	// ChartName only lists the releases of the chart with this name.
+	ChartName string
+	// AppVersion is a semantic version constraint, such as ">=1.25", that
+	// the app version of the listed releases must satisfy.
+	AppVersion string
+	// DeployedBefore and DeployedAfter, when set, only list the releases
+	// last deployed before, or after, these times.
+	DeployedBefore time.Time
+	DeployedAfter  time.Time
+	// HasValues only lists the releases whose values, including the chart
+	// defaults, hold every one of these path=value pairs, such as
+	// image.tag=1.2.3.
+	HasValues []string
 }
 
 // NewList constructs a new *List
@@ -160,7 +184,16 @@ func (l *List) Run() ([]ri.Releaser, error) {
 		}
 	}
 
-	results, err := l.cfg.Releases.List(func(rel ri.Releaser) bool {
+	match, err := l.matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	if lister, ok := l.cfg.Releases.Driver.(driver.ChunkedLister); ok {
+		lister.SetListChunkSize(l.ChunkSize)
+	}
+
+	results, err := l.list(func(rel ri.Releaser) bool {
 		r, err := releaserToV1Release(rel)
 		if err != nil {
 			return false
@@ -204,6 +237,8 @@ func (l *List) Run() ([]ri.Releaser, error) {
 	}
 	rresults = l.filterSelector(rresults, selectorObj)
 
+	rresults = filterReleases(rresults, match)
+
 	// Unfortunately, we have to sort before truncating, which can incur substantial overhead
 	l.sort(rresults)
 
@@ -226,6 +261,36 @@ func (l *List) Run() ([]ri.Releaser, error) {
 	return releaseV1ListToReleaserList(rresults)
 }
 
+// list returns the stored releases such that filter(release) == true.
+//
+// Only the latest revision of each release is usually shown, which requires
+// all the revisions to be retrieved. Superseded revisions are never the
+// latest ones though, so when listing only those the storage backend is
+// queried for them directly rather than retrieving every revision.
+func (l *List) list(filter func(ri.Releaser) bool) ([]ri.Releaser, error) {
+	if l.StateMask != ListSuperseded {
+		return l.cfg.Releases.List(filter)
+	}
+
+	rels, err := l.cfg.Releases.Query(map[string]string{
+		"owner":  "helm",
+		"status": "superseded",
+	})
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var results []ri.Releaser
+	for _, rel := range rels {
+		if filter(rel) {
+			results = append(results, rel)
+		}
+	}
+	return results, nil
+}
+
 // sort is an in-place sort where order is based on the value of a.Sort
 func (l *List) sort(rels []*release.Release) {
 	if l.SortReverse {
@@ -298,6 +363,84 @@ func (l *List) filterSelector(releases []*release.Release, selector labels.Selec
 	return desiredStateReleases
 }
 
+// matcher returns a function reporting whether a release matches the chart
+// name, app version, deploy time and value filters of the list.
+func (l *List) matcher() (func(*release.Release) bool, error) {
+	var appVersion *semver.Constraints
+	if l.AppVersion != "" {
+		var err error
+		appVersion, err = semver.NewConstraint(l.AppVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid app version constraint %q: %w", l.AppVersion, err)
+		}
+	}
+
+	values := make(map[string]string, len(l.HasValues))
+	for _, v := range l.HasValues {
+		key, val, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid value filter %q, expected path=value", v)
+		}
+		values[key] = val
+	}
+
+	return func(rls *release.Release) bool {
+		// the cheap checks go first, values are only computed when needed
+		if l.ChartName != "" && (rls.Chart == nil || rls.Chart.Name() != l.ChartName) {
+			return false
+		}
+		if appVersion != nil {
+			if rls.Chart == nil || rls.Chart.Metadata == nil {
+				return false
+			}
+			v, err := semver.NewVersion(rls.Chart.Metadata.AppVersion)
+			if err != nil || !appVersion.Check(v) {
+				return false
+			}
+		}
+		if !l.DeployedBefore.IsZero() || !l.DeployedAfter.IsZero() {
+			if rls.Info == nil {
+				return false
+			}
+			deployed := rls.Info.LastDeployed
+			if !l.DeployedBefore.IsZero() && !deployed.Before(l.DeployedBefore) {
+				return false
+			}
+			if !l.DeployedAfter.IsZero() && !deployed.After(l.DeployedAfter) {
+				return false
+			}
+		}
+		if len(values) == 0 {
+			return true
+		}
+		if rls.Chart == nil {
+			return false
+		}
+		vals, err := util.CoalesceValues(rls.Chart, rls.Config)
+		if err != nil {
+			return false
+		}
+		for key, want := range values {
+			got, err := vals.PathValue(key)
+			if err != nil || fmt.Sprint(got) != want {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// filterReleases returns the releases such that match(release) == true.
+func filterReleases(releases []*release.Release, match func(*release.Release) bool) []*release.Release {
+	matching := make([]*release.Release, 0, len(releases))
+	for _, rls := range releases {
+		if match(rls) {
+			matching = append(matching, rls)
+		}
+	}
+	return matching
+}
+
 // SetStateMask calculates the state mask based on parameters.
 func (l *List) SetStateMask() {
 	if l.All {
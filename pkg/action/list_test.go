@@ -20,6 +20,7 @@ import (
 	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -28,6 +29,7 @@ import (
 	"helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
 	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
 func TestListStates(t *testing.T) {
@@ -277,6 +279,60 @@ func makeMeSomeReleasesWithStaleFailure(t *testing.T, store *storage.Storage) {
 	assert.Len(t, all, 5, "sanity test: five items added")
 }
 
+func TestList_SupersededOnly(t *testing.T) {
+	is := assert.New(t)
+	lister := newListFixture(t)
+	lister.StateMask = ListSuperseded
+	lister.Filter = "^dirty$"
+
+	makeMeSomeReleasesWithStaleFailure(t, lister.cfg.Releases)
+	superseded := namedReleaseStub("dirty", common.StatusSuperseded)
+	superseded.Namespace = "default"
+	superseded.Version = 4
+	other := namedReleaseStub("other", common.StatusSuperseded)
+	other.Namespace = "default"
+	for _, rel := range []*release.Release{superseded, other} {
+		is.NoError(lister.cfg.Releases.Create(rel))
+	}
+
+	res, err := lister.Run()
+	is.NoError(err)
+	is.Len(res, 1)
+	rel, err := releaserToV1Release(res[0])
+	is.NoError(err)
+	is.Equal("dirty", rel.Name)
+	is.Equal(4, rel.Version)
+
+	lister.Filter = "none"
+	res, err = lister.Run()
+	is.NoError(err)
+	is.Empty(res)
+}
+
+// chunkedMemory is a memory driver recording the chunk size it is given.
+type chunkedMemory struct {
+	*driver.Memory
+	chunkSize int64
+}
+
+func (mem *chunkedMemory) SetListChunkSize(size int64) {
+	mem.chunkSize = size
+}
+
+func TestList_ChunkSize(t *testing.T) {
+	is := assert.New(t)
+	lister := newListFixture(t)
+	mem := &chunkedMemory{Memory: driver.NewMemory()}
+	lister.cfg.Releases = storage.Init(mem)
+	lister.ChunkSize = 50
+	makeMeSomeReleases(t, lister.cfg.Releases)
+
+	res, err := lister.Run()
+	is.NoError(err)
+	is.Len(res, 3)
+	is.Equal(int64(50), mem.chunkSize)
+}
+
 func TestList_Filter(t *testing.T) {
 	is := assert.New(t)
 	lister := newListFixture(t)
@@ -291,6 +347,103 @@ func TestList_Filter(t *testing.T) {
 	is.Equal("three", ac0.Name())
 }
 
+func TestList_AttributeFilters(t *testing.T) {
+	now := time.Now()
+	rel := func(name, chartName, appVersion string, deployed time.Time, tag string) *release.Release {
+		r := namedReleaseStub(name, common.StatusDeployed)
+		r.Chart = buildChart(withName(chartName), withValues(map[string]any{
+			"image": map[string]any{"repository": "nginx", "tag": "latest"},
+		}))
+		r.Chart.Metadata.AppVersion = appVersion
+		r.Config = map[string]any{"image": map[string]any{"tag": tag}}
+		r.Info.LastDeployed = deployed
+		return r
+	}
+
+	tests := []struct {
+		name   string
+		setup  func(*List)
+		expect []string
+	}{
+		{
+			name:   "chart name",
+			setup:  func(l *List) { l.ChartName = "nginx" },
+			expect: []string{"new", "old"},
+		},
+		{
+			name:   "app version",
+			setup:  func(l *List) { l.AppVersion = ">=1.25" },
+			expect: []string{"new", "redis"},
+		},
+		{
+			name:   "deployed before",
+			setup:  func(l *List) { l.DeployedBefore = now.Add(-24 * time.Hour) },
+			expect: []string{"old"},
+		},
+		{
+			name:   "deployed after",
+			setup:  func(l *List) { l.DeployedAfter = now.Add(-24 * time.Hour) },
+			expect: []string{"new", "redis"},
+		},
+		{
+			name:   "user supplied value",
+			setup:  func(l *List) { l.HasValues = []string{"image.tag=1.2.3"} },
+			expect: []string{"new", "redis"},
+		},
+		{
+			name:   "chart default value",
+			setup:  func(l *List) { l.HasValues = []string{"image.repository=nginx", "image.tag=1.0.0"} },
+			expect: []string{"old"},
+		},
+		{
+			name: "combined",
+			setup: func(l *List) {
+				l.ChartName = "nginx"
+				l.HasValues = []string{"image.tag=1.2.3"}
+			},
+			expect: []string{"new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := assert.New(t)
+			lister := newListFixture(t)
+			for _, r := range []*release.Release{
+				rel("old", "nginx", "1.24.0", now.Add(-48*time.Hour), "1.0.0"),
+				rel("new", "nginx", "1.25.3", now, "1.2.3"),
+				rel("redis", "redis", "7.2.0", now, "1.2.3"),
+			} {
+				is.NoError(lister.cfg.Releases.Create(r))
+			}
+			tt.setup(lister)
+
+			res, err := lister.Run()
+			is.NoError(err)
+			var names []string
+			for _, r := range res {
+				ac, err := ri.NewAccessor(r)
+				is.NoError(err)
+				names = append(names, ac.Name())
+			}
+			is.Equal(tt.expect, names)
+		})
+	}
+}
+
+func TestList_AttributeFiltersInvalid(t *testing.T) {
+	is := assert.New(t)
+	lister := newListFixture(t)
+	lister.AppVersion = ">=not-a-version"
+	_, err := lister.Run()
+	is.ErrorContains(err, "invalid app version constraint")
+
+	lister = newListFixture(t)
+	lister.HasValues = []string{"image.tag"}
+	_, err = lister.Run()
+	is.ErrorContains(err, "expected path=value")
+}
+
 func TestList_FilterFailsCompile(t *testing.T) {
 	is := assert.New(t)
 	lister := newListFixture(t)
@@ -0,0 +1,147 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// manifestSourceRegex extracts the chart-relative template path of a rendered
+// manifest from its "# Source:" comment.
+var manifestSourceRegex = regexp.MustCompile("# Source: [^/]+/(.+)")
+
+// ManifestFilter selects rendered manifests by the template they come from or
+// by the resource they define. A manifest is selected when it matches any of
+// the criteria.
+type ManifestFilter struct {
+	// Templates are glob patterns matched against template paths relative to
+	// the chart, such as "templates/*deploy*". A pattern matching a directory
+	// selects the templates in it.
+	Templates []string
+	// TemplateRegexes are regular expressions matched against template paths
+	// relative to the chart.
+	TemplateRegexes []*regexp.Regexp
+	// Resources select resources as KIND or KIND/NAME, where the kind is
+	// matched case-insensitively and the name can be a glob pattern.
+	Resources []string
+}
+
+// Empty returns whether the filter selects all manifests.
+func (f ManifestFilter) Empty() bool {
+	return len(f.Templates) == 0 && len(f.TemplateRegexes) == 0 && len(f.Resources) == 0
+}
+
+// Filter splits manifests into documents and returns the selected ones. The
+// documents are grouped by criterion, in the order the criteria are given,
+// and each document is returned once. It is an error for a criterion to match
+// no document.
+func (f ManifestFilter) Filter(manifests string) ([]string, error) {
+	split := releaseutil.SplitManifests(manifests)
+	keys := slices.Collect(maps.Keys(split))
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+	docs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		docs = append(docs, split[k])
+	}
+
+	var selected []string
+	seen := map[int]bool{}
+	selectDocs := func(match func(doc string) bool) bool {
+		found := false
+		for n, doc := range docs {
+			if !match(doc) {
+				continue
+			}
+			found = true
+			if !seen[n] {
+				seen[n] = true
+				selected = append(selected, doc)
+			}
+		}
+		return found
+	}
+
+	for _, pattern := range f.Templates {
+		// Use linux-style filepath separators to unify user's input path
+		pattern = path.Clean(strings.ReplaceAll(pattern, "\\", "/"))
+		if !selectDocs(func(doc string) bool { return matchTemplatePath(pattern, manifestSource(doc)) }) {
+			return nil, fmt.Errorf("could not find template %s in chart", pattern)
+		}
+	}
+	for _, re := range f.TemplateRegexes {
+		if !selectDocs(func(doc string) bool {
+			source := manifestSource(doc)
+			return source != "" && re.MatchString(source)
+		}) {
+			return nil, fmt.Errorf("could not find template matching %s in chart", re)
+		}
+	}
+	for _, resource := range f.Resources {
+		kind, name, _ := strings.Cut(resource, "/")
+		if !selectDocs(func(doc string) bool { return matchResource(kind, name, doc) }) {
+			return nil, fmt.Errorf("could not find resource %s in rendered manifests", resource)
+		}
+	}
+	return selected, nil
+}
+
+// manifestSource returns the chart-relative template path of a manifest.
+func manifestSource(doc string) string {
+	submatch := manifestSourceRegex.FindStringSubmatch(doc)
+	if len(submatch) == 0 {
+		return ""
+	}
+	return submatch[1]
+}
+
+// matchTemplatePath returns whether a glob pattern matches a template path or
+// one of its parent directories.
+func matchTemplatePath(pattern, source string) bool {
+	for p := source; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		if matched, _ := path.Match(pattern, p); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchResource returns whether a manifest defines a resource of the kind, and
+// of a name matching the glob pattern if one is given.
+func matchResource(kind, name, doc string) bool {
+	var head releaseutil.SimpleHead
+	if err := yaml.Unmarshal([]byte(doc), &head); err != nil || !strings.EqualFold(head.Kind, kind) {
+		return false
+	}
+	if name == "" {
+		return true
+	}
+	if head.Metadata == nil {
+		return false
+	}
+	matched, _ := path.Match(name, head.Metadata.Name)
+	return matched
+}
@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+const filterManifests = `---
+# Source: mychart/templates/web/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+# Source: mychart/templates/web/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Source: mychart/charts/db/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+`
+
+func TestManifestFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  ManifestFilter
+		want    []string
+		wantErr string
+	}{
+		{
+			name:   "glob",
+			filter: ManifestFilter{Templates: []string{"templates/*/dep*"}},
+			want:   []string{"deployment/web"},
+		},
+		{
+			name:   "directory",
+			filter: ManifestFilter{Templates: []string{"charts/db"}},
+			want:   []string{"service/db"},
+		},
+		{
+			name:   "regex",
+			filter: ManifestFilter{TemplateRegexes: []*regexp.Regexp{regexp.MustCompile(`service\.yaml$`)}},
+			want:   []string{"service/web", "service/db"},
+		},
+		{
+			name:   "kind",
+			filter: ManifestFilter{Resources: []string{"service"}},
+			want:   []string{"service/web", "service/db"},
+		},
+		{
+			name:   "kind and name, each manifest once",
+			filter: ManifestFilter{Templates: []string{"templates/web"}, Resources: []string{"Service/w*", "Service/db"}},
+			want:   []string{"deployment/web", "service/web", "service/db"},
+		},
+		{
+			name:    "missing template",
+			filter:  ManifestFilter{Templates: []string{"templates/ingress.yaml"}},
+			wantErr: "could not find template templates/ingress.yaml in chart",
+		},
+		{
+			name:    "missing resource",
+			filter:  ManifestFilter{Resources: []string{"Deployment/db"}},
+			wantErr: "could not find resource Deployment/db in rendered manifests",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := tt.filter.Filter(filterManifests)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var got []string
+			for _, doc := range docs {
+				var head struct {
+					Kind     string `json:"kind"`
+					Metadata struct {
+						Name string `json:"name"`
+					} `json:"metadata"`
+				}
+				require.NoError(t, yaml.Unmarshal([]byte(doc), &head))
+				got = append(got, strings.ToLower(head.Kind)+"/"+head.Metadata.Name)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// OutputDirLayout controls how rendered manifests are split into files when
+// they are written to an output directory.
+type OutputDirLayout string
+
+const (
+	// OutputDirLayoutSource writes the resources rendered from a template to a
+	// file at the path of the template. This is the default.
+	OutputDirLayoutSource OutputDirLayout = "source"
+	// OutputDirLayoutName writes each resource to a file named
+	// <kind>-<name>.yaml, in the directory of the chart it comes from.
+	OutputDirLayoutName OutputDirLayout = "name"
+	// OutputDirLayoutKind writes each resource to a file named <name>.yaml, in
+	// a directory per kind.
+	OutputDirLayoutKind OutputDirLayout = "kind"
+	// OutputDirLayoutFlat writes each resource to a file named
+	// <kind>-<name>.yaml, directly in the output directory.
+	OutputDirLayoutFlat OutputDirLayout = "flat"
+)
+
+// OutputFileName returns the path, relative to the output directory, of the
+// file a resource rendered from the template source is written to. Resources
+// without kind or name are written by source.
+func OutputFileName(layout OutputDirLayout, source, kind, name string) string {
+	if layout == "" || layout == OutputDirLayoutSource || kind == "" || name == "" {
+		return source
+	}
+
+	kind = strings.ToLower(kind)
+	switch layout {
+	case OutputDirLayoutKind:
+		return path.Join(kind, name+".yaml")
+	case OutputDirLayoutFlat:
+		return fmt.Sprintf("%s-%s.yaml", kind, name)
+	default:
+		return path.Join(chartDir(source), fmt.Sprintf("%s-%s.yaml", kind, name))
+	}
+}
+
+// chartDir returns the directory of the chart a template or CRD file belongs
+// to, such as "mychart/charts/subchart" for
+// "mychart/charts/subchart/templates/deployment.yaml".
+func chartDir(source string) string {
+	for _, dir := range []string{"/templates/", "/crds/"} {
+		if i := strings.LastIndex(source, dir); i != -1 {
+			return source[:i]
+		}
+	}
+	return path.Dir(source)
+}
+
+// outputFile is a file written to the output directory.
+type outputFile struct {
+	name    string
+	content string
+}
+
+// outputFiles splits a file of the chart, such as a CRD file, into the files
+// its resources are written to.
+func outputFiles(layout OutputDirLayout, source, content string) []outputFile {
+	if layout == "" || layout == OutputDirLayoutSource {
+		return []outputFile{{name: source, content: content}}
+	}
+
+	docs := releaseutil.SplitManifests(content)
+	keys := slices.Collect(maps.Keys(docs))
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	var files []outputFile
+	for _, key := range keys {
+		var head releaseutil.SimpleHead
+		name := source
+		if err := yaml.Unmarshal([]byte(docs[key]), &head); err == nil && head.Metadata != nil {
+			name = OutputFileName(layout, source, head.Kind, head.Metadata.Name)
+		}
+		files = append(files, outputFile{name: name, content: docs[key]})
+	}
+	return files
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputFileName(t *testing.T) {
+	tests := []struct {
+		layout OutputDirLayout
+		source string
+		kind   string
+		name   string
+		want   string
+	}{
+		{"", "mychart/templates/deploy.yaml", "Deployment", "web", "mychart/templates/deploy.yaml"},
+		{OutputDirLayoutSource, "mychart/templates/deploy.yaml", "Deployment", "web", "mychart/templates/deploy.yaml"},
+		{OutputDirLayoutName, "mychart/templates/deploy.yaml", "Deployment", "web", "mychart/deployment-web.yaml"},
+		{OutputDirLayoutName, "mychart/charts/db/templates/sts/db.yaml", "StatefulSet", "db", "mychart/charts/db/statefulset-db.yaml"},
+		{OutputDirLayoutName, "mychart/crds/crd.yaml", "CustomResourceDefinition", "a.example.com", "mychart/customresourcedefinition-a.example.com.yaml"},
+		{OutputDirLayoutKind, "mychart/templates/deploy.yaml", "Deployment", "web", "deployment/web.yaml"},
+		{OutputDirLayoutFlat, "mychart/templates/deploy.yaml", "Deployment", "web", "deployment-web.yaml"},
+		{OutputDirLayoutFlat, "mychart/templates/list.yaml", "", "", "mychart/templates/list.yaml"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, OutputFileName(tt.layout, tt.source, tt.kind, tt.name), "%s %s", tt.layout, tt.source)
+	}
+}
+
+func TestOutputFiles(t *testing.T) {
+	content := `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: a.example.com
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: b.example.com`
+
+	files := outputFiles(OutputDirLayoutSource, "mychart/crds/crds.yaml", content)
+	assert.Equal(t, []outputFile{{name: "mychart/crds/crds.yaml", content: content}}, files)
+
+	files = outputFiles(OutputDirLayoutKind, "mychart/crds/crds.yaml", content)
+	if assert.Len(t, files, 2) {
+		assert.Equal(t, "customresourcedefinition/a.example.com.yaml", files[0].name)
+		assert.Equal(t, "customresourcedefinition/b.example.com.yaml", files[1].name)
+		assert.Contains(t, files[1].content, "name: b.example.com")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"golang.org/x/term"
@@ -31,8 +32,10 @@ import (
 	ci "helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chart/loader"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
+	v2loader "helm.sh/helm/v4/pkg/chart/v2/loader"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/provenance"
+	"helm.sh/helm/v4/pkg/signing"
 )
 
 // Package is the action for packaging a chart.
@@ -48,6 +51,29 @@ type Package struct {
 	AppVersion       string
 	Destination      string
 	DependencyUpdate bool
+	// Locked is used by the package command to fail unless the dependencies
+	// of the chart match its lock file.
+	Locked bool
+	// SourceDateEpoch, when set, is the modification time of every file in
+	// the chart archive, so packaging the same chart source always yields a
+	// byte-identical archive.
+	SourceDateEpoch time.Time
+	// IgnoreFile is the ignore file to use instead of the .helmignore file of
+	// the chart.
+	IgnoreFile string
+	// Exclude are additional patterns, in the .helmignore syntax, of files
+	// to leave out of the chart archive.
+	Exclude []string
+	// Include are patterns, in the .helmignore syntax, of files to package
+	// even if they are ignored.
+	Include []string
+	// SignKeyless signs the chart archive with Sigstore keyless signing,
+	// using the OIDC IdentityToken, the FulcioURL certificate authority and
+	// the RekorURL transparency log.
+	SignKeyless   bool
+	IdentityToken string
+	FulcioURL     string
+	RekorURL      string
 
 	RepositoryConfig      string
 	RepositoryCache       string
@@ -85,6 +111,19 @@ func (p *Package) Run(path string, _ map[string]any) (string, error) {
 		return "", errors.New("invalid chart apiVersion")
 	}
 
+	if p.IgnoreFile != "" || len(p.Exclude) > 0 || len(p.Include) > 0 {
+		// Load the files of the chart selected by the options, now that it
+		// is known to be a v2 chart.
+		ch, err = v2loader.LoadDirWithOptions(path, v2loader.DirOptions{
+			IgnoreFile: p.IgnoreFile,
+			Exclude:    p.Exclude,
+			Include:    p.Include,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
 	ac, err := ci.NewAccessor(ch)
 	if err != nil {
 		return "", err
@@ -121,16 +160,32 @@ func (p *Package) Run(path string, _ map[string]any) (string, error) {
 		dest = p.Destination
 	}
 
-	name, err := chartutil.Save(ch, dest)
+	var opts []chartutil.SaveOption
+	if !p.SourceDateEpoch.IsZero() {
+		opts = append(opts, chartutil.WithModTime(p.SourceDateEpoch))
+	}
+	name, err := chartutil.Save(ch, dest, opts...)
 	if err != nil {
 		return "", fmt.Errorf("failed to save: %w", err)
 	}
 
 	if p.Sign {
-		err = p.Clearsign(name)
+		if err := p.Clearsign(name); err != nil {
+			return name, err
+		}
+	}
+	if p.SignKeyless {
+		signer := &signing.Signer{
+			IdentityToken: p.IdentityToken,
+			FulcioURL:     p.FulcioURL,
+			RekorURL:      p.RekorURL,
+		}
+		if _, err := signer.SignFile(name); err != nil {
+			return name, fmt.Errorf("failed to sign the chart: %w", err)
+		}
 	}
 
-	return name, err
+	return name, nil
 }
 
 // validateVersion Verify that version is a Version, and error out if it is not.
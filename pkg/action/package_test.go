@@ -18,14 +18,18 @@ package action
 
 import (
 	"errors"
+	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/stretchr/testify/require"
 
 	"helm.sh/helm/v4/internal/test/ensure"
+	v2loader "helm.sh/helm/v4/pkg/chart/v2/loader"
 )
 
 func TestPassphraseFileFetcher(t *testing.T) {
@@ -169,3 +173,60 @@ func TestRun(t *testing.T) {
 	require.Equal(t, "empty-0.1.0.tgz", filename)
 	require.NoError(t, os.Remove(filename))
 }
+
+func TestRunSourceDateEpoch(t *testing.T) {
+	chartPath := filepath.Join(t.TempDir(), "chart-with-schema")
+	require.NoError(t, os.CopyFS(chartPath, os.DirFS("testdata/charts/chart-with-schema")))
+
+	client := NewPackage()
+	client.SourceDateEpoch = time.Unix(1700000000, 0)
+	client.Destination = t.TempDir()
+	first, err := client.Run(chartPath, nil)
+	require.NoError(t, err)
+
+	// Touch the chart source; the archive must not change.
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, filepath.WalkDir(chartPath, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Chtimes(path, later, later)
+	}))
+	client.Destination = t.TempDir()
+	second, err := client.Run(chartPath, nil)
+	require.NoError(t, err)
+
+	firstData, err := os.ReadFile(first)
+	require.NoError(t, err)
+	secondData, err := os.ReadFile(second)
+	require.NoError(t, err)
+	require.Equal(t, firstData, secondData)
+}
+
+func TestRunExclude(t *testing.T) {
+	chartPath := filepath.Join(t.TempDir(), "chart-with-schema")
+	require.NoError(t, os.CopyFS(chartPath, os.DirFS("testdata/charts/chart-with-schema")))
+	require.NoError(t, os.MkdirAll(filepath.Join(chartPath, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(chartPath, "docs", "usage.md"), []byte("# Usage"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(chartPath, "docs", "notes.txt"), []byte("Notes"), 0o644))
+
+	client := NewPackage()
+	client.Destination = t.TempDir()
+	client.Exclude = []string{"docs/"}
+	client.Include = []string{"docs/*.md"}
+	filename, err := client.Run(chartPath, nil)
+	require.NoError(t, err)
+
+	ch, err := v2loader.Load(filename)
+	require.NoError(t, err)
+	var files []string
+	for _, f := range ch.Files {
+		files = append(files, f.Name)
+	}
+	require.Contains(t, files, "docs/usage.md")
+	require.NotContains(t, files, "docs/notes.txt")
+
+	client.IgnoreFile = filepath.Join(chartPath, "no-such-file")
+	_, err = client.Run(chartPath, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,260 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// PreflightMode controls the resource preflight check run before a release is
+// installed or upgraded.
+type PreflightMode string
+
+const (
+	// PreflightNone disables the preflight check. This is the default.
+	PreflightNone PreflightMode = "none"
+	// PreflightWarn logs a warning when the release does not fit.
+	PreflightWarn PreflightMode = "warn"
+	// PreflightStrict fails the operation when the release does not fit.
+	PreflightStrict PreflightMode = "strict"
+)
+
+// preflightResources are the resources compared by the preflight check.
+var preflightResources = []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory}
+
+// ErrPreflightFailed is returned when a strict preflight check fails.
+var ErrPreflightFailed = errors.New("preflight check failed")
+
+// preflight checks whether the CPU and memory requested by the workloads of
+// a manifest fit into the ResourceQuotas of the namespace and the allocatable
+// capacity of the cluster nodes. For upgrades, only the difference to the
+// current manifest is checked, as the current workloads are already accounted
+// for.
+func (cfg *Configuration) preflight(ctx context.Context, mode PreflightMode, current, manifest, namespace string) error {
+	if mode == "" || mode == PreflightNone {
+		return nil
+	}
+	client, err := cfg.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	problems, err := checkPreflight(ctx, client, current, manifest, namespace)
+	if err != nil {
+		return fmt.Errorf("unable to run preflight check: %w", err)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	if mode == PreflightStrict {
+		return fmt.Errorf("%w: %s", ErrPreflightFailed, strings.Join(problems, "; "))
+	}
+	for _, p := range problems {
+		cfg.Logger().Warn("preflight check", slog.String("problem", p))
+	}
+	return nil
+}
+
+// checkPreflight returns the reasons the workloads of a manifest do not fit
+// into the namespace quotas or the cluster nodes.
+func checkPreflight(ctx context.Context, client kubernetes.Interface, current, manifest, namespace string) ([]string, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil && !apierrors.IsForbidden(err) {
+		return nil, err
+	}
+	var schedulable []v1.Node
+	if nodes != nil {
+		for _, n := range nodes.Items {
+			if !n.Spec.Unschedulable {
+				schedulable = append(schedulable, n)
+			}
+		}
+	}
+
+	requests, limits := manifestRequests(manifest, len(schedulable))
+	currentRequests, currentLimits := manifestRequests(current, len(schedulable))
+	additional := v1.ResourceList{}
+	for _, name := range preflightResources {
+		req := requests[name].DeepCopy()
+		req.Sub(currentRequests[name])
+		limit := limits[name].DeepCopy()
+		limit.Sub(currentLimits[name])
+		additional[name] = req
+		additional["requests."+name] = req
+		additional["limits."+name] = limit
+	}
+
+	var problems []string
+	quotas, err := client.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !apierrors.IsForbidden(err) {
+		return nil, err
+	}
+	if quotas != nil {
+		for _, q := range quotas.Items {
+			for _, name := range preflightResources {
+				for _, key := range []v1.ResourceName{"requests." + name, name, "limits." + name} {
+					hard, ok := q.Status.Hard[key]
+					if !ok {
+						hard, ok = q.Spec.Hard[key]
+					}
+					if !ok {
+						continue
+					}
+					req := additional[key]
+					available := hard.DeepCopy()
+					available.Sub(q.Status.Used[key])
+					if req.Cmp(available) > 0 {
+						problems = append(problems, fmt.Sprintf("ResourceQuota %q in namespace %q: %s requested %s exceeds available %s", q.Name, namespace, key, req.String(), available.String()))
+					}
+				}
+			}
+		}
+	}
+
+	if len(schedulable) > 0 {
+		allocatable := v1.ResourceList{}
+		for _, n := range schedulable {
+			for _, name := range preflightResources {
+				total := allocatable[name]
+				total.Add(n.Status.Allocatable[name])
+				allocatable[name] = total
+			}
+		}
+		for _, name := range preflightResources {
+			req := additional[name]
+			available := allocatable[name]
+			if req.Cmp(available) > 0 {
+				problems = append(problems, fmt.Sprintf("nodes: %s requested %s exceeds allocatable %s", name, req.String(), available.String()))
+			}
+		}
+	}
+	return problems, nil
+}
+
+// manifestRequests sums the CPU and memory requests and limits of the
+// workloads in a manifest. DaemonSets are counted once per node.
+func manifestRequests(manifest string, nodes int) (requests, limits v1.ResourceList) {
+	requests, limits = v1.ResourceList{}, v1.ResourceList{}
+	for _, name := range preflightResources {
+		requests[name] = resource.Quantity{}
+		limits[name] = resource.Quantity{}
+	}
+	decoder := scheme.Codecs.UniversalDeserializer()
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		obj, _, err := decoder.Decode([]byte(doc), nil, nil)
+		if err != nil {
+			// Custom resources and invalid documents are not workloads
+			continue
+		}
+		var spec *v1.PodSpec
+		replicas := int32(1)
+		switch o := obj.(type) {
+		case *v1.Pod:
+			spec = &o.Spec
+		case *appsv1.Deployment:
+			spec, replicas = &o.Spec.Template.Spec, valueOr(o.Spec.Replicas, 1)
+		case *appsv1.StatefulSet:
+			spec, replicas = &o.Spec.Template.Spec, valueOr(o.Spec.Replicas, 1)
+		case *appsv1.ReplicaSet:
+			spec, replicas = &o.Spec.Template.Spec, valueOr(o.Spec.Replicas, 1)
+		case *v1.ReplicationController:
+			spec, replicas = &o.Spec.Template.Spec, valueOr(o.Spec.Replicas, 1)
+		case *appsv1.DaemonSet:
+			spec, replicas = &o.Spec.Template.Spec, int32(nodes)
+		case *batchv1.Job:
+			spec, replicas = &o.Spec.Template.Spec, valueOr(o.Spec.Parallelism, 1)
+		case *batchv1.CronJob:
+			spec, replicas = &o.Spec.JobTemplate.Spec.Template.Spec, valueOr(o.Spec.JobTemplate.Spec.Parallelism, 1)
+		default:
+			continue
+		}
+		addReplicas(requests, podResources(spec, containerRequest), replicas)
+		addReplicas(limits, podResources(spec, containerLimit), replicas)
+	}
+	return requests, limits
+}
+
+// addReplicas adds the resources of a pod times the number of replicas to total.
+func addReplicas(total, pod v1.ResourceList, replicas int32) {
+	for _, name := range preflightResources {
+		q := pod[name]
+		q.Mul(int64(replicas))
+		t := total[name]
+		t.Add(q)
+		total[name] = t
+	}
+}
+
+// podResources returns the effective requests or limits of a pod, as returned
+// by resourceOf for each container: the larger of the sum of its containers and
+// the largest init container.
+func podResources(spec *v1.PodSpec, resourceOf func(v1.Container, v1.ResourceName) resource.Quantity) v1.ResourceList {
+	result := v1.ResourceList{}
+	for _, name := range preflightResources {
+		var sum, initMax resource.Quantity
+		for _, c := range spec.Containers {
+			sum.Add(resourceOf(c, name))
+		}
+		for _, c := range spec.InitContainers {
+			if q := resourceOf(c, name); q.Cmp(initMax) > 0 {
+				initMax = q
+			}
+		}
+		if initMax.Cmp(sum) > 0 {
+			sum = initMax
+		}
+		result[name] = sum
+	}
+	return result
+}
+
+// containerRequest returns the request of a container. Limits are used for
+// containers without requests, as Kubernetes defaults requests to limits.
+func containerRequest(c v1.Container, name v1.ResourceName) resource.Quantity {
+	if q, ok := c.Resources.Requests[name]; ok {
+		return q
+	}
+	return c.Resources.Limits[name]
+}
+
+func containerLimit(c v1.Container, name v1.ResourceName) resource.Quantity {
+	return c.Resources.Limits[name]
+}
+
+func valueOr(v *int32, def int32) int32 {
+	if v == nil {
+		return def
+	}
+	return *v
+}
@@ -0,0 +1,213 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+const preflightDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      initContainers:
+      - name: init
+        resources:
+          requests:
+            cpu: 2
+      containers:
+      - name: web
+        resources:
+          requests:
+            cpu: 500m
+            memory: 128Mi
+      - name: sidecar
+        resources:
+          limits:
+            cpu: 100m
+            memory: 64Mi
+`
+
+const preflightDaemonSet = `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+spec:
+  template:
+    spec:
+      containers:
+      - name: agent
+        resources:
+          requests:
+            cpu: 100m
+            memory: 32Mi
+`
+
+const preflightConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+`
+
+func TestManifestRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		nodes    int
+		cpu      string
+		memory   string
+		cpuLimit string
+	}{
+		{
+			name:     "empty manifest",
+			manifest: "",
+			cpu:      "0",
+			memory:   "0",
+			cpuLimit: "0",
+		},
+		{
+			name:     "non workload resources are ignored",
+			manifest: preflightConfigMap,
+			cpu:      "0",
+			memory:   "0",
+			cpuLimit: "0",
+		},
+		{
+			name:     "deployment replicas and init containers",
+			manifest: preflightDeployment,
+			cpu:      "6",
+			memory:   "576Mi",
+			cpuLimit: "300m",
+		},
+		{
+			name:     "daemonset is counted once per node",
+			manifest: preflightDaemonSet,
+			nodes:    4,
+			cpu:      "400m",
+			memory:   "128Mi",
+			cpuLimit: "0",
+		},
+		{
+			name:     "multiple documents",
+			manifest: preflightDeployment + "---\n" + preflightConfigMap + "---\n" + preflightDaemonSet,
+			nodes:    1,
+			cpu:      "6100m",
+			memory:   "608Mi",
+			cpuLimit: "300m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests, limits := manifestRequests(tt.manifest, tt.nodes)
+			cpu, memory := requests[v1.ResourceCPU], requests[v1.ResourceMemory]
+			assert.Zero(t, cpu.Cmp(resource.MustParse(tt.cpu)), "cpu: %s", cpu.String())
+			assert.Zero(t, memory.Cmp(resource.MustParse(tt.memory)), "memory: %s", memory.String())
+			cpuLimit := limits[v1.ResourceCPU]
+			assert.Zero(t, cpuLimit.Cmp(resource.MustParse(tt.cpuLimit)), "cpu limit: %s", cpuLimit.String())
+		})
+	}
+}
+
+func TestCheckPreflight(t *testing.T) {
+	node := func(name, cpu, memory string, unschedulable bool) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.NodeSpec{Unschedulable: unschedulable},
+			Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}
+	}
+	quota := &v1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "spaced"},
+		Status: v1.ResourceQuotaStatus{
+			Hard: v1.ResourceList{"requests.cpu": resource.MustParse("8"), "limits.cpu": resource.MustParse("1")},
+			Used: v1.ResourceList{"requests.cpu": resource.MustParse("4"), "limits.cpu": resource.MustParse("800m")},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		current  string
+		manifest string
+		problems []string
+	}{
+		{
+			name:     "fits",
+			manifest: preflightDaemonSet,
+		},
+		{
+			name:     "exceeds quota and nodes",
+			manifest: preflightDeployment,
+			problems: []string{
+				`ResourceQuota "compute" in namespace "spaced": requests.cpu requested 6 exceeds available 4`,
+				`ResourceQuota "compute" in namespace "spaced": limits.cpu requested 300m exceeds available 200m`,
+				"nodes: cpu requested 6 exceeds allocatable 4",
+			},
+		},
+		{
+			name:     "upgrade only counts additional requests",
+			current:  preflightDeployment,
+			manifest: preflightDeployment,
+		},
+		{
+			name:     "upgrade adding replicas",
+			current:  preflightDeployment,
+			manifest: strings.Replace(preflightDeployment, "replicas: 3", "replicas: 6", 1),
+			problems: []string{
+				`ResourceQuota "compute" in namespace "spaced": requests.cpu requested 6 exceeds available 4`,
+				`ResourceQuota "compute" in namespace "spaced": limits.cpu requested 300m exceeds available 200m`,
+				"nodes: cpu requested 6 exceeds allocatable 4",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fakeclientset.NewClientset(
+				node("a", "2", "1Gi", false),
+				node("b", "2", "1Gi", false),
+				node("c", "16", "64Gi", true),
+				quota,
+			)
+			problems, err := checkPreflight(t.Context(), client, tt.current, tt.manifest, "spaced")
+			require.NoError(t, err)
+			assert.Equal(t, tt.problems, problems)
+		})
+	}
+}
+
+func TestPreflightNone(t *testing.T) {
+	// No Kubernetes client is needed when the check is disabled
+	cfg := actionConfigFixture(t)
+	assert.NoError(t, cfg.preflight(t.Context(), PreflightNone, "", preflightDeployment, "spaced"))
+	assert.NoError(t, cfg.preflight(t.Context(), "", "", preflightDeployment, "spaced"))
+}
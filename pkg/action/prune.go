@@ -0,0 +1,134 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// DefaultPruneAllowlist are the kinds of resources pruned when no allowlist is
+// given. Pods and ReplicaSets are left out since they are usually created by
+// the workloads of the release.
+var DefaultPruneAllowlist = []schema.GroupVersionKind{
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "PersistentVolumeClaim"},
+	{Version: "v1", Kind: "ReplicationController"},
+	{Version: "v1", Kind: "Secret"},
+	{Version: "v1", Kind: "Service"},
+	{Version: "v1", Kind: "ServiceAccount"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "batch", Version: "v1", Kind: "CronJob"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+}
+
+// ParseGroupVersionKind parses a kind of the form GROUP/VERSION/KIND, using
+// "core" as the group of the core API, for example "core/v1/ConfigMap" or
+// "apps/v1/Deployment".
+func ParseGroupVersionKind(s string) (schema.GroupVersionKind, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid kind %q: must be of the form GROUP/VERSION/KIND", s)
+	}
+	group := parts[0]
+	if group == "core" {
+		group = ""
+	}
+	return schema.GroupVersionKind{Group: group, Version: parts[1], Kind: parts[2]}, nil
+}
+
+// prune deletes the resources of the allowlisted kinds that belong to the
+// release but are neither part of its manifest nor of its hooks, such as
+// resources removed from the chart.
+func (u *Upgrade) prune(rel *release.Release, target kube.ResourceList) error {
+	lister, ok := u.cfg.KubeClient.(kube.InterfaceListResources)
+	if !ok {
+		return errors.New("pruning is not supported by the Kubernetes client")
+	}
+	allowlist := u.PruneAllowlist
+	if len(allowlist) == 0 {
+		allowlist = DefaultPruneAllowlist
+	}
+
+	keep := append(kube.ResourceList{}, target...)
+	for _, h := range rel.Hooks {
+		resources, err := u.cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
+		if err != nil {
+			return fmt.Errorf("unable to build hook %s: %w", h.Path, err)
+		}
+		keep = append(keep, resources...)
+	}
+
+	namespaces := []string{rel.Namespace}
+	for _, info := range target {
+		if info.Namespaced() && info.Namespace != "" && !slices.Contains(namespaces, info.Namespace) {
+			namespaces = append(namespaces, info.Namespace)
+		}
+	}
+
+	var stale kube.ResourceList
+	selector := appManagedByLabel + "=" + appManagedByHelm
+	for _, ns := range namespaces {
+		listed, err := lister.ListResources(allowlist, ns, selector)
+		if err != nil {
+			return err
+		}
+		for _, info := range listed {
+			if !belongsToRelease(info, rel.Name, rel.Namespace) || keep.Contains(info) || stale.Contains(info) {
+				continue
+			}
+			stale = append(stale, info)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	for _, info := range stale {
+		u.cfg.Logger().Debug("pruning resource", "resource", resourceString(info), "release", rel.Name)
+	}
+	if _, errs := u.cfg.KubeClient.Delete(stale, metav1.DeletePropagationBackground); errs != nil {
+		return fmt.Errorf("unable to prune resources: %w", joinErrors(errs, "; "))
+	}
+	return nil
+}
+
+// belongsToRelease returns whether a resource carries the ownership metadata
+// of the release. Resources with owner references, such as the Pods of a
+// Deployment, are managed by their owners and never belong to the release.
+func belongsToRelease(info *resource.Info, releaseName, releaseNamespace string) bool {
+	obj, err := meta.Accessor(info.Object)
+	if err != nil || len(obj.GetOwnerReferences()) > 0 {
+		return false
+	}
+	annos := obj.GetAnnotations()
+	return annos[helmReleaseNameAnnotation] == releaseName && annos[helmReleaseNamespaceAnnotation] == releaseNamespace
+}
@@ -0,0 +1,246 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/release/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestParseGroupVersionKind(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    schema.GroupVersionKind
+		wantErr bool
+	}{
+		{input: "core/v1/ConfigMap", want: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}},
+		{input: "apps/v1/Deployment", want: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}},
+		{input: "v1/ConfigMap", wantErr: true},
+		{input: "apps//Deployment", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			gvk, err := ParseGroupVersionKind(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, gvk)
+		})
+	}
+}
+
+func pruneInfo(name, namespace string, labels, annotations map[string]string) *resource.Info {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+	return &resource.Info{
+		Name:      name,
+		Namespace: namespace,
+		Object:    obj,
+		Mapping: &meta.RESTMapping{
+			GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+			Scope:            meta.RESTScopeNamespace,
+		},
+	}
+}
+
+// withOwner adds an owner reference to the object of info.
+func withOwner(info *resource.Info) *resource.Info {
+	info.Object.(*unstructured.Unstructured).SetOwnerReferences([]metav1.OwnerReference{
+		{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-5d8f7", UID: "1234"},
+	})
+	return info
+}
+
+func TestBelongsToRelease(t *testing.T) {
+	managed := map[string]string{appManagedByLabel: appManagedByHelm}
+	tests := []struct {
+		name string
+		info *resource.Info
+		want bool
+	}{
+		{
+			name: "release annotations",
+			info: pruneInfo("a", "other", managed, map[string]string{
+				helmReleaseNameAnnotation:      "rel",
+				helmReleaseNamespaceAnnotation: "spaced",
+			}),
+			want: true,
+		},
+		{
+			name: "annotations of another release",
+			info: pruneInfo("a", "spaced", managed, map[string]string{
+				helmReleaseNameAnnotation:      "other",
+				helmReleaseNamespaceAnnotation: "spaced",
+			}),
+			want: false,
+		},
+		{
+			name: "instance label only",
+			info: pruneInfo("a", "spaced", map[string]string{"app.kubernetes.io/instance": "rel"}, nil),
+			want: false,
+		},
+		{
+			name: "owned by another resource",
+			info: withOwner(pruneInfo("a", "spaced", managed, map[string]string{
+				helmReleaseNameAnnotation:      "rel",
+				helmReleaseNamespaceAnnotation: "spaced",
+			})),
+			want: false,
+		},
+		{
+			name: "no ownership metadata",
+			info: pruneInfo("a", "spaced", managed, nil),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, belongsToRelease(tt.info, "rel", "spaced"))
+		})
+	}
+}
+
+func TestUpgradeRelease_Prune(t *testing.T) {
+	stub := releaseStub()
+	owned := map[string]string{
+		helmReleaseNameAnnotation:      stub.Name,
+		helmReleaseNamespaceAnnotation: stub.Namespace,
+	}
+	rendered := pruneInfo("rendered", stub.Namespace, nil, owned)
+
+	tests := []struct {
+		name    string
+		listed  kube.ResourceList
+		wantErr string
+	}{
+		{
+			name:   "nothing to prune",
+			listed: kube.ResourceList{rendered, pruneInfo("foreign", stub.Namespace, nil, nil)},
+		},
+		{
+			name:    "stale resource is deleted",
+			listed:  kube.ResourceList{rendered, pruneInfo("stale", stub.Namespace, nil, owned)},
+			wantErr: "unable to prune resources",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upAction := upgradeAction(t)
+			rel := releaseStub()
+			rel.Info.Status = common.StatusDeployed
+			require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+			// Deleting fails so that pruning a resource is reported as an error
+			upAction.cfg.KubeClient = &kubefake.FailingKubeClient{
+				PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard},
+				DummyResources:     kube.ResourceList{rendered},
+				ListedResources:    tt.listed,
+				DeleteError:        errors.New("delete failed"),
+			}
+			upAction.Prune = true
+			upAction.DisableHooks = true
+
+			_, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// hookKubeClient builds a new ConfigMap for every manifest and records the
+// resources it creates.
+type hookKubeClient struct {
+	*kubefake.FailingKubeClient
+	created kube.ResourceList
+}
+
+func (c *hookKubeClient) Build(_ io.Reader, _ bool) (kube.ResourceList, error) {
+	return kube.ResourceList{pruneInfo("hook-leftover", "spaced", nil, nil)}, nil
+}
+
+func (c *hookKubeClient) Create(resources kube.ResourceList, _ ...kube.ClientCreateOption) (*kube.Result, error) {
+	c.created = append(c.created, resources...)
+	return &kube.Result{Created: resources}, nil
+}
+
+func TestUpgradeRelease_PruneRemovedHook(t *testing.T) {
+	// A hook without a deletion policy on success leaves its resources behind
+	cfg := actionConfigFixture(t)
+	client := &hookKubeClient{FailingKubeClient: &kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}}
+	cfg.KubeClient = client
+	rel := releaseStub()
+	rel.Hooks = []*release.Hook{{
+		Name:           "leftover",
+		Kind:           "ConfigMap",
+		Path:           "templates/hook.yaml",
+		Manifest:       "kind: ConfigMap",
+		Events:         []release.HookEvent{release.HookPreInstall},
+		DeletePolicies: []release.HookDeletePolicy{release.HookBeforeHookCreation},
+	}}
+	require.NoError(t, cfg.execHook(rel, release.HookPreInstall, kube.StatusWatcherStrategy, nil, time.Minute, false))
+	require.Len(t, client.created, 1)
+	leftover := client.created[0]
+	assert.True(t, belongsToRelease(leftover, rel.Name, rel.Namespace), "hook resources carry the ownership metadata of the release")
+
+	// The hook is removed from the chart: its leftover is pruned
+	upAction := upgradeAction(t)
+	rel.Info.Status = common.StatusDeployed
+	rel.Hooks = nil
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+	rendered := pruneInfo("rendered", rel.Namespace, nil, map[string]string{
+		helmReleaseNameAnnotation:      rel.Name,
+		helmReleaseNamespaceAnnotation: rel.Namespace,
+	})
+	upAction.cfg.KubeClient = &kubefake.FailingKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard},
+		DummyResources:     kube.ResourceList{rendered},
+		ListedResources:    kube.ResourceList{rendered, leftover},
+		// Deleting fails so that pruning the leftover is reported as an error
+		DeleteError: errors.New("delete failed"),
+	}
+	upAction.Prune = true
+	upAction.DisableHooks = true
+
+	_, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+	assert.ErrorContains(t, err, "unable to prune resources")
+}
@@ -102,6 +102,13 @@ func (p *Pull) Run(chartRef string) (string, error) {
 	} else if p.VerifyLater {
 		c.Verify = downloader.VerifyLater
 	}
+	if p.VerifyKeyless {
+		verifier, err := p.KeylessVerifier()
+		if err != nil {
+			return out.String(), err
+		}
+		c.KeylessVerifier = verifier
+	}
 
 	// If untar is set, we fetch to a tempdir, then untar and copy after
 	// verification.
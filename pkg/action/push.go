@@ -17,7 +17,9 @@ limitations under the License.
 package action
 
 import (
+	"fmt"
 	"io"
+	"os"
 	"strings"
 
 	"helm.sh/helm/v4/pkg/cli"
@@ -38,6 +40,9 @@ type Push struct {
 	insecureSkipTLSVerify bool
 	plainHTTP             bool
 	out                   io.Writer
+	sboms                 []string
+	attestations          []string
+	provAsReferrer        bool
 }
 
 // PushOpt is a type of function that sets options for a push action.
@@ -80,6 +85,30 @@ func WithPushOptWriter(out io.Writer) PushOpt {
 	}
 }
 
+// WithSBOMs attaches the given SBOM files to the pushed chart. Their format is
+// detected from their content.
+func WithSBOMs(files ...string) PushOpt {
+	return func(p *Push) {
+		p.sboms = append(p.sboms, files...)
+	}
+}
+
+// WithAttestations attaches artifacts to the pushed chart, each given as
+// ARTIFACT_TYPE=FILE.
+func WithAttestations(attestations ...string) PushOpt {
+	return func(p *Push) {
+		p.attestations = append(p.attestations, attestations...)
+	}
+}
+
+// WithProvAsReferrer attaches the provenance file of the chart as a referrer
+// rather than storing it in the chart manifest.
+func WithProvAsReferrer(provAsReferrer bool) PushOpt {
+	return func(p *Push) {
+		p.provAsReferrer = provAsReferrer
+	}
+}
+
 // NewPushWithOpts creates a new push, with configuration options.
 func NewPushWithOpts(opts ...PushOpt) *Push {
 	p := &Push{}
@@ -108,5 +137,46 @@ func (p *Push) Run(chartRef string, remote string) (string, error) {
 		c.Options = append(c.Options, pusher.WithRegistryClient(p.cfg.RegistryClient))
 	}
 
+	referrers, err := p.referrers()
+	if err != nil {
+		return out.String(), err
+	}
+	if len(referrers) > 0 || p.provAsReferrer {
+		if !registry.IsOCI(remote) {
+			return out.String(), fmt.Errorf("referrers can only be attached to charts pushed to an OCI registry, not %s", remote)
+		}
+		c.Options = append(c.Options,
+			pusher.WithReferrers(referrers...),
+			pusher.WithProvAsReferrer(p.provAsReferrer))
+	}
+
 	return out.String(), c.UploadTo(chartRef, remote)
 }
+
+// referrers reads the SBOMs and attestations to attach to the chart.
+func (p *Push) referrers() ([]registry.Referrer, error) {
+	var referrers []registry.Referrer
+	for _, file := range p.sboms {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		artifactType, err := registry.SBOMArtifactType(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		referrers = append(referrers, registry.Referrer{ArtifactType: artifactType, Data: data})
+	}
+	for _, attestation := range p.attestations {
+		artifactType, file, ok := strings.Cut(attestation, "=")
+		if !ok || artifactType == "" || file == "" {
+			return nil, fmt.Errorf("invalid attestation %q, expected ARTIFACT_TYPE=FILE", attestation)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		referrers = append(referrers, registry.Referrer{ArtifactType: artifactType, Data: data})
+	}
+	return referrers, nil
+}
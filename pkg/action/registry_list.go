@@ -0,0 +1,56 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// RegistryList lists the repositories hosted by a registry.
+type RegistryList struct {
+	cfg *Configuration
+}
+
+// NewRegistryList creates a new RegistryList object with the given configuration.
+func NewRegistryList(cfg *Configuration) *RegistryList {
+	return &RegistryList{
+		cfg: cfg,
+	}
+}
+
+// Run returns the references of the repositories hosted by host, which may
+// be followed by a namespace to only list the repositories under it.
+func (a *RegistryList) Run(host string) ([]string, error) {
+	repositories, err := a.cfg.RegistryClient.Repositories(host)
+	if err != nil {
+		return nil, err
+	}
+	return repositoryRefs(host, repositories), nil
+}
+
+// repositoryRefs turns the repository names of host into oci:// references.
+func repositoryRefs(host string, repositories []string) []string {
+	host, _, _ = strings.Cut(strings.TrimPrefix(host, registry.OCIScheme+"://"), "/")
+	refs := make([]string, len(repositories))
+	for i, repository := range repositories {
+		refs[i] = fmt.Sprintf("%s://%s/%s", registry.OCIScheme, host, repository)
+	}
+	return refs
+}
@@ -0,0 +1,87 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// RegistrySearch searches the charts hosted by a registry.
+type RegistrySearch struct {
+	cfg *Configuration
+}
+
+// RegistrySearchResult is a chart found by RegistrySearch.
+type RegistrySearchResult struct {
+	// Ref is the oci:// reference of the chart repository.
+	Ref string
+	// Chart is the metadata of the latest version of the chart.
+	Chart *chart.Metadata
+}
+
+// NewRegistrySearch creates a new RegistrySearch object with the given configuration.
+func NewRegistrySearch(cfg *Configuration) *RegistrySearch {
+	return &RegistrySearch{
+		cfg: cfg,
+	}
+}
+
+// Run returns the charts hosted by host whose repository name contains
+// keyword, ignoring case. An empty keyword matches every chart.
+//
+// Repositories without a semantic version tag, or whose latest version is
+// not a Helm chart, are skipped.
+func (a *RegistrySearch) Run(host, keyword string) ([]*RegistrySearchResult, error) {
+	client := a.cfg.RegistryClient
+	repositories, err := client.Repositories(host)
+	if err != nil {
+		return nil, err
+	}
+
+	keyword = strings.ToLower(keyword)
+	refs := repositoryRefs(host, repositories)
+	var results []*RegistrySearchResult
+	for i, repository := range repositories {
+		if !strings.Contains(strings.ToLower(repository), keyword) {
+			continue
+		}
+
+		ref := refs[i]
+		tags, err := client.Tags(strings.TrimPrefix(ref, registry.OCIScheme+"://"))
+		if err != nil {
+			return nil, err
+		}
+		if len(tags) == 0 {
+			continue
+		}
+
+		meta, err := client.ChartMetadata(fmt.Sprintf("%s:%s", ref, tags[0]))
+		if errors.Is(err, registry.ErrNotChart) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, &RegistrySearchResult{Ref: ref, Chart: meta})
+	}
+	return results, nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// RegistryTags lists the chart versions of a repository in a registry.
+type RegistryTags struct {
+	cfg *Configuration
+}
+
+// NewRegistryTags creates a new RegistryTags object with the given configuration.
+func NewRegistryTags(cfg *Configuration) *RegistryTags {
+	return &RegistryTags{
+		cfg: cfg,
+	}
+}
+
+// Run returns the versions of the chart at ref, newest first. Tags that are
+// not semantic versions are ignored.
+func (a *RegistryTags) Run(ref string) ([]string, error) {
+	if !registry.IsOCI(ref) && strings.Contains(ref, "://") {
+		return nil, fmt.Errorf("%s is not an OCI reference", ref)
+	}
+	return a.cfg.RegistryClient.Tags(strings.TrimPrefix(ref, registry.OCIScheme+"://"))
+}
@@ -0,0 +1,216 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// The files of a release archive. The release record is the one a release is
+// imported from, the other files are there to inspect the release.
+const (
+	releaseArchiveRecord   = "release.json"
+	releaseArchiveMetadata = "metadata.yaml"
+	releaseArchiveValues   = "values.yaml"
+	releaseArchiveManifest = "manifest.yaml"
+	releaseArchiveHooks    = "hooks.yaml"
+	releaseArchiveNotes    = "notes.txt"
+	releaseArchiveChartDir = "chart"
+)
+
+// WriteReleaseArchive writes a release as a gzipped tarball to w. The tarball
+// holds the release record together with the chart archive, the user supplied
+// values, the manifest, the hooks, the notes and the metadata of the release.
+// ReadReleaseArchive reads the release back.
+func WriteReleaseArchive(w io.Writer, rel *release.Release) error {
+	record, err := json.Marshal(rel)
+	if err != nil {
+		return err
+	}
+	metadata, err := yaml.Marshal(releaseArchiveMetadataOf(rel))
+	if err != nil {
+		return err
+	}
+	values, err := yaml.Marshal(rel.Config)
+	if err != nil {
+		return err
+	}
+	var hooks strings.Builder
+	for _, h := range rel.Hooks {
+		fmt.Fprintf(&hooks, "---\n# Source: %s\n%s\n", h.Path, h.Manifest)
+	}
+	var notes string
+	modTime := time.Now()
+	if rel.Info != nil {
+		notes = rel.Info.Notes
+		if !rel.Info.LastDeployed.IsZero() {
+			modTime = rel.Info.LastDeployed
+		}
+	}
+
+	zipper := gzip.NewWriter(w)
+	tw := tar.NewWriter(zipper)
+	prefix := fmt.Sprintf("%s.v%d/", rel.Name, rel.Version)
+	files := []struct {
+		name string
+		body []byte
+	}{
+		{releaseArchiveRecord, record},
+		{releaseArchiveMetadata, metadata},
+		{releaseArchiveValues, values},
+		{releaseArchiveManifest, []byte(rel.Manifest)},
+		{releaseArchiveHooks, []byte(hooks.String())},
+		{releaseArchiveNotes, []byte(notes)},
+	}
+	for _, f := range files {
+		if err := writeArchiveFile(tw, prefix+f.name, f.body, modTime); err != nil {
+			return err
+		}
+	}
+	if rel.Chart != nil {
+		name, body, err := chartArchive(rel)
+		if err != nil {
+			return err
+		}
+		if err := writeArchiveFile(tw, prefix+releaseArchiveChartDir+"/"+name, body, modTime); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zipper.Close()
+}
+
+// ReadReleaseArchive reads a release from a gzipped tarball written by
+// WriteReleaseArchive.
+func ReadReleaseArchive(r io.Reader) (*release.Release, error) {
+	zipper, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading release archive: %w", err)
+	}
+	defer zipper.Close()
+
+	var rel *release.Release
+	var metadata *Metadata
+	tr := tar.NewReader(zipper)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading release archive: %w", err)
+		}
+		// Files are nested in a directory named after the release
+		_, name, _ := strings.Cut(hdr.Name, "/")
+		switch name {
+		case releaseArchiveRecord:
+			rel = &release.Release{}
+			if err := json.NewDecoder(tr).Decode(rel); err != nil {
+				return nil, fmt.Errorf("reading release record: %w", err)
+			}
+		case releaseArchiveMetadata:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			metadata = &Metadata{}
+			if err := yaml.Unmarshal(data, metadata); err != nil {
+				return nil, fmt.Errorf("reading release metadata: %w", err)
+			}
+		}
+	}
+	if rel == nil {
+		return nil, fmt.Errorf("no %s in release archive", releaseArchiveRecord)
+	}
+	// The labels of a release are not part of its record
+	if metadata != nil {
+		rel.Labels = metadata.Labels
+	}
+	return rel, nil
+}
+
+// releaseArchiveMetadataOf returns the metadata of a release, as printed by
+// 'helm get metadata'.
+func releaseArchiveMetadataOf(rel *release.Release) *Metadata {
+	m := &Metadata{
+		Name:        rel.Name,
+		Labels:      rel.Labels,
+		Namespace:   rel.Namespace,
+		Revision:    rel.Version,
+		ApplyMethod: rel.ApplyMethod,
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		m.Chart = rel.Chart.Metadata.Name
+		m.Version = rel.Chart.Metadata.Version
+		m.AppVersion = rel.Chart.Metadata.AppVersion
+		m.Annotations = rel.Chart.Metadata.Annotations
+	}
+	if rel.Info != nil {
+		m.Status = rel.Info.Status.String()
+		m.DeployedAt = rel.Info.LastDeployed.Format(time.RFC3339)
+	}
+	return m
+}
+
+// chartArchive packages the chart of a release, returning the name and the
+// content of the chart archive.
+func chartArchive(rel *release.Release) (string, []byte, error) {
+	dir, err := os.MkdirTemp("", "helm-release-archive-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path, err := chartutil.Save(rel.Chart, dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("packaging chart: %w", err)
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return filepath.Base(path), body, nil
+}
+
+func writeArchiveFile(tw *tar.Writer, name string, body []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(body)),
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(body)
+	return err
+}
@@ -0,0 +1,48 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// ReleaseCompact is the action for rewriting release records stored in an
+// older format.
+//
+// It provides the implementation of 'helm release compact'.
+type ReleaseCompact struct {
+	cfg *Configuration
+}
+
+// NewReleaseCompact creates a new ReleaseCompact object with the given configuration.
+func NewReleaseCompact(cfg *Configuration) *ReleaseCompact {
+	return &ReleaseCompact{
+		cfg: cfg,
+	}
+}
+
+// Run rewrites the release records of the namespace that are stored in an
+// older format. It returns the keys of the rewritten records.
+func (r *ReleaseCompact) Run() ([]string, error) {
+	c, ok := r.cfg.Releases.Driver.(driver.Compactor)
+	if !ok {
+		return nil, fmt.Errorf("the %s storage driver does not support compaction", r.cfg.Releases.Name())
+	}
+	return c.Compact()
+}
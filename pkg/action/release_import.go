@@ -0,0 +1,78 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// ReleaseImport is the action for importing a release exported with
+// 'helm get all --output archive'.
+//
+// It provides the implementation of 'helm release import'.
+type ReleaseImport struct {
+	cfg *Configuration
+
+	// Namespace is the namespace the release is imported into. The namespace
+	// of the exported release is kept if it is empty.
+	Namespace string
+}
+
+// NewReleaseImport creates a new ReleaseImport object with the given configuration.
+func NewReleaseImport(cfg *Configuration) *ReleaseImport {
+	return &ReleaseImport{
+		cfg: cfg,
+	}
+}
+
+// Run stores the release in the release storage, keeping its revision,
+// status and content. The resources of the release are not created: this
+// only restores the record of the release, for example after its resources
+// were migrated to another cluster. It fails if a release of the same name
+// already exists.
+func (r *ReleaseImport) Run(rel *release.Release) (*release.Release, error) {
+	if err := chartutil.ValidateReleaseName(rel.Name); err != nil {
+		return nil, fmt.Errorf("release name is invalid: %s", rel.Name)
+	}
+	if rel.Version <= 0 {
+		return nil, errInvalidRevision
+	}
+	if rel.Info == nil || rel.Chart == nil {
+		return nil, errors.New("release record is incomplete")
+	}
+	if r.Namespace != "" {
+		rel.Namespace = r.Namespace
+	}
+
+	history, err := r.cfg.Releases.History(rel.Name)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, err
+	}
+	if len(history) > 0 {
+		return nil, fmt.Errorf("release %q already exists", rel.Name)
+	}
+
+	if err := r.cfg.Releases.Create(rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release/common"
+)
+
+func TestReleaseArchive(t *testing.T) {
+	rel := namedReleaseStub("exported", common.StatusDeployed)
+	rel.Version = 4
+	rel.Namespace = "prod"
+	rel.Manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n"
+	rel.Labels = map[string]string{"team": "web"}
+	rel.Info.Notes = "Thank you for installing"
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReleaseArchive(&buf, rel))
+
+	zipper, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	files := map[string]string{}
+	tr := tar.NewReader(zipper)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = string(body)
+	}
+	assert.Contains(t, files, "exported.v4/release.json")
+	assert.Contains(t, files, "exported.v4/chart/hello-0.1.0.tgz")
+	assert.Equal(t, "name: value\n", files["exported.v4/values.yaml"])
+	assert.Equal(t, rel.Manifest, files["exported.v4/manifest.yaml"])
+	assert.Contains(t, files["exported.v4/hooks.yaml"], "# Source: test-cm\n")
+	assert.Equal(t, "Thank you for installing", files["exported.v4/notes.txt"])
+	assert.Contains(t, files["exported.v4/metadata.yaml"], "revision: 4\n")
+
+	imported, err := ReadReleaseArchive(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, rel.Name, imported.Name)
+	assert.Equal(t, rel.Version, imported.Version)
+	assert.Equal(t, rel.Namespace, imported.Namespace)
+	assert.Equal(t, rel.Manifest, imported.Manifest)
+	assert.Equal(t, rel.Config, imported.Config)
+	assert.Equal(t, rel.Labels, imported.Labels)
+	assert.Equal(t, rel.Info.Notes, imported.Info.Notes)
+	assert.Equal(t, rel.Chart.Metadata.Version, imported.Chart.Metadata.Version)
+	assert.Len(t, imported.Hooks, len(rel.Hooks))
+
+	_, err = ReadReleaseArchive(bytes.NewReader([]byte("not an archive")))
+	assert.Error(t, err)
+}
+
+func TestReleaseImport(t *testing.T) {
+	config := actionConfigFixture(t)
+	client := NewReleaseImport(config)
+	client.Namespace = "staging"
+
+	rel := namedReleaseStub("imported", common.StatusDeployed)
+	rel.Version = 3
+	rel.Namespace = "prod"
+	imported, err := client.Run(rel)
+	require.NoError(t, err)
+	assert.Equal(t, "staging", imported.Namespace)
+
+	reli, err := config.Releases.Get("imported", 3)
+	require.NoError(t, err)
+	stored, err := releaserToV1Release(reli)
+	require.NoError(t, err)
+	assert.Equal(t, common.StatusDeployed, stored.Info.Status)
+
+	_, err = client.Run(namedReleaseStub("imported", common.StatusDeployed))
+	assert.ErrorContains(t, err, `release "imported" already exists`)
+
+	invalid := namedReleaseStub("invalid", common.StatusDeployed)
+	invalid.Version = 0
+	_, err = client.Run(invalid)
+	assert.ErrorIs(t, err, errInvalidRevision)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// ProtectedLabel is the label of the releases protected against uninstall.
+const ProtectedLabel = "helm.sh/protected"
+
+// ReleaseProtect is the action for protecting releases against uninstall.
+//
+// It provides the implementation of 'helm release protect' and
+// 'helm release unprotect'.
+type ReleaseProtect struct {
+	cfg *Configuration
+
+	// Remove removes the protection of the release instead of adding it.
+	Remove      bool
+	LockTimeout time.Duration
+}
+
+// NewReleaseProtect creates a new ReleaseProtect object with the given configuration.
+func NewReleaseProtect(cfg *Configuration) *ReleaseProtect {
+	return &ReleaseProtect{
+		cfg: cfg,
+	}
+}
+
+// Run protects the named release, or removes its protection, by labeling all
+// of its revisions, so that rollbacks and upgrades keep the protection.
+func (r *ReleaseProtect) Run(name string) error {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return fmt.Errorf("release protect: Release name is invalid: %s", name)
+	}
+
+	_, unlock, err := r.cfg.Releases.Lock(context.Background(), name, "protect", r.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	relsi, err := r.cfg.Releases.History(name)
+	if err != nil {
+		return fmt.Errorf("release protect: Release not loaded: %s: %w", name, err)
+	}
+	rels, err := releaseListToV1List(relsi)
+	if err != nil {
+		return err
+	}
+	for _, rel := range rels {
+		if isProtected(rel) != r.Remove {
+			continue
+		}
+		if r.Remove {
+			delete(rel.Labels, ProtectedLabel)
+		} else {
+			if rel.Labels == nil {
+				rel.Labels = map[string]string{}
+			}
+			rel.Labels[ProtectedLabel] = "true"
+		}
+		if err := r.cfg.Releases.Update(rel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isProtected returns whether a release is protected against uninstall.
+func isProtected(rel *release.Release) bool {
+	return rel.Labels[ProtectedLabel] == "true"
+}
@@ -0,0 +1,60 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release/common"
+)
+
+func TestReleaseProtect(t *testing.T) {
+	config := actionConfigFixture(t)
+	first := namedReleaseStub("guarded", common.StatusSuperseded)
+	second := namedReleaseStub("guarded", common.StatusDeployed)
+	second.Version = 2
+	require.NoError(t, config.Releases.Create(first))
+	require.NoError(t, config.Releases.Create(second))
+
+	require.NoError(t, NewReleaseProtect(config).Run("guarded"))
+	for _, version := range []int{1, 2} {
+		reli, err := config.Releases.Get("guarded", version)
+		require.NoError(t, err)
+		rel, err := releaserToV1Release(reli)
+		require.NoError(t, err)
+		assert.True(t, isProtected(rel), "revision %d", version)
+	}
+
+	unAction := NewUninstall(config)
+	unAction.DisableHooks = true
+	_, err := unAction.Run("guarded")
+	require.ErrorContains(t, err, `release "guarded" is protected against uninstall`)
+
+	unprotect := NewReleaseProtect(config)
+	unprotect.Remove = true
+	require.NoError(t, unprotect.Run("guarded"))
+	_, err = unAction.Run("guarded")
+	require.NoError(t, err)
+}
+
+func TestReleaseProtect_ReleaseNotFound(t *testing.T) {
+	err := NewReleaseProtect(actionConfigFixture(t)).Run("missing")
+	assert.ErrorContains(t, err, "release protect: Release not loaded: missing")
+}
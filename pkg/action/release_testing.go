@@ -18,6 +18,7 @@ package action
 
 import (
 	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
@@ -50,6 +51,13 @@ type ReleaseTesting struct {
 	// Used for fetching logs from test pods
 	Namespace string
 	Filters   map[string][]string
+	// Parallel is how many tests of the same weight are run at once. Tests
+	// are run one at a time unless it is greater than one.
+	Parallel int
+
+	// started is when the tests were last run, to tell apart the tests that
+	// did not run from the tests run before.
+	started time.Time
 }
 
 // NewReleaseTesting creates a new ReleaseTesting object with the given configuration.
@@ -105,8 +113,15 @@ func (r *ReleaseTesting) Run(name string) (ri.Releaser, ExecuteShutdownFunc, err
 		rel.Hooks = executingHooks
 	}
 
+	r.started = time.Now()
 	serverSideApply := rel.ApplyMethod == string(release.ApplyMethodServerSideApply)
-	shutdown, err := r.cfg.execHookWithDelayedShutdown(rel, release.HookTest, kube.StatusWatcherStrategy, r.WaitOptions, r.Timeout, serverSideApply)
+	var shutdown ExecuteShutdownFunc
+	if r.Parallel > 1 {
+		shutdown, err = r.cfg.execHookGraph(rel, release.HookTest, hooksForEvent(rel, release.HookTest),
+			kube.StatusWatcherStrategy, r.WaitOptions, r.Timeout, serverSideApply, r.Parallel)
+	} else {
+		shutdown, err = r.cfg.execHookWithDelayedShutdown(rel, release.HookTest, kube.StatusWatcherStrategy, r.WaitOptions, r.Timeout, serverSideApply)
+	}
 
 	if err != nil {
 		rel.Hooks = append(skippedHooks, rel.Hooks...)
@@ -132,10 +147,7 @@ func (r *ReleaseTesting) GetPodLogs(out io.Writer, rel *release.Release) error {
 	for _, h := range hooksByWeight {
 		for _, e := range h.Events {
 			if e == release.HookTest {
-				if slices.Contains(r.Filters[ExcludeNameFilter], h.Name) {
-					continue
-				}
-				if len(r.Filters[IncludeNameFilter]) > 0 && !slices.Contains(r.Filters[IncludeNameFilter], h.Name) {
+				if r.filtered(h) {
 					continue
 				}
 
@@ -151,6 +163,14 @@ func (r *ReleaseTesting) GetPodLogs(out io.Writer, rel *release.Release) error {
 	return nil
 }
 
+// filtered returns whether the filters exclude the test hook.
+func (r *ReleaseTesting) filtered(h *release.Hook) bool {
+	if slices.Contains(r.Filters[ExcludeNameFilter], h.Name) {
+		return true
+	}
+	return len(r.Filters[IncludeNameFilter]) > 0 && !slices.Contains(r.Filters[IncludeNameFilter], h.Name)
+}
+
 // getContainerLogs fetches logs from all containers (init and regular) in the
 // named pod and writes them to out. It continues on per-container errors and
 // returns all of them joined at the end.
@@ -182,3 +202,94 @@ func (r *ReleaseTesting) getContainerLogs(out io.Writer, client kubernetes.Inter
 	}
 	return errors.Join(errs...)
 }
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnitReport writes the results of the tests of the given release to
+// out as a JUnit XML report, for CI tools to consume. The tests excluded by
+// the filters, and the tests that were not run since the tests were last run
+// by this action, are reported as skipped.
+func (r *ReleaseTesting) WriteJUnitReport(out io.Writer, rel *release.Release) error {
+	suite := junitTestSuite{Name: rel.Name}
+	var started, completed time.Time
+	for _, h := range hooksForEvent(rel, release.HookTest) {
+		tc := junitTestCase{Name: h.Name, Classname: rel.Name, Time: junitSeconds(0)}
+		run := h.LastRun
+		switch {
+		case r.filtered(h):
+			tc.Skipped = &junitMessage{Message: "excluded by filter"}
+		case run.StartedAt.IsZero() || run.StartedAt.Before(r.started):
+			tc.Skipped = &junitMessage{Message: "not run"}
+		default:
+			if !run.CompletedAt.IsZero() {
+				tc.Time = junitSeconds(run.CompletedAt.Sub(run.StartedAt))
+			}
+			if started.IsZero() || run.StartedAt.Before(started) {
+				started = run.StartedAt
+			}
+			if run.CompletedAt.After(completed) {
+				completed = run.CompletedAt
+			}
+			if run.Phase != release.HookPhaseSucceeded {
+				tc.Failure = &junitMessage{Message: fmt.Sprintf("test %s: %s", h.Name, run.Phase)}
+			}
+		}
+
+		suite.Tests++
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = junitSeconds(0)
+	if !started.IsZero() {
+		suite.Timestamp = started.UTC().Format(time.RFC3339)
+		if completed.After(started) {
+			suite.Time = junitSeconds(completed.Sub(started))
+		}
+	}
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(out)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return fmt.Errorf("unable to write the JUnit report: %w", err)
+	}
+	_, err := fmt.Fprintln(out)
+	return err
+}
+
+// junitSeconds formats a duration as the seconds JUnit reports use.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
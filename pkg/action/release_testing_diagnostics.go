@@ -0,0 +1,275 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// CollectDiagnostics gathers the diagnostics of the failed tests of the given
+// release into a new bundle directory under dir, and returns the path of the
+// bundle. The bundle holds, for each pod of a failed test, a directory with
+// its description (describe.txt) and the logs of its containers
+// (<container>.log), and the events of the namespace (events.txt).
+//
+// No bundle is created, and an empty path returned, if no test failed.
+// Diagnostics that cannot be collected are skipped, and their errors returned
+// with the path of the bundle.
+func (r *ReleaseTesting) CollectDiagnostics(dir string, rel *release.Release) (string, error) {
+	var failed []*release.Hook
+	for _, h := range hooksForEvent(rel, release.HookTest) {
+		if h.LastRun.Phase == release.HookPhaseFailed && !r.filtered(h) {
+			failed = append(failed, h)
+		}
+	}
+	if len(failed) == 0 {
+		return "", nil
+	}
+
+	client, err := r.cfg.KubernetesClientSet()
+	if err != nil {
+		return "", fmt.Errorf("unable to get kubernetes client to collect diagnostics: %w", err)
+	}
+	return r.collectDiagnostics(client, dir, rel.Name, failed)
+}
+
+// collectDiagnostics gathers the diagnostics of the given failed tests into a
+// new bundle directory under dir.
+func (r *ReleaseTesting) collectDiagnostics(client kubernetes.Interface, dir, releaseName string, failed []*release.Hook) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("unable to create the diagnostics directory: %w", err)
+	}
+	bundle, err := os.MkdirTemp(dir, releaseName+"-diagnostics-")
+	if err != nil {
+		return "", fmt.Errorf("unable to create the diagnostics bundle: %w", err)
+	}
+
+	events, err := client.CoreV1().Events(r.Namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("unable to list the events of namespace %s: %w", r.Namespace, err)
+	}
+	sort.SliceStable(events.Items, func(i, j int) bool {
+		return eventTime(&events.Items[i]).Before(eventTime(&events.Items[j]))
+	})
+
+	var errs []error
+	for _, h := range failed {
+		pods, err := r.testPods(client, h)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, pod := range pods {
+			if err := r.collectPodDiagnostics(client, filepath.Join(bundle, pod.Name), &pod, events.Items); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := writeDiagnosticsFile(filepath.Join(bundle, "events.txt"), func(w io.Writer) {
+		writeEvents(w, events.Items)
+	}); err != nil {
+		errs = append(errs, err)
+	}
+	return bundle, errors.Join(errs...)
+}
+
+// testPods returns the pods run by a test hook: the pod of a Pod hook, or the
+// pods of the job of a Job hook.
+func (r *ReleaseTesting) testPods(client kubernetes.Interface, h *release.Hook) ([]v1.Pod, error) {
+	switch h.Kind {
+	case "Pod":
+		pod, err := client.CoreV1().Pods(r.Namespace).Get(context.Background(), h.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get pod %s: %w", h.Name, err)
+		}
+		return []v1.Pod{*pod}, nil
+	case "Job":
+	default:
+		return nil, nil
+	}
+	pods, err := client.CoreV1().Pods(r.Namespace).List(context.Background(), metav1.ListOptions{LabelSelector: "job-name=" + h.Name})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the pods of test %s: %w", h.Name, err)
+	}
+	return pods.Items, nil
+}
+
+// collectPodDiagnostics writes the description of a pod and the logs of its
+// containers to dir.
+func (r *ReleaseTesting) collectPodDiagnostics(client kubernetes.Interface, dir string, pod *v1.Pod, events []v1.Event) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("unable to create the diagnostics of pod %s: %w", pod.Name, err)
+	}
+
+	var errs []error
+	if err := writeDiagnosticsFile(filepath.Join(dir, "describe.txt"), func(w io.Writer) {
+		describePod(w, pod, events)
+	}); err != nil {
+		errs = append(errs, err)
+	}
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		if err := r.collectContainerLogs(client, filepath.Join(dir, c.Name+".log"), pod.Name, c.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// collectContainerLogs writes the logs of a container to the named file.
+func (r *ReleaseTesting) collectContainerLogs(client kubernetes.Interface, path, podName, container string) error {
+	logReader, err := client.CoreV1().Pods(r.Namespace).GetLogs(podName, &v1.PodLogOptions{Container: container}).Stream(context.Background())
+	if err != nil {
+		return fmt.Errorf("unable to get logs for pod %s, container %s: %w", podName, container, err)
+	}
+	defer logReader.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, logReader); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write logs for pod %s, container %s: %w", podName, container, err)
+	}
+	return f.Close()
+}
+
+// writeDiagnosticsFile creates the named file with the output of write.
+func writeDiagnosticsFile(path string, write func(w io.Writer)) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(f, 0, 0, 2, ' ', 0)
+	write(w)
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// describePod writes a description of a pod, its containers and its events,
+// like 'kubectl describe pod' does.
+func describePod(w io.Writer, pod *v1.Pod, events []v1.Event) {
+	fmt.Fprintf(w, "Name:\t%s\n", pod.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", pod.Namespace)
+	fmt.Fprintf(w, "Node:\t%s\n", pod.Spec.NodeName)
+	if pod.Status.StartTime != nil {
+		fmt.Fprintf(w, "Start Time:\t%s\n", pod.Status.StartTime.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "Status:\t%s\n", pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		fmt.Fprintf(w, "Reason:\t%s\n", pod.Status.Reason)
+	}
+	if pod.Status.Message != "" {
+		fmt.Fprintf(w, "Message:\t%s\n", pod.Status.Message)
+	}
+
+	statuses := map[string]v1.ContainerStatus{}
+	for _, cs := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		statuses[cs.Name] = cs
+	}
+	fmt.Fprintln(w, "Containers:")
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		cs := statuses[c.Name]
+		fmt.Fprintf(w, "  %s:\n", c.Name)
+		fmt.Fprintf(w, "    Image:\t%s\n", c.Image)
+		if len(c.Command) > 0 {
+			fmt.Fprintf(w, "    Command:\t%s\n", strings.Join(c.Command, " "))
+		}
+		fmt.Fprintf(w, "    State:\t%s\n", containerState(cs.State))
+		if cs.LastTerminationState != (v1.ContainerState{}) {
+			fmt.Fprintf(w, "    Last State:\t%s\n", containerState(cs.LastTerminationState))
+		}
+		fmt.Fprintf(w, "    Ready:\t%t\n", cs.Ready)
+		fmt.Fprintf(w, "    Restart Count:\t%d\n", cs.RestartCount)
+	}
+
+	fmt.Fprintln(w, "Conditions:")
+	for _, c := range pod.Status.Conditions {
+		fmt.Fprintf(w, "  %s\t%s\n", c.Type, c.Status)
+	}
+
+	var podEvents []v1.Event
+	for _, e := range events {
+		if e.InvolvedObject.Kind == "Pod" && e.InvolvedObject.Name == pod.Name {
+			podEvents = append(podEvents, e)
+		}
+	}
+	fmt.Fprintln(w, "Events:")
+	writeEvents(w, podEvents)
+}
+
+// containerState describes the state of a container.
+func containerState(state v1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return fmt.Sprintf("Running (started %s)", state.Running.StartedAt.UTC().Format(time.RFC3339))
+	case state.Terminated != nil:
+		s := fmt.Sprintf("Terminated (Reason: %s, Exit Code: %d)", state.Terminated.Reason, state.Terminated.ExitCode)
+		if state.Terminated.Message != "" {
+			s += ": " + state.Terminated.Message
+		}
+		return s
+	case state.Waiting != nil:
+		s := fmt.Sprintf("Waiting (Reason: %s)", state.Waiting.Reason)
+		if state.Waiting.Message != "" {
+			s += ": " + state.Waiting.Message
+		}
+		return s
+	default:
+		return "Unknown"
+	}
+}
+
+// writeEvents writes a table of events.
+func writeEvents(w io.Writer, events []v1.Event) {
+	fmt.Fprintln(w, "TIME\tTYPE\tREASON\tOBJECT\tMESSAGE")
+	for _, e := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%s\n",
+			eventTime(&e).UTC().Format(time.RFC3339), e.Type, e.Reason,
+			e.InvolvedObject.Kind, e.InvolvedObject.Name, strings.TrimSpace(e.Message))
+	}
+}
+
+// eventTime returns when an event was last seen.
+func eventTime(e *v1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
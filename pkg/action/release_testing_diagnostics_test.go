@@ -0,0 +1,105 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestReleaseTestingCollectDiagnostics(t *testing.T) {
+	testPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "smoke", Namespace: "default"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "curl", Image: "curlimages/curl"}},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodFailed,
+			ContainerStatuses: []v1.ContainerStatus{{
+				Name:  "curl",
+				State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Error", ExitCode: 7}},
+			}},
+		},
+	}
+	jobPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "e2e-x7k2p", Namespace: "default", Labels: map[string]string{"job-name": "e2e"}},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "e2e"}}},
+	}
+	otherPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-5d8f", Namespace: "default", Labels: map[string]string{"app": "web"}},
+	}
+	event := &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "smoke.1", Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "smoke"},
+		Type:           "Warning",
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+	}
+	client := fakeclientset.NewClientset(testPod, jobPod, otherPod, event)
+
+	failed := []*release.Hook{
+		{Name: "smoke", Kind: "Pod"},
+		{Name: "e2e", Kind: "Job"},
+	}
+	rt := &ReleaseTesting{Namespace: "default"}
+	dir := t.TempDir()
+	bundle, err := rt.collectDiagnostics(client, dir, "myrelease", failed)
+	require.NoError(t, err)
+	assert.Equal(t, dir, filepath.Dir(bundle))
+	assert.Contains(t, filepath.Base(bundle), "myrelease-diagnostics-")
+
+	describe, err := os.ReadFile(filepath.Join(bundle, "smoke", "describe.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(describe), "Status:     Failed")
+	assert.Contains(t, string(describe), "Terminated (Reason: Error, Exit Code: 7)")
+	assert.Contains(t, string(describe), "Back-off restarting failed container")
+
+	logs, err := os.ReadFile(filepath.Join(bundle, "smoke", "curl.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "fake logs", string(logs))
+	assert.FileExists(t, filepath.Join(bundle, "e2e-x7k2p", "e2e.log"))
+	assert.NoDirExists(t, filepath.Join(bundle, "web-5d8f"))
+
+	events, err := os.ReadFile(filepath.Join(bundle, "events.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(events), "Warning  BackOff  Pod/smoke")
+}
+
+func TestReleaseTestingCollectDiagnostics_NoFailedTests(t *testing.T) {
+	rel := &release.Release{
+		Name: "myrelease",
+		Hooks: []*release.Hook{{
+			Name:    "smoke",
+			Kind:    "Pod",
+			Events:  []release.HookEvent{release.HookTest},
+			LastRun: release.HookExecution{Phase: release.HookPhaseSucceeded},
+		}},
+	}
+	rt := NewReleaseTesting(actionConfigFixture(t))
+	bundle, err := rt.CollectDiagnostics(t.TempDir(), rel)
+	require.NoError(t, err)
+	assert.Empty(t, bundle)
+}
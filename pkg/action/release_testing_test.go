@@ -23,6 +23,7 @@ import (
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -258,3 +259,80 @@ func TestGetContainerLogs_OutputHeaderFormat(t *testing.T) {
 	assert.Contains(t, output, "POD LOGS: multi-test (container-a)")
 	assert.Contains(t, output, "POD LOGS: multi-test (container-b)")
 }
+
+func TestReleaseTesting_Parallel(t *testing.T) {
+	config := actionConfigFixture(t)
+	// Each test waits for the other one to have been created, which only
+	// succeeds if they run at once.
+	client := &hookGraphKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard},
+		waitFor:            map[string]string{"test-a": "test-b", "test-b": "test-a"},
+	}
+	config.KubeClient = client
+
+	rel := releaseStub()
+	rel.Name = "parallel-tests"
+	rel.Hooks = nil
+	for _, name := range []string{"test-a", "test-b"} {
+		h := graphHook(name, 0)
+		h.Events = []release.HookEvent{release.HookTest}
+		rel.Hooks = append(rel.Hooks, h)
+	}
+	require.NoError(t, config.Releases.Create(rel))
+
+	rt := NewReleaseTesting(config)
+	rt.Parallel = 2
+	_, shutdown, err := rt.Run(rel.Name)
+	require.NoError(t, err)
+	require.NoError(t, shutdown())
+	assert.ElementsMatch(t, []string{"test-a", "test-b"}, client.created)
+}
+
+func TestReleaseTesting_WriteJUnitReport(t *testing.T) {
+	started := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	testHook := func(name string, phase release.HookPhase, d time.Duration) *release.Hook {
+		return &release.Hook{
+			Name:   name,
+			Kind:   "Pod",
+			Events: []release.HookEvent{release.HookTest},
+			LastRun: release.HookExecution{
+				StartedAt:   started,
+				CompletedAt: started.Add(d),
+				Phase:       phase,
+			},
+		}
+	}
+	rel := &release.Release{
+		Name: "myrelease",
+		Hooks: []*release.Hook{
+			testHook("passing", release.HookPhaseSucceeded, 1500*time.Millisecond),
+			testHook("failing", release.HookPhaseFailed, 2*time.Second),
+			testHook("excluded", release.HookPhaseSucceeded, time.Second),
+			{Name: "pending", Kind: "Pod", Events: []release.HookEvent{release.HookTest}},
+			{Name: "install", Kind: "Job", Events: []release.HookEvent{release.HookPreInstall}},
+		},
+	}
+
+	client := NewReleaseTesting(actionConfigFixture(t))
+	client.Filters[ExcludeNameFilter] = []string{"excluded"}
+	out := &bytes.Buffer{}
+	require.NoError(t, client.WriteJUnitReport(out, rel))
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="myrelease" tests="4" failures="1" skipped="2" time="2.000" timestamp="2025-01-02T03:04:05Z">
+    <testcase name="excluded" classname="myrelease" time="0.000">
+      <skipped message="excluded by filter"></skipped>
+    </testcase>
+    <testcase name="failing" classname="myrelease" time="2.000">
+      <failure message="test failing: Failed"></failure>
+    </testcase>
+    <testcase name="passing" classname="myrelease" time="1.500"></testcase>
+    <testcase name="pending" classname="myrelease" time="0.000">
+      <skipped message="not run"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, expected, out.String())
+}
@@ -0,0 +1,53 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/storage"
+)
+
+// ReleaseUnlock is the action for removing the lock of a release.
+//
+// It provides the implementation of 'helm release unlock'.
+type ReleaseUnlock struct {
+	cfg *Configuration
+}
+
+// NewReleaseUnlock creates a new ReleaseUnlock object with the given configuration.
+func NewReleaseUnlock(cfg *Configuration) *ReleaseUnlock {
+	return &ReleaseUnlock{
+		cfg: cfg,
+	}
+}
+
+// Run removes the lock of the release regardless of the operation holding it,
+// for example after Helm was interrupted. It returns the removed lock, or nil
+// if the release was not locked.
+func (r *ReleaseUnlock) Run(name string) (*storage.Lock, error) {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, fmt.Errorf("release name is invalid: %s", name)
+	}
+	if r.cfg.Releases.Locker == nil {
+		return nil, errors.New("the storage driver does not support release locks")
+	}
+	return r.cfg.Releases.Locker.ForceUnlock(context.Background(), name)
+}
@@ -37,9 +37,28 @@ func filterManifestsToKeep(manifests []releaseutil.Manifest) (keep, remaining []
 		}
 
 		resourcePolicyType = strings.ToLower(strings.TrimSpace(resourcePolicyType))
-		if resourcePolicyType == kube.KeepPolicy {
+		switch resourcePolicyType {
+		case kube.KeepPolicy:
 			keep = append(keep, m)
+		case kube.ProtectedPolicy:
+			remaining = append(remaining, m)
 		}
 	}
 	return keep, remaining
 }
+
+// filterProtectedManifests returns the manifests of the resources protected
+// against uninstall.
+func filterProtectedManifests(manifests []releaseutil.Manifest) []releaseutil.Manifest {
+	var protected []releaseutil.Manifest
+	for _, m := range manifests {
+		if m.Head.Metadata == nil {
+			continue
+		}
+		resourcePolicyType := strings.ToLower(strings.TrimSpace(m.Head.Metadata.Annotations[kube.ResourcePolicyAnno]))
+		if resourcePolicyType == kube.ProtectedPolicy {
+			protected = append(protected, m)
+		}
+	}
+	return protected
+}
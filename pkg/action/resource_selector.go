@@ -0,0 +1,194 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// ResourceSelector matches rendered resources by kind, name and namespace.
+// Empty fields match any value.
+type ResourceSelector struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// ParseResourceSelector parses a selector of the form
+// "kind=Deployment,name=web,namespace=prod". At least one field must be set.
+func ParseResourceSelector(s string) (ResourceSelector, error) {
+	var sel ResourceSelector
+	for pair := range strings.SplitSeq(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || value == "" {
+			return sel, fmt.Errorf("invalid resource selector %q: expected key=value pairs", s)
+		}
+		switch strings.ToLower(key) {
+		case "kind":
+			sel.Kind = value
+		case "name":
+			sel.Name = value
+		case "namespace":
+			sel.Namespace = value
+		default:
+			return sel, fmt.Errorf("invalid resource selector %q: unknown key %q, must be one of kind, name or namespace", s, key)
+		}
+	}
+	return sel, nil
+}
+
+// Matches returns true if a resource with the given kind, name and namespace is selected.
+func (s ResourceSelector) Matches(kind, name, namespace string) bool {
+	return (s.Kind == "" || strings.EqualFold(s.Kind, kind)) &&
+		(s.Name == "" || s.Name == name) &&
+		(s.Namespace == "" || s.Namespace == namespace)
+}
+
+func (s ResourceSelector) String() string {
+	var parts []string
+	for _, f := range []struct{ key, value string }{{"kind", s.Kind}, {"name", s.Name}, {"namespace", s.Namespace}} {
+		if f.value != "" {
+			parts = append(parts, f.key+"="+f.value)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+type selectedResource struct {
+	key      string
+	manifest string
+	selected bool
+}
+
+// selectResources returns the manifest of an upgrade applying only the
+// resources matched by the selectors. Selected resources are taken from the
+// upgraded manifest, all other resources are kept as they are in the current
+// manifest. Resources without a namespace are in the release namespace.
+func selectResources(current, upgraded, namespace string, selectors []ResourceSelector) (string, error) {
+	currentResources, err := splitSelectedResources(current, namespace, selectors)
+	if err != nil {
+		return "", err
+	}
+	upgradedResources, err := splitSelectedResources(upgraded, namespace, selectors)
+	if err != nil {
+		return "", err
+	}
+
+	matched := false
+	unselected := make(map[string]string, len(currentResources))
+	for _, r := range currentResources {
+		if r.selected {
+			matched = true
+		} else {
+			unselected[r.key] = r.manifest
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range upgradedResources {
+		switch {
+		case r.selected:
+			matched = true
+			fmt.Fprintf(&b, "---\n%s\n", r.manifest)
+		case unselected[r.key] != "":
+			fmt.Fprintf(&b, "---\n%s\n", unselected[r.key])
+			delete(unselected, r.key)
+		}
+	}
+	// Resources that are no longer part of the chart remain deployed
+	for _, r := range currentResources {
+		if m, ok := unselected[r.key]; ok {
+			fmt.Fprintf(&b, "---\n%s\n", m)
+		}
+	}
+	if !matched {
+		return "", errors.New("no rendered resources match the resource selectors")
+	}
+	return b.String(), nil
+}
+
+// filterHooks returns the hooks whose resources are matched by the selectors
+// if selected is true, or the other hooks if selected is false. Hooks are in
+// the release namespace.
+func filterHooks(hooks []*release.Hook, namespace string, selectors []ResourceSelector, selected bool) []*release.Hook {
+	var filtered []*release.Hook
+	for _, h := range hooks {
+		matched := slices.ContainsFunc(selectors, func(s ResourceSelector) bool { return s.Matches(h.Kind, h.Name, namespace) })
+		if matched == selected {
+			filtered = append(filtered, h)
+		}
+	}
+	return filtered
+}
+
+func splitSelectedResources(manifest, namespace string, selectors []ResourceSelector) ([]selectedResource, error) {
+	docs := releaseutil.SplitManifests(manifest)
+	names := make([]string, 0, len(docs))
+	for name := range docs {
+		names = append(names, name)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(names))
+
+	resources := make([]selectedResource, 0, len(docs))
+	for _, name := range names {
+		doc := strings.TrimSpace(docs[name])
+		if doc == "" {
+			continue
+		}
+		var head struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &head); err != nil {
+			return nil, fmt.Errorf("unable to parse manifest: %w", err)
+		}
+		ns := head.Metadata.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		// Resources of the same kind in different API groups are different
+		var group string
+		if g, _, ok := strings.Cut(head.APIVersion, "/"); ok {
+			group = g
+		}
+		r := selectedResource{
+			key:      strings.Join([]string{group, head.Kind, ns, head.Metadata.Name}, "/"),
+			manifest: doc,
+		}
+		for _, s := range selectors {
+			if s.Matches(head.Kind, head.Metadata.Name, ns) {
+				r.selected = true
+				break
+			}
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
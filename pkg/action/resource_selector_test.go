@@ -0,0 +1,230 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResourceSelector(t *testing.T) {
+	tests := []struct {
+		input   string
+		expect  ResourceSelector
+		wantErr bool
+	}{
+		{input: "kind=Deployment", expect: ResourceSelector{Kind: "Deployment"}},
+		{input: "kind=Deployment,name=web", expect: ResourceSelector{Kind: "Deployment", Name: "web"}},
+		{input: "Name=web, namespace=prod", expect: ResourceSelector{Name: "web", Namespace: "prod"}},
+		{input: "", wantErr: true},
+		{input: "kind", wantErr: true},
+		{input: "kind=", wantErr: true},
+		{input: "label=app", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			sel, err := ParseResourceSelector(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, sel)
+		})
+	}
+}
+
+func TestResourceSelectorMatches(t *testing.T) {
+	sel := ResourceSelector{Kind: "deployment", Name: "web"}
+	assert.True(t, sel.Matches("Deployment", "web", "default"))
+	assert.False(t, sel.Matches("Deployment", "api", "default"))
+	assert.False(t, sel.Matches("Service", "web", "default"))
+	assert.True(t, ResourceSelector{Namespace: "prod"}.Matches("Service", "web", "prod"))
+	assert.False(t, ResourceSelector{Namespace: "prod"}.Matches("Service", "web", "default"))
+	assert.Equal(t, "kind=deployment,name=web", sel.String())
+}
+
+func TestSelectResources(t *testing.T) {
+	const current = `---
+# Source: chart/templates/cm.yaml
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: old
+---
+# Source: chart/templates/deployment.yaml
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+---
+# Source: chart/templates/removed.yaml
+kind: Service
+metadata:
+  name: removed
+`
+	const upgraded = `---
+# Source: chart/templates/cm.yaml
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: new
+---
+# Source: chart/templates/deployment.yaml
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+---
+# Source: chart/templates/added.yaml
+kind: Secret
+metadata:
+  name: added
+`
+
+	tests := []struct {
+		name      string
+		selectors []ResourceSelector
+		expect    string
+		wantErr   bool
+	}{
+		{
+			name:      "selected resource is upgraded",
+			selectors: []ResourceSelector{{Kind: "ConfigMap"}},
+			expect: `---
+# Source: chart/templates/cm.yaml
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: new
+---
+# Source: chart/templates/deployment.yaml
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+---
+# Source: chart/templates/removed.yaml
+kind: Service
+metadata:
+  name: removed
+`,
+		},
+		{
+			name:      "selected new resource is added",
+			selectors: []ResourceSelector{{Name: "added"}, {Kind: "Deployment", Namespace: "default"}},
+			expect: `---
+# Source: chart/templates/cm.yaml
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: old
+---
+# Source: chart/templates/deployment.yaml
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+---
+# Source: chart/templates/added.yaml
+kind: Secret
+metadata:
+  name: added
+---
+# Source: chart/templates/removed.yaml
+kind: Service
+metadata:
+  name: removed
+`,
+		},
+		{
+			name:      "selected removed resource is removed",
+			selectors: []ResourceSelector{{Kind: "Service"}},
+			expect: `---
+# Source: chart/templates/cm.yaml
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: old
+---
+# Source: chart/templates/deployment.yaml
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+`,
+		},
+		{
+			name:      "no match",
+			selectors: []ResourceSelector{{Kind: "Deployment", Namespace: "prod"}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, err := selectResources(current, upgraded, "default", tt.selectors)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, manifest)
+		})
+	}
+}
+
+func TestSelectResourcesAPIGroups(t *testing.T) {
+	const deployments = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: example.com/v1
+kind: Deployment
+metadata:
+  name: web
+`
+	const secret = `---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+data:
+  key: %s
+`
+	// Resources of the same kind and name in different API groups are
+	// different resources, so both are kept
+	manifest, err := selectResources(deployments+fmt.Sprintf(secret, "old"), deployments+fmt.Sprintf(secret, "new"), "default", []ResourceSelector{{Kind: "Secret"}})
+	require.NoError(t, err)
+	assert.Equal(t, deployments+fmt.Sprintf(secret, "new"), manifest)
+}
@@ -18,16 +18,23 @@ package action
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"helm.sh/helm/v4/internal/diff"
+	chartcommon "helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/kube"
 	"helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/secrets"
+	"helm.sh/helm/v4/pkg/storage"
 	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
@@ -39,6 +46,7 @@ type Rollback struct {
 
 	Version      int
 	Timeout      time.Duration
+	LockTimeout  time.Duration
 	WaitStrategy kube.WaitStrategy
 	WaitOptions  []kube.WaitOption
 	WaitForJobs  bool
@@ -59,6 +67,26 @@ type Rollback struct {
 	ServerSideApply string
 	CleanupOnFail   bool
 	MaxHistory      int // MaxHistory limits the maximum number of revisions saved per release
+	// HistoryRetention deletes the revisions of the release it does not keep,
+	// after MaxHistory is applied
+	HistoryRetention storage.RetentionPolicy
+	// ValuesOnly restores only the values of the revision rolled back to, and
+	// renders them with the chart of the current revision, instead of
+	// restoring the chart and manifest of that revision. This undoes a bad
+	// values change while keeping the current chart version.
+	ValuesOnly bool
+	// SecretProviders resolve the references to secrets in the values when
+	// they are rendered with ValuesOnly. If nil, no reference is resolved.
+	SecretProviders secrets.Providers
+	// DiffOutput, if set, receives a diff between the manifest of the deployed
+	// release and the manifest of the revision rolled back to before the
+	// rollback is performed. Combine with a DryRunStrategy to preview a
+	// rollback without performing it.
+	DiffOutput io.Writer
+	// DiffContext is the number of unchanged lines shown around each change in the diff
+	DiffContext int
+	// DiffNoColor disables colorized diff output
+	DiffNoColor bool
 }
 
 // NewRollback creates a new Rollback object with the given configuration.
@@ -67,6 +95,7 @@ func NewRollback(cfg *Configuration) *Rollback {
 		cfg:             cfg,
 		ServerSideApply: "auto", // Must always match the CLI default.
 		DryRunStrategy:  DryRunNone,
+		DiffContext:     diff.DefaultContext,
 	}
 }
 
@@ -76,7 +105,16 @@ func (r *Rollback) Run(name string) error {
 		return err
 	}
 
+	if !isDryRun(r.DryRunStrategy) {
+		_, unlock, err := r.cfg.Releases.Lock(context.Background(), name, "rollback", r.LockTimeout)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
 	r.cfg.Releases.MaxHistory = r.MaxHistory
+	r.cfg.Releases.Retention = r.HistoryRetention
 
 	r.cfg.Logger().Debug("preparing rollback", "name", name)
 	currentRelease, targetRelease, serverSideApply, err := r.prepareRollback(name)
@@ -84,6 +122,13 @@ func (r *Rollback) Run(name string) error {
 		return err
 	}
 
+	if r.DiffOutput != nil {
+		changes := diff.Manifests(currentRelease.Manifest, targetRelease.Manifest)
+		if err := diff.Write(r.DiffOutput, changes, diff.Options{Context: r.DiffContext, NoColor: r.DiffNoColor}); err != nil {
+			return fmt.Errorf("unable to write diff: %w", err)
+		}
+	}
+
 	if !isDryRun(r.DryRunStrategy) {
 		r.cfg.Logger().Debug("creating rolled back release", "name", name)
 		if err := r.cfg.Releases.Create(targetRelease); err != nil {
@@ -171,10 +216,11 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 
 	// Store a new release object with previous release's configuration
 	targetRelease := &release.Release{
-		Name:      name,
-		Namespace: currentRelease.Namespace,
-		Chart:     previousRelease.Chart,
-		Config:    previousRelease.Config,
+		Name:        name,
+		Namespace:   currentRelease.Namespace,
+		Chart:       previousRelease.Chart,
+		Config:      previousRelease.Config,
+		ValuesTrace: previousRelease.ValuesTrace,
 		Info: &release.Info{
 			FirstDeployed:    currentRelease.Info.FirstDeployed,
 			LastDeployed:     time.Now(),
@@ -192,9 +238,59 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 		ApplyMethod: string(determineReleaseSSApplyMethod(serverSideApply)),
 	}
 
+	if r.ValuesOnly {
+		if err := r.renderValuesOnly(currentRelease, targetRelease); err != nil {
+			return nil, nil, false, err
+		}
+	}
+
 	return currentRelease, targetRelease, serverSideApply, nil
 }
 
+// renderValuesOnly renders the values of targetRelease with the chart of
+// currentRelease, replacing the chart, manifest, hooks and notes of
+// targetRelease.
+func (r *Rollback) renderValuesOnly(currentRelease, targetRelease *release.Release) error {
+	ctx := context.Background()
+	chart := currentRelease.Chart
+	vals := targetRelease.Config
+
+	if err := chartutil.ProcessDependencies(chart, vals); err != nil {
+		return err
+	}
+
+	options := chartcommon.ReleaseOptions{
+		Name:      targetRelease.Name,
+		Namespace: targetRelease.Namespace,
+		Revision:  targetRelease.Version,
+		IsUpgrade: true,
+	}
+	caps, err := r.cfg.getCapabilities()
+	if err != nil {
+		return err
+	}
+	resolvedVals, err := r.SecretProviders.ResolveValues(ctx, vals)
+	if err != nil {
+		return err
+	}
+	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(chart, resolvedVals, options, caps, false)
+	if err != nil {
+		return err
+	}
+
+	hooks, manifestDoc, notesTxt, err := r.cfg.renderResources(ctx, chart, valuesToRender, "", "", "", false, false, false, nil, interactWithServer(r.DryRunStrategy), false, false, PostRenderStrategyCombined, nil)
+	if err != nil {
+		return err
+	}
+
+	targetRelease.Chart = chart
+	targetRelease.Manifest = manifestDoc.String()
+	targetRelease.Hooks = hooks
+	targetRelease.Info.Notes = notesTxt
+	targetRelease.Info.Description = fmt.Sprintf("Rollback to the values of %d", targetRelease.Info.RollbackRevision)
+	return nil
+}
+
 func (r *Rollback) performRollback(currentRelease, targetRelease *release.Release, serverSideApply bool) (*release.Release, error) {
 	if isDryRun(r.DryRunStrategy) {
 		r.cfg.Logger().Debug("dry run", "name", targetRelease.Name)
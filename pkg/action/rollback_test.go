@@ -17,6 +17,7 @@ limitations under the License.
 package action
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
@@ -25,8 +26,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	rcommon "helm.sh/helm/v4/pkg/release/common"
 )
 
 func TestNewRollback(t *testing.T) {
@@ -131,3 +135,85 @@ func TestRollbackRevisionZeroForNonRollback(t *testing.T) {
 
 	assert.Equal(t, 0, r.Info.RollbackRevision)
 }
+
+func TestRollbackValuesOnly(t *testing.T) {
+	config := actionConfigFixture(t)
+
+	chartWithVersion := func(version string) *chart.Chart {
+		ch := buildChartWithTemplates([]*common.File{{
+			Name: "templates/configmap.yaml",
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\ndata:\n  image: {{ .Values.image }}\n  chart: {{ .Chart.Version }}\n"),
+		}})
+		ch.Metadata.Version = version
+		return ch
+	}
+
+	rel1 := releaseStub()
+	rel1.Name = "rollback-values"
+	rel1.Version = 1
+	rel1.Info.Status = "superseded"
+	rel1.ApplyMethod = "csa"
+	rel1.Chart = chartWithVersion("0.1.0")
+	rel1.Config = map[string]any{"image": "nginx:1.25"}
+	require.NoError(t, config.Releases.Create(rel1))
+
+	rel2 := releaseStub()
+	rel2.Name = "rollback-values"
+	rel2.Version = 2
+	rel2.Info.Status = "deployed"
+	rel2.ApplyMethod = "csa"
+	rel2.Chart = chartWithVersion("0.2.0")
+	rel2.Config = map[string]any{"image": "nginx:broken"}
+	require.NoError(t, config.Releases.Create(rel2))
+
+	client := NewRollback(config)
+	client.Version = 1
+	client.ValuesOnly = true
+	require.NoError(t, client.Run("rollback-values"))
+
+	reli, err := config.Releases.Get("rollback-values", 3)
+	require.NoError(t, err)
+	rel, err := releaserToV1Release(reli)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0.2.0", rel.Chart.Metadata.Version)
+	assert.Equal(t, map[string]any{"image": "nginx:1.25"}, rel.Config)
+	assert.Contains(t, rel.Manifest, "image: nginx:1.25")
+	assert.Contains(t, rel.Manifest, "chart: 0.2.0")
+	assert.Equal(t, 1, rel.Info.RollbackRevision)
+	assert.Equal(t, "Rollback to the values of 1", rel.Info.Description)
+	assert.Equal(t, rcommon.StatusDeployed, rel.Info.Status)
+}
+
+func TestRollbackDryRunDiff(t *testing.T) {
+	config := actionConfigFixture(t)
+
+	rel1 := releaseStub()
+	rel1.Name = "rollback-diff"
+	rel1.Version = 1
+	rel1.Info.Status = "superseded"
+	rel1.Manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\ndata:\n  image: nginx:1.25\n"
+	require.NoError(t, config.Releases.Create(rel1))
+
+	rel2 := releaseStub()
+	rel2.Name = "rollback-diff"
+	rel2.Version = 2
+	rel2.Info.Status = "deployed"
+	rel2.Manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\ndata:\n  image: nginx:broken\n"
+	require.NoError(t, config.Releases.Create(rel2))
+
+	var out bytes.Buffer
+	client := NewRollback(config)
+	client.Version = 1
+	client.DryRunStrategy = DryRunClient
+	client.DiffOutput = &out
+	client.DiffNoColor = true
+	require.NoError(t, client.Run("rollback-diff"))
+
+	assert.Contains(t, out.String(), "-, cm, ConfigMap has been modified:\n")
+	assert.Contains(t, out.String(), "-  image: nginx:broken\n")
+	assert.Contains(t, out.String(), "+  image: nginx:1.25\n")
+
+	_, err := config.Releases.Get("rollback-diff", 3)
+	assert.Error(t, err)
+}
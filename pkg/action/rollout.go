@@ -0,0 +1,311 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+// RolloutStrategy controls how the resources of an upgrade are applied.
+type RolloutStrategy string
+
+const (
+	// RolloutStrategyAll applies all resources at once. This is the default.
+	RolloutStrategyAll RolloutStrategy = "all"
+	// RolloutStrategyCanary updates the replicas of the workloads of a release
+	// gradually, waiting for them to become ready between steps.
+	RolloutStrategyCanary RolloutStrategy = "canary"
+)
+
+// DefaultRolloutSteps are the percentages of replicas updated at each step of
+// a canary rollout when no steps are given.
+var DefaultRolloutSteps = []int{10, 50, 100}
+
+// canaryLabel is added to the selector and the pod template of the canary
+// Deployment running the new version of a Deployment during a canary rollout,
+// so that its pods are told apart from those of the Deployment.
+const canaryLabel = "helm.sh/canary"
+
+// workloadKinds are the kinds of resources that run pods from a pod template.
+var workloadKinds = map[string]bool{
+	"Deployment":            true,
+	"StatefulSet":           true,
+	"DaemonSet":             true,
+	"ReplicaSet":            true,
+	"ReplicationController": true,
+}
+
+func isWorkload(info *resource.Info) bool {
+	gvk := info.Mapping.GroupVersionKind
+	return (gvk.Group == "apps" || gvk.Group == "") && workloadKinds[gvk.Kind]
+}
+
+// isPartitionable returns whether a workload is a StatefulSet using the
+// RollingUpdate strategy, whose pods can be updated a part at a time by
+// setting the partition of the rolling update.
+func isPartitionable(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	if gvk.Group != "apps" || gvk.Kind != "StatefulSet" {
+		return false
+	}
+	strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "updateStrategy", "type")
+	return strategy == "" || strategy == "RollingUpdate"
+}
+
+// isDeployment returns whether a workload is a Deployment, which is rolled out
+// gradually by running its new version in a canary Deployment.
+func isDeployment(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == "apps" && gvk.Kind == "Deployment"
+}
+
+// podTemplateChanged returns whether the pod template of a workload differs
+// between two versions.
+func podTemplateChanged(current, target *unstructured.Unstructured) bool {
+	currentTemplate, _, _ := unstructured.NestedFieldNoCopy(current.Object, "spec", "template")
+	targetTemplate, _, _ := unstructured.NestedFieldNoCopy(target.Object, "spec", "template")
+	return !reflect.DeepEqual(currentTemplate, targetTemplate)
+}
+
+// withPartition returns a copy of a StatefulSet whose rolling update is
+// partitioned so that the given percentage of its replicas is updated. A
+// partition set by the chart is kept if it updates fewer replicas.
+func withPartition(info *resource.Info, obj *unstructured.Unstructured, percentage int) (*resource.Info, error) {
+	replicas, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		replicas = 1
+	}
+	partition, _, err := unstructured.NestedInt64(obj.Object, "spec", "updateStrategy", "rollingUpdate", "partition")
+	if err != nil {
+		return nil, err
+	}
+	// Round up so that every step updates at least one replica
+	updated := (replicas*int64(percentage) + 99) / 100
+	partition = max(partition, replicas-updated)
+
+	staged := obj.DeepCopy()
+	if err := unstructured.SetNestedField(staged.Object, "RollingUpdate", "spec", "updateStrategy", "type"); err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedField(staged.Object, partition, "spec", "updateStrategy", "rollingUpdate", "partition"); err != nil {
+		return nil, err
+	}
+	infoCopy := *info
+	infoCopy.Object = staged
+	return &infoCopy, nil
+}
+
+// withCanary returns the resources running a Deployment at a step of a canary
+// rollout: the Deployment running the pod template of current with the
+// replicas not yet updated, and a canary Deployment named after it running the
+// pod template of target with the given percentage of the replicas. The
+// canary Deployment is deleted by the last step, which applies target.
+func withCanary(info *resource.Info, current, target *unstructured.Unstructured, percentage int) (kube.ResourceList, error) {
+	replicas, found, err := unstructured.NestedInt64(target.Object, "spec", "replicas")
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		replicas = 1
+	}
+	// Round up so that every step updates at least one replica
+	updated := (replicas*int64(percentage) + 99) / 100
+
+	stable := target.DeepCopy()
+	template, found, err := unstructured.NestedFieldCopy(current.Object, "spec", "template")
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		if err := unstructured.SetNestedField(stable.Object, template, "spec", "template"); err != nil {
+			return nil, err
+		}
+	}
+	if err := unstructured.SetNestedField(stable.Object, replicas-updated, "spec", "replicas"); err != nil {
+		return nil, err
+	}
+
+	canary := target.DeepCopy()
+	canary.SetName(target.GetName() + "-canary")
+	if err := unstructured.SetNestedField(canary.Object, updated, "spec", "replicas"); err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedField(canary.Object, "true", "spec", "selector", "matchLabels", canaryLabel); err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedField(canary.Object, "true", "spec", "template", "metadata", "labels", canaryLabel); err != nil {
+		return nil, err
+	}
+
+	stableInfo := *info
+	stableInfo.Object = stable
+	canaryInfo := *info
+	canaryInfo.Name = canary.GetName()
+	canaryInfo.Object = canary
+	return kube.ResourceList{&stableInfo, &canaryInfo}, nil
+}
+
+// validateRolloutSteps checks that steps are increasing percentages between 1
+// and 100, and returns them with a final step of 100% appended if missing.
+func validateRolloutSteps(steps []int) ([]int, error) {
+	if len(steps) == 0 {
+		return DefaultRolloutSteps, nil
+	}
+	prev := 0
+	for _, s := range steps {
+		if s <= prev || s > 100 {
+			return nil, fmt.Errorf("invalid rollout steps %v: steps must be increasing percentages between 1 and 100", steps)
+		}
+		prev = s
+	}
+	if prev != 100 {
+		steps = append(slices.Clip(steps), 100)
+	}
+	return steps, nil
+}
+
+// rolloutStages returns the resources to apply at each step of a canary
+// rollout. Workloads whose pod template changed are updated gradually, so that
+// at each step the step percentage of their replicas runs the new version:
+// StatefulSets using the RollingUpdate strategy by partitioning their rolling
+// update, Deployments by running the new version in a canary Deployment. An
+// error is returned for other workloads whose pod template changed. All other
+// resources of target are applied at the first step. Resources of current
+// that are not part of target are kept until the last stage.
+func rolloutStages(current, target kube.ResourceList, steps []int) ([]kube.ResourceList, error) {
+	rolled := make(map[*resource.Info]*unstructured.Unstructured)
+	// previous holds the current version of the Deployments rolled out
+	previous := make(map[*resource.Info]*unstructured.Unstructured)
+	for _, info := range target {
+		old := current.Get(info)
+		if !isWorkload(info) || old == nil {
+			continue
+		}
+		obj, isUnstructured := info.Object.(*unstructured.Unstructured)
+		oldObj, oldIsUnstructured := old.Object.(*unstructured.Unstructured)
+		if !isUnstructured || !oldIsUnstructured || !podTemplateChanged(oldObj, obj) {
+			continue
+		}
+		switch {
+		case isDeployment(obj):
+			previous[info] = oldObj
+		case !isPartitionable(obj):
+			return nil, fmt.Errorf("%s cannot be rolled out gradually: canary rollouts only support Deployments and StatefulSets with the RollingUpdate strategy", resourceString(info))
+		}
+		rolled[info] = obj
+	}
+
+	stages := make([]kube.ResourceList, 0, len(steps))
+	for _, step := range steps {
+		if step == 100 {
+			stages = append(stages, target)
+			continue
+		}
+		var stage kube.ResourceList
+		for _, info := range target {
+			obj, ok := rolled[info]
+			if !ok {
+				stage.Append(info)
+				continue
+			}
+			if old, ok := previous[info]; ok {
+				staged, err := withCanary(info, old, obj, step)
+				if err != nil {
+					return nil, fmt.Errorf("unable to run a canary of %s: %w", resourceString(info), err)
+				}
+				stage = append(stage, staged...)
+				continue
+			}
+			staged, err := withPartition(info, obj, step)
+			if err != nil {
+				return nil, fmt.Errorf("unable to partition %s: %w", resourceString(info), err)
+			}
+			stage.Append(staged)
+		}
+		stages = append(stages, append(stage, current.Difference(target)...))
+	}
+	return stages, nil
+}
+
+// canaryRollout applies target over current step by step, waiting for the
+// applied resources to become ready after each step. When a step fails, the
+// resources are reverted to current and the error is returned.
+func (u *Upgrade) canaryRollout(current, target kube.ResourceList, opts ...kube.ClientUpdateOption) (*kube.Result, error) {
+	steps, err := validateRolloutSteps(u.RolloutSteps)
+	if err != nil {
+		return &kube.Result{}, err
+	}
+
+	// Readiness has to be checked between steps, even when not waiting for the release
+	strategy := u.WaitStrategy
+	if strategy == kube.HookOnlyStrategy || strategy == "" {
+		strategy = kube.StatusWatcherStrategy
+	}
+	var waiter kube.Waiter
+	if c, supportsOptions := u.cfg.KubeClient.(kube.InterfaceWaitOptions); supportsOptions {
+		waiter, err = c.GetWaiterWithOptions(strategy, u.WaitOptions...)
+	} else {
+		waiter, err = u.cfg.KubeClient.GetWaiter(strategy)
+	}
+	if err != nil {
+		return &kube.Result{}, err
+	}
+
+	stages, err := rolloutStages(current, target, steps)
+	if err != nil {
+		return &kube.Result{}, err
+	}
+
+	result := &kube.Result{}
+	applied := current
+	for i, stage := range stages {
+		u.cfg.Logger().Debug("canary rollout step", "step", i+1, "percentage", steps[i])
+		res, err := u.cfg.KubeClient.Update(applied, stage, opts...)
+		if res != nil {
+			result.Created = append(result.Created, res.Created...)
+			result.Updated = append(result.Updated, res.Updated...)
+			result.Deleted = append(result.Deleted, res.Deleted...)
+		}
+		// A failed update may have applied part of the stage, so revert all of it
+		applied = stage
+		if err == nil {
+			err = waitWithTimeouts(waiter, stage, u.Timeout, u.TimeoutPerKind, false)
+		}
+		if err != nil {
+			err = fmt.Errorf("canary rollout failed at step %d (%d%%): %w", i+1, steps[i], err)
+			if _, rerr := u.cfg.KubeClient.Update(applied, current, opts...); rerr != nil {
+				return result, errors.Join(err, fmt.Errorf("unable to revert canary rollout: %w", rerr))
+			}
+			// Resources created by the rollout were removed by the revert
+			return &kube.Result{}, err
+		}
+	}
+	// Canary Deployments are created and deleted by the rollout
+	result.Created = result.Created.Difference(result.Deleted)
+	return result, nil
+}
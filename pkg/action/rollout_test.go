@@ -0,0 +1,267 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/release/common"
+)
+
+func TestValidateRolloutSteps(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []int
+		want    []int
+		wantErr bool
+	}{
+		{name: "default steps", steps: nil, want: DefaultRolloutSteps},
+		{name: "final step", steps: []int{25, 100}, want: []int{25, 100}},
+		{name: "final step is appended", steps: []int{10, 50}, want: []int{10, 50, 100}},
+		{name: "decreasing steps", steps: []int{50, 10}, wantErr: true},
+		{name: "repeated steps", steps: []int{50, 50}, wantErr: true},
+		{name: "zero step", steps: []int{0, 100}, wantErr: true},
+		{name: "step above 100", steps: []int{50, 150}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := validateRolloutSteps(tt.steps)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, steps)
+		})
+	}
+}
+
+func rolloutInfo(kind, group, name, image string, spec map[string]any) *resource.Info {
+	gvk := schema.GroupVersionKind{Group: group, Version: "v1", Kind: kind}
+	if spec == nil {
+		spec = map[string]any{}
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	if image != "" {
+		_ = unstructured.SetNestedField(obj.Object, image, "spec", "template", "spec", "containers")
+	}
+	return &resource.Info{
+		Name:      name,
+		Namespace: "default",
+		Object:    obj,
+		Mapping:   &meta.RESTMapping{GroupVersionKind: gvk},
+	}
+}
+
+func partition(t *testing.T, info *resource.Info) int64 {
+	t.Helper()
+	p, found, err := unstructured.NestedInt64(info.Object.(*unstructured.Unstructured).Object, "spec", "updateStrategy", "rollingUpdate", "partition")
+	require.NoError(t, err)
+	require.True(t, found)
+	return p
+}
+
+func TestRolloutStages(t *testing.T) {
+	replicas := func(n int64) map[string]any { return map[string]any{"replicas": n} }
+
+	oldWorker := rolloutInfo("StatefulSet", "apps", "worker", "worker:1", replicas(10))
+	oldWeb := rolloutInfo("Deployment", "apps", "web", "web:1", nil)
+	oldConfig := rolloutInfo("ConfigMap", "", "config", "", nil)
+	removed := rolloutInfo("Service", "", "removed", "", nil)
+	current := kube.ResourceList{oldWorker, oldWeb, oldConfig, removed}
+
+	newWorker := rolloutInfo("StatefulSet", "apps", "worker", "worker:2", replicas(10))
+	newWeb := rolloutInfo("Deployment", "apps", "web", "web:1", nil)
+	newDB := rolloutInfo("StatefulSet", "apps", "db", "db:1", replicas(3))
+	newConfig := rolloutInfo("ConfigMap", "", "config", "", nil)
+	target := kube.ResourceList{newWorker, newWeb, newDB, newConfig}
+
+	stages, err := rolloutStages(current, target, []int{10, 50, 100})
+	require.NoError(t, err)
+	require.Len(t, stages, 3)
+
+	// The changed StatefulSet is partitioned, all other resources are applied
+	for i, want := range []int64{9, 5} {
+		require.Len(t, stages[i], 5)
+		assert.Equal(t, want, partition(t, stages[i][0]))
+		assert.Equal(t, kube.ResourceList{newWeb, newDB, newConfig, removed}, stages[i][1:])
+	}
+	// The last stage is the target
+	assert.Equal(t, target, stages[2])
+	// The target is not modified
+	assert.NotContains(t, newWorker.Object.(*unstructured.Unstructured).Object["spec"], "updateStrategy")
+
+	// Deployments whose pod template changed run the new version in a canary
+	changedWeb := rolloutInfo("Deployment", "apps", "web", "web:2", replicas(4))
+	stages, err = rolloutStages(current, kube.ResourceList{changedWeb}, []int{50, 100})
+	require.NoError(t, err)
+	require.Len(t, stages, 2)
+	require.Len(t, stages[0], 5)
+	assert.Equal(t, "web", stages[0][0].Name)
+	assert.Equal(t, "web-canary", stages[0][1].Name)
+	assert.Equal(t, kube.ResourceList{oldWorker, oldConfig, removed}, stages[0][2:])
+	assert.Equal(t, kube.ResourceList{changedWeb}, stages[1])
+
+	// Other workloads whose pod template changed cannot be rolled out
+	changedDaemon := rolloutInfo("DaemonSet", "apps", "agent", "agent:2", nil)
+	_, err = rolloutStages(kube.ResourceList{rolloutInfo("DaemonSet", "apps", "agent", "agent:1", nil)}, kube.ResourceList{changedDaemon}, []int{10, 100})
+	assert.ErrorContains(t, err, `DaemonSet "agent" in namespace "default" cannot be rolled out gradually`)
+
+	onDelete := rolloutInfo("StatefulSet", "apps", "worker", "worker:2", map[string]any{
+		"updateStrategy": map[string]any{"type": "OnDelete"},
+	})
+	_, err = rolloutStages(current, kube.ResourceList{onDelete}, []int{10, 100})
+	assert.ErrorContains(t, err, "cannot be rolled out gradually")
+}
+
+func TestWithPartition(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       map[string]any
+		percentage int
+		want       int64
+	}{
+		{name: "default replicas", percentage: 10, want: 0},
+		{name: "rounds up", spec: map[string]any{"replicas": int64(3)}, percentage: 10, want: 2},
+		{name: "half", spec: map[string]any{"replicas": int64(4)}, percentage: 50, want: 2},
+		{
+			name: "chart partition is kept",
+			spec: map[string]any{
+				"replicas":       int64(4),
+				"updateStrategy": map[string]any{"rollingUpdate": map[string]any{"partition": int64(3)}},
+			},
+			percentage: 50,
+			want:       3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := rolloutInfo("StatefulSet", "apps", "worker", "worker:2", tt.spec)
+			staged, err := withPartition(info, info.Object.(*unstructured.Unstructured), tt.percentage)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, partition(t, staged))
+		})
+	}
+}
+
+func TestWithCanary(t *testing.T) {
+	replicas := func(n int64) map[string]any {
+		return map[string]any{
+			"replicas": n,
+			"selector": map[string]any{"matchLabels": map[string]any{"app": "web"}},
+		}
+	}
+	old := rolloutInfo("Deployment", "apps", "web", "web:1", replicas(5))
+	target := rolloutInfo("Deployment", "apps", "web", "web:2", replicas(5))
+	targetObj := target.Object.(*unstructured.Unstructured)
+
+	staged, err := withCanary(target, old.Object.(*unstructured.Unstructured), targetObj, 10)
+	require.NoError(t, err)
+	require.Len(t, staged, 2)
+
+	stable := staged[0].Object.(*unstructured.Unstructured)
+	assert.Equal(t, "web", staged[0].Name)
+	assert.Equal(t, int64(4), stable.Object["spec"].(map[string]any)["replicas"])
+	image, _, _ := unstructured.NestedString(stable.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, "web:1", image, "the Deployment keeps running the current version")
+
+	canary := staged[1].Object.(*unstructured.Unstructured)
+	assert.Equal(t, "web-canary", staged[1].Name)
+	assert.Equal(t, "web-canary", canary.GetName())
+	assert.Equal(t, int64(1), canary.Object["spec"].(map[string]any)["replicas"], "every step updates at least one replica")
+	image, _, _ = unstructured.NestedString(canary.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, "web:2", image)
+	selector, _, _ := unstructured.NestedStringMap(canary.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]string{"app": "web", canaryLabel: "true"}, selector)
+	labels, _, _ := unstructured.NestedStringMap(canary.Object, "spec", "template", "metadata", "labels")
+	assert.Equal(t, map[string]string{canaryLabel: "true"}, labels)
+
+	// The target is not modified
+	assert.Equal(t, "web", targetObj.GetName())
+	assert.Equal(t, int64(5), targetObj.Object["spec"].(map[string]any)["replicas"])
+}
+
+func TestUpgradeRelease_CanaryRollout(t *testing.T) {
+	t.Run("invalid steps", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := releaseStub()
+		rel.Info.Status = common.StatusDeployed
+		require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+		upAction.RolloutStrategy = RolloutStrategyCanary
+		upAction.RolloutSteps = []int{50, 10}
+		_, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+		assert.ErrorContains(t, err, "invalid rollout steps")
+	})
+
+	t.Run("invalid strategy", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := releaseStub()
+		rel.Info.Status = common.StatusDeployed
+		require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+		upAction.RolloutStrategy = "linear"
+		_, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+		assert.ErrorContains(t, err, `invalid rollout strategy "linear"`)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := releaseStub()
+		rel.Info.Status = common.StatusDeployed
+		require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+		upAction.RolloutStrategy = RolloutStrategyCanary
+		upAction.RolloutSteps = []int{25, 100}
+		resi, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+		require.NoError(t, err)
+		res, err := releaserToV1Release(resi)
+		require.NoError(t, err)
+		assert.Equal(t, common.StatusDeployed, res.Info.Status)
+	})
+
+	t.Run("failed step", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := releaseStub()
+		rel.Info.Status = common.StatusDeployed
+		require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+		failer := upAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+		failer.WaitError = errors.New("deployment not ready")
+		upAction.cfg.KubeClient = failer
+		upAction.RolloutStrategy = RolloutStrategyCanary
+
+		resi, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "canary rollout failed at step 1 (10%): deployment not ready")
+		res, err := releaserToV1Release(resi)
+		require.NoError(t, err)
+		assert.Equal(t, common.StatusFailed, res.Info.Status)
+	})
+}
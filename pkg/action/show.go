@@ -18,17 +18,30 @@ package action
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/gosuri/uitable"
+	yamlv3 "go.yaml.in/yaml/v3"
 	"k8s.io/cli-runtime/pkg/printers"
 	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v4/internal/copystructure"
 	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/engine"
 	"helm.sh/helm/v4/pkg/registry"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
 )
 
 // ShowOutputFormat is the format of the output of `helm show`
@@ -45,10 +58,45 @@ const (
 	ShowReadme ShowOutputFormat = "readme"
 	// ShowCRDs is the format which only shows the chart's CRDs
 	ShowCRDs ShowOutputFormat = "crds"
+	// ShowDependencies is the format which only shows the chart's dependencies
+	ShowDependencies ShowOutputFormat = "dependencies"
+	// ShowSchema is the format which only shows the chart's values schema
+	ShowSchema ShowOutputFormat = "schema"
+	// ShowImages is the format which only shows the container images referenced by the chart's rendered manifests
+	ShowImages ShowOutputFormat = "images"
+	// ShowNotes is the format which only shows the chart's rendered NOTES.txt
+	ShowNotes ShowOutputFormat = "notes"
+	// ShowLicense is the format which only shows the chart's LICENSE file
+	ShowLicense ShowOutputFormat = "license"
+	// ShowFile is the format which only shows the file of the chart named by FilePath
+	ShowFile ShowOutputFormat = "file"
 )
 
+// ShowEncoding is the encoding used by `helm show values` and `helm show all`
+type ShowEncoding string
+
+const (
+	// ShowEncodingRaw prints the chart's files exactly as they are stored in the chart
+	ShowEncodingRaw ShowEncoding = "raw"
+	// ShowEncodingJSON prints the parsed information as JSON
+	ShowEncodingJSON ShowEncoding = "json"
+	// ShowEncodingYAML prints the parsed information as YAML with normalized (sorted) keys
+	ShowEncodingYAML ShowEncoding = "yaml"
+)
+
+// ShowEncodings returns the string representation of the supported encodings
+func ShowEncodings() []string {
+	return []string{ShowEncodingRaw.String(), ShowEncodingJSON.String(), ShowEncodingYAML.String()}
+}
+
+func (e ShowEncoding) String() string {
+	return string(e)
+}
+
 var readmeFileNames = []string{"readme.md", "readme.txt", "readme"}
 
+var licenseFileNames = []string{"license", "license.txt", "license.md", "licence", "copying"}
+
 func (o ShowOutputFormat) String() string {
 	return string(o)
 }
@@ -61,7 +109,27 @@ type Show struct {
 	Devel            bool
 	OutputFormat     ShowOutputFormat
 	JSONPathTemplate string
-	chart            *chart.Chart // for testing
+	// YAMLPathTemplate is a path expression such as '.image.tag' or
+	// '.dependencies[0].name' selecting a single field to print as YAML.
+	YAMLPathTemplate string
+	// Encoding controls how the chart's values (ShowValues) or the whole
+	// chart (ShowAll) are printed. The zero value behaves like ShowEncodingRaw.
+	Encoding ShowEncoding
+	// StripComments removes comments from raw values output. Parsed encodings
+	// never contain comments.
+	StripComments bool
+	// IncludeSubcharts also shows the information of vendored subcharts,
+	// placed under the key the parent chart refers to them by.
+	IncludeSubcharts bool
+	// Values are user supplied values used when rendering the chart's
+	// templates for ShowImages and ShowNotes.
+	Values map[string]any
+	// Profile is the name of a values profile of the chart, such as "prod" for
+	// profiles/prod.yaml, overriding the chart's default values.
+	Profile string
+	// FilePath is the path, relative to the chart root, of the file shown by ShowFile
+	FilePath string
+	chart    *chart.Chart // for testing
 }
 
 // NewShow creates a new Show object with the given configuration.
@@ -88,6 +156,20 @@ func (s *Show) Run(chartpath string) (string, error) {
 		}
 		s.chart = chrt
 	}
+	if s.Profile != "" {
+		if err := chartutil.ApplyProfile(s.chart, s.Profile); err != nil {
+			return "", err
+		}
+	}
+
+	if s.JSONPathTemplate != "" || s.YAMLPathTemplate != "" {
+		return s.runFilter()
+	}
+
+	if s.OutputFormat == ShowAll && s.Encoding != "" && s.Encoding != ShowEncodingRaw {
+		return s.encodeAll()
+	}
+
 	cf, err := yaml.Marshal(s.chart.Metadata)
 	if err != nil {
 		return "", err
@@ -102,18 +184,8 @@ func (s *Show) Run(chartpath string) (string, error) {
 		if s.OutputFormat == ShowAll {
 			fmt.Fprintln(&out, "---")
 		}
-		if s.JSONPathTemplate != "" {
-			printer, err := printers.NewJSONPathPrinter(s.JSONPathTemplate)
-			if err != nil {
-				return "", fmt.Errorf("error parsing jsonpath %s: %w", s.JSONPathTemplate, err)
-			}
-			printer.Execute(&out, s.chart.Values)
-		} else {
-			for _, f := range s.chart.Raw {
-				if f.Name == chartutil.ValuesfileName {
-					fmt.Fprintln(&out, string(f.Data))
-				}
-			}
+		if err := s.writeValues(&out); err != nil {
+			return "", err
 		}
 	}
 
@@ -138,12 +210,619 @@ func (s *Show) Run(chartpath string) (string, error) {
 			}
 		}
 	}
+
+	if s.OutputFormat == ShowSchema {
+		if err := s.writeSchema(&out); err != nil {
+			return "", err
+		}
+	}
+
+	if s.OutputFormat == ShowImages {
+		if err := s.writeImages(&out); err != nil {
+			return "", err
+		}
+	}
+
+	if s.OutputFormat == ShowNotes {
+		files, err := s.render(s.Values)
+		if err != nil {
+			return "", err
+		}
+		if notes := files[path.Join(s.chart.Name(), "templates", notesFileSuffix)]; notes != "" {
+			fmt.Fprintln(&out, strings.TrimRight(notes, "\n"))
+		}
+	}
+
+	if s.OutputFormat == ShowLicense {
+		if license := findFile(s.chart.Raw, licenseFileNames); license != nil {
+			fmt.Fprintf(&out, "%s", license.Data)
+		}
+	}
+
+	if s.OutputFormat == ShowFile {
+		name := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(s.FilePath)), "/")
+		var file *common.File
+		for _, f := range s.chart.Raw {
+			if f.Name == name {
+				file = f
+				break
+			}
+		}
+		if file == nil {
+			return "", fmt.Errorf("file %q not found in chart %q", s.FilePath, s.chart.Name())
+		}
+		fmt.Fprintf(&out, "%s", file.Data)
+	}
+
+	if s.OutputFormat == ShowDependencies && len(s.chart.Metadata.Dependencies) > 0 {
+		table := uitable.New()
+		table.AddRow("NAME", "VERSION", "REPOSITORY", "CONDITION", "RESOLVED")
+		addDependencyRows(table, s.chart, 0)
+		fmt.Fprintln(&out, table)
+	}
 	return out.String(), nil
 }
 
+// writeSchema writes the chart's values.schema.json to out. When subcharts are
+// included, their schemas are nested under the parent's properties using the
+// dependency alias, mirroring where their values live.
+func (s *Show) writeSchema(out *strings.Builder) error {
+	if !s.IncludeSubcharts {
+		if len(s.chart.Schema) > 0 {
+			fmt.Fprintln(out, string(bytes.TrimRight(s.chart.Schema, "\n")))
+		}
+		return nil
+	}
+
+	schema, err := composeSchema(s.chart)
+	if err != nil {
+		return err
+	}
+	if schema == nil {
+		return nil
+	}
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, string(b))
+	return nil
+}
+
+// composeSchema returns the schema of c with the schemas of its subcharts
+// merged in, or nil if neither c nor any subchart has a schema.
+func composeSchema(c *chart.Chart) (map[string]any, error) {
+	var schema map[string]any
+	if len(c.Schema) > 0 {
+		if err := json.Unmarshal(c.Schema, &schema); err != nil {
+			return nil, fmt.Errorf("unable to parse values schema of chart %q: %w", c.Name(), err)
+		}
+	}
+
+	for key, sub := range subchartsByKey(c) {
+		subSchema, err := composeSchema(sub)
+		if err != nil {
+			return nil, err
+		}
+		if subSchema == nil {
+			continue
+		}
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		props, _ := schema["properties"].(map[string]any)
+		if props == nil {
+			props = map[string]any{}
+			schema["properties"] = props
+		}
+		if existing, ok := props[key]; ok {
+			props[key] = map[string]any{"allOf": []any{existing, subSchema}}
+		} else {
+			props[key] = subSchema
+		}
+	}
+	return schema, nil
+}
+
+// subchartsByKey maps the vendored subcharts of c to the values key they are
+// addressed by, following dependency aliases. A subchart referenced by several
+// aliases appears once per alias.
+func subchartsByKey(c *chart.Chart) map[string]*chart.Chart {
+	subs := map[string]*chart.Chart{}
+	referenced := map[string]bool{}
+	if c.Metadata != nil {
+		for _, dep := range c.Metadata.Dependencies {
+			for _, sub := range c.Dependencies() {
+				if sub.Name() != dep.Name {
+					continue
+				}
+				key := dep.Name
+				if dep.Alias != "" {
+					key = dep.Alias
+				}
+				subs[key] = sub
+				referenced[sub.Name()] = true
+				break
+			}
+		}
+	}
+	for _, sub := range c.Dependencies() {
+		if !referenced[sub.Name()] {
+			subs[sub.Name()] = sub
+		}
+	}
+	return subs
+}
+
+// render renders the chart's templates locally with the given values, the
+// same way 'helm template' does without talking to a cluster.
+func (s *Show) render(vals map[string]any) (map[string]string, error) {
+	if vals == nil {
+		vals = map[string]any{}
+	}
+	if err := chartutil.ProcessDependencies(s.chart, vals); err != nil {
+		return nil, fmt.Errorf("chart dependencies processing failed: %w", err)
+	}
+	options := common.ReleaseOptions{
+		Name:      "release-name",
+		Namespace: "default",
+		Revision:  1,
+		IsInstall: true,
+	}
+	valuesToRender, err := util.ToRenderValues(s.chart, vals, options, common.DefaultCapabilities.Copy())
+	if err != nil {
+		return nil, err
+	}
+	var e engine.Engine
+	return e.Render(s.chart, valuesToRender)
+}
+
+// writeImages renders the chart and writes every distinct container image
+// referenced by an 'image' field in the manifests.
+func (s *Show) writeImages(out *strings.Builder) error {
+	files, err := s.render(s.Values)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for name, content := range files {
+		if strings.HasSuffix(name, notesFileSuffix) {
+			continue
+		}
+		for _, doc := range releaseutil.SplitManifests(content) {
+			var obj any
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				return fmt.Errorf("unable to parse manifest %s: %w", name, err)
+			}
+			collectImages(obj, seen)
+		}
+	}
+	images := slices.Sorted(maps.Keys(seen))
+
+	switch s.Encoding {
+	case "", ShowEncodingRaw:
+		for _, image := range images {
+			fmt.Fprintln(out, image)
+		}
+	case ShowEncodingJSON:
+		b, err := json.Marshal(images)
+		if err != nil {
+			return fmt.Errorf("unable to encode images as JSON: %w", err)
+		}
+		fmt.Fprintln(out, string(b))
+	case ShowEncodingYAML:
+		b, err := yaml.Marshal(images)
+		if err != nil {
+			return fmt.Errorf("unable to encode images as YAML: %w", err)
+		}
+		fmt.Fprint(out, string(b))
+	default:
+		return fmt.Errorf("invalid encoding %q, must be one of: %s", s.Encoding, strings.Join(ShowEncodings(), ", "))
+	}
+	return nil
+}
+
+// collectImages walks a decoded manifest and records the value of every
+// 'image' field holding a string.
+func collectImages(obj any, seen map[string]bool) {
+	switch v := obj.(type) {
+	case map[string]any:
+		for key, val := range v {
+			if image, ok := val.(string); ok && key == "image" {
+				if image = strings.TrimSpace(image); image != "" {
+					seen[image] = true
+				}
+				continue
+			}
+			collectImages(val, seen)
+		}
+	case []any:
+		for _, val := range v {
+			collectImages(val, seen)
+		}
+	}
+}
+
+// showAllDocument is the structured form of everything 'helm show all' prints.
+type showAllDocument struct {
+	Chart  *chart.Metadata `json:"chart"`
+	Values map[string]any  `json:"values"`
+	Readme string          `json:"readme"`
+	CRDs   []string        `json:"crds"`
+}
+
+func (s *Show) allDocument() *showAllDocument {
+	doc := &showAllDocument{
+		Chart:  s.chart.Metadata,
+		Values: s.chart.Values,
+		CRDs:   []string{},
+	}
+	if readme := findReadme(s.chart.Files); readme != nil {
+		doc.Readme = string(readme.Data)
+	}
+	for _, crd := range s.chart.CRDObjects() {
+		doc.CRDs = append(doc.CRDs, string(crd.File.Data))
+	}
+	return doc
+}
+
+// encodeAll returns everything 'helm show all' prints as a single JSON or
+// YAML object with 'chart', 'values', 'readme' and 'crds' keys.
+func (s *Show) encodeAll() (string, error) {
+	doc := s.allDocument()
+	switch s.Encoding {
+	case ShowEncodingJSON:
+		b, err := json.Marshal(doc)
+		if err != nil {
+			return "", fmt.Errorf("unable to encode chart as JSON: %w", err)
+		}
+		return string(b) + "\n", nil
+	case ShowEncodingYAML:
+		b, err := yaml.Marshal(doc)
+		if err != nil {
+			return "", fmt.Errorf("unable to encode chart as YAML: %w", err)
+		}
+		return string(b), nil
+	}
+	return "", fmt.Errorf("invalid encoding %q, must be one of: %s", s.Encoding, strings.Join(ShowEncodings(), ", "))
+}
+
+// runFilter evaluates the JSONPath or YAML path expression against the
+// document selected by the output format and returns the result.
+func (s *Show) runFilter() (string, error) {
+	if s.JSONPathTemplate != "" && s.YAMLPathTemplate != "" {
+		return "", errors.New("only one of --jsonpath and --yamlpath may be specified")
+	}
+
+	var doc any
+	switch s.OutputFormat {
+	case ShowValues:
+		if s.chart.Values == nil {
+			return "", nil
+		}
+		vals, err := s.values()
+		if err != nil {
+			return "", err
+		}
+		doc = vals
+	case ShowChart:
+		doc = s.chart.Metadata
+	case ShowAll:
+		doc = s.allDocument()
+	default:
+		return "", fmt.Errorf("filtering is not supported for %q output", s.OutputFormat)
+	}
+
+	// Round-trip through JSON so structs are addressed by their serialized
+	// field names, exactly as they are printed.
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	if s.JSONPathTemplate != "" {
+		printer, err := printers.NewJSONPathPrinter(s.JSONPathTemplate)
+		if err != nil {
+			return "", fmt.Errorf("error parsing jsonpath %s: %w", s.JSONPathTemplate, err)
+		}
+		if err := printer.JSONPath.Execute(&out, generic); err != nil {
+			return "", fmt.Errorf("error executing jsonpath %s: %w", s.JSONPathTemplate, err)
+		}
+		return out.String(), nil
+	}
+
+	result, err := lookupYAMLPath(generic, s.YAMLPathTemplate)
+	if err != nil {
+		return "", fmt.Errorf("error evaluating yamlpath %s: %w", s.YAMLPathTemplate, err)
+	}
+	switch v := result.(type) {
+	case map[string]any, []any:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		out.Write(b)
+	case float64:
+		fmt.Fprintln(&out, strconv.FormatFloat(v, 'f', -1, 64))
+	case nil:
+		fmt.Fprintln(&out, "null")
+	default:
+		fmt.Fprintln(&out, v)
+	}
+	return out.String(), nil
+}
+
+// lookupYAMLPath resolves a path such as '.a.b[0].c' or '.a["dotted.key"]'
+// against a document made of maps, slices and scalars.
+func lookupYAMLPath(doc any, path string) (any, error) {
+	cur := doc
+	rest := strings.TrimSpace(path)
+	for rest != "" {
+		var key string
+		index := -1
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key, rest = rest[:end], rest[end:]
+			if key == "" {
+				// A lone '.' selects the whole document
+				continue
+			}
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, errors.New("unterminated '['")
+			}
+			sel := rest[1:end]
+			rest = rest[end+1:]
+			if unquoted, err := strconv.Unquote(sel); err == nil {
+				key = unquoted
+			} else if i, err := strconv.Atoi(sel); err == nil && i >= 0 {
+				index = i
+			} else {
+				return nil, fmt.Errorf("invalid selector %q", sel)
+			}
+		default:
+			// Allow the leading '.' to be omitted
+			rest = "." + rest
+			continue
+		}
+
+		if index >= 0 {
+			list, ok := cur.([]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot index non-list with [%d]", index)
+			}
+			if index >= len(list) {
+				return nil, fmt.Errorf("index [%d] out of range", index)
+			}
+			cur = list[index]
+			continue
+		}
+
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot select key %q from non-map", key)
+		}
+		if cur, ok = m[key]; !ok {
+			return nil, fmt.Errorf("key %q not found", key)
+		}
+	}
+	return cur, nil
+}
+
+// addDependencyRows adds a row for each dependency declared by c. The version
+// each dependency resolved to is read from the chart's Chart.lock. Subcharts
+// that are vendored in the chart are descended into so the full tree is
+// shown, but nothing is ever downloaded.
+func addDependencyRows(table *uitable.Table, c *chart.Chart, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, dep := range c.Metadata.Dependencies {
+		name := dep.Name
+		if dep.Alias != "" {
+			name = fmt.Sprintf("%s (%s)", dep.Name, dep.Alias)
+		}
+		table.AddRow(indent+name, dep.Version, dep.Repository, dep.Condition, lockedVersion(c.Lock, dep))
+
+		for _, sub := range c.Dependencies() {
+			if sub.Name() == dep.Name && sub.Metadata != nil {
+				addDependencyRows(table, sub, depth+1)
+				break
+			}
+		}
+	}
+}
+
+// lockedVersion returns the version a dependency was resolved to in the given
+// lock, or an empty string when it has not been locked.
+func lockedVersion(lock *chart.Lock, dep *chart.Dependency) string {
+	if lock == nil {
+		return ""
+	}
+	for _, l := range lock.Dependencies {
+		if l.Name == dep.Name && l.Repository == dep.Repository {
+			return l.Version
+		}
+	}
+	return ""
+}
+
+// writeValues writes the chart's values to out using the configured encoding.
+func (s *Show) writeValues(out *strings.Builder) error {
+	switch s.Encoding {
+	case "", ShowEncodingRaw:
+		if err := s.writeRawValues(out, s.chart, ""); err != nil {
+			return err
+		}
+		// The profile follows the values it overrides
+		if s.Profile != "" {
+			name := path.Join(chartutil.ProfilesDir, s.Profile+".yaml")
+			for _, f := range s.chart.Files {
+				if f.Name != name {
+					continue
+				}
+				data := f.Data
+				if s.StripComments {
+					var err error
+					if data, err = stripYAMLComments(data); err != nil {
+						return fmt.Errorf("unable to strip comments from %s: %w", name, err)
+					}
+				}
+				fmt.Fprintf(out, "---\n# Source: %s\n%s\n", name, data)
+			}
+		}
+	case ShowEncodingJSON:
+		vals, err := s.values()
+		if err != nil {
+			return err
+		}
+		b, err := json.Marshal(vals)
+		if err != nil {
+			return fmt.Errorf("unable to encode values as JSON: %w", err)
+		}
+		fmt.Fprintln(out, string(b))
+	case ShowEncodingYAML:
+		vals, err := s.values()
+		if err != nil {
+			return err
+		}
+		b, err := yaml.Marshal(vals)
+		if err != nil {
+			return fmt.Errorf("unable to encode values as YAML: %w", err)
+		}
+		fmt.Fprint(out, string(b))
+	default:
+		return fmt.Errorf("invalid encoding %q, must be one of: %s", s.Encoding, strings.Join(ShowEncodings(), ", "))
+	}
+	return nil
+}
+
+// writeRawValues writes the values.yaml file of c to out. When subcharts are
+// included, their values files follow as separate documents, each headed by
+// the key their values live under in the parent.
+func (s *Show) writeRawValues(out *strings.Builder, c *chart.Chart, key string) error {
+	for _, f := range c.Raw {
+		if f.Name != chartutil.ValuesfileName {
+			continue
+		}
+		data := f.Data
+		if s.StripComments {
+			var err error
+			if data, err = stripYAMLComments(data); err != nil {
+				return fmt.Errorf("unable to strip comments from %s: %w", chartutil.ValuesfileName, err)
+			}
+		}
+		if key != "" {
+			fmt.Fprintf(out, "---\n# Source: %s\n", key)
+		}
+		fmt.Fprintln(out, string(data))
+	}
+
+	if !s.IncludeSubcharts {
+		return nil
+	}
+	subs := subchartsByKey(c)
+	for _, k := range slices.Sorted(maps.Keys(subs)) {
+		subKey := k
+		if key != "" {
+			subKey = key + "." + k
+		}
+		if err := s.writeRawValues(out, subs[k], subKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// values returns the chart's default values. When subcharts are included,
+// their default values are merged in under the key the parent refers to them
+// by, with the parent's values taking precedence.
+func (s *Show) values() (map[string]any, error) {
+	if !s.IncludeSubcharts {
+		return s.chart.Values, nil
+	}
+	return composeValues(s.chart)
+}
+
+func composeValues(c *chart.Chart) (map[string]any, error) {
+	v, err := copystructure.Copy(c.Values)
+	if err != nil {
+		return nil, err
+	}
+	vals, _ := v.(map[string]any)
+	if vals == nil {
+		vals = map[string]any{}
+	}
+
+	for key, sub := range subchartsByKey(c) {
+		subVals, err := composeValues(sub)
+		if err != nil {
+			return nil, err
+		}
+		// A parent overriding the subchart's values with a non-map wins outright
+		switch existing := vals[key].(type) {
+		case map[string]any:
+			vals[key] = util.MergeTables(existing, subVals)
+		case nil:
+			vals[key] = subVals
+		}
+	}
+	return vals, nil
+}
+
+// stripYAMLComments removes all comments from a YAML document while keeping
+// the original key order and formatting of the data.
+func stripYAMLComments(data []byte) ([]byte, error) {
+	var node yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	// An empty or comment-only document has nothing left to print
+	if node.Kind == 0 {
+		return nil, nil
+	}
+	clearYAMLComments(&node)
+
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func clearYAMLComments(n *yamlv3.Node) {
+	n.HeadComment = ""
+	n.LineComment = ""
+	n.FootComment = ""
+	for _, c := range n.Content {
+		clearYAMLComments(c)
+	}
+}
+
 func findReadme(files []*common.File) (file *common.File) {
+	return findFile(files, readmeFileNames)
+}
+
+// findFile returns the first of the given files whose name matches one of
+// names, ignoring case.
+func findFile(files []*common.File, names []string) *common.File {
 	for _, file := range files {
-		for _, n := range readmeFileNames {
+		for _, n := range names {
 			if file == nil {
 				continue
 			}
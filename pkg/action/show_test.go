@@ -74,6 +74,59 @@ baz
 	}
 }
 
+func TestShowAllEncoding(t *testing.T) {
+	modTime := time.Now()
+	newChart := func() *chart.Chart {
+		return &chart.Chart{
+			Metadata: &chart.Metadata{Name: "alpine", Version: "0.1.0"},
+			Files: []*common.File{
+				{Name: "README.md", ModTime: modTime, Data: []byte("README\n")},
+				{Name: "crds/foo.yaml", ModTime: modTime, Data: []byte("---\nfoo\n")},
+			},
+			Values: map[string]any{"replicas": 1},
+		}
+	}
+
+	t.Run("json", func(t *testing.T) {
+		client := NewShow(ShowAll, actionConfigFixture(t))
+		client.Encoding = ShowEncodingJSON
+		client.chart = newChart()
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		expect := `{"chart":{"name":"alpine","version":"0.1.0"},"values":{"replicas":1},"readme":"README\n","crds":["---\nfoo\n"]}` + "\n"
+		assert.Equal(t, expect, output)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		client := NewShow(ShowAll, actionConfigFixture(t))
+		client.Encoding = ShowEncodingYAML
+		client.chart = newChart()
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		expect := `chart:
+  name: alpine
+  version: 0.1.0
+crds:
+- |
+  ---
+  foo
+readme: |
+  README
+values:
+  replicas: 1
+`
+		assert.Equal(t, expect, output)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		client := NewShow(ShowAll, actionConfigFixture(t))
+		client.Encoding = "xml"
+		client.chart = newChart()
+		_, err := client.Run("")
+		assert.ErrorContains(t, err, "invalid encoding")
+	})
+}
+
 func TestShowNoValues(t *testing.T) {
 	config := actionConfigFixture(t)
 	client := NewShow(ShowAll, config)
@@ -106,6 +159,321 @@ func TestShowValuesByJsonPathFormat(t *testing.T) {
 	}
 }
 
+func TestShowFilters(t *testing.T) {
+	modTime := time.Now()
+	newChart := func() *chart.Chart {
+		c := buildChart(withSampleValues())
+		c.Metadata.AppVersion = "1.16.0"
+		c.Files = []*common.File{
+			{Name: "README.md", ModTime: modTime, Data: []byte("README\n")},
+			{Name: "crds/foo.yaml", ModTime: modTime, Data: []byte("foo\n")},
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		format   ShowOutputFormat
+		jsonpath string
+		yamlpath string
+		expect   string
+		wantErr  string
+	}{
+		{
+			name:     "jsonpath on chart",
+			format:   ShowChart,
+			jsonpath: "{.appVersion}",
+			expect:   "1.16.0",
+		},
+		{
+			name:     "jsonpath on all",
+			format:   ShowAll,
+			jsonpath: "{.values.nestedKey.simpleKey} {.readme} {.crds[0]}",
+			expect:   "simpleValue README\n foo\n",
+		},
+		{
+			name:     "yamlpath scalar on chart",
+			format:   ShowChart,
+			yamlpath: ".appVersion",
+			expect:   "1.16.0\n",
+		},
+		{
+			name:     "yamlpath map on values",
+			format:   ShowValues,
+			yamlpath: ".nestedKey.anotherNestedKey",
+			expect:   "yetAnotherNestedKey:\n  youReadyForAnotherNestedKey: \"No\"\n",
+		},
+		{
+			name:     "yamlpath on all",
+			format:   ShowAll,
+			yamlpath: `chart["name"]`,
+			expect:   "hello\n",
+		},
+		{
+			name:     "yamlpath missing key",
+			format:   ShowValues,
+			yamlpath: ".nope",
+			wantErr:  `key "nope" not found`,
+		},
+		{
+			name:     "both filters",
+			format:   ShowValues,
+			jsonpath: "{.someKey}",
+			yamlpath: ".someKey",
+			wantErr:  "only one of",
+		},
+		{
+			name:     "unsupported format",
+			format:   ShowReadme,
+			yamlpath: ".someKey",
+			wantErr:  "not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewShow(tt.format, actionConfigFixture(t))
+			client.JSONPathTemplate = tt.jsonpath
+			client.YAMLPathTemplate = tt.yamlpath
+			client.chart = newChart()
+
+			output, err := client.Run("")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, output)
+		})
+	}
+}
+
+func TestLookupYAMLPath(t *testing.T) {
+	doc := map[string]any{
+		"a": map[string]any{
+			"list":   []any{"x", map[string]any{"b": 1.5}},
+			"dot.ed": true,
+		},
+	}
+
+	tests := []struct {
+		path    string
+		expect  any
+		wantErr bool
+	}{
+		{path: ".", expect: doc},
+		{path: ".a.list[0]", expect: "x"},
+		{path: "a.list[1].b", expect: 1.5},
+		{path: `.a["dot.ed"]`, expect: true},
+		{path: ".a.list[2]", wantErr: true},
+		{path: ".a[0]", wantErr: true},
+		{path: ".a.list.b", wantErr: true},
+		{path: ".a[", wantErr: true},
+		{path: ".a[x]", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := lookupYAMLPath(doc, tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
+
+func TestShowEncoding(t *testing.T) {
+	modTime := time.Now()
+	raw := "# top comment\nb: 2 # trailing\n# about a\na:\n  z: true\n  y: [1, 2]\n"
+	values := map[string]any{
+		"b": 2,
+		"a": map[string]any{"z": true, "y": []any{1, 2}},
+	}
+
+	tests := []struct {
+		name          string
+		encoding      ShowEncoding
+		stripComments bool
+		expect        string
+		wantErr       bool
+	}{
+		{
+			name:   "default is raw",
+			expect: raw + "\n",
+		},
+		{
+			name:          "raw with stripped comments",
+			encoding:      ShowEncodingRaw,
+			stripComments: true,
+			expect:        "b: 2\na:\n  z: true\n  y: [1, 2]\n\n",
+		},
+		{
+			name:     "json",
+			encoding: ShowEncodingJSON,
+			expect:   "{\"a\":{\"y\":[1,2],\"z\":true},\"b\":2}\n",
+		},
+		{
+			name:     "yaml",
+			encoding: ShowEncodingYAML,
+			expect:   "a:\n  \"y\":\n  - 1\n  - 2\n  z: true\nb: 2\n",
+		},
+		{
+			name:     "invalid",
+			encoding: "toml",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := actionConfigFixture(t)
+			client := NewShow(ShowValues, config)
+			client.Encoding = tt.encoding
+			client.StripComments = tt.stripComments
+			client.chart = &chart.Chart{
+				Metadata: &chart.Metadata{Name: "alpine"},
+				Raw: []*common.File{
+					{Name: "values.yaml", ModTime: modTime, Data: []byte(raw)},
+				},
+				Values: values,
+			}
+
+			output, err := client.Run("")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, output)
+		})
+	}
+}
+
+func TestShowValuesIncludeSubcharts(t *testing.T) {
+	modTime := time.Now()
+	newChart := func() *chart.Chart {
+		leaf := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "leaf"},
+			Raw:      []*common.File{{Name: "values.yaml", ModTime: modTime, Data: []byte("size: 1\n")}},
+			Values:   map[string]any{"size": 1},
+		}
+		sub := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "sub"},
+			Raw:      []*common.File{{Name: "values.yaml", ModTime: modTime, Data: []byte("port: 80\nhost: a\n")}},
+			Values:   map[string]any{"port": 80, "host": "a"},
+		}
+		sub.AddDependency(leaf)
+		parent := &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name:         "parent",
+				Dependencies: []*chart.Dependency{{Name: "sub", Alias: "backend"}},
+			},
+			Raw:    []*common.File{{Name: "values.yaml", ModTime: modTime, Data: []byte("backend:\n  port: 8080\n")}},
+			Values: map[string]any{"backend": map[string]any{"port": 8080}},
+		}
+		parent.AddDependency(sub)
+		return parent
+	}
+
+	t.Run("merged yaml", func(t *testing.T) {
+		client := NewShow(ShowValues, actionConfigFixture(t))
+		client.IncludeSubcharts = true
+		client.Encoding = ShowEncodingYAML
+		client.chart = newChart()
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		assert.Equal(t, "backend:\n  host: a\n  leaf:\n    size: 1\n  port: 8080\n", output)
+		// The chart's own values must not be modified
+		assert.Equal(t, map[string]any{"backend": map[string]any{"port": 8080}}, client.chart.Values)
+	})
+
+	t.Run("raw documents", func(t *testing.T) {
+		client := NewShow(ShowValues, actionConfigFixture(t))
+		client.IncludeSubcharts = true
+		client.chart = newChart()
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		expect := "backend:\n  port: 8080\n\n" +
+			"---\n# Source: backend\nport: 80\nhost: a\n\n" +
+			"---\n# Source: backend.leaf\nsize: 1\n\n"
+		assert.Equal(t, expect, output)
+	})
+
+	t.Run("yamlpath into subchart", func(t *testing.T) {
+		client := NewShow(ShowValues, actionConfigFixture(t))
+		client.IncludeSubcharts = true
+		client.YAMLPathTemplate = ".backend.leaf.size"
+		client.chart = newChart()
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		assert.Equal(t, "1\n", output)
+	})
+}
+
+func TestShowValuesProfile(t *testing.T) {
+	modTime := time.Now()
+	newChart := func() *chart.Chart {
+		return &chart.Chart{
+			Metadata: &chart.Metadata{Name: "web"},
+			Raw:      []*common.File{{Name: "values.yaml", ModTime: modTime, Data: []byte("replicas: 1\ntag: latest\n")}},
+			Files:    []*common.File{{Name: "profiles/prod.yaml", ModTime: modTime, Data: []byte("# production\nreplicas: 3\n")}},
+			Values:   map[string]any{"replicas": 1, "tag": "latest"},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		profile       string
+		encoding      ShowEncoding
+		stripComments bool
+		expect        string
+		expectError   string
+	}{
+		{
+			name:    "raw documents",
+			profile: "prod",
+			expect:  "replicas: 1\ntag: latest\n\n---\n# Source: profiles/prod.yaml\n# production\nreplicas: 3\n\n",
+		},
+		{
+			name:          "raw documents with stripped comments",
+			profile:       "prod",
+			stripComments: true,
+			expect:        "replicas: 1\ntag: latest\n\n---\n# Source: profiles/prod.yaml\nreplicas: 3\n\n",
+		},
+		{
+			name:     "merged yaml",
+			profile:  "prod",
+			encoding: ShowEncodingYAML,
+			expect:   "replicas: 3\ntag: latest\n",
+		},
+		{
+			name:        "unknown profile",
+			profile:     "dev",
+			expectError: `profile "dev" not found in chart web, available profiles: prod`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewShow(ShowValues, actionConfigFixture(t))
+			client.Profile = tt.profile
+			client.Encoding = tt.encoding
+			client.StripComments = tt.stripComments
+			client.chart = newChart()
+			output, err := client.Run("")
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, output)
+		})
+	}
+}
+
 func TestShowCRDs(t *testing.T) {
 	config := actionConfigFixture(t)
 	client := NewShow(ShowCRDs, config)
@@ -140,6 +508,280 @@ baz
 	}
 }
 
+func TestShowDependencies(t *testing.T) {
+	config := actionConfigFixture(t)
+	client := NewShow(ShowDependencies, config)
+
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:    "sub",
+			Version: "1.2.3",
+			Dependencies: []*chart.Dependency{
+				{Name: "leaf", Version: "~0.1", Repository: "https://example.com/charts"},
+			},
+		},
+		Lock: &chart.Lock{
+			Dependencies: []*chart.Dependency{
+				{Name: "leaf", Version: "0.1.4", Repository: "https://example.com/charts"},
+			},
+		},
+	}
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "parent",
+			Dependencies: []*chart.Dependency{
+				{Name: "sub", Version: "^1.0.0", Repository: "https://example.com/charts", Condition: "sub.enabled"},
+				{Name: "other", Alias: "db", Version: "2.x", Repository: "oci://example.com/charts"},
+			},
+		},
+		Lock: &chart.Lock{
+			Dependencies: []*chart.Dependency{
+				{Name: "sub", Version: "1.2.3", Repository: "https://example.com/charts"},
+			},
+		},
+	}
+	parent.AddDependency(sub)
+	client.chart = parent
+
+	output, err := client.Run("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `NAME      	VERSION	REPOSITORY                	CONDITION  	RESOLVED
+sub       	^1.0.0 	https://example.com/charts	sub.enabled	1.2.3   
+  leaf    	~0.1   	https://example.com/charts	           	0.1.4   
+other (db)	2.x    	oci://example.com/charts  	           	        
+`
+	assert.Equal(t, expect, output)
+}
+
+func TestShowNoDependencies(t *testing.T) {
+	config := actionConfigFixture(t)
+	client := NewShow(ShowDependencies, config)
+	client.chart = &chart.Chart{Metadata: &chart.Metadata{Name: "alpine"}}
+
+	output, err := client.Run("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, output)
+}
+
+func TestShowSchema(t *testing.T) {
+	newChart := func() *chart.Chart {
+		sub := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "sub"},
+			Schema:   []byte(`{"type": "object", "properties": {"port": {"type": "integer"}}}`),
+		}
+		noSchema := &chart.Chart{Metadata: &chart.Metadata{Name: "plain"}}
+		parent := &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name: "parent",
+				Dependencies: []*chart.Dependency{
+					{Name: "sub", Alias: "backend"},
+					{Name: "plain"},
+				},
+			},
+			Schema: []byte("{\"type\": \"object\"}\n"),
+		}
+		parent.AddDependency(sub, noSchema)
+		return parent
+	}
+
+	t.Run("chart schema only", func(t *testing.T) {
+		client := NewShow(ShowSchema, actionConfigFixture(t))
+		client.chart = newChart()
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		assert.Equal(t, "{\"type\": \"object\"}\n", output)
+	})
+
+	t.Run("include subcharts", func(t *testing.T) {
+		client := NewShow(ShowSchema, actionConfigFixture(t))
+		client.IncludeSubcharts = true
+		client.chart = newChart()
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		expect := `{
+  "properties": {
+    "backend": {
+      "properties": {
+        "port": {
+          "type": "integer"
+        }
+      },
+      "type": "object"
+    }
+  },
+  "type": "object"
+}
+`
+		assert.Equal(t, expect, output)
+	})
+
+	t.Run("no schema", func(t *testing.T) {
+		client := NewShow(ShowSchema, actionConfigFixture(t))
+		client.IncludeSubcharts = true
+		client.chart = &chart.Chart{Metadata: &chart.Metadata{Name: "alpine"}}
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		assert.Empty(t, output)
+	})
+}
+
+func TestShowImages(t *testing.T) {
+	modTime := time.Now()
+	newChart := func() *chart.Chart {
+		return &chart.Chart{
+			Metadata: &chart.Metadata{Name: "alpine", Version: "0.1.0", APIVersion: chart.APIVersionV2},
+			Templates: []*common.File{
+				{Name: "templates/pod.yaml", ModTime: modTime, Data: []byte(`apiVersion: v1
+kind: Pod
+metadata:
+  name: {{ .Release.Name }}
+spec:
+  initContainers:
+  - name: init
+    image: busybox:1.36
+  containers:
+  - name: app
+    image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: other
+spec:
+  containers:
+  - name: app
+    image: busybox:1.36
+`)},
+				{Name: "templates/NOTES.txt", ModTime: modTime, Data: []byte("image: not-an-image\n")},
+			},
+			Values: map[string]any{
+				"image": map[string]any{"repository": "nginx", "tag": "1.25"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		encoding ShowEncoding
+		expect   string
+	}{
+		{name: "lines", expect: "busybox:1.36\nnginx:1.25\n"},
+		{name: "json", encoding: ShowEncodingJSON, expect: `["busybox:1.36","nginx:1.25"]` + "\n"},
+		{name: "yaml", encoding: ShowEncodingYAML, expect: "- busybox:1.36\n- nginx:1.25\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewShow(ShowImages, actionConfigFixture(t))
+			client.Encoding = tt.encoding
+			client.chart = newChart()
+			output, err := client.Run("")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, output)
+		})
+	}
+}
+
+func TestShowNotes(t *testing.T) {
+	modTime := time.Now()
+	newChart := func() *chart.Chart {
+		sub := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "sub", Version: "0.1.0", APIVersion: chart.APIVersionV2},
+			Templates: []*common.File{
+				{Name: "templates/NOTES.txt", ModTime: modTime, Data: []byte("subchart notes\n")},
+			},
+		}
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "alpine", Version: "0.1.0", APIVersion: chart.APIVersionV2},
+			Templates: []*common.File{
+				{Name: "templates/NOTES.txt", ModTime: modTime, Data: []byte("Visit http://{{ .Values.host }}/\n\n")},
+			},
+			Values: map[string]any{"host": "example.com"},
+		}
+		c.AddDependency(sub)
+		return c
+	}
+
+	t.Run("default values", func(t *testing.T) {
+		client := NewShow(ShowNotes, actionConfigFixture(t))
+		client.chart = newChart()
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		assert.Equal(t, "Visit http://example.com/\n", output)
+	})
+
+	t.Run("supplied values", func(t *testing.T) {
+		client := NewShow(ShowNotes, actionConfigFixture(t))
+		client.Values = map[string]any{"host": "helm.sh"}
+		client.chart = newChart()
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		assert.Equal(t, "Visit http://helm.sh/\n", output)
+	})
+
+	t.Run("no notes", func(t *testing.T) {
+		client := NewShow(ShowNotes, actionConfigFixture(t))
+		client.chart = &chart.Chart{Metadata: &chart.Metadata{Name: "alpine", Version: "0.1.0", APIVersion: chart.APIVersionV2}}
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		assert.Empty(t, output)
+	})
+}
+
+func TestShowLicenseAndFile(t *testing.T) {
+	modTime := time.Now()
+	newChart := func() *chart.Chart {
+		return &chart.Chart{
+			Metadata: &chart.Metadata{Name: "alpine"},
+			Raw: []*common.File{
+				{Name: "Chart.yaml", ModTime: modTime, Data: []byte("name: alpine\n")},
+				{Name: "LICENSE.txt", ModTime: modTime, Data: []byte("Apache License\n")},
+				{Name: "docs/usage.md", ModTime: modTime, Data: []byte("# Usage\n")},
+			},
+		}
+	}
+
+	t.Run("license", func(t *testing.T) {
+		client := NewShow(ShowLicense, actionConfigFixture(t))
+		client.chart = newChart()
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		assert.Equal(t, "Apache License\n", output)
+	})
+
+	t.Run("no license", func(t *testing.T) {
+		client := NewShow(ShowLicense, actionConfigFixture(t))
+		client.chart = &chart.Chart{Metadata: &chart.Metadata{Name: "alpine"}}
+		output, err := client.Run("")
+		assert.NoError(t, err)
+		assert.Empty(t, output)
+	})
+
+	for _, p := range []string{"docs/usage.md", "./docs/usage.md", "/docs/../docs/usage.md"} {
+		t.Run("file "+p, func(t *testing.T) {
+			client := NewShow(ShowFile, actionConfigFixture(t))
+			client.FilePath = p
+			client.chart = newChart()
+			output, err := client.Run("")
+			assert.NoError(t, err)
+			assert.Equal(t, "# Usage\n", output)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		client := NewShow(ShowFile, actionConfigFixture(t))
+		client.FilePath = "docs/missing.md"
+		client.chart = newChart()
+		_, err := client.Run("")
+		assert.ErrorContains(t, err, `file "docs/missing.md" not found in chart "alpine"`)
+	})
+}
+
 func TestShowNoReadme(t *testing.T) {
 	config := actionConfigFixture(t)
 	client := NewShow(ShowAll, config)
@@ -18,9 +18,12 @@ package action
 
 import (
 	"bytes"
+	"context"
+	"errors"
 
 	"helm.sh/helm/v4/pkg/kube"
 	ri "helm.sh/helm/v4/pkg/release"
+	release "helm.sh/helm/v4/pkg/release/v1"
 )
 
 // Status is the action for checking the deployment status of releases.
@@ -34,6 +37,10 @@ type Status struct {
 	// ShowResourcesTable is used with ShowResources. When true this will cause
 	// the resulting objects to be retrieved as a kind=table.
 	ShowResourcesTable bool
+
+	// ShowHealth computes the health of the resources of the release from
+	// their live state and sets it in the info of the returned release.
+	ShowHealth bool
 }
 
 // NewStatus creates a new Status object with the given configuration.
@@ -79,5 +86,71 @@ func (s *Status) Run(name string) (ri.Releaser, error) {
 
 	rel.Info.Resources = resp
 
+	if s.ShowHealth {
+		rel.Info.Health, err = s.health(rel)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return rel, nil
 }
+
+// health returns the health of the resources of rel, computed from their live
+// state.
+func (s *Status) health(rel *release.Release) ([]release.ResourceHealth, error) {
+	checker, ok := s.cfg.KubeClient.(kube.InterfaceHealth)
+	if !ok {
+		return nil, errors.New("computing the health of resources is not supported by the Kubernetes client")
+	}
+	resources, err := s.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return nil, err
+	}
+	health, err := checker.Health(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]release.ResourceHealth, 0, len(health))
+	for _, rh := range health {
+		var conditions []release.ResourceCondition
+		for _, c := range rh.Conditions {
+			conditions = append(conditions, release.ResourceCondition(c))
+		}
+		result = append(result, release.ResourceHealth{
+			Kind:       rh.Kind,
+			Namespace:  rh.Namespace,
+			Name:       rh.Name,
+			Health:     rh.Health,
+			Message:    rh.Message,
+			Conditions: conditions,
+		})
+	}
+	return result, nil
+}
+
+// Watch watches the resources of the given release and calls fn with the
+// status of a resource every time it changes, until ctx is done. It gives a
+// live view of the readiness of exactly the objects of the release.
+func (s *Status) Watch(ctx context.Context, name string, fn func(kube.ResourceStatus)) error {
+	watcher, ok := s.cfg.KubeClient.(kube.InterfaceWatchStatus)
+	if !ok {
+		return errors.New("watching the status of resources is not supported by the Kubernetes client")
+	}
+
+	reli, err := s.cfg.releaseContent(name, s.Version)
+	if err != nil {
+		return err
+	}
+	rel, err := releaserToV1Release(reli)
+	if err != nil {
+		return err
+	}
+
+	resources, err := s.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return err
+	}
+	return watcher.WatchStatus(ctx, resources, fn)
+}
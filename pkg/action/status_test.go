@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
 	rcommon "helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
@@ -141,3 +142,75 @@ metadata:
   namespace: default
   name: test-application
 `
+
+func TestStatusWatch(t *testing.T) {
+	config := actionConfigFixture(t)
+	failingKubeClient := kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}, BuildDummy: true}
+	config.KubeClient = &failingKubeClient
+	client := NewStatus(config)
+
+	releaseName := "test-release"
+	require.NoError(t, configureReleaseContent(config, releaseName))
+
+	var statuses []kube.ResourceStatus
+	err := client.Watch(t.Context(), releaseName, func(rs kube.ResourceStatus) {
+		statuses = append(statuses, rs)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []kube.ResourceStatus{{
+		Namespace: "dummyNamespace",
+		Name:      "dummyName",
+		Status:    "Current",
+		Message:   "Resource is current",
+	}}, statuses)
+
+	failingKubeClient.WatchStatusError = errors.New("watch error")
+	err = client.Watch(t.Context(), releaseName, func(kube.ResourceStatus) {})
+	assert.ErrorContains(t, err, "watch error")
+
+	err = client.Watch(t.Context(), "missing", func(kube.ResourceStatus) {})
+	assert.Error(t, err)
+}
+
+func TestStatusRun_ShowHealth(t *testing.T) {
+	config := actionConfigFixture(t)
+	failingKubeClient := kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}
+	failingKubeClient.ResourceHealth = []kube.ResourceHealth{{
+		Kind:      "Job",
+		Namespace: "default",
+		Name:      "migrate",
+		Health:    kube.HealthDegraded,
+		Message:   "Job Failed. failed: 1/1",
+		Conditions: []kube.ResourceCondition{{
+			Type:   "Failed",
+			Status: "True",
+			Reason: "BackoffLimitExceeded",
+		}},
+	}}
+	config.KubeClient = &failingKubeClient
+	client := NewStatus(config)
+	client.ShowHealth = true
+
+	releaseName := "test-release"
+	require.NoError(t, configureReleaseContent(config, releaseName))
+	releaser, err := client.Run(releaseName)
+	require.NoError(t, err)
+	result, err := releaserToV1Release(releaser)
+	require.NoError(t, err)
+	assert.Equal(t, []release.ResourceHealth{{
+		Kind:      "Job",
+		Namespace: "default",
+		Name:      "migrate",
+		Health:    "Degraded",
+		Message:   "Job Failed. failed: 1/1",
+		Conditions: []release.ResourceCondition{{
+			Type:   "Failed",
+			Status: "True",
+			Reason: "BackoffLimitExceeded",
+		}},
+	}}, result.Info.Health)
+
+	failingKubeClient.HealthError = errors.New("health error")
+	_, err = client.Run(releaseName)
+	assert.ErrorContains(t, err, "health error")
+}
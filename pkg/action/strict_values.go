@@ -0,0 +1,176 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/engine"
+)
+
+// ErrUnknownValues is returned in strict values mode when user supplied values
+// contain keys that no template of the chart references.
+var ErrUnknownValues = errors.New("values contain keys not used by the chart")
+
+// knownValues is the set of values paths a chart uses. A path covers the keys
+// below it, as templates like `toYaml .Values.resources` use a whole subtree.
+type knownValues map[string]bool
+
+func (k knownValues) add(path []string) {
+	k[strings.Join(path, ".")] = true
+}
+
+// known returns whether the values key at path is used: either the key or one
+// of its parents is covered, or a key below it is.
+func (k knownValues) known(path []string) bool {
+	for n := 0; n <= len(path); n++ {
+		if k[strings.Join(path[:n], ".")] {
+			return true
+		}
+	}
+	prefix := strings.Join(path, ".") + "."
+	for p := range k {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// covered returns whether the key at path and all keys below it are used.
+func (k knownValues) covered(path []string) bool {
+	for n := 0; n <= len(path); n++ {
+		if k[strings.Join(path[:n], ".")] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUnknownValues returns an error listing the keys of the user supplied
+// values that are neither referenced by a template of the chart or its
+// subcharts, nor declared in a values.schema.json. It must be called after the
+// dependencies of the chart have been processed.
+func checkUnknownValues(chrt *chart.Chart, vals map[string]any) error {
+	refs, err := engine.ValuesReferences(chrt)
+	if err != nil {
+		return err
+	}
+	known := knownValues{}
+	for _, paths := range refs {
+		for _, p := range paths {
+			known.add(p)
+		}
+	}
+	if err := collectKnownValues(chrt, nil, known); err != nil {
+		return err
+	}
+
+	var unknown []string
+	var walk func(path []string, v map[string]any)
+	walk = func(path []string, v map[string]any) {
+		for key, val := range v {
+			p := append(slices.Clone(path), key)
+			if !known.known(p) {
+				unknown = append(unknown, strings.Join(p, "."))
+				continue
+			}
+			if sub, ok := val.(map[string]any); ok && !known.covered(p) {
+				walk(p, sub)
+			}
+		}
+	}
+	walk(nil, vals)
+
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("%w:\n  %s", ErrUnknownValues, strings.Join(unknown, "\n  "))
+}
+
+// collectKnownValues adds the values paths declared by the schema and the
+// dependencies of a chart, whose values are found at prefix, and of its
+// subcharts.
+func collectKnownValues(chrt *chart.Chart, prefix []string, known knownValues) error {
+	if len(chrt.Schema) > 0 {
+		var schema map[string]any
+		if err := json.Unmarshal(chrt.Schema, &schema); err != nil {
+			return fmt.Errorf("unable to parse values schema of chart %s: %w", chrt.Name(), err)
+		}
+		schemaValues(schema, prefix, known)
+	}
+
+	loaded := map[string]*chart.Chart{}
+	for _, dep := range chrt.Dependencies() {
+		loaded[dep.Name()] = dep
+	}
+	if chrt.Metadata != nil {
+		for _, dep := range chrt.Metadata.Dependencies {
+			name := dep.Name
+			if dep.Alias != "" {
+				name = dep.Alias
+			}
+			for _, c := range strings.Split(dep.Condition, ",") {
+				if c = strings.TrimSpace(c); c != "" {
+					known.add(append(slices.Clone(prefix), strings.Split(c, ".")...))
+				}
+			}
+			if len(dep.Tags) > 0 {
+				known.add([]string{"tags"})
+			}
+			// The values of a disabled subchart are not checked
+			if _, ok := loaded[name]; !ok {
+				known.add(append(slices.Clone(prefix), name))
+			}
+		}
+	}
+
+	for name, dep := range loaded {
+		if err := collectKnownValues(dep, append(slices.Clone(prefix), name), known); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaValues adds the values paths declared by the properties of a JSON
+// schema. An object without declared properties, or allowing additional
+// properties explicitly, covers all the keys below it.
+func schemaValues(schema map[string]any, path []string, known knownValues) {
+	props, _ := schema["properties"].(map[string]any)
+	if len(props) == 0 || schema["additionalProperties"] == true {
+		if len(path) > 0 {
+			known.add(path)
+		}
+		return
+	}
+	for key, prop := range props {
+		p := append(slices.Clone(path), key)
+		if sub, ok := prop.(map[string]any); ok {
+			schemaValues(sub, p, known)
+		} else {
+			known.add(p)
+		}
+	}
+}
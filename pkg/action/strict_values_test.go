@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func strictValuesChart() *chart.Chart {
+	ch := buildChartWithTemplates([]*common.File{
+		{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "hello.image" }}{{ .Values.image.repository }}:{{ index .Values "image" "tag" }}{{ end }}`)},
+		{Name: "templates/deployment.yaml", Data: []byte(`replicas: {{ .Values.replicaCount }}
+image: {{ include "hello.image" . }}
+{{- with .Values.resources }}
+resources: {{ toYaml . }}
+{{- end }}
+{{- if $.Values.global.debug }}
+debug: true
+{{- end }}`)},
+	})
+	ch.AddDependency(buildChartWithTemplates([]*common.File{
+		{Name: "templates/service.yaml", Data: []byte(`port: {{ .Values.port }}`)},
+	}, withName("sub")))
+	ch.Schema = []byte(`{"properties": {"extra": {"type": "object"}, "service": {"properties": {"port": {"type": "integer"}}}}}`)
+	return ch
+}
+
+func TestCheckUnknownValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		vals    map[string]any
+		wantErr string
+	}{
+		{
+			name: "referenced values",
+			vals: map[string]any{
+				"replicaCount": 3,
+				"image":        map[string]any{"repository": "nginx", "tag": "1.0"},
+				"resources":    map[string]any{"limits": map[string]any{"cpu": "1"}},
+				"global":       map[string]any{"debug": true},
+			},
+		},
+		{
+			name: "values declared in the schema",
+			vals: map[string]any{
+				"extra":   map[string]any{"anything": "goes"},
+				"service": map[string]any{"port": 80},
+			},
+		},
+		{
+			name:    "typo",
+			vals:    map[string]any{"replicaCont": 3, "image": map[string]any{"tga": "1.0"}},
+			wantErr: "values contain keys not used by the chart:\n  image.tga\n  replicaCont",
+		},
+		{
+			name:    "key not declared in the schema",
+			vals:    map[string]any{"service": map[string]any{"type": "ClusterIP"}},
+			wantErr: "service.type",
+		},
+		{
+			name:    "subchart value",
+			vals:    map[string]any{"sub": map[string]any{"port": 80, "unknown": true}},
+			wantErr: "sub.unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkUnknownValues(strictValuesChart(), tt.vals)
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, ErrUnknownValues)
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestInstallRelease_StrictValues(t *testing.T) {
+	instAction := installAction(t)
+	instAction.StrictValues = true
+	_, err := instAction.Run(buildChart(withSampleTemplates()), map[string]any{"replicaCont": 3})
+	assert.ErrorIs(t, err, ErrUnknownValues)
+}
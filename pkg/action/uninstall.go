@@ -17,6 +17,7 @@ limitations under the License.
 package action
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -24,6 +25,7 @@ import (
 	"time"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
 
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/kube"
@@ -47,8 +49,18 @@ type Uninstall struct {
 	WaitStrategy        kube.WaitStrategy
 	WaitOptions         []kube.WaitOption
 	DeletionPropagation string
-	Timeout             time.Duration
-	Description         string
+	// KindDeletionPropagation overrides DeletionPropagation for the resources
+	// of the given kinds, e.g. {"StatefulSet": "orphan"}.
+	KindDeletionPropagation map[string]string
+	// DeleteVolumeClaims deletes the PersistentVolumeClaims created from the
+	// volume claim templates of the uninstalled StatefulSets.
+	DeleteVolumeClaims bool
+	// ForceProtected uninstalls releases protected against uninstall, and
+	// releases with resources annotated with the protected resource policy.
+	ForceProtected bool
+	Timeout        time.Duration
+	LockTimeout    time.Duration
+	Description    string
 }
 
 // NewUninstall creates a new Uninstall object with the given configuration.
@@ -63,6 +75,11 @@ func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error)
 	if err := u.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
+	if len(u.KindDeletionPropagation) > 0 || u.DeleteVolumeClaims {
+		if _, ok := u.cfg.KubeClient.(kube.InterfaceDeleteWithOptions); !ok {
+			return nil, errDeleteWithOptionsUnsupported
+		}
+	}
 
 	var waiter kube.Waiter
 	var err error
@@ -75,6 +92,14 @@ func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error)
 		return nil, err
 	}
 
+	if !u.DryRun {
+		_, unlock, err := u.cfg.Releases.Lock(context.Background(), name, "uninstall", u.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
 	if u.DryRun {
 		ri, err := u.cfg.releaseContent(name, 0)
 
@@ -88,8 +113,12 @@ func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error)
 		if err != nil {
 			return nil, err
 		}
+		if err := u.checkProtection(r); err != nil {
+			return nil, err
+		}
 
 		// Verify ownership in dry-run mode to show what would actually be deleted
+		var planned []releasei.UninstallResource
 		manifests := releaseutil.SplitManifests(r.Manifest)
 		_, files, err := releaseutil.SortManifests(manifests, nil, releaseutil.UninstallOrder)
 		if err == nil {
@@ -113,6 +142,7 @@ func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error)
 								"kind", info.Mapping.GroupVersionKind.Kind,
 								"name", info.Name,
 								"namespace", info.Namespace)
+							planned = append(planned, plannedResource(info, releasei.UninstallSkip, "", "not owned by this release"))
 						}
 					}
 
@@ -126,6 +156,7 @@ func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error)
 								"name", ur.Info.Name,
 								"namespace", ur.Info.Namespace,
 								"error", ur.Err)
+							planned = append(planned, plannedResource(ur.Info, releasei.UninstallSkip, "", fmt.Sprintf("ownership could not be verified: %s", ur.Err)))
 						}
 					}
 
@@ -138,6 +169,8 @@ func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error)
 								"kind", info.Mapping.GroupVersionKind.Kind,
 								"name", info.Name,
 								"namespace", info.Namespace)
+							cascade := u.deletionPropagationFor(info.Mapping.GroupVersionKind.Kind)
+							planned = append(planned, plannedResource(info, releasei.UninstallDelete, string(cascade), ""))
 						}
 					}
 				}
@@ -149,13 +182,20 @@ func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error)
 				kept.WriteString("These resources were kept due to the resource policy:\n")
 				for _, f := range filesToKeep {
 					fmt.Fprintf(&kept, "[%s] %s\n", f.Head.Kind, f.Head.Metadata.Name)
+					planned = append(planned, releasei.UninstallResource{
+						Kind:      f.Head.Kind,
+						Name:      f.Head.Metadata.Name,
+						Namespace: r.Namespace,
+						Action:    releasei.UninstallKeep,
+						Reason:    "resource policy " + kube.KeepPolicy,
+					})
 				}
-				res := &releasei.UninstallReleaseResponse{Release: r, Info: kept.String()}
+				res := &releasei.UninstallReleaseResponse{Release: r, Info: kept.String(), Resources: planned}
 				return res, nil
 			}
 		}
 
-		return &releasei.UninstallReleaseResponse{Release: r}, nil
+		return &releasei.UninstallReleaseResponse{Release: r, Resources: planned}, nil
 	}
 
 	if err := chartutil.ValidateReleaseName(name); err != nil {
@@ -192,6 +232,9 @@ func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error)
 		}
 		return nil, fmt.Errorf("the release named %q is already deleted", name)
 	}
+	if err := u.checkProtection(rel); err != nil {
+		return nil, err
+	}
 
 	u.cfg.Logger().Debug("uninstall: deleting release", "name", name)
 	rel.Info.Status = common.StatusUninstalling
@@ -284,6 +327,32 @@ func (u *Uninstall) Run(name string) (*releasei.UninstallReleaseResponse, error)
 	return res, nil
 }
 
+// checkProtection returns an error if the release, or one of its resources,
+// is protected against uninstall and the protection is not overridden.
+func (u *Uninstall) checkProtection(rel *release.Release) error {
+	if u.ForceProtected {
+		return nil
+	}
+	if isProtected(rel) {
+		return fmt.Errorf("uninstall: release %q is protected against uninstall", rel.Name)
+	}
+
+	// Corrupted release records are reported when deleting the resources.
+	_, files, err := releaseutil.SortManifests(releaseutil.SplitManifests(rel.Manifest), nil, releaseutil.UninstallOrder)
+	if err != nil {
+		return nil
+	}
+	protected := filterProtectedManifests(files)
+	if len(protected) == 0 {
+		return nil
+	}
+	resources := make([]string, 0, len(protected))
+	for _, m := range protected {
+		resources = append(resources, fmt.Sprintf("[%s] %s", m.Head.Kind, m.Head.Metadata.Name))
+	}
+	return fmt.Errorf("uninstall: release %q has resources protected against uninstall: %s", rel.Name, strings.Join(resources, ", "))
+}
+
 func (u *Uninstall) purgeReleases(rels ...*release.Release) error {
 	for _, rel := range rels {
 		if _, err := u.cfg.Releases.Delete(rel.Name, rel.Version); err != nil {
@@ -405,12 +474,59 @@ func (u *Uninstall) deleteRelease(rel *release.Release) (kube.ResourceList, stri
 					"namespace", info.Namespace,
 					"release", rel.Name)
 			}
-			_, errs = u.cfg.KubeClient.Delete(ownedResources, parseCascadingFlag(u.DeletionPropagation, u.cfg.Logger()))
+			_, errs = u.deleteResources(ownedResources)
 		}
 	}
 	return ownedResources, kept.String(), errs
 }
 
+var errDeleteWithOptionsUnsupported = errors.New("the kube client does not support per-kind deletion propagation or volume claim deletion")
+
+// deleteResources deletes the resources with the deletion propagation
+// policies and the volume claim handling of the uninstall.
+func (u *Uninstall) deleteResources(resources kube.ResourceList) (*kube.Result, []error) {
+	policy := parseCascadingFlag(u.DeletionPropagation, u.cfg.Logger())
+	if len(u.KindDeletionPropagation) == 0 && !u.DeleteVolumeClaims {
+		return u.cfg.KubeClient.Delete(resources, policy)
+	}
+
+	c, ok := u.cfg.KubeClient.(kube.InterfaceDeleteWithOptions)
+	if !ok {
+		return nil, []error{errDeleteWithOptionsUnsupported}
+	}
+	options := []kube.ClientDeleteOption{
+		kube.ClientDeleteOptionPropagationPolicy(policy),
+		kube.ClientDeleteOptionVolumeClaims(u.DeleteVolumeClaims),
+	}
+	for kind, kindPolicy := range u.KindDeletionPropagation {
+		options = append(options, kube.ClientDeleteOptionKindPropagationPolicy(kind, v1.DeletionPropagation(kindPolicy)))
+	}
+	return c.DeleteWithOptions(resources, options...)
+}
+
+// deletionPropagationFor returns the deletion propagation policy of the
+// resources of a kind.
+func (u *Uninstall) deletionPropagationFor(kind string) v1.DeletionPropagation {
+	for k, policy := range u.KindDeletionPropagation {
+		if strings.EqualFold(k, kind) {
+			return v1.DeletionPropagation(policy)
+		}
+	}
+	return parseCascadingFlag(u.DeletionPropagation, u.cfg.Logger())
+}
+
+// plannedResource describes what a dry-run uninstall would do with a resource.
+func plannedResource(info *resource.Info, action releasei.UninstallResourceAction, cascade, reason string) releasei.UninstallResource {
+	return releasei.UninstallResource{
+		Kind:      info.Mapping.GroupVersionKind.Kind,
+		Name:      info.Name,
+		Namespace: info.Namespace,
+		Action:    action,
+		Cascade:   cascade,
+		Reason:    reason,
+	}
+}
+
 func parseCascadingFlag(cascadingFlag string, logger *slog.Logger) v1.DeletionPropagation {
 	switch cascadingFlag {
 	case "orphan":
@@ -28,6 +28,7 @@ import (
 
 	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	releasei "helm.sh/helm/v4/pkg/release"
 	"helm.sh/helm/v4/pkg/release/common"
 )
 
@@ -164,6 +165,81 @@ func TestUninstallRelease_Cascade(t *testing.T) {
 	is.Contains(err.Error(), "failed to delete release: come-fail-away")
 }
 
+func TestUninstallRelease_ProtectedResources(t *testing.T) {
+	unAction := uninstallAction(t)
+	unAction.DisableHooks = true
+
+	rel := releaseStub()
+	rel.Name = "protected-secret"
+	rel.Manifest = `{
+		"apiVersion": "v1",
+		"kind": "Secret",
+		"metadata": {
+		  "name": "secret",
+		  "annotations": {
+			"helm.sh/resource-policy": "protected"
+		  }
+		},
+		"type": "Opaque"
+	}`
+	require.NoError(t, unAction.cfg.Releases.Create(rel))
+
+	_, err := unAction.Run(rel.Name)
+	require.ErrorContains(t, err, `release "protected-secret" has resources protected against uninstall: [Secret] secret`)
+
+	unAction.ForceProtected = true
+	res, err := unAction.Run(rel.Name)
+	require.NoError(t, err)
+	assert.NotContains(t, res.Info, "kept due to the resource policy")
+}
+
+// deleteOnlyKubeClient is a kube client which does not support deleting with
+// options.
+type deleteOnlyKubeClient struct {
+	kube.Interface
+}
+
+func TestUninstallRelease_CascadeFor(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		wrap      func(kube.Interface) kube.Interface
+		wantError string
+	}{
+		{
+			name: "deletes with options",
+			wrap: func(c kube.Interface) kube.Interface { return c },
+		},
+		{
+			name:      "kube client without options support",
+			wrap:      func(c kube.Interface) kube.Interface { return deleteOnlyKubeClient{c} },
+			wantError: "does not support per-kind deletion propagation",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			unAction := uninstallAction(t)
+			unAction.DisableHooks = true
+			unAction.WaitStrategy = kube.HookOnlyStrategy
+			unAction.KindDeletionPropagation = map[string]string{"StatefulSet": "orphan"}
+			unAction.DeleteVolumeClaims = true
+
+			rel := releaseStub()
+			rel.Name = "cascade-for"
+			require.NoError(t, unAction.cfg.Releases.Create(rel))
+
+			failer := unAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+			failer.DummyResources = kube.ResourceList{newDeploymentResource("web", "", "")}
+			unAction.cfg.KubeClient = tc.wrap(failer)
+
+			_, err := unAction.Run(rel.Name)
+			if tc.wantError != "" {
+				require.ErrorContains(t, err, tc.wantError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
 func TestUninstallRun_UnreachableKubeClient(t *testing.T) {
 	t.Helper()
 	config := actionConfigFixture(t)
@@ -352,3 +428,46 @@ data:
 	is.Contains(logOutput, "dryrun-unowned-deploy")
 	is.Contains(logOutput, "Deployment")
 }
+
+func TestUninstallRelease_DryRun_Resources(t *testing.T) {
+	config := actionConfigFixture(t)
+	unAction := NewUninstall(config)
+	unAction.DisableHooks = true
+	unAction.DryRun = true
+	unAction.KindDeletionPropagation = map[string]string{"deployment": "orphan"}
+
+	rel := releaseStub()
+	rel.Name = "dryrun-resources"
+	rel.Namespace = "default"
+	rel.Manifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kept-configmap
+  annotations:
+    helm.sh/resource-policy: keep
+data:
+  key: value`
+	require.NoError(t, config.Releases.Create(rel))
+
+	owner := map[string]string{
+		"meta.helm.sh/release-name":      "dryrun-resources",
+		"meta.helm.sh/release-namespace": "default",
+	}
+	failer := config.KubeClient.(*kubefake.FailingKubeClient)
+	failer.DummyResources = kube.ResourceList{
+		newDeploymentWithOwner("owned", "default", map[string]string{"app.kubernetes.io/managed-by": "Helm"}, owner),
+		newDeploymentWithOwner("unowned", "default", nil, nil),
+	}
+
+	res, err := unAction.Run(rel.Name)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []releasei.UninstallResource{
+		{Kind: "Deployment", Name: "owned", Namespace: "default", Action: releasei.UninstallDelete, Cascade: "orphan"},
+		{Kind: "Deployment", Name: "unowned", Namespace: "default", Action: releasei.UninstallSkip, Reason: "not owned by this release"},
+		{Kind: "ConfigMap", Name: "kept-configmap", Namespace: "default", Action: releasei.UninstallKeep, Reason: "resource policy keep"},
+	}, res.Resources)
+
+	// Nothing was uninstalled.
+	_, err = config.Releases.Last(rel.Name)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"os"
+
+	"helm.sh/helm/v4/pkg/chart/v2/unittest"
+)
+
+// UnitTest is the action for running the unit tests of a chart client-side.
+//
+// It provides the implementation of 'helm test --unit'.
+type UnitTest struct {
+	// UpdateSnapshots rewrites the snapshots that do not match instead of
+	// failing.
+	UpdateSnapshots bool
+}
+
+// NewUnitTest creates a new UnitTest object.
+func NewUnitTest() *UnitTest {
+	return &UnitTest{}
+}
+
+// Run runs the unit tests of the chart in the given directory, starting from
+// the given values.
+func (u *UnitTest) Run(chartDir string, vals map[string]any) ([]unittest.SuiteResult, error) {
+	fi, err := os.Stat(chartDir)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not a chart directory: unit tests run against unpacked charts", chartDir)
+	}
+	return unittest.Run(chartDir, unittest.Options{
+		UpdateSnapshots: u.UpdateSnapshots,
+		Values:          vals,
+	})
+}
@@ -21,14 +21,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
 
+	"helm.sh/helm/v4/internal/diff"
 	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/common/util"
@@ -41,6 +46,8 @@ import (
 	rcommon "helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
 	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+	"helm.sh/helm/v4/pkg/secrets"
+	"helm.sh/helm/v4/pkg/storage"
 	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
@@ -70,6 +77,15 @@ type Upgrade struct {
 	SkipCRDs bool
 	// Timeout is the timeout for this operation
 	Timeout time.Duration
+	// LockTimeout is how long to wait for another operation on the release to
+	// release its lock. Zero fails immediately if the release is locked.
+	LockTimeout time.Duration
+	// APIDeprecationCheck checks the rendered manifests for Kubernetes APIs
+	// that are deprecated or removed in the version of the target cluster
+	APIDeprecationCheck APIDeprecationCheck
+	// TimeoutPerKind overrides Timeout when waiting for resources of the
+	// given kinds, for example to give StatefulSets more time than ConfigMaps.
+	TimeoutPerKind map[string]time.Duration
 	// WaitStrategy determines what type of waiting should be done
 	WaitStrategy kube.WaitStrategy
 	// WaitOptions are additional options for waiting on resources
@@ -80,6 +96,20 @@ type Upgrade struct {
 	DisableHooks bool
 	// DryRunStrategy can be set to prepare, but not execute the operation and whether or not to interact with the remote cluster
 	DryRunStrategy DryRunStrategy
+	// Prune deletes the resources that belong to the release but are no longer
+	// rendered by the chart, including resources left behind by hooks
+	Prune bool
+	// PruneAllowlist limits pruning to resources of the given kinds.
+	// DefaultPruneAllowlist is used when empty.
+	PruneAllowlist []schema.GroupVersionKind
+	// RolloutStrategy controls how the resources of the upgrade are applied
+	RolloutStrategy RolloutStrategy
+	// RolloutSteps are the percentages of replicas updated at each step of a
+	// canary rollout. DefaultRolloutSteps is used when empty.
+	RolloutSteps []int
+	// Preflight checks that the additional resources requested by the upgrade
+	// fit into the namespace quotas and the cluster nodes before upgrading
+	Preflight PreflightMode
 	// HideSecret can be set to true when DryRun is enabled in order to hide
 	// Kubernetes Secrets in the output. It cannot be used outside of DryRun.
 	HideSecret bool
@@ -101,8 +131,14 @@ type Upgrade struct {
 	ReuseValues bool
 	// ResetThenReuseValues will reset the values to the chart's built-ins then merge with user's last supplied values.
 	ResetThenReuseValues bool
+	// ReuseValuesStrategy controls how the user's last supplied values are merged
+	// with the new values when ReuseValues or ResetThenReuseValues is set.
+	ReuseValuesStrategy ReuseValuesStrategy
 	// MaxHistory limits the maximum number of revisions saved per release
 	MaxHistory int
+	// HistoryRetention deletes the revisions of the release it does not keep,
+	// after MaxHistory is applied
+	HistoryRetention storage.RetentionPolicy
 	// RollbackOnFailure enables rolling back the upgraded release on failure
 	RollbackOnFailure bool
 	// CleanupOnFail will, if true, cause the upgrade to delete newly-created resources on a failed update.
@@ -113,13 +149,32 @@ type Upgrade struct {
 	HideNotes bool
 	// SkipSchemaValidation determines if JSON schema validation is disabled.
 	SkipSchemaValidation bool
+	// StrictValues fails the upgrade when the values contain keys that no
+	// template of the chart references.
+	StrictValues bool
 	// Description is the description of this operation
 	Description string
 	Labels      map[string]string
+	// ValuesTrace records which file or flag set each of the values passed to
+	// Run. Values reused from the previous release are traced as such.
+	ValuesTrace map[string]string
+	// Profile is the name of a values profile of the chart, such as "prod" for
+	// profiles/prod.yaml, overriding the chart's default values.
+	Profile string
+	// SecretProviders resolve the references to secrets in the values, such
+	// as vault://secret/data/db#password, for rendering. The release is stored
+	// with the references rather than the secrets. If nil, no reference is
+	// resolved.
+	SecretProviders secrets.Providers
+	// SecretReferences, if set, limits the references resolved to those it
+	// holds at the same keys as the values, such as the values set with
+	// --set-secret. Otherwise every reference in the values is resolved.
+	SecretReferences map[string]any
 	// PostRenderer is an optional post-renderer
 	//
 	// If this is non-nil, then after templates are rendered, they will be sent to the
-	// post renderer before sending to the Kubernetes API server.
+	// post renderer before sending to the Kubernetes API server. Use a
+	// postrenderer.Chain to run several post-renderers in order.
 	PostRenderer postrenderer.PostRenderer
 	// PostRenderStrategy controls how hooks and regular templates are passed
 	// to the configured post-renderer. See PostRenderStrategy for the
@@ -129,12 +184,41 @@ type Upgrade struct {
 	DisableOpenAPIValidation bool
 	// Get missing dependencies
 	DependencyUpdate bool
+	// Fail unless the dependencies of the chart match its lock file
+	Locked bool
 	// Lock to control raceconditions when the process receives a SIGTERM
 	Lock sync.Mutex
 	// Enable DNS lookups when rendering templates
 	EnableDNS bool
 	// TakeOwnership will skip the check for helm annotations and adopt all existing resources.
 	TakeOwnership bool
+	// DiffOutput, if set, receives a diff between the manifest of the deployed
+	// release and the manifest of the upgrade before the upgrade is performed.
+	// Combine with a DryRunStrategy to preview an upgrade without performing it.
+	DiffOutput io.Writer
+	// DiffContext is the number of unchanged lines shown around each change in the diff
+	DiffContext int
+	// DiffNoColor disables colorized diff output
+	DiffNoColor bool
+	// Only restricts the upgrade to the rendered resources matching any of the
+	// selectors. All other resources are kept as they are in the deployed release.
+	Only []ResourceSelector
+	// AtomicHooks snapshots the live state of the release resources before the
+	// upgrade. If the upgrade fails, including when a pre- or post-upgrade hook
+	// fails, the resources of the upgrade hooks are deleted and the fields Helm
+	// manages are restored from the snapshot. The Kubernetes client must
+	// implement kube.InterfaceSnapshot.
+	AtomicHooks bool
+
+	// snapshot is the live state of the resources taken when AtomicHooks is set
+	snapshot *upgradeSnapshot
+}
+
+// upgradeSnapshot holds the live state of the resources of a release before an
+// upgrade, along with the resources the upgrade applies.
+type upgradeSnapshot struct {
+	live   kube.ResourceList
+	target kube.ResourceList
 }
 
 type resultMessage struct {
@@ -142,6 +226,22 @@ type resultMessage struct {
 	e error
 }
 
+// ReuseValuesStrategy is the strategy used to merge the values of the previous
+// release with the values supplied for an upgrade.
+type ReuseValuesStrategy string
+
+const (
+	// ReuseValuesCoalesce deep merges the new values into the previous ones
+	// and removes keys set to null. This is the default.
+	ReuseValuesCoalesce ReuseValuesStrategy = "coalesce"
+	// ReuseValuesDeep deep merges the new values into the previous ones, and
+	// keeps keys set to null so that they also override the chart defaults.
+	ReuseValuesDeep ReuseValuesStrategy = "deep"
+	// ReuseValuesShallow replaces each top-level key of the previous values
+	// with the new value for it, without merging nested maps.
+	ReuseValuesShallow ReuseValuesStrategy = "shallow"
+)
+
 // NewUpgrade creates a new Upgrade object with the given configuration.
 func NewUpgrade(cfg *Configuration) *Upgrade {
 	up := &Upgrade{
@@ -149,6 +249,7 @@ func NewUpgrade(cfg *Configuration) *Upgrade {
 		ServerSideApply:    "auto", // Must always match the CLI default.
 		DryRunStrategy:     DryRunNone,
 		PostRenderStrategy: PostRenderStrategyCombined,
+		DiffContext:        diff.DefaultContext,
 	}
 	up.registryClient = cfg.RegistryClient
 
@@ -192,13 +293,50 @@ func (u *Upgrade) RunWithContext(ctx context.Context, name string, ch chart.Char
 		return nil, fmt.Errorf("release name is invalid: %s", name)
 	}
 
+	switch u.RolloutStrategy {
+	case RolloutStrategyAll, "":
+	case RolloutStrategyCanary:
+		if _, err := validateRolloutSteps(u.RolloutSteps); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid rollout strategy %q", u.RolloutStrategy)
+	}
+
+	if !isDryRun(u.DryRunStrategy) {
+		lockCtx, unlock, err := u.cfg.Releases.Lock(ctx, name, "upgrade", u.LockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+		ctx = lockCtx
+	}
+
 	u.cfg.Logger().Debug("preparing upgrade", "name", name)
 	currentRelease, upgradedRelease, serverSideApply, err := u.prepareUpgrade(ctx, name, chrt, vals)
 	if err != nil {
 		return nil, err
 	}
 
+	if u.DiffOutput != nil {
+		changes := diff.Manifests(currentRelease.Manifest, upgradedRelease.Manifest)
+		if err := diff.Write(u.DiffOutput, changes, diff.Options{Context: u.DiffContext, NoColor: u.DiffNoColor, HideSecrets: u.HideSecret}); err != nil {
+			return nil, fmt.Errorf("unable to write diff: %w", err)
+		}
+	}
+
+	if err := u.cfg.apiDeprecationCheck(u.APIDeprecationCheck, upgradedRelease); err != nil {
+		return nil, err
+	}
+
+	if interactWithServer(u.DryRunStrategy) {
+		if err := u.cfg.preflight(ctx, u.Preflight, currentRelease.Manifest, upgradedRelease.Manifest, upgradedRelease.Namespace); err != nil {
+			return nil, err
+		}
+	}
+
 	u.cfg.Releases.MaxHistory = u.MaxHistory
+	u.cfg.Releases.Retention = u.HistoryRetention
 
 	u.cfg.Logger().Debug("performing update", "name", name)
 	res, err := u.performUpgrade(ctx, currentRelease, upgradedRelease, serverSideApply)
@@ -271,15 +409,29 @@ func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv
 	}
 
 	// determine if values will be reused
+	valuesTrace := u.traceValues(currentRelease, vals)
 	vals, err = u.reuseValues(chart, currentRelease, vals)
 	if err != nil {
 		return nil, nil, false, err
 	}
 
+	// With --reuse-values, the profile overrides the previous chart values
+	if u.Profile != "" {
+		if err := chartutil.ApplyProfile(chart, u.Profile); err != nil {
+			return nil, nil, false, err
+		}
+	}
+
 	if err := chartutil.ProcessDependencies(chart, vals); err != nil {
 		return nil, nil, false, err
 	}
 
+	if u.StrictValues {
+		if err := checkUnknownValues(chart, vals); err != nil {
+			return nil, nil, false, err
+		}
+	}
+
 	// Increment revision count. This is passed to templates, and also stored on
 	// the release object.
 	revision := lastRelease.Version + 1
@@ -295,12 +447,16 @@ func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv
 	if err != nil {
 		return nil, nil, false, err
 	}
-	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(chart, vals, options, caps, u.SkipSchemaValidation)
+	resolvedVals, err := resolveSecrets(ctx, u.SecretProviders, u.SecretReferences, vals)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(chart, resolvedVals, options, caps, u.SkipSchemaValidation)
 	if err != nil {
 		return nil, nil, false, err
 	}
 
-	hooks, manifestDoc, notesTxt, err := u.cfg.renderResources(ctx, chart, valuesToRender, "", "", u.SubNotes, false, false, u.PostRenderer, interactWithServer(u.DryRunStrategy), u.EnableDNS, u.HideSecret, u.PostRenderStrategy)
+	hooks, manifestDoc, notesTxt, err := u.cfg.renderResources(ctx, chart, valuesToRender, "", "", "", u.SubNotes, false, false, u.PostRenderer, interactWithServer(u.DryRunStrategy), u.EnableDNS, u.HideSecret, u.PostRenderStrategy, nil)
 	if err != nil {
 		return nil, nil, false, err
 	}
@@ -316,12 +472,25 @@ func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv
 
 	u.cfg.Logger().Debug("determined release apply method", slog.Bool("server_side_apply", serverSideApply), slog.String("previous_release_apply_method", lastRelease.ApplyMethod))
 
+	manifest := manifestDoc.String()
+	if len(u.Only) > 0 {
+		manifest, err = selectResources(currentRelease.Manifest, manifest, currentRelease.Namespace, u.Only)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		// Like the resources, the hooks of the selected resources are upgraded
+		// and all other hooks are kept as they are
+		hooks = append(filterHooks(hooks, currentRelease.Namespace, u.Only, true),
+			filterHooks(currentRelease.Hooks, currentRelease.Namespace, u.Only, false)...)
+	}
+
 	// Store an upgraded release.
 	upgradedRelease := &release.Release{
-		Name:      name,
-		Namespace: currentRelease.Namespace,
-		Chart:     chart,
-		Config:    vals,
+		Name:        name,
+		Namespace:   currentRelease.Namespace,
+		Chart:       chart,
+		Config:      vals,
+		ValuesTrace: valuesTrace,
 		Info: &release.Info{
 			FirstDeployed: currentRelease.Info.FirstDeployed,
 			LastDeployed:  Timestamper(),
@@ -329,7 +498,7 @@ func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv
 			Description:   "Preparing upgrade", // This should be overwritten later.
 		},
 		Version:     revision,
-		Manifest:    manifestDoc.String(),
+		Manifest:    manifest,
 		Hooks:       hooks,
 		Labels:      mergeCustomLabels(lastRelease.Labels, u.Labels),
 		ApplyMethod: string(determineReleaseSSApplyMethod(serverSideApply)),
@@ -338,10 +507,23 @@ func (u *Upgrade) prepareUpgrade(ctx context.Context, name string, chart *chartv
 	if len(notesTxt) > 0 {
 		upgradedRelease.Info.Notes = notesTxt
 	}
-	err = validateManifest(u.cfg.KubeClient, manifestDoc.Bytes(), !u.DisableOpenAPIValidation)
+	err = validateManifest(u.cfg.KubeClient, []byte(manifest), !u.DisableOpenAPIValidation)
 	return currentRelease, upgradedRelease, serverSideApply, err
 }
 
+// execHook executes the hooks of the upgrade for the given hook event. With
+// Only, just the hooks of the selected resources are executed.
+func (u *Upgrade) execHook(rel *release.Release, hook release.HookEvent, serverSideApply bool) error {
+	if len(u.Only) == 0 {
+		return u.cfg.execHook(rel, hook, u.WaitStrategy, u.WaitOptions, u.Timeout, serverSideApply)
+	}
+	hooks := rel.Hooks
+	rel.Hooks = filterHooks(hooks, rel.Namespace, u.Only, true)
+	err := u.cfg.execHook(rel, hook, u.WaitStrategy, u.WaitOptions, u.Timeout, serverSideApply)
+	rel.Hooks = hooks
+	return err
+}
+
 func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedRelease *release.Release, serverSideApply bool) (*release.Release, error) {
 	current, err := u.cfg.KubeClient.Build(bytes.NewBufferString(originalRelease.Manifest), false)
 	if err != nil {
@@ -396,6 +578,12 @@ func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedR
 		return nil
 	})
 
+	if u.DryRunStrategy == DryRunServerAdmission {
+		if err := u.cfg.admissionDryRun(upgradedRelease, target, serverSideApply, u.ForceConflicts); err != nil {
+			return nil, fmt.Errorf("unable to continue with update: %w", err)
+		}
+	}
+
 	if isDryRun(u.DryRunStrategy) {
 		u.cfg.Logger().Debug("dry run for release", "name", upgradedRelease.Name)
 		if len(u.Description) > 0 {
@@ -433,6 +621,7 @@ func (u *Upgrade) reportToPerformUpgrade(c chan<- resultMessage, rel *release.Re
 	if err != nil {
 		rel, err = u.failRelease(rel, created, err)
 	}
+	u.snapshot = nil
 	c <- resultMessage{r: rel, e: err}
 	u.Lock.Unlock()
 }
@@ -455,10 +644,26 @@ func isReleaseApplyMethodClientSideApply(applyMethod string) bool {
 }
 
 func (u *Upgrade) releasingUpgrade(c chan<- resultMessage, upgradedRelease *release.Release, current kube.ResourceList, target kube.ResourceList, originalRelease *release.Release, serverSideApply bool) {
+	if u.AtomicHooks {
+		sc, ok := u.cfg.KubeClient.(kube.InterfaceSnapshot)
+		if !ok {
+			u.reportToPerformUpgrade(c, upgradedRelease, kube.ResourceList{}, errors.New("atomic hooks are not supported by the Kubernetes client"))
+			return
+		}
+		live, err := sc.Snapshot(current)
+		if err != nil {
+			u.reportToPerformUpgrade(c, upgradedRelease, kube.ResourceList{}, fmt.Errorf("unable to snapshot release resources: %w", err))
+			return
+		}
+		u.Lock.Lock()
+		u.snapshot = &upgradeSnapshot{live: live, target: target}
+		u.Lock.Unlock()
+	}
+
 	// pre-upgrade hooks
 
 	if !u.DisableHooks {
-		if err := u.cfg.execHook(upgradedRelease, release.HookPreUpgrade, u.WaitStrategy, u.WaitOptions, u.Timeout, serverSideApply); err != nil {
+		if err := u.execHook(upgradedRelease, release.HookPreUpgrade, serverSideApply); err != nil {
 			u.reportToPerformUpgrade(c, upgradedRelease, kube.ResourceList{}, fmt.Errorf("pre-upgrade hooks failed: %w", err))
 			return
 		}
@@ -467,12 +672,18 @@ func (u *Upgrade) releasingUpgrade(c chan<- resultMessage, upgradedRelease *rele
 	}
 
 	upgradeClientSideFieldManager := isReleaseApplyMethodClientSideApply(originalRelease.ApplyMethod) && serverSideApply // Update client-side field manager if transitioning from client-side to server-side apply
-	results, err := u.cfg.KubeClient.Update(
-		current,
-		target,
+	updateOpts := []kube.ClientUpdateOption{
 		kube.ClientUpdateOptionForceReplace(u.ForceReplace),
 		kube.ClientUpdateOptionServerSideApply(serverSideApply, u.ForceConflicts),
-		kube.ClientUpdateOptionUpgradeClientSideFieldManager(upgradeClientSideFieldManager))
+		kube.ClientUpdateOptionUpgradeClientSideFieldManager(upgradeClientSideFieldManager),
+	}
+	var results *kube.Result
+	var err error
+	if u.RolloutStrategy == RolloutStrategyCanary {
+		results, err = u.canaryRollout(current, target, updateOpts...)
+	} else {
+		results, err = u.cfg.KubeClient.Update(current, target, updateOpts...)
+	}
 	if err != nil {
 		u.cfg.recordRelease(originalRelease)
 		u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
@@ -490,28 +701,27 @@ func (u *Upgrade) releasingUpgrade(c chan<- resultMessage, upgradedRelease *rele
 		u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
 		return
 	}
-	if u.WaitForJobs {
-		if err := waiter.WaitWithJobs(target, u.Timeout); err != nil {
-			u.cfg.recordRelease(originalRelease)
-			u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
-			return
-		}
-	} else {
-		if err := waiter.Wait(target, u.Timeout); err != nil {
-			u.cfg.recordRelease(originalRelease)
-			u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
-			return
-		}
+	if err := waitWithTimeouts(waiter, target, u.Timeout, u.TimeoutPerKind, u.WaitForJobs); err != nil {
+		u.cfg.recordRelease(originalRelease)
+		u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
+		return
 	}
 
 	// post-upgrade hooks
 	if !u.DisableHooks {
-		if err := u.cfg.execHook(upgradedRelease, release.HookPostUpgrade, u.WaitStrategy, u.WaitOptions, u.Timeout, serverSideApply); err != nil {
+		if err := u.execHook(upgradedRelease, release.HookPostUpgrade, serverSideApply); err != nil {
 			u.reportToPerformUpgrade(c, upgradedRelease, results.Created, fmt.Errorf("post-upgrade hooks failed: %w", err))
 			return
 		}
 	}
 
+	if u.Prune {
+		if err := u.prune(upgradedRelease, target); err != nil {
+			u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
+			return
+		}
+	}
+
 	originalRelease.Info.Status = rcommon.StatusSuperseded
 	u.cfg.recordRelease(originalRelease)
 
@@ -551,6 +761,14 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 		u.cfg.Logger().Debug("resource cleanup complete")
 	}
 
+	if u.AtomicHooks && u.snapshot != nil {
+		u.cfg.Logger().Debug("Upgrade failed and atomic-hooks is set, restoring the release resources")
+		if restoreErr := u.restoreSnapshot(rel, u.snapshot); restoreErr != nil {
+			return rel, fmt.Errorf("an error occurred while restoring the release resources. original upgrade error: %w: %w", err, restoreErr)
+		}
+		return rel, fmt.Errorf("release %s failed, and its resources have been restored due to atomic-hooks being set: %w", rel.Name, err)
+	}
+
 	if u.RollbackOnFailure {
 		u.cfg.Logger().Debug("Upgrade failed and rollback-on-failure is set, rolling back to previous successful release")
 
@@ -597,6 +815,41 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 	return rel, err
 }
 
+// restoreSnapshot deletes the resources of the upgrade hooks of a failed
+// release and restores the live state of its resources from the snapshot.
+func (u *Upgrade) restoreSnapshot(rel *release.Release, snapshot *upgradeSnapshot) error {
+	var errs []error
+	for _, h := range rel.Hooks {
+		if !slices.ContainsFunc(h.Events, func(e release.HookEvent) bool {
+			return e == release.HookPreUpgrade || e == release.HookPostUpgrade
+		}) {
+			continue
+		}
+		resources, err := u.cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to build hook %s: %w", h.Path, err))
+			continue
+		}
+		if len(resources) == 0 {
+			continue
+		}
+		if _, deleteErrs := u.cfg.KubeClient.Delete(resources, metav1.DeletePropagationBackground); deleteErrs != nil {
+			errs = append(errs, deleteErrs...)
+		}
+	}
+
+	// The snapshot only holds the fields Helm managed before the upgrade, so
+	// forcing conflicts puts back these fields as they were without taking
+	// over fields of other managers. Resources created by the upgrade are removed.
+	if _, err := u.cfg.KubeClient.Update(
+		snapshot.target,
+		snapshot.live,
+		kube.ClientUpdateOptionServerSideApply(true, true)); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
 // reuseValues copies values from the current release to a new release if the
 // new release does not have any values.
 //
@@ -622,7 +875,10 @@ func (u *Upgrade) reuseValues(chart *chartv2.Chart, current *release.Release, ne
 			return nil, fmt.Errorf("failed to rebuild old values: %w", err)
 		}
 
-		newVals = util.CoalesceTables(newVals, current.Config)
+		newVals, err = u.mergeReusedValues(newVals, current.Config)
+		if err != nil {
+			return nil, err
+		}
 
 		chart.Values = oldVals
 
@@ -633,9 +889,7 @@ func (u *Upgrade) reuseValues(chart *chartv2.Chart, current *release.Release, ne
 	if u.ResetThenReuseValues {
 		u.cfg.Logger().Debug("merging values from old release to new values")
 
-		newVals = util.CoalesceTables(newVals, current.Config)
-
-		return newVals, nil
+		return u.mergeReusedValues(newVals, current.Config)
 	}
 
 	if len(newVals) == 0 && len(current.Config) > 0 {
@@ -645,6 +899,41 @@ func (u *Upgrade) reuseValues(chart *chartv2.Chart, current *release.Release, ne
 	return newVals, nil
 }
 
+// traceValues returns the sources of the values of the upgrade, the values
+// reuseValues merges from the current release traced as reused.
+func (u *Upgrade) traceValues(current *release.Release, newVals map[string]any) map[string]string {
+	if u.ValuesTrace == nil {
+		return nil
+	}
+	trace := util.ValuesTrace{}
+	reused := u.ReuseValues || u.ResetThenReuseValues || len(newVals) == 0
+	if !u.ResetValues && reused {
+		trace.Record(current.Config, "reuse-values")
+	}
+	trace.Merge(u.ValuesTrace)
+	return trace
+}
+
+// mergeReusedValues merges the values of the previous release into the new
+// values according to u.ReuseValuesStrategy. The new values take precedence.
+func (u *Upgrade) mergeReusedValues(newVals, oldVals map[string]any) (map[string]any, error) {
+	switch u.ReuseValuesStrategy {
+	case ReuseValuesCoalesce, "":
+		return util.CoalesceTables(newVals, oldVals), nil
+	case ReuseValuesDeep:
+		return util.MergeTables(newVals, oldVals), nil
+	case ReuseValuesShallow:
+		vals := maps.Clone(oldVals)
+		if vals == nil {
+			vals = map[string]any{}
+		}
+		maps.Copy(vals, newVals)
+		return vals, nil
+	default:
+		return nil, fmt.Errorf("invalid reuse values strategy %q", u.ReuseValuesStrategy)
+	}
+}
+
 func validateManifest(c kube.Interface, manifest []byte, openAPIValidation bool) error {
 	_, err := c.Build(bytes.NewReader(manifest), openAPIValidation)
 	return err
@@ -677,3 +966,51 @@ func getUpgradeServerSideValue(serverSideOption string, releaseApplyMethod strin
 		return false, fmt.Errorf("invalid/unknown release server-side apply method: %s", serverSideOption)
 	}
 }
+
+// waitWithTimeouts waits for resources to become ready. Resources of a kind in
+// perKind are waited on with the timeout of their kind, all others with
+// timeout. The groups of resources are waited on concurrently, so that waiting
+// takes no longer than the longest of their timeouts.
+func waitWithTimeouts(waiter kube.Waiter, resources kube.ResourceList, timeout time.Duration, perKind map[string]time.Duration, withJobs bool) error {
+	wait := waiter.Wait
+	if withJobs {
+		wait = waiter.WaitWithJobs
+	}
+	if len(perKind) == 0 {
+		return wait(resources, timeout)
+	}
+
+	var kinds []string
+	groups := make(map[string]kube.ResourceList)
+	for _, info := range resources {
+		// Resources waited on with the default timeout are grouped under the empty kind
+		var group string
+		for kind := range perKind {
+			if strings.EqualFold(kind, infoKind(info)) {
+				group = kind
+				break
+			}
+		}
+		if _, ok := groups[group]; !ok {
+			kinds = append(kinds, group)
+		}
+		groups[group] = append(groups[group], info)
+	}
+
+	// errs holds the error of each group in the order of kinds
+	errs := make([]error, len(kinds))
+	var wg sync.WaitGroup
+	for i, kind := range kinds {
+		wg.Go(func() {
+			if kind == "" {
+				errs[i] = wait(groups[kind], timeout)
+				return
+			}
+			if err := wait(groups[kind], perKind[kind]); err != nil {
+				errs[i] = fmt.Errorf("waiting for %s resources: %w", kind, err)
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -17,11 +17,13 @@ limitations under the License.
 package action
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -30,13 +32,16 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
 
+	chartcommon "helm.sh/helm/v4/pkg/chart/common"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
 	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage"
 	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
@@ -211,6 +216,74 @@ func TestUpgradeRelease_RollbackOnFailure(t *testing.T) {
 	})
 }
 
+func TestUpgradeRelease_AtomicHooks(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	t.Run("post-upgrade hook failure restores snapshot", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := releaseStub()
+		rel.Name = "nuketown"
+		rel.Info.Status = common.StatusDeployed
+		req.NoError(upAction.cfg.Releases.Create(rel))
+
+		failer := upAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+		failer.WatchUntilReadyError = errors.New("arming key removed")
+		upAction.AtomicHooks = true
+
+		resi, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+		req.Error(err)
+		is.Contains(err.Error(), "arming key removed")
+		is.Contains(err.Error(), "atomic-hooks")
+		res, err := releaserToV1Release(resi)
+		req.NoError(err)
+		is.Equal(common.StatusFailed, res.Info.Status)
+		is.Nil(upAction.snapshot)
+
+		// The previous release is still the deployed one, no rollback revision is created
+		deployedi, err := upAction.cfg.Releases.Deployed(rel.Name)
+		req.NoError(err)
+		deployed, err := releaserToV1Release(deployedi)
+		req.NoError(err)
+		is.Equal(1, deployed.Version)
+		_, err = upAction.cfg.Releases.Get(rel.Name, 3)
+		is.Error(err)
+	})
+
+	t.Run("snapshot fails", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := releaseStub()
+		rel.Name = "fallout"
+		rel.Info.Status = common.StatusDeployed
+		req.NoError(upAction.cfg.Releases.Create(rel))
+
+		failer := upAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+		failer.SnapshotError = errors.New("snapshot fail")
+		upAction.AtomicHooks = true
+
+		_, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+		req.Error(err)
+		is.Contains(err.Error(), "unable to snapshot release resources: snapshot fail")
+	})
+
+	t.Run("restore fails", func(t *testing.T) {
+		upAction := upgradeAction(t)
+		rel := releaseStub()
+		rel.Name = "fallout"
+		rel.Info.Status = common.StatusDeployed
+		req.NoError(upAction.cfg.Releases.Create(rel))
+
+		failer := upAction.cfg.KubeClient.(*kubefake.FailingKubeClient)
+		failer.UpdateError = errors.New("update fail")
+		upAction.AtomicHooks = true
+
+		_, err := upAction.Run(rel.Name, buildChart(), map[string]any{})
+		req.Error(err)
+		is.Contains(err.Error(), "update fail")
+		is.Contains(err.Error(), "an error occurred while restoring the release resources")
+	})
+}
+
 func TestUpgradeRelease_ReuseValues(t *testing.T) {
 	is := assert.New(t)
 
@@ -264,6 +337,52 @@ func TestUpgradeRelease_ReuseValues(t *testing.T) {
 		is.Equal(expectedValues, updatedRes.Config)
 	})
 
+	t.Run("reuse values should trace the reused values", func(t *testing.T) {
+		upAction := upgradeAction(t)
+
+		rel := releaseStub()
+		rel.Name = "nuketown"
+		rel.Info.Status = common.StatusDeployed
+		rel.Config = map[string]any{"name": "value", "replicas": 2}
+		rel.ValuesTrace = map[string]string{"name": "--set", "replicas": "--set"}
+		is.NoError(upAction.cfg.Releases.Create(rel))
+
+		upAction.ReuseValues = true
+		upAction.ValuesTrace = map[string]string{"name": "-f values.yaml (file 1)"}
+		resi, err := upAction.Run(rel.Name, buildChart(), map[string]any{"name": "newValue"})
+		is.NoError(err)
+		res, err := releaserToV1Release(resi)
+		is.NoError(err)
+
+		is.Equal(map[string]string{
+			"name":     "-f values.yaml (file 1)",
+			"replicas": "reuse-values",
+		}, res.ValuesTrace)
+	})
+
+	t.Run("reuse values should apply the profile over the previous chart values", func(t *testing.T) {
+		upAction := upgradeAction(t)
+
+		rel := releaseStub()
+		rel.Name = "nuketown"
+		rel.Info.Status = common.StatusDeployed
+		rel.Chart = buildChart(withValues(map[string]any{"replicas": 1, "tag": "old"}))
+		is.NoError(upAction.cfg.Releases.Create(rel))
+
+		upAction.ReuseValues = true
+		upAction.Profile = "prod"
+		ch := buildChart(
+			withValues(map[string]any{"replicas": 1, "tag": "new"}),
+			withFile(chartcommon.File{Name: "profiles/prod.yaml", Data: []byte("replicas: 3\n")}),
+		)
+		resi, err := upAction.Run(rel.Name, ch, map[string]any{})
+		is.NoError(err)
+		res, err := releaserToV1Release(resi)
+		is.NoError(err)
+
+		is.Equal(map[string]any{"name": "value", "replicas": float64(3), "tag": "old"}, res.Chart.Values)
+	})
+
 	t.Run("reuse values should not install disabled charts", func(t *testing.T) {
 		upAction := upgradeAction(t)
 		chartDefaultValues := map[string]any{
@@ -397,6 +516,110 @@ func TestUpgradeRelease_ResetThenReuseValues(t *testing.T) {
 	})
 }
 
+func TestUpgradeRelease_ReuseValuesStrategy(t *testing.T) {
+	existingValues := func() map[string]any {
+		return map[string]any{
+			"image": map[string]any{
+				"repository": "nginx",
+				"tag":        "1.0",
+			},
+			"replicas": 2,
+		}
+	}
+	newValues := func() map[string]any {
+		return map[string]any{
+			"image": map[string]any{
+				"tag": "2.0",
+			},
+			"replicas": nil,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		strategy ReuseValuesStrategy
+		expected map[string]any
+	}{
+		{
+			name: "default coalesces values",
+			expected: map[string]any{
+				"image": map[string]any{
+					"repository": "nginx",
+					"tag":        "2.0",
+				},
+			},
+		},
+		{
+			name:     "coalesce",
+			strategy: ReuseValuesCoalesce,
+			expected: map[string]any{
+				"image": map[string]any{
+					"repository": "nginx",
+					"tag":        "2.0",
+				},
+			},
+		},
+		{
+			name:     "deep keeps null values",
+			strategy: ReuseValuesDeep,
+			expected: map[string]any{
+				"image": map[string]any{
+					"repository": "nginx",
+					"tag":        "2.0",
+				},
+				"replicas": nil,
+			},
+		},
+		{
+			name:     "shallow replaces top-level keys",
+			strategy: ReuseValuesShallow,
+			expected: map[string]any{
+				"image": map[string]any{
+					"tag": "2.0",
+				},
+				"replicas": nil,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upAction := upgradeAction(t)
+
+			rel := releaseStub()
+			rel.Name = "nuketown"
+			rel.Info.Status = common.StatusDeployed
+			rel.Config = existingValues()
+			require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+			upAction.ReuseValues = true
+			upAction.ReuseValuesStrategy = tt.strategy
+			_, err := upAction.Run(rel.Name, buildChart(), newValues())
+			require.NoError(t, err)
+
+			updatedResi, err := upAction.cfg.Releases.Get(rel.Name, 2)
+			require.NoError(t, err)
+			updatedRes, err := releaserToV1Release(updatedResi)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, updatedRes.Config)
+		})
+	}
+
+	t.Run("invalid strategy", func(t *testing.T) {
+		upAction := upgradeAction(t)
+
+		rel := releaseStub()
+		rel.Name = "nuketown"
+		rel.Info.Status = common.StatusDeployed
+		rel.Config = existingValues()
+		require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+		upAction.ReuseValues = true
+		upAction.ReuseValuesStrategy = "merge"
+		_, err := upAction.Run(rel.Name, buildChart(), newValues())
+		assert.ErrorContains(t, err, `invalid reuse values strategy "merge"`)
+	})
+}
+
 func TestUpgradeRelease_Pending(t *testing.T) {
 	req := require.New(t)
 
@@ -637,6 +860,78 @@ func TestUpgradeRelease_DryRun(t *testing.T) {
 	req.Error(err)
 }
 
+func TestUpgradeRelease_Diff(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "previous-release"
+	rel.Info.Status = common.StatusDeployed
+	rel.Manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: removed\n"
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	var out bytes.Buffer
+	upAction.DryRunStrategy = DryRunClient
+	upAction.DiffOutput = &out
+	upAction.DiffNoColor = true
+
+	_, err := upAction.RunWithContext(t.Context(), rel.Name, buildChart(withSampleSecret()), map[string]any{})
+	req.NoError(err)
+
+	is.Contains(out.String(), "-, removed, ConfigMap has been removed:\n")
+	is.Contains(out.String(), "-kind: ConfigMap\n")
+	is.Contains(out.String(), "+kind: Secret\n")
+
+	lastReleasei, err := upAction.cfg.Releases.Last(rel.Name)
+	req.NoError(err)
+	lastRelease, err := releaserToV1Release(lastReleasei)
+	req.NoError(err)
+	is.Equal(1, lastRelease.Version)
+}
+
+func TestUpgradeRelease_Only(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "previous-release"
+	rel.Info.Status = common.StatusDeployed
+	rel.Manifest = "---\n# Source: hello/templates/kept\nkind: ConfigMap\nmetadata:\n  name: kept\n"
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	upAction.Only = []ResourceSelector{{Kind: "Secret"}}
+	resi, err := upAction.RunWithContext(t.Context(), rel.Name, buildChart(withSampleSecret()), map[string]any{})
+	req.NoError(err)
+	res, err := releaserToV1Release(resi)
+	req.NoError(err)
+	is.Contains(res.Manifest, "kind: Secret")
+	is.Contains(res.Manifest, "name: kept")
+	is.NotContains(res.Manifest, "hello: world")
+	// The hooks of unselected resources are kept and not run
+	is.Equal(rel.Hooks, res.Hooks)
+	for _, h := range res.Hooks {
+		is.True(h.LastRun.StartedAt.IsZero(), "hook %s was run", h.Name)
+	}
+
+	// The hooks of selected resources are upgraded and run
+	upAction.Only = []ResourceSelector{{Kind: "ConfigMap"}}
+	resi, err = upAction.RunWithContext(t.Context(), rel.Name, buildChart(), map[string]any{})
+	req.NoError(err)
+	res, err = releaserToV1Release(resi)
+	req.NoError(err)
+	req.Len(res.Hooks, 2)
+	is.Equal("test-cm", res.Hooks[0].Name)
+	is.False(res.Hooks[0].LastRun.StartedAt.IsZero())
+	is.Equal("finding-nemo", res.Hooks[1].Name)
+	is.True(res.Hooks[1].LastRun.StartedAt.IsZero())
+
+	upAction.Only = []ResourceSelector{{Kind: "Deployment"}}
+	_, err = upAction.RunWithContext(t.Context(), rel.Name, buildChart(withSampleSecret()), map[string]any{})
+	is.Error(err)
+}
+
 func TestGetUpgradeServerSideValue(t *testing.T) {
 	tests := []struct {
 		name                    string
@@ -800,3 +1095,92 @@ func TestUpgradeRelease_WaitOptionsPassedDownstream(t *testing.T) {
 	// Verify that WaitOptions were passed to GetWaiter
 	is.NotEmpty(failer.RecordedWaitOptions, "WaitOptions should be passed to GetWaiter")
 }
+
+// recordingWaiter records the timeout used for each resource it waits on.
+type recordingWaiter struct {
+	kube.Waiter
+	mu       sync.Mutex
+	timeouts map[string]time.Duration
+	err      error
+}
+
+func (w *recordingWaiter) Wait(resources kube.ResourceList, timeout time.Duration) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, info := range resources {
+		w.timeouts[info.Name] = timeout
+	}
+	return w.err
+}
+
+// barrierWaiter only returns once every group of resources is waited on.
+type barrierWaiter struct {
+	kube.Waiter
+	barrier sync.WaitGroup
+}
+
+func (w *barrierWaiter) Wait(_ kube.ResourceList, _ time.Duration) error {
+	w.barrier.Done()
+	done := make(chan struct{})
+	go func() {
+		w.barrier.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-time.After(10 * time.Second):
+		return errors.New("the groups of resources were not waited on concurrently")
+	}
+}
+
+func TestWaitWithTimeouts(t *testing.T) {
+	resources := kube.ResourceList{
+		checkpointInfo("ConfigMap", "config"),
+		checkpointInfo("StatefulSet", "db"),
+		checkpointInfo("Service", "svc"),
+	}
+
+	waiter := &recordingWaiter{timeouts: map[string]time.Duration{}}
+	require.NoError(t, waitWithTimeouts(waiter, resources, time.Minute, map[string]time.Duration{
+		"statefulset": 20 * time.Minute,
+		"ConfigMap":   30 * time.Second,
+	}, false))
+	assert.Equal(t, map[string]time.Duration{
+		"config": 30 * time.Second,
+		"db":     20 * time.Minute,
+		"svc":    time.Minute,
+	}, waiter.timeouts)
+
+	waiter = &recordingWaiter{timeouts: map[string]time.Duration{}, err: errors.New("timed out")}
+	err := waitWithTimeouts(waiter, resources[1:2], time.Minute, map[string]time.Duration{"StatefulSet": time.Second}, false)
+	assert.EqualError(t, err, "waiting for StatefulSet resources: timed out")
+
+	// the groups are waited on concurrently, not one after the other
+	barrier := &barrierWaiter{}
+	barrier.barrier.Add(3)
+	require.NoError(t, waitWithTimeouts(barrier, resources, time.Minute, map[string]time.Duration{
+		"StatefulSet": 20 * time.Minute,
+		"ConfigMap":   30 * time.Second,
+	}, false))
+}
+
+func TestUpgradeRelease_Locked(t *testing.T) {
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Info.Status = common.StatusDeployed
+	require.NoError(t, upAction.cfg.Releases.Create(rel))
+
+	leases := fakeclientset.NewClientset().CoordinationV1().Leases("default")
+	upAction.cfg.Releases.Locker = storage.NewLeaseLocker(leases)
+	_, unlock, err := storage.NewLeaseLocker(leases).Lock(t.Context(), rel.Name, "rollback", 0)
+	require.NoError(t, err)
+
+	_, err = upAction.Run(rel.Name, buildChart(), map[string]any{})
+	require.ErrorIs(t, err, storage.ErrReleaseLocked)
+	assert.ErrorContains(t, err, "release angry-panda locked by operation rollback started at")
+
+	require.NoError(t, unlock())
+	_, err = upAction.Run(rel.Name, buildChart(), map[string]any{})
+	require.NoError(t, err)
+}
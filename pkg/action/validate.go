@@ -40,36 +40,37 @@ const (
 
 // requireAdoption returns the subset of resources that already exist in the cluster.
 func requireAdoption(resources kube.ResourceList) (kube.ResourceList, error) {
-	var requireUpdate kube.ResourceList
-
-	err := resources.Visit(func(info *resource.Info, err error) error {
-		if err != nil {
-			return err
-		}
+	return existingResources(resources, nil)
+}
 
-		isGenerateName, err := validateNameAndGenerateName(info)
-		if isGenerateName || err != nil {
-			return err
+func existingResourceConflict(resources kube.ResourceList, releaseName, releaseNamespace string) (kube.ResourceList, error) {
+	return existingResources(resources, func(info *resource.Info, existing runtime.Object) error {
+		// Allow adoption of the resource if it is managed by Helm and is annotated with correct release name and namespace.
+		if err := checkOwnership(existing, releaseName, releaseNamespace); err != nil {
+			return fmt.Errorf("%s exists and cannot be imported into the current release: %w", resourceString(info), err)
 		}
+		return nil
+	})
+}
 
-		helper := resource.NewHelper(info.Client, info.Mapping)
-		_, err = helper.Get(info.Namespace, info.Name)
-		if err != nil {
-			if apierrors.IsNotFound(err) {
-				return nil
-			}
-			return fmt.Errorf("could not get information about the resource %s: %w", resourceString(info), err)
+// adoptExisting returns the subset of resources that already exist in the
+// cluster and can be adopted by the release. Existing resources that are not
+// managed by Helm, such as resources created with kubectl, can be adopted.
+// Resources managed by another tool or belonging to another release result in
+// an error.
+func adoptExisting(resources kube.ResourceList, releaseName, releaseNamespace string) (kube.ResourceList, error) {
+	return existingResources(resources, func(info *resource.Info, existing runtime.Object) error {
+		if err := checkAdoptable(existing, releaseName, releaseNamespace); err != nil {
+			return fmt.Errorf("%s exists and cannot be adopted by the current release: %w", resourceString(info), err)
 		}
-
-		infoCopy := *info
-		requireUpdate.Append(&infoCopy)
 		return nil
 	})
-
-	return requireUpdate, err
 }
 
-func existingResourceConflict(resources kube.ResourceList, releaseName, releaseNamespace string) (kube.ResourceList, error) {
+// existingResources returns the subset of resources that already exist in the
+// cluster. Each existing resource is passed to check, if set, and an error
+// returned by check stops the visit.
+func existingResources(resources kube.ResourceList, check func(info *resource.Info, existing runtime.Object) error) (kube.ResourceList, error) {
 	var requireUpdate kube.ResourceList
 
 	err := resources.Visit(func(info *resource.Info, err error) error {
@@ -91,11 +92,11 @@ func existingResourceConflict(resources kube.ResourceList, releaseName, releaseN
 			return fmt.Errorf("could not get information about the resource %s: %w", resourceString(info), err)
 		}
 
-		// Allow adoption of the resource if it is managed by Helm and is annotated with correct release name and namespace.
-		if err := checkOwnership(existing, releaseName, releaseNamespace); err != nil {
-			return fmt.Errorf("%s exists and cannot be imported into the current release: %w", resourceString(info), err)
+		if check != nil {
+			if err := check(info, existing); err != nil {
+				return err
+			}
 		}
-		// Resources that are not found are skipped because they are already deleted and do not need deletion.
 		infoCopy := *info
 		requireUpdate.Append(&infoCopy)
 		return nil
@@ -104,6 +105,36 @@ func existingResourceConflict(resources kube.ResourceList, releaseName, releaseN
 	return requireUpdate, err
 }
 
+// checkAdoptable returns an error if an object is owned by something other
+// than the given release. Objects without ownership metadata are adoptable.
+func checkAdoptable(obj runtime.Object, releaseName, releaseNamespace string) error {
+	lbls, err := accessor.Labels(obj)
+	if err != nil {
+		return err
+	}
+	annos, err := accessor.Annotations(obj)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if v, ok := lbls[appManagedByLabel]; ok && v != appManagedByHelm {
+		errs = append(errs, fmt.Errorf("label validation error: key %q is set to %q", appManagedByLabel, v))
+	}
+	if v, ok := annos[helmReleaseNameAnnotation]; ok && v != releaseName {
+		errs = append(errs, fmt.Errorf("annotation validation error: owned by release %q", v))
+	}
+	if v, ok := annos[helmReleaseNamespaceAnnotation]; ok && v != releaseNamespace {
+		errs = append(errs, fmt.Errorf("annotation validation error: owned by a release in namespace %q", v))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("conflicting ownership metadata; %w", joinErrors(errs, "; "))
+	}
+
+	return nil
+}
+
 // unverifiableResource pairs a resource with the error encountered while attempting
 // to verify its ownership (for example, RBAC or network failures).
 type unverifiableResource struct {
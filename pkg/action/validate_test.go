@@ -25,6 +25,7 @@ import (
 	"helm.sh/helm/v4/pkg/kube"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -166,6 +167,63 @@ func TestExistingResourceConflict(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestAdoptExisting(t *testing.T) {
+	var (
+		releaseName      = "rel-name"
+		releaseNamespace = "rel-namespace"
+		missing          = newMissingDeployment("missing", "ns-a")
+		unmanaged        = newDeploymentWithOwner("unmanaged", "ns-a", nil, nil)
+		owned            = newDeploymentWithOwner("owned", "ns-a",
+			map[string]string{appManagedByLabel: appManagedByHelm},
+			map[string]string{
+				helmReleaseNameAnnotation:      releaseName,
+				helmReleaseNamespaceAnnotation: releaseNamespace,
+			})
+		otherRelease = newDeploymentWithOwner("other-release", "ns-a",
+			map[string]string{appManagedByLabel: appManagedByHelm},
+			map[string]string{
+				helmReleaseNameAnnotation:      "other",
+				helmReleaseNamespaceAnnotation: releaseNamespace,
+			})
+		otherTool = newDeploymentWithOwner("other-tool", "ns-a",
+			map[string]string{appManagedByLabel: "kustomize"}, nil)
+	)
+
+	tests := []struct {
+		name      string
+		resources kube.ResourceList
+		adopted   kube.ResourceList
+		wantErr   string
+	}{
+		{
+			name:      "unmanaged and owned resources are adopted",
+			resources: kube.ResourceList{missing, unmanaged, owned},
+			adopted:   kube.ResourceList{unmanaged, owned},
+		},
+		{
+			name:      "resource owned by another release",
+			resources: kube.ResourceList{unmanaged, otherRelease},
+			wantErr:   `owned by release "other"`,
+		},
+		{
+			name:      "resource managed by another tool",
+			resources: kube.ResourceList{otherTool},
+			wantErr:   `key "app.kubernetes.io/managed-by" is set to "kustomize"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adopted, err := adoptExisting(tt.resources, releaseName, releaseNamespace)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.adopted, adopted)
+		})
+	}
+}
+
 func TestCheckOwnership(t *testing.T) {
 	deployFoo := newDeploymentResource("foo", "ns-a", "")
 
@@ -57,15 +57,18 @@ type Capabilities struct {
 	KubeVersion KubeVersion
 	// APIVersions are supported Kubernetes API versions.
 	APIVersions VersionSet
+	// APIResources are the resources served by the Kubernetes API.
+	APIResources APIResources
 	// HelmVersion is the build information for this helm version
 	HelmVersion helmversion.BuildInfo
 }
 
 func (capabilities *Capabilities) Copy() *Capabilities {
 	return &Capabilities{
-		KubeVersion: capabilities.KubeVersion,
-		APIVersions: capabilities.APIVersions,
-		HelmVersion: capabilities.HelmVersion,
+		KubeVersion:  capabilities.KubeVersion,
+		APIVersions:  capabilities.APIVersions,
+		APIResources: capabilities.APIResources,
+		HelmVersion:  capabilities.HelmVersion,
 	}
 }
 
@@ -127,6 +130,74 @@ func (v VersionSet) Has(apiVersion string) bool {
 	return slices.Contains(v, apiVersion)
 }
 
+// APIResource describes a resource served by the Kubernetes API.
+type APIResource struct {
+	// Group is the API group of the resource, empty for the core group.
+	Group string `json:"group,omitempty"`
+	// Version is the API version of the resource.
+	Version string `json:"version"`
+	// Resource is the plural name of the resource, such as "deployments".
+	Resource string `json:"resource"`
+	// Kind is the kind of the objects of the resource, such as "Deployment".
+	Kind string `json:"kind"`
+	// Namespaced is true if the objects of the resource are namespaced.
+	Namespaced bool `json:"namespaced"`
+	// Verbs are the operations the resource supports, such as "get" or "list".
+	Verbs []string `json:"verbs,omitempty"`
+}
+
+// GroupVersion returns the API version of the resource, such as "apps/v1".
+func (r APIResource) GroupVersion() string {
+	if r.Group == "" {
+		return r.Version
+	}
+	return r.Group + "/" + r.Version
+}
+
+// HasVerb returns true if the resource supports the verb.
+func (r APIResource) HasVerb(verb string) bool {
+	return slices.Contains(r.Verbs, verb)
+}
+
+// APIResources is a set of Kubernetes API resources.
+type APIResources []APIResource
+
+// Get returns the resource of an API version by kind or resource name, or nil
+// if it is not served.
+//
+//	rs.Get("apps/v1", "Deployment")
+//	rs.Get("apps/v1", "deployments")
+func (rs APIResources) Get(apiVersion, kindOrResource string) *APIResource {
+	for n, r := range rs {
+		if r.GroupVersion() == apiVersion && (r.Kind == kindOrResource || r.Resource == kindOrResource) {
+			return &rs[n]
+		}
+	}
+	return nil
+}
+
+// Has returns true if a resource of an API version is served, by kind or
+// resource name.
+//
+//	rs.Has("monitoring.coreos.com/v1", "ServiceMonitor")
+func (rs APIResources) Has(apiVersion, kindOrResource string) bool {
+	return rs.Get(apiVersion, kindOrResource) != nil
+}
+
+// Versions returns the API versions and the API versions with kinds, as found
+// in APIVersions, of the resources.
+func (rs APIResources) Versions() VersionSet {
+	var vs VersionSet
+	for _, r := range rs {
+		for _, v := range []string{r.GroupVersion(), r.GroupVersion() + "/" + r.Kind} {
+			if !vs.Has(v) {
+				vs = append(vs, v)
+			}
+		}
+	}
+	return vs
+}
+
 func allKnownVersions() VersionSet {
 	// We should register the built in extension APIs as well so CRDs are
 	// supported in the default version set. This has caused problems with `helm
@@ -34,6 +34,33 @@ func TestVersionSet(t *testing.T) {
 	}
 }
 
+func TestAPIResources(t *testing.T) {
+	rs := APIResources{
+		{Version: "v1", Resource: "namespaces", Kind: "Namespace", Verbs: []string{"get", "list"}},
+		{Group: "apps", Version: "v1", Resource: "deployments", Kind: "Deployment", Namespaced: true},
+	}
+
+	if r := rs.Get("apps/v1", "Deployment"); r == nil || r.Resource != "deployments" || !r.Namespaced {
+		t.Errorf("Expected to find namespaced apps/v1 deployments, got %v", r)
+	}
+	if !rs.Has("apps/v1", "deployments") {
+		t.Error("Expected to find apps/v1 deployments by resource name")
+	}
+	if rs.Has("v1", "Deployment") {
+		t.Error("Expected not to find v1 Deployment")
+	}
+	if r := rs.Get("v1", "Namespace"); r == nil || r.Namespaced || !r.HasVerb("list") || r.HasVerb("delete") {
+		t.Errorf("Expected cluster scoped v1 namespaces listable but not deletable, got %v", r)
+	}
+
+	vs := rs.Versions()
+	for _, v := range []string{"v1", "v1/Namespace", "apps/v1", "apps/v1/Deployment"} {
+		if !vs.Has(v) {
+			t.Errorf("Expected versions to include %s, got %v", v, vs)
+		}
+	}
+}
+
 func TestDefaultVersionSet(t *testing.T) {
 	if !DefaultVersionSet.Has("v1") {
 		t.Error("Expected core v1 version set")
@@ -0,0 +1,249 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+	goYaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// ValuesTrace records the source of values, such as "-f values.yaml (file 1)"
+// or "--set", keyed by the dot separated path of each value.
+//
+// Only the leaves of the values are traced: scalars, lists, empty maps and
+// nulls. Lists are traced as a whole, as no source sets single list items.
+type ValuesTrace map[string]string
+
+// Record sets source as the source of all leaves of vals, replacing the
+// sources of the values they override.
+func (t ValuesTrace) Record(vals map[string]any, source string) {
+	t.record(nil, vals, source)
+}
+
+func (t ValuesTrace) record(path []string, vals map[string]any, source string) {
+	for key, val := range vals {
+		p := append(path[:len(path):len(path)], key)
+		if m, ok := val.(map[string]any); ok && len(m) > 0 {
+			t.record(p, m, source)
+			continue
+		}
+		t.Set(p, source)
+	}
+}
+
+// Set sets source as the source of the value at path, replacing the sources
+// of the values it overrides: those below path and the leaves above it.
+func (t ValuesTrace) Set(path []string, source string) {
+	t.set(tracePath(path), source)
+}
+
+// Merge sets the sources traced in src, which override those in t.
+func (t ValuesTrace) Merge(src map[string]string) {
+	for key, source := range src {
+		t.set(key, source)
+	}
+}
+
+func (t ValuesTrace) set(key, source string) {
+	for k := range t {
+		if strings.HasPrefix(k, key+".") || strings.HasPrefix(key, k+".") {
+			delete(t, k)
+		}
+	}
+	t[key] = source
+}
+
+// lookup returns the source of the value at path, or of the leaf above it.
+func (t ValuesTrace) lookup(path []string) (string, bool) {
+	for n := len(path); n > 0; n-- {
+		if source, ok := t[tracePath(path[:n])]; ok {
+			return source, true
+		}
+	}
+	return "", false
+}
+
+// TraceValues returns the sources of all values of a chart computed from the
+// user supplied values, which were traced in userTrace. Values no user source
+// set come from the default values of the chart or, for subcharts, from the
+// values of a parent chart.
+func TraceValues(chrt chart.Charter, vals map[string]any, userTrace ValuesTrace) (ValuesTrace, error) {
+	coalesced, err := CoalesceValues(chrt, vals)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := chart.NewAccessor(chrt)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := ValuesTrace{}
+	var walk func(path []string, vals map[string]any) error
+	walk = func(path []string, vals map[string]any) error {
+		for key, val := range vals {
+			p := append(path[:len(path):len(path)], key)
+			if m, ok := val.(map[string]any); ok && len(m) > 0 {
+				if err := walk(p, m); err != nil {
+					return err
+				}
+				continue
+			}
+			source, err := traceSource(ch, p, val, userTrace)
+			if err != nil {
+				return err
+			}
+			trace[tracePath(p)] = source
+		}
+		return nil
+	}
+	return trace, walk(nil, coalesced)
+}
+
+// traceSource returns the source of the computed value at path.
+func traceSource(ch chart.Accessor, path []string, value any, userTrace ValuesTrace) (string, error) {
+	if source, ok := userTrace.lookup(path); ok {
+		return source, nil
+	}
+
+	// Find the charts along path, which values of the outer charts override
+	type owner struct {
+		chart  chart.Accessor
+		offset int
+	}
+	owners := []owner{{ch, 0}}
+	for n := 0; n < len(path); n++ {
+		current := owners[len(owners)-1]
+		// Global values of subcharts usually come from their parent
+		if path[n] == common.GlobalKey && n > 0 && n == current.offset {
+			global := append(append(path[:n-1:n-1], common.GlobalKey), path[n+1:]...)
+			source, err := traceSource(ch, global, value, userTrace)
+			if err != nil || source != "" {
+				return source, err
+			}
+			break
+		}
+		var next chart.Accessor
+		for _, dep := range current.chart.Dependencies() {
+			sub, err := chart.NewAccessor(dep)
+			if err != nil {
+				return "", err
+			}
+			if sub.Name() == path[n] {
+				next = sub
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		owners = append(owners, owner{next, n + 1})
+	}
+
+	// Processing the dependencies of a chart copies the values of its subcharts
+	// into its own, so the value comes from the innermost chart setting it.
+	origin := -1
+	for n, o := range owners {
+		if v, ok := valueAt(o.chart.Values(), path[o.offset:]); ok && reflect.DeepEqual(v, value) {
+			origin = n
+		}
+	}
+	if origin < 0 {
+		return "", nil
+	}
+	if origin < len(owners)-1 {
+		return fmt.Sprintf("parent chart (%s)", owners[origin].chart.Name()), nil
+	}
+	return fmt.Sprintf("chart default (%s)", owners[origin].chart.Name()), nil
+}
+
+// valueAt returns the value at path in vals.
+func valueAt(vals map[string]any, path []string) (any, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+	for _, key := range path[:len(path)-1] {
+		next, ok := vals[key].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		vals = next
+	}
+	v, ok := vals[path[len(path)-1]]
+	return v, ok
+}
+
+func tracePath(path []string) string {
+	return strings.Join(path, ".")
+}
+
+// AnnotateValues marshals vals to YAML with the source of each value in trace
+// as a comment.
+func AnnotateValues(vals map[string]any, trace ValuesTrace) ([]byte, error) {
+	data, err := yaml.Marshal(vals)
+	if err != nil {
+		return nil, err
+	}
+	var doc goYaml.Node
+	if err := goYaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return data, nil
+	}
+
+	var annotate func(path []string, node *goYaml.Node)
+	annotate = func(path []string, node *goYaml.Node) {
+		for n := 0; n+1 < len(node.Content); n += 2 {
+			key, val := node.Content[n], node.Content[n+1]
+			p := append(path[:len(path):len(path)], key.Value)
+			if val.Kind == goYaml.MappingNode && len(val.Content) > 0 {
+				annotate(p, val)
+				continue
+			}
+			source, ok := trace.lookup(p)
+			if !ok || source == "" {
+				continue
+			}
+			// Comments on block lists only render on their key
+			if val.Kind == goYaml.SequenceNode && len(val.Content) > 0 {
+				key.LineComment = "# " + source
+			} else {
+				val.LineComment = "# " + source
+			}
+		}
+	}
+	annotate(nil, doc.Content[0])
+
+	var buf bytes.Buffer
+	enc := goYaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
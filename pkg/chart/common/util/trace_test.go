@@ -0,0 +1,112 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestValuesTraceRecord(t *testing.T) {
+	trace := ValuesTrace{}
+	trace.Record(map[string]any{
+		"image":   map[string]any{"repository": "nginx", "tag": "1.27"},
+		"hosts":   []any{"a.example.com"},
+		"labels":  map[string]any{},
+		"enabled": nil,
+	}, "-f values.yaml (file 1)")
+	trace.Record(map[string]any{"image": map[string]any{"tag": "1.28"}}, "--set")
+	// Setting a scalar replaces the sources of the map it overrides
+	trace.Record(map[string]any{"labels": "none"}, "--set-string")
+	trace.Set([]string{"hosts", "extra"}, "--set-jsonpatch")
+
+	assert.Equal(t, ValuesTrace{
+		"image.repository": "-f values.yaml (file 1)",
+		"image.tag":        "--set",
+		"hosts.extra":      "--set-jsonpatch",
+		"labels":           "--set-string",
+		"enabled":          "-f values.yaml (file 1)",
+	}, trace)
+
+	trace.Merge(map[string]string{"image": "reuse-values"})
+	assert.Equal(t, ValuesTrace{
+		"image":       "reuse-values",
+		"hosts.extra": "--set-jsonpatch",
+		"labels":      "--set-string",
+		"enabled":     "-f values.yaml (file 1)",
+	}, trace)
+}
+
+func TestTraceValues(t *testing.T) {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "sub", Version: "0.1.0", APIVersion: chart.APIVersionV2},
+		Values: map[string]any{
+			"port":   80,
+			"name":   "sub",
+			"global": map[string]any{"region": "eu"},
+		},
+	}
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "parent", Version: "0.1.0", APIVersion: chart.APIVersionV2},
+		Values: map[string]any{
+			"replicas": 1,
+			"sub":      map[string]any{"name": "from-parent"},
+			"global":   map[string]any{"env": "prod"},
+		},
+	}
+	parent.AddDependency(sub)
+
+	trace, err := TraceValues(parent, map[string]any{"replicas": 3}, ValuesTrace{"replicas": "--set"})
+	require.NoError(t, err)
+	assert.Equal(t, ValuesTrace{
+		"replicas":          "--set",
+		"global.env":        "chart default (parent)",
+		"sub.port":          "chart default (sub)",
+		"sub.name":          "parent chart (parent)",
+		"sub.global.env":    "chart default (parent)",
+		"sub.global.region": "chart default (sub)",
+	}, trace)
+}
+
+func TestAnnotateValues(t *testing.T) {
+	vals := map[string]any{
+		"image": map[string]any{"tag": "1.27"},
+		"hosts": []any{"a.example.com", "b.example.com"},
+		"empty": map[string]any{},
+		"other": "untraced",
+	}
+	trace := ValuesTrace{
+		"image.tag": "--set",
+		"hosts":     "-f values.yaml (file 1)",
+		"empty":     "chart default (mychart)",
+	}
+
+	data, err := AnnotateValues(vals, trace)
+	require.NoError(t, err)
+	assert.Equal(t, `empty: {} # chart default (mychart)
+hosts: # -f values.yaml (file 1)
+  - a.example.com
+  - b.example.com
+image:
+  tag: "1.27" # --set
+other: untraced
+`, string(data))
+}
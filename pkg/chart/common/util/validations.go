@@ -0,0 +1,170 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"sigs.k8s.io/yaml"
+
+	chart "helm.sh/helm/v4/pkg/chart"
+)
+
+// ValidationsFile is the file of a chart holding validation rules, in addition
+// to the validations of its Chart.yaml.
+const ValidationsFile = "validations.yaml"
+
+// validationCostLimit bounds the evaluation cost of a validation rule, so that
+// a rule cannot make an install hang.
+const validationCostLimit = 1000000
+
+// validationRule is a CEL rule the values of a chart must satisfy.
+type validationRule struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message,omitempty"`
+}
+
+// ValidateAgainstRules checks that values satisfy the CEL validation rules of a
+// chart and its dependencies. The rules are declared with `validations` in
+// Chart.yaml, or in a validations.yaml file, and can use `values`, the values
+// of the chart, and `release`, the release options:
+//
+//	validations:
+//	  - rule: "!values.ingress.enabled || values.ingress.host != ''"
+//	    message: ingress.host is required when the ingress is enabled
+func ValidateAgainstRules(ch chart.Charter, values map[string]any, release map[string]any) error {
+	accessor, err := chart.NewAccessor(ch)
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	rules, err := chartRules(accessor)
+	if err != nil {
+		fmt.Fprintf(&sb, "%s:\n- %s\n", accessor.Name(), err)
+	}
+	if len(rules) > 0 {
+		if failed := evalRules(rules, values, release); len(failed) > 0 {
+			fmt.Fprintf(&sb, "%s:\n", accessor.Name())
+			for _, msg := range failed {
+				fmt.Fprintf(&sb, "- %s\n", msg)
+			}
+		}
+	}
+
+	for _, subchart := range accessor.Dependencies() {
+		sub, err := chart.NewAccessor(subchart)
+		if err != nil {
+			return err
+		}
+		subchartValues, ok := values[sub.Name()].(map[string]any)
+		if !ok {
+			// Missing or invalid values of a subchart are reported by the
+			// schema validation
+			continue
+		}
+		if err := ValidateAgainstRules(subchart, subchartValues, release); err != nil {
+			sb.WriteString(err.Error())
+		}
+	}
+
+	if sb.Len() > 0 {
+		return errors.New(sb.String())
+	}
+	return nil
+}
+
+// chartRules returns the validation rules of Chart.yaml followed by those of
+// validations.yaml.
+func chartRules(accessor chart.Accessor) ([]validationRule, error) {
+	var rules []validationRule
+	validations, _ := accessor.MetadataAsMap()["Validations"].([]any)
+	for _, v := range validations {
+		m, _ := v.(map[string]any)
+		rule, _ := m["Rule"].(string)
+		msg, _ := m["Message"].(string)
+		rules = append(rules, validationRule{Rule: rule, Message: msg})
+	}
+	for _, f := range accessor.Files() {
+		if f.Name != ValidationsFile {
+			continue
+		}
+		var fileRules []validationRule
+		if err := yaml.UnmarshalStrict(f.Data, &fileRules); err != nil {
+			return rules, fmt.Errorf("unable to parse %s: %w", ValidationsFile, err)
+		}
+		rules = append(rules, fileRules...)
+	}
+	return rules, nil
+}
+
+// evalRules returns the messages of the rules the values do not satisfy.
+func evalRules(rules []validationRule, values, release map[string]any) []string {
+	env, err := cel.NewEnv(
+		cel.Variable("values", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("release", cel.MapType(cel.StringType, cel.DynType)),
+		ext.Strings(),
+	)
+	if err != nil {
+		return []string{fmt.Sprintf("unable to create the CEL environment: %s", err)}
+	}
+	if values == nil {
+		values = map[string]any{}
+	}
+	vars := map[string]any{"values": values, "release": release}
+
+	var failed []string
+	for _, r := range rules {
+		ok, err := evalRule(env, r.Rule, vars)
+		switch {
+		case err != nil:
+			failed = append(failed, fmt.Sprintf("rule %q: %s", r.Rule, err))
+		case !ok && r.Message != "":
+			failed = append(failed, r.Message)
+		case !ok:
+			failed = append(failed, fmt.Sprintf("failed rule %q", r.Rule))
+		}
+	}
+	return failed
+}
+
+// evalRule evaluates a rule, which must evaluate to a bool.
+func evalRule(env *cel.Env, rule string, vars map[string]any) (bool, error) {
+	ast, issues := env.Compile(rule)
+	if issues.Err() != nil {
+		return false, issues.Err()
+	}
+	if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+		return false, fmt.Errorf("must evaluate to a bool, not %s", t)
+	}
+	prg, err := env.Program(ast, cel.CostLimit(validationCostLimit))
+	if err != nil {
+		return false, err
+	}
+	out, _, err := prg.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	ok, isBool := out.Value().(bool)
+	if !isBool {
+		return false, fmt.Errorf("must evaluate to a bool, not %s", out.Type())
+	}
+	return ok, nil
+}
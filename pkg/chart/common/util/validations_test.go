@@ -0,0 +1,134 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestValidateAgainstRules(t *testing.T) {
+	ingressRule := &chart.Validation{
+		Rule:    "!values.ingress.enabled || values.ingress.host != ''",
+		Message: "ingress.host is required when the ingress is enabled",
+	}
+
+	tests := []struct {
+		name        string
+		validations []*chart.Validation
+		file        string
+		values      map[string]any
+		release     map[string]any
+		expectError string
+	}{
+		{
+			name:        "rule satisfied",
+			validations: []*chart.Validation{ingressRule},
+			values:      map[string]any{"ingress": map[string]any{"enabled": true, "host": "example.com"}},
+		},
+		{
+			name:        "rule not satisfied",
+			validations: []*chart.Validation{ingressRule},
+			values:      map[string]any{"ingress": map[string]any{"enabled": true, "host": ""}},
+			expectError: "chrt:\n- ingress.host is required when the ingress is enabled\n",
+		},
+		{
+			name:        "rule without message",
+			validations: []*chart.Validation{{Rule: "values.replicas <= 3"}},
+			values:      map[string]any{"replicas": 5},
+			expectError: "chrt:\n- failed rule \"values.replicas <= 3\"\n",
+		},
+		{
+			name:        "rule of validations.yaml",
+			file:        "- rule: values.replicas <= 3\n  message: at most 3 replicas\n",
+			values:      map[string]any{"replicas": 5},
+			expectError: "chrt:\n- at most 3 replicas\n",
+		},
+		{
+			name:        "invalid validations.yaml",
+			file:        "rule: values.replicas <= 3\n",
+			expectError: "unable to parse validations.yaml",
+		},
+		{
+			name:        "rule using the release",
+			validations: []*chart.Validation{{Rule: "!release.IsUpgrade || !has(values.storageClass)", Message: "storageClass cannot be changed"}},
+			values:      map[string]any{"storageClass": "fast"},
+			release:     map[string]any{"IsUpgrade": true},
+			expectError: "storageClass cannot be changed",
+		},
+		{
+			name:        "missing value",
+			validations: []*chart.Validation{{Rule: "values.missing == 1"}},
+			expectError: `rule "values.missing == 1": no such key: missing`,
+		},
+		{
+			name:        "invalid rule",
+			validations: []*chart.Validation{{Rule: "values.replicas <="}},
+			expectError: `rule "values.replicas <=": ERROR`,
+		},
+		{
+			name:        "rule not evaluating to a bool",
+			validations: []*chart.Validation{{Rule: "'replicas'"}},
+			expectError: "must evaluate to a bool, not string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &chart.Chart{
+				Metadata: &chart.Metadata{Name: "chrt", Version: "0.1.0", Validations: tt.validations},
+			}
+			if tt.file != "" {
+				c.Files = []*common.File{{Name: ValidationsFile, Data: []byte(tt.file)}}
+			}
+			release := tt.release
+			if release == nil {
+				release = map[string]any{"IsUpgrade": false}
+			}
+
+			err := ValidateAgainstRules(c, tt.values, release)
+			if tt.expectError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
+
+func TestValidateAgainstRulesSubchart(t *testing.T) {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:        "sub",
+			Version:     "0.1.0",
+			Validations: []*chart.Validation{{Rule: "values.port > 1024", Message: "port must be unprivileged"}},
+		},
+	}
+	c := &chart.Chart{Metadata: &chart.Metadata{Name: "chrt", Version: "0.1.0"}}
+	c.AddDependency(sub)
+
+	require.NoError(t, ValidateAgainstRules(c, map[string]any{"sub": map[string]any{"port": 8080}}, nil))
+
+	err := ValidateAgainstRules(c, map[string]any{"sub": map[string]any{"port": 80}}, nil)
+	require.Error(t, err)
+	assert.Equal(t, "sub:\n- port must be unprivileged\n", err.Error())
+}
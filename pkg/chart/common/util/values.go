@@ -63,6 +63,9 @@ func ToRenderValuesWithSchemaValidation(chrt chart.Charter, chrtVals map[string]
 		if err := ValidateAgainstSchema(chrt, vals); err != nil {
 			return top, fmt.Errorf("values don't meet the specifications of the schema(s) in the following chart(s):\n%w", err)
 		}
+		if err := ValidateAgainstRules(chrt, vals, top["Release"].(map[string]any)); err != nil {
+			return top, fmt.Errorf("values don't meet the validation rules of the following chart(s):\n%w", err)
+		}
 	}
 
 	top["Values"] = vals
@@ -17,16 +17,42 @@ limitations under the License.
 package lint // import "helm.sh/helm/v4/pkg/chart/v2/lint"
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
 	"path/filepath"
 
+	"sigs.k8s.io/yaml"
+
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/rules"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
 )
 
+// ConfigFile is the file of a chart configuring the lint rules, e.g.
+//
+//	rules:
+//	  chart-icon: disabled
+//	  template-deprecated-api: error
+const ConfigFile = ".helmlintrc"
+
+// DefaultRegistry holds the rules of Helm and the rules added with Register.
+var DefaultRegistry = support.NewRegistry(rules.Builtin()...)
+
+// Register adds a rule to the default registry, checked for every chart
+// linted after the rules of Helm.
+func Register(rule support.Rule) error {
+	return DefaultRegistry.Register(rule)
+}
+
 type linterOptions struct {
 	KubeVersion          *common.KubeVersion
 	SkipSchemaValidation bool
+	Registry             *support.Registry
+	RuleConfig           support.RuleConfig
+	KubeLint             bool
 }
 
 type LinterOption func(lo *linterOptions)
@@ -43,10 +69,33 @@ func WithSkipSchemaValidation(skipSchemaValidation bool) LinterOption {
 	}
 }
 
+// WithRegistry sets the registry of the rules, instead of DefaultRegistry.
+func WithRegistry(registry *support.Registry) LinterOption {
+	return func(lo *linterOptions) {
+		lo.Registry = registry
+	}
+}
+
+// WithRuleConfig overrides the severity of rules, over the configuration
+// file of the chart.
+func WithRuleConfig(config support.RuleConfig) LinterOption {
+	return func(lo *linterOptions) {
+		lo.RuleConfig = config
+	}
+}
+
+// WithKubeLint checks the rendered resources against the best practices of
+// Kubernetes: resource limits, probes, image tags and privileged containers.
+func WithKubeLint(kubeLint bool) LinterOption {
+	return func(lo *linterOptions) {
+		lo.KubeLint = kubeLint
+	}
+}
+
 func RunAll(baseDir string, values map[string]any, namespace string, options ...LinterOption) support.Linter {
 	chartDir, _ := filepath.Abs(baseDir)
 
-	lo := linterOptions{}
+	lo := linterOptions{Registry: DefaultRegistry}
 	for _, option := range options {
 		option(&lo)
 	}
@@ -55,16 +104,54 @@ func RunAll(baseDir string, values map[string]any, namespace string, options ...
 		ChartDir: chartDir,
 	}
 
+	config, err := LoadRuleConfig(filepath.Join(chartDir, ConfigFile))
+	if err == nil {
+		err = lo.Registry.Validate(config)
+	}
+	if !result.RunLinterRule(support.ErrorSev, ConfigFile, err) {
+		return result
+	}
+	maps.Copy(config, lo.RuleConfig)
+	result.RuleConfig = config
+
 	rules.Chartfile(&result)
 	rules.ValuesWithOverrides(&result, values, lo.SkipSchemaValidation)
+	rules.ValuesSchema(&result, values, lo.SkipSchemaValidation)
 	rules.Templates(
 		&result,
 		namespace,
 		values,
 		rules.TemplateLinterKubeVersion(lo.KubeVersion),
-		rules.TemplateLinterSkipSchemaValidation(lo.SkipSchemaValidation))
+		rules.TemplateLinterSkipSchemaValidation(lo.SkipSchemaValidation),
+		rules.TemplateLinterKubeLint(lo.KubeLint))
 	rules.Dependencies(&result)
 	rules.Crds(&result)
 
+	for _, rule := range lo.Registry.Rules() {
+		if rule.Check != nil {
+			rule.Check(&result, rule)
+		}
+	}
+
 	return result
 }
+
+// LoadRuleConfig reads a lint configuration file. A missing file configures
+// no rules.
+func LoadRuleConfig(path string) (support.RuleConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return support.RuleConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Rules map[string]string `json:"rules"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", filepath.Base(path), err)
+	}
+	return support.ParseRuleConfig(file.Rules)
+}
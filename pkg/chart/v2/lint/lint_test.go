@@ -17,12 +17,16 @@ limitations under the License.
 package lint
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"helm.sh/helm/v4/pkg/chart/v2/lint/rules"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 )
@@ -172,6 +176,29 @@ func TestHelmCreateChart(t *testing.T) {
 	}
 }
 
+// TestHelmCreateScaffolds tests that the charts of every scaffold of
+// `helm create --type` pass a `helm lint` test.
+func TestHelmCreateScaffolds(t *testing.T) {
+	for _, scaffold := range chartutil.Scaffolds {
+		t.Run(scaffold, func(t *testing.T) {
+			createdChart, err := chartutil.CreateScaffold("testhelmcreatepasseslint", t.TempDir(), scaffold)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m := RunAll(createdChart, nil, namespace, WithSkipSchemaValidation(true)).Messages
+			if ll := len(m); ll != 1 {
+				t.Errorf("All should have had exactly 1 error. Got %d", ll)
+				for i, msg := range m {
+					t.Logf("Message %d: %s", i, msg.Error())
+				}
+			} else if msg := m[0].Err.Error(); !strings.Contains(msg, "icon is recommended") {
+				t.Errorf("Unexpected lint error: %s", msg)
+			}
+		})
+	}
+}
+
 // TestHelmCreateChart_CheckDeprecatedWarnings checks if any default template created by `helm create` throws
 // deprecated warnings in the linter check against the current Kubernetes version (provided using ldflags).
 //
@@ -245,3 +272,59 @@ func TestMalformedTemplate(t *testing.T) {
 		}
 	}
 }
+
+func TestRunAllRuleConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Chart.yaml"), "apiVersion: v2\nname: ruleconfig\nversion: 0.1.0\n")
+	writeFile(t, filepath.Join(dir, "values.yaml"), "")
+	writeFile(t, filepath.Join(dir, ConfigFile), "rules:\n  chart-icon: error\n  templates-dir-exists: disabled\n")
+
+	linter := RunAll(dir, nil, namespace)
+	if assert.Len(t, linter.Messages, 1) {
+		assert.Equal(t, support.ErrorSev, linter.Messages[0].Severity)
+		assert.Contains(t, linter.Messages[0].Err.Error(), "icon is recommended")
+	}
+
+	// The configuration of the run takes precedence over the file.
+	linter = RunAll(dir, nil, namespace, WithRuleConfig(support.RuleConfig{"chart-icon": support.DisabledSev}))
+	assert.Empty(t, linter.Messages)
+
+	writeFile(t, filepath.Join(dir, ConfigFile), "rules:\n  chart-icons: error\n")
+	linter = RunAll(dir, nil, namespace)
+	if assert.Len(t, linter.Messages, 1) {
+		assert.Equal(t, "[ERROR] .helmlintrc: unknown lint rules: chart-icons", linter.Messages[0].Error())
+	}
+}
+
+func TestRunAllCustomRule(t *testing.T) {
+	registry := support.NewRegistry(rules.Builtin()...)
+	err := registry.Register(support.Rule{
+		ID:       "chart-has-readme",
+		Severity: support.WarningSev,
+		Check: func(l *support.Linter, rule support.Rule) {
+			_, err := os.Stat(filepath.Join(l.ChartDir, "README.md"))
+			l.RunRule(rule, "README.md", err)
+		},
+	})
+	require.NoError(t, err)
+
+	linter := RunAll(goodChartDir, nil, namespace, WithRegistry(registry))
+	var found bool
+	for _, msg := range linter.Messages {
+		if msg.Path == "README.md" {
+			found = true
+			assert.Equal(t, support.WarningSev, msg.Severity)
+		}
+	}
+	assert.True(t, found, "expected a message of the custom rule, got %v", linter.Messages)
+
+	linter = RunAll(goodChartDir, nil, namespace, WithRegistry(registry), WithRuleConfig(support.RuleConfig{"chart-has-readme": support.DisabledSev}))
+	for _, msg := range linter.Messages {
+		assert.NotEqual(t, "README.md", msg.Path)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
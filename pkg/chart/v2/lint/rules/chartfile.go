@@ -36,10 +36,10 @@ func Chartfile(linter *support.Linter) {
 	chartFileName := "Chart.yaml"
 	chartPath := filepath.Join(linter.ChartDir, chartFileName)
 
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartYamlNotDirectory(chartPath))
+	linter.RunRule(chartYamlNotDirectoryRule, chartFileName, validateChartYamlNotDirectory(chartPath))
 
 	chartFile, err := chartutil.LoadChartfile(chartPath)
-	validChartFile := linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartYamlFormat(err))
+	validChartFile := linter.RunRule(chartYamlFormatRule, chartFileName, validateChartYamlFormat(err))
 
 	// Guard clause. Following linter rules require a parsable ChartFile
 	if !validChartFile {
@@ -47,27 +47,27 @@ func Chartfile(linter *support.Linter) {
 	}
 
 	_, err = chartutil.StrictLoadChartfile(chartPath)
-	linter.RunLinterRule(support.WarningSev, chartFileName, validateChartYamlStrictFormat(err))
+	linter.RunRule(chartYamlStrictFormatRule, chartFileName, validateChartYamlStrictFormat(err))
 
 	// type check for Chart.yaml . ignoring error as any parse
 	// errors would already be caught in the above load function
 	chartFileForTypeCheck, _ := loadChartFileForTypeCheck(chartPath)
 
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartName(chartFile))
+	linter.RunRule(chartNameRule, chartFileName, validateChartName(chartFile))
 
 	// Chart metadata
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartAPIVersion(chartFile))
-
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartVersionType(chartFileForTypeCheck))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartVersion(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartAppVersionType(chartFileForTypeCheck))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartMaintainer(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartSources(chartFile))
-	linter.RunLinterRule(support.InfoSev, chartFileName, validateChartIconPresence(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartIconURL(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartType(chartFile))
-	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartDependencies(chartFile))
-	linter.RunLinterRule(support.WarningSev, chartFileName, validateChartVersionStrictSemVerV2(chartFile))
+	linter.RunRule(chartAPIVersionRule, chartFileName, validateChartAPIVersion(chartFile))
+
+	linter.RunRule(chartVersionTypeRule, chartFileName, validateChartVersionType(chartFileForTypeCheck))
+	linter.RunRule(chartVersionRule, chartFileName, validateChartVersion(chartFile))
+	linter.RunRule(chartAppVersionTypeRule, chartFileName, validateChartAppVersionType(chartFileForTypeCheck))
+	linter.RunRule(chartMaintainerRule, chartFileName, validateChartMaintainer(chartFile))
+	linter.RunRule(chartSourcesRule, chartFileName, validateChartSources(chartFile))
+	linter.RunRule(chartIconRule, chartFileName, validateChartIconPresence(chartFile))
+	linter.RunRule(chartIconURLRule, chartFileName, validateChartIconURL(chartFile))
+	linter.RunRule(chartTypeRule, chartFileName, validateChartType(chartFile))
+	linter.RunRule(chartDependenciesRule, chartFileName, validateChartDependencies(chartFile))
+	linter.RunRule(chartVersionSemVerV2Rule, chartFileName, validateChartVersionStrictSemVerV2(chartFile))
 }
 
 func validateChartVersionType(data map[string]any) error {
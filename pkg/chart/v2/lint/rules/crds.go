@@ -41,7 +41,7 @@ func Crds(linter *support.Linter) {
 		return
 	}
 
-	crdsDirValid := linter.RunLinterRule(support.ErrorSev, fpath, validateCrdsDir(crdsPath))
+	crdsDirValid := linter.RunRule(crdsDirRule, fpath, validateCrdsDir(crdsPath))
 	if !crdsDirValid {
 		return
 	}
@@ -49,7 +49,7 @@ func Crds(linter *support.Linter) {
 	// Load chart and parse CRDs
 	chart, err := loader.Load(linter.ChartDir)
 
-	chartLoaded := linter.RunLinterRule(support.ErrorSev, fpath, err)
+	chartLoaded := linter.RunRule(chartLoadRule, fpath, err)
 
 	if !chartLoaded {
 		return
@@ -75,13 +75,13 @@ func Crds(linter *support.Linter) {
 
 			// If YAML parsing fails here, it will always fail in the next block as well, so we should return here.
 			// This also confirms the YAML is not a template, since templates can't be decoded into a K8sYamlStruct.
-			if !linter.RunLinterRule(support.ErrorSev, fpath, validateYamlContent(err)) {
+			if !linter.RunRule(crdYamlRule, fpath, validateYamlContent(err)) {
 				return
 			}
 
 			if yamlStruct != nil {
-				linter.RunLinterRule(support.ErrorSev, fpath, validateCrdAPIVersion(yamlStruct))
-				linter.RunLinterRule(support.ErrorSev, fpath, validateCrdKind(yamlStruct))
+				linter.RunRule(crdAPIVersionRule, fpath, validateCrdAPIVersion(yamlStruct))
+				linter.RunRule(crdKindRule, fpath, validateCrdKind(yamlStruct))
 			}
 		}
 	}
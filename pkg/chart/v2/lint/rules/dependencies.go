@@ -30,13 +30,13 @@ import (
 // See https://github.com/helm/helm/issues/7910
 func Dependencies(linter *support.Linter) {
 	c, err := loader.LoadDir(linter.ChartDir)
-	if !linter.RunLinterRule(support.ErrorSev, "", validateChartFormat(err)) {
+	if !linter.RunRule(chartLoadRule, "", validateChartFormat(err)) {
 		return
 	}
 
-	linter.RunLinterRule(support.ErrorSev, linter.ChartDir, validateDependencyInMetadata(c))
-	linter.RunLinterRule(support.ErrorSev, linter.ChartDir, validateDependenciesUnique(c))
-	linter.RunLinterRule(support.WarningSev, linter.ChartDir, validateDependencyInChartsDir(c))
+	linter.RunRule(dependencyMetaRule, linter.ChartDir, validateDependencyInMetadata(c))
+	linter.RunRule(dependencyUniqRule, linter.ChartDir, validateDependenciesUnique(c))
+	linter.RunRule(dependencyDirRule, linter.ChartDir, validateDependencyInChartsDir(c))
 }
 
 func validateChartFormat(chartError error) error {
@@ -0,0 +1,157 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// TemplateLinterKubeLint checks the rendered resources against the best
+// practices of Kubernetes.
+func TemplateLinterKubeLint(kubeLint bool) TemplateLinterOption {
+	return func(tl *templateLinter) {
+		tl.kubeLint = kubeLint
+	}
+}
+
+// workload is a rendered resource that may run pods.
+type workload struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		v1.PodSpec
+		Template *struct {
+			Spec v1.PodSpec `json:"spec"`
+		} `json:"template"`
+		JobTemplate *struct {
+			Spec struct {
+				Template struct {
+					Spec v1.PodSpec `json:"spec"`
+				} `json:"template"`
+			} `json:"spec"`
+		} `json:"jobTemplate"`
+	} `json:"spec"`
+}
+
+// podSpec returns the spec of the pods the workload runs, if any.
+func (w *workload) podSpec() *v1.PodSpec {
+	switch w.Kind {
+	case "Pod":
+		return &w.Spec.PodSpec
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		if w.Spec.Template != nil {
+			return &w.Spec.Template.Spec
+		}
+	case "CronJob":
+		if w.Spec.JobTemplate != nil {
+			return &w.Spec.JobTemplate.Spec.Template.Spec
+		}
+	}
+	return nil
+}
+
+// runsToCompletion returns whether the pods of the workload exit when done,
+// and so need no probes.
+func (w *workload) runsToCompletion() bool {
+	return w.Kind == "Job" || w.Kind == "CronJob"
+}
+
+// lintKubeBestPractices checks the workloads rendered by a template against
+// the best practices of Kubernetes.
+func (t *templateLinter) lintKubeBestPractices(fileName, renderedContent string) {
+	for _, manifest := range releaseutil.SplitManifests(renderedContent) {
+		var w workload
+		if err := yaml.Unmarshal([]byte(manifest), &w); err != nil {
+			// Invalid resources are reported by the other rules.
+			continue
+		}
+		spec := w.podSpec()
+		if spec == nil {
+			continue
+		}
+		resource := fmt.Sprintf("%s/%s", w.Kind, w.Metadata.Name)
+
+		for _, c := range append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...) {
+			t.linter.RunRule(kubeResourceLimitsRule, fileName, validateResourceLimits(resource, c))
+			t.linter.RunRule(kubeImageTagRule, fileName, validateImageTag(resource, c))
+			t.linter.RunRule(kubePrivilegedRule, fileName, validateNotPrivileged(resource, c))
+		}
+		if !w.runsToCompletion() {
+			for _, c := range spec.Containers {
+				t.linter.RunRule(kubeProbesRule, fileName, validateProbes(resource, c))
+			}
+		}
+	}
+}
+
+func validateResourceLimits(resource string, c v1.Container) error {
+	var missing []string
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		if _, ok := c.Resources.Limits[name]; !ok {
+			missing = append(missing, string(name))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("container %q of %s has no %s limit", c.Name, resource, strings.Join(missing, " or "))
+	}
+	return nil
+}
+
+func validateProbes(resource string, c v1.Container) error {
+	var missing []string
+	if c.LivenessProbe == nil {
+		missing = append(missing, "liveness")
+	}
+	if c.ReadinessProbe == nil {
+		missing = append(missing, "readiness")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("container %q of %s has no %s probe", c.Name, resource, strings.Join(missing, " or "))
+	}
+	return nil
+}
+
+func validateImageTag(resource string, c v1.Container) error {
+	image := c.Image
+	if strings.Contains(image, "@") {
+		// Pinned by digest.
+		return nil
+	}
+	tag := ""
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		tag = image[i+1:]
+	}
+	if tag == "" || tag == "latest" {
+		return fmt.Errorf("container %q of %s uses the image %q: pin a tag other than latest, or a digest", c.Name, resource, image)
+	}
+	return nil
+}
+
+func validateNotPrivileged(resource string, c v1.Container) error {
+	if c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged {
+		return fmt.Errorf("container %q of %s is privileged", c.Name, resource)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestValidateImageTag(t *testing.T) {
+	for image, valid := range map[string]bool{
+		"nginx":                         false,
+		"nginx:latest":                  false,
+		"registry:5000/nginx":           false,
+		"registry:5000/nginx:1.27":      true,
+		"nginx:1.27":                    true,
+		"nginx@sha256:0123456789abcdef": true,
+	} {
+		err := validateImageTag("Pod/web", v1.Container{Name: "web", Image: image})
+		assert.Equal(t, valid, err == nil, "image %s: %v", image, err)
+	}
+}
+
+func TestValidateResourceLimits(t *testing.T) {
+	c := v1.Container{Name: "web"}
+	assert.EqualError(t, validateResourceLimits("Pod/web", c), `container "web" of Pod/web has no cpu or memory limit`)
+
+	c.Resources.Limits = v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")}
+	assert.EqualError(t, validateResourceLimits("Pod/web", c), `container "web" of Pod/web has no memory limit`)
+
+	c.Resources.Limits[v1.ResourceMemory] = resource.MustParse("128Mi")
+	assert.NoError(t, validateResourceLimits("Pod/web", c))
+}
+
+func TestValidateProbes(t *testing.T) {
+	c := v1.Container{Name: "web", ReadinessProbe: &v1.Probe{}}
+	assert.EqualError(t, validateProbes("Pod/web", c), `container "web" of Pod/web has no liveness probe`)
+
+	c.LivenessProbe = &v1.Probe{}
+	assert.NoError(t, validateProbes("Pod/web", c))
+}
+
+const kubeLintDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:latest
+          securityContext:
+            privileged: true
+`
+
+const kubeLintCronJob = `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  schedule: "@daily"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: backup
+              image: backup:1.0
+              resources:
+                limits:
+                  cpu: 100m
+                  memory: 64Mi
+`
+
+func TestTemplatesKubeLint(t *testing.T) {
+	modTime := time.Now()
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "kubelint", Version: "0.1.0"},
+		Templates: []*common.File{
+			{Name: "templates/deployment.yaml", ModTime: modTime, Data: []byte(kubeLintDeployment)},
+			{Name: "templates/cronjob.yaml", ModTime: modTime, Data: []byte(kubeLintCronJob)},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+	chartDir := filepath.Join(tmpdir, mychart.Name())
+
+	linter := support.Linter{ChartDir: chartDir}
+	Templates(&linter, namespace, nil)
+	assert.Empty(t, linter.Messages, "the best practices are only checked with kube lint")
+
+	linter = support.Linter{ChartDir: chartDir}
+	Templates(&linter, namespace, nil, TemplateLinterKubeLint(true))
+
+	var rules []string
+	for _, msg := range linter.Messages {
+		assert.Equal(t, "templates/deployment.yaml", msg.Path, msg.Error())
+		rules = append(rules, msg.RuleID)
+	}
+	assert.Equal(t, []string{"kube-resource-limits", "kube-image-tag", "kube-privileged", "kube-probes"}, rules)
+}
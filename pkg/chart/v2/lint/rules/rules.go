@@ -0,0 +1,89 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import "helm.sh/helm/v4/pkg/chart/v2/lint/support"
+
+// The lint rules of Helm. The rules guarding the rules after them, like
+// chart-load, still stop the linting of the chart when disabled. The kube-*
+// rules only run when the best practices of Kubernetes are linted.
+var (
+	chartYamlNotDirectoryRule = support.Rule{ID: "chart-yaml-not-directory", Severity: support.ErrorSev, Description: "Chart.yaml is a file"}
+	chartYamlFormatRule       = support.Rule{ID: "chart-yaml-format", Severity: support.ErrorSev, Description: "Chart.yaml is valid YAML"}
+	chartYamlStrictFormatRule = support.Rule{ID: "chart-yaml-strict-format", Severity: support.WarningSev, Description: "Chart.yaml has no unknown or duplicate fields"}
+	chartNameRule             = support.Rule{ID: "chart-name", Severity: support.ErrorSev, Description: "the chart has a valid name"}
+	chartAPIVersionRule       = support.Rule{ID: "chart-api-version", Severity: support.ErrorSev, Description: "the chart has a supported apiVersion"}
+	chartVersionTypeRule      = support.Rule{ID: "chart-version-type", Severity: support.ErrorSev, Description: "the version of the chart is a string"}
+	chartVersionRule          = support.Rule{ID: "chart-version", Severity: support.ErrorSev, Description: "the version of the chart is a semantic version"}
+	chartAppVersionTypeRule   = support.Rule{ID: "chart-app-version-type", Severity: support.ErrorSev, Description: "the appVersion of the chart is a string"}
+	chartMaintainerRule       = support.Rule{ID: "chart-maintainer", Severity: support.ErrorSev, Description: "the maintainers of the chart have a name and valid email and URL"}
+	chartSourcesRule          = support.Rule{ID: "chart-sources", Severity: support.ErrorSev, Description: "the sources of the chart are valid URLs"}
+	chartIconRule             = support.Rule{ID: "chart-icon", Severity: support.InfoSev, Description: "the chart has an icon"}
+	chartIconURLRule          = support.Rule{ID: "chart-icon-url", Severity: support.ErrorSev, Description: "the icon of the chart is a valid URL"}
+	chartTypeRule             = support.Rule{ID: "chart-type", Severity: support.ErrorSev, Description: "the type of the chart is application or library"}
+	chartDependenciesRule     = support.Rule{ID: "chart-dependencies", Severity: support.ErrorSev, Description: "the dependencies of the chart are valid"}
+	chartVersionSemVerV2Rule  = support.Rule{ID: "chart-version-semver-v2", Severity: support.WarningSev, Description: "the version of the chart is a strict SemVer 2 version"}
+
+	chartLoadRule = support.Rule{ID: "chart-load", Severity: support.ErrorSev, Description: "the chart loads"}
+
+	crdsDirRule        = support.Rule{ID: "crds-dir", Severity: support.ErrorSev, Description: "crds is a directory"}
+	crdYamlRule        = support.Rule{ID: "crd-yaml", Severity: support.ErrorSev, Description: "the CRDs are valid YAML"}
+	crdAPIVersionRule  = support.Rule{ID: "crd-api-version", Severity: support.ErrorSev, Description: "the CRDs have the apiVersion apiextensions.k8s.io/v1"}
+	crdKindRule        = support.Rule{ID: "crd-kind", Severity: support.ErrorSev, Description: "the CRDs have the kind CustomResourceDefinition"}
+	dependencyMetaRule = support.Rule{ID: "dependency-in-metadata", Severity: support.ErrorSev, Description: "the subcharts are dependencies of the chart"}
+	dependencyUniqRule = support.Rule{ID: "dependency-unique", Severity: support.ErrorSev, Description: "the dependencies have unique names or aliases"}
+	dependencyDirRule  = support.Rule{ID: "dependency-in-charts-dir", Severity: support.WarningSev, Description: "the dependencies are in the charts directory"}
+
+	templatesDirExistsRule     = support.Rule{ID: "templates-dir-exists", Severity: support.WarningSev, Description: "the chart has a templates directory"}
+	templatesDirRule           = support.Rule{ID: "templates-dir", Severity: support.ErrorSev, Description: "templates is a directory"}
+	templateValuesRule         = support.Rule{ID: "template-values", Severity: support.ErrorSev, Description: "the values validate against the schema of the chart"}
+	templateRenderRule         = support.Rule{ID: "template-render", Severity: support.ErrorSev, Description: "the templates render"}
+	templateExtensionRule      = support.Rule{ID: "template-extension", Severity: support.ErrorSev, Description: "the templates have a supported extension"}
+	templateIndentRule         = support.Rule{ID: "template-top-level-indent", Severity: support.WarningSev, Description: "the rendered templates are not indented at the top level"}
+	templateYamlRule           = support.Rule{ID: "template-yaml", Severity: support.ErrorSev, Description: "the rendered templates are valid YAML"}
+	templateMetadataNameRule   = support.Rule{ID: "template-metadata-name", Severity: support.WarningSev, Description: "the resources have valid names"}
+	templateDeprecatedAPIRule  = support.Rule{ID: "template-deprecated-api", Severity: support.WarningSev, Description: "the resources use no deprecated API"}
+	templateMatchSelectorRule  = support.Rule{ID: "template-match-selector", Severity: support.ErrorSev, Description: "the workloads have a selector"}
+	templateListAnnotationRule = support.Rule{ID: "template-list-annotations", Severity: support.ErrorSev, Description: "the lists have no annotations in their metadata"}
+
+	kubeResourceLimitsRule = support.Rule{ID: "kube-resource-limits", Severity: support.WarningSev, Description: "the containers have CPU and memory limits (--kube-lint)"}
+	kubeProbesRule         = support.Rule{ID: "kube-probes", Severity: support.WarningSev, Description: "the containers of long-running workloads have liveness and readiness probes (--kube-lint)"}
+	kubeImageTagRule       = support.Rule{ID: "kube-image-tag", Severity: support.WarningSev, Description: "the images of the containers have a tag other than latest, or a digest (--kube-lint)"}
+	kubePrivilegedRule     = support.Rule{ID: "kube-privileged", Severity: support.WarningSev, Description: "the containers are not privileged (--kube-lint)"}
+
+	valuesFileExistsRule = support.Rule{ID: "values-file-exists", Severity: support.InfoSev, Description: "the chart has a values.yaml file"}
+	valuesFileRule       = support.Rule{ID: "values-file", Severity: support.ErrorSev, Description: "the values are valid and validate against the schema of the chart"}
+
+	valuesSchemaDefaultsRule       = support.Rule{ID: "values-schema-defaults", Severity: support.ErrorSev, Description: "the default values validate against the schema of the chart"}
+	valuesSchemaMissingDefaultRule = support.Rule{ID: "values-schema-missing-default", Severity: support.InfoSev, Description: "the properties of the schema of the chart have a default in values.yaml"}
+	valuesUndefinedReferenceRule   = support.Rule{ID: "values-undefined-reference", Severity: support.WarningSev, Description: "the templates only reference values in values.yaml or the schema of the chart"}
+)
+
+// Builtin returns the lint rules of Helm.
+func Builtin() []support.Rule {
+	return []support.Rule{
+		chartYamlNotDirectoryRule, chartYamlFormatRule, chartYamlStrictFormatRule, chartNameRule, chartAPIVersionRule,
+		chartVersionTypeRule, chartVersionRule, chartAppVersionTypeRule, chartMaintainerRule, chartSourcesRule, chartIconRule,
+		chartIconURLRule, chartTypeRule, chartDependenciesRule, chartVersionSemVerV2Rule, chartLoadRule,
+		crdsDirRule, crdYamlRule, crdAPIVersionRule, crdKindRule, dependencyMetaRule, dependencyUniqRule, dependencyDirRule,
+		templatesDirExistsRule, templatesDirRule, templateValuesRule, templateRenderRule, templateExtensionRule, templateIndentRule,
+		templateYamlRule, templateMetadataNameRule, templateDeprecatedAPIRule, templateMatchSelectorRule, templateListAnnotationRule,
+		kubeResourceLimitsRule, kubeProbesRule, kubeImageTagRule, kubePrivilegedRule,
+		valuesFileExistsRule, valuesFileRule,
+		valuesSchemaDefaultsRule, valuesSchemaMissingDefaultRule, valuesUndefinedReferenceRule,
+	}
+}
@@ -82,18 +82,19 @@ type templateLinter struct {
 	namespace            string
 	kubeVersion          *common.KubeVersion
 	skipSchemaValidation bool
+	kubeLint             bool
 }
 
 func (t *templateLinter) Lint() {
 	templatesDir := "templates/"
 	templatesPath := filepath.Join(t.linter.ChartDir, templatesDir)
 
-	templatesDirExists := t.linter.RunLinterRule(support.WarningSev, templatesDir, templatesDirExists(templatesPath))
+	templatesDirExists := t.linter.RunRule(templatesDirExistsRule, templatesDir, templatesDirExists(templatesPath))
 	if !templatesDirExists {
 		return
 	}
 
-	validTemplatesDir := t.linter.RunLinterRule(support.ErrorSev, templatesDir, validateTemplatesDir(templatesPath))
+	validTemplatesDir := t.linter.RunRule(templatesDirRule, templatesDir, validateTemplatesDir(templatesPath))
 	if !validTemplatesDir {
 		return
 	}
@@ -101,7 +102,7 @@ func (t *templateLinter) Lint() {
 	// Load chart and parse templates
 	chart, err := loader.Load(t.linter.ChartDir)
 
-	chartLoaded := t.linter.RunLinterRule(support.ErrorSev, templatesDir, err)
+	chartLoaded := t.linter.RunRule(chartLoadRule, templatesDir, err)
 
 	if !chartLoaded {
 		return
@@ -130,14 +131,14 @@ func (t *templateLinter) Lint() {
 
 	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(chart, cvals, options, caps, t.skipSchemaValidation)
 	if err != nil {
-		t.linter.RunLinterRule(support.ErrorSev, templatesDir, err)
+		t.linter.RunRule(templateValuesRule, templatesDir, err)
 		return
 	}
 	var e engine.Engine
 	e.LintMode = true
 	renderedContentMap, err := e.RenderWithContext(context.Background(), chart, valuesToRender)
 
-	renderOk := t.linter.RunLinterRule(support.ErrorSev, templatesDir, err)
+	renderOk := t.linter.RunRule(templateRenderRule, templatesDir, err)
 
 	if !renderOk {
 		return
@@ -153,7 +154,7 @@ func (t *templateLinter) Lint() {
 	for _, template := range chart.Templates {
 		fileName := template.Name
 
-		t.linter.RunLinterRule(support.ErrorSev, fileName, validateAllowedExtension(fileName))
+		t.linter.RunRule(templateExtensionRule, fileName, validateAllowedExtension(fileName))
 
 		// We only apply the following lint rules to yaml files
 		if !isYamlFileExtension(fileName) {
@@ -169,7 +170,7 @@ func (t *templateLinter) Lint() {
 
 		renderedContent := renderedContentMap[path.Join(chart.Name(), fileName)]
 		if strings.TrimSpace(renderedContent) != "" {
-			t.linter.RunLinterRule(support.WarningSev, fileName, validateTopIndentLevel(renderedContent))
+			t.linter.RunRule(templateIndentRule, fileName, validateTopIndentLevel(renderedContent))
 
 			decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(renderedContent), 4096)
 
@@ -186,19 +187,23 @@ func (t *templateLinter) Lint() {
 
 				//  If YAML linting fails here, it will always fail in the next block as well, so we should return here.
 				// fix https://github.com/helm/helm/issues/11391
-				if !t.linter.RunLinterRule(support.ErrorSev, fileName, validateYamlContent(err)) {
+				if !t.linter.RunRule(templateYamlRule, fileName, validateYamlContent(err)) {
 					return
 				}
 				if yamlStruct != nil {
 					// NOTE: set to warnings to allow users to support out-of-date kubernetes
 					// Refs https://github.com/helm/helm/issues/8596
-					t.linter.RunLinterRule(support.WarningSev, fileName, validateMetadataName(yamlStruct))
-					t.linter.RunLinterRule(support.WarningSev, fileName, validateNoDeprecations(yamlStruct, t.kubeVersion))
+					t.linter.RunRule(templateMetadataNameRule, fileName, validateMetadataName(yamlStruct))
+					t.linter.RunRule(templateDeprecatedAPIRule, fileName, validateNoDeprecations(yamlStruct, t.kubeVersion))
 
-					t.linter.RunLinterRule(support.ErrorSev, fileName, validateMatchSelector(yamlStruct, renderedContent))
-					t.linter.RunLinterRule(support.ErrorSev, fileName, validateListAnnotations(yamlStruct, renderedContent))
+					t.linter.RunRule(templateMatchSelectorRule, fileName, validateMatchSelector(yamlStruct, renderedContent))
+					t.linter.RunRule(templateListAnnotationRule, fileName, validateListAnnotations(yamlStruct, renderedContent))
 				}
 			}
+
+			if t.kubeLint {
+				t.lintKubeBestPractices(fileName, renderedContent)
+			}
 		}
 	}
 }
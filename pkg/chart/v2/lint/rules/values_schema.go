@@ -0,0 +1,292 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+)
+
+// ValuesSchema checks that the values.yaml file, the values.schema.json file
+// and the templates of a chart agree: the default values validate against the
+// schema, the properties of the schema have a default, and the templates
+// only reference values in either. Charts without a schema are not checked.
+//
+// When no values are supplied, the default values are already validated by
+// ValuesWithOverrides.
+func ValuesSchema(linter *support.Linter, valueOverrides map[string]any, skipSchemaValidation bool) {
+	c, err := loader.Load(linter.ChartDir)
+	if err != nil || len(c.Schema) == 0 {
+		// Charts that do not load are reported by the other rules.
+		return
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(c.Schema, &schema); err != nil {
+		// Invalid schemas are reported by ValuesWithOverrides.
+		return
+	}
+	values := c.Values
+	if values == nil {
+		values = map[string]any{}
+	}
+
+	if len(valueOverrides) > 0 && !skipSchemaValidation {
+		linter.RunRule(valuesSchemaDefaultsRule, "values.yaml", validateDefaultValues(values, c.Schema))
+	}
+
+	for _, path := range schemaProperties(schema, schema, "") {
+		linter.RunRule(valuesSchemaMissingDefaultRule, "values.schema.json", validateHasDefault(values, path))
+	}
+
+	external := subchartValuesKeys(c)
+	for _, tpl := range c.Templates {
+		for _, path := range valuesReferences(tpl.Name, string(tpl.Data)) {
+			if slices.Contains(external, path[0]) {
+				continue
+			}
+			linter.RunRule(valuesUndefinedReferenceRule, tpl.Name, validateValuesReference(values, schema, path))
+		}
+	}
+}
+
+func validateDefaultValues(values map[string]any, schema []byte) error {
+	if err := util.ValidateAgainstSingleSchema(values, schema); err != nil {
+		return fmt.Errorf("the default values do not validate against the schema: %w", err)
+	}
+	return nil
+}
+
+func validateHasDefault(values map[string]any, path string) error {
+	if !valuesHasPath(values, strings.Split(path, ".")) {
+		return fmt.Errorf("property %s has no default in values.yaml", path)
+	}
+	return nil
+}
+
+func validateValuesReference(values, schema map[string]any, path []string) error {
+	if valuesHasPath(values, path) || schemaHasPath(schema, schema, path) {
+		return nil
+	}
+	return fmt.Errorf(".Values.%s is neither in values.yaml nor in values.schema.json", strings.Join(path, "."))
+}
+
+// subchartValuesKeys returns the top-level keys of values that belong to the
+// subcharts of a chart, or to all charts.
+func subchartValuesKeys(c *chart.Chart) []string {
+	keys := []string{"global"}
+	for _, dep := range c.Metadata.Dependencies {
+		keys = append(keys, dep.Name, dep.Alias)
+	}
+	for _, sub := range c.Dependencies() {
+		keys = append(keys, sub.Name())
+	}
+	return keys
+}
+
+// valuesHasPath returns whether values have the value at a path. A path
+// under a value that is not a map, like a list or null, is not checked.
+func valuesHasPath(values map[string]any, path []string) bool {
+	var v any = values
+	for _, key := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return true
+		}
+		if v, ok = m[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveSchema follows the local reference of a schema, if any.
+func resolveSchema(root, node map[string]any) map[string]any {
+	for range 32 {
+		ref, ok := node["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return node
+		}
+		var target any = root
+		for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			m, ok := target.(map[string]any)
+			if !ok {
+				return node
+			}
+			target = m[key]
+		}
+		next, ok := target.(map[string]any)
+		if !ok {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+// schemaProperties returns the dotted paths of the properties a schema
+// declares, sorted.
+func schemaProperties(root, node map[string]any, prefix string) []string {
+	var paths []string
+	props, _ := resolveSchema(root, node)["properties"].(map[string]any)
+	for key, prop := range props {
+		path := prefix + key
+		paths = append(paths, path)
+		if child, ok := prop.(map[string]any); ok {
+			paths = append(paths, schemaProperties(root, child, path+".")...)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// schemaHasPath returns whether a schema allows the value at a path. The
+// properties of an object are only checked if the schema declares them and
+// does not allow other properties.
+func schemaHasPath(root, node map[string]any, path []string) bool {
+	node = resolveSchema(root, node)
+	if len(path) == 0 {
+		return true
+	}
+	combined := false
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		branches, _ := node[keyword].([]any)
+		for _, b := range branches {
+			combined = true
+			if branch, ok := b.(map[string]any); ok && schemaHasPath(root, branch, path) {
+				return true
+			}
+		}
+	}
+
+	props, ok := node["properties"].(map[string]any)
+	if !ok {
+		// A schema that declares no properties, not even in its branches,
+		// allows any.
+		return !combined
+	}
+	if prop, ok := props[path[0]].(map[string]any); ok {
+		return schemaHasPath(root, prop, path[1:])
+	}
+	if _, ok := node["patternProperties"]; ok {
+		return true
+	}
+	_, ok = node["additionalProperties"].(map[string]any)
+	return ok
+}
+
+// valuesReferences returns the paths of the values a template references as
+// .Values or $.Values, e.g. [image tag] for .Values.image.tag, in the order
+// of the names of the templates the file defines. Templates that do not parse
+// are reported by the other rules.
+func valuesReferences(name, text string) [][]string {
+	t := parse.New(name)
+	t.Mode = parse.SkipFuncCheck
+	trees := map[string]*parse.Tree{}
+	if _, err := t.Parse(text, "", "", trees); err != nil {
+		return nil
+	}
+
+	r := valuesReferenceCollector{seen: map[string]bool{}}
+	names := make([]string, 0, len(trees))
+	for n := range trees {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		r.walk(trees[n].Root, true)
+	}
+	return r.paths
+}
+
+// valuesReferenceCollector collects the values referenced by templates.
+type valuesReferenceCollector struct {
+	paths [][]string
+	seen  map[string]bool
+}
+
+// walk collects the values referenced by a node. rootDot is whether the dot
+// is the root of the values, which it is not in the body of range and with.
+func (r *valuesReferenceCollector) walk(node parse.Node, rootDot bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			r.walk(child, rootDot)
+		}
+	case *parse.ActionNode:
+		r.walk(n.Pipe, rootDot)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				r.walk(arg, rootDot)
+			}
+		}
+	case *parse.IfNode:
+		r.walkBranch(&n.BranchNode, rootDot, rootDot)
+	case *parse.RangeNode:
+		r.walkBranch(&n.BranchNode, rootDot, false)
+	case *parse.WithNode:
+		r.walkBranch(&n.BranchNode, rootDot, false)
+	case *parse.TemplateNode:
+		r.walk(n.Pipe, rootDot)
+	case *parse.FieldNode:
+		if rootDot {
+			r.add(n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 0 && n.Ident[0] == "$" {
+			r.add(n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		r.walk(n.Node, rootDot)
+	}
+}
+
+func (r *valuesReferenceCollector) walkBranch(n *parse.BranchNode, rootDot, bodyRootDot bool) {
+	r.walk(n.Pipe, rootDot)
+	r.walk(n.List, bodyRootDot)
+	r.walk(n.ElseList, rootDot)
+}
+
+// add collects the path of values of the identifiers of a field, if it is
+// a field of .Values.
+func (r *valuesReferenceCollector) add(ident []string) {
+	if len(ident) < 2 || ident[0] != "Values" {
+		return
+	}
+	path := ident[1:]
+	key := strings.Join(path, ".")
+	if r.seen[key] {
+		return
+	}
+	r.seen[key] = true
+	r.paths = append(r.paths, path)
+}
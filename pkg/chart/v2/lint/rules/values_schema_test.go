@@ -0,0 +1,133 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+func TestValuesReferences(t *testing.T) {
+	text := `{{ define "name" }}{{ .Values.nameOverride | default .Chart.Name }}{{ end }}
+image: {{ .Values.image.repository }}:{{ .Values.image.tag }}
+{{- if .Values.ingress.enabled }}
+{{- range .Values.ingress.hosts }}
+host: {{ .Values }}{{ $.Values.ingress.className }}
+{{- end }}
+{{- end }}
+{{- with .Values.podAnnotations }}{{ .Values.notValues }}{{ end }}
+{{ include "name" . }}{{ .Values.image.repository }}`
+
+	// The templates defined in the file come in the order of their names.
+	assert.Equal(t, [][]string{
+		{"nameOverride"},
+		{"image", "repository"},
+		{"image", "tag"},
+		{"ingress", "enabled"},
+		{"ingress", "hosts"},
+		{"ingress", "className"},
+		{"podAnnotations"},
+	}, valuesReferences("templates/test.yaml", text))
+
+	assert.Nil(t, valuesReferences("templates/broken.yaml", "{{ .Values.image "))
+}
+
+func TestSchemaHasPath(t *testing.T) {
+	schema := map[string]any{
+		"definitions": map[string]any{
+			"image": map[string]any{"properties": map[string]any{"tag": map[string]any{"type": "string"}}},
+		},
+		"properties": map[string]any{
+			"image":          map[string]any{"$ref": "#/definitions/image"},
+			"podAnnotations": map[string]any{"type": "object"},
+			"labels":         map[string]any{"properties": map[string]any{}, "additionalProperties": map[string]any{"type": "string"}},
+			"tls":            map[string]any{"oneOf": []any{map[string]any{"properties": map[string]any{"secret": map[string]any{}}}}},
+		},
+	}
+
+	for path, has := range map[string]bool{
+		"image.tag":          true,
+		"image.digest":       false,
+		"podAnnotations.foo": true,
+		"labels.app":         true,
+		"tls.secret":         true,
+		"tls.key":            false,
+		"replicas":           false,
+	} {
+		assert.Equal(t, has, schemaHasPath(schema, schema, strings.Split(path, ".")), path)
+	}
+
+	assert.Equal(t, []string{"image", "image.tag", "labels", "podAnnotations", "tls"}, schemaProperties(schema, schema, ""))
+}
+
+func TestValuesSchema(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: schema\nversion: 0.1.0\ndependencies:\n  - name: redis\n    version: 1.0.0\n",
+		"values.yaml": "image:\n  repository: nginx\nreplicas: one\n",
+		"values.schema.json": `{
+  "properties": {
+    "image": {"properties": {"repository": {"type": "string"}, "tag": {"type": "string"}}},
+    "replicas": {"type": "integer"}
+  }
+}`,
+		"templates/deployment.yaml": "image: {{ .Values.image.repository }}:{{ .Values.image.tag }}\nreplicas: {{ .Values.replicas }}\nport: {{ .Values.port }}\nredis: {{ .Values.redis.host }}{{ .Values.global.domain }}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	linter := support.Linter{ChartDir: dir}
+	ValuesSchema(&linter, nil, false)
+	assert.Equal(t, []string{
+		"[INFO] values.schema.json: property image.tag has no default in values.yaml",
+		"[WARNING] templates/deployment.yaml: .Values.port is neither in values.yaml nor in values.schema.json",
+	}, messageStrings(linter.Messages))
+
+	// The default values are validated by ValuesWithOverrides, unless other
+	// values are supplied.
+	linter = support.Linter{ChartDir: dir}
+	ValuesSchema(&linter, map[string]any{"replicas": 2}, false)
+	require.NotEmpty(t, linter.Messages)
+	assert.Equal(t, "values-schema-defaults", linter.Messages[0].RuleID)
+	assert.Contains(t, linter.Messages[0].Err.Error(), "the default values do not validate against the schema")
+
+	linter = support.Linter{ChartDir: dir}
+	ValuesSchema(&linter, map[string]any{"replicas": 2}, true)
+	assert.Len(t, linter.Messages, 2)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "values.schema.json")))
+	linter = support.Linter{ChartDir: dir}
+	ValuesSchema(&linter, nil, false)
+	assert.Empty(t, linter.Messages, "charts without a schema are not checked")
+}
+
+func messageStrings(messages []support.Message) []string {
+	var s []string
+	for _, m := range messages {
+		s = append(s, m.Error())
+	}
+	return s
+}
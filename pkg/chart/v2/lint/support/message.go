@@ -39,6 +39,8 @@ type Linter struct {
 	// The highest severity of all the failing lint rules
 	HighestSeverity int
 	ChartDir        string
+	// RuleConfig overrides the severity of the rules checked with RunRule.
+	RuleConfig RuleConfig
 }
 
 // Message describes an error encountered while linting.
@@ -47,6 +49,9 @@ type Message struct {
 	Severity int
 	Path     string
 	Err      error
+	// RuleID is the ID of the rule that failed, if the message is reported
+	// by RunRule.
+	RuleID string
 }
 
 func (m Message) Error() string {
@@ -74,3 +79,19 @@ func (l *Linter) RunLinterRule(severity int, path string, err error) bool {
 	}
 	return err == nil
 }
+
+// RunRule reports the failure of a rule, with the severity the linter
+// configures for it, and returns true if the validation passed. The failures
+// of disabled rules are not reported.
+func (l *Linter) RunRule(rule Rule, path string, err error) bool {
+	severity := l.RuleConfig.severity(rule)
+	if severity == DisabledSev || err == nil {
+		return err == nil
+	}
+	n := len(l.Messages)
+	l.RunLinterRule(severity, path, err)
+	if len(l.Messages) > n {
+		l.Messages[n].RuleID = rule.ID
+	}
+	return false
+}
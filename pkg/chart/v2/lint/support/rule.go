@@ -0,0 +1,166 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DisabledSev is the severity of a rule a RuleConfig disables. The failures
+// of a disabled rule are not reported.
+const DisabledSev = -1
+
+// Rule is a lint rule. The rules of Helm are checked by the linter itself;
+// the rules added to a Registry with a Check are checked after them.
+type Rule struct {
+	// ID identifies the rule in a RuleConfig, e.g. chart-icon.
+	ID string
+	// Description describes what the rule checks.
+	Description string
+	// Severity is the default severity of the failures of the rule.
+	Severity int
+	// Check checks the rule against the chart being linted, reporting its
+	// failures with l.RunRule(rule, path, err).
+	Check func(l *Linter, rule Rule)
+}
+
+// RuleConfig overrides the severity of rules by ID. A rule set to
+// DisabledSev is disabled.
+type RuleConfig map[string]int
+
+// ParseSeverity parses the name of a severity for a RuleConfig: info,
+// warning, error or disabled.
+func ParseSeverity(name string) (int, error) {
+	switch strings.ToLower(name) {
+	case "info":
+		return InfoSev, nil
+	case "warning":
+		return WarningSev, nil
+	case "error":
+		return ErrorSev, nil
+	case "disabled", "off":
+		return DisabledSev, nil
+	}
+	return 0, fmt.Errorf("invalid severity %q, expected one of: info, warning, error, disabled", name)
+}
+
+// SeverityName returns the name of a severity.
+func SeverityName(severity int) string {
+	if severity == DisabledSev {
+		return "DISABLED"
+	}
+	if severity < 0 || severity >= len(sev) {
+		return sev[UnknownSev]
+	}
+	return sev[severity]
+}
+
+// ParseRuleConfig parses rule settings of the form ID=SEVERITY.
+func ParseRuleConfig(settings map[string]string) (RuleConfig, error) {
+	config := RuleConfig{}
+	for id, name := range settings {
+		severity, err := ParseSeverity(name)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", id, err)
+		}
+		config[id] = severity
+	}
+	return config, nil
+}
+
+// severity returns the severity of a rule.
+func (c RuleConfig) severity(rule Rule) int {
+	if severity, ok := c[rule.ID]; ok {
+		return severity
+	}
+	return rule.Severity
+}
+
+// Registry holds the lint rules, by ID.
+type Registry struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+}
+
+// NewRegistry returns a registry of the given rules. It panics if a rule is
+// invalid.
+func NewRegistry(rules ...Rule) *Registry {
+	r := &Registry{rules: map[string]Rule{}}
+	for _, rule := range rules {
+		if err := r.Register(rule); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Register adds a rule to the registry.
+func (r *Registry) Register(rule Rule) error {
+	if rule.ID == "" {
+		return errors.New("lint rule has no ID")
+	}
+	if rule.Severity < InfoSev || rule.Severity > ErrorSev {
+		return fmt.Errorf("lint rule %s has an invalid severity %d", rule.ID, rule.Severity)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rules[rule.ID]; ok {
+		return fmt.Errorf("lint rule %s is already registered", rule.ID)
+	}
+	r.rules[rule.ID] = rule
+	return nil
+}
+
+// Lookup returns the rule with the given ID.
+func (r *Registry) Lookup(id string) (Rule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rule, ok := r.rules[id]
+	return rule, ok
+}
+
+// Rules returns the rules of the registry, sorted by ID.
+func (r *Registry) Rules() []Rule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rules := make([]Rule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// Validate checks that a configuration only sets rules of the registry.
+func (r *Registry) Validate(config RuleConfig) error {
+	var unknown []string
+	for id := range config {
+		if _, ok := r.Lookup(id); !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown lint rules: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunRule(t *testing.T) {
+	icon := Rule{ID: "chart-icon", Severity: InfoSev}
+	name := Rule{ID: "chart-name", Severity: ErrorSev}
+
+	linter := Linter{RuleConfig: RuleConfig{"chart-icon": ErrorSev, "chart-name": DisabledSev}}
+
+	assert.False(t, linter.RunRule(icon, "Chart.yaml", errLint))
+	assert.False(t, linter.RunRule(name, "Chart.yaml", errLint), "a disabled rule still fails")
+	assert.True(t, linter.RunRule(name, "Chart.yaml", nil))
+
+	require.Len(t, linter.Messages, 1)
+	assert.Equal(t, ErrorSev, linter.Messages[0].Severity)
+	assert.Equal(t, "chart-icon", linter.Messages[0].RuleID)
+	assert.Equal(t, ErrorSev, linter.HighestSeverity)
+}
+
+func TestParseRuleConfig(t *testing.T) {
+	config, err := ParseRuleConfig(map[string]string{"chart-icon": "Warning", "chart-name": "disabled"})
+	require.NoError(t, err)
+	assert.Equal(t, RuleConfig{"chart-icon": WarningSev, "chart-name": DisabledSev}, config)
+
+	_, err = ParseRuleConfig(map[string]string{"chart-icon": "fatal"})
+	assert.ErrorContains(t, err, `rule chart-icon: invalid severity "fatal"`)
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(Rule{ID: "b", Severity: WarningSev}, Rule{ID: "a", Severity: InfoSev})
+
+	assert.ErrorContains(t, r.Register(Rule{ID: "a", Severity: ErrorSev}), "already registered")
+	assert.ErrorContains(t, r.Register(Rule{Severity: ErrorSev}), "no ID")
+	assert.ErrorContains(t, r.Register(Rule{ID: "c"}), "invalid severity")
+	require.NoError(t, r.Register(Rule{ID: "c", Severity: ErrorSev}))
+
+	var ids []string
+	for _, rule := range r.Rules() {
+		ids = append(ids, rule.ID)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, ids)
+
+	assert.NoError(t, r.Validate(RuleConfig{"a": DisabledSev}))
+	assert.EqualError(t, r.Validate(RuleConfig{"z": ErrorSev, "y": InfoSev}), "unknown lint rules: y, z")
+}
@@ -39,10 +39,28 @@ func (l DirLoader) Load() (*chart.Chart, error) {
 	return LoadDir(string(l))
 }
 
+// DirOptions selects the files of a chart directory to load.
+type DirOptions struct {
+	// IgnoreFile is the ignore file to use instead of the .helmignore file
+	// of the chart.
+	IgnoreFile string
+	// Exclude are additional patterns, in the .helmignore syntax, of files to
+	// ignore.
+	Exclude []string
+	// Include are patterns, in the .helmignore syntax, of files to load even
+	// if they are ignored.
+	Include []string
+}
+
 // LoadDir loads from a directory.
 //
 // This loads charts only from directories.
 func LoadDir(dir string) (*chart.Chart, error) {
+	return LoadDirWithOptions(dir, DirOptions{})
+}
+
+// LoadDirWithOptions loads from a directory the files selected by the options.
+func LoadDirWithOptions(dir string, opts DirOptions) (*chart.Chart, error) {
 	topdir, err := filepath.Abs(dir)
 	if err != nil {
 		return nil, err
@@ -53,7 +71,10 @@ func LoadDir(dir string) (*chart.Chart, error) {
 
 	rules := ignore.Empty()
 	ifile := filepath.Join(topdir, ignore.HelmIgnore)
-	if _, err := os.Stat(ifile); err == nil {
+	if opts.IgnoreFile != "" {
+		ifile = opts.IgnoreFile
+	}
+	if _, err := os.Stat(ifile); err == nil || opts.IgnoreFile != "" {
 		r, err := ignore.ParseFile(ifile)
 		if err != nil {
 			return c, err
@@ -61,6 +82,24 @@ func LoadDir(dir string) (*chart.Chart, error) {
 		rules = r
 	}
 	rules.AddDefaults()
+	if err := rules.AddRules(opts.Exclude...); err != nil {
+		return c, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+	includes := ignore.Empty()
+	if err := includes.AddRules(opts.Include...); err != nil {
+		return c, fmt.Errorf("invalid include pattern: %w", err)
+	}
+
+	// The ignored directories that are still walked for included files.
+	var ignoredDirs []string
+	ignored := func(n string, fi os.FileInfo) bool {
+		for _, d := range ignoredDirs {
+			if strings.HasPrefix(n, d) {
+				return true
+			}
+		}
+		return rules.Ignore(n, fi)
+	}
 
 	files := []*archive.BufferedFile{}
 	topdir += string(filepath.Separator)
@@ -81,15 +120,19 @@ func LoadDir(dir string) (*chart.Chart, error) {
 		}
 		if fi.IsDir() {
 			// Directory-based ignore rules should involve skipping the entire
-			// contents of that directory.
-			if rules.Ignore(n, fi) {
-				return filepath.SkipDir
+			// contents of that directory, unless some of it may be included.
+			if ignored(n, fi) {
+				if len(opts.Include) == 0 {
+					return filepath.SkipDir
+				}
+				ignoredDirs = append(ignoredDirs, n+"/")
 			}
 			return nil
 		}
 
-		// If a .helmignore file matches, skip this file.
-		if rules.Ignore(n, fi) {
+		// If a .helmignore file matches, skip this file, unless an include
+		// pattern matches.
+		if ignored(n, fi) && !includes.Ignore(n, fi) {
 			return nil
 		}
 
@@ -26,6 +26,7 @@ import (
 	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
@@ -168,7 +169,10 @@ func LoadFiles(files []*archive.BufferedFile) (*chart.Chart, error) {
 		return c, err
 	}
 
-	for n, files := range subcharts {
+	// Load the subcharts in a fixed order, so the dependencies of a chart do
+	// not depend on the iteration order of the map.
+	for _, n := range slices.Sorted(maps.Keys(subcharts)) {
+		files := subcharts[n]
 		var sc *chart.Chart
 		var err error
 		switch {
@@ -27,6 +27,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -51,6 +52,70 @@ func TestLoadDir(t *testing.T) {
 	verifyDependenciesLock(t, c)
 }
 
+func TestLoadDirWithOptions(t *testing.T) {
+	ignoreFile := filepath.Join(t.TempDir(), "ignore")
+	if err := os.WriteFile(ignoreFile, []byte("README.md\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    DirOptions
+		present []string
+		absent  []string
+	}{
+		{
+			name:    "exclude",
+			opts:    DirOptions{Exclude: []string{"docs/", "LICENSE"}},
+			present: []string{"README.md", "INSTALL.txt"},
+			absent:  []string{"docs/README.md", "LICENSE", "ignore/me.txt"},
+		},
+		{
+			name:    "include an ignored file",
+			opts:    DirOptions{Include: []string{"ignore/me.txt"}},
+			present: []string{"ignore/me.txt", "docs/README.md"},
+		},
+		{
+			name:    "include an excluded file",
+			opts:    DirOptions{Exclude: []string{"*.md"}, Include: []string{"/README.md"}},
+			present: []string{"README.md"},
+			absent:  []string{"docs/README.md"},
+		},
+		{
+			name:    "ignore file",
+			opts:    DirOptions{IgnoreFile: ignoreFile},
+			present: []string{"ignore/me.txt", "LICENSE"},
+			absent:  []string{"README.md", "docs/README.md"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := LoadDirWithOptions("testdata/frobnitz", tt.opts)
+			if err != nil {
+				t.Fatalf("Failed to load testdata: %s", err)
+			}
+			files := map[string]bool{}
+			for _, f := range c.Files {
+				files[f.Name] = true
+			}
+			for _, name := range tt.present {
+				if !files[name] {
+					t.Errorf("Expected file %s to be loaded", name)
+				}
+			}
+			for _, name := range tt.absent {
+				if files[name] {
+					t.Errorf("Expected file %s not to be loaded", name)
+				}
+			}
+		})
+	}
+
+	if _, err := LoadDirWithOptions("testdata/frobnitz", DirOptions{IgnoreFile: "testdata/no-such-file"}); err == nil {
+		t.Error("Expected an error for a missing ignore file")
+	}
+}
+
 func TestLoadDirWithDevNull(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("test only works on unix systems with /dev/null present")
@@ -394,6 +459,20 @@ icon: https://example.com/64x64.png
 
 // Packaging the chart on a Windows machine will produce an
 // archive that has \\ as delimiters. Test that we support these archives
+func TestLoadDependenciesOrder(t *testing.T) {
+	c, err := Load("testdata/frobnitz")
+	if err != nil {
+		t.Fatalf("Failed to load testdata: %s", err)
+	}
+	var names []string
+	for _, dep := range c.Dependencies() {
+		names = append(names, dep.Name())
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("Expected dependencies sorted by name, got %v", names)
+	}
+}
+
 func TestLoadFileBackslash(t *testing.T) {
 	c, err := Load("testdata/frobnitz_backslash-1.2.3.tgz")
 	if err != nil {
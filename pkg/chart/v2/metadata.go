@@ -44,6 +44,27 @@ func (m *Maintainer) Validate() error {
 	return nil
 }
 
+// Validation is a rule the values of a chart must satisfy.
+type Validation struct {
+	// Rule is a CEL expression evaluating to true when the values are valid,
+	// such as `values.replicas <= 1 || values.persistence.enabled`.
+	Rule string `json:"rule"`
+	// Message is the error reported when the rule is not satisfied.
+	Message string `json:"message,omitempty"`
+}
+
+// Validate checks valid data and sanitizes string characters.
+func (v *Validation) Validate() error {
+	if v == nil {
+		return ValidationError("validations must not contain empty or null nodes")
+	}
+	v.Message = sanitizeString(v.Message)
+	if strings.TrimSpace(v.Rule) == "" {
+		return ValidationError("validations must have a rule")
+	}
+	return nil
+}
+
 // Metadata for a Chart file. This models the structure of a Chart.yaml file.
 type Metadata struct {
 	// The name of the chart. Required.
@@ -81,6 +102,12 @@ type Metadata struct {
 	Dependencies []*Dependency `json:"dependencies,omitempty"`
 	// Specifies the chart type: application or library
 	Type string `json:"type,omitempty"`
+	// FunctionSet is the version of the template functions the chart requires,
+	// such as "v1". Templates can only use the functions of that version.
+	FunctionSet string `json:"functionSet,omitempty"`
+	// Validations are rules the values of the chart must satisfy, checked
+	// along with the values schema.
+	Validations []*Validation `json:"validations,omitempty"`
 }
 
 // Validate checks the metadata for known issues and sanitizes string
@@ -98,6 +125,7 @@ func (md *Metadata) Validate() error {
 	md.Tags = sanitizeString(md.Tags)
 	md.AppVersion = sanitizeString(md.AppVersion)
 	md.KubeVersion = sanitizeString(md.KubeVersion)
+	md.FunctionSet = sanitizeString(md.FunctionSet)
 	for i := range md.Sources {
 		md.Sources[i] = sanitizeString(md.Sources[i])
 	}
@@ -135,6 +163,12 @@ func (md *Metadata) Validate() error {
 		}
 	}
 
+	for _, v := range md.Validations {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Aliases need to be validated here to make sure that the alias name does
 	// not contain any illegal characters.
 	dependencies := map[string]*Dependency{}
@@ -0,0 +1,365 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The kinds of assertions.
+const (
+	assertEqual          = "equal"
+	assertNotEqual       = "notEqual"
+	assertIsNull         = "isNull"
+	assertIsNotNull      = "isNotNull"
+	assertExists         = "exists"
+	assertNotExists      = "notExists"
+	assertMatchRegex     = "matchRegex"
+	assertContains       = "contains"
+	assertIsKind         = "isKind"
+	assertHasDocuments   = "hasDocuments"
+	assertMatchSnapshot  = "matchSnapshot"
+	assertFailedTemplate = "failedTemplate"
+)
+
+var assertionKinds = []string{
+	assertEqual, assertNotEqual, assertIsNull, assertIsNotNull, assertExists, assertNotExists,
+	assertMatchRegex, assertContains, assertIsKind, assertHasDocuments, assertMatchSnapshot, assertFailedTemplate,
+}
+
+// Assertion is an assertion of a test against the documents rendered by its
+// templates. It is written as the kind of the assertion holding its
+// parameters, e.g.
+//
+//	equal:
+//	  path: spec.replicas
+//	  value: 3
+//	documentIndex: 0
+type Assertion struct {
+	// Kind is the kind of the assertion.
+	Kind string
+	// Not negates the assertion.
+	Not bool
+	// DocumentIndex restricts the assertion to the document at this index.
+	// Nil means every document.
+	DocumentIndex *int
+
+	params assertionParams
+}
+
+// assertionParams are the parameters of the kinds of assertions.
+type assertionParams struct {
+	// Path is the path of the asserted field, e.g.
+	// spec.containers[0].image or metadata.annotations["helm.sh/hook"].
+	Path string `json:"path,omitempty"`
+	// Value is the value the field equals.
+	Value any `json:"value,omitempty"`
+	// Pattern is the regular expression the field matches.
+	Pattern string `json:"pattern,omitempty"`
+	// Content is the element the list field contains.
+	Content any `json:"content,omitempty"`
+	// Of is the kind of the documents.
+	Of string `json:"of,omitempty"`
+	// Count is the number of documents.
+	Count *int `json:"count,omitempty"`
+	// ErrorMessage is a part of the error of a failed rendering.
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// UnmarshalJSON reads an assertion.
+func (a *Assertion) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for key, value := range fields {
+		switch key {
+		case "not":
+			if err := json.Unmarshal(value, &a.Not); err != nil {
+				return fmt.Errorf("invalid not: %w", err)
+			}
+		case "documentIndex":
+			if err := json.Unmarshal(value, &a.DocumentIndex); err != nil {
+				return fmt.Errorf("invalid documentIndex: %w", err)
+			}
+		default:
+			if a.Kind != "" {
+				return fmt.Errorf("assertion has several kinds: %s and %s", a.Kind, key)
+			}
+			if !slices.Contains(assertionKinds, key) {
+				return fmt.Errorf("unknown assertion %q, expected one of: %s", key, strings.Join(assertionKinds, ", "))
+			}
+			a.Kind = key
+			if string(value) == "null" {
+				continue
+			}
+			dec := json.NewDecoder(bytes.NewReader(value))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&a.params); err != nil {
+				return fmt.Errorf("invalid %s assertion: %w", key, err)
+			}
+		}
+	}
+	if a.Kind == "" {
+		return errors.New("assertion has no kind")
+	}
+	return a.validate()
+}
+
+// validate checks that an assertion has the parameters its kind needs.
+func (a *Assertion) validate() error {
+	switch a.Kind {
+	case assertEqual, assertNotEqual, assertIsNull, assertIsNotNull, assertExists, assertNotExists, assertMatchRegex, assertContains:
+		if a.params.Path == "" {
+			return fmt.Errorf("%s assertion needs a path", a.Kind)
+		}
+		if _, err := parsePath(a.params.Path); err != nil {
+			return fmt.Errorf("invalid %s assertion: %w", a.Kind, err)
+		}
+	}
+	switch a.Kind {
+	case assertMatchRegex:
+		if _, err := regexp.Compile(a.params.Pattern); err != nil {
+			return fmt.Errorf("invalid matchRegex assertion: %w", err)
+		}
+	case assertIsKind:
+		if a.params.Of == "" {
+			return errors.New("isKind assertion needs the kind: of")
+		}
+	case assertHasDocuments:
+		if a.params.Count == nil {
+			return errors.New("hasDocuments assertion needs a count")
+		}
+	}
+	return nil
+}
+
+// kind returns the kind of the assertion as written, with its negation.
+func (a *Assertion) kind() string {
+	if a.Not {
+		return "not " + a.Kind
+	}
+	return a.Kind
+}
+
+// evaluate checks the assertion against the documents rendered by the
+// templates of a test, or the error rendering them.
+func (a *Assertion) evaluate(docs []document, renderErr error, snapshot *testSnapshots) error {
+	if a.Kind == assertFailedTemplate {
+		ok := renderErr != nil && strings.Contains(renderErr.Error(), a.params.ErrorMessage)
+		if ok != a.Not {
+			return nil
+		}
+		switch {
+		case a.Not:
+			return fmt.Errorf("expected the templates to render, got: %v", renderErr)
+		case renderErr == nil:
+			return errRendered
+		default:
+			return fmt.Errorf("expected an error containing %q, got: %v", a.params.ErrorMessage, renderErr)
+		}
+	}
+	if renderErr != nil {
+		return fmt.Errorf("rendering failed: %w", renderErr)
+	}
+
+	if a.Kind == assertHasDocuments {
+		if (len(docs) == *a.params.Count) != a.Not {
+			return nil
+		}
+		return a.failure(fmt.Sprintf("%d documents", *a.params.Count), fmt.Sprintf("%d", len(docs)))
+	}
+
+	selected := docs
+	if a.DocumentIndex != nil {
+		i := *a.DocumentIndex
+		if i < 0 || i >= len(docs) {
+			return fmt.Errorf("documentIndex %d out of range: the templates rendered %d documents", i, len(docs))
+		}
+		selected = docs[i : i+1]
+	}
+	if len(selected) == 0 {
+		return errors.New("the templates rendered no documents")
+	}
+
+	var errs []error
+	for i, doc := range selected {
+		index := i
+		if a.DocumentIndex != nil {
+			index = *a.DocumentIndex
+		}
+		if a.Kind == assertMatchSnapshot {
+			if err := snapshot.match(doc.content); err != nil {
+				errs = append(errs, fmt.Errorf("document %d of %s: %w", index, doc.template, err))
+			}
+			continue
+		}
+		expected, actual, ok := a.check(doc.content)
+		if ok == a.Not {
+			errs = append(errs, fmt.Errorf("document %d of %s: %w", index, doc.template, a.failure(expected, actual)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// failure returns the error of an assertion that failed.
+func (a *Assertion) failure(expected, actual string) error {
+	if a.Not {
+		return fmt.Errorf("expected not %s, got %s", expected, actual)
+	}
+	return fmt.Errorf("expected %s, got %s", expected, actual)
+}
+
+// check checks the assertion against a document. It returns what the
+// assertion expects and what the document has.
+func (a *Assertion) check(doc map[string]any) (string, string, bool) {
+	if a.Kind == assertIsKind {
+		kind, _ := doc["kind"].(string)
+		return "kind " + a.params.Of, "kind " + kind, kind == a.params.Of
+	}
+
+	p := a.params
+	value, found := lookup(doc, p.Path)
+	actual := p.Path + " missing"
+	if found {
+		actual = p.Path + " = " + formatValue(value)
+	}
+	switch a.Kind {
+	case assertEqual:
+		return p.Path + " = " + formatValue(p.Value), actual, found && reflect.DeepEqual(value, p.Value)
+	case assertNotEqual:
+		return p.Path + " != " + formatValue(p.Value), actual, !found || !reflect.DeepEqual(value, p.Value)
+	case assertIsNull:
+		return p.Path + " null", actual, value == nil
+	case assertIsNotNull:
+		return p.Path + " not null", actual, value != nil
+	case assertExists:
+		return p.Path + " to exist", actual, found
+	case assertNotExists:
+		return p.Path + " not to exist", actual, !found
+	case assertMatchRegex:
+		s, isString := value.(string)
+		return p.Path + " matching " + p.Pattern, actual, isString && regexp.MustCompile(p.Pattern).MatchString(s)
+	case assertContains:
+		list, _ := value.([]any)
+		return p.Path + " containing " + formatValue(p.Content), actual, slices.ContainsFunc(list, func(v any) bool {
+			return reflect.DeepEqual(v, p.Content)
+		})
+	}
+	return a.Kind, "an unknown assertion", false
+}
+
+// formatValue formats a value for a failure message.
+func formatValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// pathElement is an element of the path of a field: the key of a map, or the
+// index of a list if key is empty.
+type pathElement struct {
+	key   string
+	index int
+}
+
+// parsePath parses the path of a field, made of keys separated by dots, list
+// indexes like [0], and quoted keys like ["helm.sh/hook"].
+func parsePath(path string) ([]pathElement, error) {
+	var elems []pathElement
+	rest := path
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, `["`):
+			end := strings.Index(rest, `"]`)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated key in path %q", path)
+			}
+			elems = append(elems, pathElement{key: rest[2:end]})
+			rest = rest[end+2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index in path %q", path)
+			}
+			i, err := strconv.Atoi(rest[1:end])
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid index %q in path %q", rest[1:end], path)
+			}
+			elems = append(elems, pathElement{index: i})
+			rest = rest[end+1:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty key in path %q", path)
+			}
+			elems = append(elems, pathElement{key: rest[:end]})
+			rest = rest[end:]
+		}
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("path %q ends with a dot", path)
+			}
+		}
+	}
+	return elems, nil
+}
+
+// lookup returns the value of the field at the given path of a document, and
+// whether the field exists.
+func lookup(doc map[string]any, path string) (any, bool) {
+	elems, err := parsePath(path)
+	if err != nil {
+		return nil, false
+	}
+	var value any = doc
+	for _, e := range elems {
+		switch v := value.(type) {
+		case map[string]any:
+			if e.key == "" {
+				return nil, false
+			}
+			next, ok := v[e.key]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			if e.key != "" || e.index >= len(v) {
+				return nil, false
+			}
+			value = v[e.index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,116 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// SnapshotDir is the directory of the tests of a chart holding the snapshots
+// of its suites.
+const SnapshotDir = "__snapshot__"
+
+// snapshotPath returns the path of the snapshots of a suite file.
+func snapshotPath(suiteFile string) string {
+	return filepath.Join(filepath.Dir(suiteFile), SnapshotDir, strings.TrimSuffix(filepath.Base(suiteFile), ".yaml")+".snap")
+}
+
+// snapshotStore holds the snapshots of a suite: the documents matched by
+// the snapshot assertions of each test, in order.
+type snapshotStore struct {
+	path      string
+	snapshots map[string][]string
+	changed   bool
+}
+
+// loadSnapshots reads the snapshots of a suite, if any.
+func loadSnapshots(path string) (*snapshotStore, error) {
+	s := &snapshotStore{path: path, snapshots: map[string][]string{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &s.snapshots); err != nil {
+		return nil, fmt.Errorf("unable to parse snapshots %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// forTest returns the snapshots of a test.
+func (s *snapshotStore) forTest(name string, update bool) *testSnapshots {
+	return &testSnapshots{store: s, name: name, update: update}
+}
+
+// save writes the snapshots if they changed.
+func (s *snapshotStore) save() error {
+	if !s.changed {
+		return nil
+	}
+	data, err := yaml.Marshal(s.snapshots)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0644)
+}
+
+// testSnapshots are the snapshots of a test.
+type testSnapshots struct {
+	store  *snapshotStore
+	name   string
+	update bool
+	next   int
+}
+
+// match compares a document with the next snapshot of the test. A missing
+// snapshot is recorded, as is a different one when updating the snapshots.
+func (t *testSnapshots) match(doc map[string]any) error {
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	actual := string(data)
+
+	i := t.next
+	t.next++
+	stored := t.store.snapshots[t.name]
+	switch {
+	case i >= len(stored):
+		t.store.snapshots[t.name] = append(stored, actual)
+		t.store.changed = true
+		return nil
+	case stored[i] == actual:
+		return nil
+	case t.update:
+		stored[i] = actual
+		t.store.changed = true
+		return nil
+	default:
+		return fmt.Errorf("the document does not match snapshot %d in %s:\n--- expected\n%s--- actual\n%s", i, t.store.path, stored[i], actual)
+	}
+}
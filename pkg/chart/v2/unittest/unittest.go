@@ -0,0 +1,362 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package unittest runs the unit tests of a chart: assertions against the
+templates of the chart rendered client-side, with the values of each test.
+
+The tests of a chart are the suites in its tests/*_test.yaml files:
+
+	suite: deployment
+	templates:
+	  - templates/deployment.yaml
+	values:
+	  - values/production.yaml
+	tests:
+	  - it: sets the number of replicas
+	    set:
+	      replicaCount: 3
+	    asserts:
+	      - isKind:
+	          of: Deployment
+	      - equal:
+	          path: spec.replicas
+	          value: 3
+	      - matchSnapshot: {}
+
+The values files are relative to the chart. Each assertion applies to every
+document the templates render, or to the document at its documentIndex, and
+is negated by "not: true". The snapshots of a suite are kept in the
+tests/__snapshot__ directory of the chart, and written the first time a
+snapshot assertion runs.
+*/
+package unittest // import "helm.sh/helm/v4/pkg/chart/v2/unittest"
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/engine"
+)
+
+// TestsDir is the directory of a chart holding its unit tests.
+const TestsDir = "tests"
+
+// Suite is a suite of unit tests, read from a tests/*_test.yaml file.
+type Suite struct {
+	// Name is the name of the suite. It defaults to the name of its file.
+	Name string `json:"suite,omitempty"`
+	// Templates are the templates the tests assert, relative to the chart.
+	Templates []string `json:"templates,omitempty"`
+	// Release is the release the templates are rendered for.
+	Release Release `json:"release,omitempty"`
+	// Values are the values files of all tests, relative to the chart.
+	Values []string `json:"values,omitempty"`
+	// Set are the values of all tests, by dotted path.
+	Set map[string]any `json:"set,omitempty"`
+	// Tests are the tests of the suite.
+	Tests []Test `json:"tests"`
+
+	// file is the path of the suite file.
+	file string
+}
+
+// Release is the release the templates of a test are rendered for.
+type Release struct {
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Revision  int    `json:"revision,omitempty"`
+	Upgrade   bool   `json:"upgrade,omitempty"`
+}
+
+// Test is a unit test of a suite.
+type Test struct {
+	// It describes what the test checks, and names it.
+	It string `json:"it"`
+	// Template overrides the templates of the suite for the test.
+	Template string `json:"template,omitempty"`
+	// Release overrides the fields of the release of the suite that are set.
+	Release Release `json:"release,omitempty"`
+	// Values are the values files of the test, after those of the suite.
+	Values []string `json:"values,omitempty"`
+	// Set are the values of the test, by dotted path, after those of the
+	// suite.
+	Set map[string]any `json:"set,omitempty"`
+	// Asserts are the assertions of the test.
+	Asserts []Assertion `json:"asserts"`
+}
+
+// SuiteResult is the result of a suite.
+type SuiteResult struct {
+	Name string
+	// File is the path of the suite file, relative to the chart.
+	File  string
+	Tests []TestResult
+	// Err is set when the suite could not run.
+	Err error
+}
+
+// Passed returns whether all of the tests of the suite passed.
+func (r *SuiteResult) Passed() bool {
+	if r.Err != nil {
+		return false
+	}
+	for _, t := range r.Tests {
+		if !t.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// TestResult is the result of a test.
+type TestResult struct {
+	Name string
+	// Failures describe the assertions that failed.
+	Failures []string
+}
+
+// Passed returns whether all of the assertions of the test passed.
+func (r *TestResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Options are the options of a run of the unit tests of a chart.
+type Options struct {
+	// UpdateSnapshots rewrites the snapshots that do not match instead of
+	// failing.
+	UpdateSnapshots bool
+	// Values are the values every test starts from, over the values of the
+	// chart.
+	Values map[string]any
+}
+
+// Run runs the unit tests of the chart in the given directory, in the order
+// of their files.
+func Run(chartDir string, opts Options) ([]SuiteResult, error) {
+	files, err := filepath.Glob(filepath.Join(chartDir, TestsDir, "*_test.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no unit tests found in %s", filepath.Join(chartDir, TestsDir))
+	}
+	sort.Strings(files)
+
+	ch, err := loader.LoadDir(chartDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load chart %s: %w", chartDir, err)
+	}
+
+	var results []SuiteResult
+	for _, file := range files {
+		rel, _ := filepath.Rel(chartDir, file)
+		suite, err := loadSuite(file)
+		if err != nil {
+			results = append(results, SuiteResult{Name: rel, File: rel, Err: err})
+			continue
+		}
+		results = append(results, suite.run(chartDir, ch, rel, opts))
+	}
+	return results, nil
+}
+
+// loadSuite reads a suite file.
+func loadSuite(file string) (*Suite, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	suite := &Suite{file: file}
+	if err := yaml.UnmarshalStrict(data, suite); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", file, err)
+	}
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(file), "_test.yaml")
+	}
+	for i, t := range suite.Tests {
+		if t.It == "" {
+			return nil, fmt.Errorf("test %d of %s has no name: set \"it\"", i+1, file)
+		}
+		if t.Template == "" && len(suite.Templates) == 0 {
+			return nil, fmt.Errorf("test %q of %s has no templates", t.It, file)
+		}
+	}
+	return suite, nil
+}
+
+// run runs the tests of the suite.
+func (s *Suite) run(chartDir string, ch *chart.Chart, file string, opts Options) SuiteResult {
+	result := SuiteResult{Name: s.Name, File: file}
+	snapshots, err := loadSnapshots(snapshotPath(s.file))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	for _, t := range s.Tests {
+		result.Tests = append(result.Tests, s.runTest(chartDir, ch, t, snapshots, opts))
+	}
+
+	if err := snapshots.save(); err != nil {
+		result.Err = err
+	}
+	return result
+}
+
+// runTest renders the templates of a test and evaluates its assertions.
+func (s *Suite) runTest(chartDir string, ch *chart.Chart, t Test, snapshots *snapshotStore, opts Options) TestResult {
+	result := TestResult{Name: t.It}
+	templates := s.Templates
+	if t.Template != "" {
+		templates = []string{t.Template}
+	}
+
+	vals, err := s.testValues(chartDir, t, opts.Values)
+	if err != nil {
+		result.Failures = append(result.Failures, err.Error())
+		return result
+	}
+	docs, renderErr := render(ch, vals, s.releaseOptions(t), templates)
+
+	snapshot := snapshots.forTest(t.It, opts.UpdateSnapshots)
+	for i, a := range t.Asserts {
+		if err := a.evaluate(docs, renderErr, snapshot); err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("assertion %d (%s): %v", i+1, a.kind(), err))
+		}
+	}
+	return result
+}
+
+// releaseOptions returns the release a test renders the templates for.
+func (s *Suite) releaseOptions(t Test) common.ReleaseOptions {
+	opts := common.ReleaseOptions{Name: "release-name", Namespace: "default", Revision: 1, IsInstall: true}
+	for _, r := range []Release{s.Release, t.Release} {
+		if r.Name != "" {
+			opts.Name = r.Name
+		}
+		if r.Namespace != "" {
+			opts.Namespace = r.Namespace
+		}
+		if r.Revision != 0 {
+			opts.Revision = r.Revision
+		}
+		if r.Upgrade {
+			opts.IsUpgrade, opts.IsInstall = true, false
+		}
+	}
+	return opts
+}
+
+// testValues returns the values a test renders the templates with, before
+// the values of the chart.
+func (s *Suite) testValues(chartDir string, t Test, base map[string]any) (map[string]any, error) {
+	vals := loader.MergeMaps(map[string]any{}, base)
+	for _, file := range append(append([]string{}, s.Values...), t.Values...) {
+		fileVals, err := common.ReadValuesFile(filepath.Join(chartDir, file))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read values file %s: %w", file, err)
+		}
+		vals = loader.MergeMaps(vals, fileVals)
+	}
+	for _, set := range []map[string]any{s.Set, t.Set} {
+		for path, value := range set {
+			vals = loader.MergeMaps(vals, dottedValue(path, value))
+		}
+	}
+	return vals, nil
+}
+
+// dottedValue returns the values setting the value at a dotted path.
+func dottedValue(path string, value any) map[string]any {
+	keys := strings.Split(path, ".")
+	vals := map[string]any{keys[len(keys)-1]: value}
+	for i := len(keys) - 2; i >= 0; i-- {
+		vals = map[string]any{keys[i]: vals}
+	}
+	return vals
+}
+
+// document is a document rendered by a template.
+type document struct {
+	template string
+	content  map[string]any
+}
+
+var yamlSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// render renders the chart and returns the documents of the given templates,
+// in order.
+func render(ch *chart.Chart, vals map[string]any, options common.ReleaseOptions, templates []string) ([]document, error) {
+	if err := chartutil.ProcessDependencies(ch, vals); err != nil {
+		return nil, err
+	}
+	cvals, err := util.CoalesceValues(ch, vals)
+	if err != nil {
+		return nil, err
+	}
+	valuesToRender, err := util.ToRenderValues(ch, cvals, options, common.DefaultCapabilities.Copy())
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := engine.Render(ch, valuesToRender)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []document
+	for _, tpl := range templates {
+		content, ok := rendered[pathJoin(ch.Name(), tpl)]
+		if !ok {
+			return nil, fmt.Errorf("template %s not found in chart %s", tpl, ch.Name())
+		}
+		for _, part := range yamlSeparator.Split(content, -1) {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			doc := map[string]any{}
+			if err := yaml.Unmarshal([]byte(part), &doc); err != nil {
+				return nil, fmt.Errorf("template %s rendered invalid YAML: %w", tpl, err)
+			}
+			if len(doc) > 0 {
+				docs = append(docs, document{template: tpl, content: doc})
+			}
+		}
+	}
+	return docs, nil
+}
+
+// pathJoin joins the name of a chart and the path of one of its templates
+// the way the engine names the rendered templates.
+func pathJoin(chartName, tpl string) string {
+	return chartName + "/" + filepath.ToSlash(filepath.Clean(tpl))
+}
+
+// errRendered is the error of a failedTemplate assertion whose templates
+// rendered.
+var errRendered = errors.New("the templates rendered without error")
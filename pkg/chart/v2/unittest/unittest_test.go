@@ -0,0 +1,187 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unittest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+// copyChart copies the test chart to a temporary directory, so that the
+// snapshots written by the tests do not land in testdata.
+func copyChart(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "mychart")
+	require.NoError(t, os.CopyFS(dir, os.DirFS("testdata/mychart")))
+	return dir
+}
+
+func writeSuite(t *testing.T, chartDir, name, suite string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, TestsDir, name), []byte(suite), 0644))
+}
+
+func TestRun(t *testing.T) {
+	chartDir := copyChart(t)
+
+	results, err := Run(chartDir, Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, suite := range results {
+		assert.True(t, suite.Passed(), "suite %s failed: %+v", suite.Name, suite)
+	}
+	assert.Equal(t, "configmap", results[0].Name)
+	assert.Equal(t, filepath.Join("tests", "configmap_test.yaml"), results[0].File)
+	assert.Len(t, results[0].Tests, 3)
+	assert.Equal(t, "deployment", results[1].Name)
+	assert.Equal(t, "renders the defaults", results[1].Tests[0].Name)
+
+	// The first run writes the snapshots.
+	snapshots, err := os.ReadFile(filepath.Join(chartDir, TestsDir, SnapshotDir, "deployment_test.snap"))
+	require.NoError(t, err)
+	stored := map[string][]string{}
+	require.NoError(t, yaml.Unmarshal(snapshots, &stored))
+	require.Len(t, stored["renders the defaults"], 1)
+	assert.Contains(t, stored["renders the defaults"][0], "image: nginx:1.27")
+	assert.NoFileExists(t, filepath.Join(chartDir, TestsDir, SnapshotDir, "configmap_test.snap"))
+
+	// A changed chart no longer matches the snapshot.
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, "values.yaml"),
+		[]byte("replicaCount: 1\nimage:\n  repository: nginx\n  tag: \"1.29\"\nconfig:\n  enabled: false\n"), 0644))
+	results, err = Run(chartDir, Options{})
+	require.NoError(t, err)
+	require.False(t, results[1].Passed())
+	failures := results[1].Tests[0].Failures
+	require.Len(t, failures, 1)
+	assert.Contains(t, failures[0], "assertion 9 (matchSnapshot): document 0 of templates/deployment.yaml: the document does not match snapshot 0")
+	assert.Contains(t, failures[0], "image: nginx:1.29")
+
+	// Updating the snapshots makes it pass again.
+	results, err = Run(chartDir, Options{UpdateSnapshots: true})
+	require.NoError(t, err)
+	assert.True(t, results[1].Passed())
+	results, err = Run(chartDir, Options{})
+	require.NoError(t, err)
+	assert.True(t, results[1].Passed())
+}
+
+func TestRun_Failures(t *testing.T) {
+	chartDir := copyChart(t)
+	require.NoError(t, os.RemoveAll(filepath.Join(chartDir, TestsDir)))
+	require.NoError(t, os.Mkdir(filepath.Join(chartDir, TestsDir), 0755))
+	writeSuite(t, chartDir, "failing_test.yaml", `templates:
+  - templates/deployment.yaml
+tests:
+  - it: fails
+    asserts:
+      - equal:
+          path: spec.replicas
+          value: 2
+      - exists:
+          path: spec.template.spec.containers[1]
+      - isKind:
+          of: Deployment
+        not: true
+      - failedTemplate: {}
+      - equal:
+          path: spec.replicas
+          value: 1
+        documentIndex: 1
+  - it: renders a missing template
+    template: templates/missing.yaml
+    asserts:
+      - hasDocuments:
+          count: 0
+`)
+	writeSuite(t, chartDir, "invalid_test.yaml", `tests:
+  - it: uses an unknown assertion
+    template: templates/deployment.yaml
+    asserts:
+      - isEqual:
+          path: spec.replicas
+`)
+
+	results, err := Run(chartDir, Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	failing := results[0]
+	assert.Equal(t, "failing", failing.Name)
+	require.Len(t, failing.Tests, 2)
+	assert.Equal(t, []string{
+		"assertion 1 (equal): document 0 of templates/deployment.yaml: expected spec.replicas = 2, got spec.replicas = 1",
+		"assertion 2 (exists): document 0 of templates/deployment.yaml: expected spec.template.spec.containers[1] to exist, got spec.template.spec.containers[1] missing",
+		"assertion 3 (not isKind): document 0 of templates/deployment.yaml: expected not kind Deployment, got kind Deployment",
+		"assertion 4 (failedTemplate): the templates rendered without error",
+		"assertion 5 (equal): documentIndex 1 out of range: the templates rendered 1 documents",
+	}, failing.Tests[0].Failures)
+	require.Len(t, failing.Tests[1].Failures, 1)
+	assert.Contains(t, failing.Tests[1].Failures[0], "template templates/missing.yaml not found in chart mychart")
+
+	invalid := results[1]
+	assert.False(t, invalid.Passed())
+	assert.ErrorContains(t, invalid.Err, `unknown assertion "isEqual"`)
+}
+
+func TestRun_NoTests(t *testing.T) {
+	chartDir := copyChart(t)
+	require.NoError(t, os.RemoveAll(filepath.Join(chartDir, TestsDir)))
+
+	_, err := Run(chartDir, Options{})
+	assert.ErrorContains(t, err, "no unit tests found")
+}
+
+func TestParsePath(t *testing.T) {
+	elems, err := parsePath(`spec.containers[0].env[1]["x.y/z"].value`)
+	require.NoError(t, err)
+	assert.Equal(t, []pathElement{
+		{key: "spec"}, {key: "containers"}, {index: 0}, {key: "env"}, {index: 1}, {key: "x.y/z"}, {key: "value"},
+	}, elems)
+
+	for _, path := range []string{"spec.", "spec..replicas", "spec[a]", `spec["a`, "spec[0"} {
+		_, err := parsePath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestAssertionUnmarshal(t *testing.T) {
+	for assertion, expected := range map[string]string{
+		`{"equal": {"value": 1}}`:                           "equal assertion needs a path",
+		`{"equal": {"path": "a"}, "isKind": {"of": "Pod"}}`: "assertion has several kinds",
+		`{"not": true}`:                                     "assertion has no kind",
+		`{"hasDocuments": {}}`:                              "hasDocuments assertion needs a count",
+		`{"matchRegex": {"path": "a", "pattern": "("}}`:     "invalid matchRegex assertion",
+		`{"isKind": {"of": "Pod", "kind": "Pod"}}`:          "unknown field",
+	} {
+		var a Assertion
+		err := yaml.Unmarshal([]byte(assertion), &a)
+		assert.ErrorContains(t, err, expected, assertion)
+	}
+
+	var a Assertion
+	require.NoError(t, yaml.Unmarshal([]byte("matchSnapshot:\nnot: true\ndocumentIndex: 2\n"), &a))
+	assert.Equal(t, "matchSnapshot", a.Kind)
+	assert.True(t, a.Not)
+	assert.Equal(t, 2, *a.DocumentIndex)
+	assert.True(t, strings.HasPrefix(a.kind(), "not "))
+}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"sigs.k8s.io/yaml"
@@ -647,19 +648,90 @@ spec:
 // compatibility.
 var Stderr io.Writer = os.Stderr
 
+// StarterfileName is the file of a starter declaring its parameters. It is
+// not copied to the charts created from the starter.
+const StarterfileName = "starter.yaml"
+
+// StarterParameter is a parameter of a starter, declared in its starter.yaml
+// file:
+//
+//	parameters:
+//	  - name: IMAGE
+//	    description: The image of the application
+//	    default: nginx
+//
+// The value of a parameter replaces its placeholder, e.g. <IMAGE>, in the
+// templates and the values of the starter, like the name of the chart
+// replaces <CHARTNAME>.
+type StarterParameter struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+var starterParameterName = regexp.MustCompile("^[A-Z][A-Z0-9_]*$")
+
+// LoadStarterParameters returns the parameters of the starter at src.
+func LoadStarterParameters(src string) ([]StarterParameter, error) {
+	schart, err := loader.Load(src)
+	if err != nil {
+		return nil, fmt.Errorf("could not load %s: %w", src, err)
+	}
+	return starterParameters(schart)
+}
+
+// starterParameters returns the parameters a starter declares.
+func starterParameters(schart *chart.Chart) ([]StarterParameter, error) {
+	for _, f := range schart.Files {
+		if f.Name != StarterfileName {
+			continue
+		}
+		var starterfile struct {
+			Parameters []StarterParameter `json:"parameters"`
+		}
+		if err := yaml.UnmarshalStrict(f.Data, &starterfile); err != nil {
+			return nil, fmt.Errorf("cannot load %s: %w", StarterfileName, err)
+		}
+		for _, p := range starterfile.Parameters {
+			if p.Name == "CHARTNAME" || !starterParameterName.MatchString(p.Name) {
+				return nil, fmt.Errorf("invalid starter parameter %q: the name must match the regular expression %q and not be CHARTNAME", p.Name, starterParameterName.String())
+			}
+		}
+		return starterfile.Parameters, nil
+	}
+	return nil, nil
+}
+
 // CreateFrom creates a new chart, but scaffolds it from the src chart.
 func CreateFrom(chartfile *chart.Metadata, dest, src string) error {
+	return CreateFromWithParameters(chartfile, dest, src, nil)
+}
+
+// CreateFromWithParameters creates a new chart like CreateFrom, replacing
+// the placeholders of the parameters of the starter with the given values,
+// or their defaults.
+func CreateFromWithParameters(chartfile *chart.Metadata, dest, src string, params map[string]string) error {
 	schart, err := loader.Load(src)
 	if err != nil {
 		return fmt.Errorf("could not load %s: %w", src, err)
 	}
 
+	declared, err := starterParameters(schart)
+	if err != nil {
+		return err
+	}
+
 	schart.Metadata = chartfile
 
+	replacements, err := starterReplacements(schart.Name(), declared, params)
+	if err != nil {
+		return err
+	}
+
 	var updatedTemplates []*common.File
 
 	for _, template := range schart.Templates {
-		newData := transform(string(template.Data), schart.Name())
+		newData := []byte(replacements.Replace(string(template.Data)))
 		updatedTemplates = append(updatedTemplates, &common.File{Name: template.Name, ModTime: template.ModTime, Data: newData})
 	}
 
@@ -670,7 +742,7 @@ func CreateFrom(chartfile *chart.Metadata, dest, src string) error {
 	}
 
 	var m map[string]any
-	if err := yaml.Unmarshal(transform(string(b), schart.Name()), &m); err != nil {
+	if err := yaml.Unmarshal([]byte(replacements.Replace(string(b))), &m); err != nil {
 		return fmt.Errorf("transforming values file: %w", err)
 	}
 	schart.Values = m
@@ -680,13 +752,40 @@ func CreateFrom(chartfile *chart.Metadata, dest, src string) error {
 	// needs to be replaced on that file.
 	for _, f := range schart.Raw {
 		if f.Name == ValuesfileName {
-			f.Data = transform(string(f.Data), schart.Name())
+			f.Data = []byte(replacements.Replace(string(f.Data)))
 		}
 	}
 
+	schart.Files = slices.DeleteFunc(schart.Files, func(f *common.File) bool {
+		return f.Name == StarterfileName
+	})
+
 	return SaveDir(schart, dest)
 }
 
+// starterReplacements returns the replacements of the placeholders of a
+// starter: its name, and its parameters.
+func starterReplacements(name string, declared []StarterParameter, params map[string]string) (*strings.Replacer, error) {
+	for p := range params {
+		if !slices.ContainsFunc(declared, func(d StarterParameter) bool { return d.Name == p }) {
+			return nil, fmt.Errorf("unknown starter parameter %q", p)
+		}
+	}
+
+	replacements := []string{"<CHARTNAME>", name}
+	for _, d := range declared {
+		value, ok := params[d.Name]
+		if !ok {
+			if d.Default == "" {
+				return nil, fmt.Errorf("starter parameter %s is not set", d.Name)
+			}
+			value = d.Default
+		}
+		replacements = append(replacements, "<"+d.Name+">", value)
+	}
+	return strings.NewReplacer(replacements...), nil
+}
+
 // Create creates a new chart in a directory.
 //
 // Inside of dir, this will create a directory based on the name of
@@ -701,25 +800,9 @@ func CreateFrom(chartfile *chart.Metadata, dest, src string) error {
 // error. In such a case, this will attempt to clean up by removing the
 // new chart directory.
 func Create(name, dir string) (string, error) {
-	// Sanity-check the name of a chart so user doesn't create one that causes problems.
-	if err := validateChartName(name); err != nil {
-		return "", err
-	}
-
-	path, err := filepath.Abs(dir)
+	cdir, err := chartDir(name, dir)
 	if err != nil {
-		return path, err
-	}
-
-	if fi, err := os.Stat(path); err != nil {
-		return path, err
-	} else if !fi.IsDir() {
-		return path, fmt.Errorf("no such directory %s", path)
-	}
-
-	cdir := filepath.Join(path, name)
-	if fi, err := os.Stat(cdir); err == nil && !fi.IsDir() {
-		return cdir, fmt.Errorf("file %s already exists and is not a directory", cdir)
+		return cdir, err
 	}
 
 	// Note: If adding a new template below (i.e., to `helm create`) which is disabled by default (similar to hpa and
@@ -808,6 +891,32 @@ func Create(name, dir string) (string, error) {
 	return cdir, nil
 }
 
+// chartDir returns the absolute path of the directory of a new chart in dir,
+// checking that the chart can be created there.
+func chartDir(name, dir string) (string, error) {
+	// Sanity-check the name of a chart so user doesn't create one that causes problems.
+	if err := validateChartName(name); err != nil {
+		return "", err
+	}
+
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return path, err
+	}
+
+	if fi, err := os.Stat(path); err != nil {
+		return path, err
+	} else if !fi.IsDir() {
+		return path, fmt.Errorf("no such directory %s", path)
+	}
+
+	cdir := filepath.Join(path, name)
+	if fi, err := os.Stat(cdir); err == nil && !fi.IsDir() {
+		return cdir, fmt.Errorf("file %s already exists and is not a directory", cdir)
+	}
+	return cdir, nil
+}
+
 // transform performs a string replacement of the specified source for
 // a given key with the replacement string
 func transform(src, replacement string) []byte {
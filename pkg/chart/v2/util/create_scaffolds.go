@@ -0,0 +1,695 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The scaffolds of the charts Create creates.
+const (
+	// ScaffoldApplication is a stateless application: a Deployment behind a
+	// Service, with an optional Ingress, HTTPRoute and autoscaling.
+	ScaffoldApplication = "application"
+	// ScaffoldStatefulSet is a stateful application: a StatefulSet with
+	// persistent volumes behind a headless Service.
+	ScaffoldStatefulSet = "statefulset"
+	// ScaffoldCronJob is a job run on a schedule.
+	ScaffoldCronJob = "cronjob"
+	// ScaffoldOperator is a Kubernetes operator: a controller Deployment
+	// with the RBAC rules to watch its resources, and their CRDs.
+	ScaffoldOperator = "operator"
+	// ScaffoldLibrary is a library chart, providing templates to the charts
+	// depending on it.
+	ScaffoldLibrary = "library"
+)
+
+// Scaffolds are the scaffolds of the charts Create creates.
+var Scaffolds = []string{ScaffoldApplication, ScaffoldStatefulSet, ScaffoldCronJob, ScaffoldOperator, ScaffoldLibrary}
+
+const (
+	// StatefulSetName is the name of the example statefulset file.
+	StatefulSetName = TemplatesDir + sep + "statefulset.yaml"
+	// CronJobName is the name of the example cronjob file.
+	CronJobName = TemplatesDir + sep + "cronjob.yaml"
+	// ClusterRoleName is the name of the example clusterrole file.
+	ClusterRoleName = TemplatesDir + sep + "clusterrole.yaml"
+	// ClusterRoleBindingName is the name of the example clusterrolebinding file.
+	ClusterRoleBindingName = TemplatesDir + sep + "clusterrolebinding.yaml"
+	// CRDsDir is the relative directory name for custom resource definitions.
+	CRDsDir = "crds"
+)
+
+const statefulSetValues = `# Default values for %s.
+# This is a YAML-formatted file.
+# Declare variables to be passed into your templates.
+
+# The number of pods of the StatefulSet: https://kubernetes.io/docs/concepts/workloads/controllers/statefulset/
+replicaCount: 1
+
+image:
+  repository: nginx
+  pullPolicy: IfNotPresent
+  # Overrides the image tag whose default is the chart appVersion.
+  tag: ""
+
+imagePullSecrets: []
+nameOverride: ""
+fullnameOverride: ""
+
+serviceAccount:
+  # Specifies whether a service account should be created.
+  create: true
+  # Automatically mount a ServiceAccount's API credentials?
+  automount: true
+  # Annotations to add to the service account.
+  annotations: {}
+  # The name of the service account to use.
+  # If not set and create is true, a name is generated using the fullname template.
+  name: ""
+
+podAnnotations: {}
+podLabels: {}
+podSecurityContext: {}
+securityContext: {}
+
+# The headless Service giving each pod a stable network identity.
+service:
+  port: 80
+
+# The volume claim of each pod: https://kubernetes.io/docs/concepts/storage/persistent-volumes/
+persistence:
+  enabled: true
+  # The storage class of the volumes; the default storage class if empty.
+  storageClass: ""
+  accessModes:
+    - ReadWriteOnce
+  size: 1Gi
+  mountPath: /data
+
+resources: {}
+  # limits:
+  #   cpu: 100m
+  #   memory: 128Mi
+  # requests:
+  #   cpu: 100m
+  #   memory: 128Mi
+
+livenessProbe:
+  httpGet:
+    path: /
+    port: http
+readinessProbe:
+  httpGet:
+    path: /
+    port: http
+
+nodeSelector: {}
+tolerations: []
+affinity: {}
+`
+
+const defaultStatefulSet = `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  serviceName: {{ include "<CHARTNAME>.fullname" . }}
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      {{- include "<CHARTNAME>.selectorLabels" . | nindent 6 }}
+  template:
+    metadata:
+      {{- with .Values.podAnnotations }}
+      annotations:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      labels:
+        {{- include "<CHARTNAME>.labels" . | nindent 8 }}
+        {{- with .Values.podLabels }}
+        {{- toYaml . | nindent 8 }}
+        {{- end }}
+    spec:
+      {{- with .Values.imagePullSecrets }}
+      imagePullSecrets:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      serviceAccountName: {{ include "<CHARTNAME>.serviceAccountName" . }}
+      {{- with .Values.podSecurityContext }}
+      securityContext:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      containers:
+        - name: {{ .Chart.Name }}
+          {{- with .Values.securityContext }}
+          securityContext:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}"
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          ports:
+            - name: http
+              containerPort: {{ .Values.service.port }}
+              protocol: TCP
+          {{- with .Values.livenessProbe }}
+          livenessProbe:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          {{- with .Values.readinessProbe }}
+          readinessProbe:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          {{- with .Values.resources }}
+          resources:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          {{- if .Values.persistence.enabled }}
+          volumeMounts:
+            - name: data
+              mountPath: {{ .Values.persistence.mountPath }}
+          {{- end }}
+      {{- with .Values.nodeSelector }}
+      nodeSelector:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      {{- with .Values.affinity }}
+      affinity:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      {{- with .Values.tolerations }}
+      tolerations:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+  {{- if .Values.persistence.enabled }}
+  volumeClaimTemplates:
+    - metadata:
+        name: data
+      spec:
+        accessModes:
+          {{- toYaml .Values.persistence.accessModes | nindent 10 }}
+        {{- with .Values.persistence.storageClass }}
+        storageClassName: {{ . }}
+        {{- end }}
+        resources:
+          requests:
+            storage: {{ .Values.persistence.size }}
+  {{- end }}
+`
+
+const defaultHeadlessService = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  clusterIP: None
+  ports:
+    - port: {{ .Values.service.port }}
+      targetPort: http
+      protocol: TCP
+      name: http
+  selector:
+    {{- include "<CHARTNAME>.selectorLabels" . | nindent 4 }}
+`
+
+const statefulSetNotes = `1. The pods of the StatefulSet are reachable at:
+  {{ include "<CHARTNAME>.fullname" . }}-<ORDINAL>.{{ include "<CHARTNAME>.fullname" . }}.{{ .Release.Namespace }}.svc.cluster.local:{{ .Values.service.port }}
+
+2. Watch them start by running:
+  kubectl get pods --namespace {{ .Release.Namespace }} -l "app.kubernetes.io/instance={{ .Release.Name }}" -w
+`
+
+const cronJobValues = `# Default values for %s.
+# This is a YAML-formatted file.
+# Declare variables to be passed into your templates.
+
+# The schedule of the job, in the cron format: https://kubernetes.io/docs/concepts/workloads/controllers/cron-jobs/
+schedule: "0 * * * *"
+# Whether to skip, allow or replace a run while the previous one is running: Forbid, Allow or Replace.
+concurrencyPolicy: Forbid
+suspend: false
+successfulJobsHistoryLimit: 3
+failedJobsHistoryLimit: 1
+# The number of retries of a failed run.
+backoffLimit: 2
+
+image:
+  repository: busybox
+  pullPolicy: IfNotPresent
+  # Overrides the image tag whose default is the chart appVersion.
+  tag: ""
+
+# The command the job runs.
+command:
+  - /bin/sh
+  - -c
+  - date; echo Hello from the Kubernetes cluster
+args: []
+env: []
+
+imagePullSecrets: []
+nameOverride: ""
+fullnameOverride: ""
+
+serviceAccount:
+  # Specifies whether a service account should be created.
+  create: true
+  # Automatically mount a ServiceAccount's API credentials?
+  automount: true
+  # Annotations to add to the service account.
+  annotations: {}
+  # The name of the service account to use.
+  # If not set and create is true, a name is generated using the fullname template.
+  name: ""
+
+podAnnotations: {}
+podLabels: {}
+podSecurityContext: {}
+securityContext: {}
+
+resources: {}
+  # limits:
+  #   cpu: 100m
+  #   memory: 128Mi
+
+nodeSelector: {}
+tolerations: []
+affinity: {}
+`
+
+const defaultCronJob = `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  schedule: {{ .Values.schedule | quote }}
+  concurrencyPolicy: {{ .Values.concurrencyPolicy }}
+  suspend: {{ .Values.suspend }}
+  successfulJobsHistoryLimit: {{ .Values.successfulJobsHistoryLimit }}
+  failedJobsHistoryLimit: {{ .Values.failedJobsHistoryLimit }}
+  jobTemplate:
+    spec:
+      backoffLimit: {{ .Values.backoffLimit }}
+      template:
+        metadata:
+          {{- with .Values.podAnnotations }}
+          annotations:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          labels:
+            {{- include "<CHARTNAME>.labels" . | nindent 12 }}
+            {{- with .Values.podLabels }}
+            {{- toYaml . | nindent 12 }}
+            {{- end }}
+        spec:
+          restartPolicy: OnFailure
+          {{- with .Values.imagePullSecrets }}
+          imagePullSecrets:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          serviceAccountName: {{ include "<CHARTNAME>.serviceAccountName" . }}
+          {{- with .Values.podSecurityContext }}
+          securityContext:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          containers:
+            - name: {{ .Chart.Name }}
+              {{- with .Values.securityContext }}
+              securityContext:
+                {{- toYaml . | nindent 16 }}
+              {{- end }}
+              image: "{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}"
+              imagePullPolicy: {{ .Values.image.pullPolicy }}
+              {{- with .Values.command }}
+              command:
+                {{- toYaml . | nindent 16 }}
+              {{- end }}
+              {{- with .Values.args }}
+              args:
+                {{- toYaml . | nindent 16 }}
+              {{- end }}
+              {{- with .Values.env }}
+              env:
+                {{- toYaml . | nindent 16 }}
+              {{- end }}
+              {{- with .Values.resources }}
+              resources:
+                {{- toYaml . | nindent 16 }}
+              {{- end }}
+          {{- with .Values.nodeSelector }}
+          nodeSelector:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          {{- with .Values.affinity }}
+          affinity:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          {{- with .Values.tolerations }}
+          tolerations:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+`
+
+const cronJobNotes = `1. The job runs on the schedule "{{ .Values.schedule }}". See its runs with:
+  kubectl get jobs --namespace {{ .Release.Namespace }} -l "app.kubernetes.io/instance={{ .Release.Name }}"
+
+2. Run it now with:
+  kubectl create job --namespace {{ .Release.Namespace }} --from=cronjob/{{ include "<CHARTNAME>.fullname" . }} {{ include "<CHARTNAME>.fullname" . }}-manual
+`
+
+const operatorValues = `# Default values for %s.
+# This is a YAML-formatted file.
+# Declare variables to be passed into your templates.
+
+# The number of replicas of the controller. Leader election keeps one active.
+replicaCount: 1
+
+image:
+  repository: example.com/operator
+  pullPolicy: IfNotPresent
+  # Overrides the image tag whose default is the chart appVersion.
+  tag: ""
+
+# The arguments of the controller.
+args:
+  - --leader-elect
+  - --health-probe-bind-address=:8081
+
+imagePullSecrets: []
+nameOverride: ""
+fullnameOverride: ""
+
+serviceAccount:
+  # Specifies whether a service account should be created.
+  create: true
+  # Automatically mount a ServiceAccount's API credentials?
+  automount: true
+  # Annotations to add to the service account.
+  annotations: {}
+  # The name of the service account to use.
+  # If not set and create is true, a name is generated using the fullname template.
+  name: ""
+
+rbac:
+  # Specifies whether the ClusterRole of the controller and its binding should be created.
+  create: true
+  # The rules of the ClusterRole, granting access to the resources the controller watches.
+  rules:
+    - apiGroups: [""]
+      resources: ["events"]
+      verbs: ["create", "patch"]
+    - apiGroups: ["coordination.k8s.io"]
+      resources: ["leases"]
+      verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+
+podAnnotations: {}
+podLabels: {}
+podSecurityContext:
+  runAsNonRoot: true
+securityContext:
+  allowPrivilegeEscalation: false
+  capabilities:
+    drop:
+      - ALL
+
+resources:
+  limits:
+    cpu: 500m
+    memory: 128Mi
+  requests:
+    cpu: 10m
+    memory: 64Mi
+
+nodeSelector: {}
+tolerations: []
+affinity: {}
+`
+
+const operatorDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      {{- include "<CHARTNAME>.selectorLabels" . | nindent 6 }}
+  template:
+    metadata:
+      {{- with .Values.podAnnotations }}
+      annotations:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      labels:
+        {{- include "<CHARTNAME>.labels" . | nindent 8 }}
+        {{- with .Values.podLabels }}
+        {{- toYaml . | nindent 8 }}
+        {{- end }}
+    spec:
+      {{- with .Values.imagePullSecrets }}
+      imagePullSecrets:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      serviceAccountName: {{ include "<CHARTNAME>.serviceAccountName" . }}
+      {{- with .Values.podSecurityContext }}
+      securityContext:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      containers:
+        - name: manager
+          {{- with .Values.securityContext }}
+          securityContext:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}"
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          {{- with .Values.args }}
+          args:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          ports:
+            - name: health
+              containerPort: 8081
+              protocol: TCP
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: health
+          readinessProbe:
+            httpGet:
+              path: /readyz
+              port: health
+          {{- with .Values.resources }}
+          resources:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+      {{- with .Values.nodeSelector }}
+      nodeSelector:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      {{- with .Values.affinity }}
+      affinity:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      {{- with .Values.tolerations }}
+      tolerations:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+`
+
+const defaultClusterRole = `{{- if .Values.rbac.create -}}
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+rules:
+  {{- toYaml .Values.rbac.rules | nindent 2 }}
+{{- end }}
+`
+
+const defaultClusterRoleBinding = `{{- if .Values.rbac.create -}}
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: {{ include "<CHARTNAME>.fullname" . }}
+subjects:
+  - kind: ServiceAccount
+    name: {{ include "<CHARTNAME>.serviceAccountName" . }}
+    namespace: {{ .Release.Namespace }}
+{{- end }}
+`
+
+const operatorNotes = `1. The controller of {{ .Chart.Name }} is running in the namespace {{ .Release.Namespace }}. See its logs with:
+  kubectl logs --namespace {{ .Release.Namespace }} deployment/{{ include "<CHARTNAME>.fullname" . }}
+
+2. Put the CustomResourceDefinitions of the resources it manages in the crds directory of the chart.
+`
+
+const libraryValues = `# Default values for %s.
+# A library chart renders no resources: the values of the charts depending on
+# it are passed to its templates.
+`
+
+const libraryHelpers = `{{/*
+The templates of a library chart are included by the charts depending on it,
+e.g. {{ include "<CHARTNAME>.labels" . }}.
+*/}}
+
+{{/*
+Expand the name of the chart.
+*/}}
+{{- define "<CHARTNAME>.name" -}}
+{{- default .Chart.Name .Values.nameOverride | trunc 63 | trimSuffix "-" }}
+{{- end }}
+
+{{/*
+Create a default fully qualified app name.
+*/}}
+{{- define "<CHARTNAME>.fullname" -}}
+{{- if .Values.fullnameOverride }}
+{{- .Values.fullnameOverride | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- $name := default .Chart.Name .Values.nameOverride }}
+{{- if contains $name .Release.Name }}
+{{- .Release.Name | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- printf "%s-%s" .Release.Name $name | trunc 63 | trimSuffix "-" }}
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+Common labels
+*/}}
+{{- define "<CHARTNAME>.labels" -}}
+helm.sh/chart: {{ printf "%s-%s" .Chart.Name .Chart.Version | replace "+" "_" | trunc 63 | trimSuffix "-" }}
+app.kubernetes.io/name: {{ include "<CHARTNAME>.name" . }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- if .Chart.AppVersion }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+{{- end }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- end }}
+`
+
+// scaffoldFile is a file of a scaffold, relative to the chart.
+type scaffoldFile struct {
+	path    string
+	content []byte
+}
+
+// scaffoldFiles returns the files of a scaffold, other than the application.
+func scaffoldFiles(name, scaffold string) ([]scaffoldFile, error) {
+	common := []scaffoldFile{
+		{path: ChartfileName, content: fmt.Appendf(nil, defaultChartfile, name)},
+		{path: IgnorefileName, content: []byte(defaultIgnore)},
+	}
+	switch scaffold {
+	case ScaffoldStatefulSet:
+		return append(common,
+			scaffoldFile{path: ValuesfileName, content: fmt.Appendf(nil, statefulSetValues, name)},
+			scaffoldFile{path: StatefulSetName, content: transform(defaultStatefulSet, name)},
+			scaffoldFile{path: ServiceName, content: transform(defaultHeadlessService, name)},
+			scaffoldFile{path: ServiceAccountName, content: transform(defaultServiceAccount, name)},
+			scaffoldFile{path: NotesName, content: transform(statefulSetNotes, name)},
+			scaffoldFile{path: HelpersName, content: transform(defaultHelpers, name)},
+			scaffoldFile{path: TestConnectionName, content: transform(defaultTestConnection, name)},
+		), nil
+	case ScaffoldCronJob:
+		return append(common,
+			scaffoldFile{path: ValuesfileName, content: fmt.Appendf(nil, cronJobValues, name)},
+			scaffoldFile{path: CronJobName, content: transform(defaultCronJob, name)},
+			scaffoldFile{path: ServiceAccountName, content: transform(defaultServiceAccount, name)},
+			scaffoldFile{path: NotesName, content: transform(cronJobNotes, name)},
+			scaffoldFile{path: HelpersName, content: transform(defaultHelpers, name)},
+		), nil
+	case ScaffoldOperator:
+		return append(common,
+			scaffoldFile{path: ValuesfileName, content: fmt.Appendf(nil, operatorValues, name)},
+			scaffoldFile{path: DeploymentName, content: transform(operatorDeployment, name)},
+			scaffoldFile{path: ServiceAccountName, content: transform(defaultServiceAccount, name)},
+			scaffoldFile{path: ClusterRoleName, content: transform(defaultClusterRole, name)},
+			scaffoldFile{path: ClusterRoleBindingName, content: transform(defaultClusterRoleBinding, name)},
+			scaffoldFile{path: NotesName, content: transform(operatorNotes, name)},
+			scaffoldFile{path: HelpersName, content: transform(defaultHelpers, name)},
+		), nil
+	case ScaffoldLibrary:
+		return []scaffoldFile{
+			{path: ChartfileName, content: []byte(strings.Replace(fmt.Sprintf(defaultChartfile, name), "\ntype: application\n", "\ntype: library\n", 1))},
+			{path: IgnorefileName, content: []byte(defaultIgnore)},
+			{path: ValuesfileName, content: fmt.Appendf(nil, libraryValues, name)},
+			{path: HelpersName, content: transform(libraryHelpers, name)},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown scaffold %q, expected one of: %s", scaffold, strings.Join(Scaffolds, ", "))
+}
+
+// CreateScaffold creates a new chart in a directory like Create, from one of
+// the Scaffolds.
+func CreateScaffold(name, dir, scaffold string) (string, error) {
+	if scaffold == "" || scaffold == ScaffoldApplication {
+		return Create(name, dir)
+	}
+	files, err := scaffoldFiles(name, scaffold)
+	if err != nil {
+		return "", err
+	}
+	cdir, err := chartDir(name, dir)
+	if err != nil {
+		return cdir, err
+	}
+
+	for _, file := range files {
+		path := filepath.Join(cdir, file.path)
+		if _, err := os.Stat(path); err == nil {
+			// There is no handle to a preferred output stream here.
+			fmt.Fprintf(Stderr, "WARNING: File %q already exists. Overwriting.\n", path)
+		}
+		if err := writeFile(path, file.content); err != nil {
+			return cdir, err
+		}
+	}
+	dirs := []string{ChartsDir}
+	if scaffold == ScaffoldOperator {
+		dirs = append(dirs, CRDsDir)
+	}
+	for _, d := range dirs {
+		if err := os.MkdirAll(filepath.Join(cdir, d), 0755); err != nil {
+			return cdir, err
+		}
+	}
+	return cdir, nil
+}
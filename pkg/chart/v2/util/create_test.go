@@ -109,6 +109,86 @@ func TestCreateFrom(t *testing.T) {
 	}
 }
 
+func TestCreateScaffold(t *testing.T) {
+	for _, scaffold := range Scaffolds {
+		t.Run(scaffold, func(t *testing.T) {
+			tdir := t.TempDir()
+
+			dir, err := CreateScaffold("foo", tdir, scaffold)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mychart, err := loader.LoadDir(dir)
+			if err != nil {
+				t.Fatalf("Failed to load newly created chart %q: %s", dir, err)
+			}
+			if mychart.Name() != "foo" {
+				t.Errorf("Expected name to be 'foo', got %q", mychart.Name())
+			}
+			wantType := "application"
+			if scaffold == ScaffoldLibrary {
+				wantType = "library"
+			}
+			if mychart.Metadata.Type != wantType {
+				t.Errorf("Expected type %q, got %q", wantType, mychart.Metadata.Type)
+			}
+		})
+	}
+
+	if _, err := CreateScaffold("foo", t.TempDir(), "daemonset"); err == nil {
+		t.Error("Expected an error for an unknown scaffold")
+	}
+}
+
+func TestCreateFromWithParameters(t *testing.T) {
+	srcdir := t.TempDir()
+	files := map[string]string{
+		ChartfileName:   "apiVersion: v2\nname: starter\nversion: 0.1.0\n",
+		ValuesfileName:  "image: <IMAGE>\nport: <PORT>\n",
+		StarterfileName: "parameters:\n  - name: IMAGE\n  - name: PORT\n    default: \"80\"\n",
+		filepath.Join(TemplatesDir, "config.yaml"): "name: <CHARTNAME>\n",
+	}
+	for name, content := range files {
+		if err := writeFile(filepath.Join(srcdir, name), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cf := &chart.Metadata{
+		APIVersion: chart.APIVersionV2,
+		Name:       "foo",
+		Version:    "0.1.0",
+	}
+
+	if err := CreateFromWithParameters(cf, t.TempDir(), srcdir, nil); err == nil || err.Error() != "starter parameter IMAGE is not set" {
+		t.Errorf("Expected an error for the unset parameter, got %v", err)
+	}
+	if err := CreateFromWithParameters(cf, t.TempDir(), srcdir, map[string]string{"IMAGE": "nginx", "TAG": "1"}); err == nil {
+		t.Error("Expected an error for an unknown parameter")
+	}
+
+	tdir := t.TempDir()
+	if err := CreateFromWithParameters(cf, tdir, srcdir, map[string]string{"IMAGE": "nginx"}); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(tdir, "foo")
+	for f, want := range map[string]string{
+		ValuesfileName: "image: nginx\nport: 80\n",
+		filepath.Join(TemplatesDir, "config.yaml"): "name: foo\n",
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != want {
+			t.Errorf("Expected %s to be %q, got %q", f, want, b)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, StarterfileName)); !os.IsNotExist(err) {
+		t.Errorf("Expected no %s file, got %v", StarterfileName, err)
+	}
+}
+
 // TestCreate_Overwrite is a regression test for making sure that files are overwritten.
 func TestCreate_Overwrite(t *testing.T) {
 	tdir := t.TempDir()
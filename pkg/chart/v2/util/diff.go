@@ -0,0 +1,238 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"unicode/utf8"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// DiffChange describes how a part of a chart changed between two charts.
+type DiffChange string
+
+const (
+	// DiffAdded means the part only exists in the new chart
+	DiffAdded DiffChange = "added"
+	// DiffRemoved means the part only exists in the old chart
+	DiffRemoved DiffChange = "removed"
+	// DiffModified means the part exists in both charts with different content
+	DiffModified DiffChange = "modified"
+)
+
+// MetadataChange is a field of the Chart.yaml file that changed.
+type MetadataChange struct {
+	Field  string     `json:"field"`
+	Change DiffChange `json:"change"`
+	Old    any        `json:"old,omitempty"`
+	New    any        `json:"new,omitempty"`
+}
+
+// FileChange is a file of a chart that was added, removed or modified. The
+// files of subcharts are prefixed with their path in the chart, e.g.
+// "charts/mariadb/values.yaml".
+type FileChange struct {
+	Name   string     `json:"name"`
+	Change DiffChange `json:"change"`
+	// Old is the content of the file in the old chart, empty if it was added
+	// or is binary
+	Old string `json:"old,omitempty"`
+	// New is the content of the file in the new chart, empty if it was
+	// removed or is binary
+	New string `json:"new,omitempty"`
+	// Binary is true if the file is not text, and its content is left out
+	Binary bool `json:"binary,omitempty"`
+}
+
+// ValueChange is a default value of a chart that was added, removed or
+// modified, identified by its path in the values, e.g. "image.tag".
+type ValueChange struct {
+	Path   string     `json:"path"`
+	Change DiffChange `json:"change"`
+	Old    any        `json:"old,omitempty"`
+	New    any        `json:"new,omitempty"`
+}
+
+// ChartDiff is the difference between two charts.
+type ChartDiff struct {
+	// Metadata are the changed fields of the Chart.yaml file, ordered by name
+	Metadata []MetadataChange `json:"metadata"`
+	// Files are the changed files, other than Chart.yaml, ordered by name
+	Files []FileChange `json:"files"`
+	// Values are the changed default values, ordered by path
+	Values []ValueChange `json:"values"`
+}
+
+// Changed reports whether the charts differ.
+func (d *ChartDiff) Changed() bool {
+	return len(d.Metadata) > 0 || len(d.Files) > 0 || len(d.Values) > 0
+}
+
+// DiffCharts compares two charts: the fields of their Chart.yaml files, their
+// files, including the files of their subcharts, and their default values.
+func DiffCharts(oldChart, newChart *chart.Chart) (*ChartDiff, error) {
+	metadata, err := diffMetadata(oldChart.Metadata, newChart.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &ChartDiff{
+		Metadata: metadata,
+		Files:    diffFiles(chartFiles(oldChart, ""), chartFiles(newChart, "")),
+		Values:   diffValues(flattenValues(oldChart.Values, ""), flattenValues(newChart.Values, "")),
+	}, nil
+}
+
+// diffMetadata compares the fields of two Chart.yaml files.
+func diffMetadata(oldMetadata, newMetadata *chart.Metadata) ([]MetadataChange, error) {
+	oldFields, err := metadataFields(oldMetadata)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := metadataFields(newMetadata)
+	if err != nil {
+		return nil, err
+	}
+	changes := []MetadataChange{}
+	for _, field := range sortedKeys(oldFields, newFields) {
+		o, inOld := oldFields[field]
+		n, inNew := newFields[field]
+		if change, ok := diffChange(inOld, inNew, reflect.DeepEqual(o, n)); ok {
+			changes = append(changes, MetadataChange{Field: field, Change: change, Old: o, New: n})
+		}
+	}
+	return changes, nil
+}
+
+// metadataFields returns the fields of a Chart.yaml file by their names in the
+// file.
+func metadataFields(md *chart.Metadata) (map[string]any, error) {
+	fields := map[string]any{}
+	if md == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(md)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// chartFiles returns the files of a chart and of its subcharts by name,
+// leaving out the Chart.yaml file of the chart.
+func chartFiles(c *chart.Chart, prefix string) map[string][]byte {
+	files := map[string][]byte{}
+	for _, f := range c.Raw {
+		if prefix == "" && f.Name == ChartfileName {
+			continue
+		}
+		files[prefix+f.Name] = f.Data
+	}
+	for _, dep := range c.Dependencies() {
+		maps.Copy(files, chartFiles(dep, path.Join(prefix, ChartsDir, dep.Name())+"/"))
+	}
+	return files
+}
+
+// diffFiles compares the files of two charts.
+func diffFiles(oldFiles, newFiles map[string][]byte) []FileChange {
+	changes := []FileChange{}
+	for _, name := range sortedKeys(oldFiles, newFiles) {
+		o, inOld := oldFiles[name]
+		n, inNew := newFiles[name]
+		change, ok := diffChange(inOld, inNew, string(o) == string(n))
+		if !ok {
+			continue
+		}
+		fc := FileChange{Name: name, Change: change}
+		if isText(o) && isText(n) {
+			fc.Old, fc.New = string(o), string(n)
+		} else {
+			fc.Binary = true
+		}
+		changes = append(changes, fc)
+	}
+	return changes
+}
+
+// isText reports whether the content of a file is text: valid UTF-8 without
+// NUL bytes.
+func isText(data []byte) bool {
+	return utf8.Valid(data) && bytes.IndexByte(data, 0) < 0
+}
+
+// flattenValues returns the leaf values by their dotted paths. Lists are
+// leaves.
+func flattenValues(values map[string]any, prefix string) map[string]any {
+	flat := map[string]any{}
+	for key, value := range values {
+		p := prefix + key
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			maps.Copy(flat, flattenValues(nested, p+"."))
+			continue
+		}
+		flat[p] = value
+	}
+	return flat
+}
+
+// diffValues compares the default values of two charts.
+func diffValues(oldValues, newValues map[string]any) []ValueChange {
+	changes := []ValueChange{}
+	for _, p := range sortedKeys(oldValues, newValues) {
+		o, inOld := oldValues[p]
+		n, inNew := newValues[p]
+		if change, ok := diffChange(inOld, inNew, reflect.DeepEqual(o, n)); ok {
+			changes = append(changes, ValueChange{Path: p, Change: change, Old: o, New: n})
+		}
+	}
+	return changes
+}
+
+// diffChange returns how a part changed between two charts, and whether it
+// changed at all.
+func diffChange(inOld, inNew, equal bool) (DiffChange, bool) {
+	switch {
+	case !inOld:
+		return DiffAdded, true
+	case !inNew:
+		return DiffRemoved, true
+	case !equal:
+		return DiffModified, true
+	}
+	return "", false
+}
+
+// sortedKeys returns the keys of two maps, sorted and without duplicates.
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestDiffCharts(t *testing.T) {
+	oldChart := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "ahab", Version: "1.0.0", Description: "Moby Dick"},
+		Raw: []*common.File{
+			{Name: ChartfileName, Data: []byte("name: ahab\nversion: 1.0.0\n")},
+			{Name: ValuesfileName, Data: []byte("image:\n  tag: \"1.0\"\nreplicas: 1\n")},
+			{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment\n")},
+			{Name: "templates/hpa.yaml", Data: []byte("kind: HorizontalPodAutoscaler\n")},
+			{Name: "icon.png", Data: []byte{0x89, 'P', 'N', 'G', 0}},
+		},
+		Values: map[string]any{"image": map[string]any{"tag": "1.0"}, "replicas": 1},
+	}
+	oldChart.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "pequod", Version: "0.1.0"},
+		Raw:      []*common.File{{Name: ValuesfileName, Data: []byte("crew: 30\n")}},
+	})
+
+	newChart := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "ahab", Version: "1.1.0", Icon: "icon.png"},
+		Raw: []*common.File{
+			{Name: ChartfileName, Data: []byte("name: ahab\nversion: 1.1.0\n")},
+			{Name: ValuesfileName, Data: []byte("image:\n  tag: \"1.1\"\nreplicas: 1\nservice:\n  port: 80\n")},
+			{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment\n")},
+			{Name: "templates/service.yaml", Data: []byte("kind: Service\n")},
+			{Name: "icon.png", Data: []byte{0x89, 'P', 'N', 'G', 1}},
+		},
+		Values: map[string]any{"image": map[string]any{"tag": "1.1"}, "replicas": 1, "service": map[string]any{"port": 80}},
+	}
+	newChart.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "pequod", Version: "0.1.0"},
+		Raw:      []*common.File{{Name: ValuesfileName, Data: []byte("crew: 29\n")}},
+	})
+
+	d, err := DiffCharts(oldChart, newChart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Changed() {
+		t.Error("Expected the charts to differ")
+	}
+
+	expectMetadata := []MetadataChange{
+		{Field: "description", Change: DiffRemoved, Old: "Moby Dick"},
+		{Field: "icon", Change: DiffAdded, New: "icon.png"},
+		{Field: "version", Change: DiffModified, Old: "1.0.0", New: "1.1.0"},
+	}
+	if !reflect.DeepEqual(d.Metadata, expectMetadata) {
+		t.Errorf("Expected metadata changes %v, got %v", expectMetadata, d.Metadata)
+	}
+
+	expectFiles := []FileChange{
+		{Name: "charts/pequod/values.yaml", Change: DiffModified, Old: "crew: 30\n", New: "crew: 29\n"},
+		{Name: "icon.png", Change: DiffModified, Binary: true},
+		{Name: "templates/hpa.yaml", Change: DiffRemoved, Old: "kind: HorizontalPodAutoscaler\n"},
+		{Name: "templates/service.yaml", Change: DiffAdded, New: "kind: Service\n"},
+		{Name: "values.yaml", Change: DiffModified, Old: "image:\n  tag: \"1.0\"\nreplicas: 1\n", New: "image:\n  tag: \"1.1\"\nreplicas: 1\nservice:\n  port: 80\n"},
+	}
+	if !reflect.DeepEqual(d.Files, expectFiles) {
+		t.Errorf("Expected file changes %v, got %v", expectFiles, d.Files)
+	}
+
+	expectValues := []ValueChange{
+		{Path: "image.tag", Change: DiffModified, Old: "1.0", New: "1.1"},
+		{Path: "service.port", Change: DiffAdded, New: 80},
+	}
+	if !reflect.DeepEqual(d.Values, expectValues) {
+		t.Errorf("Expected value changes %v, got %v", expectValues, d.Values)
+	}
+
+	same, err := DiffCharts(oldChart, oldChart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same.Changed() {
+		t.Errorf("Expected no differences, got %v", same)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// ProfilesDir is the directory of a chart holding its values profiles, curated
+// presets of values such as profiles/prod.yaml, selected by name.
+const ProfilesDir = "profiles"
+
+// Profiles returns the sorted names of the values profiles of a chart.
+func Profiles(c *chart.Chart) []string {
+	var names []string
+	for _, f := range c.Files {
+		if name, ok := profileName(f.Name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile coalesces the values of the named profile of a chart over the
+// chart's default values. The profile overrides values.yaml, and user supplied
+// values still override both. As in user supplied values, a null in the
+// profile removes a default value.
+func ApplyProfile(c *chart.Chart, name string) error {
+	var profile *common.File
+	for _, f := range c.Files {
+		if n, ok := profileName(f.Name); ok && n == name {
+			profile = f
+			break
+		}
+	}
+	if profile == nil {
+		profiles := Profiles(c)
+		if len(profiles) == 0 {
+			return fmt.Errorf("profile %q not found: chart %s has no profiles", name, c.Name())
+		}
+		return fmt.Errorf("profile %q not found in chart %s, available profiles: %s", name, c.Name(), strings.Join(profiles, ", "))
+	}
+
+	vals, err := common.ReadValues(profile.Data)
+	if err != nil {
+		return fmt.Errorf("unable to parse profile %q: %w", name, err)
+	}
+	c.Values = util.CoalesceTables(vals, c.Values)
+	return nil
+}
+
+// profileName returns the name of the profile in file, such as "prod" for
+// profiles/prod.yaml.
+func profileName(file string) (string, bool) {
+	dir, base := path.Split(file)
+	if dir != ProfilesDir+"/" || path.Ext(base) != ".yaml" {
+		return "", false
+	}
+	return strings.TrimSuffix(base, ".yaml"), true
+}
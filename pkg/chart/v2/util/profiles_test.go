@@ -0,0 +1,120 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func profilesChart() *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{Name: "web", Version: "0.1.0"},
+		Values: map[string]any{
+			"replicaCount": 1,
+			"image":        map[string]any{"repository": "nginx", "tag": "1.27"},
+			"debug":        true,
+		},
+		Files: []*common.File{
+			{Name: "profiles/prod.yaml", Data: []byte("replicaCount: 3\nimage:\n  tag: 1.27-alpine\ndebug: null\n")},
+			{Name: "profiles/minimal.yaml", Data: []byte("replicaCount: 0\n")},
+			{Name: "profiles/broken.yaml", Data: []byte("replicaCount: [\n")},
+			{Name: "profiles/README.md", Data: []byte("# Profiles\n")},
+			{Name: "profiles/nested/dev.yaml", Data: []byte("debug: true\n")},
+			{Name: "staging.yaml", Data: []byte("replicaCount: 2\n")},
+		},
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	assert.Equal(t, []string{"broken", "minimal", "prod"}, Profiles(profilesChart()))
+	assert.Empty(t, Profiles(&chart.Chart{Metadata: &chart.Metadata{Name: "empty"}}))
+}
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		chart       *chart.Chart
+		profile     string
+		expect      map[string]any
+		expectError string
+	}{
+		{
+			name:    "profile overrides defaults",
+			chart:   profilesChart(),
+			profile: "prod",
+			expect: map[string]any{
+				"replicaCount": float64(3),
+				"image":        map[string]any{"repository": "nginx", "tag": "1.27-alpine"},
+			},
+		},
+		{
+			name:    "profile sets a single value",
+			chart:   profilesChart(),
+			profile: "minimal",
+			expect: map[string]any{
+				"replicaCount": float64(0),
+				"image":        map[string]any{"repository": "nginx", "tag": "1.27"},
+				"debug":        true,
+			},
+		},
+		{
+			name: "chart without default values",
+			chart: &chart.Chart{
+				Metadata: &chart.Metadata{Name: "web"},
+				Files:    []*common.File{{Name: "profiles/prod.yaml", Data: []byte("replicaCount: 3\n")}},
+			},
+			profile: "prod",
+			expect:  map[string]any{"replicaCount": float64(3)},
+		},
+		{
+			name:        "unknown profile",
+			chart:       profilesChart(),
+			profile:     "staging",
+			expectError: `profile "staging" not found in chart web, available profiles: broken, minimal, prod`,
+		},
+		{
+			name:        "chart without profiles",
+			chart:       &chart.Chart{Metadata: &chart.Metadata{Name: "web"}},
+			profile:     "prod",
+			expectError: `profile "prod" not found: chart web has no profiles`,
+		},
+		{
+			name:        "invalid profile",
+			chart:       profilesChart(),
+			profile:     "broken",
+			expectError: `unable to parse profile "broken"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ApplyProfile(tt.chart, tt.profile)
+			if tt.expectError != "" {
+				require.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, tt.chart.Values)
+		})
+	}
+}
@@ -25,6 +25,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"sigs.k8s.io/yaml"
@@ -97,6 +99,33 @@ func SaveDir(c *chart.Chart, dest string) error {
 	return nil
 }
 
+// SaveOption configures the chart archives created by Save.
+type SaveOption func(*saveOptions)
+
+type saveOptions struct {
+	modTime time.Time
+}
+
+// WithModTime sets the modification time of every file in the chart archive,
+// instead of the modification times of the files of the chart.
+//
+// Together with the fixed order of the files and the fixed gzip settings of
+// the archive, this makes the archives of the same chart byte-identical, e.g.
+// when the time is that of the last commit of the chart (SOURCE_DATE_EPOCH).
+func WithModTime(modTime time.Time) SaveOption {
+	return func(o *saveOptions) {
+		o.modTime = modTime
+	}
+}
+
+// fileModTime returns the modification time of a file in the archive.
+func (o saveOptions) fileModTime(modTime time.Time) time.Time {
+	if !o.modTime.IsZero() {
+		return o.modTime
+	}
+	return modTime
+}
+
 // Save creates an archived chart to the given directory.
 //
 // This takes an existing chart and a destination directory.
@@ -105,7 +134,12 @@ func SaveDir(c *chart.Chart, dest string) error {
 // will generate /foo/bar-1.0.0.tgz.
 //
 // This returns the absolute path to the chart archive file.
-func Save(c *chart.Chart, outDir string) (string, error) {
+func Save(c *chart.Chart, outDir string, opts ...SaveOption) (string, error) {
+	var o saveOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	if err := c.Validate(); err != nil {
 		return "", fmt.Errorf("chart validation: %w", err)
 	}
@@ -130,8 +164,14 @@ func Save(c *chart.Chart, outDir string) (string, error) {
 		return "", err
 	}
 
-	// Wrap in gzip writer
-	zipper := gzip.NewWriter(f)
+	// Wrap in gzip writer. The compression level is fixed, and the header has
+	// no modification time or name, so the archive only depends on its files.
+	zipper, err := gzip.NewWriterLevel(f, gzip.DefaultCompression)
+	if err != nil {
+		f.Close()
+		os.Remove(filename)
+		return "", err
+	}
 	zipper.Extra = headerBytes
 	zipper.Comment = "Helm"
 
@@ -147,14 +187,14 @@ func Save(c *chart.Chart, outDir string) (string, error) {
 		}
 	}()
 
-	if err := writeTarContents(twriter, c, ""); err != nil {
+	if err := writeTarContents(twriter, c, "", o); err != nil {
 		rollback = true
 		return filename, err
 	}
 	return filename, nil
 }
 
-func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
+func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string, o saveOptions) error {
 	err := validateName(c.Name())
 	if err != nil {
 		return err
@@ -175,7 +215,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	if err != nil {
 		return err
 	}
-	if err := writeToTar(out, filepath.Join(base, ChartfileName), cdata, c.ModTime); err != nil {
+	if err := writeToTar(out, filepath.Join(base, ChartfileName), cdata, o.fileModTime(c.ModTime)); err != nil {
 		return err
 	}
 
@@ -187,7 +227,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 			if err != nil {
 				return err
 			}
-			if err := writeToTar(out, filepath.Join(base, "Chart.lock"), ldata, c.Lock.Generated); err != nil {
+			if err := writeToTar(out, filepath.Join(base, "Chart.lock"), ldata, o.fileModTime(c.Lock.Generated)); err != nil {
 				return err
 			}
 		}
@@ -196,7 +236,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	// Save values.yaml
 	for _, f := range c.Raw {
 		if f.Name == ValuesfileName {
-			if err := writeToTar(out, filepath.Join(base, ValuesfileName), f.Data, f.ModTime); err != nil {
+			if err := writeToTar(out, filepath.Join(base, ValuesfileName), f.Data, o.fileModTime(f.ModTime)); err != nil {
 				return err
 			}
 		}
@@ -207,7 +247,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 		if !json.Valid(c.Schema) {
 			return errors.New("invalid JSON in " + SchemafileName)
 		}
-		if err := writeToTar(out, filepath.Join(base, SchemafileName), c.Schema, c.SchemaModTime); err != nil {
+		if err := writeToTar(out, filepath.Join(base, SchemafileName), c.Schema, o.fileModTime(c.SchemaModTime)); err != nil {
 			return err
 		}
 	}
@@ -215,7 +255,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	// Save templates
 	for _, f := range c.Templates {
 		n := filepath.Join(base, f.Name)
-		if err := writeToTar(out, n, f.Data, f.ModTime); err != nil {
+		if err := writeToTar(out, n, f.Data, o.fileModTime(f.ModTime)); err != nil {
 			return err
 		}
 	}
@@ -223,14 +263,17 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	// Save files
 	for _, f := range c.Files {
 		n := filepath.Join(base, f.Name)
-		if err := writeToTar(out, n, f.Data, f.ModTime); err != nil {
+		if err := writeToTar(out, n, f.Data, o.fileModTime(f.ModTime)); err != nil {
 			return err
 		}
 	}
 
-	// Save dependencies
-	for _, dep := range c.Dependencies() {
-		if err := writeTarContents(out, dep, filepath.Join(base, ChartsDir)); err != nil {
+	// Save dependencies, in a fixed order
+	deps := slices.SortedStableFunc(slices.Values(c.Dependencies()), func(a, b *chart.Chart) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	for _, dep := range deps {
+		if err := writeTarContents(out, dep, filepath.Join(base, ChartsDir), o); err != nil {
 			return err
 		}
 	}
@@ -186,6 +186,75 @@ func TestSavePreservesTimestamps(t *testing.T) {
 	}
 }
 
+func TestSaveWithModTime(t *testing.T) {
+	modTime := time.Unix(1700000000, 0).UTC()
+	newChart := func(fileModTime time.Time, depNames ...string) *chart.Chart {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       "ahab",
+				Version:    "1.2.3",
+			},
+			ModTime: fileModTime,
+			Files: []*common.File{
+				{Name: "scheherazade/shahryar.txt", ModTime: fileModTime, Data: []byte("1,001 Nights")},
+			},
+			Schema:        []byte("{\n  \"title\": \"Values\"\n}"),
+			SchemaModTime: fileModTime,
+		}
+		for _, name := range depNames {
+			c.AddDependency(&chart.Chart{
+				Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: name, Version: "0.1.0"},
+				ModTime:  fileModTime,
+			})
+		}
+		return c
+	}
+
+	first, err := Save(newChart(time.Now(), "mariner", "alpine"), t.TempDir(), WithModTime(modTime))
+	if err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+	second, err := Save(newChart(time.Now().Add(time.Hour), "alpine", "mariner"), t.TempDir(), WithModTime(modTime))
+	if err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+
+	firstSum, err := sha256Sum(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secondSum, err := sha256Sum(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if firstSum != secondSum {
+		t.Errorf("Expected byte-identical archives, got digests %s and %s", firstSum, secondSum)
+	}
+
+	headers, err := retrieveAllHeadersFromTar(first)
+	if err != nil {
+		t.Fatalf("Failed to parse tar: %v", err)
+	}
+	var names []string
+	for _, header := range headers {
+		if !header.ModTime.Equal(modTime) {
+			t.Errorf("Expected %s to have timestamp %v, got %v", header.Name, modTime, header.ModTime)
+		}
+		names = append(names, header.Name)
+	}
+	expectNames := []string{
+		"ahab/Chart.yaml",
+		"ahab/values.schema.json",
+		"ahab/scheherazade/shahryar.txt",
+		"ahab/charts/alpine/Chart.yaml",
+		"ahab/charts/mariner/Chart.yaml",
+	}
+	if strings.Join(names, ",") != strings.Join(expectNames, ",") {
+		t.Errorf("Expected files %v, got %v", expectNames, names)
+	}
+}
+
 // We could refactor `load.go` to use this `retrieveAllHeadersFromTar` function
 // as well, so we are not duplicating components of the code which iterate
 // through the tar.
@@ -0,0 +1,211 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	goyaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// SchemaDraft is the JSON Schema version of the generated values schemas.
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// jsonSchema is the part of a JSON Schema a values schema is generated with.
+type jsonSchema struct {
+	Schema      string            `json:"$schema,omitempty"`
+	Type        string            `json:"type,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Default     any               `json:"default,omitempty"`
+	Properties  *schemaProperties `json:"properties,omitempty"`
+	Items       *jsonSchema       `json:"items,omitempty"`
+}
+
+// schemaProperties are the properties of an object schema, in the order of
+// the keys of the values.
+type schemaProperties struct {
+	names   []string
+	schemas map[string]*jsonSchema
+}
+
+func (p *schemaProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for n, name := range p.names {
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.schemas[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// GenerateValuesSchema infers a values.schema.json skeleton from the content of
+// a values.yaml file. Each value is described by its type and, for scalars,
+// its default. The comments above a key or at the end of its line become the
+// description of the value, without the "--" prefix used by helm-docs.
+//
+// The schema is a starting point: it does not require any value, and allows
+// values it does not describe.
+func GenerateValuesSchema(values []byte) ([]byte, error) {
+	var doc goyaml.Node
+	if err := goyaml.Unmarshal(values, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse values: %w", err)
+	}
+
+	schema := &jsonSchema{Type: "object"}
+	if len(doc.Content) > 0 {
+		root := doc.Content[0]
+		if root.Kind != goyaml.MappingNode {
+			return nil, fmt.Errorf("values must be a map, not a %s", root.ShortTag())
+		}
+		var err error
+		if schema, err = nodeSchema(root); err != nil {
+			return nil, err
+		}
+	}
+	schema.Schema = SchemaDraft
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// nodeSchema returns the schema of a YAML value.
+func nodeSchema(node *goyaml.Node) (*jsonSchema, error) {
+	if node.Kind == goyaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch node.Kind {
+	case goyaml.MappingNode:
+		schema := &jsonSchema{
+			Type:       "object",
+			Properties: &schemaProperties{schemas: map[string]*jsonSchema{}},
+		}
+		for n := 0; n+1 < len(node.Content); n += 2 {
+			key, value := node.Content[n], node.Content[n+1]
+			// Merge keys are left out, their values are not known keys
+			if key.Tag == "!!merge" {
+				continue
+			}
+			prop, err := nodeSchema(value)
+			if err != nil {
+				return nil, err
+			}
+			prop.Description = commentDescription(key.HeadComment, key.LineComment, value.LineComment)
+			if _, ok := schema.Properties.schemas[key.Value]; !ok {
+				schema.Properties.names = append(schema.Properties.names, key.Value)
+			}
+			schema.Properties.schemas[key.Value] = prop
+		}
+		return schema, nil
+
+	case goyaml.SequenceNode:
+		schema := &jsonSchema{Type: "array"}
+		for _, item := range node.Content {
+			itemSchema, err := nodeSchema(item)
+			if err != nil {
+				return nil, err
+			}
+			// The values of the first items are not defaults of new items
+			clearDefaults(itemSchema)
+			if schema.Items == nil {
+				schema.Items = itemSchema
+			} else if schema.Items.Type != itemSchema.Type {
+				// Items of different types are not described
+				schema.Items = &jsonSchema{}
+				break
+			}
+		}
+		return schema, nil
+
+	default:
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return nil, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		schema := &jsonSchema{Default: value}
+		switch node.ShortTag() {
+		case "!!str", "!!timestamp", "!!binary":
+			schema.Type = "string"
+			schema.Default = node.Value
+		case "!!int":
+			schema.Type = "integer"
+		case "!!float":
+			schema.Type = "number"
+			// JSON has no infinity or NaN
+			if f, ok := value.(float64); ok && (math.IsInf(f, 0) || math.IsNaN(f)) {
+				schema.Default = nil
+			}
+		case "!!bool":
+			schema.Type = "boolean"
+		}
+		return schema, nil
+	}
+}
+
+// clearDefaults removes the defaults of a schema and of its properties and
+// items.
+func clearDefaults(schema *jsonSchema) {
+	schema.Default = nil
+	if schema.Properties != nil {
+		for _, prop := range schema.Properties.schemas {
+			clearDefaults(prop)
+		}
+	}
+	if schema.Items != nil {
+		clearDefaults(schema.Items)
+	}
+}
+
+// commentDescription returns the text of YAML comments, joining their lines.
+func commentDescription(comments ...string) string {
+	var lines []string
+	for _, comment := range comments {
+		for _, line := range strings.Split(comment, "\n") {
+			line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+			line = strings.TrimSpace(strings.TrimPrefix(line, "--"))
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return strings.Join(lines, " ")
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateValuesSchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      string
+		expect      string
+		expectError string
+	}{
+		{
+			name:   "empty values",
+			values: "",
+			expect: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}`,
+		},
+		{
+			name:   "scalars",
+			values: "name: app\nreplicas: 2\nratio: 0.5\nenabled: false\nnothing: null\ninf: .inf\n",
+			expect: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "properties": {
+				"name": {"type": "string", "default": "app"},
+				"replicas": {"type": "integer", "default": 2},
+				"ratio": {"type": "number", "default": 0.5},
+				"enabled": {"type": "boolean", "default": false},
+				"nothing": {},
+				"inf": {"type": "number"}
+			}}`,
+		},
+		{
+			name:   "descriptions",
+			values: "# The image\n# to run\nimage: nginx # with a tag\n# -- Number of replicas\nreplicas: 1\n",
+			expect: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "properties": {
+				"image": {"type": "string", "description": "The image to run with a tag", "default": "nginx"},
+				"replicas": {"type": "integer", "description": "Number of replicas", "default": 1}
+			}}`,
+		},
+		{
+			name:   "lists",
+			values: "args: [a, b]\nports:\n  - port: 80\nmixed: [a, 1]\nempty: []\n",
+			expect: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "properties": {
+				"args": {"type": "array", "items": {"type": "string"}},
+				"ports": {"type": "array", "items": {"type": "object", "properties": {"port": {"type": "integer"}}}},
+				"mixed": {"type": "array", "items": {}},
+				"empty": {"type": "array"}
+			}}`,
+		},
+		{
+			name:   "anchors",
+			values: "base: &base\n  size: 1\ncopy: *base\n",
+			expect: `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object", "properties": {
+				"base": {"type": "object", "properties": {"size": {"type": "integer", "default": 1}}},
+				"copy": {"type": "object", "properties": {"size": {"type": "integer", "default": 1}}}
+			}}`,
+		},
+		{
+			name:        "not a map",
+			values:      "- a\n",
+			expectError: "values must be a map, not a !!seq",
+		},
+		{
+			name:        "invalid yaml",
+			values:      "a: [",
+			expectError: "unable to parse values",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := GenerateValuesSchema([]byte(tt.values))
+			if tt.expectError != "" {
+				require.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expect, string(schema))
+		})
+	}
+}
+
+func TestGenerateValuesSchemaKeepsKeyOrder(t *testing.T) {
+	schema, err := GenerateValuesSchema([]byte("zebra: 1\napple: 2\n"))
+	require.NoError(t, err)
+	assert.Regexp(t, `(?s)"zebra".*"apple"`, string(schema))
+}
@@ -76,6 +76,9 @@ type EnvSettings struct {
 	Debug bool
 	// RegistryConfig is the path to the registry config file.
 	RegistryConfig string
+	// RegistriesConfig is the path to the file of the registry mirrors and
+	// per-registry settings.
+	RegistriesConfig string
 	// RepositoryConfig is the path to the repositories file.
 	RepositoryConfig string
 	// RepositoryCache is the path to the repository cache directory.
@@ -92,6 +95,8 @@ type EnvSettings struct {
 	ColorMode string
 	// ContentCache is the location where cached charts are stored
 	ContentCache string
+	// RenderHooksConfig is the path to the render hooks file.
+	RenderHooksConfig string
 }
 
 func New() *EnvSettings {
@@ -109,9 +114,11 @@ func New() *EnvSettings {
 		KubeInsecureSkipTLSVerify: envBoolOr("HELM_KUBEINSECURE_SKIP_TLS_VERIFY", false),
 		PluginsDirectory:          envOr("HELM_PLUGINS", helmpath.DataPath("plugins")),
 		RegistryConfig:            envOr("HELM_REGISTRY_CONFIG", helmpath.ConfigPath("registry/config.json")),
+		RegistriesConfig:          envOr("HELM_REGISTRIES_CONFIG", helmpath.ConfigPath("registry/registries.yaml")),
 		RepositoryConfig:          envOr("HELM_REPOSITORY_CONFIG", helmpath.ConfigPath("repositories.yaml")),
 		RepositoryCache:           envOr("HELM_REPOSITORY_CACHE", helmpath.CachePath("repository")),
 		ContentCache:              envOr("HELM_CONTENT_CACHE", helmpath.CachePath("content")),
+		RenderHooksConfig:         envOr("HELM_RENDER_HOOKS_CONFIG", helmpath.ConfigPath("render-hooks.yaml")),
 		BurstLimit:                envIntOr("HELM_BURST_LIMIT", defaultBurstLimit),
 		QPS:                       envFloat32Or("HELM_QPS", defaultQPS),
 		ColorMode:                 envColorMode(),
@@ -241,20 +248,22 @@ func envColorMode() string {
 
 func (s *EnvSettings) EnvVars() map[string]string {
 	envvars := map[string]string{
-		"HELM_BIN":               os.Args[0],
-		"HELM_CACHE_HOME":        helmpath.CachePath(""),
-		"HELM_CONFIG_HOME":       helmpath.ConfigPath(""),
-		"HELM_DATA_HOME":         helmpath.DataPath(""),
-		"HELM_DEBUG":             strconv.FormatBool(s.Debug),
-		"HELM_PLUGINS":           s.PluginsDirectory,
-		"HELM_REGISTRY_CONFIG":   s.RegistryConfig,
-		"HELM_REPOSITORY_CACHE":  s.RepositoryCache,
-		"HELM_CONTENT_CACHE":     s.ContentCache,
-		"HELM_REPOSITORY_CONFIG": s.RepositoryConfig,
-		"HELM_NAMESPACE":         s.Namespace(),
-		"HELM_MAX_HISTORY":       strconv.Itoa(s.MaxHistory),
-		"HELM_BURST_LIMIT":       strconv.Itoa(s.BurstLimit),
-		"HELM_QPS":               strconv.FormatFloat(float64(s.QPS), 'f', 2, 32),
+		"HELM_BIN":                 os.Args[0],
+		"HELM_CACHE_HOME":          helmpath.CachePath(""),
+		"HELM_CONFIG_HOME":         helmpath.ConfigPath(""),
+		"HELM_DATA_HOME":           helmpath.DataPath(""),
+		"HELM_DEBUG":               strconv.FormatBool(s.Debug),
+		"HELM_PLUGINS":             s.PluginsDirectory,
+		"HELM_REGISTRY_CONFIG":     s.RegistryConfig,
+		"HELM_REGISTRIES_CONFIG":   s.RegistriesConfig,
+		"HELM_REPOSITORY_CACHE":    s.RepositoryCache,
+		"HELM_CONTENT_CACHE":       s.ContentCache,
+		"HELM_REPOSITORY_CONFIG":   s.RepositoryConfig,
+		"HELM_RENDER_HOOKS_CONFIG": s.RenderHooksConfig,
+		"HELM_NAMESPACE":           s.Namespace(),
+		"HELM_MAX_HISTORY":         strconv.Itoa(s.MaxHistory),
+		"HELM_BURST_LIMIT":         strconv.Itoa(s.BurstLimit),
+		"HELM_QPS":                 strconv.FormatFloat(float64(s.QPS), 'f', 2, 32),
 
 		// broken, these are populated from helm flags and not kubeconfig.
 		"HELM_KUBECONTEXT":                  s.KubeContext,
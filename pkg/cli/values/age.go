@@ -0,0 +1,146 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// The environment variables and the file SOPS reads age identities from.
+const (
+	ageKeyEnv      = "SOPS_AGE_KEY"
+	ageKeyFileEnv  = "SOPS_AGE_KEY_FILE"
+	ageKeyUserPath = "sops/age/keys.txt"
+)
+
+// ageHeader starts the binary format of age-encrypted files.
+const ageHeader = "age-encryption.org/v1"
+
+// AgeKeyProvider decrypts age-encrypted values files, and the data keys of
+// SOPS-encrypted values files encrypted for age recipients.
+type AgeKeyProvider struct {
+	Identities []age.Identity
+}
+
+// NewAgeKeyProvider returns an AgeKeyProvider decrypting with the given
+// identities.
+func NewAgeKeyProvider(identities ...age.Identity) *AgeKeyProvider {
+	return &AgeKeyProvider{Identities: identities}
+}
+
+// LoadAgeKeyProvider returns an AgeKeyProvider with the identities SOPS uses:
+// those in the SOPS_AGE_KEY environment variable, in the file named by the
+// SOPS_AGE_KEY_FILE environment variable, and in sops/age/keys.txt in the user
+// configuration directory.
+func LoadAgeKeyProvider() (*AgeKeyProvider, error) {
+	var identities []age.Identity
+	if keys := os.Getenv(ageKeyEnv); keys != "" {
+		ids, err := age.ParseIdentities(strings.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the age identities in %s: %w", ageKeyEnv, err)
+		}
+		identities = append(identities, ids...)
+	}
+
+	var files []string
+	if file := os.Getenv(ageKeyFileEnv); file != "" {
+		files = append(files, file)
+	}
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir, _ = os.UserConfigDir()
+	}
+	if configDir != "" {
+		files = append(files, filepath.Join(configDir, filepath.FromSlash(ageKeyUserPath)))
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids, err := age.ParseIdentities(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the age identities in %s: %w", file, err)
+		}
+		identities = append(identities, ids...)
+	}
+
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("no age identity found: set %s or %s, or write the identities to %s in the user configuration directory", ageKeyEnv, ageKeyFileEnv, ageKeyUserPath)
+	}
+	return NewAgeKeyProvider(identities...), nil
+}
+
+// KeyType returns "age".
+func (p *AgeKeyProvider) KeyType() string {
+	return "age"
+}
+
+// DecryptDataKey decrypts the armored data key in the "enc" field of an age
+// key of the SOPS metadata.
+func (p *AgeKeyProvider) DecryptDataKey(key map[string]any) ([]byte, error) {
+	enc, ok := key["enc"].(string)
+	if !ok {
+		return nil, errors.New("age key without encrypted data key")
+	}
+	return p.decrypt([]byte(enc))
+}
+
+func (p *AgeKeyProvider) isEncrypted(data []byte) bool {
+	return isAgeEncrypted(data)
+}
+
+func (p *AgeKeyProvider) decryptFile(data []byte) ([]byte, error) {
+	plaintext, err := p.decrypt(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the age-encrypted file: %w", err)
+	}
+	return plaintext, nil
+}
+
+// decrypt decrypts age-encrypted data, in the binary or the armored format.
+func (p *AgeKeyProvider) decrypt(data []byte) ([]byte, error) {
+	var src io.Reader = bytes.NewReader(data)
+	if !bytes.HasPrefix(data, []byte(ageHeader)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	r, err := age.Decrypt(src, p.Identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// isAgeEncrypted returns true if data is an age-encrypted file, in the binary
+// or the armored format.
+func isAgeEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(ageHeader)) ||
+		bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header))
+}
@@ -0,0 +1,335 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	goYaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// sopsMetadataKey is the key of the metadata of SOPS-encrypted files.
+const sopsMetadataKey = "sops"
+
+// sopsMACOnlyEncrypted is the initialization of the MAC of files encrypted
+// with SOPS' mac_only_encrypted option.
+var sopsMACOnlyEncrypted = []byte{0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3, 0xd1, 0x47, 0xbe, 0xb, 0xb, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69}
+
+var sopsEncryptedValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+
+// A KeyProvider decrypts the data keys of SOPS-encrypted values files for a
+// type of key, such as age keys or a key management service.
+type KeyProvider interface {
+	// KeyType is the type of keys the provider decrypts, as named in the SOPS
+	// metadata, such as "age", "pgp" or "kms".
+	KeyType() string
+	// DecryptDataKey decrypts the data key of a file with one of the keys
+	// listed for the type of the provider in the SOPS metadata, such as
+	// {"recipient": "age1...", "enc": "-----BEGIN AGE ENCRYPTED FILE-----..."}.
+	DecryptDataKey(key map[string]any) ([]byte, error)
+}
+
+// fileDecrypter is implemented by the key providers able to decrypt whole
+// files, such as age-encrypted files.
+type fileDecrypter interface {
+	isEncrypted(data []byte) bool
+	decryptFile(data []byte) ([]byte, error)
+}
+
+// IsEncrypted returns true if data is an age-encrypted file or a SOPS-encrypted
+// YAML or JSON file.
+func IsEncrypted(data []byte) bool {
+	if isAgeEncrypted(data) {
+		return true
+	}
+	_, _, err := parseSOPS(data)
+	return err == nil
+}
+
+// Decrypt decrypts the data of an age-encrypted or SOPS-encrypted values file
+// with the given key providers. Data that is not encrypted is returned as is.
+//
+// The message authentication code of SOPS-encrypted files is verified, so the
+// values of a file cannot be changed without the data key.
+func Decrypt(data []byte, providers []KeyProvider) ([]byte, error) {
+	for _, p := range providers {
+		if d, ok := p.(fileDecrypter); ok && d.isEncrypted(data) {
+			return d.decryptFile(data)
+		}
+	}
+	if isAgeEncrypted(data) {
+		return nil, errors.New("no age identity to decrypt the age-encrypted file")
+	}
+
+	doc, meta, err := parseSOPS(data)
+	if err != nil {
+		// Not a SOPS-encrypted file
+		return data, nil
+	}
+	return decryptSOPS(doc, meta, providers)
+}
+
+// parseSOPS parses a SOPS-encrypted file, returning its document and metadata.
+func parseSOPS(data []byte) (*goYaml.Node, map[string]any, error) {
+	var doc goYaml.Node
+	if err := goYaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != goYaml.MappingNode {
+		return nil, nil, errors.New("not a SOPS-encrypted file")
+	}
+	root := doc.Content[0]
+	for n := 0; n+1 < len(root.Content); n += 2 {
+		if root.Content[n].Value != sopsMetadataKey {
+			continue
+		}
+		var meta map[string]any
+		if err := root.Content[n+1].Decode(&meta); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := meta["version"]; ok {
+			return &doc, meta, nil
+		}
+	}
+	return nil, nil, errors.New("not a SOPS-encrypted file")
+}
+
+func decryptSOPS(doc *goYaml.Node, meta map[string]any, providers []KeyProvider) ([]byte, error) {
+	key, err := sopsDataKey(meta, providers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt the values and compute the MAC of their plaintext like SOPS
+	hash := sha512.New()
+	macOnlyEncrypted, _ := meta["mac_only_encrypted"].(bool)
+	if macOnlyEncrypted {
+		hash.Write(sopsMACOnlyEncrypted)
+	}
+	var walk func(node *goYaml.Node, path []string) error
+	walk = func(node *goYaml.Node, path []string) error {
+		// Comments are encrypted too, and not values
+		node.HeadComment, node.LineComment, node.FootComment = "", "", ""
+		switch node.Kind {
+		case goYaml.MappingNode:
+			for n := 0; n+1 < len(node.Content); n += 2 {
+				if err := walk(node.Content[n+1], append(path[:len(path):len(path)], node.Content[n].Value)); err != nil {
+					return err
+				}
+			}
+		case goYaml.SequenceNode:
+			// SOPS does not add the index of list items to their path
+			for _, item := range node.Content {
+				if err := walk(item, path); err != nil {
+					return err
+				}
+			}
+		case goYaml.ScalarNode:
+			encrypted := node.Tag == "!!str" && sopsEncryptedValue.MatchString(node.Value)
+			if encrypted {
+				if err := decryptSOPSValue(node, key, strings.Join(path, ":")+":"); err != nil {
+					return fmt.Errorf("failed to decrypt %s: %w", strings.Join(path, "."), err)
+				}
+			}
+			if !macOnlyEncrypted || encrypted {
+				hash.Write(sopsMACBytes(node))
+			}
+		}
+		return nil
+	}
+
+	root := doc.Content[0]
+	for n := 0; n+1 < len(root.Content); n += 2 {
+		if root.Content[n].Value == sopsMetadataKey {
+			root.Content = append(root.Content[:n], root.Content[n+2:]...)
+			break
+		}
+	}
+	if err := walk(root, nil); err != nil {
+		return nil, err
+	}
+
+	if err := verifySOPSMAC(meta, key, fmt.Sprintf("%X", hash.Sum(nil))); err != nil {
+		return nil, err
+	}
+	return goYaml.Marshal(doc)
+}
+
+// sopsDataKey decrypts the data key of a SOPS-encrypted file with the first
+// of the keys in its metadata a provider can decrypt.
+func sopsDataKey(meta map[string]any, providers []KeyProvider) ([]byte, error) {
+	group := meta
+	if groups, ok := meta["key_groups"].([]any); ok {
+		if len(groups) != 1 {
+			return nil, errors.New("SOPS-encrypted files with several key groups are not supported")
+		}
+		if group, ok = groups[0].(map[string]any); !ok {
+			return nil, errors.New("invalid SOPS key group")
+		}
+	}
+
+	var errs []error
+	for _, p := range providers {
+		keys, _ := group[p.KeyType()].([]any)
+		for _, k := range keys {
+			key, ok := k.(map[string]any)
+			if !ok {
+				continue
+			}
+			dataKey, err := p.DecryptDataKey(key)
+			if err == nil {
+				return dataKey, nil
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", p.KeyType(), err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no key to decrypt the SOPS-encrypted file")
+	}
+	return nil, fmt.Errorf("failed to decrypt the data key of the SOPS-encrypted file: %w", errors.Join(errs...))
+}
+
+// decryptSOPSValue replaces an encrypted value, such as
+// ENC[AES256_GCM,data:...,iv:...,tag:...,type:str], with its plaintext.
+func decryptSOPSValue(node *goYaml.Node, key []byte, additionalData string) error {
+	plaintext, datatype, err := decryptSOPSString(node.Value, key, additionalData)
+	if err != nil {
+		return err
+	}
+	node.Style = 0
+	node.Value = string(plaintext)
+	switch datatype {
+	case "str", "bytes":
+		node.Tag = "!!str"
+	case "int":
+		node.Tag = "!!int"
+	case "float":
+		node.Tag = "!!float"
+	case "bool":
+		node.Tag = "!!bool"
+		b, err := strconv.ParseBool(node.Value)
+		if err != nil {
+			return err
+		}
+		node.Value = strconv.FormatBool(b)
+	case "time":
+		node.Tag = "!!timestamp"
+	default:
+		return fmt.Errorf("unknown datatype %q", datatype)
+	}
+	return nil
+}
+
+func decryptSOPSString(value string, key []byte, additionalData string) ([]byte, string, error) {
+	m := sopsEncryptedValue.FindStringSubmatch(value)
+	if m == nil {
+		return nil, "", errors.New("invalid encrypted value")
+	}
+	var parts [3][]byte
+	for n := range parts {
+		b, err := base64.StdEncoding.DecodeString(m[n+1])
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid encrypted value: %w", err)
+		}
+		parts[n] = b
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, "", err
+	}
+	return plaintext, m[4], nil
+}
+
+// sopsMACBytes returns the bytes of a value SOPS computes the MAC with.
+func sopsMACBytes(node *goYaml.Node) []byte {
+	switch node.Tag {
+	case "!!null":
+		return nil
+	case "!!int":
+		var i int
+		if err := node.Decode(&i); err == nil {
+			return []byte(strconv.Itoa(i))
+		}
+	case "!!float":
+		var f float64
+		if err := node.Decode(&f); err == nil {
+			return []byte(strconv.FormatFloat(f, 'f', -1, 64))
+		}
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err == nil {
+			if b {
+				return []byte("True")
+			}
+			return []byte("False")
+		}
+	case "!!timestamp":
+		var t time.Time
+		if err := node.Decode(&t); err == nil {
+			if b, err := t.MarshalText(); err == nil {
+				return b
+			}
+		}
+	}
+	return []byte(node.Value)
+}
+
+func verifySOPSMAC(meta map[string]any, key []byte, mac string) error {
+	encrypted, ok := meta["mac"].(string)
+	if !ok {
+		return errors.New("the SOPS-encrypted file has no MAC")
+	}
+	// The timestamp may be unquoted in YAML files
+	var lastModified string
+	switch t := meta["lastmodified"].(type) {
+	case time.Time:
+		lastModified = t.Format(time.RFC3339)
+	case string:
+		lastModified = t
+		if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+			lastModified = parsed.Format(time.RFC3339)
+		}
+	}
+	fileMAC, _, err := decryptSOPSString(encrypted, key, lastModified)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt the MAC of the SOPS-encrypted file: %w", err)
+	}
+	if !bytes.Equal(fileMAC, []byte(mac)) {
+		return errors.New("MAC mismatch: the SOPS-encrypted file was modified without its data key")
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goYaml "sigs.k8s.io/yaml/goyaml.v3"
+
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const sopsTestLastModified = "2025-01-02T03:04:05Z"
+
+// encryptSOPS encrypts a YAML document for an age recipient like SOPS.
+func encryptSOPS(t *testing.T, plaintext string, recipient age.Recipient, macOnlyEncrypted bool) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	var doc goYaml.Node
+	require.NoError(t, goYaml.Unmarshal([]byte(plaintext), &doc))
+	hash := sha512.New()
+	if macOnlyEncrypted {
+		hash.Write(sopsMACOnlyEncrypted)
+	}
+	var walk func(node *goYaml.Node, path []string)
+	walk = func(node *goYaml.Node, path []string) {
+		switch node.Kind {
+		case goYaml.MappingNode:
+			for n := 0; n+1 < len(node.Content); n += 2 {
+				walk(node.Content[n+1], append(path[:len(path):len(path)], node.Content[n].Value))
+			}
+		case goYaml.SequenceNode:
+			for _, item := range node.Content {
+				walk(item, path)
+			}
+		case goYaml.ScalarNode:
+			if node.Tag == "!!null" {
+				return
+			}
+			plain := sopsMACBytes(node)
+			unencrypted := strings.HasSuffix(path[len(path)-1], "_unencrypted")
+			if !macOnlyEncrypted || !unencrypted {
+				hash.Write(plain)
+			}
+			// SOPS leaves empty strings as they are
+			if unencrypted || len(plain) == 0 {
+				return
+			}
+			datatype := map[string]string{"!!str": "str", "!!int": "int", "!!float": "float", "!!bool": "bool"}[node.Tag]
+			node.Value = encryptSOPSString(t, plain, key, strings.Join(path, ":")+":", datatype)
+			node.Tag, node.Style = "!!str", 0
+		}
+	}
+	walk(doc.Content[0], nil)
+
+	var encKey bytes.Buffer
+	aw := armor.NewWriter(&encKey)
+	w, err := age.Encrypt(aw, recipient)
+	require.NoError(t, err)
+	_, err = w.Write(key)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, aw.Close())
+
+	meta := map[string]any{
+		"age":          []map[string]any{{"recipient": fmt.Sprint(recipient), "enc": encKey.String()}},
+		"lastmodified": sopsTestLastModified,
+		"mac":          encryptSOPSString(t, fmt.Appendf(nil, "%X", hash.Sum(nil)), key, sopsTestLastModified, "str"),
+		"version":      "3.9.4",
+	}
+	if macOnlyEncrypted {
+		meta["mac_only_encrypted"] = true
+	}
+	var metaNode goYaml.Node
+	require.NoError(t, metaNode.Encode(meta))
+	root := doc.Content[0]
+	root.Content = append(root.Content, &goYaml.Node{Kind: goYaml.ScalarNode, Tag: "!!str", Value: sopsMetadataKey}, &metaNode)
+
+	data, err := goYaml.Marshal(&doc)
+	require.NoError(t, err)
+	return data
+}
+
+func encryptSOPSString(t *testing.T, plaintext, key []byte, additionalData, datatype string) string {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCMWithNonceSize(block, 32)
+	require.NoError(t, err)
+	iv := make([]byte, 32)
+	_, err = rand.Read(iv)
+	require.NoError(t, err)
+	out := gcm.Seal(nil, iv, plaintext, []byte(additionalData))
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]",
+		base64.StdEncoding.EncodeToString(out[:len(out)-aes.BlockSize]),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(out[len(out)-aes.BlockSize:]),
+		datatype)
+}
+
+func encryptAge(t *testing.T, plaintext string, recipient age.Recipient, armored bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var dst io.Writer = &buf
+	var aw io.WriteCloser
+	if armored {
+		aw = armor.NewWriter(&buf)
+		dst = aw
+	}
+	w, err := age.Encrypt(dst, recipient)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, plaintext)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	if aw != nil {
+		require.NoError(t, aw.Close())
+	}
+	return buf.Bytes()
+}
+
+func TestDecrypt(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	plaintext := `# database settings
+database:
+  user: admin
+  password: s3cr3t
+  port: 5432
+  ratio: 0.5
+  tls: true
+  hosts:
+    - db-0
+    - db-1
+  host_unencrypted: db.example.com
+empty: ""
+none: null
+`
+	want := map[string]any{
+		"database": map[string]any{
+			"user":             "admin",
+			"password":         "s3cr3t",
+			"port":             int64(5432),
+			"ratio":            0.5,
+			"tls":              true,
+			"hosts":            []any{"db-0", "db-1"},
+			"host_unencrypted": "db.example.com",
+		},
+		"empty": "",
+		"none":  nil,
+	}
+
+	tampered := encryptSOPS(t, plaintext, identity.Recipient(), false)
+	tampered = bytes.Replace(tampered, []byte("db.example.com"), []byte("evil.example.com"), 1)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		providers []KeyProvider
+		wantErr   string
+	}{
+		{
+			name:      "SOPS-encrypted file",
+			data:      encryptSOPS(t, plaintext, identity.Recipient(), false),
+			providers: []KeyProvider{NewAgeKeyProvider(identity)},
+		},
+		{
+			name:      "SOPS-encrypted file with MAC of the encrypted values only",
+			data:      encryptSOPS(t, plaintext, identity.Recipient(), true),
+			providers: []KeyProvider{NewAgeKeyProvider(identity)},
+		},
+		{
+			name:      "SOPS-encrypted file with several identities",
+			data:      encryptSOPS(t, plaintext, identity.Recipient(), false),
+			providers: []KeyProvider{NewAgeKeyProvider(other, identity)},
+		},
+		{
+			name:      "age-encrypted file",
+			data:      encryptAge(t, plaintext, identity.Recipient(), false),
+			providers: []KeyProvider{NewAgeKeyProvider(identity)},
+		},
+		{
+			name:      "armored age-encrypted file",
+			data:      encryptAge(t, plaintext, identity.Recipient(), true),
+			providers: []KeyProvider{NewAgeKeyProvider(identity)},
+		},
+		{
+			name:      "plaintext file",
+			data:      []byte(plaintext),
+			providers: []KeyProvider{NewAgeKeyProvider(identity)},
+		},
+		{
+			name:      "SOPS-encrypted file with the wrong identity",
+			data:      encryptSOPS(t, plaintext, identity.Recipient(), false),
+			providers: []KeyProvider{NewAgeKeyProvider(other)},
+			wantErr:   "failed to decrypt the data key of the SOPS-encrypted file",
+		},
+		{
+			name:    "SOPS-encrypted file without key provider",
+			data:    encryptSOPS(t, plaintext, identity.Recipient(), false),
+			wantErr: "no key to decrypt the SOPS-encrypted file",
+		},
+		{
+			name:      "tampered SOPS-encrypted file",
+			data:      tampered,
+			providers: []KeyProvider{NewAgeKeyProvider(identity)},
+			wantErr:   "MAC mismatch",
+		},
+		{
+			name:      "age-encrypted file with the wrong identity",
+			data:      encryptAge(t, plaintext, identity.Recipient(), true),
+			providers: []KeyProvider{NewAgeKeyProvider(other)},
+			wantErr:   "failed to decrypt the age-encrypted file",
+		},
+		{
+			name:    "age-encrypted file without key provider",
+			data:    encryptAge(t, plaintext, identity.Recipient(), false),
+			wantErr: "no age identity to decrypt the age-encrypted file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decrypt(tt.data, tt.providers)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			var vals map[string]any
+			require.NoError(t, goYaml.Unmarshal(got, &vals))
+			if v, ok := vals["database"].(map[string]any)["port"].(int); ok {
+				vals["database"].(map[string]any)["port"] = int64(v)
+			}
+			assert.Equal(t, want, vals)
+		})
+	}
+}
+
+func TestMergeValuesDecrypt(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "keys.txt")
+	require.NoError(t, os.WriteFile(keyFile, []byte("# test key\n"+identity.String()+"\n"), 0600))
+	t.Setenv(ageKeyEnv, "")
+	t.Setenv(ageKeyFileEnv, keyFile)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	secrets := filepath.Join(dir, "secrets.yaml")
+	require.NoError(t, os.WriteFile(secrets, encryptSOPS(t, "image:\n  tag: \"1.27\"\npassword: s3cr3t\n", identity.Recipient(), false), 0644))
+	values := filepath.Join(dir, "values.yaml")
+	require.NoError(t, os.WriteFile(values, []byte("image:\n  repository: nginx\n"), 0644))
+
+	opts := Options{ValueFiles: []string{values, secrets}}
+	vals, err := opts.MergeValues(getter.Providers{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"image":    map[string]any{"repository": "nginx", "tag": "1.27"},
+		"password": "s3cr3t",
+	}, vals)
+
+	// Key providers replace the identities of the environment
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	opts.KeyProviders = []KeyProvider{NewAgeKeyProvider(other)}
+	_, err = opts.MergeValues(getter.Providers{})
+	require.ErrorContains(t, err, "failed to decrypt "+secrets)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// envVarName matches the names of environment variables that ExpandEnv expands.
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ExpandEnv expands the references to environment variables in the data of a
+// values file, looking them up with lookup, such as os.LookupEnv.
+//
+// The rules are:
+//
+//   - ${NAME} is replaced with the value of NAME. It is an error if NAME is
+//     not set.
+//   - ${NAME:-default} is replaced with the value of NAME, or with default if
+//     NAME is not set or empty.
+//   - $${ is replaced with a literal ${, which is not expanded.
+//   - Any other $, such as in $NAME, is left as is.
+//
+// The values of the variables are inserted as they are, before the data is
+// parsed as YAML, so they should be quoted in the file where they may contain
+// YAML syntax.
+func ExpandEnv(data []byte, lookup func(string) (string, bool)) ([]byte, error) {
+	var buf bytes.Buffer
+	for {
+		i := bytes.IndexByte(data, '$')
+		if i < 0 {
+			buf.Write(data)
+			return buf.Bytes(), nil
+		}
+		buf.Write(data[:i])
+		data = data[i:]
+
+		switch {
+		case bytes.HasPrefix(data, []byte("$${")):
+			buf.WriteString("${")
+			data = data[3:]
+		case bytes.HasPrefix(data, []byte("${")):
+			end := bytes.IndexByte(data, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated environment variable reference %q", firstLine(data))
+			}
+			value, err := expandRef(string(data[2:end]), lookup)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString(value)
+			data = data[end+1:]
+		default:
+			buf.WriteByte('$')
+			data = data[1:]
+		}
+	}
+}
+
+// expandRef returns the value of a reference, such as NAME or NAME:-default,
+// between ${ and }.
+func expandRef(ref string, lookup func(string) (string, bool)) (string, error) {
+	name, def, hasDefault := strings.Cut(ref, ":-")
+	if !envVarName.MatchString(name) {
+		return "", fmt.Errorf("invalid environment variable reference ${%s}", ref)
+	}
+	value, ok := lookup(name)
+	if hasDefault && value == "" {
+		return def, nil
+	}
+	if !ok {
+		return "", fmt.Errorf("environment variable %s referenced as ${%s} is not set", name, ref)
+	}
+	return value, nil
+}
+
+func firstLine(data []byte) []byte {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return data[:i]
+	}
+	return data
+}
@@ -0,0 +1,134 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{
+		"TAG":   "1.27",
+		"EMPTY": "",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "no references",
+			data: "image:\n  tag: latest\n",
+			want: "image:\n  tag: latest\n",
+		},
+		{
+			name: "reference",
+			data: "tag: ${TAG}\nimage: nginx:${TAG}\n",
+			want: "tag: 1.27\nimage: nginx:1.27\n",
+		},
+		{
+			name: "default for unset variable",
+			data: "region: ${REGION:-eu-west-1}",
+			want: "region: eu-west-1",
+		},
+		{
+			name: "default for empty variable",
+			data: "name: ${EMPTY:-fallback}",
+			want: "name: fallback",
+		},
+		{
+			name: "default ignored for set variable",
+			data: "tag: ${TAG:-latest}",
+			want: "tag: 1.27",
+		},
+		{
+			name: "empty variable",
+			data: "name: '${EMPTY}'",
+			want: "name: ''",
+		},
+		{
+			name: "escaped reference",
+			data: "template: $${TAG}",
+			want: "template: ${TAG}",
+		},
+		{
+			name: "other dollar signs",
+			data: "password: pa$$word$TAG$",
+			want: "password: pa$$word$TAG$",
+		},
+		{
+			name:    "unset variable",
+			data:    "region: ${REGION}",
+			wantErr: "environment variable REGION referenced as ${REGION} is not set",
+		},
+		{
+			name:    "invalid name",
+			data:    "region: ${1REGION}",
+			wantErr: "invalid environment variable reference ${1REGION}",
+		},
+		{
+			name:    "unterminated reference",
+			data:    "region: ${REGION\nzone: a\n",
+			wantErr: `unterminated environment variable reference "${REGION"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandEnv([]byte(tt.data), lookup)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestMergeValuesEnvExpand(t *testing.T) {
+	t.Setenv("HELM_TEST_TAG", "1.27")
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(valuesFile, []byte("image:\n  tag: \"${HELM_TEST_TAG}\"\n"), 0644))
+
+	opts := Options{ValueFiles: []string{valuesFile}}
+	vals, err := opts.MergeValues(getter.Providers{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"image": map[string]any{"tag": "${HELM_TEST_TAG}"}}, vals)
+
+	opts.ValuesEnvExpand = true
+	vals, err = opts.MergeValues(getter.Providers{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"image": map[string]any{"tag": "1.27"}}, vals)
+
+	require.NoError(t, os.WriteFile(valuesFile, []byte("image:\n  tag: ${HELM_TEST_UNSET}\n"), 0644))
+	_, err = opts.MergeValues(getter.Providers{})
+	require.ErrorContains(t, err, "failed to expand "+valuesFile)
+}
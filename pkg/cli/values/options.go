@@ -18,15 +18,20 @@ package values
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"helm.sh/helm/v4/pkg/chart/common/util"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/strvals"
 )
 
@@ -38,25 +43,70 @@ type Options struct {
 	FileValues    []string // --set-file
 	JSONValues    []string // --set-json
 	LiteralValues []string // --set-literal
+	// KeyProviders decrypt the SOPS-encrypted and age-encrypted values files.
+	// If nil, the age identities are loaded with LoadAgeKeyProvider.
+	KeyProviders []KeyProvider
+	// ValuesEnvExpand expands the references to environment variables in the
+	// values files with ExpandEnv.
+	ValuesEnvExpand bool // --values-env-expand
+	// JSONPatchFiles and JSONPatches are RFC 6902 JSON patches applied with
+	// ApplyJSONPatches, which needs the chart the values are for.
+	JSONPatchFiles []string // --json-patch
+	JSONPatches    []string // --set-jsonpatch
+	// SecretValues set values to references to secrets, such as
+	// vault://secret/data/db#password, which are resolved when rendering.
+	SecretValues []string // --set-secret
+	// ResolveSecrets resolves the references to secrets anywhere in the
+	// values, such as in the values files, rather than only those set with
+	// SecretValues.
+	ResolveSecrets bool // --resolve-secrets
 }
 
 // MergeValues merges values from files specified via -f/--values and directly
 // via --set-json, --set, --set-string, or --set-file, marshaling them to YAML
 func (opts *Options) MergeValues(p getter.Providers) (map[string]any, error) {
+	base, _, err := opts.MergeValuesWithTrace(p)
+	return base, err
+}
+
+// MergeValuesWithTrace merges values like MergeValues and traces which of the
+// files and flags set each value.
+func (opts *Options) MergeValuesWithTrace(p getter.Providers) (map[string]any, util.ValuesTrace, error) {
 	base := map[string]any{}
+	trace := util.ValuesTrace{}
+
+	// The values a single flag sets, to trace them
+	parsed := func(parse func(map[string]any) error) map[string]any {
+		vals := map[string]any{}
+		if err := parse(vals); err != nil {
+			return nil
+		}
+		return vals
+	}
 
 	// User specified a values files via -f/--values
-	for _, filePath := range opts.ValueFiles {
+	for n, filePath := range opts.ValueFiles {
 		raw, err := readFile(filePath, p)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		if IsEncrypted(raw) {
+			if raw, err = opts.decrypt(raw); err != nil {
+				return nil, nil, fmt.Errorf("failed to decrypt %s: %w", filePath, err)
+			}
+		}
+		if opts.ValuesEnvExpand {
+			if raw, err = ExpandEnv(raw, os.LookupEnv); err != nil {
+				return nil, nil, fmt.Errorf("failed to expand %s: %w", filePath, err)
+			}
 		}
 		currentMap, err := loader.LoadValues(bytes.NewReader(raw))
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
 		}
 		// Merge with the previous map
 		base = loader.MergeMaps(base, currentMap)
+		trace.Record(currentMap, fmt.Sprintf("-f %s (file %d)", filePath, n+1))
 	}
 
 	// User specified a value via --set-json
@@ -66,57 +116,214 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]any, error) {
 			// If value is JSON object format, parse it as map
 			var jsonMap map[string]any
 			if err := json.Unmarshal([]byte(trimmedValue), &jsonMap); err != nil {
-				return nil, fmt.Errorf("failed parsing --set-json data JSON: %s", value)
+				return nil, nil, fmt.Errorf("failed parsing --set-json data JSON: %s", value)
 			}
 			base = loader.MergeMaps(base, jsonMap)
+			trace.Record(jsonMap, "--set-json")
 		} else {
 			// Otherwise, parse it as key=value format
 			if err := strvals.ParseJSON(value, base); err != nil {
-				return nil, fmt.Errorf("failed parsing --set-json data %s", value)
+				return nil, nil, fmt.Errorf("failed parsing --set-json data %s", value)
 			}
+			trace.Record(parsed(func(vals map[string]any) error { return strvals.ParseJSON(value, vals) }), "--set-json")
 		}
 	}
 
 	// User specified a value via --set
 	for _, value := range opts.Values {
 		if err := strvals.ParseInto(value, base); err != nil {
-			return nil, fmt.Errorf("failed parsing --set data: %w", err)
+			return nil, nil, fmt.Errorf("failed parsing --set data: %w", err)
 		}
+		trace.Record(parsed(func(vals map[string]any) error { return strvals.ParseInto(value, vals) }), "--set")
 	}
 
 	// User specified a value via --set-string
 	for _, value := range opts.StringValues {
 		if err := strvals.ParseIntoString(value, base); err != nil {
-			return nil, fmt.Errorf("failed parsing --set-string data: %w", err)
+			return nil, nil, fmt.Errorf("failed parsing --set-string data: %w", err)
 		}
+		trace.Record(parsed(func(vals map[string]any) error { return strvals.ParseIntoString(value, vals) }), "--set-string")
 	}
 
 	// User specified a value via --set-file
 	for _, value := range opts.FileValues {
 		reader := func(rs []rune) (any, error) {
-			bytes, err := readFile(string(rs), p)
-			if err != nil {
-				return nil, err
-			}
-			return string(bytes), err
+			return readSetFile(string(rs), p)
 		}
 		if err := strvals.ParseIntoFile(value, base, reader); err != nil {
-			return nil, fmt.Errorf("failed parsing --set-file data: %w", err)
+			return nil, nil, fmt.Errorf("failed parsing --set-file data: %w", err)
 		}
+		// Trace without reading the file again, which may be stdin
+		noRead := func([]rune) (any, error) { return "", nil }
+		trace.Record(parsed(func(vals map[string]any) error { return strvals.ParseIntoFile(value, vals, noRead) }), "--set-file")
 	}
 
 	// User specified a value via --set-literal
 	for _, value := range opts.LiteralValues {
 		if err := strvals.ParseLiteralInto(value, base); err != nil {
-			return nil, fmt.Errorf("failed parsing --set-literal data: %w", err)
+			return nil, nil, fmt.Errorf("failed parsing --set-literal data: %w", err)
+		}
+		trace.Record(parsed(func(vals map[string]any) error { return strvals.ParseLiteralInto(value, vals) }), "--set-literal")
+	}
+
+	// User specified a value via --set-secret
+	for _, value := range opts.SecretValues {
+		vals := map[string]any{}
+		if err := strvals.ParseIntoString(value, vals); err != nil {
+			return nil, nil, fmt.Errorf("failed parsing --set-secret data: %w", err)
+		}
+		if err := checkSecretReferences(vals); err != nil {
+			return nil, nil, fmt.Errorf("failed parsing --set-secret data: %w", err)
+		}
+		base = loader.MergeMaps(base, vals)
+		trace.Record(vals, "--set-secret")
+	}
+
+	return base, trace, nil
+}
+
+// SecretReferences returns the values set to references to secrets with
+// --set-secret, at the keys they are set at.
+func (opts *Options) SecretReferences() (map[string]any, error) {
+	refs := map[string]any{}
+	for _, value := range opts.SecretValues {
+		vals := map[string]any{}
+		if err := strvals.ParseIntoString(value, vals); err != nil {
+			return nil, fmt.Errorf("failed parsing --set-secret data: %w", err)
+		}
+		if err := checkSecretReferences(vals); err != nil {
+			return nil, fmt.Errorf("failed parsing --set-secret data: %w", err)
+		}
+		refs = loader.MergeMaps(refs, vals)
+	}
+	return refs, nil
+}
+
+// checkSecretReferences returns an error if a value set with --set-secret is
+// not a reference to a secret, such as vault://secret/data/db#password.
+func checkSecretReferences(vals map[string]any) error {
+	for _, v := range vals {
+		switch v := v.(type) {
+		case map[string]any:
+			if err := checkSecretReferences(v); err != nil {
+				return err
+			}
+		case []any:
+			for _, e := range v {
+				if err := checkSecretReferences(map[string]any{"": e}); err != nil {
+					return err
+				}
+			}
+		case string:
+			if u, err := url.Parse(v); err != nil || u.Scheme == "" || !strings.Contains(v, "://") {
+				return fmt.Errorf("%q is not a reference to a secret, such as vault://secret/data/db#password", v)
+			}
+		}
+	}
+	return nil
+}
+
+// decrypt decrypts an encrypted values file with the key providers.
+func (opts *Options) decrypt(data []byte) ([]byte, error) {
+	providers := opts.KeyProviders
+	if providers == nil {
+		p, err := LoadAgeKeyProvider()
+		if err != nil {
+			return nil, err
+		}
+		providers = []KeyProvider{p}
+	}
+	return Decrypt(data, providers)
+}
+
+// readSetFile reads the value of a --set-file flag: the content of a file or,
+// for a local directory or glob pattern, a map of the names of the matching
+// files to their contents. Subdirectories are skipped.
+func readSetFile(filePath string, p getter.Providers) (any, error) {
+	if u, err := url.Parse(filePath); err == nil {
+		if _, err := p.ByScheme(u.Scheme); err == nil {
+			data, err := readFile(filePath, p)
+			return string(data), err
+		}
+	}
+
+	info, err := os.Stat(filePath)
+	switch {
+	case err == nil && info.IsDir():
+		entries, err := os.ReadDir(filePath)
+		if err != nil {
+			return nil, err
+		}
+		paths := make([]string, 0, len(entries))
+		for _, e := range entries {
+			paths = append(paths, filepath.Join(filePath, e.Name()))
 		}
+		return readFiles(paths)
+	case err != nil && strings.ContainsAny(filePath, "*?["):
+		paths, err := filepath.Glob(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", filePath, err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no files match %q", filePath)
+		}
+		return readFiles(paths)
 	}
+	data, err := readFile(filePath, p)
+	return string(data), err
+}
 
-	return base, nil
+// readFiles returns a map of the base names of the regular files among paths
+// to their contents.
+func readFiles(paths []string) (map[string]any, error) {
+	files := map[string]any{}
+	seen := map[string]string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		name := filepath.Base(path)
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("%s and %s have the same file name %q", other, path, name)
+		}
+		seen[name] = path
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = string(data)
+	}
+	return files, nil
 }
 
+// checksumFragment pins the SHA-256 checksum of a values file, such as in
+// https://example.com/values.yaml#sha256=<hex digest>.
+const checksumFragment = "#sha256="
+
 // readFile load a file from stdin, the local directory, or a remote file with a url.
+//
+// If the path ends with a checksum fragment, the content must match the checksum.
 func readFile(filePath string, p getter.Providers) ([]byte, error) {
+	filePath, checksum, pinned := strings.Cut(filePath, checksumFragment)
+	data, err := fetchFile(filePath, p)
+	if err != nil || !pinned {
+		return data, err
+	}
+	want, err := hex.DecodeString(checksum)
+	if err != nil || len(want) != sha256.Size {
+		return nil, fmt.Errorf("invalid sha256 checksum %q for %s", checksum, filePath)
+	}
+	if got := sha256.Sum256(data); !bytes.Equal(got[:], want) {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected sha256:%s, got sha256:%x", filePath, checksum, got)
+	}
+	return data, nil
+}
+
+func fetchFile(filePath string, p getter.Providers) ([]byte, error) {
 	if strings.TrimSpace(filePath) == "-" {
 		return io.ReadAll(os.Stdin)
 	}
@@ -130,7 +337,11 @@ func readFile(filePath string, p getter.Providers) ([]byte, error) {
 	if err != nil {
 		return os.ReadFile(filePath)
 	}
-	data, err := g.Get(filePath, getter.WithURL(filePath))
+	options := []getter.Option{getter.WithURL(filePath)}
+	if u.Scheme == registry.OCIScheme {
+		options = append(options, getter.WithArtifactType("values"))
+	}
+	data, err := g.Get(filePath, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"helm.sh/helm/v4/pkg/chart/common/util"
 	"helm.sh/helm/v4/pkg/getter"
 )
 
@@ -130,6 +131,15 @@ func TestReadFile(t *testing.T) {
 			expectError:  false,
 			expectedData: []byte("oci content"),
 		},
+		{
+			name:     "remote file with pinned checksum - success",
+			filePath: "https://example.com/values.yaml#sha256=0709e9b00585ba4764fd4d89bdefec5b1a20b3735c50d8e33a27f740023ceca2",
+			providers: getter.Providers{
+				mockProvider([]string{"http", "https"}, []byte("remote content"), nil),
+			},
+			expectError:  false,
+			expectedData: []byte("remote content"),
+		},
 		{
 			name:     "remote file - getter error",
 			filePath: "http://example.com/values.yaml",
@@ -253,6 +263,18 @@ func TestReadFileErrorMessages(t *testing.T) {
 			providers: getter.Providers{mockProvider([]string{"http"}, nil, errors.New("connection refused"))},
 			wantErr:   "connection refused",
 		},
+		{
+			name:      "checksum mismatch",
+			filePath:  "https://example.com/values.yaml#sha256=e6f99c26d9bdb577b83c4155b909611227b84e10e5448186f0cb3231dc579ace",
+			providers: getter.Providers{mockProvider([]string{"https"}, []byte("remote content"), nil)},
+			wantErr:   "checksum mismatch for https://example.com/values.yaml: expected sha256:e6f99c26d9bdb577b83c4155b909611227b84e10e5448186f0cb3231dc579ace, got sha256:0709e9b00585ba4764fd4d89bdefec5b1a20b3735c50d8e33a27f740023ceca2",
+		},
+		{
+			name:      "invalid checksum",
+			filePath:  "oci://registry.example.com/org/values:1.0.0#sha256=abc",
+			providers: getter.Providers{mockProvider([]string{"oci"}, []byte("remote content"), nil)},
+			wantErr:   `invalid sha256 checksum "abc" for oci://registry.example.com/org/values:1.0.0`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -272,6 +294,96 @@ func TestReadFileOriginal(t *testing.T) {
 	assert.Error(t, err, "Expected error when has special strings")
 }
 
+func TestReadSetFile(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "conf")
+	require.NoError(t, os.MkdirAll(filepath.Join(conf, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(conf, "app.conf"), []byte("port 80\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(conf, "log.conf"), []byte("level info\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(conf, "README.md"), []byte("# conf\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(conf, "sub", "app.conf"), []byte("port 81\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "other"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other", "app.conf"), []byte("port 82\n"), 0644))
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.Mkdir(empty, 0755))
+
+	tests := []struct {
+		name      string
+		filePath  string
+		providers getter.Providers
+		expected  any
+		wantErr   string
+	}{
+		{
+			name:     "file",
+			filePath: filepath.Join(conf, "app.conf"),
+			expected: "port 80\n",
+		},
+		{
+			name:     "directory",
+			filePath: conf,
+			expected: map[string]any{"app.conf": "port 80\n", "log.conf": "level info\n", "README.md": "# conf\n"},
+		},
+		{
+			name:     "empty directory",
+			filePath: empty,
+			expected: map[string]any{},
+		},
+		{
+			name:     "glob",
+			filePath: filepath.Join(conf, "*.conf"),
+			expected: map[string]any{"app.conf": "port 80\n", "log.conf": "level info\n"},
+		},
+		{
+			name:     "glob across directories",
+			filePath: filepath.Join(conf, "*", "app.conf"),
+			expected: map[string]any{"app.conf": "port 81\n"},
+		},
+		{
+			name:     "glob matching files with the same name",
+			filePath: filepath.Join(dir, "*", "app.conf"),
+			wantErr:  `have the same file name "app.conf"`,
+		},
+		{
+			name:     "glob without match",
+			filePath: filepath.Join(conf, "*.yaml"),
+			wantErr:  "no files match",
+		},
+		{
+			name:     "remote file is not globbed",
+			filePath: "https://example.com/app.conf?version=*",
+			providers: getter.Providers{
+				mockProvider([]string{"https"}, []byte("remote content"), nil),
+			},
+			expected: "remote content",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readSetFile(tt.filePath, tt.providers)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+
+	t.Run("set-file with a glob", func(t *testing.T) {
+		opts := Options{FileValues: []string{"app.configs=" + filepath.Join(conf, "*.conf")}}
+		vals, trace, err := opts.MergeValuesWithTrace(getter.Providers{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"app": map[string]any{
+				"configs": map[string]any{"app.conf": "port 80\n", "log.conf": "level info\n"},
+			},
+		}, vals)
+		assert.Equal(t, util.ValuesTrace{"app.configs": "--set-file"}, trace)
+	})
+}
+
 func TestMergeValuesCLI(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -328,6 +440,24 @@ func TestMergeValuesCLI(t *testing.T) {
 				"foo": "true",
 			},
 		},
+		{
+			name: "set secret value",
+			opts: Options{
+				SecretValues: []string{"db.password=vault://secret/data/db#password"},
+			},
+			expected: map[string]any{
+				"db": map[string]any{
+					"password": "vault://secret/data/db#password",
+				},
+			},
+		},
+		{
+			name: "set secret value that is not a reference",
+			opts: Options{
+				SecretValues: []string{"db.password=hunter2"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "multiple options",
 			opts: Options{
@@ -364,3 +494,54 @@ func TestMergeValuesCLI(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretReferences(t *testing.T) {
+	opts := Options{
+		Values:       []string{"replicas=2"},
+		SecretValues: []string{"db.password=vault://secret/data/db#password", "api.token=awssm://api#token"},
+	}
+	refs, err := opts.SecretReferences()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"db":  map[string]any{"password": "vault://secret/data/db#password"},
+		"api": map[string]any{"token": "awssm://api#token"},
+	}, refs)
+
+	opts.SecretValues = []string{"db.password=hunter2"}
+	_, err = opts.SecretReferences()
+	assert.Error(t, err)
+}
+
+func TestMergeValuesWithTrace(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(valuesFile, []byte("image:\n  repository: nginx\n  tag: \"1.27\"\nhosts:\n  - a.example.com\n"), 0644))
+	setFile := filepath.Join(t.TempDir(), "motd.txt")
+	require.NoError(t, os.WriteFile(setFile, []byte("hello"), 0644))
+
+	opts := Options{
+		ValueFiles:    []string{valuesFile},
+		JSONValues:    []string{`hosts=["b.example.com"]`},
+		Values:        []string{"image.tag=1.28"},
+		StringValues:  []string{"replicas=3"},
+		FileValues:    []string{"motd=" + setFile},
+		LiteralValues: []string{"password=a,b"},
+	}
+	vals, trace, err := opts.MergeValuesWithTrace(getter.Providers{})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"image":    map[string]any{"repository": "nginx", "tag": "1.28"},
+		"hosts":    []any{"b.example.com"},
+		"replicas": "3",
+		"motd":     "hello",
+		"password": "a,b",
+	}, vals)
+	assert.Equal(t, util.ValuesTrace{
+		"image.repository": "-f " + valuesFile + " (file 1)",
+		"image.tag":        "--set",
+		"hosts":            "--set-json",
+		"replicas":         "--set-string",
+		"motd":             "--set-file",
+		"password":         "--set-literal",
+	}, trace)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+// HasJSONPatches returns true if values are patched with --json-patch or
+// --set-jsonpatch.
+func (opts *Options) HasJSONPatches() bool {
+	return len(opts.JSONPatchFiles) > 0 || len(opts.JSONPatches) > 0
+}
+
+// ApplyJSONPatches applies the RFC 6902 JSON patches of --json-patch and
+// --set-jsonpatch to the values of a chart, in order, after all other values.
+//
+// The patches apply to the default values of the chart and its dependencies
+// merged with vals, so that they can remove or reorder the items of a default
+// list, which --set cannot express. The parts of the values the patches change
+// are returned merged into vals: the changed lists as a whole, and null for
+// removed keys. The patched values are traced in trace, unless it is nil.
+func (opts *Options) ApplyJSONPatches(chrt chart.Charter, vals map[string]any, p getter.Providers, trace util.ValuesTrace) (map[string]any, error) {
+	if !opts.HasJSONPatches() {
+		return vals, nil
+	}
+
+	var patches []jsonpatch.Patch
+	var sources []string
+	for _, filePath := range opts.JSONPatchFiles {
+		raw, err := readFile(filePath, p)
+		if err != nil {
+			return nil, err
+		}
+		// Patches can be written in YAML as well
+		data, err := yaml.YAMLToJSON(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+		patch, err := jsonpatch.DecodePatch(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+		patches = append(patches, patch)
+		sources = append(sources, "--json-patch "+filePath)
+	}
+	for _, value := range opts.JSONPatches {
+		patch, err := jsonpatch.DecodePatch([]byte(value))
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing --set-jsonpatch data %s: %w", value, err)
+		}
+		patches = append(patches, patch)
+		sources = append(sources, "--set-jsonpatch")
+	}
+
+	coalesced, err := util.CoalesceValues(chrt, vals)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := json.Marshal(coalesced)
+	if err != nil {
+		return nil, err
+	}
+
+	type change struct {
+		path   []string
+		source string
+	}
+	var changed []change
+	for n, patch := range patches {
+		if doc, err = patch.Apply(doc); err != nil {
+			return nil, fmt.Errorf("failed to apply JSON patch to values: %w", err)
+		}
+		for _, op := range patch {
+			if op.Kind() == "test" {
+				continue
+			}
+			for _, pointer := range []func() (string, error){op.Path, op.From} {
+				// From is only set for move and copy operations
+				if path, err := pointer(); err == nil {
+					changed = append(changed, change{parsePointer(path), sources[n]})
+				}
+			}
+		}
+	}
+
+	var patched map[string]any
+	if err := json.Unmarshal(doc, &patched); err != nil {
+		return nil, fmt.Errorf("JSON patches must leave the values a map: %w", err)
+	}
+	for _, c := range changed {
+		var set []string
+		vals, set = overlay(vals, patched, c.path)
+		if trace == nil {
+			continue
+		}
+		if len(set) == 0 {
+			trace.Record(vals, c.source)
+		} else {
+			trace.Set(set, c.source)
+		}
+	}
+	return vals, nil
+}
+
+// overlay sets the part of the patched values changed at path in vals. Lists
+// are set as a whole, as values cannot set single items of a list, and keys
+// missing in the patched values are set to null, which deletes their defaults.
+// It returns the path it set.
+func overlay(vals, patched map[string]any, path []string) (map[string]any, []string) {
+	if len(path) == 0 {
+		return patched, nil
+	}
+
+	// Find the part of the path to set: up to a list, a missing key or a scalar
+	var current any = patched
+	var value any
+	end := len(path)
+	for n, key := range path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			end = n
+			value = current
+			break
+		}
+		v, ok := m[key]
+		if !ok {
+			end = n + 1
+			value = nil
+			break
+		}
+		current, value = v, v
+	}
+
+	if vals == nil {
+		vals = map[string]any{}
+	}
+	parent := vals
+	for _, key := range path[:end-1] {
+		next, ok := parent[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			parent[key] = next
+		}
+		parent = next
+	}
+	parent[path[end-1]] = value
+	return vals, path[:end]
+}
+
+// parsePointer splits a JSON pointer, such as "/ingress/hosts/0", into its
+// unescaped tokens.
+func parsePointer(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for n, token := range tokens {
+		tokens[n] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
@@ -0,0 +1,146 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+func TestApplyJSONPatches(t *testing.T) {
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "patched", Version: "0.1.0", APIVersion: chart.APIVersionV2},
+		Values: map[string]any{
+			"image": map[string]any{"repository": "nginx", "tag": "1.27"},
+			"ingress": map[string]any{
+				"hosts": []any{"a.example.com", "b.example.com", "c.example.com"},
+			},
+		},
+	}
+
+	patchFile := filepath.Join(t.TempDir(), "patch.yaml")
+	require.NoError(t, os.WriteFile(patchFile, []byte("- op: remove\n  path: /ingress/hosts/0\n"), 0644))
+
+	tests := []struct {
+		name    string
+		opts    Options
+		vals    map[string]any
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name: "no patches",
+			vals: map[string]any{"replicas": 2},
+			want: map[string]any{"replicas": 2},
+		},
+		{
+			name: "remove list item",
+			opts: Options{JSONPatches: []string{`[{"op":"remove","path":"/ingress/hosts/1"}]`}},
+			want: map[string]any{
+				"ingress": map[string]any{"hosts": []any{"a.example.com", "c.example.com"}},
+			},
+		},
+		{
+			name: "reorder list items",
+			opts: Options{JSONPatches: []string{`[{"op":"move","from":"/ingress/hosts/2","path":"/ingress/hosts/0"}]`}},
+			want: map[string]any{
+				"ingress": map[string]any{"hosts": []any{"c.example.com", "a.example.com", "b.example.com"}},
+			},
+		},
+		{
+			name: "remove key",
+			opts: Options{JSONPatches: []string{`[{"op":"remove","path":"/image/tag"}]`}},
+			vals: map[string]any{"replicas": 2},
+			want: map[string]any{
+				"replicas": 2,
+				"image":    map[string]any{"tag": nil},
+			},
+		},
+		{
+			name: "patches apply after other values",
+			opts: Options{JSONPatches: []string{`[{"op":"add","path":"/ingress/hosts/-","value":"d.example.com"}]`}},
+			vals: map[string]any{"ingress": map[string]any{"hosts": []any{"x.example.com"}}},
+			want: map[string]any{
+				"ingress": map[string]any{"hosts": []any{"x.example.com", "d.example.com"}},
+			},
+		},
+		{
+			name: "patch file before inline patches",
+			opts: Options{
+				JSONPatchFiles: []string{patchFile},
+				JSONPatches:    []string{`[{"op":"test","path":"/ingress/hosts/0","value":"b.example.com"}]`},
+			},
+			want: map[string]any{
+				"ingress": map[string]any{"hosts": []any{"b.example.com", "c.example.com"}},
+			},
+		},
+		{
+			name:    "invalid patch",
+			opts:    Options{JSONPatches: []string{`{"op":"remove"}`}},
+			wantErr: "failed parsing --set-jsonpatch data",
+		},
+		{
+			name:    "failed patch",
+			opts:    Options{JSONPatches: []string{`[{"op":"remove","path":"/ingress/hosts/5"}]`}},
+			wantErr: "failed to apply JSON patch to values",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.ApplyJSONPatches(chrt, tt.vals, getter.Providers{}, nil)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyJSONPatchesTrace(t *testing.T) {
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "patched", Version: "0.1.0", APIVersion: chart.APIVersionV2},
+		Values: map[string]any{
+			"image":   map[string]any{"tag": "1.27"},
+			"ingress": map[string]any{"hosts": []any{"a.example.com", "b.example.com"}},
+		},
+	}
+	opts := Options{JSONPatches: []string{`[{"op":"remove","path":"/ingress/hosts/0"},{"op":"remove","path":"/image/tag"}]`}}
+	trace := util.ValuesTrace{"ingress.hosts": "--set"}
+
+	_, err := opts.ApplyJSONPatches(chrt, map[string]any{}, getter.Providers{}, trace)
+	require.NoError(t, err)
+	assert.Equal(t, util.ValuesTrace{
+		"ingress.hosts": "--set-jsonpatch",
+		"image.tag":     "--set-jsonpatch",
+	}, trace)
+}
+
+func TestParsePointer(t *testing.T) {
+	assert.Nil(t, parsePointer(""))
+	assert.Equal(t, []string{"annotations", "example.com/a~b", "0"}, parsePointer("/annotations/example.com~1a~0b/0"))
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	ci "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const adoptDesc = `
+This command adopts resources deployed without Helm, for example with kubectl,
+into a new release.
+
+The chart is rendered with the given values, as 'helm install --dry-run=server'
+would, and each rendered resource is matched with the live resource of the
+same kind, namespace and name. Every resource must exist, must not belong to
+another release or be managed by another tool and must match the rendered
+chart. The live resources are then labeled and annotated as belonging to the
+release, and revision 1 of the release is recorded. Apart from their labels
+and annotations, the resources are not changed, and no hooks are run.
+
+    $ helm adopt my-app ./my-app -f production.yaml
+
+Use '--allow-drift' to adopt resources that differ from the rendered chart.
+The differences are kept until the next upgrade of the release, which applies
+the chart. 'helm drift' shows them after the adoption.
+`
+
+func newAdoptCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewAdopt(cfg)
+	valueOpts := &values.Options{}
+
+	cmd := &cobra.Command{
+		Use:   "adopt RELEASE_NAME CHART",
+		Short: "adopt resources deployed without Helm into a new release",
+		Long:  adoptDesc,
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return compListCharts(toComplete, true)
+			}
+			return noMoreArgsComp()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registryClient, err := newRegistryClient(out, client.CertFile, client.KeyFile, client.CaFile,
+				client.InsecureSkipTLSVerify, client.PlainHTTP, client.Username, client.Password)
+			if err != nil {
+				return fmt.Errorf("missing registry client: %w", err)
+			}
+			client.SetRegistryClient(registryClient)
+			client.Namespace = settings.Namespace()
+
+			chartPath, err := client.LocateChart(args[1], settings)
+			if err != nil {
+				return err
+			}
+			p := getter.All(settings)
+			vals, err := valueOpts.MergeValues(p)
+			if err != nil {
+				return err
+			}
+			if client.SecretProviders, client.SecretReferences, err = secretResolution(valueOpts); err != nil {
+				return err
+			}
+			ch, err := loader.Load(chartPath)
+			if err != nil {
+				return err
+			}
+			ac, err := ci.NewAccessor(ch)
+			if err != nil {
+				return err
+			}
+			if req := ac.MetaDependencies(); len(req) > 0 {
+				if err := action.CheckDependencies(ch, req); err != nil {
+					return fmt.Errorf("an error occurred while checking for chart dependencies. You may need to run 'helm dependency build' to fetch missing dependencies: %w", err)
+				}
+			}
+
+			rel, err := client.Run(cmd.Context(), args[0], ch, vals)
+			if err != nil {
+				return fmt.Errorf("ADOPT FAILED: %w", err)
+			}
+			fmt.Fprintf(out, "Release %q has been adopted in namespace %q. Happy Helming!\n", rel.Name, rel.Namespace)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&client.Description, "description", "", "add a custom description")
+	f.BoolVar(&client.AllowDrift, "allow-drift", false, "adopt resources whose live state differs from the rendered chart")
+	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
+	addLockTimeoutFlag(f, &client.LockTimeout)
+	addChartPathOptionsFlags(f, &client.ChartPathOptions)
+	addValueOptionsFlags(f, valueOpts)
+	addSecretFlags(f, valueOpts)
+
+	return cmd
+}
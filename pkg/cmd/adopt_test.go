@@ -0,0 +1,48 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestAdoptCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "adopt the resources of a chart",
+		cmd:    "adopt virgil testdata/testcharts/alpine --set test.Name=bar",
+		golden: "output/adopt.txt",
+	}, {
+		name:      "adopt into an existing release",
+		cmd:       "adopt virgil testdata/testcharts/alpine",
+		golden:    "output/adopt-existing-release.txt",
+		rels:      []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "virgil"})},
+		wantError: true,
+	}, {
+		name:      "adopt without chart",
+		cmd:       "adopt virgil",
+		golden:    "output/adopt-no-chart.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestAdoptFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "adopt", false)
+	checkFileCompletion(t, "adopt myrelease", true)
+}
@@ -0,0 +1,188 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/loader"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/downloader"
+)
+
+const cacheHelp = `
+This command consists of multiple subcommands to inspect and clean up the
+content cache, where charts downloaded by Helm are stored by digest.
+
+The location of the cache can be changed with '--content-cache' or the
+HELM_CONTENT_CACHE environment variable.
+`
+
+const cachePruneHelp = `
+This command removes content from the cache. By default only content stored
+more than 30 days ago is removed; use '--older-than' to change the age or
+'--all' to empty the cache.
+`
+
+func newCacheCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache list|prune",
+		Short: "inspect and prune the chart content cache",
+		Long:  cacheHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newCacheListCmd(out))
+	cmd.AddCommand(newCachePruneCmd(out))
+
+	return cmd
+}
+
+func newCacheListCmd(out io.Writer) *cobra.Command {
+	var outfmt output.Format
+	cmd := &cobra.Command{
+		Use:               "list",
+		Aliases:           []string{"ls"},
+		Short:             "list the content stored in the cache",
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cache := &downloader.DiskCache{Root: settings.ContentCache}
+			entries, err := cache.Entries()
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, newCacheListWriter(entries))
+		},
+	}
+
+	bindOutputFlag(cmd, &outfmt)
+	return cmd
+}
+
+func newCachePruneCmd(out io.Writer) *cobra.Command {
+	var olderThan time.Duration
+	var all bool
+	cmd := &cobra.Command{
+		Use:               "prune",
+		Short:             "remove old content from the cache",
+		Long:              cachePruneHelp,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if olderThan < 0 {
+				return errors.New("--older-than must not be negative")
+			}
+			before := time.Now().Add(-olderThan)
+			if all {
+				// Anything stored up to now, including content written a moment ago
+				before = time.Now().Add(time.Second)
+			}
+			cache := &downloader.DiskCache{Root: settings.ContentCache}
+			removed, err := cache.Prune(before)
+			var size int64
+			for _, e := range removed {
+				size += e.Size
+			}
+			fmt.Fprintf(out, "Removed %d cache entries (%s)\n", len(removed), formatSize(size))
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.DurationVar(&olderThan, "older-than", 30*24*time.Hour, "remove content stored longer ago than this duration")
+	f.BoolVar(&all, "all", false, "remove all content from the cache")
+	cmd.MarkFlagsMutuallyExclusive("older-than", "all")
+	return cmd
+}
+
+type cacheElement struct {
+	Name    string    `json:"name,omitempty"`
+	Version string    `json:"version,omitempty"`
+	Type    string    `json:"type"`
+	Digest  string    `json:"digest"`
+	Size    int64     `json:"size"`
+	Cached  time.Time `json:"cached"`
+}
+
+type cacheListWriter struct {
+	entries []cacheElement
+}
+
+func newCacheListWriter(entries []downloader.CacheEntry) *cacheListWriter {
+	// Initialize the array so no results returns an empty array instead of null
+	elements := make([]cacheElement, 0, len(entries))
+	for _, e := range entries {
+		el := cacheElement{
+			Type:   e.Type[1:],
+			Digest: e.Digest,
+			Size:   e.Size,
+			Cached: e.ModTime,
+		}
+		if e.Type == downloader.CacheChart {
+			// The name and version are informational, a chart that cannot be
+			// loaded is still listed.
+			if c, err := loader.LoadFile(e.Path); err == nil {
+				if acc, err := chart.NewAccessor(c); err == nil {
+					el.Name = acc.Name()
+					el.Version, _ = acc.MetadataAsMap()["Version"].(string)
+				}
+			}
+		}
+		elements = append(elements, el)
+	}
+	return &cacheListWriter{entries: elements}
+}
+
+func (w *cacheListWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("NAME", "VERSION", "TYPE", "DIGEST", "SIZE", "CACHED")
+	for _, e := range w.entries {
+		table.AddRow(e.Name, e.Version, e.Type, e.Digest[:12], formatSize(e.Size), e.Cached.Format(time.DateTime))
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *cacheListWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.entries)
+}
+
+func (w *cacheListWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.entries)
+}
+
+// formatSize returns a human readable representation of a size in bytes.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
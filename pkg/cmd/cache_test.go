@@ -0,0 +1,71 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/downloader"
+)
+
+func TestCacheListAndPrune(t *testing.T) {
+	contentCache := t.TempDir()
+	cache := &downloader.DiskCache{Root: contentCache}
+
+	data, err := os.ReadFile("testdata/testcharts/compressedchart-0.1.0.tgz")
+	require.NoError(t, err)
+	key := sha256.Sum256(data)
+	pth, err := cache.Put(key, bytes.NewReader(data), downloader.CacheChart)
+	require.NoError(t, err)
+	longAgo := time.Now().Add(-90 * 24 * time.Hour)
+	require.NoError(t, os.Chtimes(pth, longAgo, longAgo))
+
+	recent := []byte("not a chart")
+	_, err = cache.Put(sha256.Sum256(recent), bytes.NewReader(recent), downloader.CacheChart)
+	require.NoError(t, err)
+
+	_, out, err := executeActionCommand(fmt.Sprintf("cache list --content-cache %s", contentCache))
+	require.NoError(t, err)
+	assert.Contains(t, out, "compressedchart")
+	assert.Contains(t, out, "0.1.0")
+	assert.Contains(t, out, hex.EncodeToString(key[:])[:12])
+
+	_, out, err = executeActionCommand(fmt.Sprintf("cache list --content-cache %s -o json", contentCache))
+	require.NoError(t, err)
+	assert.Contains(t, out, `"digest":"`+hex.EncodeToString(key[:])+`"`)
+
+	_, out, err = executeActionCommand(fmt.Sprintf("cache prune --content-cache %s", contentCache))
+	require.NoError(t, err)
+	assert.Contains(t, out, "Removed 1 cache entries")
+
+	_, out, err = executeActionCommand(fmt.Sprintf("cache prune --all --content-cache %s", contentCache))
+	require.NoError(t, err)
+	assert.Contains(t, out, "Removed 1 cache entries")
+
+	entries, err := cache.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
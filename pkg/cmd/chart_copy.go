@@ -0,0 +1,69 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const chartCopyHelp = `
+This command copies a chart from an OCI registry to another, or to another
+repository of the same registry, such as to promote a release candidate from
+a staging registry to production.
+
+The signatures, SBOMs and other artifacts attached to the chart are copied
+along, unless '--skip-referrers' is set. The chart is not stored locally: it is
+streamed between the registries, and within a registry its content is mounted
+in the destination repository when the registry supports it.
+
+    $ helm chart copy oci://staging.example.com/charts/mychart:1.2.3 oci://prod.example.com/charts/mychart:1.2.3
+
+The destination defaults to the version of the source:
+
+    $ helm chart copy oci://staging.example.com/charts/mychart:1.2.3 oci://prod.example.com/charts/mychart
+`
+
+func newChartCopyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewChartCopy(cfg)
+	o := &registryClientOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "copy SOURCE DESTINATION",
+		Short:             "copy a chart between OCI registries",
+		Long:              chartCopyHelp,
+		Args:              require.ExactArgs(2),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := o.setRegistryClient(cfg, out); err != nil {
+				return err
+			}
+			_, err := client.Run(args[0], args[1])
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.SkipReferrers, "skip-referrers", false, "do not copy the signatures, SBOMs and other artifacts attached to the chart")
+	o.addFlags(f)
+
+	return cmd
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChartCopyCmd(t *testing.T) {
+	host, flags := setupRegistryBrowse(t)
+	dir := t.TempDir()
+	sbom := filepath.Join(dir, "sbom.json")
+	if err := os.WriteFile(sbom, []byte(`{"bomFormat":"CycloneDX","specVersion":"1.5"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := executeActionCommand(fmt.Sprintf(
+		"push testdata/testcharts/signtest-0.1.0.tgz oci://%s/staging --prov-referrer --sbom %s %s", host, sbom, flags)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Within a registry, the blobs are mounted from the source repository
+	_, out, err := executeActionCommand(fmt.Sprintf(
+		"chart copy oci://%s/staging/signtest:0.1.0 oci://%s/prod/signtest %s", host, host, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		fmt.Sprintf("Copied: %s/staging/signtest:0.1.0 to %s/prod/signtest:0.1.0\n", host, host),
+		"Copied referrer: application/vnd.cncf.helm.chart.provenance.v1.prov sha256:",
+		"Copied referrer: application/vnd.cyclonedx+json sha256:",
+		"Mounted: 5 blobs from staging/signtest\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in copy output %q", want, out)
+		}
+	}
+
+	// Across registries, the chart and its referrers are streamed
+	other, _ := setupRegistryBrowse(t)
+	_, out, err = executeActionCommand(fmt.Sprintf(
+		"chart copy oci://%s/prod/signtest:0.1.0 oci://%s/charts/signtest:0.1.0 --username username --password password %s", host, other, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "Mounted:") || !strings.Contains(out, "Copied referrer: application/vnd.cyclonedx+json") {
+		t.Errorf("unexpected copy output %q", out)
+	}
+	_, out, err = executeActionCommand(fmt.Sprintf(
+		"pull oci://%s/charts/signtest --version 0.1.0 --verify --keyring testdata/helm-test-key.pub -d %s --username username --password password %s",
+		other, dir, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Chart Hash Verified: ") {
+		t.Errorf("expected the copied chart to verify, got %q", out)
+	}
+
+	_, out, err = executeActionCommand(fmt.Sprintf(
+		"chart copy oci://%s/staging/signtest:0.1.0 oci://%s/bare/signtest --skip-referrers %s", host, host, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "Copied referrer:") {
+		t.Errorf("expected no referrers to be copied, got %q", out)
+	}
+
+	for _, tt := range []struct {
+		args string
+		err  string
+	}{
+		{fmt.Sprintf("testdata/testcharts/signtest-0.1.0.tgz oci://%s/prod/signtest", host), "is not an OCI reference"},
+		{fmt.Sprintf("oci://%s/staging/signtest oci://%s/prod/signtest", host, host), "the version of the chart to copy is required"},
+		{fmt.Sprintf("oci://%s/staging/signtest:0.1.0 oci://%s/prod/signtest@sha256:%s", host, host, strings.Repeat("0", 64)), "cannot be a digest"},
+		{fmt.Sprintf("oci://%s/staging/signtest:9.9.9 oci://%s/prod/signtest", host, host), "not found"},
+	} {
+		_, _, err := executeActionCommand(fmt.Sprintf("chart copy %s %s", tt.args, flags))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("chart copy %s: expected an error containing %q, got %v", tt.args, tt.err, err)
+		}
+	}
+}
+
+func TestChartCopyFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "chart copy", false)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const chartDiffHelp = `
+This command compares two charts, chart archives or directories, such as two
+versions of a chart, to review a version bump.
+
+It prints the changed fields of Chart.yaml, followed by a unified diff of every
+file that was added, removed or modified, including the files of the subcharts
+and the default values in values.yaml:
+
+    $ helm chart diff mychart-1.0.0.tgz mychart-1.1.0.tgz
+
+With '--manifests', it instead compares the manifests rendered from the charts,
+the way 'helm template' renders them, resource by resource. The values of
+Secrets are redacted. The values to render the charts with are set with the
+'--values' and '--set' flags:
+
+    $ helm chart diff --manifests -f production.yaml mychart-1.0.0.tgz mychart-1.1.0.tgz
+
+With '--output json' or '--output yaml', the differences are printed as a
+document, which also lists the changed default values by their path.
+`
+
+func newChartDiffCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewChartDiff(cfg)
+	valueOpts := &values.Options{}
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "diff OLD_CHART NEW_CHART",
+		Short: "show the differences between two charts",
+		Long:  chartDiffHelp,
+		Args:  require.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			vals, err := valueOpts.MergeValues(getter.All(settings))
+			if err != nil {
+				return err
+			}
+			client.Namespace = settings.Namespace()
+			client.NoColor = settings.ShouldDisableColor()
+
+			result, err := client.Run(args[0], args[1], vals)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &chartDiffWriter{client: client, result: result})
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.Manifests, "manifests", false, "compare the manifests rendered from the charts instead of their contents")
+	f.IntVar(&client.Context, "diff-context", client.Context, "number of unchanged lines to show around each change in the diff")
+	addValueOptionsFlags(f, valueOpts)
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type chartDiffWriter struct {
+	client *action.ChartDiff
+	result *action.ChartDiffResult
+}
+
+func (w *chartDiffWriter) WriteTable(out io.Writer) error {
+	if !w.result.Changed() {
+		_, err := fmt.Fprintln(out, "No differences between the charts")
+		return err
+	}
+	return w.client.Write(out, w.result)
+}
+
+func (w *chartDiffWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.result)
+}
+
+func (w *chartDiffWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.result)
+}
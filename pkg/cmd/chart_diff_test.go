@@ -0,0 +1,52 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestChartDiffCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "diff the contents of two charts",
+		cmd:    "chart diff testdata/testcharts/chart-diff-v1 testdata/testcharts/chart-diff-v2",
+		golden: "output/chart-diff.txt",
+	}, {
+		name:   "diff the contents of two charts as json",
+		cmd:    "chart diff testdata/testcharts/chart-diff-v1 testdata/testcharts/chart-diff-v2 -o json",
+		golden: "output/chart-diff.json",
+	}, {
+		name:   "diff the rendered manifests of two charts",
+		cmd:    "chart diff --manifests --set greeting=hi testdata/testcharts/chart-diff-v1 testdata/testcharts/chart-diff-v2",
+		golden: "output/chart-diff-manifests.txt",
+	}, {
+		name:   "diff a chart with itself",
+		cmd:    "chart diff testdata/testcharts/chart-diff-v1 testdata/testcharts/chart-diff-v1",
+		golden: "output/chart-diff-none.txt",
+	}, {
+		name:      "diff a missing chart",
+		cmd:       "chart diff testdata/testcharts/chart-diff-v1 testdata/testcharts/no-such-chart",
+		golden:    "output/chart-diff-missing.txt",
+		wantError: true,
+	}, {
+		name:      "diff with one chart",
+		cmd:       "chart diff testdata/testcharts/chart-diff-v1",
+		golden:    "output/chart-diff-no-args.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
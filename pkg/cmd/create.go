@@ -17,15 +17,22 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/moby/term"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	chartv3 "helm.sh/helm/v4/internal/chart/v3"
 	chartutilv3 "helm.sh/helm/v4/internal/chart/v3/util"
 	"helm.sh/helm/v4/internal/gates"
+	"helm.sh/helm/v4/pkg/action"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cmd/require"
@@ -51,17 +58,43 @@ something like this:
 do not exist, Helm will attempt to create them as it goes. If the given
 destination exists and there are files in that directory, conflicting files
 will be overwritten, but other files will be left alone.
+
+The '--type' flag selects the scaffold of the chart: an 'application' (the
+default), a 'statefulset' with persistent volumes, a 'cronjob', an 'operator'
+with its RBAC rules, or a 'library' chart.
+
+The '--starter' flag scaffolds the chart from a starter instead: the name of a
+starter in $HELM_DATA_HOME/starters, the path of a starter, the OCI reference of
+a starter chart, or the git repository of a starter:
+
+    $ helm create --starter oci://example.com/starters/web:1.0.0 foo
+    $ helm create --starter 'git+https://example.com/starters.git//web?ref=v1.0.0' foo
+
+A starter may declare parameters in its starter.yaml file, whose values replace
+their placeholders in its templates and values, like <CHARTNAME>:
+
+    parameters:
+      - name: IMAGE
+        description: The image of the application
+        default: nginx
+
+The values are read from the YAML files of '--starter-values', e.g. 'IMAGE:
+busybox', or prompted for on a terminal. Parameters left unset take their
+defaults.
 `
 
 type createOptions struct {
 	starter         string // --starter
 	name            string
 	starterDir      string
-	chartAPIVersion string // --chart-api-version
+	chartAPIVersion string   // --chart-api-version
+	scaffold        string   // --type
+	starterValues   []string // --starter-values
+	client          *action.Create
 }
 
-func newCreateCmd(out io.Writer) *cobra.Command {
-	o := &createOptions{}
+func newCreateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	o := &createOptions{client: action.NewCreate(cfg)}
 
 	cmd := &cobra.Command{
 		Use:   "create NAME",
@@ -84,7 +117,15 @@ func newCreateCmd(out io.Writer) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&o.starter, "starter", "p", "", "the name or absolute path to Helm starter scaffold")
+	cmd.Flags().StringVarP(&o.starter, "starter", "p", "", "the name, absolute path, OCI reference or git repository (git+URL[//DIR][?ref=REF]) of the Helm starter scaffold")
+	cmd.Flags().StringVar(&o.scaffold, "type", "", fmt.Sprintf("the built-in scaffold of the chart. Allowed values: %s", strings.Join(chartutil.Scaffolds, ", ")))
+	cmd.Flags().StringSliceVar(&o.starterValues, "starter-values", nil, "the values of the parameters of the starter, in YAML files (can specify multiple)")
+	err := cmd.RegisterFlagCompletionFunc("type", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return chartutil.Scaffolds, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 	cmd.Flags().StringVar(&o.chartAPIVersion, "chart-api-version", chart.APIVersionV2, "chart API version to use (v2 or v3)")
 
 	if !gates.ChartV3.IsEnabled() {
@@ -111,31 +152,59 @@ func (o *createOptions) run(out io.Writer) error {
 }
 
 func (o *createOptions) createV2Chart(out io.Writer) error {
-	chartname := filepath.Base(o.name)
-	cfile := &chart.Metadata{
-		Name:        chartname,
-		Description: "A Helm chart for Kubernetes",
-		Type:        "application",
-		Version:     "0.1.0",
-		AppVersion:  "0.1.0",
-		APIVersion:  chart.APIVersionV2,
+	values, err := readStarterValues(o.starterValues)
+	if err != nil {
+		return err
 	}
 
-	if o.starter != "" {
-		// Create from the starter
-		lstarter := filepath.Join(o.starterDir, o.starter)
-		// If path is absolute, we don't want to prefix it with helm starters folder
-		if filepath.IsAbs(o.starter) {
-			lstarter = o.starter
-		}
-		return chartutil.CreateFrom(cfile, filepath.Dir(o.name), lstarter)
+	o.client.Settings = settings
+	o.client.Starter = o.starter
+	o.client.StarterDir = o.starterDir
+	o.client.StarterValues = values
+	o.client.Type = o.scaffold
+	if term.IsTerminal(os.Stdin.Fd()) {
+		o.client.Prompt = promptStarterParameter
 	}
 
 	chartutil.Stderr = out
-	_, err := chartutil.Create(chartname, filepath.Dir(o.name))
+	_, err = o.client.Run(o.name)
 	return err
 }
 
+// readStarterValues reads the values of the parameters of a starter from
+// YAML files, the later files overriding the earlier.
+func readStarterValues(files []string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var fileValues map[string]any
+		if err := yaml.Unmarshal(data, &fileValues); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		for name, value := range fileValues {
+			values[name] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
+
+// promptStarterParameter asks for the value of a parameter of a starter on
+// the terminal.
+func promptStarterParameter(p chartutil.StarterParameter) (string, error) {
+	prompt := p.Name
+	if p.Description != "" {
+		prompt = fmt.Sprintf("%s (%s)", p.Description, p.Name)
+	}
+	if p.Default != "" {
+		prompt += fmt.Sprintf(" [%s]", p.Default)
+	}
+	value, err := readLine(prompt+": ", false)
+	return strings.TrimSpace(value), err
+}
+
 func (o *createOptions) createV3Chart(out io.Writer) error {
 	chartname := filepath.Base(o.name)
 	cfile := &chartv3.Metadata{
@@ -147,13 +216,20 @@ func (o *createOptions) createV3Chart(out io.Writer) error {
 		APIVersion:  chartv3.APIVersionV3,
 	}
 
+	if o.scaffold != "" || len(o.starterValues) > 0 {
+		return errors.New("--type and --starter-values are not supported with chart API version v3")
+	}
+
 	if o.starter != "" {
 		// Create from the starter
-		lstarter := filepath.Join(o.starterDir, o.starter)
-		// If path is absolute, we don't want to prefix it with helm starters folder
-		if filepath.IsAbs(o.starter) {
-			lstarter = o.starter
+		o.client.Settings = settings
+		o.client.Starter = o.starter
+		o.client.StarterDir = o.starterDir
+		lstarter, cleanup, err := o.client.ResolveStarter()
+		if err != nil {
+			return err
 		}
+		defer cleanup()
 		return chartutilv3.CreateFrom(cfile, filepath.Dir(o.name), lstarter)
 	}
 
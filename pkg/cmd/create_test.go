@@ -298,3 +298,72 @@ func TestCreateCmdInvalidChartAPIVersion(t *testing.T) {
 		t.Errorf("Expected error %q, got %q", expectedErr, err.Error())
 	}
 }
+
+func TestCreateCmdType(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ensure.HelmHome(t)
+	cname := "testchart"
+
+	if _, _, err := executeActionCommand("create --type=library " + cname); err != nil {
+		t.Fatalf("Failed to run create: %s", err)
+	}
+
+	c, err := chartloader.LoadDir(cname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acc, err := chart.NewAccessor(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !acc.IsLibraryChart() {
+		t.Error("Expected a library chart")
+	}
+
+	if _, _, err := executeActionCommand("create --type=daemonset " + cname); err == nil {
+		t.Error("Expected error for unknown type, got nil")
+	}
+}
+
+func TestCreateCmdStarterValues(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ensure.HelmHome(t)
+	cname := "testchart"
+
+	// Create a starter with a parameter
+	starterchart := helmpath.DataPath("starters")
+	if err := os.MkdirAll(starterchart, 0o755); err != nil {
+		t.Fatalf("Could not create chart directory: %s", err)
+	}
+	if _, err := chartutil.Create("starterchart", starterchart); err != nil {
+		t.Fatalf("Could not create chart: %s", err)
+	}
+	starter := filepath.Join(starterchart, "starterchart")
+	if err := os.WriteFile(filepath.Join(starter, chartutil.StarterfileName), []byte("parameters:\n  - name: IMAGE\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(starter, chartutil.ValuesfileName), []byte("image: <IMAGE>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("starter-values.yaml", []byte("IMAGE: nginx\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := executeActionCommand("create --starter=starterchart " + cname); err == nil {
+		t.Error("Expected error for unset starter parameter, got nil")
+	}
+
+	if _, _, err := executeActionCommand("create --starter=starterchart --starter-values=starter-values.yaml " + cname); err != nil {
+		t.Fatalf("Failed to run create: %s", err)
+	}
+	values, err := os.ReadFile(filepath.Join(cname, chartutil.ValuesfileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(values) != "image: nginx\n" {
+		t.Errorf("Expected the IMAGE parameter to be replaced, got %q", values)
+	}
+	if _, err := os.Stat(filepath.Join(cname, chartutil.StarterfileName)); !os.IsNotExist(err) {
+		t.Errorf("Expected no %s file, got %v", chartutil.StarterfileName, err)
+	}
+}
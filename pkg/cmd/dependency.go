@@ -16,6 +16,7 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"io"
 	"path/filepath"
 
@@ -24,6 +25,8 @@ import (
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/getter"
 )
 
 const dependencyDesc = `
@@ -85,7 +88,7 @@ This will produce an error if the chart cannot be loaded.
 
 func newDependencyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "dependency update|build|list",
+		Use:     "dependency update|build|list|outdated|upgrade|tree|verify",
 		Aliases: []string{"dep", "dependencies"},
 		Short:   "manage a chart's dependencies",
 		Long:    dependencyDesc,
@@ -95,6 +98,10 @@ func newDependencyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	cmd.AddCommand(newDependencyListCmd(out))
 	cmd.AddCommand(newDependencyUpdateCmd(cfg, out))
 	cmd.AddCommand(newDependencyBuildCmd(out))
+	cmd.AddCommand(newDependencyOutdatedCmd(out))
+	cmd.AddCommand(newDependencyUpgradeCmd(out))
+	cmd.AddCommand(newDependencyTreeCmd(out))
+	cmd.AddCommand(newDependencyVerifyCmd(out))
 
 	return cmd
 }
@@ -125,6 +132,12 @@ func newDependencyListCmd(out io.Writer) *cobra.Command {
 func addDependencySubcommandFlags(f *pflag.FlagSet, client *action.Dependency) {
 	f.BoolVar(&client.Verify, "verify", false, "verify the packages against signatures")
 	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
+	addDependencyRepoFlags(f, client)
+	f.IntVar(&client.Parallel, "parallel", client.Parallel, "number of charts to download at the same time")
+}
+
+// addDependencyRepoFlags adds the flags to access the repositories of the dependencies.
+func addDependencyRepoFlags(f *pflag.FlagSet, client *action.Dependency) {
 	f.BoolVar(&client.SkipRefresh, "skip-refresh", false, "do not refresh the local repository cache")
 	f.StringVar(&client.Username, "username", "", "chart repository username where to locate the requested chart")
 	f.StringVar(&client.Password, "password", "", "chart repository password where to locate the requested chart")
@@ -134,3 +147,25 @@ func addDependencySubcommandFlags(f *pflag.FlagSet, client *action.Dependency) {
 	f.BoolVar(&client.PlainHTTP, "plain-http", false, "use insecure HTTP connections for the chart download")
 	f.StringVar(&client.CaFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
 }
+
+// newDependencyManager creates a manager for the dependencies of the chart at chartpath.
+func newDependencyManager(out io.Writer, chartpath string, client *action.Dependency) (*downloader.Manager, error) {
+	registryClient, err := newRegistryClient(out, client.CertFile, client.KeyFile, client.CaFile,
+		client.InsecureSkipTLSVerify, client.PlainHTTP, client.Username, client.Password)
+	if err != nil {
+		return nil, fmt.Errorf("missing registry client: %w", err)
+	}
+	return &downloader.Manager{
+		Out:              out,
+		ChartPath:        chartpath,
+		Keyring:          client.Keyring,
+		SkipUpdate:       client.SkipRefresh,
+		Getters:          getter.All(settings),
+		RegistryClient:   registryClient,
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+		ContentCache:     settings.ContentCache,
+		Debug:            settings.Debug,
+		Parallel:         client.Parallel,
+	}, nil
+}
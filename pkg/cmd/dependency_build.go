@@ -72,6 +72,7 @@ func newDependencyBuildCmd(out io.Writer) *cobra.Command {
 				RepositoryCache:  settings.RepositoryCache,
 				ContentCache:     settings.ContentCache,
 				Debug:            settings.Debug,
+				Parallel:         client.Parallel,
 			}
 			if client.Verify {
 				man.Verify = downloader.VerifyIfPossible
@@ -0,0 +1,144 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/downloader"
+)
+
+const dependencyOutdatedDesc = `
+List the dependencies of a chart for which a newer version is available.
+
+This refreshes the local repository cache, unless '--skip-refresh' is set, and
+shows for every outdated dependency:
+
+- CURRENT: the version in Chart.lock, or in 'charts/' when there is no lock file
+- WANTED: the newest version satisfying the version constraint in Chart.yaml
+- LATEST: the newest version available in the repository
+
+Use '--all' to list all of the dependencies. This will not alter the chart. Use
+'helm dependency upgrade' to upgrade the dependencies.
+`
+
+func newDependencyOutdatedCmd(out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+	var outfmt output.Format
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "outdated CHART",
+		Short: "list the dependencies that have newer versions",
+		Long:  dependencyOutdatedDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+
+			// Only tables are written along with the repository updates.
+			log := out
+			if outfmt != output.Table {
+				log = io.Discard
+			}
+			man, err := newDependencyManager(log, chartpath, client)
+			if err != nil {
+				return err
+			}
+			deps, err := man.Outdated()
+			if err != nil {
+				return err
+			}
+			if !all {
+				deps = outdatedDependencies(deps)
+			}
+			return outfmt.Write(out, &dependencyOutdatedWriter{deps: deps, columnWidth: client.ColumnWidth})
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&all, "all", false, "list all of the dependencies, including the ones that are up to date")
+	f.UintVar(&client.ColumnWidth, "max-col-width", 80, "maximum column width for output table")
+	addDependencyRepoFlags(f, client)
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+// outdatedDependencies returns the dependencies for which a newer version is available.
+func outdatedDependencies(deps []*downloader.DependencyVersions) []*downloader.DependencyVersions {
+	var outdated []*downloader.DependencyVersions
+	for _, d := range deps {
+		if d.Outdated() {
+			outdated = append(outdated, d)
+		}
+	}
+	return outdated
+}
+
+type dependencyOutdatedWriter struct {
+	deps        []*downloader.DependencyVersions
+	columnWidth uint
+}
+
+func (w *dependencyOutdatedWriter) WriteTable(out io.Writer) error {
+	if len(w.deps) == 0 {
+		_, err := fmt.Fprintln(out, "All dependencies are up to date")
+		return err
+	}
+	table := uitable.New()
+	table.MaxColWidth = w.columnWidth
+	table.AddRow("NAME", "CONSTRAINT", "CURRENT", "WANTED", "LATEST", "REPOSITORY")
+	for _, d := range w.deps {
+		table.AddRow(d.Name, d.Constraint, orDash(d.Current), orDash(d.Wanted), orDash(d.Latest), d.Repository)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *dependencyOutdatedWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.elements())
+}
+
+func (w *dependencyOutdatedWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.elements())
+}
+
+// elements initializes the list so no results are written as an empty list instead of null.
+func (w *dependencyOutdatedWriter) elements() []*downloader.DependencyVersions {
+	if w.deps == nil {
+		return []*downloader.DependencyVersions{}
+	}
+	return w.deps
+}
+
+// orDash returns s, or "-" when s is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -52,6 +52,23 @@ func TestDependencyListCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestDependencyTreeCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "Dependency tree",
+		cmd:    "dependency tree testdata/testcharts/dependency-tree",
+		golden: "output/dependency-tree.txt",
+	}, {
+		name:   "Dependency tree in JSON",
+		cmd:    "dependency tree testdata/testcharts/dependency-tree -o json",
+		golden: "output/dependency-tree.json",
+	}, {
+		name:   "Dependency tree without dependencies",
+		cmd:    "dependency tree testdata/testcharts/alpine",
+		golden: "output/dependency-tree-no-dependencies.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestDependencyFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "dependency", false)
 }
@@ -0,0 +1,123 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/internal/resolver"
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const dependencyTreeDesc = `
+Show the dependency tree of a chart, including the dependencies of its
+subcharts.
+
+Each chart is shown with the version found in its parent's 'charts/' directory
+and the version constraint its parent declares. Charts that are missing, at a
+version not satisfying their constraint, or in 'charts/' without being declared
+are marked as such.
+
+Charts included at different versions, or more than once under different
+aliases, are reported as conflicts.
+
+This can take chart archives and chart directories as input. It will not alter
+the contents of a chart.
+`
+
+func newDependencyTreeCmd(out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "tree CHART",
+		Short: "show the dependency tree of a chart",
+		Long:  dependencyTreeDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			tree, err := client.Tree(chartpath)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &dependencyTreeWriter{tree})
+		},
+	}
+
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type dependencyTreeWriter struct {
+	tree *resolver.Tree
+}
+
+func (w *dependencyTreeWriter) WriteTable(out io.Writer) error {
+	fmt.Fprintln(out, treeNodeLine(w.tree.Chart))
+	writeTreeNodes(out, w.tree.Chart.Dependencies, "")
+	for _, c := range w.tree.Conflicts {
+		fmt.Fprintf(out, "WARNING: %s\n", c)
+	}
+	return nil
+}
+
+func (w *dependencyTreeWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.tree)
+}
+
+func (w *dependencyTreeWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.tree)
+}
+
+// writeTreeNodes writes the nodes of a level of the tree, prefixing their
+// lines with the branches of the levels above.
+func writeTreeNodes(out io.Writer, nodes []*resolver.TreeNode, prefix string) {
+	for i, n := range nodes {
+		branch, indent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Fprintf(out, "%s%s%s\n", prefix, branch, treeNodeLine(n))
+		writeTreeNodes(out, n.Dependencies, prefix+indent)
+	}
+}
+
+// treeNodeLine describes a chart of the tree, as in "redis 17.3.0 as cache (^17.0.0)".
+func treeNodeLine(n *resolver.TreeNode) string {
+	parts := []string{n.Name, orDash(n.Version)}
+	if n.Alias != "" {
+		parts = append(parts, "as", n.Alias)
+	}
+	if n.Constraint != "" {
+		parts = append(parts, "("+n.Constraint+")")
+	}
+	if n.Status != resolver.StatusOK {
+		parts = append(parts, "["+n.Status+"]")
+	}
+	return strings.Join(parts, " ")
+}
@@ -41,6 +41,11 @@ rebuild the dependencies to an exact version.
 Dependencies are not required to be represented in 'Chart.yaml'. For that
 reason, an update command will not remove charts unless they are (a) present
 in the Chart.yaml file, but (b) at the wrong version.
+
+Use '--parallel' to download several charts at the same time, which speeds up
+updating charts with many dependencies. Downloaded charts are kept in the
+content cache, so charts shared with other charts, or downloaded by an
+interrupted update, are not downloaded again.
 `
 
 // newDependencyUpdateCmd creates a new dependency update command.
@@ -75,6 +80,7 @@ func newDependencyUpdateCmd(_ *action.Configuration, out io.Writer) *cobra.Comma
 				RepositoryCache:  settings.RepositoryCache,
 				ContentCache:     settings.ContentCache,
 				Debug:            settings.Debug,
+				Parallel:         client.Parallel,
 			}
 			if client.Verify {
 				man.Verify = downloader.VerifyAlways
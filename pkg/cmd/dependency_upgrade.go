@@ -0,0 +1,127 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/downloader"
+)
+
+const dependencyUpgradeDesc = `
+Upgrade the dependencies of a chart to newer versions.
+
+By default, the dependencies are upgraded to the newest version satisfying
+their version constraint in Chart.yaml. With '--latest', they are upgraded to
+the newest version available, and the constraints that the new versions do not
+satisfy are rewritten in Chart.yaml. Exact versions are replaced by the new
+version, and ranges like '^1.2.0' or '~1.2.0' are moved to the new version.
+
+Without '--write', this only prints the upgrades. With '--write', Chart.yaml is
+rewritten, leaving its comments and formatting untouched, and then Chart.lock
+and 'charts/' are updated like 'helm dependency update' does.
+
+The dependencies to upgrade can be named after the chart. By default, all of
+the dependencies are upgraded.
+
+    $ helm dependency upgrade ./mychart
+    $ helm dependency upgrade ./mychart nginx --latest --write
+`
+
+func newDependencyUpgradeCmd(out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+	var latest, write bool
+
+	cmd := &cobra.Command{
+		Use:   "upgrade CHART [DEPENDENCY...]",
+		Short: "upgrade the dependencies to newer versions",
+		Long:  dependencyUpgradeDesc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			var names []string
+			if len(args) > 1 {
+				names = args[1:]
+			}
+
+			man, err := newDependencyManager(out, chartpath, client)
+			if err != nil {
+				return err
+			}
+			if client.Verify {
+				man.Verify = downloader.VerifyAlways
+			}
+			deps, err := man.Outdated()
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				if !slices.ContainsFunc(deps, func(d *downloader.DependencyVersions) bool { return d.Name == name }) {
+					return fmt.Errorf("dependency %q not found in Chart.yaml", name)
+				}
+			}
+
+			var upgrades []*downloader.DependencyVersions
+			for _, d := range deps {
+				if len(names) > 0 && !slices.Contains(names, d.Name) {
+					continue
+				}
+				if d.Upgradable(latest) {
+					upgrades = append(upgrades, d)
+				}
+			}
+			if len(upgrades) == 0 {
+				fmt.Fprintln(out, "All dependencies are up to date")
+				return nil
+			}
+
+			table := uitable.New()
+			table.MaxColWidth = client.ColumnWidth
+			table.AddRow("NAME", "CURRENT", "UPGRADE", "CONSTRAINT")
+			for _, d := range upgrades {
+				constraint := d.Constraint
+				if c := d.UpgradeConstraint(latest); c != constraint {
+					constraint += " -> " + c
+				}
+				table.AddRow(d.Name, orDash(d.Current), d.Target(latest), constraint)
+			}
+			fmt.Fprintln(out, table)
+
+			if !write {
+				fmt.Fprintln(out, "\nRun with --write to update Chart.yaml and Chart.lock")
+				return nil
+			}
+			return man.Upgrade(upgrades, latest)
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&latest, "latest", false, "upgrade to the newest versions, even if they do not satisfy the version constraints")
+	f.BoolVar(&write, "write", false, "rewrite Chart.yaml and Chart.lock and update charts/")
+	addDependencySubcommandFlags(f, client)
+
+	return cmd
+}
@@ -0,0 +1,163 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/repo/v1/repotest"
+)
+
+// setupDependencyUpgrade creates a chart depending on reqtest 0.1.0, which is
+// up to date, and on compressedchart 0.1.0, which has newer versions, and
+// returns the chart directory along with the flags to access its repository.
+func setupDependencyUpgrade(t *testing.T) (string, string) {
+	t.Helper()
+	srv := repotest.NewTempServer(
+		t,
+		repotest.WithChartSourceGlob("testdata/testcharts/*.tgz"),
+	)
+	t.Cleanup(srv.Stop)
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+
+	chartpath := filepath.Join(srv.Root(), "upgrade")
+	if err := os.MkdirAll(chartpath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	chartfile := fmt.Sprintf(`apiVersion: v2
+name: upgrade
+version: 1.2.3
+dependencies:
+  - name: reqtest
+    version: 0.1.0
+    repository: %[1]s
+  # Keep on the 0.1 series
+  - name: compressedchart
+    version: "~0.1.0"
+    repository: %[1]s
+`, srv.URL())
+	if err := os.WriteFile(filepath.Join(chartpath, "Chart.yaml"), []byte(chartfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	flags := fmt.Sprintf("--repository-config %s --repository-cache %s --content-cache %s",
+		filepath.Join(srv.Root(), "repositories.yaml"), srv.Root(), t.TempDir())
+	if _, out, err := executeActionCommand(fmt.Sprintf("dependency update '%s' %s", chartpath, flags)); err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	return chartpath, flags
+}
+
+func TestDependencyOutdatedCmd(t *testing.T) {
+	chartpath, flags := setupDependencyUpgrade(t)
+
+	_, out, err := executeActionCommand(fmt.Sprintf("dependency outdated '%s' %s", chartpath, flags))
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	if !strings.Contains(out, "NAME           \tCONSTRAINT\tCURRENT\tWANTED\tLATEST\tREPOSITORY") {
+		t.Errorf("expected a table header, got:\n%s", out)
+	}
+	if !strings.Contains(out, "compressedchart\t~0.1.0    \t0.1.0  \t0.1.0 \t0.3.0 \t") {
+		t.Errorf("expected compressedchart to be outdated, got:\n%s", out)
+	}
+	if strings.Contains(out, "reqtest") {
+		t.Errorf("expected reqtest to be up to date, got:\n%s", out)
+	}
+
+	_, out, err = executeActionCommand(fmt.Sprintf("dependency outdated '%s' %s --skip-refresh --all -o json", chartpath, flags))
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	var deps []downloader.DependencyVersions
+	if err := json.Unmarshal([]byte(out), &deps); err != nil {
+		t.Fatalf("invalid JSON output: %s\n%s", err, out)
+	}
+	if len(deps) != 2 {
+		t.Fatalf("expected 2 dependencies, got %d", len(deps))
+	}
+	if d := deps[0]; d.Name != "reqtest" || d.Current != "0.1.0" || d.Latest != "0.1.0" {
+		t.Errorf("unexpected versions for reqtest: %+v", d)
+	}
+}
+
+func TestDependencyUpgradeCmd(t *testing.T) {
+	chartpath, flags := setupDependencyUpgrade(t)
+	chartfile := filepath.Join(chartpath, "Chart.yaml")
+
+	// The constraint keeps compressedchart on 0.1.0.
+	_, out, err := executeActionCommand(fmt.Sprintf("dependency upgrade '%s' %s --skip-refresh", chartpath, flags))
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	if !strings.Contains(out, "All dependencies are up to date") {
+		t.Errorf("expected no upgrades, got:\n%s", out)
+	}
+
+	// Without --write, the chart is not changed.
+	before, err := os.ReadFile(chartfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, out, err = executeActionCommand(fmt.Sprintf("dependency upgrade '%s' compressedchart %s --skip-refresh --latest", chartpath, flags))
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	if !strings.Contains(out, "compressedchart\t0.1.0  \t0.3.0  \t~0.1.0 -> ~0.3.0") {
+		t.Errorf("expected compressedchart to be upgraded, got:\n%s", out)
+	}
+	if after, err := os.ReadFile(chartfile); err != nil {
+		t.Fatal(err)
+	} else if string(after) != string(before) {
+		t.Errorf("expected Chart.yaml to be unchanged, got:\n%s", after)
+	}
+
+	_, out, err = executeActionCommand(fmt.Sprintf("dependency upgrade '%s' compressedchart %s --skip-refresh --latest --write", chartpath, flags))
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	after, err := os.ReadFile(chartfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := strings.Replace(string(before), `"~0.1.0"`, `"~0.3.0"`, 1); string(after) != expected {
+		t.Errorf("expected Chart.yaml:\n%s\ngot:\n%s", expected, after)
+	}
+	lock, err := os.ReadFile(filepath.Join(chartpath, "Chart.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(lock), "version: 0.3.0") {
+		t.Errorf("expected compressedchart 0.3.0 in Chart.lock, got:\n%s", lock)
+	}
+	if _, err := os.Stat(filepath.Join(chartpath, "charts", "compressedchart-0.3.0.tgz")); err != nil {
+		t.Error(err)
+	}
+
+	_, _, err = executeActionCommand(fmt.Sprintf("dependency upgrade '%s' missing %s --skip-refresh", chartpath, flags))
+	if err == nil || !strings.Contains(err.Error(), `dependency "missing" not found in Chart.yaml`) {
+		t.Errorf("expected an error for a missing dependency, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/downloader"
+)
+
+const dependencyVerifyDesc = `
+Verify that the dependencies of a chart match its lock file.
+
+This fails if Chart.lock is missing or out of sync with the dependencies in
+Chart.yaml, or if a locked dependency is missing from 'charts/' or at another
+version than the locked one. For a chart directory, the archives in 'charts/'
+must also match the digest of the charts in the cached index of their
+repository.
+
+The same checks are run by the '--locked' flag of 'helm install', 'helm
+upgrade', 'helm template' and 'helm package', so that CI pipelines build and
+deploy charts with exactly the dependencies that were locked.
+`
+
+func newDependencyVerifyCmd(out io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify CHART",
+		Short: "verify that the dependencies of a chart match its lock file",
+		Long:  dependencyVerifyDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			if err := verifyLock(chartpath); err != nil {
+				return err
+			}
+			fmt.Fprintln(out, "The dependencies match the lock file")
+			return nil
+		},
+	}
+}
+
+// verifyLock checks that the dependencies of a chart match its lock file.
+func verifyLock(chartpath string) error {
+	man := &downloader.Manager{
+		ChartPath:        chartpath,
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+	}
+	return man.VerifyLock()
+}
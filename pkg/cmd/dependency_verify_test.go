@@ -0,0 +1,84 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestDependencyVerifyCmd(t *testing.T) {
+	chartpath, flags := setupDependencyUpgrade(t)
+
+	_, out, err := executeActionCommand(fmt.Sprintf("dependency verify '%s' %s", chartpath, flags))
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	if !strings.Contains(out, "The dependencies match the lock file") {
+		t.Errorf("expected the dependencies to match, got:\n%s", out)
+	}
+	if _, out, err := executeActionCommand(fmt.Sprintf("package '%s' %s --locked --destination %s", chartpath, flags, t.TempDir())); err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+
+	// Repackage a dependency, so that its archive no longer matches the
+	// digest in the repository index.
+	archive := filepath.Join(chartpath, "charts", "compressedchart-0.1.0.tgz")
+	c, err := loader.Load(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(archive); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chartutil.Save(c, filepath.Dir(archive)); err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range []string{"dependency verify", "template --locked", "package --locked --destination " + t.TempDir()} {
+		_, _, err = executeActionCommand(fmt.Sprintf("%s '%s' %s", cmd, chartpath, flags))
+		if err == nil || !strings.Contains(err.Error(), "compressedchart-0.1.0.tgz does not match the digest of compressedchart 0.1.0") {
+			t.Errorf("%s: expected a digest mismatch, got %v", cmd, err)
+		}
+	}
+
+	if err := os.Remove(archive); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = executeActionCommand(fmt.Sprintf("dependency verify '%s' %s", chartpath, flags))
+	if err == nil || !strings.Contains(err.Error(), "compressedchart 0.1.0 is missing") {
+		t.Errorf("expected a missing dependency, got %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(chartpath, "Chart.lock")); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = executeActionCommand(fmt.Sprintf("dependency verify '%s' %s", chartpath, flags))
+	if err == nil || !strings.Contains(err.Error(), "the lock file (Chart.lock) is missing") {
+		t.Errorf("expected a missing lock file, got %v", err)
+	}
+
+	_, _, err = executeActionCommand(fmt.Sprintf("template '%s' --locked --dev-deps", chartpath))
+	if err == nil || !strings.Contains(err.Error(), "none of the others can be") {
+		t.Errorf("expected --locked and --dev-deps to be exclusive, got %v", err)
+	}
+}
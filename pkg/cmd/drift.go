@@ -0,0 +1,143 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/kube"
+)
+
+const driftHelp = `
+This command compares the resources of a release with their live state in the
+cluster, and shows the ones that drifted from the manifest of the release.
+
+Each field of the manifest that was modified or removed in the cluster is
+reported, along with the fields other tools added, such as a label added with
+'kubectl edit'. Fields set by Kubernetes itself, such as defaults and the
+status of the resources, are not reported: the managed fields of the resources
+tell which tool set each field.
+
+    $ helm drift my-release
+    Deployment default/web:
+      ~ spec.replicas: 3 -> 5
+      + metadata.annotations: {"team":"payments"} (kubectl-edit)
+    Service default/web: missing
+
+By default, the resources are compared with the latest revision of the release.
+Use '--revision' to compare them with another one.
+`
+
+func newDriftCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewDrift(cfg)
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "drift RELEASE_NAME",
+		Short: "show the drift of the resources of a release from its manifest",
+		Long:  driftHelp,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListReleases(toComplete, args, cfg)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			drift, err := client.Run(args[0])
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, driftWriter(drift))
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&client.Version, "revision", 0, "if set, compare the resources with the manifest of the named release with revision")
+	err := cmd.RegisterFlagCompletionFunc("revision", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return compListRevisions(toComplete, cfg, args[0])
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type driftWriter []kube.ResourceDrift
+
+func (d driftWriter) WriteTable(out io.Writer) error {
+	if len(d) == 0 {
+		_, err := fmt.Fprintln(out, "No drift detected")
+		return err
+	}
+	for _, rd := range d {
+		name := rd.Name
+		if rd.Namespace != "" {
+			name = rd.Namespace + "/" + rd.Name
+		}
+		if rd.Missing {
+			fmt.Fprintf(out, "%s %s: missing\n", rd.Kind, name)
+			continue
+		}
+		fmt.Fprintf(out, "%s %s:\n", rd.Kind, name)
+		for _, f := range rd.Fields {
+			switch f.Type {
+			case kube.FieldAdded:
+				fmt.Fprintf(out, "  + %s: %s (%s)\n", f.Path, driftValue(f.Live), f.Manager)
+			case kube.FieldRemoved:
+				fmt.Fprintf(out, "  - %s: %s\n", f.Path, driftValue(f.Desired))
+			default:
+				fmt.Fprintf(out, "  ~ %s: %s -> %s\n", f.Path, driftValue(f.Desired), driftValue(f.Live))
+			}
+		}
+	}
+	return nil
+}
+
+func (d driftWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, []kube.ResourceDrift(d))
+}
+
+func (d driftWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, []kube.ResourceDrift(d))
+}
+
+// driftValue formats the value of a field on a single line.
+func driftValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/kube"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestDriftCmd(t *testing.T) {
+	rels := []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "musketeers"})}
+
+	tests := []cmdTestCase{{
+		name:   "drift of a release without drift",
+		cmd:    "drift musketeers",
+		golden: "output/drift-none.txt",
+		rels:   rels,
+	}, {
+		name:   "drift of a release without drift in json",
+		cmd:    "drift musketeers -o json",
+		golden: "output/drift-none.json",
+		rels:   rels,
+	}, {
+		name:      "drift of a missing release",
+		cmd:       "drift dartagnan",
+		golden:    "output/drift-missing-release.txt",
+		rels:      rels,
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestDriftWriter(t *testing.T) {
+	drift := driftWriter{{
+		Kind:      "Deployment",
+		Namespace: "default",
+		Name:      "web",
+		Fields: []kube.FieldDrift{{
+			Path:    "metadata.annotations",
+			Type:    kube.FieldAdded,
+			Live:    map[string]any{"team": "payments"},
+			Manager: "kubectl-edit",
+		}, {
+			Path:    "metadata.labels.tier",
+			Type:    kube.FieldRemoved,
+			Desired: "frontend",
+		}, {
+			Path:    "spec.replicas",
+			Type:    kube.FieldModified,
+			Desired: int64(3),
+			Live:    int64(5),
+		}},
+	}, {
+		Kind:    "ClusterRole",
+		Name:    "web",
+		Missing: true,
+	}}
+
+	var out bytes.Buffer
+	if err := drift.WriteTable(&out); err != nil {
+		t.Fatal(err)
+	}
+	expect := `Deployment default/web:
+  + metadata.annotations: {"team":"payments"} (kubectl-edit)
+  - metadata.labels.tier: frontend
+  ~ spec.replicas: 3 -> 5
+ClusterRole web: missing
+`
+	if out.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, out.String())
+	}
+}
+
+func TestDriftRevisionCompletion(t *testing.T) {
+	revisionFlagCompletionTest(t, "drift")
+}
+
+func TestDriftOutputCompletion(t *testing.T) {
+	outputFlagCompletionTest(t, "drift")
+}
+
+func TestDriftFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "drift", false)
+	checkFileCompletion(t, "drift myrelease", false)
+}
@@ -23,37 +23,80 @@ import (
 	"log"
 	"log/slog"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
 	"k8s.io/klog/v2"
 
+	"helm.sh/helm/v4/internal/statusreaders"
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/downloader"
 	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/kube"
 	"helm.sh/helm/v4/pkg/postrenderer"
+	"helm.sh/helm/v4/pkg/release/common"
 	"helm.sh/helm/v4/pkg/repo/v1"
+	"helm.sh/helm/v4/pkg/secrets"
+	"helm.sh/helm/v4/pkg/storage"
 )
 
 const (
 	outputFlag         = "output"
 	postRenderFlag     = "post-renderer"
 	postRenderArgsFlag = "post-renderer-args"
+	postRenderWasmFlag = "post-renderer-wasm"
 )
 
 func addValueOptionsFlags(f *pflag.FlagSet, v *values.Options) {
-	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML file or a URL (can specify multiple)")
+	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML file, a URL or an OCI reference, optionally pinned with #sha256=<checksum> (can specify multiple)")
 	f.StringArrayVar(&v.Values, "set", []string{}, "set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	f.StringArrayVar(&v.StringValues, "set-string", []string{}, "set STRING values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
-	f.StringArrayVar(&v.FileValues, "set-file", []string{}, "set values from respective files specified via the command line (can specify multiple or separate values with commas: key1=path1,key2=path2)")
+	f.StringArrayVar(&v.FileValues, "set-file", []string{}, "set values from respective files specified via the command line, or maps of file names to contents from directories and glob patterns (can specify multiple or separate values with commas: key1=path1,key2=path2)")
 	f.StringArrayVar(&v.JSONValues, "set-json", []string{}, "set JSON values on the command line (can specify multiple or separate values with commas: key1=jsonval1,key2=jsonval2 or using json format: {\"key1\": jsonval1, \"key2\": \"jsonval2\"})")
 	f.StringArrayVar(&v.LiteralValues, "set-literal", []string{}, "set a literal STRING value on the command line")
+	f.BoolVar(&v.ValuesEnvExpand, "values-env-expand", false, "expand ${VAR} and ${VAR:-default} references to environment variables in the values files. Use $${ for a literal ${")
+}
+
+// addJSONPatchFlags adds the flags patching values with JSON patches, which
+// need the chart the values are for.
+func addJSONPatchFlags(f *pflag.FlagSet, v *values.Options) {
+	f.StringArrayVar(&v.JSONPatchFiles, "json-patch", []string{}, "patch the values with an RFC 6902 JSON patch in a JSON or YAML file or a URL, after all other values (can specify multiple)")
+	f.StringArrayVar(&v.JSONPatches, "set-jsonpatch", []string{}, "patch the values with an RFC 6902 JSON patch, such as '[{\"op\":\"remove\",\"path\":\"/ingress/hosts/0\"}]', after all other values (can specify multiple)")
+}
+
+// addSecretFlags adds the flags setting values to references to secrets, which
+// are resolved when rendering the chart.
+func addSecretFlags(f *pflag.FlagSet, v *values.Options) {
+	f.StringArrayVar(&v.SecretValues, "set-secret", []string{}, "set values to references to secrets, resolved when rendering and stored with the release as references (can specify multiple or separate values with commas: key1=vault://path#field,key2=awssm://name#field)")
+	f.BoolVar(&v.ResolveSecrets, "resolve-secrets", false, "resolve the references to secrets anywhere in the values, such as in values files, rather than only those set with --set-secret")
+}
+
+// secretResolution returns the providers resolving the references to secrets
+// in the values and the references they are limited to. Only the references
+// set with --set-secret are resolved, or every reference with
+// --resolve-secrets. Without either, no reference is resolved.
+func secretResolution(v *values.Options) (secrets.Providers, map[string]any, error) {
+	if v.ResolveSecrets {
+		return secrets.Default(), nil, nil
+	}
+	if len(v.SecretValues) == 0 {
+		return nil, nil, nil
+	}
+	refs, err := v.SecretReferences()
+	if err != nil {
+		return nil, nil, err
+	}
+	return secrets.Default(), refs, nil
 }
 
 func AddWaitFlag(cmd *cobra.Command, wait *kube.WaitStrategy) {
@@ -65,6 +108,33 @@ func AddWaitFlag(cmd *cobra.Command, wait *kube.WaitStrategy) {
 	cmd.Flags().Lookup("wait").NoOptDefVal = string(kube.StatusWatcherStrategy)
 }
 
+// addWaitForFlag adds the --wait-for flag, which sets custom readiness
+// conditions for resources of specific kinds.
+func addWaitForFlag(cmd *cobra.Command, exprs *[]string) {
+	cmd.Flags().StringArrayVar(exprs, "wait-for", nil, `custom readiness condition for resources of a kind, replacing the built-in checks for that kind. Of the form KIND[.GROUP]:JSONPATH[==VALUE|!=VALUE] or KIND[.GROUP]:cel:EXPRESSION, e.g. 'Certificate:.status.conditions[?(@.type=="Ready")].status==True'. Can be specified multiple times. Implies --wait=watcher when --wait is not set`)
+}
+
+// waitForOptions validates the --wait-for expressions and returns the wait
+// options that apply them. Unless --wait is set explicitly, the watcher
+// strategy is used so the expressions are evaluated for all resources.
+func waitForOptions(cmd *cobra.Command, exprs []string, ws *kube.WaitStrategy) ([]kube.WaitOption, error) {
+	if len(exprs) == 0 {
+		return nil, nil
+	}
+	for _, expr := range exprs {
+		if _, err := statusreaders.ParseReadinessExpression(expr); err != nil {
+			return nil, err
+		}
+	}
+	if *ws == kube.LegacyStrategy {
+		return nil, errors.New("--wait-for cannot be used with --wait=legacy")
+	}
+	if !cmd.Flags().Changed("wait") {
+		*ws = kube.StatusWatcherStrategy
+	}
+	return []kube.WaitOption{kube.WithReadinessExpressions(exprs...)}, nil
+}
+
 type waitValue kube.WaitStrategy
 
 func newWaitValue(defaultValue kube.WaitStrategy, ws *kube.WaitStrategy) *waitValue {
@@ -105,6 +175,10 @@ func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
 	f.StringVar(&c.Version, "version", "", "specify a version constraint for the chart version to use. This constraint can be a specific tag (e.g. 1.1.1) or it may reference a valid range (e.g. ^2.0.0). If this is not specified, the latest version is used")
 	f.BoolVar(&c.Verify, "verify", false, "verify the package before using it")
 	f.StringVar(&c.Keyring, "keyring", defaultKeyring(), "location of public keys used for verification")
+	f.BoolVar(&c.VerifyKeyless, "verify-keyless", false, "verify the Sigstore keyless signature of the package before using it")
+	f.StringVar(&c.CertificateIdentity, "certificate-identity", "", "identity the keyless signing certificate must be issued to")
+	f.StringVar(&c.CertificateOIDCIssuer, "certificate-oidc-issuer", "", "OIDC issuer that must have authenticated the keyless signing identity")
+	f.StringVar(&c.TrustedRoot, "trusted-root", "", "location of the Sigstore trusted root used for keyless verification")
 	f.StringVar(&c.RepoURL, "repo", "", "chart repository url where to locate the requested chart")
 	f.StringVar(&c.Username, "username", "", "chart repository username where to locate the requested chart")
 	f.StringVar(&c.Password, "password", "", "chart repository password where to locate the requested chart")
@@ -116,6 +190,497 @@ func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
 	f.BoolVar(&c.PassCredentialsAll, "pass-credentials", false, "pass credentials to all domains")
 }
 
+const (
+	applyMethodClient = "client"
+	applyMethodServer = "server"
+	applyMethodAuto   = "auto"
+)
+
+// addApplyMethodFlag adds the --apply-method flag, which selects client-side
+// or server-side apply by name. It is an alternative to --server-side and sets
+// the same option.
+func addApplyMethodFlag(cmd *cobra.Command, serverSide *bool) {
+	v := &applyMethodValue{
+		allowed: []string{applyMethodClient, applyMethodServer},
+		get: func() string {
+			if *serverSide {
+				return applyMethodServer
+			}
+			return applyMethodClient
+		},
+		set: func(method string) { *serverSide = method == applyMethodServer },
+	}
+	bindApplyMethodFlag(cmd, v)
+}
+
+// addApplyMethodFlagWithAuto adds the --apply-method flag for commands acting
+// on an existing release, where "auto" reuses the method of the previous
+// release. It sets the same option as --server-side.
+func addApplyMethodFlagWithAuto(cmd *cobra.Command, serverSide *string) {
+	v := &applyMethodValue{
+		allowed: []string{applyMethodClient, applyMethodServer, applyMethodAuto},
+		get: func() string {
+			switch *serverSide {
+			case "true":
+				return applyMethodServer
+			case "false":
+				return applyMethodClient
+			}
+			return applyMethodAuto
+		},
+		set: func(method string) {
+			switch method {
+			case applyMethodServer:
+				*serverSide = "true"
+			case applyMethodClient:
+				*serverSide = "false"
+			default:
+				*serverSide = "auto"
+			}
+		},
+	}
+	bindApplyMethodFlag(cmd, v)
+}
+
+func bindApplyMethodFlag(cmd *cobra.Command, v *applyMethodValue) {
+	cmd.Flags().Var(v, "apply-method", fmt.Sprintf("the method used to apply resources to the cluster. Must be one of: %s. Equivalent to --server-side", strings.Join(v.allowed, ", ")))
+	cmd.MarkFlagsMutuallyExclusive("apply-method", "server-side")
+
+	err := cmd.RegisterFlagCompletionFunc("apply-method", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return v.allowed, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type applyMethodValue struct {
+	allowed []string
+	get     func() string
+	set     func(string)
+}
+
+func (a *applyMethodValue) String() string {
+	return a.get()
+}
+
+func (a *applyMethodValue) Type() string {
+	return "method"
+}
+
+func (a *applyMethodValue) Set(s string) error {
+	if !slices.Contains(a.allowed, s) {
+		return fmt.Errorf("invalid apply method %q, must be one of: %s", s, strings.Join(a.allowed, ", "))
+	}
+	a.set(s)
+	return nil
+}
+
+// addCacheModeFlags adds the --use-cache and --no-cache flags controlling how
+// charts located by the command are read from the content cache.
+func addCacheModeFlags(cmd *cobra.Command, mode *downloader.CacheMode) {
+	f := cmd.Flags()
+	f.Var(&cacheModeValue{mode: mode, value: downloader.CachePreferred}, "use-cache", "reuse a chart previously downloaded for the same reference and version without contacting the repository")
+	f.Lookup("use-cache").NoOptDefVal = "true"
+	f.Var(&cacheModeValue{mode: mode, value: downloader.CacheBypass}, "no-cache", "always download the chart, even if it is in the content cache")
+	f.Lookup("no-cache").NoOptDefVal = "true"
+	cmd.MarkFlagsMutuallyExclusive("use-cache", "no-cache")
+}
+
+// cacheModeValue is a boolean flag that selects a downloader.CacheMode when set.
+type cacheModeValue struct {
+	mode  *downloader.CacheMode
+	value downloader.CacheMode
+}
+
+func (c *cacheModeValue) String() string {
+	return strconv.FormatBool(*c.mode == c.value)
+}
+
+func (c *cacheModeValue) Type() string {
+	return "bool"
+}
+
+func (c *cacheModeValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if v {
+		*c.mode = c.value
+	} else if *c.mode == c.value {
+		*c.mode = downloader.CacheDigest
+	}
+	return nil
+}
+
+// addPreflightFlag adds the --preflight flag, which checks that the resources
+// requested by a release fit into the cluster before it is deployed.
+func addPreflightFlag(cmd *cobra.Command, mode *action.PreflightMode) {
+	*mode = action.PreflightNone
+	cmd.Flags().Var((*preflightValue)(mode), "preflight", "check that the CPU and memory requests and limits added by the release fit into the namespace ResourceQuotas and the cluster nodes. Allowed values: none, warn, strict")
+	err := cmd.RegisterFlagCompletionFunc("preflight", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{
+			string(action.PreflightNone) + "\tdo not check the release resources",
+			string(action.PreflightWarn) + "\twarn when the release does not fit",
+			string(action.PreflightStrict) + "\tfail when the release does not fit",
+		}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type preflightValue action.PreflightMode
+
+func (p *preflightValue) String() string {
+	return string(*p)
+}
+
+func (p *preflightValue) Type() string {
+	return "string"
+}
+
+func (p *preflightValue) Set(s string) error {
+	switch mode := action.PreflightMode(s); mode {
+	case action.PreflightNone, action.PreflightWarn, action.PreflightStrict:
+		*p = preflightValue(mode)
+		return nil
+	}
+	return fmt.Errorf("invalid preflight mode %q, must be one of: none, warn, strict", s)
+}
+
+// addReuseValuesStrategyFlag adds the --reuse-values-strategy flag, which
+// selects how the values of the previous release are merged with new values.
+func addReuseValuesStrategyFlag(cmd *cobra.Command, strategy *action.ReuseValuesStrategy) {
+	*strategy = action.ReuseValuesCoalesce
+	cmd.Flags().Var((*reuseValuesStrategyValue)(strategy), "reuse-values-strategy", "how the last release's values are merged with overrides when using --reuse-values or --reset-then-reuse-values. Allowed values: coalesce, deep, shallow")
+	err := cmd.RegisterFlagCompletionFunc("reuse-values-strategy", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{
+			string(action.ReuseValuesCoalesce) + "\tmerge nested maps, null removes a key",
+			string(action.ReuseValuesDeep) + "\tmerge nested maps, null overrides chart defaults",
+			string(action.ReuseValuesShallow) + "\treplace top-level keys",
+		}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type reuseValuesStrategyValue action.ReuseValuesStrategy
+
+func (r *reuseValuesStrategyValue) String() string {
+	return string(*r)
+}
+
+func (r *reuseValuesStrategyValue) Type() string {
+	return "string"
+}
+
+func (r *reuseValuesStrategyValue) Set(s string) error {
+	switch strategy := action.ReuseValuesStrategy(s); strategy {
+	case action.ReuseValuesCoalesce, action.ReuseValuesDeep, action.ReuseValuesShallow:
+		*r = reuseValuesStrategyValue(strategy)
+		return nil
+	}
+	return fmt.Errorf("invalid reuse values strategy %q, must be one of: coalesce, deep, shallow", s)
+}
+
+// addRolloutFlags adds the --rollout-strategy and --rollout-steps flags, which
+// control how the workloads of a release are updated.
+func addRolloutFlags(cmd *cobra.Command, strategy *action.RolloutStrategy, steps *[]int) {
+	f := cmd.Flags()
+	*strategy = action.RolloutStrategyAll
+	f.Var((*rolloutStrategyValue)(strategy), "rollout-strategy", "how the resources of the release are applied. Allowed values: all, canary")
+	f.IntSliceVar(steps, "rollout-steps", action.DefaultRolloutSteps, "percentages of replicas updated at each step of a canary rollout")
+	err := cmd.RegisterFlagCompletionFunc("rollout-strategy", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{
+			string(action.RolloutStrategyAll) + "\tapply all resources at once",
+			string(action.RolloutStrategyCanary) + "\tupdate workloads gradually, waiting for them between steps",
+		}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type rolloutStrategyValue action.RolloutStrategy
+
+func (r *rolloutStrategyValue) String() string {
+	return string(*r)
+}
+
+func (r *rolloutStrategyValue) Type() string {
+	return "string"
+}
+
+func (r *rolloutStrategyValue) Set(s string) error {
+	switch strategy := action.RolloutStrategy(s); strategy {
+	case action.RolloutStrategyAll, action.RolloutStrategyCanary:
+		*r = rolloutStrategyValue(strategy)
+		return nil
+	}
+	return fmt.Errorf("invalid rollout strategy %q, must be one of: all, canary", s)
+}
+
+// addAPIDeprecationCheckFlag adds the --api-deprecation-check flag, which
+// controls the check for deprecated and removed Kubernetes APIs.
+func addAPIDeprecationCheckFlag(cmd *cobra.Command, check *action.APIDeprecationCheck) {
+	*check = action.APIDeprecationCheckWarn
+	cmd.Flags().Var((*apiDeprecationCheckValue)(check), "api-deprecation-check", "check the rendered manifests for Kubernetes APIs deprecated or removed in the cluster version. Allowed values: off, warn, error")
+	err := cmd.RegisterFlagCompletionFunc("api-deprecation-check", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{
+			string(action.APIDeprecationCheckOff) + "\tskip the check",
+			string(action.APIDeprecationCheckWarn) + "\twarn about deprecated and removed APIs",
+			string(action.APIDeprecationCheckError) + "\tfail when an API has been removed",
+		}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type apiDeprecationCheckValue action.APIDeprecationCheck
+
+func (a *apiDeprecationCheckValue) String() string {
+	return string(*a)
+}
+
+func (a *apiDeprecationCheckValue) Type() string {
+	return "string"
+}
+
+func (a *apiDeprecationCheckValue) Set(s string) error {
+	switch check := action.APIDeprecationCheck(s); check {
+	case action.APIDeprecationCheckOff, action.APIDeprecationCheckWarn, action.APIDeprecationCheckError:
+		*a = apiDeprecationCheckValue(check)
+		return nil
+	}
+	return fmt.Errorf("invalid API deprecation check %q, must be one of: off, warn, error", s)
+}
+
+// addOutputDirLayoutFlag adds the --output-dir-layout flag, which controls how
+// the manifests written to the output directory are split into files.
+func addOutputDirLayoutFlag(cmd *cobra.Command, layout *action.OutputDirLayout) {
+	*layout = action.OutputDirLayoutSource
+	cmd.Flags().Var((*outputDirLayoutValue)(layout), "output-dir-layout", "how the manifests written to output-dir are split into files. Allowed values: source, name, kind, flat")
+	err := cmd.RegisterFlagCompletionFunc("output-dir-layout", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{
+			string(action.OutputDirLayoutSource) + "\tone file per template",
+			string(action.OutputDirLayoutName) + "\tone file per resource, named <kind>-<name>.yaml, in the directory of its chart",
+			string(action.OutputDirLayoutKind) + "\tone file per resource, named <name>.yaml, in a directory per kind",
+			string(action.OutputDirLayoutFlat) + "\tone file per resource, named <kind>-<name>.yaml, in output-dir",
+		}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+type outputDirLayoutValue action.OutputDirLayout
+
+func (o *outputDirLayoutValue) String() string {
+	return string(*o)
+}
+
+func (o *outputDirLayoutValue) Type() string {
+	return "string"
+}
+
+func (o *outputDirLayoutValue) Set(s string) error {
+	switch layout := action.OutputDirLayout(s); layout {
+	case action.OutputDirLayoutSource, action.OutputDirLayoutName, action.OutputDirLayoutKind, action.OutputDirLayoutFlat:
+		*o = outputDirLayoutValue(layout)
+		return nil
+	}
+	return fmt.Errorf("invalid output directory layout %q, must be one of: source, name, kind, flat", s)
+}
+
+// regexpSliceValue is a flag value compiling each value to a regular
+// expression.
+type regexpSliceValue struct {
+	regexps *[]*regexp.Regexp
+}
+
+func (r *regexpSliceValue) String() string {
+	patterns := make([]string, 0, len(*r.regexps))
+	for _, re := range *r.regexps {
+		patterns = append(patterns, re.String())
+	}
+	return "[" + strings.Join(patterns, ",") + "]"
+}
+
+func (r *regexpSliceValue) Type() string {
+	return "stringArray"
+}
+
+func (r *regexpSliceValue) Set(s string) error {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return fmt.Errorf("invalid regular expression %q: %w", s, err)
+	}
+	*r.regexps = append(*r.regexps, re)
+	return nil
+}
+
+// addLockTimeoutFlag adds the --lock-timeout flag, which sets how long to wait
+// for the lock of a release held by another operation.
+func addLockTimeoutFlag(f *pflag.FlagSet, timeout *time.Duration) {
+	f.DurationVar(timeout, "lock-timeout", 0, "time to wait for another operation on the release to finish. By default, fail immediately if the release is locked")
+}
+
+// addTimeoutPerResourceFlag adds the --timeout-per-resource flag, which sets
+// the timeout for waiting on resources of specific kinds.
+func addTimeoutPerResourceFlag(cmd *cobra.Command, timeouts *map[string]time.Duration) {
+	cmd.Flags().Var((*kindTimeoutsValue)(timeouts), "timeout-per-resource", "time to wait for resources of a kind to become ready, overriding --timeout for that kind. Of the form KIND=DURATION, e.g. StatefulSet=20m,ConfigMap=30s. Can be specified multiple times")
+}
+
+type kindTimeoutsValue map[string]time.Duration
+
+func (k *kindTimeoutsValue) String() string {
+	kinds := make([]string, 0, len(*k))
+	for kind, timeout := range *k {
+		kinds = append(kinds, kind+"="+timeout.String())
+	}
+	sort.Strings(kinds)
+	return "[" + strings.Join(kinds, ",") + "]"
+}
+
+func (k *kindTimeoutsValue) Type() string {
+	return "kind=duration"
+}
+
+func (k *kindTimeoutsValue) Set(s string) error {
+	if *k == nil {
+		*k = make(kindTimeoutsValue)
+	}
+	for pair := range strings.SplitSeq(s, ",") {
+		kind, value, ok := strings.Cut(pair, "=")
+		if !ok || kind == "" {
+			return fmt.Errorf("invalid timeout %q, must be of the form KIND=DURATION", pair)
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid timeout for kind %s: %w", kind, err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("invalid timeout for kind %s: must be positive", kind)
+		}
+		(*k)[kind] = timeout
+	}
+	return nil
+}
+
+// addHistoryRetentionFlags adds the --history-max-per-status and
+// --history-max-age flags, which set the retention policy applied to the
+// history of a release when a revision is created.
+func addHistoryRetentionFlags(f *pflag.FlagSet, policy *storage.RetentionPolicy) {
+	f.Var((*statusCountsValue)(&policy.KeepPerStatus), "history-max-per-status", "limit the number of revisions of a status saved per release, applied after --history-max. Of the form STATUS=COUNT, e.g. failed=2,superseded=5. Can be specified multiple times")
+	f.Var((*ageValue)(&policy.OlderThan), "history-max-age", "delete revisions older than this, e.g. 30d or 12h. With --history-max-per-status, only the revisions exceeding those limits are deleted")
+}
+
+// releaseStatuses are the statuses accepted by statusCountsValue.
+var releaseStatuses = []common.Status{
+	common.StatusUnknown,
+	common.StatusDeployed,
+	common.StatusUninstalled,
+	common.StatusSuperseded,
+	common.StatusFailed,
+	common.StatusUninstalling,
+	common.StatusPendingInstall,
+	common.StatusPendingUpgrade,
+	common.StatusPendingRollback,
+}
+
+type statusCountsValue map[common.Status]int
+
+func (s *statusCountsValue) String() string {
+	statuses := make([]string, 0, len(*s))
+	for status, n := range *s {
+		statuses = append(statuses, status.String()+"="+strconv.Itoa(n))
+	}
+	sort.Strings(statuses)
+	return "[" + strings.Join(statuses, ",") + "]"
+}
+
+func (s *statusCountsValue) Type() string {
+	return "status=count"
+}
+
+func (s *statusCountsValue) Set(v string) error {
+	if *s == nil {
+		*s = make(statusCountsValue)
+	}
+	for pair := range strings.SplitSeq(v, ",") {
+		status, value, ok := strings.Cut(pair, "=")
+		if !ok || !slices.Contains(releaseStatuses, common.Status(status)) {
+			return fmt.Errorf("invalid revision count %q, must be of the form STATUS=COUNT with a release status", pair)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid revision count for status %s: must be a non-negative integer", status)
+		}
+		(*s)[common.Status(status)] = n
+	}
+	return nil
+}
+
+// ageValue is a duration that can also be given in days, e.g. 30d.
+type ageValue time.Duration
+
+func (a *ageValue) String() string {
+	if *a == 0 {
+		return "0s"
+	}
+	return time.Duration(*a).String()
+}
+
+func (a *ageValue) Type() string {
+	return "duration"
+}
+
+func (a *ageValue) Set(s string) error {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid age %q: must be a non-negative number of days or a duration", s)
+		}
+		*a = ageValue(time.Duration(n) * 24 * time.Hour)
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return fmt.Errorf("invalid age %q: must be a non-negative number of days or a duration", s)
+	}
+	*a = ageValue(d)
+	return nil
+}
+
+// dateValue is a time given either as a date, e.g. 2024-01-01, or in RFC 3339
+// format.
+type dateValue time.Time
+
+func (d *dateValue) String() string {
+	if time.Time(*d).IsZero() {
+		return ""
+	}
+	return time.Time(*d).Format(time.RFC3339)
+}
+
+func (d *dateValue) Type() string {
+	return "date"
+}
+
+func (d *dateValue) Set(s string) error {
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			*d = dateValue(t)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid date %q: must be formatted as 2006-01-02 or 2006-01-02T15:04:05Z07:00", s)
+}
+
 // bindOutputFlag will add the output flag to the given command and bind the
 // value to the given format pointer
 func bindOutputFlag(cmd *cobra.Command, varRef *output.Format) {
@@ -167,16 +732,84 @@ func (o *outputValue) Set(s string) error {
 
 // TODO there is probably a better way to pass cobra settings than as a param
 func bindPostRenderFlag(cmd *cobra.Command, varRef *postrenderer.PostRenderer, settings *cli.EnvSettings) {
-	p := &postRendererOptions{varRef, "", []string{}, settings}
-	cmd.Flags().Var(&postRendererString{p}, postRenderFlag, "the name of a postrenderer type plugin to be used for post rendering. If it exists, the plugin will be used")
-	cmd.Flags().Var(&postRendererArgsSlice{p}, postRenderArgsFlag, "an argument to the post-renderer (can specify multiple)")
+	p := &postRendererOptions{renderer: varRef, settings: settings}
+	cmd.Flags().Var(&postRendererString{p}, postRenderFlag, "the name of a postrenderer type plugin to be used for post rendering. If it exists, the plugin will be used. Otherwise \"kustomize\" selects the built-in kustomize post-renderer, which takes the kustomization directory as argument. Can be specified multiple times to chain post-renderers, which run in order")
+	cmd.Flags().Var(&postRendererArgsSlice{p}, postRenderArgsFlag, "an argument to the preceding post-renderer (can specify multiple)")
+	cmd.Flags().Var(&postRendererWasm{p}, postRenderWasmFlag, "the path of a Wasm module to be run as a sandboxed post-renderer. Can be specified multiple times, and combined with --post-renderer to chain post-renderers")
 }
 
 type postRendererOptions struct {
-	renderer   *postrenderer.PostRenderer
-	pluginName string
-	args       []string
-	settings   *cli.EnvSettings
+	renderer  *postrenderer.PostRenderer
+	renderers []postRendererSpec
+	// args holds the arguments of each post-renderer. Arguments given before
+	// the first post-renderer belong to the first post-renderer.
+	args     [][]string
+	settings *cli.EnvSettings
+}
+
+// current returns the index of the post-renderer the arguments are added to.
+func (o *postRendererOptions) current() int {
+	n := max(len(o.renderers)-1, 0)
+	for len(o.args) <= n {
+		o.args = append(o.args, []string{})
+	}
+	return n
+}
+
+// build sets the renderer to the chain of post-renderers.
+func (o *postRendererOptions) build() error {
+	var chain postrenderer.Chain
+	for n, spec := range o.renderers {
+		var pr postrenderer.PostRenderer
+		var err error
+		if spec.wasm {
+			pr, err = postrenderer.NewPostRendererWasm(spec.name, o.args[n]...)
+		} else {
+			pr, err = postrenderer.NewPostRenderer(o.settings, spec.name, o.args[n]...)
+		}
+		if err != nil {
+			return err
+		}
+		chain = append(chain, pr)
+	}
+	switch len(chain) {
+	case 0:
+	case 1:
+		*o.renderer = chain[0]
+	default:
+		*o.renderer = chain
+	}
+	return nil
+}
+
+// add adds a post-renderer to the end of the chain.
+func (o *postRendererOptions) add(name string, wasm bool) error {
+	o.renderers = append(o.renderers, postRendererSpec{name: name, wasm: wasm})
+	o.current()
+	if err := o.build(); err != nil {
+		o.renderers = o.renderers[:len(o.renderers)-1]
+		return err
+	}
+	return nil
+}
+
+// names returns the names of the plugins, or the paths of the Wasm modules, of
+// the chain.
+func (o *postRendererOptions) names(wasm bool) string {
+	var names []string
+	for _, spec := range o.renderers {
+		if spec.wasm == wasm {
+			names = append(names, spec.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// postRendererSpec is a post-renderer of the chain: a post-renderer plugin, or
+// a Wasm module.
+type postRendererSpec struct {
+	name string
+	wasm bool
 }
 
 type postRendererString struct {
@@ -184,7 +817,7 @@ type postRendererString struct {
 }
 
 func (p *postRendererString) String() string {
-	return p.options.pluginName
+	return p.options.names(false)
 }
 
 func (p *postRendererString) Type() string {
@@ -195,16 +828,26 @@ func (p *postRendererString) Set(val string) error {
 	if val == "" {
 		return nil
 	}
-	if p.options.pluginName != "" {
-		return errors.New("cannot specify --post-renderer flag more than once")
-	}
-	p.options.pluginName = val
-	pr, err := postrenderer.NewPostRendererPlugin(p.options.settings, p.options.pluginName, p.options.args...)
-	if err != nil {
-		return err
+	return p.options.add(val, false)
+}
+
+type postRendererWasm struct {
+	options *postRendererOptions
+}
+
+func (p *postRendererWasm) String() string {
+	return p.options.names(true)
+}
+
+func (p *postRendererWasm) Type() string {
+	return "postRendererWasm"
+}
+
+func (p *postRendererWasm) Set(val string) error {
+	if val == "" {
+		return nil
 	}
-	*p.options.renderer = pr
-	return nil
+	return p.options.add(val, true)
 }
 
 type postRendererArgsSlice struct {
@@ -212,7 +855,7 @@ type postRendererArgsSlice struct {
 }
 
 func (p *postRendererArgsSlice) String() string {
-	return "[" + strings.Join(p.options.args, ",") + "]"
+	return "[" + strings.Join(p.GetSlice(), ",") + "]"
 }
 
 func (p *postRendererArgsSlice) Type() string {
@@ -221,32 +864,26 @@ func (p *postRendererArgsSlice) Type() string {
 
 func (p *postRendererArgsSlice) Set(val string) error {
 	// a post-renderer defined by a user may accept empty arguments
-	p.options.args = append(p.options.args, val)
+	n := p.options.current()
+	p.options.args[n] = append(p.options.args[n], val)
 
-	if p.options.pluginName == "" {
-		return nil
-	}
-	// overwrite if already create PostRenderer by `post-renderer` flags
-	pr, err := postrenderer.NewPostRendererPlugin(p.options.settings, p.options.pluginName, p.options.args...)
-	if err != nil {
-		return err
-	}
-	*p.options.renderer = pr
-	return nil
+	// rebuild the post-renderers created by `post-renderer` flags
+	return p.options.build()
 }
 
 func (p *postRendererArgsSlice) Append(val string) error {
-	p.options.args = append(p.options.args, val)
+	n := p.options.current()
+	p.options.args[n] = append(p.options.args[n], val)
 	return nil
 }
 
 func (p *postRendererArgsSlice) Replace(val []string) error {
-	p.options.args = val
+	p.options.args[p.options.current()] = val
 	return nil
 }
 
 func (p *postRendererArgsSlice) GetSlice() []string {
-	return p.options.args
+	return p.options.args[p.options.current()]
 }
 
 func compVersionFlag(chartRef string, _ string) ([]string, cobra.ShellCompDirective) {
@@ -21,12 +21,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 
 	"helm.sh/helm/v4/pkg/action"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/postrenderer"
 	"helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage"
 )
 
 func outputFlagCompletionTest(t *testing.T, cmdName string) {
@@ -99,25 +103,245 @@ func outputFlagCompletionTest(t *testing.T, cmdName string) {
 	runTestCmd(t, tests)
 }
 
-func TestPostRendererFlagSetOnce(t *testing.T) {
+func TestPostRendererFlagChain(t *testing.T) {
 	cfg := action.Configuration{}
 	client := action.NewInstall(&cfg)
 	settings.PluginsDirectory = "testdata/helmhome/helm/plugins"
-	str := postRendererString{
-		options: &postRendererOptions{
-			renderer: &client.PostRenderer,
-			settings: settings,
+	options := &postRendererOptions{
+		renderer: &client.PostRenderer,
+		settings: settings,
+	}
+	str := postRendererString{options}
+	args := postRendererArgsSlice{options}
+
+	// Arguments given before the first post-renderer belong to it
+	require.NoError(t, args.Set("ARG1"))
+	require.NoError(t, str.Set("postrenderer-v1"))
+	require.NotNil(t, client.PostRenderer)
+	_, isChain := client.PostRenderer.(postrenderer.Chain)
+	require.False(t, isChain)
+
+	// Setting the plugin name again chains the post-renderers
+	require.NoError(t, str.Set("postrenderer-v1"))
+	require.NoError(t, args.Set("ARG2"))
+	require.NoError(t, str.Set(postrenderer.KustomizeName))
+	require.NoError(t, args.Set("testdata/kustomize"))
+
+	chain, ok := client.PostRenderer.(postrenderer.Chain)
+	require.True(t, ok)
+	require.Len(t, chain, 3)
+	require.Equal(t, "postrenderer-v1,postrenderer-v1,kustomize", str.String())
+	require.Equal(t, [][]string{{"ARG1"}, {"ARG2"}, {"testdata/kustomize"}}, options.args)
+
+	// An unknown post-renderer is an error
+	require.Error(t, str.Set("cat"))
+
+	// So is a missing Wasm module
+	wasm := postRendererWasm{options}
+	require.Error(t, wasm.Set("testdata/missing.wasm"))
+}
+
+func TestApplyMethodFlag(t *testing.T) {
+	newCmd := func() (*cobra.Command, *bool) {
+		serverSide := true
+		cmd := &cobra.Command{Use: "test"}
+		cmd.Flags().BoolVar(&serverSide, "server-side", true, "")
+		addApplyMethodFlag(cmd, &serverSide)
+		return cmd, &serverSide
+	}
+
+	cmd, serverSide := newCmd()
+	require.Equal(t, "server", cmd.Flag("apply-method").Value.String())
+	require.NoError(t, cmd.ParseFlags([]string{"--apply-method", "client"}))
+	require.False(t, *serverSide)
+
+	cmd, serverSide = newCmd()
+	require.NoError(t, cmd.ParseFlags([]string{"--apply-method=server"}))
+	require.True(t, *serverSide)
+
+	cmd, _ = newCmd()
+	require.Error(t, cmd.ParseFlags([]string{"--apply-method", "auto"}))
+}
+
+func TestApplyMethodFlagWithAuto(t *testing.T) {
+	for method, expect := range map[string]string{"client": "false", "server": "true", "auto": "auto"} {
+		t.Run(method, func(t *testing.T) {
+			serverSide := "auto"
+			cmd := &cobra.Command{Use: "test"}
+			cmd.Flags().StringVar(&serverSide, "server-side", "auto", "")
+			addApplyMethodFlagWithAuto(cmd, &serverSide)
+
+			require.NoError(t, cmd.ParseFlags([]string{"--apply-method", method}))
+			require.Equal(t, expect, serverSide)
+			require.Equal(t, method, cmd.Flag("apply-method").Value.String())
+		})
+	}
+}
+
+func TestWaitForOptions(t *testing.T) {
+	const expr = `Certificate:.status.conditions[?(@.type=="Ready")].status==True`
+	tests := []struct {
+		name         string
+		args         []string
+		exprs        []string
+		wantStrategy kube.WaitStrategy
+		wantOpts     int
+		wantErr      bool
+	}{
+		{
+			name:         "no expressions",
+			wantStrategy: kube.HookOnlyStrategy,
+		},
+		{
+			name:         "implies watcher",
+			exprs:        []string{expr},
+			wantStrategy: kube.StatusWatcherStrategy,
+			wantOpts:     1,
+		},
+		{
+			name:         "keeps explicit strategy",
+			args:         []string{"--wait=hookOnly"},
+			exprs:        []string{expr},
+			wantStrategy: kube.HookOnlyStrategy,
+			wantOpts:     1,
+		},
+		{
+			name:    "legacy strategy",
+			args:    []string{"--wait=legacy"},
+			exprs:   []string{expr},
+			wantErr: true,
+		},
+		{
+			name:    "invalid expression",
+			exprs:   []string{"Certificate"},
+			wantErr: true,
 		},
 	}
-	// Set the plugin name once
-	err := str.Set("postrenderer-v1")
-	require.NoError(t, err)
 
-	// Set the plugin name again to the same value is not ok
-	err = str.Set("postrenderer-v1")
-	require.Error(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ws kube.WaitStrategy
+			cmd := &cobra.Command{Use: "test"}
+			AddWaitFlag(cmd, &ws)
+			require.NoError(t, cmd.ParseFlags(tt.args))
 
-	// Set the plugin name again to a different value is not ok
-	err = str.Set("cat")
-	require.Error(t, err)
+			opts, err := waitForOptions(cmd, tt.exprs, &ws)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, opts, tt.wantOpts)
+			require.Equal(t, tt.wantStrategy, ws)
+		})
+	}
+}
+
+func TestTimeoutPerResourceFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{
+			name: "not set",
+		},
+		{
+			name: "comma separated",
+			args: []string{"--timeout-per-resource=StatefulSet=20m,ConfigMap=30s"},
+			want: map[string]time.Duration{"StatefulSet": 20 * time.Minute, "ConfigMap": 30 * time.Second},
+		},
+		{
+			name: "repeated",
+			args: []string{"--timeout-per-resource=StatefulSet=20m", "--timeout-per-resource=StatefulSet=5m"},
+			want: map[string]time.Duration{"StatefulSet": 5 * time.Minute},
+		},
+		{
+			name:    "missing duration",
+			args:    []string{"--timeout-per-resource=StatefulSet"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid duration",
+			args:    []string{"--timeout-per-resource=StatefulSet=soon"},
+			wantErr: true,
+		},
+		{
+			name:    "zero duration",
+			args:    []string{"--timeout-per-resource=StatefulSet=0s"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var timeouts map[string]time.Duration
+			cmd := &cobra.Command{Use: "test"}
+			addTimeoutPerResourceFlag(cmd, &timeouts)
+
+			err := cmd.ParseFlags(tt.args)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, timeouts)
+		})
+	}
+}
+
+func TestHistoryRetentionFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    storage.RetentionPolicy
+		wantErr bool
+	}{
+		{
+			name: "not set",
+		},
+		{
+			name: "per status and days",
+			args: []string{"--history-max-per-status=failed=2,superseded=5", "--history-max-age=30d"},
+			want: storage.RetentionPolicy{
+				KeepPerStatus: map[common.Status]int{common.StatusFailed: 2, common.StatusSuperseded: 5},
+				OlderThan:     30 * 24 * time.Hour,
+			},
+		},
+		{
+			name: "duration",
+			args: []string{"--history-max-age=12h"},
+			want: storage.RetentionPolicy{OlderThan: 12 * time.Hour},
+		},
+		{
+			name:    "unknown status",
+			args:    []string{"--history-max-per-status=broken=1"},
+			wantErr: true,
+		},
+		{
+			name:    "negative count",
+			args:    []string{"--history-max-per-status=failed=-1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid age",
+			args:    []string{"--history-max-age=a month"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var policy storage.RetentionPolicy
+			cmd := &cobra.Command{Use: "test"}
+			addHistoryRetentionFlags(cmd.Flags(), &policy)
+
+			err := cmd.ParseFlags(tt.args)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, policy)
+		})
+	}
 }
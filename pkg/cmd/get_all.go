@@ -17,41 +17,70 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"io"
 	"log"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/release"
 )
 
 var getAllHelp = `
 This command prints a human readable collection of information about the
 notes, hooks, supplied values, and generated manifest file of the given release.
+
+With '--output archive', the release is exported as a gzipped tarball to the
+file given as second argument instead. The tarball holds the release record,
+the chart archive, the values, the manifest, the hooks, the notes and the
+metadata of the release. 'helm release import' restores the release from it,
+for example into the release storage of another cluster:
+
+    $ helm get all my-release --output archive my-release.tgz
 `
 
+// getAllOutputArchive is the output format of 'helm get all' exporting the
+// release as a tarball.
+const getAllOutputArchive = "archive"
+
 func newGetAllCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
-	var template string
+	var template, outfmt string
 	client := action.NewGet(cfg)
 
 	cmd := &cobra.Command{
-		Use:   "all RELEASE_NAME",
+		Use:   "all RELEASE_NAME [ARCHIVE]",
 		Short: "download all information for a named release",
 		Long:  getAllHelp,
-		Args:  require.ExactArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if outfmt == getAllOutputArchive {
+				return require.ExactArgs(2)(cmd, args)
+			}
+			return require.ExactArgs(1)(cmd, args)
+		},
 		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 && outfmt == getAllOutputArchive {
+				return nil, cobra.ShellCompDirectiveDefault
+			}
 			if len(args) != 0 {
 				return noMoreArgsComp()
 			}
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if outfmt != "table" && outfmt != getAllOutputArchive {
+				return fmt.Errorf("invalid output format %q, must be one of: table, archive", outfmt)
+			}
 			res, err := client.Run(args[0])
 			if err != nil {
 				return err
 			}
+			if outfmt == getAllOutputArchive {
+				return writeReleaseArchive(out, res, args[1])
+			}
 			if template != "" {
 				data := map[string]any{
 					"Release": res,
@@ -81,6 +110,34 @@ func newGetAllCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	f.StringVar(&template, "template", "", "go template for formatting the output, eg: {{.Release.Name}}")
+	f.StringVarP(&outfmt, "output", "o", "table", "prints the output in the specified format. Allowed values: table, archive")
+	err = cmd.RegisterFlagCompletionFunc("output", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", getAllOutputArchive}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	return cmd
 }
+
+// writeReleaseArchive exports the release as a tarball to the file at path.
+func writeReleaseArchive(out io.Writer, res release.Releaser, path string) error {
+	rel, err := releaserToV1Release(res)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := action.WriteReleaseArchive(f, rel); err != nil {
+		f.Close()
+		return fmt.Errorf("exporting release: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Exported release %q revision %d to %s\n", rel.Name, rel.Version, path)
+	return nil
+}
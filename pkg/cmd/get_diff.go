@@ -0,0 +1,82 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+var getDiffHelp = `
+This command compares two revisions of a release.
+
+It prints a unified diff of the manifests, the user supplied values and the
+notes of the revisions. Manifests are compared resource by resource, and the
+values of Secrets are redacted.
+
+    $ helm get diff my-release 3 4
+`
+
+func newGetDiffCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewGetDiff(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "diff RELEASE_NAME REVISION1 REVISION2",
+		Short: "show the differences between two revisions of a named release",
+		Long:  getDiffHelp,
+		Args:  require.ExactArgs(3),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return compListReleases(toComplete, args, cfg)
+			case 1, 2:
+				return compListRevisions(toComplete, cfg, args[0])
+			}
+			return noMoreArgsComp()
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			revisions := make([]int, 2)
+			for i, arg := range args[1:] {
+				rev, err := strconv.Atoi(arg)
+				if err != nil {
+					return fmt.Errorf("could not convert revision to a number: %w", err)
+				}
+				revisions[i] = rev
+			}
+			client.NoColor = settings.ShouldDisableColor()
+
+			changed, err := client.Run(out, args[0], revisions[0], revisions[1])
+			if err != nil {
+				return err
+			}
+			if !changed {
+				fmt.Fprintf(out, "No differences between revisions %d and %d\n", revisions[0], revisions[1])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&client.Context, "diff-context", client.Context, "number of unchanged lines to show around each change in the diff")
+
+	return cmd
+}
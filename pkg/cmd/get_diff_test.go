@@ -0,0 +1,98 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/release/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestGetDiffCmd(t *testing.T) {
+	rel1 := release.Mock(&release.MockReleaseOptions{Name: "the-limerick", Version: 1, Status: common.StatusSuperseded})
+	rel1.Manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: verse\ndata:\n  line: There was a young lady named Bright\n"
+	rel1.Config = map[string]any{"speed": "light"}
+	rel2 := release.Mock(&release.MockReleaseOptions{Name: "the-limerick", Version: 2})
+	rel2.Manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: verse\ndata:\n  line: Whose speed was far faster than light\n"
+	rel2.Config = map[string]any{"speed": "faster than light"}
+	rels := []*release.Release{rel1, rel2}
+
+	tests := []cmdTestCase{{
+		name:   "get diff between two revisions",
+		cmd:    "get diff the-limerick 1 2",
+		golden: "output/get-diff.txt",
+		rels:   rels,
+	}, {
+		name:   "get diff of a revision with itself",
+		cmd:    "get diff the-limerick 2 2",
+		golden: "output/get-diff-none.txt",
+		rels:   rels,
+	}, {
+		name:      "get diff with a missing revision",
+		cmd:       "get diff the-limerick 1 3",
+		golden:    "output/get-diff-missing-revision.txt",
+		rels:      rels,
+		wantError: true,
+	}, {
+		name:      "get diff with an invalid revision",
+		cmd:       "get diff the-limerick 1 two",
+		golden:    "output/get-diff-invalid-revision.txt",
+		rels:      rels,
+		wantError: true,
+	}, {
+		name:      "get diff without revisions",
+		cmd:       "get diff the-limerick",
+		golden:    "output/get-diff-no-args.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestGetDiffCompletion(t *testing.T) {
+	releases := []*release.Release{
+		release.Mock(&release.MockReleaseOptions{Name: "musketeers", Version: 11, Status: common.StatusDeployed}),
+		release.Mock(&release.MockReleaseOptions{Name: "musketeers", Version: 10, Status: common.StatusSuperseded}),
+		release.Mock(&release.MockReleaseOptions{Name: "musketeers", Version: 9, Status: common.StatusSuperseded}),
+		release.Mock(&release.MockReleaseOptions{Name: "musketeers", Version: 8, Status: common.StatusSuperseded}),
+	}
+
+	tests := []cmdTestCase{{
+		name:   "completion for revision parameter",
+		cmd:    "__complete get diff musketeers ''",
+		rels:   releases,
+		golden: "output/revision-comp.txt",
+	}, {
+		name:   "completion for second revision parameter",
+		cmd:    "__complete get diff musketeers 8 ''",
+		rels:   releases,
+		golden: "output/revision-comp.txt",
+	}, {
+		name:   "completion with too many args",
+		cmd:    "__complete get diff musketeers 8 11 ''",
+		rels:   releases,
+		golden: "output/rollback-wrong-args-comp.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestGetDiffFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "get diff", false)
+	checkFileCompletion(t, "get diff myrelease", false)
+	checkFileCompletion(t, "get diff myrelease 1", false)
+	checkFileCompletion(t, "get diff myrelease 1 2", false)
+}
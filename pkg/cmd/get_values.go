@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -24,21 +25,30 @@ import (
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/common/util"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
 )
 
 var getValuesHelp = `
 This command downloads a values file for a given release.
+
+With '--trace', each value is annotated with its source: the values file or
+flag that set it, 'reuse-values' for the values reused from the previous
+release, or, with '--all', the chart or parent chart whose default values it
+comes from.
 `
 
 type valuesWriter struct {
 	vals      map[string]any
 	allValues bool
+	// trace, if set, annotates the values with their sources
+	trace util.ValuesTrace
 }
 
 func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var outfmt output.Format
+	var trace bool
 	client := action.NewGetValues(cfg)
 
 	cmd := &cobra.Command{
@@ -53,11 +63,21 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if trace {
+				if outfmt == output.JSON {
+					return errors.New("--trace cannot be used with JSON output")
+				}
+				vals, valuesTrace, err := client.RunWithTrace(args[0])
+				if err != nil {
+					return err
+				}
+				return outfmt.Write(out, &valuesWriter{vals, client.AllValues, valuesTrace})
+			}
 			vals, err := client.Run(args[0])
 			if err != nil {
 				return err
 			}
-			return outfmt.Write(out, &valuesWriter{vals, client.AllValues})
+			return outfmt.Write(out, &valuesWriter{vals: vals, allValues: client.AllValues})
 		},
 	}
 
@@ -75,6 +95,7 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	f.BoolVarP(&client.AllValues, "all", "a", false, "dump all (computed) values")
+	f.BoolVar(&trace, "trace", false, "annotate each value with the values file, flag or chart that set it")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
@@ -86,7 +107,7 @@ func (v valuesWriter) WriteTable(out io.Writer) error {
 	} else {
 		fmt.Fprintln(out, "USER-SUPPLIED VALUES:")
 	}
-	return output.EncodeYAML(out, v.vals)
+	return v.WriteYAML(out)
 }
 
 func (v valuesWriter) WriteJSON(out io.Writer) error {
@@ -94,5 +115,15 @@ func (v valuesWriter) WriteJSON(out io.Writer) error {
 }
 
 func (v valuesWriter) WriteYAML(out io.Writer) error {
-	return output.EncodeYAML(out, v.vals)
+	if v.trace == nil {
+		return output.EncodeYAML(out, v.vals)
+	}
+	data, err := util.AnnotateValues(v.vals, v.trace)
+	if err != nil {
+		return fmt.Errorf("unable to write YAML output: %w", err)
+	}
+	if _, err := out.Write(data); err != nil {
+		return fmt.Errorf("unable to write YAML output: %w", err)
+	}
+	return nil
 }
@@ -23,6 +23,14 @@ import (
 )
 
 func TestGetValuesCmd(t *testing.T) {
+	tracedRelease := func() *release.Release {
+		rel := release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})
+		rel.Chart.Values = map[string]any{"name": "default", "replicas": 1}
+		rel.Config = map[string]any{"name": "value", "image": map[string]any{"tag": "1.2.3"}}
+		rel.ValuesTrace = map[string]string{"name": "-f values.yaml (file 1)", "image.tag": "--set"}
+		return rel
+	}
+
 	tests := []cmdTestCase{{
 		name:   "get values with a release",
 		cmd:    "get values thomas-guide",
@@ -49,6 +57,27 @@ func TestGetValuesCmd(t *testing.T) {
 		cmd:    "get values thomas-guide --output yaml",
 		golden: "output/values.yaml",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:   "get values with trace",
+		cmd:    "get values thomas-guide --trace",
+		golden: "output/get-values-trace.txt",
+		rels:   []*release.Release{tracedRelease()},
+	}, {
+		name:   "get values with trace (all)",
+		cmd:    "get values thomas-guide --trace --all",
+		golden: "output/get-values-trace-all.txt",
+		rels:   []*release.Release{tracedRelease()},
+	}, {
+		name:   "get values with trace of a release without trace",
+		cmd:    "get values thomas-guide --trace",
+		golden: "output/get-values-trace-untraced.txt",
+		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})},
+	}, {
+		name:      "get values with trace to json",
+		cmd:       "get values thomas-guide --trace --output json",
+		golden:    "output/get-values-trace-json.txt",
+		rels:      []*release.Release{tracedRelease()},
+		wantError: true,
 	}}
 	runTestCmd(t, tests)
 }
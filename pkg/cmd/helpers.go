@@ -17,11 +17,14 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
 
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"helm.sh/helm/v4/pkg/action"
 )
@@ -36,7 +39,7 @@ func addDryRunFlag(cmd *cobra.Command) {
 	f.String(
 		"dry-run",
 		"none",
-		`simulates the operation without persisting changes. Must be one of: "none" (default), "client", or "server". '--dry-run=none' executes the operation normally and persists changes (no simulation). '--dry-run=client' simulates the operation client-side only and avoids cluster connections. '--dry-run=server' simulates the operation on the server, requiring cluster connectivity.`)
+		`simulates the operation without persisting changes. Must be one of: "none" (default), "client", "server", or "server-admission". '--dry-run=none' executes the operation normally and persists changes (no simulation). '--dry-run=client' simulates the operation client-side only and avoids cluster connections. '--dry-run=server' simulates the operation on the server, requiring cluster connectivity. '--dry-run=server-admission' additionally submits the resources to the server as a dry-run, so that admission webhooks and policies validate them.`)
 	f.Lookup("dry-run").NoOptDefVal = "unset"
 }
 
@@ -54,6 +57,11 @@ func cmdGetDryRunFlagStrategy(cmd *cobra.Command, isTemplate bool) (action.DryRu
 		return action.DryRunClient, nil
 	case string(action.DryRunServer):
 		return action.DryRunServer, nil
+	case string(action.DryRunServerAdmission):
+		if isTemplate {
+			return action.DryRunNone, fmt.Errorf(`invalid dry-run value (%q). Must be "server" or "client"`, v)
+		}
+		return action.DryRunServerAdmission, nil
 	case string(action.DryRunNone):
 		if isTemplate {
 			// Special case hack for `helm template`, which is always a dry run
@@ -80,3 +88,26 @@ func cmdGetDryRunFlagStrategy(cmd *cobra.Command, isTemplate bool) (action.DryRu
 
 	return result, nil
 }
+
+// withForceConflictsHint adds a hint about the --force-conflicts flag to
+// errors caused by server-side apply field manager conflicts, which occur
+// when another controller or user manages fields set by the chart.
+func withForceConflictsHint(err error, forceConflicts bool) error {
+	if err == nil || forceConflicts || !isFieldManagerConflict(err) {
+		return err
+	}
+	return fmt.Errorf("%w\nuse --force-conflicts to take ownership of the conflicting fields", err)
+}
+
+func isFieldManagerConflict(err error) bool {
+	var status apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &status) || status.Status().Details == nil {
+		return false
+	}
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			return true
+		}
+	}
+	return false
+}
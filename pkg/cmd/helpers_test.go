@@ -19,6 +19,8 @@ package cmd
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
@@ -30,6 +32,9 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"helm.sh/helm/v4/internal/test"
 	"helm.sh/helm/v4/pkg/action"
@@ -196,6 +201,15 @@ func TestCmdGetDryRunFlagStrategy(t *testing.T) {
 			DryRunFlagArg:    "--dry-run=server",
 			ExpectedStrategy: action.DryRunServer,
 		},
+		"server-admission": {
+			DryRunFlagArg:    "--dry-run=server-admission",
+			ExpectedStrategy: action.DryRunServerAdmission,
+		},
+		"server-admission_template": {
+			DryRunFlagArg: "--dry-run=server-admission",
+			IsTemplate:    true,
+			ExpectedError: true,
+		},
 		"bool_false": {
 			DryRunFlagArg:    "--dry-run=false",
 			ExpectedStrategy: action.DryRunNone,
@@ -305,3 +319,42 @@ func TestCmdGetDryRunFlagStrategy(t *testing.T) {
 		})
 	}
 }
+
+func TestWithForceConflictsHint(t *testing.T) {
+	gr := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	fieldConflict := apierrors.NewApplyConflict([]metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldManagerConflict,
+		Message: `conflict with "kubectl-edit"`,
+		Field:   ".spec.replicas",
+	}}, "Apply failed with 1 conflict")
+	versionConflict := apierrors.NewConflict(gr, "web", errors.New("the object has been modified"))
+
+	tests := []struct {
+		name           string
+		err            error
+		forceConflicts bool
+		hint           bool
+	}{
+		{name: "no error"},
+		{name: "field manager conflict", err: fieldConflict, hint: true},
+		{name: "wrapped field manager conflict", err: fmt.Errorf("conflict occurred while applying object: %w", fieldConflict), hint: true},
+		{name: "already forcing conflicts", err: fieldConflict, forceConflicts: true},
+		{name: "resource version conflict", err: versionConflict},
+		{name: "other error", err: errors.New("boom")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := withForceConflictsHint(tt.err, tt.forceConflicts)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.err)
+			if tt.hint {
+				assert.ErrorContains(t, err, "use --force-conflicts")
+			} else {
+				assert.Equal(t, tt.err.Error(), err.Error())
+			}
+		})
+	}
+}
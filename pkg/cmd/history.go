@@ -62,12 +62,14 @@ Use '--show-rollback-revision' to include a column showing the revision that was
 
 func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewHistory(cfg)
+	pruneClient := action.NewHistoryPrune(cfg)
 	var outfmt output.Format
 	var showRollback bool
+	var prune bool
 
 	cmd := &cobra.Command{
 		Use:     "history RELEASE_NAME",
-		Long:    historyHelp,
+		Long:    historyHelp + historyPruneHelp,
 		Short:   "fetch release history",
 		Aliases: []string{"hist"},
 		Args:    require.ExactArgs(1),
@@ -77,7 +79,16 @@ func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			}
 			return compListReleases(toComplete, args, cfg)
 		},
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if prune {
+				return runHistoryPrune(out, pruneClient, args[0])
+			}
+			for _, name := range []string{"keep", "keep-per-status", "older-than"} {
+				if cmd.Flags().Changed(name) {
+					return fmt.Errorf("--%s requires --prune", name)
+				}
+			}
+
 			history, err := getHistory(client, args[0])
 			if err != nil {
 				return err
@@ -94,6 +105,7 @@ func newHistoryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.IntVar(&client.Max, "max", 256, "maximum number of revision to include in history")
 	f.BoolVar(&showRollback, "show-rollback-revision", false, "show the rollback revision column in table output")
 	bindOutputFlag(cmd, &outfmt)
+	addHistoryPruneFlags(cmd, pruneClient, &prune)
 
 	return cmd
 }
@@ -0,0 +1,81 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/release"
+)
+
+const historyPruneHelp = `
+Use '--prune' to delete revisions from the history of a release rather than
+print them. '--keep' and '--keep-per-status' limit the number of revisions to
+keep, the revisions exceeding a limit are deleted. '--older-than' only deletes
+revisions deployed before the given age. Without limits, all revisions older
+than '--older-than' are deleted. The most recent revision and the last
+deployed revision are never deleted.
+
+Keep the 5 most recent revisions, deleting the others deployed more than 30
+days ago:
+
+    $ helm history angry-bird --prune --keep 5 --older-than 30d
+
+Keep a single failed revision:
+
+    $ helm history angry-bird --prune --keep-per-status failed=1
+
+Deleted revisions cannot be rolled back to.
+`
+
+// addHistoryPruneFlags adds the flags deleting revisions from the history of
+// a release with --prune.
+func addHistoryPruneFlags(cmd *cobra.Command, client *action.HistoryPrune, prune *bool) {
+	f := cmd.Flags()
+	f.BoolVar(prune, "prune", false, "delete the revisions exceeding --keep or --keep-per-status or older than --older-than rather than print the history")
+	f.IntVar(&client.Retention.Keep, "keep", 0, "with --prune, number of most recent revisions to keep")
+	f.Var((*statusCountsValue)(&client.Retention.KeepPerStatus), "keep-per-status", "with --prune, number of most recent revisions of a status to keep. Of the form STATUS=COUNT, e.g. failed=1,superseded=5. Can be specified multiple times")
+	f.Var((*ageValue)(&client.Retention.OlderThan), "older-than", "with --prune, only delete revisions deployed more than this long ago, e.g. 30d or 12h")
+	addLockTimeoutFlag(f, &client.LockTimeout)
+}
+
+// runHistoryPrune deletes revisions from the history of the release name.
+func runHistoryPrune(out io.Writer, client *action.HistoryPrune, name string) error {
+	if client.Retention.IsZero() {
+		return errors.New("at least one of --keep, --keep-per-status or --older-than is required with --prune")
+	}
+	pruned, err := client.Run(name)
+	for _, r := range pruned {
+		rac, aerr := release.NewAccessor(r)
+		if aerr != nil {
+			return aerr
+		}
+		fmt.Fprintf(out, "Deleted revision %d of release %q\n", rac.Version(), name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(pruned) == 0 {
+		fmt.Fprintf(out, "No revisions of release %q to delete\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release/common"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestHistoryPruneCmd(t *testing.T) {
+	store := storageFixture()
+	for v, status := range []common.Status{common.StatusSuperseded, common.StatusFailed, common.StatusSuperseded, common.StatusFailed, common.StatusDeployed} {
+		rel := release.Mock(&release.MockReleaseOptions{Name: "angry-bird", Version: v + 1, Status: status})
+		require.NoError(t, store.Create(rel))
+	}
+
+	_, _, err := executeActionCommandC(store, "history angry-bird --prune")
+	assert.ErrorContains(t, err, "at least one of --keep, --keep-per-status or --older-than is required with --prune")
+
+	_, _, err = executeActionCommandC(store, "history angry-bird --keep 3")
+	assert.ErrorContains(t, err, "--keep requires --prune")
+
+	_, out, err := executeActionCommandC(store, "history angry-bird --prune --keep 3")
+	require.NoError(t, err)
+	assert.Equal(t, "Deleted revision 2 of release \"angry-bird\"\nDeleted revision 1 of release \"angry-bird\"\n", out)
+
+	_, out, err = executeActionCommandC(store, "history angry-bird --prune --keep 1 --older-than 36500d")
+	require.NoError(t, err)
+	assert.Equal(t, "No revisions of release \"angry-bird\" to delete\n", out)
+
+	_, out, err = executeActionCommandC(store, "history angry-bird --prune --keep-per-status failed=0")
+	require.NoError(t, err)
+	assert.Equal(t, "Deleted revision 4 of release \"angry-bird\"\n", out)
+
+	h, err := store.History("angry-bird")
+	require.NoError(t, err)
+	assert.Len(t, h, 2)
+}
+
+func TestHistoryPruneReleaseNamedPrune(t *testing.T) {
+	store := storageFixture()
+	require.NoError(t, store.Create(release.Mock(&release.MockReleaseOptions{Name: "prune", Version: 1})))
+
+	_, out, err := executeActionCommandC(store, "history prune")
+	require.NoError(t, err)
+	assert.Contains(t, out, "REVISION")
+}
@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -33,10 +34,12 @@ import (
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chart/loader"
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
 	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/engine"
 	"helm.sh/helm/v4/pkg/getter"
 	release "helm.sh/helm/v4/pkg/release/v1"
 )
@@ -68,6 +71,12 @@ or
 
     $ helm install --set-file my_script=dothings.sh myredis ./redis
 
+With a directory or a glob pattern, '--set-file' sets a map of the names of the
+matching files to their contents, such as for templating them into a ConfigMap:
+
+    $ helm install --set-file configs=./conf/ myredis ./redis
+    $ helm install --set-file 'configs=./conf/*.conf' myredis ./redis
+
 or
 
     $ helm install --set-json 'master.sidecars=[{"name":"sidecar","image":"myImage","imagePullPolicy":"Always","ports":[{"name":"portname","containerPort":1234}]}]' myredis ./redis
@@ -96,6 +105,41 @@ And in the following example, 'foo' is set to '{"key1":"value1","key2":"bar"}':
 
     $ helm install --set-json='foo={"key1":"value1","key2":"value2"}' --set-json='foo.key2="bar"' myredis ./redis
 
+With --values-env-expand, references to environment variables in the values
+files are expanded: '${VAR}' to the value of VAR, failing if it is not set, and
+'${VAR:-default}' to the value of VAR or to the default if VAR is unset or empty.
+Write '$${' for a literal '${'. The values are inserted before the files are
+parsed, so quote references whose values may contain YAML syntax:
+
+    $ IMAGE_TAG=1.2.3 helm install --values-env-expand -f values.yaml myredis ./redis
+
+Values files encrypted with SOPS or age are decrypted transparently. The age
+identities are read from the SOPS_AGE_KEY and SOPS_AGE_KEY_FILE environment
+variables, and from sops/age/keys.txt in the user configuration directory, like
+SOPS does:
+
+    $ SOPS_AGE_KEY_FILE=keys.txt helm install -f secrets.enc.yaml myredis ./redis
+
+Values files can be fetched from HTTP(S) URLs and OCI registries. The content
+of a remote file can be pinned by appending its SHA-256 checksum to the URL:
+
+    $ helm install -f oci://registry.example.com/org/values:1.0.0 myredis ./redis
+    $ helm install -f https://example.com/values.yaml#sha256=<checksum> myredis ./redis
+
+Charts can ship curated presets of values as profiles, YAML files in their
+'profiles/' directory. The --profile flag applies one over the chart's default
+values, and the values supplied with -f and --set still override it:
+
+    $ helm install --profile prod myredis ./redis
+
+The release name can be generated from a template with the --name-template flag.
+The template has access to the chart name and version, the namespace and the
+values supplied by the user, as well as the Sprig functions. If the generated
+name is already in use, the template is rendered again, unless it renders the
+same name every time:
+
+    $ helm install --name-template '{{ .ChartName }}-{{ randNumeric 4 }}' ./redis
+
 To check the generated manifests of a release without installing the chart,
 the --debug and --dry-run flags can be combined.
 
@@ -106,6 +150,23 @@ which can contain sensitive values. To hide Kubernetes Secrets use the
 If --verify is set, the chart MUST have a provenance file, and the provenance
 file MUST pass all verification steps.
 
+When waiting on resources, the --wait-for flag replaces the built-in readiness
+checks for a kind with a JSONPath or CEL condition. For example, to wait for
+cert-manager Certificates to become ready:
+
+    $ helm install --wait-for 'Certificate:.status.conditions[?(@.type=="Ready")].status==True' mycerts ./certs
+
+CEL conditions are prefixed with "cel:" and get the resource as 'self':
+
+    $ helm install --wait-for 'Certificate:cel:self.status.conditions.exists(c, c.type == "Ready" && c.status == "True")' mycerts ./certs
+
+With the --resume flag, Helm records the applied resources in the release as a
+checkpoint while installing. If the install fails, running the same command
+again continues from there instead of uninstalling the release and starting
+over:
+
+    $ helm install --resume myredis ./redis
+
 There are six different ways you can express the chart you want to install:
 
 1. By chart reference: helm install mymaria example/mariadb
@@ -133,6 +194,7 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewInstall(cfg)
 	valueOpts := &values.Options{}
 	var outfmt output.Format
+	var waitFor []string
 
 	cmd := &cobra.Command{
 		Use:   "install [NAME] [CHART]",
@@ -156,9 +218,18 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			}
 			client.DryRunStrategy = dryRunStrategy
 
+			waitOpts, err := waitForOptions(cmd, waitFor, &client.WaitStrategy)
+			if err != nil {
+				return err
+			}
+			client.WaitOptions = append(client.WaitOptions, waitOpts...)
+
+			cfg.RenderWarnings = &engine.RenderWarnings{}
+			defer printRenderWarnings(cmd.ErrOrStderr(), cfg.RenderWarnings)
+
 			rel, err := runInstall(args, client, valueOpts, out)
 			if err != nil {
-				return fmt.Errorf("INSTALLATION FAILED: %w", err)
+				return fmt.Errorf("INSTALLATION FAILED: %w", withForceConflictsHint(err, client.ForceConflicts))
 			}
 
 			return outfmt.Write(out, &statusPrinter{
@@ -176,6 +247,7 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	// hide-secret is not available in all places the install flags are used so
 	// it is added separately
 	f.BoolVar(&client.HideSecret, "hide-secret", false, "hide Kubernetes Secrets when also using the --dry-run flag")
+	addWaitForFlag(cmd, &waitFor)
 	addDryRunFlag(cmd)
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer, settings)
@@ -190,15 +262,21 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 	f.MarkDeprecated("force", "use --force-replace instead")
 	f.BoolVar(&client.ForceConflicts, "force-conflicts", false, "if set server-side apply will force changes against conflicts")
 	f.BoolVar(&client.ServerSideApply, "server-side", true, "object updates run in the server instead of the client")
+	addApplyMethodFlag(cmd, &client.ServerSideApply)
+	addPreflightFlag(cmd, &client.Preflight)
+	f.IntVar(&client.Parallelism, "parallelism", 0, "maximum number of resources created concurrently. Resources of the same kind are created together, in the install order of kinds. 0 means no limit")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during install")
 	f.BoolVar(&client.Replace, "replace", false, "reuse the given name, only if that name is a deleted release which remains in the history. This is unsafe in production")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	addLockTimeoutFlag(f, &client.LockTimeout)
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVarP(&client.GenerateName, "generate-name", "g", false, "generate the name (and omit the NAME parameter)")
-	f.StringVar(&client.NameTemplate, "name-template", "", "specify template used to name the release")
+	f.StringVar(&client.NameTemplate, "name-template", "", "specify template used to name the release. The template can use .ChartName, .ChartVersion, .AppVersion, .Namespace and .Values")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
 	f.BoolVar(&client.Devel, "devel", false, "use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
 	f.BoolVar(&client.DependencyUpdate, "dependency-update", false, "update dependencies if they are missing before installing the chart")
+	f.BoolVar(&client.Locked, "locked", false, "fail if the lock file is missing or out of sync with Chart.yaml, or if the charts in charts/ do not match the lock file")
+	cmd.MarkFlagsMutuallyExclusive("dependency-update", "locked")
 	f.BoolVar(&client.DisableOpenAPIValidation, "disable-openapi-validation", false, "if set, the installation process will not validate rendered templates against the Kubernetes OpenAPI Schema")
 	f.BoolVar(&client.RollbackOnFailure, "rollback-on-failure", false, "if set, Helm will rollback (uninstall) the installation upon failure. The --wait flag will be default to \"watcher\" if --rollback-on-failure is set")
 	f.BoolVar(&client.RollbackOnFailure, "atomic", false, "deprecated")
@@ -206,10 +284,16 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed. By default, CRDs are installed if not already present")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
+	f.BoolVar(&client.StrictValues, "strict-values", false, "if set, fail when the supplied values contain keys that are neither used by a template nor declared in values.schema.json")
+	f.StringVar(&client.Profile, "profile", "", "apply the named values profile of the chart, such as 'prod' for profiles/prod.yaml, over its default values")
 	f.StringToStringVarP(&client.Labels, "labels", "l", nil, "Labels that would be added to release metadata. Should be divided by comma.")
 	f.BoolVar(&client.EnableDNS, "enable-dns", false, "enable DNS lookups when rendering templates")
 	f.BoolVar(&client.HideNotes, "hide-notes", false, "if set, do not show notes in install output. Does not affect presence in chart metadata")
 	f.BoolVar(&client.TakeOwnership, "take-ownership", false, "if set, install will ignore the check for helm annotations and take ownership of the existing resources")
+	f.BoolVar(&client.AdoptExisting, "adopt-existing", false, "if set, install will take ownership of existing resources that are not managed by Helm or another tool, such as resources created with kubectl. Use --take-ownership to also adopt resources managed by another tool or owned by another release")
+	cmd.MarkFlagsMutuallyExclusive("take-ownership", "adopt-existing")
+	f.BoolVar(&client.Resume, "resume", false, "if set, record checkpoints while installing and resume a failed install of the release that was run with --resume from its last checkpoint instead of starting over. The chart and values should be the same as for the failed install")
+	cmd.MarkFlagsMutuallyExclusive("replace", "resume")
 
 	// For `helm template`, these notes flags are legacy, unused, and should not show in help, but
 	// must remain accepted for backwards compatibility in Helm 4. Deprecate and hide them for now
@@ -231,6 +315,8 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 	}
 
 	addValueOptionsFlags(f, valueOpts)
+	addJSONPatchFlags(f, valueOpts)
+	addSecretFlags(f, valueOpts)
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 	AddWaitFlag(cmd, &client.WaitStrategy)
 	cmd.MarkFlagsMutuallyExclusive("force-replace", "force-conflicts")
@@ -272,7 +358,7 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 	slog.Debug("Chart path", "path", cp)
 
 	p := getter.All(settings)
-	vals, err := valueOpts.MergeValues(p)
+	vals, valuesTrace, err := valueOpts.MergeValuesWithTrace(p)
 	if err != nil {
 		return nil, err
 	}
@@ -282,6 +368,16 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 	if err != nil {
 		return nil, err
 	}
+	if client.Locked {
+		if err := verifyLock(cp); err != nil {
+			return nil, err
+		}
+	}
+	if client.DevDependencies {
+		if err := linkLocalDependencies(chartRequested, cp); err != nil {
+			return nil, err
+		}
+	}
 
 	ac, err := chart.NewAccessor(chartRequested)
 	if err != nil {
@@ -327,6 +423,14 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 		}
 	}
 
+	if vals, err = valueOpts.ApplyJSONPatches(chartRequested, vals, p, valuesTrace); err != nil {
+		return nil, err
+	}
+	client.ValuesTrace = valuesTrace
+	if client.SecretProviders, client.SecretReferences, err = secretResolution(valueOpts); err != nil {
+		return nil, err
+	}
+
 	client.Namespace = settings.Namespace()
 
 	// Create context and prepare the handle of SIGTERM
@@ -365,6 +469,21 @@ func checkIfInstallable(ch chart.Accessor) error {
 	return fmt.Errorf("%s charts are not installable", meta["Type"])
 }
 
+// linkLocalDependencies loads the dependencies of a chart directory that have a
+// "file://" repository from their directory, for --dev-deps.
+func linkLocalDependencies(ch chart.Charter, chartpath string) error {
+	if fi, err := os.Stat(chartpath); err != nil {
+		return err
+	} else if !fi.IsDir() {
+		return errors.New("--dev-deps requires a chart directory")
+	}
+	c, ok := ch.(*chartv2.Chart)
+	if !ok {
+		return errors.New("--dev-deps is only supported for apiVersion v1 and v2 charts")
+	}
+	return action.LinkLocalDependencies(c, chartpath)
+}
+
 // Provide dynamic auto-completion for the install and template commands
 func compInstall(args []string, toComplete string, client *action.Install) ([]string, cobra.ShellCompDirective) {
 	requiredArgs := 1
@@ -376,3 +495,11 @@ func compInstall(args []string, toComplete string, client *action.Install) ([]st
 	}
 	return nil, cobra.ShellCompDirectiveNoFileComp
 }
+
+// printRenderWarnings prints the warnings the templates of a chart emitted with
+// the `warn` template function.
+func printRenderWarnings(out io.Writer, warnings *engine.RenderWarnings) {
+	for _, w := range warnings.Warnings() {
+		fmt.Fprintf(out, "WARNING: %s\n", w)
+	}
+}
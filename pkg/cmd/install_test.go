@@ -205,6 +205,13 @@ func TestInstall(t *testing.T) {
 			wantError: true,
 			golden:    "output/schema-negative-cli.txt",
 		},
+		// Install, values not satisfying validation rules
+		{
+			name:      "install with validation rules, with errors",
+			cmd:       "install validations testdata/testcharts/chart-with-validations --set replicas=5 --set ingress.enabled=true",
+			wantError: true,
+			golden:    "output/validations-negative.txt",
+		},
 		// Install with subchart, values from yaml, schematized with errors
 		{
 			name:      "install with schema file and schematized subchart, with errors",
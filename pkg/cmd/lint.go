@@ -20,15 +20,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
 	"helm.sh/helm/v4/pkg/getter"
@@ -41,21 +45,66 @@ the chart is well-formed.
 If the linter encounters things that will cause the chart to fail installation,
 it will emit [ERROR] messages. If it encounters issues that break with convention
 or recommendation, it will emit [WARNING] messages.
+
+With '--kube-lint', the rendered resources are also checked against the best
+practices of Kubernetes: containers without resource limits or probes, images
+tagged latest or not at all, and privileged containers. Deprecated APIs are
+always reported.
+
+The messages are written as JSON with '--output json', as SARIF for the code
+scanning of CI systems with '--output sarif', or as GitHub Actions annotations,
+shown inline on pull requests, with '--output github'.
+
+Each check is a rule with an ID and a severity, listed by '--list-rules'. The
+severity of a rule is overridden, or the rule disabled, in the .helmlintrc file
+of the chart:
+
+    rules:
+      chart-icon: disabled
+      template-deprecated-api: error
+
+or with the '--rules' flag, which takes precedence over the file:
+
+    $ helm lint --rules chart-icon=warning,template-top-level-indent=disabled mychart
 `
 
 func newLintCmd(out io.Writer) *cobra.Command {
 	client := action.NewLint()
 	valueOpts := &values.Options{}
 	var kubeVersion string
+	var ruleSettings map[string]string
+	var listRules bool
+	outfmt := lintOutputText
 
 	cmd := &cobra.Command{
 		Use:   "lint PATH",
 		Short: "examine a chart for possible issues",
 		Long:  longLintHelp,
-		Args:  require.MinimumNArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if listRules {
+				return require.NoArgs(cmd, args)
+			}
+			return require.MinimumNArgs(1)(cmd, args)
+		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if listRules {
+				return writeLintRules(out, client.Rules())
+			}
 			paths := args
 
+			if !slices.Contains(lintOutputFormats, outfmt) {
+				return fmt.Errorf("invalid output format %q, expected one of: %s", outfmt, strings.Join(lintOutputFormats, ", "))
+			}
+
+			ruleConfig, err := support.ParseRuleConfig(ruleSettings)
+			if err == nil {
+				err = client.ValidateRuleConfig(ruleConfig)
+			}
+			if err != nil {
+				return fmt.Errorf("invalid --rules: %w", err)
+			}
+			client.RuleConfig = ruleConfig
+
 			if kubeVersion != "" {
 				parsedKubeVersion, err := common.ParseKubeVersion(kubeVersion)
 				if err != nil {
@@ -86,12 +135,21 @@ func newLintCmd(out io.Writer) *cobra.Command {
 			}
 
 			var message strings.Builder
+			var report []action.LintChartResult
 			failed := 0
 			errorsOrWarnings := 0
 
 			for _, path := range paths {
 				result := client.Run([]string{path}, vals)
 
+				if outfmt != lintOutputText {
+					if len(result.Errors) != 0 {
+						failed++
+					}
+					report = append(report, action.LintChartResult{Path: path, Result: result})
+					continue
+				}
+
 				// If there is no errors/warnings and quiet flag is set
 				// go to the next chart
 				hasWarningsOrErrors := action.HasWarningsOrErrors(result)
@@ -130,13 +188,18 @@ func newLintCmd(out io.Writer) *cobra.Command {
 				fmt.Fprint(&message, "\n")
 			}
 
+			if outfmt != lintOutputText {
+				if err := client.WriteReport(out, action.LintFormat(outfmt), report); err != nil {
+					return err
+				}
+			}
 			fmt.Fprint(out, message.String())
 
 			summary := fmt.Sprintf("%d chart(s) linted, %d chart(s) failed", len(paths), failed)
 			if failed > 0 {
 				return errors.New(summary)
 			}
-			if !client.Quiet || errorsOrWarnings > 0 {
+			if outfmt == lintOutputText && (!client.Quiet || errorsOrWarnings > 0) {
 				fmt.Fprintln(out, summary)
 			}
 			return nil
@@ -149,7 +212,34 @@ func newLintCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Quiet, "quiet", false, "print only warnings and errors")
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
 	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for capabilities and deprecation checks")
+	f.StringToStringVar(&ruleSettings, "rules", nil, "override the severity of lint rules by ID, e.g. chart-icon=error. The severity is one of info, warning, error or disabled. Can be specified multiple times or as comma-separated pairs")
+	f.BoolVar(&listRules, "list-rules", false, "list the lint rules with their ID and default severity")
+	f.BoolVar(&client.KubeLint, "kube-lint", false, "check the rendered resources against the best practices of Kubernetes: resource limits, probes, image tags and privileged containers")
+	f.StringVarP(&outfmt, "output", "o", lintOutputText, fmt.Sprintf("prints the output in the specified format. Allowed values: %s", strings.Join(lintOutputFormats, ", ")))
+	err := cmd.RegisterFlagCompletionFunc("output", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return lintOutputFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 	addValueOptionsFlags(f, valueOpts)
 
 	return cmd
 }
+
+// lintOutputText is the default output format of helm lint, the messages
+// of each chart as text.
+const lintOutputText = "text"
+
+// lintOutputFormats are the output formats of helm lint.
+var lintOutputFormats = []string{lintOutputText, string(action.LintFormatJSON), string(action.LintFormatSARIF), string(action.LintFormatGitHub)}
+
+// writeLintRules writes lint rules as a table.
+func writeLintRules(out io.Writer, rules []support.Rule) error {
+	table := uitable.New()
+	table.AddRow("ID", "SEVERITY", "DESCRIPTION")
+	for _, rule := range rules {
+		table.AddRow(rule.ID, support.SeverityName(rule.Severity), rule.Description)
+	}
+	return output.EncodeTable(out, table)
+}
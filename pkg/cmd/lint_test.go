@@ -103,3 +103,63 @@ func TestLintFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "lint", true)
 	checkFileCompletion(t, "lint mypath", true) // Multiple paths can be given
 }
+
+func TestLintCmdWithRulesFlag(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-only-crds"
+	tests := []cmdTestCase{{
+		name:   "lint with a rule disabled",
+		cmd:    "lint --quiet --rules templates-dir-exists=disabled " + testChart,
+		golden: "output/lint-quiet.txt",
+	}, {
+		name:      "lint with a rule promoted",
+		cmd:       "lint --rules templates-dir-exists=error " + testChart,
+		golden:    "output/lint-rules-promoted.txt",
+		wantError: true,
+	}, {
+		name:      "lint with an unknown rule",
+		cmd:       "lint --rules templates-dir=error,bogus=off " + testChart,
+		golden:    "output/lint-rules-unknown.txt",
+		wantError: true,
+	}, {
+		name:   "list the lint rules",
+		cmd:    "lint --list-rules",
+		golden: "output/lint-list-rules.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithKubeLintFlag(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-kube-lint-issues"
+	tests := []cmdTestCase{{
+		name:   "lint without kube lint",
+		cmd:    "lint " + testChart,
+		golden: "output/lint-without-kube-lint.txt",
+	}, {
+		name:   "lint with kube lint",
+		cmd:    "lint --kube-lint " + testChart,
+		golden: "output/lint-kube-lint.txt",
+	}, {
+		name:      "lint with kube lint and strict",
+		cmd:       "lint --kube-lint --strict " + testChart,
+		golden:    "output/lint-kube-lint-strict.txt",
+		wantError: true,
+	}, {
+		name:   "lint with kube lint as JSON",
+		cmd:    "lint --kube-lint --output json " + testChart,
+		golden: "output/lint-kube-lint.json",
+	}, {
+		name:   "lint with kube lint as SARIF",
+		cmd:    "lint --kube-lint --output sarif " + testChart,
+		golden: "output/lint-kube-lint.sarif",
+	}, {
+		name:   "lint with kube lint as GitHub annotations",
+		cmd:    "lint --kube-lint --output github " + testChart,
+		golden: "output/lint-kube-lint-github.txt",
+	}, {
+		name:      "lint with an invalid output format",
+		cmd:       "lint --output xml " + testChart,
+		golden:    "output/lint-invalid-output.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
@@ -53,6 +53,13 @@ Only items that match the filter will be returned.
     NAME                UPDATED                                  CHART
     maudlin-arachnid    2020-06-18 14:17:46.125134977 +0000 UTC  alpine-0.1.0
 
+Releases can also be filtered by chart name, app version, deploy date and
+values. Such filters can be combined, only the releases matching all of them
+are shown:
+
+    $ helm list --chart nginx --app-version ">=1.25" --has-value image.tag=1.2.3
+    $ helm list --all-namespaces --deployed-before 2024-01-01
+
 If no results are found, 'helm list' will exit 0, but with no output (or in
 the case of no '-q' flag, only headers).
 
@@ -60,6 +67,11 @@ By default, up to 256 items may be returned. To limit this, use the '--max' flag
 Setting '--max' to 0 will not return all results. Rather, it will return the
 server's default, which may be much higher than 256. Pairing the '--max'
 flag with the '--offset' flag allows you to page through results.
+
+Releases stored in Secrets or ConfigMaps are retrieved from the cluster in
+chunks of up to 500 objects, which keeps requests small when listing across
+many namespaces with '--all-namespaces'. Use the '--chunk-size' flag to change
+the size of the chunks, or set it to 0 to retrieve all the objects at once.
 `
 
 func newListCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -132,6 +144,12 @@ func newListCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVarP(&client.AllNamespaces, "all-namespaces", "A", false, "list releases across all namespaces")
 	f.IntVarP(&client.Limit, "max", "m", 256, "maximum number of releases to fetch")
 	f.IntVar(&client.Offset, "offset", 0, "next release index in the list, used to offset from start value")
+	f.StringVar(&client.ChartName, "chart", "", "show only releases of the chart with this name")
+	f.StringVar(&client.AppVersion, "app-version", "", `show only releases whose app version satisfies this semantic version constraint, e.g. ">=1.25"`)
+	f.Var((*dateValue)(&client.DeployedBefore), "deployed-before", "show only releases last deployed before this date, e.g. 2024-01-01")
+	f.Var((*dateValue)(&client.DeployedAfter), "deployed-after", "show only releases last deployed after this date, e.g. 2024-01-01")
+	f.StringArrayVar(&client.HasValues, "has-value", []string{}, "show only releases whose values, including the chart defaults, have this path=value pair, e.g. image.tag=1.2.3 (can specify multiple)")
+	f.Int64Var(&client.ChunkSize, "chunk-size", 500, "maximum number of stored releases to retrieve per request from the secret and configmap storage backends, 0 retrieves them all at once")
 	f.StringVarP(&client.Filter, "filter", "f", "", "a regular expression (Perl compatible). Any releases that match the expression will be included in the results")
 	f.StringVarP(&client.Selector, "selector", "l", "", "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2). Works only for secret(default) and configmap storage backends.")
 	bindOutputFlag(cmd, &outfmt)
@@ -234,6 +234,22 @@ func TestListCmd(t *testing.T) {
 		cmd:    "list -n milano",
 		golden: "output/list-namespace.txt",
 		rels:   releaseFixture,
+	}, {
+		name:   "list releases by chart and app version",
+		cmd:    "list --chart chickadee --app-version '>=0.0.1'",
+		golden: "output/list-all.txt",
+		rels:   releaseFixture,
+	}, {
+		name:   "list releases deployed after a date",
+		cmd:    "list --deployed-after 2016-01-16T00:00:02Z",
+		golden: "output/list-deployed-after.txt",
+		rels:   releaseFixture,
+	}, {
+		name:      "list releases deployed before an invalid date",
+		cmd:       "list --deployed-before yesterday",
+		golden:    "output/list-invalid-date.txt",
+		rels:      releaseFixture,
+		wantError: true,
 	}}
 	runTestCmd(t, tests)
 }
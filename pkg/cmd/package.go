@@ -22,6 +22,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -29,6 +31,7 @@ import (
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/downloader"
 	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/signing"
 )
 
 const packageDesc = `
@@ -45,11 +48,40 @@ provide '--keyring path/to/secret/keys' and '--key keyname'.
 
 If '--keyring' is not specified, Helm usually defaults to the public keyring
 unless your environment is otherwise configured.
+
+To sign a chart without managing keys, use Sigstore keyless signing with the
+'--sign-keyless' flag. Fulcio issues a short-lived certificate to the identity
+of an OIDC token, given with '--identity-token' or the SIGSTORE_ID_TOKEN
+environment variable, and the signature is recorded in the Rekor transparency
+log. The signature is written next to the chart archive, in a .sigstore.json
+bundle that 'helm push' attaches to the chart in OCI registries:
+
+  $ helm package --sign-keyless --identity-token "$ID_TOKEN" ./mychart
+
+Packaging is reproducible: the files of the chart archive are in a fixed order,
+and with '--source-date-epoch' they all have the given modification time, in
+seconds since the Unix epoch, so the same chart source always yields a
+byte-identical archive. It defaults to the SOURCE_DATE_EPOCH environment
+variable, e.g. the time of the last commit of the chart:
+
+  $ helm package --source-date-epoch "$(git log -1 --format=%ct)" ./mychart
+
+The files of the chart ignored by its .helmignore file are not packaged. Use
+'--ignore-file' to use another ignore file instead, '--exclude' to ignore more
+files, and '--include' to package files even if they are ignored, all with the
+patterns of .helmignore files, without changing the chart:
+
+  $ helm package --exclude 'tests/' --exclude '*.md' --include README.md ./mychart
 `
 
+// sourceDateEpochEnvVar is the environment variable of the reproducible
+// builds specification setting the modification time of the built files.
+const sourceDateEpochEnvVar = "SOURCE_DATE_EPOCH"
+
 func newPackageCmd(out io.Writer) *cobra.Command {
 	client := action.NewPackage()
 	valueOpts := &values.Options{}
+	var sourceDateEpoch string
 
 	cmd := &cobra.Command{
 		Use:   "package [CHART_PATH] [...]",
@@ -67,6 +99,24 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 					return errors.New("--keyring is required for signing a package")
 				}
 			}
+			if client.SignKeyless {
+				if client.IdentityToken == "" {
+					client.IdentityToken = os.Getenv(signing.IdentityTokenEnvVar)
+				}
+				if client.IdentityToken == "" {
+					return fmt.Errorf("--identity-token or $%s is required for keyless signing", signing.IdentityTokenEnvVar)
+				}
+			}
+			if sourceDateEpoch == "" {
+				sourceDateEpoch = os.Getenv(sourceDateEpochEnvVar)
+			}
+			if sourceDateEpoch != "" {
+				t, err := parseSourceDateEpoch(sourceDateEpoch)
+				if err != nil {
+					return err
+				}
+				client.SourceDateEpoch = t
+			}
 			client.RepositoryConfig = settings.RepositoryConfig
 			client.RepositoryCache = settings.RepositoryCache
 			p := getter.All(settings)
@@ -107,6 +157,11 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 						return err
 					}
 				}
+				if client.Locked {
+					if err := verifyLock(path); err != nil {
+						return err
+					}
+				}
 				p, err := client.Run(path, vals)
 				if err != nil {
 					return err
@@ -122,10 +177,19 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&client.Key, "key", "", "name of the key to use when signing. Used if --sign is true")
 	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "location of a public keyring")
 	f.StringVar(&client.PassphraseFile, "passphrase-file", "", `location of a file which contains the passphrase for the signing key. Use "-" in order to read from stdin.`)
+	f.BoolVar(&client.SignKeyless, "sign-keyless", false, "sign this package with Sigstore keyless signing")
+	f.StringVar(&client.IdentityToken, "identity-token", "", fmt.Sprintf("OIDC token of the identity signing the package. Used if --sign-keyless is true (default $%s)", signing.IdentityTokenEnvVar))
+	f.StringVar(&client.FulcioURL, "fulcio-url", signing.DefaultFulcioURL, "URL of the Sigstore certificate authority. Used if --sign-keyless is true")
+	f.StringVar(&client.RekorURL, "rekor-url", signing.DefaultRekorURL, "URL of the Sigstore transparency log. Used if --sign-keyless is true")
 	f.StringVar(&client.Version, "version", "", "set the version on the chart to this semver version")
 	f.StringVar(&client.AppVersion, "app-version", "", "set the appVersion on the chart to this version")
 	f.StringVarP(&client.Destination, "destination", "d", ".", "location to write the chart.")
 	f.BoolVarP(&client.DependencyUpdate, "dependency-update", "u", false, `update dependencies from "Chart.yaml" to dir "charts/" before packaging`)
+	f.BoolVar(&client.Locked, "locked", false, "fail if the lock file is missing or out of sync with Chart.yaml, or if the charts in charts/ do not match the lock file")
+	f.StringVar(&client.IgnoreFile, "ignore-file", "", "use this ignore file instead of the .helmignore file of the chart")
+	f.StringArrayVar(&client.Exclude, "exclude", nil, "do not package the files matching this .helmignore pattern (can specify multiple)")
+	f.StringArrayVar(&client.Include, "include", nil, "package the files matching this .helmignore pattern even if they are ignored (can specify multiple)")
+	f.StringVar(&sourceDateEpoch, "source-date-epoch", "", fmt.Sprintf("set the modification time of the files in the chart archive, in seconds since the Unix epoch (default $%s)", sourceDateEpochEnvVar))
 	f.StringVar(&client.Username, "username", "", "chart repository username where to locate the requested chart")
 	f.StringVar(&client.Password, "password", "", "chart repository password where to locate the requested chart")
 	f.StringVar(&client.CertFile, "cert-file", "", "identify HTTPS client using this SSL certificate file")
@@ -133,6 +197,16 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&client.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the chart download")
 	f.BoolVar(&client.PlainHTTP, "plain-http", false, "use insecure HTTP connections for the chart download")
 	f.StringVar(&client.CaFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	cmd.MarkFlagsMutuallyExclusive("dependency-update", "locked")
 
 	return cmd
 }
+
+// parseSourceDateEpoch parses a time in seconds since the Unix epoch.
+func parseSourceDateEpoch(s string) (time.Time, error) {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || sec < 0 {
+		return time.Time{}, fmt.Errorf("invalid source date epoch %q: expected a non-negative number of seconds since the Unix epoch", s)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
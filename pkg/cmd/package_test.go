@@ -26,6 +26,8 @@ import (
 	"helm.sh/helm/v4/internal/test/ensure"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/signing"
+	"helm.sh/helm/v4/pkg/signing/signingtest"
 )
 
 func TestPackage(t *testing.T) {
@@ -84,6 +86,34 @@ func TestPackage(t *testing.T) {
 			expect:  "",
 			hasfile: "toot/alpine-0.1.0.tgz",
 		},
+		{
+			name:    "package --source-date-epoch",
+			args:    []string{"testdata/testcharts/alpine"},
+			flags:   map[string]string{"source-date-epoch": "1700000000"},
+			expect:  "",
+			hasfile: "alpine-0.1.0.tgz",
+		},
+		{
+			name:   "package --source-date-epoch, invalid",
+			args:   []string{"testdata/testcharts/alpine"},
+			flags:  map[string]string{"source-date-epoch": "yesterday"},
+			expect: `invalid source date epoch "yesterday"`,
+			err:    true,
+		},
+		{
+			name:    "package --exclude --include",
+			args:    []string{"testdata/testcharts/alpine"},
+			flags:   map[string]string{"exclude": "*.md", "include": "README.md"},
+			expect:  "",
+			hasfile: "alpine-0.1.0.tgz",
+		},
+		{
+			name:   "package --ignore-file, missing",
+			args:   []string{"testdata/testcharts/alpine"},
+			flags:  map[string]string{"ignore-file": "no-such-file"},
+			expect: "no such file or directory",
+			err:    true,
+		},
 		{
 			name:    "package --sign --key=KEY --keyring=KEYRING testdata/testcharts/alpine",
 			args:    []string{"testdata/testcharts/alpine"},
@@ -196,3 +226,58 @@ func TestPackageFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "package", true)
 	checkFileCompletion(t, "package mypath", true) // Multiple paths can be given
 }
+
+func TestPackageSignKeyless(t *testing.T) {
+	srv := signingtest.NewServer(t)
+	host, flags := setupRegistryBrowse(t)
+	dir := t.TempDir()
+	token := signingtest.Token("dev@example.com", "https://issuer.example.com")
+
+	_, _, err := executeActionCommand(fmt.Sprintf(
+		"package testdata/testcharts/alpine --destination %s --sign-keyless --identity-token %s --fulcio-url %s --rekor-url %s",
+		dir, token, srv.URL, srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chartPath := filepath.Join(dir, "alpine-0.1.0.tgz")
+	if _, err := os.Stat(chartPath + signing.BundleExt); err != nil {
+		t.Fatalf("expected a Sigstore bundle: %v", err)
+	}
+
+	verify := fmt.Sprintf("--verify-keyless --certificate-oidc-issuer https://issuer.example.com --trusted-root %s", srv.TrustedRoot)
+	if _, _, err := executeActionCommand(fmt.Sprintf(
+		"template %s %s --certificate-identity dev@example.com", chartPath, verify)); err != nil {
+		t.Errorf("expected the keyless signature to verify, got %v", err)
+	}
+	_, _, err = executeActionCommand(fmt.Sprintf(
+		"template %s %s --certificate-identity someone@example.com", chartPath, verify))
+	if err == nil || !strings.Contains(err.Error(), "the chart was signed by [dev@example.com], not someone@example.com") {
+		t.Errorf("expected an identity mismatch error, got %v", err)
+	}
+
+	_, out, err := executeActionCommand(fmt.Sprintf("push %s oci://%s/keyless %s", chartPath, host, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Attached: " + signing.BundleMediaType; !strings.Contains(out, want) {
+		t.Errorf("expected %q in push output %q", want, out)
+	}
+	if _, _, err := executeActionCommand(fmt.Sprintf(
+		"pull oci://%s/keyless/alpine --version 0.1.0 -d %s %s --certificate-identity dev@example.com %s",
+		host, t.TempDir(), verify, flags)); err != nil {
+		t.Errorf("expected the pulled chart to verify, got %v", err)
+	}
+	_, _, err = executeActionCommand(fmt.Sprintf(
+		"pull oci://%s/keyless/alpine --version 0.1.0 -d %s %s --certificate-identity dev@example.com --certificate-oidc-issuer https://other.example.com %s",
+		host, t.TempDir(), verify, flags))
+	if err == nil || !strings.Contains(err.Error(), "not https://other.example.com") {
+		t.Errorf("expected an issuer mismatch error, got %v", err)
+	}
+
+	t.Setenv(signing.IdentityTokenEnvVar, "")
+	_, _, err = executeActionCommand(fmt.Sprintf(
+		"package testdata/testcharts/alpine --destination %s --sign-keyless", dir))
+	if err == nil || !strings.Contains(err.Error(), "--identity-token or $SIGSTORE_ID_TOKEN is required") {
+		t.Errorf("expected a missing token error, got %v", err)
+	}
+}
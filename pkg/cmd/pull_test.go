@@ -557,3 +557,54 @@ func TestPullOCIWithTagAndDigest(t *testing.T) {
 		}
 	}
 }
+
+func TestPullMirrorCmd(t *testing.T) {
+	host, flags := setupRegistryBrowse(t)
+	// The mirror endpoint sets plain HTTP for the mirror only
+	flags = strings.TrimSuffix(flags, " --plain-http")
+	dir := t.TempDir()
+	registriesConfig := filepath.Join(dir, "registries.yaml")
+	mirrors := fmt.Sprintf("mirrors:\n  charts.example.invalid:\n    endpoint:\n      - http://%s/u\n", host)
+	if err := os.WriteFile(registriesConfig, []byte(mirrors), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	orig := settings.RegistriesConfig
+	settings.RegistriesConfig = registriesConfig
+	t.Cleanup(func() { settings.RegistriesConfig = orig })
+
+	_, out, err := executeActionCommand(fmt.Sprintf(
+		"pull oci://charts.example.invalid/ocitestuser/oci-dependent-chart --version 0.1.0 -d %s %s", dir, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("Pulled: %s/u/ocitestuser/oci-dependent-chart:0.1.0", host); !strings.Contains(out, want) {
+		t.Errorf("expected %q in pull output %q", want, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "oci-dependent-chart-0.1.0.tgz")); err != nil {
+		t.Errorf("expected the chart pulled from the mirror: %v", err)
+	}
+
+	_, out, err = executeActionCommand(fmt.Sprintf(
+		"registry tags oci://charts.example.invalid/ocitestuser/oci-dependent-chart %s", flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "0.1.0") {
+		t.Errorf("expected the tags of the mirror, got %q", out)
+	}
+
+	_, _, err = executeActionCommand(fmt.Sprintf(
+		"pull oci://charts.example.invalid/ocitestuser/no-such-chart --version 0.1.0 -d %s %s", dir, flags))
+	if err == nil {
+		t.Error("expected an error for a chart missing from the mirror and the registry")
+	}
+
+	if err := os.WriteFile(registriesConfig, []byte("mirrors:\n  docker.io:\n    endpoint: [ftp://mirror]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = executeActionCommand(fmt.Sprintf(
+		"pull oci://charts.example.invalid/ocitestuser/oci-dependent-chart --version 0.1.0 -d %s %s", dir, flags))
+	if err == nil || !strings.Contains(err.Error(), "invalid mirror of docker.io") {
+		t.Errorf("expected an invalid registries config error, got %v", err)
+	}
+}
@@ -32,6 +32,17 @@ Upload a chart to a registry.
 
 If the chart has an associated provenance file,
 it will also be uploaded.
+
+SBOMs and other attestations can be attached to the chart with the OCI
+referrers API, using '--sbom' and '--attestation'. With '--prov-referrer', the
+provenance file is attached the same way instead of being stored in the chart
+manifest, so that the chart digest does not depend on its signature.
+'helm pull --verify' finds the provenance file in either place, and checks the
+attached artifacts against their digests.
+
+    $ helm push mychart-0.1.0.tgz oci://localhost:5000/charts \
+        --sbom sbom.spdx.json \
+        --attestation application/vnd.in-toto+json=provenance.intoto.json
 `
 
 type registryPushOptions struct {
@@ -42,6 +53,9 @@ type registryPushOptions struct {
 	plainHTTP             bool
 	password              string
 	username              string
+	sboms                 []string
+	attestations          []string
+	provAsReferrer        bool
 }
 
 func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -84,7 +98,10 @@ func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				action.WithTLSClientConfig(o.certFile, o.keyFile, o.caFile),
 				action.WithInsecureSkipTLSVerify(o.insecureSkipTLSVerify),
 				action.WithPlainHTTP(o.plainHTTP),
-				action.WithPushOptWriter(out))
+				action.WithPushOptWriter(out),
+				action.WithSBOMs(o.sboms...),
+				action.WithAttestations(o.attestations...),
+				action.WithProvAsReferrer(o.provAsReferrer))
 			client.Settings = settings
 			output, err := client.Run(chartRef, remote)
 			if err != nil {
@@ -103,6 +120,9 @@ func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the chart upload")
 	f.StringVar(&o.username, "username", "", "chart repository username where to locate the requested chart")
 	f.StringVar(&o.password, "password", "", "chart repository password where to locate the requested chart")
+	f.StringArrayVar(&o.sboms, "sbom", nil, "attach an SPDX or CycloneDX JSON SBOM to the chart (can specify multiple)")
+	f.StringArrayVar(&o.attestations, "attestation", nil, "attach a file to the chart as an artifact of the given type, in the form ARTIFACT_TYPE=FILE (can specify multiple)")
+	f.BoolVar(&o.provAsReferrer, "prov-referrer", false, "attach the provenance file to the chart as a referrer instead of storing it in the chart manifest")
 
 	return cmd
 }
@@ -17,6 +17,10 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -25,3 +29,60 @@ func TestPushFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "push package.tgz", false)
 	checkFileCompletion(t, "push package.tgz oci://localhost:5000", false)
 }
+
+func TestPushReferrersCmd(t *testing.T) {
+	host, flags := setupRegistryBrowse(t)
+	dir := t.TempDir()
+	sbom := filepath.Join(dir, "sbom.json")
+	if err := os.WriteFile(sbom, []byte(`{"bomFormat":"CycloneDX","specVersion":"1.5"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	attestation := filepath.Join(dir, "attestation.json")
+	if err := os.WriteFile(attestation, []byte(`{"_type":"https://in-toto.io/Statement/v1"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, out, err := executeActionCommand(fmt.Sprintf(
+		"push testdata/testcharts/signtest-0.1.0.tgz oci://%s/signed --prov-referrer --sbom %s --attestation application/vnd.in-toto+json=%s %s",
+		host, sbom, attestation, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Attached: application/vnd.cncf.helm.chart.provenance.v1.prov sha256:",
+		"Attached: application/vnd.cyclonedx+json sha256:",
+		"Attached: application/vnd.in-toto+json sha256:",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in push output %q", want, out)
+		}
+	}
+
+	_, out, err = executeActionCommand(fmt.Sprintf(
+		"pull oci://%s/signed/signtest --version 0.1.0 --verify --keyring testdata/helm-test-key.pub -d %s %s",
+		host, dir, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Verified referrer: application/vnd.cyclonedx+json sha256:",
+		"Verified referrer: application/vnd.in-toto+json sha256:",
+		"Chart Hash Verified: ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in pull output %q", want, out)
+		}
+	}
+
+	_, _, err = executeActionCommand(fmt.Sprintf(
+		"push testdata/testcharts/signtest-0.1.0.tgz oci://%s/signed --attestation %s %s", host, attestation, flags))
+	if err == nil || !strings.Contains(err.Error(), "expected ARTIFACT_TYPE=FILE") {
+		t.Errorf("expected an invalid attestation error, got %v", err)
+	}
+
+	_, _, err = executeActionCommand(fmt.Sprintf(
+		"push testdata/testcharts/compressedchart-0.1.0.tgz oci://%s/unsigned --prov-referrer %s", host, flags))
+	if err == nil || !strings.Contains(err.Error(), ".prov: no such file") {
+		t.Errorf("expected a missing provenance error, got %v", err)
+	}
+}
@@ -16,26 +16,91 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"helm.sh/helm/v4/pkg/action"
 )
 
 const registryHelp = `
 This command consists of multiple subcommands to interact with registries.
+
+Charts can be pulled from registry mirrors, such as pull-through caches,
+listed in the registries file ($HELM_REGISTRIES_CONFIG). The mirrors of a
+registry are tried in order before the registry itself, and each registry
+can have its own credentials and TLS settings:
+
+    mirrors:
+      docker.io:
+        endpoint:
+          - https://mirror.example.com/dockerhub
+    configs:
+      mirror.example.com:
+        auth:
+          username: helm
+          password: secret
+        tls:
+          ca_file: /etc/ssl/mirror-ca.pem
+      123456789012.dkr.ecr.us-east-1.amazonaws.com:
+        auth:
+          helper: ecr-login
+
+Without credentials from 'helm registry login', the registries file or the
+Docker credential helpers, the credentials of Amazon ECR, Google Artifact
+Registry and Azure Container Registry registries are obtained from the ambient
+credentials of their cloud, such as the IAM role, service account or managed
+identity of the instance or pod.
 `
 
 func newRegistryCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "registry",
-		Short: "login to or logout from a registry",
+		Short: "login to, logout from or browse a registry",
 		Long:  registryHelp,
 	}
 	cmd.AddCommand(
 		newRegistryLoginCmd(cfg, out),
 		newRegistryLogoutCmd(cfg, out),
+		newRegistryListCmd(cfg, out),
+		newRegistrySearchCmd(cfg, out),
+		newRegistryTagsCmd(cfg, out),
 	)
 	return cmd
 }
+
+// registryClientOptions holds the connection flags of the subcommands that
+// query a registry.
+type registryClientOptions struct {
+	certFile              string
+	keyFile               string
+	caFile                string
+	insecureSkipTLSVerify bool
+	plainHTTP             bool
+	username              string
+	password              string
+}
+
+func (o *registryClientOptions) addFlags(f *pflag.FlagSet) {
+	f.StringVar(&o.certFile, "cert-file", "", "identify registry client using this SSL certificate file")
+	f.StringVar(&o.keyFile, "key-file", "", "identify registry client using this SSL key file")
+	f.StringVar(&o.caFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	f.BoolVar(&o.insecureSkipTLSVerify, "insecure-skip-tls-verify", false, "skip tls certificate checks for the registry")
+	f.BoolVar(&o.plainHTTP, "plain-http", false, "use insecure HTTP connections for the registry")
+	f.StringVar(&o.username, "username", "", "registry username")
+	f.StringVar(&o.password, "password", "", "registry password or identity token")
+}
+
+// setRegistryClient configures cfg with a registry client built from the flags.
+func (o *registryClientOptions) setRegistryClient(cfg *action.Configuration, out io.Writer) error {
+	registryClient, err := newRegistryClient(
+		out, o.certFile, o.keyFile, o.caFile, o.insecureSkipTLSVerify, o.plainHTTP, o.username, o.password,
+	)
+	if err != nil {
+		return fmt.Errorf("missing registry client: %w", err)
+	}
+	cfg.RegistryClient = registryClient
+	return nil
+}
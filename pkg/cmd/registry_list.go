@@ -0,0 +1,103 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const registryListDesc = `
+List the repositories hosted by an OCI registry.
+
+The registry must implement the catalog API (/v2/_catalog). Append a namespace
+to the host to only list the repositories under it:
+
+    $ helm registry list localhost:5000/charts
+`
+
+func newRegistryListCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	o := &registryClientOptions{}
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "list HOST[/NAMESPACE]",
+		Aliases:           []string{"ls"},
+		Short:             "list the repositories of a registry",
+		Long:              registryListDesc,
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := o.setRegistryClient(cfg, out); err != nil {
+				return err
+			}
+			refs, err := action.NewRegistryList(cfg).Run(args[0])
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &registryListWriter{refs: refs, header: "NAME", empty: "No repositories found"})
+		},
+	}
+
+	o.addFlags(cmd.Flags())
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+// registryListWriter writes a single column of registry references or tags.
+type registryListWriter struct {
+	refs   []string
+	header string
+	empty  string
+}
+
+func (w *registryListWriter) WriteTable(out io.Writer) error {
+	if len(w.refs) == 0 {
+		_, err := fmt.Fprintln(out, w.empty)
+		return err
+	}
+	table := uitable.New()
+	table.AddRow(w.header)
+	for _, ref := range w.refs {
+		table.AddRow(ref)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *registryListWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.list())
+}
+
+func (w *registryListWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.list())
+}
+
+// list returns the references, as an empty list rather than null.
+func (w *registryListWriter) list() []string {
+	if w.refs == nil {
+		return []string{}
+	}
+	return w.refs
+}
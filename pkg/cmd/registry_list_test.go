@@ -0,0 +1,150 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/repo/v1/repotest"
+)
+
+// setupRegistryBrowse starts a registry holding oci-dependent-chart and
+// returns its host along with the flags to reach it.
+func setupRegistryBrowse(t *testing.T) (string, string) {
+	t.Helper()
+	srv := repotest.NewTempServer(t, repotest.WithChartSourceGlob("testdata/testcharts/*.tgz*"))
+	t.Cleanup(srv.Stop)
+
+	ociSrv, err := repotest.NewOCIServer(t, srv.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ociSrv.Run(t)
+
+	return ociSrv.RegistryURL, fmt.Sprintf("--registry-config %s --plain-http", filepath.Join(srv.Root(), "config.json"))
+}
+
+func TestRegistryListCmd(t *testing.T) {
+	host, flags := setupRegistryBrowse(t)
+	ref := fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart", host)
+	table := fmt.Sprintf("%-*s\n%s\n", len(ref), "NAME", ref)
+
+	tests := []struct {
+		name      string
+		cmd       string
+		expect    string
+		wantError bool
+	}{
+		{
+			name:   "list the repositories of a registry",
+			cmd:    fmt.Sprintf("registry list %s %s", host, flags),
+			expect: table,
+		},
+		{
+			name:   "list the repositories of a namespace",
+			cmd:    fmt.Sprintf("registry ls oci://%s/u/ocitestuser %s", host, flags),
+			expect: table,
+		},
+		{
+			name:   "list an empty namespace",
+			cmd:    fmt.Sprintf("registry list %s/u/nobody %s", host, flags),
+			expect: "No repositories found\n",
+		},
+		{
+			name:   "list as json",
+			cmd:    fmt.Sprintf("registry list %s %s -o json", host, flags),
+			expect: fmt.Sprintf("[%q]\n", ref),
+		},
+		{
+			name:      "list without credentials",
+			cmd:       fmt.Sprintf("registry list %s --plain-http --registry-config %s", host, filepath.Join(t.TempDir(), "config.json")),
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, out, err := executeActionCommand(tt.cmd)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("expected error %t, got %v", tt.wantError, err)
+			}
+			if !tt.wantError && out != tt.expect {
+				t.Errorf("expected %q, got %q", tt.expect, out)
+			}
+		})
+	}
+}
+
+func TestRegistryTagsCmd(t *testing.T) {
+	host, flags := setupRegistryBrowse(t)
+
+	_, out, err := executeActionCommand(fmt.Sprintf("registry tags oci://%s/u/ocitestuser/oci-dependent-chart %s", host, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "VERSION\n0.1.0  \n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	if _, _, err := executeActionCommand(fmt.Sprintf("registry tags %s/u/ocitestuser/no-such-chart %s", host, flags)); err == nil {
+		t.Error("expected an error for a missing repository")
+	}
+
+	if _, _, err := executeActionCommand(fmt.Sprintf("registry tags https://%s/charts %s", host, flags)); err == nil {
+		t.Error("expected an error for a non-OCI reference")
+	}
+}
+
+func TestRegistrySearchCmd(t *testing.T) {
+	host, flags := setupRegistryBrowse(t)
+
+	_, out, err := executeActionCommand(fmt.Sprintf("registry search %s DEPENDENT %s --max-col-width 0", host, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := fmt.Sprintf("oci://%s/u/ocitestuser/oci-dependent-chart", host)
+	for _, want := range []string{"NAME", "CHART VERSION", ref, "0.1.0"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output %q", want, out)
+		}
+	}
+
+	_, out, err = executeActionCommand(fmt.Sprintf("registry search %s nothing-matches %s", host, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "No results found\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	_, out, err = executeActionCommand(fmt.Sprintf("registry search %s/u %s -o json", host, flags))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, fmt.Sprintf(`"name":%q,"version":"0.1.0"`, ref)) {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestRegistryListFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "registry list", false)
+	checkFileCompletion(t, "registry search", false)
+	checkFileCompletion(t, "registry tags", false)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const registrySearchDesc = `
+Search the charts hosted by an OCI registry.
+
+The repositories of the registry are listed with the catalog API (/v2/_catalog)
+and filtered by keyword, ignoring case. The latest version of each matching
+chart is shown along with its description. Repositories that do not hold Helm
+charts are skipped.
+
+    $ helm registry search localhost:5000 nginx
+    $ helm registry search localhost:5000/charts
+`
+
+type registrySearchOptions struct {
+	registryClientOptions
+	maxColWidth  uint
+	outputFormat output.Format
+}
+
+func newRegistrySearchCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	o := &registrySearchOptions{}
+
+	cmd := &cobra.Command{
+		Use:               "search HOST[/NAMESPACE] [KEYWORD]",
+		Short:             "search the charts of a registry",
+		Long:              registrySearchDesc,
+		Args:              require.MinimumNArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := o.setRegistryClient(cfg, out); err != nil {
+				return err
+			}
+			results, err := action.NewRegistrySearch(cfg).Run(args[0], strings.Join(args[1:], " "))
+			if err != nil {
+				return err
+			}
+			return o.outputFormat.Write(out, &registrySearchWriter{results: results, columnWidth: o.maxColWidth})
+		},
+	}
+
+	f := cmd.Flags()
+	o.addFlags(f)
+	f.UintVar(&o.maxColWidth, "max-col-width", 80, "maximum column width for output table")
+	bindOutputFlag(cmd, &o.outputFormat)
+
+	return cmd
+}
+
+type registrySearchWriter struct {
+	results     []*action.RegistrySearchResult
+	columnWidth uint
+}
+
+func (w *registrySearchWriter) WriteTable(out io.Writer) error {
+	if len(w.results) == 0 {
+		_, err := out.Write([]byte("No results found\n"))
+		if err != nil {
+			return fmt.Errorf("unable to write results: %w", err)
+		}
+		return nil
+	}
+	table := uitable.New()
+	table.MaxColWidth = w.columnWidth
+	table.AddRow("NAME", "CHART VERSION", "APP VERSION", "DESCRIPTION")
+	for _, r := range w.results {
+		table.AddRow(r.Ref, r.Chart.Version, r.Chart.AppVersion, r.Chart.Description)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *registrySearchWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.charts())
+}
+
+func (w *registrySearchWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.charts())
+}
+
+// charts returns the results in the format of helm search repo.
+func (w *registrySearchWriter) charts() []repoChartElement {
+	// Initialize the array so no results returns an empty array instead of null
+	chartList := make([]repoChartElement, 0, len(w.results))
+	for _, r := range w.results {
+		chartList = append(chartList, repoChartElement{r.Ref, r.Chart.Version, r.Chart.AppVersion, r.Chart.Description, nil})
+	}
+	return chartList
+}
@@ -0,0 +1,63 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const registryTagsDesc = `
+List the versions of a chart stored in an OCI registry, newest first.
+
+Tags that are not semantic versions are ignored.
+
+    $ helm registry tags oci://localhost:5000/charts/mychart
+`
+
+func newRegistryTagsCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	o := &registryClientOptions{}
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "tags REF",
+		Short:             "list the versions of a chart in a registry",
+		Long:              registryTagsDesc,
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := o.setRegistryClient(cfg, out); err != nil {
+				return err
+			}
+			tags, err := action.NewRegistryTags(cfg).Run(args[0])
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &registryListWriter{refs: tags, header: "VERSION", empty: "No versions found"})
+		},
+	}
+
+	o.addFlags(cmd.Flags())
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
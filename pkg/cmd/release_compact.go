@@ -0,0 +1,68 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const releaseCompactDesc = `
+This command rewrites the release records of the namespace that are stored in
+an older format.
+
+The Secret storage driver stores release records gzipped by default, and only
+compresses records larger than the size limit of a Secret with zstd, splitting
+them across several Secrets if needed. With HELM_DRIVER_SECRETS_COMPRESSION=zstd,
+it compresses every record with zstd, rewriting records whenever Helm updates
+them, and this command rewrites the remaining ones. Releases and their history
+are not changed.
+
+Note that earlier versions of Helm cannot read the rewritten records.
+`
+
+func newReleaseCompactCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseCompact(cfg)
+
+	cmd := &cobra.Command{
+		Use:               "compact",
+		Short:             "rewrite release records stored in an older format",
+		Long:              releaseCompactDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			keys, err := client.Run()
+			for _, key := range keys {
+				fmt.Fprintf(out, "Compacted release record %s\n", key)
+			}
+			if err != nil {
+				return err
+			}
+			if len(keys) == 0 {
+				fmt.Fprintln(out, "No release records to compact")
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,75 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func TestReleaseCompactCmd(t *testing.T) {
+	_, _, err := executeActionCommandC(storageFixture(), "release compact")
+	assert.ErrorContains(t, err, "the Memory storage driver does not support compaction")
+
+	secrets := fake.NewClientset().CoreV1().Secrets("default")
+	d := driver.NewSecrets(secrets)
+	store := storage.Init(d)
+
+	_, _, err = executeActionCommandC(store, "release compact")
+	assert.ErrorContains(t, err, "zstd compression is not enabled")
+
+	d.Zstd = true
+	_, out, err := executeActionCommandC(store, "release compact")
+	require.NoError(t, err)
+	assert.Equal(t, "No release records to compact\n", out)
+
+	// a release record stored uncompressed by an early version of Helm
+	rel := release.Mock(&release.MockReleaseOptions{Name: "angry-bird"})
+	b, err := json.Marshal(rel)
+	require.NoError(t, err)
+	_, err = secrets.Create(t.Context(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "sh.helm.release.v1.angry-bird.v1",
+			Labels: map[string]string{"name": "angry-bird", "owner": "helm", "status": "deployed", "version": "1"},
+		},
+		Type: "helm.sh/release.v1",
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(b))},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	_, out, err = executeActionCommandC(store, "release compact")
+	require.NoError(t, err)
+	assert.Equal(t, "Compacted release record sh.helm.release.v1.angry-bird.v1\n", out)
+
+	_, err = store.Get("angry-bird", 1)
+	require.NoError(t, err)
+
+	_, out, err = executeActionCommandC(store, "release compact")
+	require.NoError(t, err)
+	assert.Equal(t, "No release records to compact\n", out)
+}
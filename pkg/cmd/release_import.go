@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const releaseImportDesc = `
+This command imports a release exported with 'helm get all --output archive'.
+
+The release is stored in the namespace with the revision, status and content it
+was exported with, for example to migrate a release to another cluster. The
+resources of the release are not created: only the record of the release is
+restored. The import fails if a release of the same name already exists.
+
+    $ helm get all my-release --output archive my-release.tgz
+    $ helm release import my-release.tgz --kube-context other-cluster
+`
+
+func newReleaseImportCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseImport(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "import ARCHIVE",
+		Short: "import a release exported with 'helm get all --output archive'",
+		Long:  releaseImportDesc,
+		Args:  require.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			rel, err := action.ReadReleaseArchive(f)
+			if err != nil {
+				return err
+			}
+			client.Namespace = settings.Namespace()
+			rel, err = client.Run(rel)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Imported release %q revision %d into namespace %q\n", rel.Name, rel.Version, rel.Namespace)
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestReleaseExportImport(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "thomas-guide.tgz")
+
+	source := storageFixture()
+	rel := release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Version: 2, Labels: map[string]string{"team": "maps"}})
+	rel.Chart.Metadata.APIVersion = "v2"
+	require.NoError(t, source.Create(rel))
+
+	_, out, err := executeActionCommandC(source, fmt.Sprintf("get all thomas-guide --output archive %s", archive))
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("Exported release \"thomas-guide\" revision 2 to %s\n", archive), out)
+
+	target := storageFixture()
+	_, out, err = executeActionCommandC(target, fmt.Sprintf("release import %s", archive))
+	require.NoError(t, err)
+	assert.Equal(t, "Imported release \"thomas-guide\" revision 2 into namespace \"default\"\n", out)
+
+	reli, err := target.Get("thomas-guide", 2)
+	require.NoError(t, err)
+	imported, err := releaserToV1Release(reli)
+	require.NoError(t, err)
+	assert.Equal(t, rel.Manifest, imported.Manifest)
+	assert.Equal(t, rel.Labels, imported.Labels)
+
+	_, _, err = executeActionCommandC(target, fmt.Sprintf("release import %s", archive))
+	assert.ErrorContains(t, err, "release \"thomas-guide\" already exists")
+}
+
+func TestGetAllArchiveArgs(t *testing.T) {
+	_, _, err := executeActionCommandC(storageFixture(), "get all thomas-guide --output archive")
+	assert.ErrorContains(t, err, "requires 2 arguments")
+
+	_, _, err = executeActionCommandC(storageFixture(), "get all thomas-guide --output json")
+	assert.ErrorContains(t, err, "invalid output format \"json\"")
+}
+
+func TestReleaseImportFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "release import", true)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const releaseProtectDesc = `
+This command protects a release against uninstall.
+
+'helm uninstall' refuses to uninstall a protected release unless
+'--force-protected' is set. The protection is recorded in the release history,
+so that it is kept by upgrades and rollbacks. Use 'helm release unprotect' to
+remove it.
+
+Single resources can be protected with the 'helm.sh/resource-policy: protected'
+annotation, which protects the release of the resource in the same way.
+`
+
+const releaseUnprotectDesc = `
+This command removes the protection of a release against uninstall added with
+'helm release protect'.
+`
+
+func newReleaseProtectCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseProtect(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "protect RELEASE_NAME",
+		Short: "protect a release against uninstall",
+		Long:  releaseProtectDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListReleases(toComplete, args, cfg)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := client.Run(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Release %q is protected against uninstall\n", args[0])
+			return nil
+		},
+	}
+
+	addLockTimeoutFlag(cmd.Flags(), &client.LockTimeout)
+
+	return cmd
+}
+
+func newReleaseUnprotectCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseProtect(cfg)
+	client.Remove = true
+
+	cmd := &cobra.Command{
+		Use:   "unprotect RELEASE_NAME",
+		Short: "remove the protection of a release against uninstall",
+		Long:  releaseUnprotectDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListReleases(toComplete, args, cfg)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := client.Run(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Release %q is no longer protected against uninstall\n", args[0])
+			return nil
+		},
+	}
+
+	addLockTimeoutFlag(cmd.Flags(), &client.LockTimeout)
+
+	return cmd
+}
@@ -0,0 +1,71 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestReleaseProtectCmd(t *testing.T) {
+	protected := func() *release.Release {
+		rel := release.Mock(&release.MockReleaseOptions{Name: "guarded"})
+		rel.Labels = map[string]string{"helm.sh/protected": "true"}
+		return rel
+	}
+
+	tests := []cmdTestCase{
+		{
+			name:   "protect a release",
+			cmd:    "release protect aeneas",
+			golden: "output/release-protect.txt",
+			rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "aeneas"})},
+		},
+		{
+			name:      "protect a missing release",
+			cmd:       "release protect aeneas",
+			golden:    "output/release-protect-missing.txt",
+			wantError: true,
+		},
+		{
+			name:   "unprotect a release",
+			cmd:    "release unprotect guarded",
+			golden: "output/release-unprotect.txt",
+			rels:   []*release.Release{protected()},
+		},
+		{
+			name:      "uninstall a protected release",
+			cmd:       "uninstall guarded",
+			golden:    "output/uninstall-protected.txt",
+			rels:      []*release.Release{protected()},
+			wantError: true,
+		},
+		{
+			name:   "force uninstall a protected release",
+			cmd:    "uninstall guarded --force-protected",
+			golden: "output/uninstall-force-protected.txt",
+			rels:   []*release.Release{protected()},
+		},
+	}
+	runTestCmd(t, tests)
+}
+
+func TestReleaseProtectCompletion(t *testing.T) {
+	checkReleaseCompletion(t, "release protect", false)
+	checkReleaseCompletion(t, "release unprotect", false)
+}
@@ -20,7 +20,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -29,6 +32,7 @@ import (
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	release "helm.sh/helm/v4/pkg/release/v1"
 )
 
 const releaseTestHelp = `
@@ -36,13 +40,61 @@ The test command runs the tests for a release.
 
 The argument this command takes is the name of a deployed release.
 The tests to be run are defined in the chart that was installed.
+
+Tests run one at a time in the order of their weights. With '--parallel', tests
+of the same weight run at once, up to the given number. A test may set its own
+timeout with the 'helm.sh/hook-timeout' annotation, e.g. "2m", which overrides
+'--timeout'.
+
+With '--output junit=FILE', a JUnit XML report of the tests is written to FILE
+for CI tools to consume, in addition to the status of the release:
+
+    $ helm test myrelease --parallel 4 --output junit=report.xml
+
+With '--on-fail collect-diagnostics', when tests fail, the description and the
+logs of their pods and the recent events of the namespace are gathered into a
+diagnostics bundle directory under '--diagnostics-dir', whose path is printed.
+This runs after all tests are complete, but before any cleanup.
+
+With '--unit', the argument is the directory of a chart instead, and the unit
+tests of the chart are run client-side, without a cluster. The unit tests are
+the suites of assertions in the tests/*_test.yaml files of the chart, e.g.
+
+    suite: deployment
+    templates:
+      - templates/deployment.yaml
+    tests:
+      - it: sets the number of replicas
+        set:
+          replicaCount: 3
+        asserts:
+          - equal:
+              path: spec.replicas
+              value: 3
+          - matchSnapshot: {}
+
+The assertions are equal, notEqual, isNull, isNotNull, exists, notExists,
+matchRegex, contains, isKind, hasDocuments, matchSnapshot and failedTemplate.
+Snapshots are kept in tests/__snapshot__, and rewritten with
+'--update-snapshots'.
 `
 
+// onFailCollectDiagnostics is the --on-fail action collecting the
+// diagnostics of the failed tests.
+const onFailCollectDiagnostics = "collect-diagnostics"
+
+// junitOutputPrefix prefixes the file of the JUnit report in --output.
+const junitOutputPrefix = "junit="
+
 func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewReleaseTesting(cfg)
 	outfmt := output.Table
+	var junitReport string
 	var outputLogs bool
+	var onFail, diagnosticsDir string
 	var filter []string
+	var unit bool
+	unitTest := action.NewUnitTest()
 
 	cmd := &cobra.Command{
 		Use:   "test [RELEASE]",
@@ -53,9 +105,18 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 			if len(args) != 0 {
 				return noMoreArgsComp()
 			}
+			if unit {
+				return nil, cobra.ShellCompDirectiveFilterDirs
+			}
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) (returnError error) {
+			if unit {
+				return runUnitTests(out, unitTest, args[0])
+			}
+			if onFail != "" && onFail != onFailCollectDiagnostics {
+				return fmt.Errorf("invalid --on-fail action %q: the only action is %q", onFail, onFailCollectDiagnostics)
+			}
 			client.Namespace = settings.Namespace()
 			notName := regexp.MustCompile(`^!\s?name=`)
 			for _, f := range filter {
@@ -96,6 +157,12 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 				return err
 			}
 
+			if junitReport != "" {
+				if err := writeJUnitReport(client, rel, junitReport); err != nil {
+					return errors.Join(runErr, err)
+				}
+			}
+
 			if outputLogs {
 				// Print a newline to stdout to separate the output
 				fmt.Fprintln(out)
@@ -104,6 +171,16 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 				}
 			}
 
+			if runErr != nil && onFail == onFailCollectDiagnostics {
+				bundle, err := client.CollectDiagnostics(diagnosticsDir, rel)
+				if bundle != "" {
+					fmt.Fprintf(out, "\nDiagnostics collected in: %s\n", bundle)
+				}
+				if err != nil {
+					return errors.Join(runErr, fmt.Errorf("collecting diagnostics: %w", err))
+				}
+			}
+
 			return runErr
 		},
 	}
@@ -112,6 +189,112 @@ func newReleaseTestCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
 	f.BoolVar(&outputLogs, "logs", false, "dump the logs from test pods (this runs after all tests are complete, but before any cleanup)")
 	f.StringSliceVar(&filter, "filter", []string{}, "specify tests by attribute (currently \"name\") using attribute=value syntax or '!attribute=value' to exclude a test (can specify multiple or separate values with commas: name=test1,name=test2)")
+	f.StringVar(&onFail, "on-fail", "", "what to do when tests fail. If set to \""+onFailCollectDiagnostics+"\", gather the descriptions and logs of the pods of the failed tests and the events of the namespace into a diagnostics bundle")
+	f.StringVar(&diagnosticsDir, "diagnostics-dir", os.TempDir(), "the directory to create the diagnostics bundles in")
+	f.BoolVar(&unit, "unit", false, "run the unit tests of the chart in the directory given as argument client-side, instead of the tests of a release")
+	f.BoolVar(&unitTest.UpdateSnapshots, "update-snapshots", false, "with --unit, rewrite the snapshots that do not match instead of failing")
+	f.IntVar(&client.Parallel, "parallel", 1, "the number of tests of the same weight to run at once")
+	f.VarP(&testOutputValue{format: &outfmt, junit: &junitReport}, outputFlag, "o",
+		"prints the output in the specified format, or writes a JUnit XML report of the tests to a file with junit=FILE (can specify both). Allowed values: "+strings.Join(output.Formats(), ", ")+", "+junitOutputPrefix+"FILE")
+
+	err := cmd.RegisterFlagCompletionFunc(outputFlag, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		formats := append(output.Formats(), junitOutputPrefix)
+		sort.Strings(formats)
+		return formats, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = cmd.RegisterFlagCompletionFunc("on-fail", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{onFailCollectDiagnostics}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	return cmd
 }
+
+// runUnitTests runs the unit tests of a chart and prints their results.
+func runUnitTests(out io.Writer, unitTest *action.UnitTest, chartDir string) error {
+	results, err := unitTest.Run(chartDir, nil)
+	if err != nil {
+		return err
+	}
+
+	var suitesFailed, testsPassed, testsFailed int
+	for _, suite := range results {
+		status := "PASS"
+		if !suite.Passed() {
+			status = "FAIL"
+			suitesFailed++
+		}
+		fmt.Fprintf(out, "%s  %s\t%s\n", status, suite.Name, suite.File)
+		if suite.Err != nil {
+			fmt.Fprintf(out, "\t%v\n", suite.Err)
+		}
+		for _, test := range suite.Tests {
+			if test.Passed() {
+				testsPassed++
+				continue
+			}
+			testsFailed++
+			fmt.Fprintf(out, "\t- %s\n", test.Name)
+			for _, failure := range test.Failures {
+				fmt.Fprintf(out, "\t\t%s\n", strings.ReplaceAll(failure, "\n", "\n\t\t"))
+			}
+		}
+	}
+	fmt.Fprintf(out, "\nSuites: %d passed, %d failed, %d total\n", len(results)-suitesFailed, suitesFailed, len(results))
+	fmt.Fprintf(out, "Tests:  %d passed, %d failed, %d total\n", testsPassed, testsFailed, testsPassed+testsFailed)
+
+	if suitesFailed > 0 {
+		return errors.New("unit tests failed")
+	}
+	return nil
+}
+
+// writeJUnitReport writes the JUnit report of the tests of the release to the
+// named file.
+func writeJUnitReport(client *action.ReleaseTesting, rel *release.Release, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create the JUnit report: %w", err)
+	}
+	if err := client.WriteJUnitReport(f, rel); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// testOutputValue is the --output flag of 'helm test', which takes either an
+// output format or the file of a JUnit report.
+type testOutputValue struct {
+	format *output.Format
+	junit  *string
+}
+
+func (o *testOutputValue) String() string {
+	return string(*o.format)
+}
+
+func (o *testOutputValue) Type() string {
+	return "format"
+}
+
+func (o *testOutputValue) Set(s string) error {
+	if path, ok := strings.CutPrefix(s, junitOutputPrefix); ok {
+		if path == "" {
+			return errors.New("the JUnit report needs a file: junit=FILE")
+		}
+		*o.junit = path
+		return nil
+	}
+	outfmt, err := output.ParseFormat(s)
+	if err != nil {
+		return err
+	}
+	*o.format = outfmt
+	return nil
+}
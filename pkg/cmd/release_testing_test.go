@@ -19,6 +19,8 @@ package cmd
 import (
 	"bytes"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -79,3 +81,101 @@ func TestReleaseTestNotesHandling(t *testing.T) {
 		t.Errorf("Expected notes to be hidden by default, but found NOTES section in output: %s", output1)
 	}
 }
+
+func TestReleaseTestJUnitOutput(t *testing.T) {
+	rel := &release.Release{
+		Name:      "junit-release",
+		Namespace: "default",
+		Info:      &release.Info{Status: rcommon.StatusDeployed},
+		Chart:     &chart.Chart{Metadata: &chart.Metadata{Name: "test", Version: "1.0.0"}},
+		Hooks: []*release.Hook{{
+			Name:     "smoke",
+			Kind:     "Pod",
+			Path:     "templates/tests/smoke.yaml",
+			Manifest: "kind: Pod\nmetadata:\n  name: smoke\n",
+			Events:   []release.HookEvent{release.HookTest},
+		}},
+	}
+	store := storageFixture()
+	store.Create(rel)
+	actionConfig := &action.Configuration{
+		Releases:     store,
+		KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+		Capabilities: common.DefaultCapabilities,
+	}
+
+	report := filepath.Join(t.TempDir(), "report.xml")
+	var buf bytes.Buffer
+	cmd := newReleaseTestCmd(actionConfig, &buf)
+	cmd.SetArgs([]string{"junit-release", "--parallel", "2", "--output", "junit=" + report, "--output", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "{") {
+		t.Errorf("Expected the status as JSON, got: %s", buf.String())
+	}
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `<testcase name="smoke" classname="junit-release"`) {
+		t.Errorf("Expected the smoke test in the JUnit report, got: %s", data)
+	}
+}
+
+func TestReleaseTestInvalidOutput(t *testing.T) {
+	cmd := newReleaseTestCmd(&action.Configuration{}, io.Discard)
+	cmd.SetArgs([]string{"myrelease", "--output", "junit="})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "junit=FILE") {
+		t.Errorf("Expected an error about the JUnit report file, got: %v", err)
+	}
+}
+
+func TestReleaseTestInvalidOnFail(t *testing.T) {
+	cmd := newReleaseTestCmd(&action.Configuration{}, io.Discard)
+	cmd.SetArgs([]string{"myrelease", "--on-fail", "retry"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), `invalid --on-fail action "retry"`) {
+		t.Errorf("Expected an error about the --on-fail action, got: %v", err)
+	}
+}
+
+func TestReleaseTestUnit(t *testing.T) {
+	chartDir := t.TempDir()
+	files := map[string]string{
+		"Chart.yaml":               "apiVersion: v2\nname: unit\nversion: 0.1.0\n",
+		"values.yaml":              "replicas: 1\n",
+		"templates/deploy.yaml":    "kind: Deployment\nmetadata:\n  name: {{ .Release.Name }}\nspec:\n  replicas: {{ .Values.replicas }}\n",
+		"tests/passing_test.yaml":  "templates: [templates/deploy.yaml]\ntests:\n  - it: renders a deployment\n    asserts:\n      - isKind:\n          of: Deployment\n",
+		"tests/failing_test.yaml":  "templates: [templates/deploy.yaml]\ntests:\n  - it: sets the replicas\n    set:\n      replicas: 2\n    asserts:\n      - equal:\n          path: spec.replicas\n          value: 3\n",
+		"tests/snapshot_test.yaml": "templates: [templates/deploy.yaml]\ntests:\n  - it: matches the snapshot\n    asserts:\n      - matchSnapshot: {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(chartDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, out, err := executeActionCommand("test --unit " + chartDir)
+	if err == nil || err.Error() != "unit tests failed" {
+		t.Errorf("Expected the unit tests to fail, got: %v", err)
+	}
+	expected := `FAIL  failing	tests/failing_test.yaml
+	- sets the replicas
+		assertion 1 (equal): document 0 of templates/deploy.yaml: expected spec.replicas = 3, got spec.replicas = 2
+PASS  passing	tests/passing_test.yaml
+PASS  snapshot	tests/snapshot_test.yaml
+
+Suites: 2 passed, 1 failed, 3 total
+Tests:  2 passed, 1 failed, 3 total
+`
+	if !strings.HasPrefix(out, expected) {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, out)
+	}
+	if _, err := os.Stat(filepath.Join(chartDir, "tests", "__snapshot__", "snapshot_test.snap")); err != nil {
+		t.Errorf("Expected the snapshot to be written: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const releaseUnlockDesc = `
+This command removes the lock of a release.
+
+Install, upgrade, rollback and uninstall lock the release they operate on, so
+that concurrent operations on the same release fail instead of corrupting it.
+Locks of interrupted operations expire after a minute. Use this command to
+remove a lock right away. Make sure that no operation is running on the
+release, as the lock is removed regardless of the operation holding it.
+`
+
+func newReleaseUnlockCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewReleaseUnlock(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "unlock RELEASE_NAME",
+		Short: "remove the lock of a release",
+		Long:  releaseUnlockDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListReleases(toComplete, args, cfg)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			lock, err := client.Run(args[0])
+			if err != nil {
+				return err
+			}
+			if lock == nil {
+				fmt.Fprintf(out, "Release %q is not locked\n", args[0])
+				return nil
+			}
+			fmt.Fprintf(out, "Removed lock of release %q held by operation %s started at %s\n", args[0], lock.Operation, lock.Acquired.Format(time.RFC3339))
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,50 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"helm.sh/helm/v4/pkg/storage"
+)
+
+func TestReleaseUnlockCmd(t *testing.T) {
+	store := storageFixture()
+	_, _, err := executeActionCommandC(store, "release unlock angry-bird")
+	assert.ErrorContains(t, err, "the storage driver does not support release locks")
+
+	leases := fake.NewClientset().CoordinationV1().Leases("default")
+	store.Locker = storage.NewLeaseLocker(leases)
+
+	_, out, err := executeActionCommandC(store, "release unlock angry-bird")
+	require.NoError(t, err)
+	assert.Equal(t, "Release \"angry-bird\" is not locked\n", out)
+
+	_, _, err = storage.NewLeaseLocker(leases).Lock(t.Context(), "angry-bird", "upgrade", 0)
+	require.NoError(t, err)
+	_, out, err = executeActionCommandC(store, "release unlock angry-bird")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Removed lock of release \"angry-bird\" held by operation upgrade started at")
+}
+
+func TestReleaseUnlockCompletion(t *testing.T) {
+	checkReleaseCompletion(t, "release unlock", false)
+}
@@ -41,6 +41,13 @@ To merge the generated index with an existing index file, use the '--merge'
 flag. In this case, the charts found in the current directory will be merged
 into the index passed in with --merge, with local charts taking priority over
 existing charts.
+
+To let clients update their copy of a large repository by transferring only
+what changed, use the '--index-v2' flag. In addition to 'index.yaml', this
+writes a sharded index: 'index-v2.yaml', listing pages of charts, and the
+'index-v2' directory, holding the pages and the versions of each chart in
+files named after their digest. Clients fall back to 'index.yaml' when the
+repository does not serve a sharded index.
 `
 
 type repoIndexOptions struct {
@@ -48,6 +55,9 @@ type repoIndexOptions struct {
 	url   string
 	merge string
 	json  bool
+
+	indexV2         bool
+	indexV2PageSize int
 }
 
 func newRepoIndexCmd(out io.Writer) *cobra.Command {
@@ -76,6 +86,8 @@ func newRepoIndexCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&o.url, "url", "", "url of chart repository")
 	f.StringVar(&o.merge, "merge", "", "merge the generated index into the given index")
 	f.BoolVar(&o.json, "json", false, "output in JSON format")
+	f.BoolVar(&o.indexV2, "index-v2", false, "also write a sharded index that clients can update incrementally")
+	f.IntVar(&o.indexV2PageSize, "index-v2-page-size", repo.DefaultIndexV2PageSize, "number of charts listed on each page of the sharded index")
 
 	return cmd
 }
@@ -86,10 +98,20 @@ func (i *repoIndexOptions) run(_ io.Writer) error {
 		return err
 	}
 
-	return index(path, i.url, i.merge, i.json)
+	indexV2PageSize := 0
+	if i.indexV2 {
+		indexV2PageSize = i.indexV2PageSize
+		if indexV2PageSize <= 0 {
+			return errors.New("the page size of the sharded index must be positive")
+		}
+	}
+
+	return index(path, i.url, i.merge, i.json, indexV2PageSize)
 }
 
-func index(dir, url, mergeTo string, json bool) error {
+// index writes the index of the charts in dir. A sharded index is also
+// written when indexV2PageSize is positive.
+func index(dir, url, mergeTo string, json bool, indexV2PageSize int) error {
 	out := filepath.Join(dir, "index.yaml")
 
 	i, err := repo.IndexDirectory(dir, url)
@@ -111,7 +133,13 @@ func index(dir, url, mergeTo string, json bool) error {
 		i.Merge(i2)
 	}
 	i.SortEntries()
-	return writeIndexFile(i, out, json)
+	if err := writeIndexFile(i, out, json); err != nil {
+		return err
+	}
+	if indexV2PageSize > 0 {
+		return i.WriteV2(dir, indexV2PageSize, 0o644)
+	}
+	return nil
 }
 
 func writeIndexFile(i *repo.IndexFile, out string, json bool) error {
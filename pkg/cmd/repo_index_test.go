@@ -24,6 +24,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"sigs.k8s.io/yaml"
+
 	"helm.sh/helm/v4/pkg/repo/v1"
 )
 
@@ -161,6 +163,49 @@ func TestRepoIndexCmd(t *testing.T) {
 	}
 }
 
+func TestRepoIndexV2Cmd(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, chart := range []string{"compressedchart-0.1.0.tgz", "reqtest-0.1.0.tgz"} {
+		if err := linkOrCopy(filepath.Join("testdata/testcharts", chart), filepath.Join(dir, chart)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := newRepoIndexCmd(io.Discard)
+	c.ParseFlags([]string{"--index-v2", "--index-v2-page-size", "1"})
+	if err := c.RunE(c, []string{dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.LoadIndexFile(filepath.Join(dir, "index.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, repo.IndexV2FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := &repo.IndexV2File{}
+	if err := yaml.Unmarshal(b, root); err != nil {
+		t.Fatal(err)
+	}
+	if root.APIVersion != repo.APIVersionV2 || len(root.Pages) == 0 {
+		t.Errorf("unexpected root of the sharded index: %+v", root)
+	}
+	shards, err := filepath.Glob(filepath.Join(dir, repo.IndexV2Dir, "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := len(root.Pages) + 2; len(shards) != want {
+		t.Errorf("expected %d pages and shards, got %d", want, len(shards))
+	}
+
+	c.ParseFlags([]string{"--index-v2-page-size", "0"})
+	if err := c.RunE(c, []string{dir}); err == nil {
+		t.Error("expected an error with a page size of 0")
+	}
+}
+
 func linkOrCopy(source, target string) error {
 	if err := os.Link(source, target); err != nil {
 		return copyFile(source, target)
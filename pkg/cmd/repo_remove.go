@@ -87,6 +87,13 @@ func removeRepoCache(root, name string) error {
 		os.Remove(idx)
 	}
 
+	if err := os.RemoveAll(filepath.Join(root, helmpath.CacheIndexHeadersFile(name))); err != nil {
+		return fmt.Errorf("can't remove index caching headers: %w", err)
+	}
+	if err := os.RemoveAll(filepath.Join(root, helmpath.CacheIndexV2Dir(name))); err != nil {
+		return fmt.Errorf("can't remove sharded index cache: %w", err)
+	}
+
 	idx = filepath.Join(root, helmpath.CacheIndexFile(name))
 	if _, err := os.Stat(idx); errors.Is(err, fs.ErrNotExist) {
 		return nil
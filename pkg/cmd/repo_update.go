@@ -38,6 +38,10 @@ Information is cached locally, where it is used by commands like 'helm search'.
 You can optionally specify a list of repositories you want to update.
 	$ helm repo update <repo_name> ...
 To update all the repositories, use 'helm repo update'.
+
+Repositories are updated in parallel. Use '--parallel' to limit how many are
+updated at the same time. Indexes are fetched with conditional requests, so
+the index of a repository that did not change is not downloaded again.
 `
 
 var errNoRepositories = errors.New("no repositories found. You must add one before updating")
@@ -48,10 +52,14 @@ type repoUpdateOptions struct {
 	repoCache string
 	names     []string
 	timeout   time.Duration
+	parallel  int
 }
 
 func newRepoUpdateCmd(out io.Writer) *cobra.Command {
-	o := &repoUpdateOptions{update: updateCharts}
+	o := &repoUpdateOptions{}
+	o.update = func(repos []*repo.ChartRepository, out io.Writer) error {
+		return updateChartsInParallel(repos, out, o.parallel)
+	}
 
 	cmd := &cobra.Command{
 		Use:     "update [REPO1 [REPO2 ...]]",
@@ -66,12 +74,16 @@ func newRepoUpdateCmd(out io.Writer) *cobra.Command {
 			o.repoFile = settings.RepositoryConfig
 			o.repoCache = settings.RepositoryCache
 			o.names = args
+			if o.parallel < 0 {
+				return errors.New("the number of repositories to update in parallel cannot be negative")
+			}
 			return o.run(out)
 		},
 	}
 
 	f := cmd.Flags()
 	f.DurationVar(&o.timeout, "timeout", getter.DefaultHTTPTimeout*time.Second, "time to wait for the index file download to complete")
+	f.IntVar(&o.parallel, "parallel", 0, "maximum number of repositories to update at the same time. 0 updates all of them at once")
 
 	return cmd
 }
@@ -114,15 +126,27 @@ func (o *repoUpdateOptions) run(out io.Writer) error {
 }
 
 func updateCharts(repos []*repo.ChartRepository, out io.Writer) error {
+	return updateChartsInParallel(repos, out, 0)
+}
+
+// updateChartsInParallel updates at most parallel repositories at the same
+// time, or all of them at once when parallel is 0.
+func updateChartsInParallel(repos []*repo.ChartRepository, out io.Writer, parallel int) error {
 	fmt.Fprintln(out, "Hang tight while we grab the latest from your chart repositories...")
+	if parallel <= 0 {
+		parallel = len(repos)
+	}
 	var wg sync.WaitGroup
 	failRepoURLChan := make(chan string, len(repos))
+	sem := make(chan struct{}, parallel)
 
 	writeMutex := sync.Mutex{}
 	for _, re := range repos {
 		wg.Add(1)
 		go func(re *repo.ChartRepository) {
 			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 			if _, err := re.DownloadIndexFile(); err != nil {
 				writeMutex.Lock()
 				defer writeMutex.Unlock()
@@ -19,13 +19,18 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"helm.sh/helm/v4/internal/test/ensure"
 	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/repo/v1"
 	"helm.sh/helm/v4/pkg/repo/v1/repotest"
 )
@@ -127,6 +132,38 @@ func TestUpdateCustomCacheCmd(t *testing.T) {
 	}
 }
 
+func TestUpdateIndexV2Cmd(t *testing.T) {
+	cachePath := t.TempDir()
+
+	ts := repotest.NewTempServer(
+		t,
+		repotest.WithChartSourceGlob("testdata/testcharts/*.tgz"),
+		repotest.WithIndexV2(1),
+	)
+	defer ts.Stop()
+
+	o := &repoUpdateOptions{
+		update:    updateCharts,
+		repoFile:  filepath.Join(ts.Root(), "repositories.yaml"),
+		repoCache: cachePath,
+	}
+	for range 2 {
+		if err := o.run(io.Discard); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cachePath, helmpath.CacheIndexV2Dir("test"), repo.IndexV2FileName)); err != nil {
+		t.Fatalf("error finding the cached sharded index: %v", err)
+	}
+	index, err := repo.LoadIndexFile(filepath.Join(cachePath, "test-index.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !index.Has("compressedchart", "0.3.0") {
+		t.Errorf("expected the index assembled from the sharded index to have compressedchart 0.3.0, got %v", index.Entries)
+	}
+}
+
 func TestUpdateCharts(t *testing.T) {
 	defer resetEnv()()
 	ensure.HelmHome(t)
@@ -156,6 +193,73 @@ func TestUpdateCharts(t *testing.T) {
 	}
 }
 
+func TestUpdateChartsInParallel(t *testing.T) {
+	defer resetEnv()()
+	ensure.HelmHome(t)
+
+	index, err := os.ReadFile("testdata/testserver/index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Write(index)
+	}))
+	defer ts.Close()
+
+	var repos []*repo.ChartRepository
+	for i := range 4 {
+		r, err := repo.NewChartRepository(&repo.Entry{
+			Name: fmt.Sprintf("charts%d", i),
+			URL:  ts.URL,
+		}, getter.All(settings))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.CachePath = t.TempDir()
+		repos = append(repos, r)
+	}
+
+	b := bytes.NewBuffer(nil)
+	if err := updateChartsInParallel(repos, b, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(b.String(), "Successfully got an update"); got != 4 {
+		t.Errorf("expected 4 repositories to be updated, got %d: %q", got, b.String())
+	}
+	if maxInFlight != 1 {
+		t.Errorf("expected 1 repository to be updated at a time, got %d", maxInFlight)
+	}
+
+	maxInFlight = 0
+	if err := updateChartsInParallel(repos, io.Discard, 0); err != nil {
+		t.Fatal(err)
+	}
+	if maxInFlight < 2 {
+		t.Errorf("expected repositories to be updated at the same time, got %d at most", maxInFlight)
+	}
+}
+
+func TestUpdateCmdParallelInvalid(t *testing.T) {
+	_, _, err := executeActionCommand("repo update --parallel -1")
+	if err == nil || !strings.Contains(err.Error(), "cannot be negative") {
+		t.Errorf("expected an error for a negative --parallel, got %v", err)
+	}
+}
+
 func TestRepoUpdateFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "repo update", false)
 	checkFileCompletion(t, "repo update repo1", false)
@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
@@ -26,6 +27,7 @@ import (
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/secrets"
 )
 
 const rollbackDesc = `
@@ -36,10 +38,24 @@ second is a revision (version) number. If this argument is omitted or set to
 0, it will roll back to the previous release.
 
 To see revision numbers, run 'helm history RELEASE'.
+
+With '--to-values-only', only the values of the revision are restored: they are
+rendered with the chart of the current revision rather than the chart of the
+revision rolled back to. Use it to undo a bad change of values while keeping
+the current chart version.
+
+The --diff flag prints the changes the rollback makes to the manifest of the
+deployed release, resource by resource, before performing it. The values of
+Secrets are redacted. To only preview the changes without rolling back, use
+'--dry-run=diff':
+
+    $ helm rollback --dry-run=diff redis 3
 `
 
 func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewRollback(cfg)
+	var showDiff bool
+	var resolveSecrets bool
 
 	cmd := &cobra.Command{
 		Use:   "rollback <RELEASE> [REVISION]",
@@ -66,14 +82,35 @@ func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				client.Version = ver
 			}
 
-			dryRunStrategy, err := cmdGetDryRunFlagStrategy(cmd, false)
-			if err != nil {
-				return err
+			// '--dry-run=diff' is a server-side dry run that only prints the diff
+			diffOnly := cmd.Flag("dry-run").Value.String() == "diff"
+			if diffOnly {
+				client.DryRunStrategy = action.DryRunServer
+			} else {
+				dryRunStrategy, err := cmdGetDryRunFlagStrategy(cmd, false)
+				if err != nil {
+					return err
+				}
+				if dryRunStrategy == action.DryRunServerAdmission {
+					return errors.New("--dry-run=server-admission is not supported by rollback")
+				}
+				client.DryRunStrategy = dryRunStrategy
+			}
+			if resolveSecrets {
+				client.SecretProviders = secrets.Default()
+			}
+
+			if showDiff || diffOnly {
+				client.DiffOutput = out
+				client.DiffNoColor = settings.ShouldDisableColor()
 			}
-			client.DryRunStrategy = dryRunStrategy
 
 			if err := client.Run(args[0]); err != nil {
-				return err
+				return withForceConflictsHint(err, client.ForceConflicts)
+			}
+
+			if diffOnly {
+				return nil
 			}
 
 			fmt.Fprint(out, "Rollback was a success! Happy Helming!\n")
@@ -87,12 +124,20 @@ func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.MarkDeprecated("force", "use --force-replace instead")
 	f.BoolVar(&client.ForceConflicts, "force-conflicts", false, "if set server-side apply will force changes against conflicts")
 	f.StringVar(&client.ServerSideApply, "server-side", "auto", "must be \"true\", \"false\" or \"auto\". Object updates run in the server instead of the client (\"auto\" defaults the value from the previous chart release's method)")
+	addApplyMethodFlagWithAuto(cmd, &client.ServerSideApply)
+	f.BoolVar(&client.ValuesOnly, "to-values-only", false, "restore only the values of the revision, rendering them with the chart of the current revision")
+	f.BoolVar(&resolveSecrets, "resolve-secrets", false, "resolve the references to secrets, such as vault://path#field, in the restored values when rendering them with --to-values-only")
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during rollback")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	addLockTimeoutFlag(f, &client.LockTimeout)
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this rollback when rollback fails")
 	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	addHistoryRetentionFlags(f, &client.HistoryRetention)
+	f.BoolVar(&showDiff, "diff", false, "print a diff of the manifest changes before rolling back. Use '--dry-run=diff' to only print the diff")
+	f.IntVar(&client.DiffContext, "diff-context", client.DiffContext, "number of unchanged lines to show around each change in the diff")
 	addDryRunFlag(cmd)
+	f.Lookup("dry-run").Usage += ` Rollback also accepts "diff": '--dry-run=diff' simulates the rollback on the server and only prints a diff of the manifest changes, with the values of Secrets redacted.`
 	AddWaitFlag(cmd, &client.WaitStrategy)
 	cmd.MarkFlagsMutuallyExclusive("force-replace", "force-conflicts")
 	cmd.MarkFlagsMutuallyExclusive("force", "force-conflicts")
@@ -62,6 +62,46 @@ func TestRollbackCmd(t *testing.T) {
 		cmd:    "rollback funny-honey 1 --wait --wait-for-jobs",
 		golden: "output/rollback-wait-for-jobs.txt",
 		rels:   rels,
+	}, {
+		name:   "rollback the values of a release",
+		cmd:    "rollback funny-honey 1 --to-values-only",
+		golden: "output/rollback.txt",
+		rels: []*release.Release{
+			{
+				Name:    "funny-honey",
+				Info:    &release.Info{Status: common.StatusSuperseded},
+				Chart:   &chart.Chart{Metadata: &chart.Metadata{APIVersion: "v2", Name: "honey", Version: "0.1.0"}},
+				Config:  map[string]any{"flavor": "acacia"},
+				Version: 1,
+			},
+			{
+				Name:    "funny-honey",
+				Info:    &release.Info{Status: common.StatusDeployed},
+				Chart:   &chart.Chart{Metadata: &chart.Metadata{APIVersion: "v2", Name: "honey", Version: "0.2.0"}},
+				Config:  map[string]any{"flavor": "buckwheat"},
+				Version: 2,
+			},
+		},
+	}, {
+		name:   "rollback a release with a diff preview",
+		cmd:    "rollback funny-honey 1 --dry-run=diff",
+		golden: "output/rollback-dry-run-diff.txt",
+		rels: []*release.Release{
+			{
+				Name:     "funny-honey",
+				Info:     &release.Info{Status: common.StatusSuperseded},
+				Chart:    &chart.Chart{},
+				Manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: honey\ndata:\n  flavor: acacia\n",
+				Version:  1,
+			},
+			{
+				Name:     "funny-honey",
+				Info:     &release.Info{Status: common.StatusDeployed},
+				Chart:    &chart.Chart{},
+				Manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: honey\ndata:\n  flavor: buckwheat\n",
+				Version:  2,
+			},
+		},
 	}, {
 		name:   "rollback a release without revision",
 		cmd:    "rollback funny-honey",
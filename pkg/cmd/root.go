@@ -41,6 +41,7 @@ import (
 	"helm.sh/helm/v4/pkg/registry"
 	ri "helm.sh/helm/v4/pkg/release"
 	release "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/renderhook"
 	"helm.sh/helm/v4/pkg/repo/v1"
 	"helm.sh/helm/v4/pkg/storage/driver"
 )
@@ -62,13 +63,16 @@ Environment variables:
 | $HELM_CONFIG_HOME                  | set an alternative location for storing Helm configuration.                                                |
 | $HELM_DATA_HOME                    | set an alternative location for storing Helm data.                                                         |
 | $HELM_DEBUG                        | indicate whether or not Helm is running in Debug mode                                                      |
-| $HELM_DRIVER                       | set the backend storage driver. Values are: configmap, secret, memory, sql.                                |
+| $HELM_DRIVER                       | set the backend storage driver. Values are: configmap, secret, memory, sql, plugin:NAME.                   |
+| $HELM_DRIVER_SECRETS_COMPRESSION   | set the compression of the Secret storage driver. Values are: gzip (default), zstd.                        |
 | $HELM_DRIVER_SQL_CONNECTION_STRING | set the connection string the SQL storage driver should use.                                               |
 | $HELM_MAX_HISTORY                  | set the maximum number of helm release history.                                                            |
 | $HELM_NAMESPACE                    | set the namespace used for the helm operations.                                                            |
 | $HELM_NO_PLUGINS                   | disable plugins. Set HELM_NO_PLUGINS=1 to disable plugins.                                                 |
 | $HELM_PLUGINS                      | set the path to the plugins directory                                                                      |
 | $HELM_REGISTRY_CONFIG              | set the path to the registry config file.                                                                  |
+| $HELM_REGISTRIES_CONFIG            | set the path to the file of the registry mirrors and of the auth and TLS settings of each registry.        |
+| $HELM_RENDER_HOOKS_CONFIG          | set the path to the file of the pre-render and post-render hooks run on every rendered chart.              |
 | $HELM_REPOSITORY_CACHE             | set the path to the repository cache directory                                                             |
 | $HELM_REPOSITORY_CONFIG            | set the path to the repositories file.                                                                     |
 | $KUBECONFIG                        | set an alternative Kubernetes configuration file (default "~/.kube/config")                                |
@@ -117,6 +121,11 @@ func NewRootCmd(out io.Writer, args []string, logSetup func(bool)) (*cobra.Comma
 			loadReleasesInMemory(actionConfig)
 		}
 		actionConfig.SetHookOutputFunc(hookOutputWriter)
+		hooks, err := renderhook.Load(settings.RenderHooksConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+		actionConfig.RenderHooks = hooks
 	})
 	return cmd, nil
 }
@@ -266,21 +275,27 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 	// Add subcommands
 	cmd.AddCommand(
 		// chart commands
-		newCreateCmd(out),
+		newCreateCmd(actionConfig, out),
+		newChartCmd(actionConfig, out),
 		newDependencyCmd(actionConfig, out),
 		newPullCmd(actionConfig, out),
 		newShowCmd(actionConfig, out),
 		newLintCmd(out),
 		newPackageCmd(out),
+		newSchemaCmd(out),
 		newRepoCmd(out),
 		newSearchCmd(out),
 		newVerifyCmd(out),
+		newCacheCmd(out),
 
 		// release commands
+		newAdoptCmd(actionConfig, out),
+		newDriftCmd(actionConfig, out),
 		newGetCmd(actionConfig, out),
 		newHistoryCmd(actionConfig, out),
 		newInstallCmd(actionConfig, out),
 		newListCmd(actionConfig, out),
+		newReleaseCmd(actionConfig, out),
 		newReleaseTestCmd(actionConfig, out),
 		newRollbackCmd(actionConfig, out),
 		newStatusCmd(actionConfig, out),
@@ -418,12 +433,18 @@ func newRegistryClient(
 }
 
 func newDefaultRegistryClient(out io.Writer, plainHTTP bool, username, password string) (*registry.Client, error) {
+	registriesConfig, err := registry.LoadRegistriesConfig(settings.RegistriesConfig)
+	if err != nil {
+		return nil, err
+	}
 	opts := []registry.ClientOption{
 		registry.ClientOptDebug(settings.Debug),
 		registry.ClientOptEnableCache(true),
 		registry.ClientOptWriter(out),
 		registry.ClientOptCredentialsFile(settings.RegistryConfig),
 		registry.ClientOptBasicAuth(username, password),
+		registry.ClientOptRegistriesConfig(registriesConfig),
+		registry.ClientOptCloudAuth(true),
 	}
 	if plainHTTP {
 		opts = append(opts, registry.ClientOptPlainHTTP())
@@ -449,6 +470,10 @@ func newRegistryClientWithTLS(
 	if err != nil {
 		return nil, fmt.Errorf("can't create TLS config for client: %w", err)
 	}
+	registriesConfig, err := registry.LoadRegistriesConfig(settings.RegistriesConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create a new registry client
 	registryClient, err := registry.NewClient(
@@ -463,6 +488,8 @@ func newRegistryClientWithTLS(
 			},
 		}),
 		registry.ClientOptBasicAuth(username, password),
+		registry.ClientOptRegistriesConfig(registriesConfig),
+		registry.ClientOptCloudAuth(true),
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const schemaGenDesc = `
+Generate a values.schema.json skeleton from the values.yaml file of a chart.
+
+The schema describes the type of every value, and the default of every scalar
+value. The comments above a key, or at the end of its line, become the
+description of the value:
+
+    # Number of replicas of the deployment
+    replicaCount: 1
+
+The schema is printed, or written to the values.schema.json file of the chart
+with '--write'. It is a starting point: review it, and add constraints such as
+required values and enums before publishing the chart.
+`
+
+func newSchemaGenCmd(out io.Writer) *cobra.Command {
+	var write, force bool
+
+	cmd := &cobra.Command{
+		Use:   "gen CHART",
+		Short: "generate a values schema from the values of a chart",
+		Long:  schemaGenDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				// Allow file completion when completing the argument for the directory
+				return nil, cobra.ShellCompDirectiveDefault
+			}
+			// No more completions, so disable file completion
+			return noMoreArgsComp()
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			values, err := os.ReadFile(filepath.Join(args[0], chartutil.ValuesfileName))
+			if err != nil {
+				return fmt.Errorf("unable to read the values of the chart: %w", err)
+			}
+			schema, err := chartutil.GenerateValuesSchema(values)
+			if err != nil {
+				return err
+			}
+			if !write {
+				_, err := out.Write(schema)
+				return err
+			}
+
+			file := filepath.Join(args[0], chartutil.SchemafileName)
+			if _, err := os.Stat(file); err == nil && !force {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", file)
+			} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			if err := os.WriteFile(file, schema, 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Wrote %s\n", file)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&write, "write", false, "write the schema to the values.schema.json file of the chart")
+	f.BoolVar(&force, "force", false, "overwrite an existing values.schema.json file when used with --write")
+
+	return cmd
+}
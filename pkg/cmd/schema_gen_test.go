@@ -0,0 +1,60 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaGenCmd(t *testing.T) {
+	tests := []cmdTestCase{
+		{
+			name:   "generate a values schema",
+			cmd:    "schema gen testdata/testcharts/chart-for-schema-gen",
+			golden: "output/schema-gen.txt",
+		},
+		{
+			name:      "generate a values schema without values",
+			cmd:       "schema gen testdata/testcharts/does-not-exist",
+			golden:    "output/schema-gen-no-values.txt",
+			wantError: true,
+		},
+	}
+	runTestCmd(t, tests)
+}
+
+func TestSchemaGenCmdWrite(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("replicaCount: 1\n"), 0644))
+
+	_, _, err := executeActionCommand("schema gen --write " + dir)
+	require.NoError(t, err)
+	schema, err := os.ReadFile(filepath.Join(dir, "values.schema.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(schema), `"replicaCount"`)
+
+	_, _, err = executeActionCommand("schema gen --write " + dir)
+	require.ErrorContains(t, err, "already exists, use --force to overwrite it")
+
+	_, _, err = executeActionCommand("schema gen --write --force " + dir)
+	require.NoError(t, err)
+}
@@ -0,0 +1,244 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Query is a search query made of free text and field filters.
+//
+// A field filter is a term of the form 'field:value', such as 'name:nginx' or
+// 'appVersion:>=1.25'. Charts must match all the field filters of a query, in
+// addition to its free text. Values containing spaces can be quoted.
+type Query struct {
+	// Text is the free text of the query, matched as with Index.Search.
+	Text string
+	// Regexp reports whether the free text and the values of the field
+	// filters are regular expressions.
+	Regexp bool
+
+	filters []queryFilter
+}
+
+// queryFilter reports whether a search result matches a field filter.
+type queryFilter func(i *Index, r *Result) bool
+
+// queryFields are the fields that can be filtered on, with the functions
+// building their filter from a value.
+var queryFields = map[string]func(value string, regexp bool) (queryFilter, error){
+	"name": stringFilter(func(r *Result) []string { return []string{r.Chart.Name} }),
+	"repo": stringFilter(func(r *Result) []string {
+		repo, _, _ := strings.Cut(r.Name, "/")
+		return []string{repo}
+	}),
+	"description": stringFilter(func(r *Result) []string { return []string{r.Chart.Description} }),
+	"keyword":     stringFilter(func(r *Result) []string { return r.Chart.Keywords }),
+	"keywords":    stringFilter(func(r *Result) []string { return r.Chart.Keywords }),
+	"maintainer":  stringFilter(maintainers),
+	"maintainers": stringFilter(maintainers),
+	"home":        stringFilter(func(r *Result) []string { return []string{r.Chart.Home} }),
+	"source":      stringFilter(func(r *Result) []string { return r.Chart.Sources }),
+	"sources":     stringFilter(func(r *Result) []string { return r.Chart.Sources }),
+	"type": stringFilter(func(r *Result) []string {
+		if r.Chart.Type == "" {
+			return []string{"application"}
+		}
+		return []string{r.Chart.Type}
+	}),
+	"version":    versionFilter(func(r *Result) string { return r.Chart.Version }),
+	"appversion": versionFilter(func(r *Result) string { return r.Chart.AppVersion }),
+	"deprecated": deprecatedFilter,
+}
+
+// ParseQuery parses a search query.
+//
+// Terms of the form 'field:value' are field filters when field is one of
+// name, repo, description, keyword(s), maintainer(s), home, source(s), type,
+// version, appVersion or deprecated. Field names are case-insensitive. All
+// the other terms make up the free text of the query.
+//
+// Values of the version and appVersion fields are semantic version
+// constraints, and values of the deprecated field are booleans matched
+// against the latest version of charts. Values of other fields are matched
+// case-insensitively as substrings, or as regular expressions when regexp is
+// true or when they are enclosed in slashes, like 'name:/^nginx$/'.
+func ParseQuery(query string, regexp bool) (*Query, error) {
+	q := &Query{Regexp: regexp}
+	var text []string
+	for _, term := range splitQuery(query) {
+		field, value, ok := strings.Cut(term, ":")
+		newFilter, known := queryFields[strings.ToLower(field)]
+		if !ok || !known {
+			text = append(text, term)
+			continue
+		}
+		filter, err := newFilter(value, regexp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", field, err)
+		}
+		q.filters = append(q.filters, filter)
+	}
+
+	// The free text of queries without field filters is kept as is.
+	if len(q.filters) == 0 {
+		q.Text = query
+	} else {
+		q.Text = strings.Join(text, " ")
+	}
+	return q, nil
+}
+
+// splitQuery splits a query into terms separated by spaces, outside of double
+// quotes. The quotes are removed.
+func splitQuery(query string) []string {
+	var terms []string
+	var term strings.Builder
+	quoted, inTerm := false, false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inTerm = true
+		case unicode.IsSpace(r) && !quoted:
+			if inTerm {
+				terms = append(terms, term.String())
+				term.Reset()
+				inTerm = false
+			}
+		default:
+			term.WriteRune(r)
+			inTerm = true
+		}
+	}
+	if inTerm {
+		terms = append(terms, term.String())
+	}
+	return terms
+}
+
+// matches reports whether a search result matches all the field filters of
+// the query.
+func (q *Query) matches(i *Index, r *Result) bool {
+	for _, filter := range q.filters {
+		if !filter(i, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// stringFilter returns a function building a filter that matches the results
+// for which any of the strings returned by values matches.
+func stringFilter(values func(r *Result) []string) func(string, bool) (queryFilter, error) {
+	return func(value string, isRegexp bool) (queryFilter, error) {
+		var match func(string) bool
+		if pattern, ok := strings.CutPrefix(value, "/"); ok && len(pattern) > 0 && strings.HasSuffix(pattern, "/") {
+			value, isRegexp = strings.TrimSuffix(pattern, "/"), true
+		}
+		if isRegexp {
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, err
+			}
+			match = re.MatchString
+		} else {
+			value = strings.ToLower(value)
+			match = func(s string) bool { return strings.Contains(strings.ToLower(s), value) }
+		}
+		return func(_ *Index, r *Result) bool {
+			return slices.ContainsFunc(values(r), match)
+		}, nil
+	}
+}
+
+// versionFilter returns a function building a filter that matches the results
+// for which the version returned by version satisfies a constraint.
+func versionFilter(version func(r *Result) string) func(string, bool) (queryFilter, error) {
+	return func(value string, _ bool) (queryFilter, error) {
+		constraint, err := semver.NewConstraint(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(_ *Index, r *Result) bool {
+			v, err := semver.NewVersion(version(r))
+			return err == nil && constraint.Check(v)
+		}, nil
+	}
+}
+
+// deprecatedFilter builds a filter that matches the results of charts whose
+// latest version is, or is not, deprecated.
+func deprecatedFilter(value string, _ bool) (queryFilter, error) {
+	deprecated, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return func(i *Index, r *Result) bool {
+		latest, ok := i.latest[r.Name]
+		if !ok {
+			latest = r.Chart
+		}
+		return latest.Deprecated == deprecated
+	}, nil
+}
+
+// maintainers returns the names and emails of the maintainers of a chart.
+func maintainers(r *Result) []string {
+	var values []string
+	for _, m := range r.Chart.Maintainers {
+		if m != nil {
+			values = append(values, m.Name, m.Email)
+		}
+	}
+	return values
+}
+
+// Query searches the index for the charts matching the given query.
+//
+// Threshold applies to the free text of the query, as with Search. Queries
+// without free text match all the charts in the index against their field
+// filters.
+func (i *Index) Query(q *Query, threshold int) ([]*Result, error) {
+	var res []*Result
+	if q.Text == "" {
+		res = i.All()
+	} else {
+		var err error
+		if res, err = i.Search(q.Text, threshold, q.Regexp); err != nil {
+			return nil, err
+		}
+	}
+	if len(q.filters) == 0 {
+		return res, nil
+	}
+
+	filtered := res[:0]
+	for _, r := range res {
+		if q.matches(i, r) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered, nil
+}
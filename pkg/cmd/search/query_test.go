@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	"slices"
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/repo/v1"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		regexp  bool
+		text    string
+		filters int
+		fail    bool
+	}{
+		{query: "nginx ingress", text: "nginx ingress"},
+		{query: "  spaced  ", text: "  spaced  "},
+		{query: "foo:bar", text: "foo:bar"},
+		{query: "name:nginx", filters: 1},
+		{query: "nginx Keywords:ingress maintainer:foo", text: "nginx", filters: 2},
+		{query: `description:"web server" proxy`, text: "proxy", filters: 1},
+		{query: "appVersion:>=1.25 version:^1", filters: 2},
+		{query: "appVersion:not-a-constraint", fail: true},
+		{query: "deprecated:false", filters: 1},
+		{query: "deprecated:maybe", fail: true},
+		{query: "name:/^ngin[x$/", fail: true},
+		{query: "name:ngin[x", regexp: true, fail: true},
+		{query: "name:ngin[x", filters: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query, tt.regexp)
+			if tt.fail {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.Text != tt.text {
+				t.Errorf("Expected text %q, got %q", tt.text, q.Text)
+			}
+			if len(q.filters) != tt.filters {
+				t.Errorf("Expected %d filters, got %d", tt.filters, len(q.filters))
+			}
+		})
+	}
+}
+
+func TestIndexQuery(t *testing.T) {
+	i := NewIndex()
+	i.AddRepo("stable", &repo.IndexFile{Entries: map[string]repo.ChartVersions{
+		"nginx": {
+			{Metadata: &chart.Metadata{
+				Name:        "nginx",
+				Version:     "2.0.0",
+				AppVersion:  "1.27.0",
+				Description: "NGINX web server",
+				Keywords:    []string{"ingress", "proxy"},
+				Maintainers: []*chart.Maintainer{{Name: "Foo Bar", Email: "foo@example.com"}},
+			}},
+			{Metadata: &chart.Metadata{
+				Name:        "nginx",
+				Version:     "1.0.0",
+				AppVersion:  "1.21.0",
+				Description: "NGINX web server",
+				Keywords:    []string{"ingress", "proxy"},
+			}},
+		},
+		"haproxy": {
+			{Metadata: &chart.Metadata{
+				Name:        "haproxy",
+				Version:     "1.0.0",
+				AppVersion:  "v2.8",
+				Description: "HAProxy load balancer",
+				Keywords:    []string{"ingress"},
+				Deprecated:  true,
+			}},
+		},
+		"common": {
+			{Metadata: &chart.Metadata{
+				Name:    "common",
+				Version: "1.0.0",
+				Type:    "library",
+			}},
+		},
+	}}, true)
+
+	tests := []struct {
+		query  string
+		regexp bool
+		expect []string
+	}{
+		{query: "", expect: []string{"stable/common", "stable/haproxy", "stable/nginx@1.0.0", "stable/nginx@2.0.0"}},
+		{query: "keywords:ingress", expect: []string{"stable/haproxy", "stable/nginx@1.0.0", "stable/nginx@2.0.0"}},
+		{query: "keywords:INGRESS name:nginx", expect: []string{"stable/nginx@1.0.0", "stable/nginx@2.0.0"}},
+		{query: "maintainer:foo@example", expect: []string{"stable/nginx@2.0.0"}},
+		{query: "appVersion:>=1.25", expect: []string{"stable/haproxy", "stable/nginx@2.0.0"}},
+		{query: "keywords:ingress deprecated:false", expect: []string{"stable/nginx@1.0.0", "stable/nginx@2.0.0"}},
+		{query: "deprecated:true", expect: []string{"stable/haproxy"}},
+		{query: "name:/^(ha|ng)/ version:<2", expect: []string{"stable/haproxy", "stable/nginx@1.0.0"}},
+		{query: "name:prox.$", regexp: true, expect: []string{"stable/haproxy"}},
+		{query: "type:library", expect: []string{"stable/common"}},
+		{query: "type:application repo:stable balancer", expect: []string{"stable/haproxy"}},
+		{query: `description:"web server"`, expect: []string{"stable/nginx@1.0.0", "stable/nginx@2.0.0"}},
+		{query: "repo:incubator", expect: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := ParseQuery(tt.query, tt.regexp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := i.Query(q, 25)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, r := range res {
+				name := r.Name
+				if r.Name == "stable/nginx" {
+					name += "@" + r.Chart.Version
+				}
+				got = append(got, name)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.expect) {
+				t.Errorf("Expected %v, got %v", tt.expect, got)
+			}
+		})
+	}
+}
@@ -19,7 +19,7 @@ Package search provides client-side repository searching.
 
 This supports building an in-memory search index based on the contents of
 multiple repositories, and then using string matching or regular expressions
-to find matches.
+to find matches. Queries can also filter matches on the fields of charts.
 */
 package search
 
@@ -48,13 +48,15 @@ type Result struct {
 type Index struct {
 	lines  map[string]string
 	charts map[string]*repo.ChartVersion
+	// latest holds the latest version of each chart, by name.
+	latest map[string]*repo.ChartVersion
 }
 
 const sep = "\v"
 
 // NewIndex creates a new Index.
 func NewIndex() *Index {
-	return &Index{lines: map[string]string{}, charts: map[string]*repo.ChartVersion{}}
+	return &Index{lines: map[string]string{}, charts: map[string]*repo.ChartVersion{}, latest: map[string]*repo.ChartVersion{}}
 }
 
 // verSep is a separator for version fields in map keys.
@@ -74,6 +76,7 @@ func (i *Index) AddRepo(rname string, ind *repo.IndexFile, all bool) {
 		// Note: Do not use filePath.Join since on Windows it will return \
 		//       which results in a repo name that cannot be understood.
 		fname := path.Join(rname, name)
+		i.latest[fname] = ref[0]
 		if !all {
 			i.lines[fname] = indstr(rname, ref[0])
 			i.charts[fname] = ref[0]
@@ -57,6 +57,21 @@ Examples:
     # Search for the latest stable release for nginx-ingress with a major version of 1
     $ helm search repo nginx-ingress --version ^1.0.0
 
+    # Search for charts with the keyword "ingress" that are not deprecated and
+    # whose app version is at least 1.25
+    $ helm search repo keywords:ingress deprecated:false 'appVersion:>=1.25'
+
+Terms of the form 'field:value' only match charts whose field matches the
+value. The fields are name, repo, description, keyword, maintainer, home,
+source, type, version, appVersion and deprecated. Values are matched
+case-insensitively as substrings, or as regular expressions when enclosed in
+slashes, like 'name:/^nginx$/', or when --regexp is set. Values of version and
+appVersion are semantic version constraints, and deprecated matches the latest
+version of charts against true or false. Values containing spaces can be
+quoted, like 'description:"web server"'.
+
+With --output json or yaml, the full metadata of each chart is included.
+
 Repositories are managed with 'helm repo' commands.
 `
 
@@ -110,15 +125,13 @@ func (o *searchRepoOptions) run(out io.Writer, args []string) error {
 		return err
 	}
 
-	var res []*search.Result
-	if len(args) == 0 {
-		res = index.All()
-	} else {
-		q := strings.Join(args, " ")
-		res, err = index.Search(q, searchMaxScore, o.regexp)
-		if err != nil {
-			return err
-		}
+	q, err := search.ParseQuery(strings.Join(args, " "), o.regexp)
+	if err != nil {
+		return err
+	}
+	res, err := index.Query(q, searchMaxScore)
+	if err != nil {
+		return err
 	}
 
 	search.SortScore(res)
@@ -204,6 +217,8 @@ type repoChartElement struct {
 	Version     string `json:"version"`
 	AppVersion  string `json:"app_version"`
 	Description string `json:"description"`
+	// Chart is the full entry of the chart in the repository index
+	Chart *repo.ChartVersion `json:"chart,omitempty"`
 }
 
 type repoSearchWriter struct {
@@ -252,7 +267,7 @@ func (r *repoSearchWriter) encodeByFormat(out io.Writer, format output.Format) e
 	chartList := make([]repoChartElement, 0, len(r.results))
 
 	for _, r := range r.results {
-		chartList = append(chartList, repoChartElement{r.Name, r.Chart.Version, r.Chart.AppVersion, r.Chart.Description})
+		chartList = append(chartList, repoChartElement{r.Name, r.Chart.Version, r.Chart.AppVersion, r.Chart.Description, r.Chart})
 	}
 
 	switch format {
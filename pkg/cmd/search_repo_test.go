@@ -86,6 +86,26 @@ func TestSearchRepositoriesCmd(t *testing.T) {
 		name:   "search for 'alpine', expect valid yaml output",
 		cmd:    "search repo alpine --output yaml",
 		golden: "output/search-output-yaml.txt",
+	}, {
+		name:   "search for charts with the keyword 'database', expect one match",
+		cmd:    "search repo keywords:database",
+		golden: "output/search-query-keywords.txt",
+	}, {
+		name:   "search for 'alpine' with an app version before 2, expect one match with version 0.1.0",
+		cmd:    "search repo name:alpine 'appVersion:<2'",
+		golden: "output/search-query-app-version.txt",
+	}, {
+		name:   "search for deprecated charts, expect no matches",
+		cmd:    "search repo deprecated:true",
+		golden: "output/search-not-found.txt",
+	}, {
+		name:   "search for maintainers matching a regular expression, expect valid json output",
+		cmd:    "search repo maintainer:/^Bit/ --output json",
+		golden: "output/search-query-json.txt",
+	}, {
+		name:      "search with an invalid app version constraint, expect failure",
+		cmd:       "search repo appVersion:latest",
+		wantError: true,
 	}}
 
 	settings.Debug = true
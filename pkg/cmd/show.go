@@ -21,11 +21,14 @@ import (
 	"io"
 	"log"
 	"log/slog"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/getter"
 )
 
 const showDesc = `
@@ -37,11 +40,40 @@ This command inspects a chart (directory, file, or URL) and displays all its con
 (values.yaml, Chart.yaml, README)
 `
 
+const showAllOutputDesc = `
+Use '--output json' or '--output yaml' to print a single object with 'chart',
+'values', 'readme' and 'crds' keys instead of the '---' separated sections.
+`
+
 const showValuesDesc = `
 This command inspects a chart (directory, file, or URL) and displays the contents
 of the values.yaml file
 `
 
+const showValuesOutputDesc = `
+By default the values.yaml file is printed as-is. Use '--output json' or
+'--output yaml' to print the parsed values instead, with keys normalized and
+comments removed. Use '--strip-comments' to remove comments from the raw output.
+
+Use '--include-subcharts' to also show the default values of the chart's
+subcharts. With '--output json' or '--output yaml' they are merged into a single
+tree under the dependency's name or alias, with the parent's values taking
+precedence; otherwise each subchart's values.yaml is printed as its own document.
+
+Use '--profile' to apply one of the chart's values profiles, such as 'prod' for
+profiles/prod.yaml. The raw output prints the profile as its own document after
+values.yaml.
+`
+
+const showFilterDesc = `
+The output can be narrowed down with '--jsonpath' or '--yamlpath'. For 'show all'
+the expressions are evaluated against an object with 'chart', 'values', 'readme'
+and 'crds' keys.
+
+    $ helm show chart --yamlpath .appVersion ./mychart
+    $ helm show all --jsonpath '{.chart.version}' ./mychart
+`
+
 const showChartDesc = `
 This command inspects a chart (directory, file, or URL) and displays the contents
 of the Chart.yaml file
@@ -57,8 +89,55 @@ This command inspects a chart (directory, file, or URL) and displays the content
 of the CustomResourceDefinition files
 `
 
+const showDependenciesDesc = `
+This command inspects a chart (directory, file, or URL) and displays its
+dependencies: the name, version constraint, repository and condition declared
+in Chart.yaml, along with the version resolved in Chart.lock. Dependencies of
+subcharts vendored in the chart are shown as a tree. Subcharts are never
+downloaded.
+`
+
+const showSchemaDesc = `
+This command inspects a chart (directory, file, or URL) and displays the contents
+of the values.schema.json file.
+
+With '--include-subcharts', the schemas of the chart's subcharts are nested under
+the properties named after the dependency (or its alias), matching the layout of
+the chart's values.
+`
+
+const showImagesDesc = `
+This command inspects a chart (directory, file, or URL), renders its templates
+locally and lists the container images referenced by 'image' fields in the
+resulting manifests. The chart's default values are used unless values are
+supplied with '--values' or '--set'. No cluster is contacted.
+
+Use '--output json' or '--output yaml' to print the images as a list, which is
+useful for mirroring the images a chart needs into an air-gapped registry.
+`
+
+const showNotesDesc = `
+This command inspects a chart (directory, file, or URL), renders its templates
+locally and displays the resulting NOTES.txt, previewing the guidance shown
+after an install. The chart's default values are used unless values are
+supplied with '--values' or '--set'. No cluster is contacted.
+`
+
+const showLicenseDesc = `
+This command inspects a chart (directory, file, or URL) and displays the contents
+of the LICENSE file
+`
+
+const showFileDesc = `
+This command inspects a chart (directory, file, or URL) and displays the contents
+of any file packaged in it, given its path relative to the chart root.
+
+    $ helm show file ./mychart-0.1.0.tgz docs/upgrading.md
+`
+
 func newShowCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewShow(action.ShowAll, cfg)
+	valueOpts := &values.Options{}
 
 	showCommand := &cobra.Command{
 		Use:     "show",
@@ -79,7 +158,7 @@ func newShowCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	all := &cobra.Command{
 		Use:               "all [CHART]",
 		Short:             "show all information of the chart",
-		Long:              showAllDesc,
+		Long:              showAllDesc + showAllOutputDesc + showFilterDesc,
 		Args:              require.ExactArgs(1),
 		ValidArgsFunction: validArgsFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
@@ -100,7 +179,7 @@ func newShowCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	valuesSubCmd := &cobra.Command{
 		Use:               "values [CHART]",
 		Short:             "show the chart's values",
-		Long:              showValuesDesc,
+		Long:              showValuesDesc + showValuesOutputDesc + showFilterDesc,
 		Args:              require.ExactArgs(1),
 		ValidArgsFunction: validArgsFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
@@ -121,7 +200,7 @@ func newShowCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	chartSubCmd := &cobra.Command{
 		Use:               "chart [CHART]",
 		Short:             "show the chart's definition",
-		Long:              showChartDesc,
+		Long:              showChartDesc + showFilterDesc,
 		Args:              require.ExactArgs(1),
 		ValidArgsFunction: validArgsFunc,
 		RunE: func(_ *cobra.Command, args []string) error {
@@ -181,11 +260,153 @@ func newShowCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		},
 	}
 
-	cmds := []*cobra.Command{all, readmeSubCmd, valuesSubCmd, chartSubCmd, crdsSubCmd}
+	dependenciesSubCmd := &cobra.Command{
+		Use:               "dependencies [CHART]",
+		Aliases:           []string{"deps"},
+		Short:             "show the chart's dependencies",
+		Long:              showDependenciesDesc,
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: validArgsFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			client.OutputFormat = action.ShowDependencies
+			err := addRegistryClient(out, client)
+			if err != nil {
+				return err
+			}
+			output, err := runShow(args, client)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(out, output)
+			return nil
+		},
+	}
+
+	schemaSubCmd := &cobra.Command{
+		Use:               "schema [CHART]",
+		Short:             "show the chart's values schema",
+		Long:              showSchemaDesc,
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: validArgsFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			client.OutputFormat = action.ShowSchema
+			err := addRegistryClient(out, client)
+			if err != nil {
+				return err
+			}
+			output, err := runShow(args, client)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(out, output)
+			return nil
+		},
+	}
+
+	imagesSubCmd := &cobra.Command{
+		Use:               "images [CHART]",
+		Short:             "show the container images referenced by the chart",
+		Long:              showImagesDesc,
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: validArgsFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			client.OutputFormat = action.ShowImages
+			err := addRegistryClient(out, client)
+			if err != nil {
+				return err
+			}
+			if client.Values, err = valueOpts.MergeValues(getter.All(settings)); err != nil {
+				return err
+			}
+			output, err := runShow(args, client)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(out, output)
+			return nil
+		},
+	}
+
+	notesSubCmd := &cobra.Command{
+		Use:               "notes [CHART]",
+		Short:             "show the chart's rendered NOTES.txt",
+		Long:              showNotesDesc,
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: validArgsFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			client.OutputFormat = action.ShowNotes
+			err := addRegistryClient(out, client)
+			if err != nil {
+				return err
+			}
+			if client.Values, err = valueOpts.MergeValues(getter.All(settings)); err != nil {
+				return err
+			}
+			output, err := runShow(args, client)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(out, output)
+			return nil
+		},
+	}
+
+	licenseSubCmd := &cobra.Command{
+		Use:               "license [CHART]",
+		Short:             "show the chart's LICENSE",
+		Long:              showLicenseDesc,
+		Args:              require.ExactArgs(1),
+		ValidArgsFunction: validArgsFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			client.OutputFormat = action.ShowLicense
+			err := addRegistryClient(out, client)
+			if err != nil {
+				return err
+			}
+			output, err := runShow(args, client)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(out, output)
+			return nil
+		},
+	}
+
+	fileSubCmd := &cobra.Command{
+		Use:   "file [CHART] [PATH]",
+		Short: "show a file packaged in the chart",
+		Long:  showFileDesc,
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return compListCharts(toComplete, true)
+			}
+			return noMoreArgsComp()
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			client.OutputFormat = action.ShowFile
+			client.FilePath = args[1]
+			err := addRegistryClient(out, client)
+			if err != nil {
+				return err
+			}
+			output, err := runShow(args, client)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(out, output)
+			return nil
+		},
+	}
+
+	cmds := []*cobra.Command{all, readmeSubCmd, valuesSubCmd, chartSubCmd, crdsSubCmd, dependenciesSubCmd, schemaSubCmd, imagesSubCmd, notesSubCmd, licenseSubCmd, fileSubCmd}
 	for _, subCmd := range cmds {
 		addShowFlags(subCmd, client)
 		showCommand.AddCommand(subCmd)
 	}
+	for _, subCmd := range []*cobra.Command{imagesSubCmd, notesSubCmd} {
+		addValueOptionsFlags(subCmd.Flags(), valueOpts)
+	}
 
 	return showCommand
 }
@@ -194,10 +415,34 @@ func addShowFlags(subCmd *cobra.Command, client *action.Show) {
 	f := subCmd.Flags()
 
 	f.BoolVar(&client.Devel, "devel", false, "use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
-	if subCmd.Name() == "values" {
+	switch subCmd.Name() {
+	case "values", "chart", "all":
 		f.StringVar(&client.JSONPathTemplate, "jsonpath", "", "supply a JSONPath expression to filter the output")
+		f.StringVar(&client.YAMLPathTemplate, "yamlpath", "", "supply a path expression (e.g. '.appVersion') selecting a single field to print")
+	}
+	if subCmd.Name() == "schema" {
+		f.BoolVar(&client.IncludeSubcharts, "include-subcharts", false, "include the schemas of subcharts under their dependency alias")
+	}
+	switch subCmd.Name() {
+	case "values", "all", "images":
+		f.StringVarP((*string)(&client.Encoding), "output", "o", action.ShowEncodingRaw.String(), "prints the output in the specified format. Allowed values: "+strings.Join(action.ShowEncodings(), ", "))
+		err := subCmd.RegisterFlagCompletionFunc("output", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return action.ShowEncodings(), cobra.ShellCompDirectiveNoFileComp
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	switch subCmd.Name() {
+	case "values", "all", "images", "notes":
+		f.StringVar(&client.Profile, "profile", "", "apply the named values profile of the chart, such as 'prod' for profiles/prod.yaml, over its default values")
+	}
+	if subCmd.Name() == "values" {
+		f.BoolVar(&client.StripComments, "strip-comments", false, "remove comments from the raw values output")
+		f.BoolVar(&client.IncludeSubcharts, "include-subcharts", false, "include the default values of subcharts under their dependency alias")
 	}
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
+	addCacheModeFlags(subCmd, &client.CacheMode)
 
 	err := subCmd.RegisterFlagCompletionFunc("version", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) != 1 {
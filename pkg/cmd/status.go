@@ -18,12 +18,17 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"log"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"k8s.io/kubectl/pkg/cmd/get"
@@ -33,6 +38,7 @@ import (
 	"helm.sh/helm/v4/pkg/chart/common/util"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/kube"
 	"helm.sh/helm/v4/pkg/release"
 	releasev1 "helm.sh/helm/v4/pkg/release/v1"
 )
@@ -49,11 +55,17 @@ The status consists of:
 - list of resources that this release consists of
 - details on last test suite run, if applicable
 - additional notes provided by the chart
+- health of each resource (Ready, Progressing, Degraded or Missing), if '--show-health' is set
+
+With the '--watch' flag, the status of the resources of the release keeps being
+shown as it changes, until the command is interrupted. Resources are reported
+as Current once ready, InProgress while they are not, or Failed.
 `
 
 func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewStatus(cfg)
 	var outfmt output.Format
+	var watch bool
 
 	cmd := &cobra.Command{
 		Use:   "status RELEASE_NAME",
@@ -85,19 +97,33 @@ func newStatusCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			// strip chart metadata from the output
 			rel.Chart = nil
 
-			return outfmt.Write(out, &statusPrinter{
+			err = outfmt.Write(out, &statusPrinter{
 				release:      rel,
 				debug:        false,
 				showMetadata: false,
 				hideNotes:    false,
 				noColor:      settings.ShouldDisableColor(),
 			})
+			if err != nil || !watch {
+				return err
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+			printer := &resourceStatusPrinter{out: out, format: outfmt}
+			return client.Watch(ctx, args[0], func(rs kube.ResourceStatus) {
+				if err := printer.print(rs); err != nil {
+					cancel()
+				}
+			})
 		},
 	}
 
 	f := cmd.Flags()
 
 	f.IntVar(&client.Version, "revision", 0, "if set, display the status of the named release with revision")
+	f.BoolVar(&client.ShowHealth, "show-health", false, "if set, display the health of every resource of the release, computed from its live state, along with its conditions")
+	f.BoolVarP(&watch, "watch", "w", false, "after displaying the status, watch the resources of the release and show their status as it changes, until interrupted")
 
 	err := cmd.RegisterFlagCompletionFunc("revision", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 1 {
@@ -186,6 +212,10 @@ func (s statusPrinter) WriteTable(out io.Writer) error {
 		_, _ = fmt.Fprintf(out, "RESOURCES:\n%s\n", buf.String())
 	}
 
+	if len(rel.Info.Health) > 0 {
+		_, _ = fmt.Fprintf(out, "HEALTH:\n%s\n\n", healthTable(rel.Info.Health))
+	}
+
 	executions := executionsByHookEvent(rel)
 	if tests, ok := executions[releasev1.HookTest]; !ok || len(tests) == 0 {
 		_, _ = fmt.Fprintln(out, "TEST SUITE: None")
@@ -255,3 +285,74 @@ func executionsByHookEvent(rel *releasev1.Release) map[releasev1.HookEvent][]*re
 	}
 	return result
 }
+
+// resourceStatusPrinter prints the status of resources as they are watched,
+// one line or document per change.
+type resourceStatusPrinter struct {
+	out    io.Writer
+	format output.Format
+	// widths are the widths of the table columns, which grow with the
+	// longest value printed so far
+	widths [4]int
+	header bool
+}
+
+func (p *resourceStatusPrinter) print(rs kube.ResourceStatus) error {
+	switch p.format {
+	case output.JSON:
+		return output.EncodeJSON(p.out, rs)
+	case output.YAML:
+		if _, err := fmt.Fprintln(p.out, "---"); err != nil {
+			return err
+		}
+		return output.EncodeYAML(p.out, rs)
+	}
+
+	row := []string{rs.Namespace, rs.Kind, rs.Name, rs.Status, rs.Message}
+	if !p.header {
+		p.header = true
+		header := []string{"NAMESPACE", "KIND", "NAME", "STATUS", "MESSAGE"}
+		// align the header with the first row
+		for i := range p.widths {
+			p.widths[i] = max(len(header[i]), len(row[i]))
+		}
+		// separate the table from the status of the release
+		if _, err := fmt.Fprintln(p.out); err != nil {
+			return err
+		}
+		if err := p.printRow(header); err != nil {
+			return err
+		}
+	}
+	return p.printRow(row)
+}
+
+func (p *resourceStatusPrinter) printRow(row []string) error {
+	var b strings.Builder
+	for i := range p.widths {
+		p.widths[i] = max(p.widths[i], len(row[i]))
+		fmt.Fprintf(&b, "%-*s   ", p.widths[i], row[i])
+	}
+	b.WriteString(row[len(p.widths)])
+	_, err := fmt.Fprintln(p.out, strings.TrimRight(b.String(), " "))
+	return err
+}
+
+// healthTable returns a table of the health of resources, listing the
+// conditions reported by each of them.
+func healthTable(health []releasev1.ResourceHealth) *uitable.Table {
+	tbl := uitable.New()
+	tbl.AddRow("NAMESPACE", "KIND", "NAME", "HEALTH", "CONDITIONS", "MESSAGE")
+	for _, rh := range health {
+		conditions := make([]string, 0, len(rh.Conditions))
+		for _, c := range rh.Conditions {
+			condition := c.Type + "=" + c.Status
+			if c.Reason != "" {
+				condition += " (" + c.Reason + ")"
+			}
+			conditions = append(conditions, condition)
+		}
+		tbl.AddRow(rh.Namespace, rh.Kind, rh.Name, rh.Health, strings.Join(conditions, ", "), rh.Message)
+	}
+	return tbl
+}
@@ -17,10 +17,13 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
 	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/kube"
 	"helm.sh/helm/v4/pkg/release/common"
 	release "helm.sh/helm/v4/pkg/release/v1"
 )
@@ -60,6 +63,37 @@ func TestStatusCmd(t *testing.T) {
 			Status: common.StatusDeployed,
 			Notes:  "release notes",
 		}),
+	}, {
+		name:   "get status of a deployed release and watch its resources",
+		cmd:    "status flummoxed-chickadee --watch",
+		golden: "output/status.txt",
+		rels: releasesMockWithStatus(&release.Info{
+			Status: common.StatusDeployed,
+		}),
+	}, {
+		name:   "get status of a deployed release with the health of its resources",
+		cmd:    "status flummoxed-chickadee",
+		golden: "output/status-with-health.txt",
+		rels: releasesMockWithStatus(&release.Info{
+			Status: common.StatusDeployed,
+			Health: []release.ResourceHealth{{
+				Kind:      "Deployment",
+				Namespace: "default",
+				Name:      "web",
+				Health:    "Progressing",
+				Message:   "Deployment is available. Replicas: 1",
+				Conditions: []release.ResourceCondition{
+					{Type: "Available", Status: "True", Reason: "MinimumReplicasAvailable"},
+					{Type: "Progressing", Status: "True", Reason: "ReplicaSetUpdated"},
+				},
+			}, {
+				Kind:      "Service",
+				Namespace: "default",
+				Name:      "web",
+				Health:    "Ready",
+				Message:   "Service is ready",
+			}},
+		}),
 	}, {
 		name:   "get status of a deployed release with notes in json",
 		cmd:    "status flummoxed-chickadee -o json",
@@ -218,3 +252,40 @@ func TestStatusFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "status", false)
 	checkFileCompletion(t, "status myrelease", false)
 }
+
+func TestResourceStatusPrinter(t *testing.T) {
+	statuses := []kube.ResourceStatus{
+		{Kind: "Deployment", Namespace: "default", Name: "web", Status: "InProgress", Message: "Replicas: 1/3"},
+		{Kind: "Service", Namespace: "default", Name: "web-frontend", Status: "Current", Message: "Service is ready"},
+		{Kind: "Deployment", Namespace: "default", Name: "web", Status: "Current"},
+	}
+	tests := []struct {
+		format output.Format
+		expect string
+	}{{
+		format: output.Table,
+		expect: "\nNAMESPACE   KIND         NAME   STATUS       MESSAGE\n" +
+			"default     Deployment   web    InProgress   Replicas: 1/3\n" +
+			"default     Service      web-frontend   Current      Service is ready\n" +
+			"default     Deployment   web            Current\n",
+	}, {
+		format: output.JSON,
+		expect: `{"kind":"Deployment","namespace":"default","name":"web","status":"InProgress","message":"Replicas: 1/3"}` + "\n" +
+			`{"kind":"Service","namespace":"default","name":"web-frontend","status":"Current","message":"Service is ready"}` + "\n" +
+			`{"kind":"Deployment","namespace":"default","name":"web","status":"Current"}` + "\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			var out bytes.Buffer
+			p := &resourceStatusPrinter{out: &out, format: tt.format}
+			for _, rs := range statuses {
+				if err := p.print(rs); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if out.String() != tt.expect {
+				t.Errorf("expected\n%s\ngot\n%s", tt.expect, out.String())
+			}
+		})
+	}
+}
@@ -24,20 +24,22 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"regexp"
 	"slices"
-	"sort"
 	"strings"
 
 	release "helm.sh/helm/v4/pkg/release/v1"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
-	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+	"helm.sh/helm/v4/pkg/engine"
 )
 
 const templateDesc = `
@@ -56,6 +58,52 @@ comma-separated list:
 or
 
     $ helm template --api-versions networking.k8s.io/v1,cert-manager.io/v1 mychart ./mychart
+
+To specify the resources used for Capabilities.APIResources, such as to render
+charts checking whether a resource is namespaced, use the '--api-resources' flag
+with a YAML or JSON file holding a list of resources:
+
+    - group: monitoring.coreos.com
+      version: v1
+      resource: servicemonitors
+      kind: ServiceMonitor
+      namespaced: true
+      verbs: [get, list, create]
+
+The versions of the resources are added to Capabilities.APIVersions.
+
+To debug which of the values files and flags, or chart defaults, set a value,
+use the '--trace-values' flag. It prints the computed values to stderr with the
+source of each value as a comment:
+
+    $ helm template -f prod.yaml --set image.tag=1.2.3 --trace-values mychart ./mychart
+
+With '--debug-interactive', a rendering failure starts a debugger reading from
+standard input, where the values of the chart can be inspected and template
+expressions evaluated:
+
+    $ helm template --debug-interactive mychart ./mychart
+    debug> .Values.image
+    debug> {{ include "mychart.labels" . }}
+
+With '--snapshot-dir', the rendered manifests are compared with their snapshot
+in the directory, named after the release, instead of being printed. The
+snapshot holds the documents in a canonical form, with sorted fields, so that
+it only changes when the resources do. The command fails with a diff of the
+changed resources, until the changes are accepted with '--update-snapshots'.
+The snapshot is written the first time. Rendering each set of values under its
+own release name keeps a snapshot per set of values:
+
+    $ helm template prod ./mychart -f prod.yaml --snapshot-dir snapshots
+    $ helm template prod ./mychart -f prod.yaml --snapshot-dir snapshots --update-snapshots
+
+With '--dev-deps', the dependencies with a 'file://' repository, such as
+'file://../mychart', are rendered from their directory instead of from the
+copies in 'charts/'. Changes to charts developed together in a repository are
+then rendered right away, without running 'helm dependency update' and
+rewriting the archives in 'charts/' after every change:
+
+    $ helm template --dev-deps mychart ./mychart
 `
 
 func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -66,7 +114,15 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	valueOpts := &values.Options{}
 	var kubeVersion string
 	var extraAPIs []string
-	var showFiles []string
+	var apiResources string
+	var showFilter action.ManifestFilter
+	var renderCacheDir string
+	var debugInteractive bool
+	var profileRender string
+	var lookupFixtures string
+	var traceValues bool
+	var snapshotDir string
+	var updateSnapshots bool
 
 	cmd := &cobra.Command{
 		Use:   "template [NAME] [CHART]",
@@ -77,6 +133,9 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compInstall(args, toComplete, client)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if updateSnapshots && snapshotDir == "" {
+				return errors.New("--update-snapshots requires --snapshot-dir")
+			}
 			if kubeVersion != "" {
 				parsedKubeVersion, err := common.ParseKubeVersion(kubeVersion)
 				if err != nil {
@@ -101,11 +160,43 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				dryRunStrategy = action.DryRunServer
 			}
 			client.DryRunStrategy = dryRunStrategy
+			if renderCacheDir != "" {
+				cfg.RenderCache = engine.NewRenderCache(renderCacheDir)
+			}
+			if lookupFixtures != "" {
+				fixtures, err := engine.LoadLookupFixtures(lookupFixtures)
+				if err != nil {
+					return err
+				}
+				cfg.LookupFixtures = fixtures
+			}
+			if profileRender != "" {
+				cfg.RenderProfile = engine.NewRenderProfile()
+			}
+			cfg.RenderWarnings = &engine.RenderWarnings{}
+			defer printRenderWarnings(cmd.ErrOrStderr(), cfg.RenderWarnings)
 			client.ReleaseName = "release-name"
 			client.Replace = true // Skip the name check
 			client.APIVersions = common.VersionSet(extraAPIs)
+			if apiResources != "" {
+				resources, err := loadAPIResources(apiResources)
+				if err != nil {
+					return err
+				}
+				client.APIResources = resources
+			}
 			client.IncludeCRDs = includeCrds
+			if debugInteractive {
+				client.OnRenderError = func(ev *engine.Evaluator, err error) {
+					runTemplateDebugger(cmd.InOrStdin(), out, ev, err)
+				}
+			}
 			rel, err := runInstall(args, client, valueOpts, out)
+			if cfg.RenderProfile != nil {
+				if err := writeRenderProfile(cmd.ErrOrStderr(), profileRender, cfg.RenderProfile); err != nil {
+					return err
+				}
+			}
 
 			if err != nil && !settings.Debug {
 				if rel != nil {
@@ -115,6 +206,12 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			}
 			installErr := err
 
+			if traceValues && rel != nil {
+				if err := writeValuesTrace(cmd.ErrOrStderr(), rel); err != nil {
+					return err
+				}
+			}
+
 			// We ignore a potential error here because, when the --debug flag was specified,
 			// we always want to print the YAML, even if it is not valid. The error is still returned afterwards.
 			if rel != nil {
@@ -133,12 +230,13 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 							if client.UseReleaseName {
 								newDir = filepath.Join(client.OutputDir, client.ReleaseName)
 							}
-							_, err := os.Stat(filepath.Join(newDir, m.Path))
+							name := action.OutputFileName(client.OutputDirLayout, m.Path, m.Kind, m.Name)
+							_, err := os.Stat(filepath.Join(newDir, name))
 							if err == nil {
-								fileWritten[m.Path] = true
+								fileWritten[name] = true
 							}
 
-							err = writeToFile(newDir, m.Path, m.Manifest, fileWritten[m.Path])
+							err = writeToFile(newDir, name, m.Path, m.Manifest, fileWritten[name])
 							if err != nil {
 								return err
 							}
@@ -146,60 +244,29 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					}
 				}
 
-				// if we have a list of files to render, then check that each of the
-				// provided files exists in the chart.
-				if len(showFiles) > 0 {
-					// This is necessary to ensure consistent manifest ordering when using --show-only
-					// with globs or directory names.
-					splitManifests := releaseutil.SplitManifests(manifests.String())
-					manifestsKeys := make([]string, 0, len(splitManifests))
-					for k := range splitManifests {
-						manifestsKeys = append(manifestsKeys, k)
-					}
-					sort.Sort(releaseutil.BySplitManifestsOrder(manifestsKeys))
-
-					manifestNameRegex := regexp.MustCompile("# Source: [^/]+/(.+)")
-					var manifestsToRender []string
-					for _, f := range showFiles {
-						missing := true
-						// Use linux-style filepath separators to unify user's input path
-						f = filepath.ToSlash(f)
-						for _, manifestKey := range manifestsKeys {
-							manifest := splitManifests[manifestKey]
-							submatch := manifestNameRegex.FindStringSubmatch(manifest)
-							if len(submatch) == 0 {
-								continue
-							}
-							manifestName := submatch[1]
-							// manifest.Name is rendered using linux-style filepath separators on Windows as
-							// well as macOS/linux.
-							manifestPathSplit := strings.Split(manifestName, "/")
-							// manifest.Path is connected using linux-style filepath separators on Windows as
-							// well as macOS/linux
-							manifestPath := strings.Join(manifestPathSplit, "/")
-
-							// if the filepath provided matches a manifest path in the
-							// chart, render that manifest
-							if matched, _ := filepath.Match(f, manifestPath); !matched {
-								continue
-							}
-							manifestsToRender = append(manifestsToRender, manifest)
-							missing = false
-						}
-						if missing {
-							if installErr != nil && settings.Debug {
-								// assume the manifest itself is too malformed to be rendered
-								return installErr
-							}
-							return fmt.Errorf("could not find template %s in chart", f)
+				rendered := manifests.String()
+				// if we have a list of files or resources to render, then check
+				// that each of them exists in the chart.
+				if !showFilter.Empty() {
+					manifestsToRender, err := showFilter.Filter(manifests.String())
+					if err != nil {
+						if installErr != nil && settings.Debug {
+							// assume the manifest itself is too malformed to be rendered
+							return installErr
 						}
+						return err
 					}
+					var filtered strings.Builder
 					for _, m := range manifestsToRender {
-						fmt.Fprintf(out, "---\n%s\n", m)
+						fmt.Fprintf(&filtered, "---\n%s\n", m)
 					}
-				} else {
-					fmt.Fprintf(out, "%s", manifests.String())
+					rendered = filtered.String()
+				}
+
+				if snapshotDir != "" && installErr == nil {
+					return checkTemplateSnapshot(out, snapshotDir, rel.Name, rendered, updateSnapshots)
 				}
+				fmt.Fprintf(out, "%s", rendered)
 			}
 
 			return installErr
@@ -208,7 +275,9 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	addInstallFlags(cmd, f, client, valueOpts)
-	f.StringArrayVarP(&showFiles, "show-only", "s", []string{}, "only show manifests rendered from the given templates")
+	f.StringArrayVarP(&showFilter.Templates, "show-only", "s", []string{}, "only show manifests rendered from the given templates. Glob patterns and directories are supported")
+	f.Var(&regexpSliceValue{&showFilter.TemplateRegexes}, "show-only-regex", "only show manifests rendered from templates whose path matches the given regular expression (can specify multiple)")
+	f.StringArrayVar(&showFilter.Resources, "show-kind", []string{}, "only show the resources of the given kind, or of the given kind and name as KIND/NAME. The name can be a glob pattern (can specify multiple)")
 	f.StringVar(&client.OutputDir, "output-dir", "", "writes the executed templates to files in output-dir instead of stdout")
 	f.BoolVar(&validate, "validate", false, "deprecated")
 	f.MarkDeprecated("validate", "use '--dry-run=server' instead")
@@ -217,7 +286,18 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.IsUpgrade, "is-upgrade", false, "set .Release.IsUpgrade instead of .Release.IsInstall")
 	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for Capabilities.KubeVersion")
 	f.StringSliceVarP(&extraAPIs, "api-versions", "a", []string{}, "Kubernetes api versions used for Capabilities.APIVersions (multiple can be specified)")
+	f.StringVar(&apiResources, "api-resources", "", "YAML or JSON file with the list of Kubernetes resources used for Capabilities.APIResources")
 	f.BoolVar(&client.UseReleaseName, "release-name", false, "use release name in the output-dir path.")
+	addOutputDirLayoutFlag(cmd, &client.OutputDirLayout)
+	f.StringVar(&renderCacheDir, "render-cache-dir", "", "cache rendered templates in this directory, and reuse them when the chart, values and capabilities are unchanged. Not used with --dry-run=server or --enable-dns")
+	f.BoolVar(&debugInteractive, "debug-interactive", false, "when rendering fails, start an interactive debugger to inspect the values and evaluate template expressions")
+	f.BoolVar(&client.DevDependencies, "dev-deps", false, "render the dependencies with a file:// repository from their directory instead of from charts/, so changes to them are rendered without running 'helm dependency update'")
+	f.BoolVar(&client.ChangedValuesOnly, "changed-values-only", false, "only render the templates that reference the values passed with --values and --set, for quickly checking the effect of a change of values")
+	f.StringVar(&profileRender, "profile-render", "", "write a profile of the time spent rendering templates and calling expensive template functions to this file, as folded stacks for flame graph tools, and print a summary")
+	f.BoolVar(&traceValues, "trace-values", false, "print the computed values to stderr, each annotated with the values file, flag or chart that set it")
+	f.StringVar(&lookupFixtures, "lookup-fixtures", "", "directory of YAML or JSON files with the objects returned by the lookup template function, instead of empty results. Not used with --dry-run=server")
+	f.StringVar(&snapshotDir, "snapshot-dir", "", "compare the rendered manifests with their snapshot in this directory instead of printing them, failing with a diff if they changed. The snapshot is written if there is none yet")
+	f.BoolVar(&updateSnapshots, "update-snapshots", false, "with --snapshot-dir, rewrite the snapshot of the rendered manifests when they changed instead of failing")
 	f.String(
 		"dry-run",
 		"client",
@@ -225,6 +305,8 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.Lookup("dry-run").NoOptDefVal = "unset"
 	bindPostRenderFlag(cmd, &client.PostRenderer, settings)
 	cmd.MarkFlagsMutuallyExclusive("validate", "dry-run")
+	cmd.MarkFlagsMutuallyExclusive("snapshot-dir", "output-dir")
+	cmd.MarkFlagsMutuallyExclusive("dev-deps", "locked")
 
 	return cmd
 }
@@ -238,7 +320,7 @@ func isTestHook(h *release.Hook) bool {
 // bug introduced by #8156. As part of the todo to refactor renderResources
 // this duplicate code should be removed. It is added here so that the API
 // surface area is as minimally impacted as possible in fixing the issue.
-func writeToFile(outputDir string, name string, data string, appendData bool) error {
+func writeToFile(outputDir string, name string, source string, data string, appendData bool) error {
 	outfileName := strings.Join([]string{outputDir, name}, string(filepath.Separator))
 
 	err := ensureDirectoryForFile(outfileName)
@@ -253,7 +335,7 @@ func writeToFile(outputDir string, name string, data string, appendData bool) er
 
 	defer f.Close()
 
-	_, err = fmt.Fprintf(f, "---\n# Source: %s\n%s\n", name, data)
+	_, err = fmt.Fprintf(f, "---\n# Source: %s\n%s\n", source, data)
 
 	if err != nil {
 		return err
@@ -279,3 +361,68 @@ func ensureDirectoryForFile(file string) error {
 
 	return os.MkdirAll(baseDir, 0755)
 }
+
+// writeValuesTrace writes the computed values of a release to out, annotated
+// with their sources.
+func writeValuesTrace(out io.Writer, rel *release.Release) error {
+	vals, err := util.CoalesceValues(rel.Chart, rel.Config)
+	if err != nil {
+		return err
+	}
+	trace, err := util.TraceValues(rel.Chart, rel.Config, rel.ValuesTrace)
+	if err != nil {
+		return err
+	}
+	return valuesWriter{vals: vals, allValues: true, trace: trace}.WriteTable(out)
+}
+
+// writeRenderProfile writes the folded stacks of a render profile to file, and
+// a summary of the slowest templates and functions to out.
+func writeRenderProfile(out io.Writer, file string, profile *engine.RenderProfile) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("unable to write render profile: %w", err)
+	}
+	if err := profile.WriteFolded(f); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write render profile: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write render profile: %w", err)
+	}
+
+	templates := uitable.New()
+	templates.AddRow("TEMPLATE", "CALLS", "TIME")
+	for _, e := range profile.Templates() {
+		templates.AddRow(e.Name, e.Calls, e.Duration)
+	}
+	if err := output.EncodeTable(out, templates); err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+
+	funcs := uitable.New()
+	funcs.AddRow("FUNCTION", "CALLS", "TIME")
+	for _, e := range profile.Functions() {
+		funcs.AddRow(e.Name, e.Calls, e.Duration)
+	}
+	return output.EncodeTable(out, funcs)
+}
+
+// loadAPIResources reads a YAML or JSON list of API resources.
+func loadAPIResources(file string) (common.APIResources, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var resources common.APIResources
+	if err := yaml.UnmarshalStrict(data, &resources); err != nil {
+		return nil, fmt.Errorf("unable to parse API resources from %s: %w", file, err)
+	}
+	for _, r := range resources {
+		if r.Version == "" || r.Kind == "" || r.Resource == "" {
+			return nil, fmt.Errorf("API resource %q in %s needs a version, kind and resource", r.GroupVersion()+"/"+r.Kind, file)
+		}
+	}
+	return resources, nil
+}
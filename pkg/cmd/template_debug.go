@@ -0,0 +1,93 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/engine"
+)
+
+const templateDebugHelp = `Enter a template expression to evaluate it, such as:
+
+    .Values.image
+    {{ include "mychart.fullname" . }}
+
+Expressions without delimiters are printed as YAML. The top level object holds
+.Values, .Release, .Capabilities, .Chart and .Files of the chart.
+
+Commands:
+    :error              print the render error
+    :templates          list the templates of the chart
+    :render TEMPLATE    render a template
+    :help               print this help
+    :quit               stop debugging
+`
+
+// runTemplateDebugger reads template expressions and commands from in, and
+// writes their results to out, until in is exhausted or the user quits.
+func runTemplateDebugger(in io.Reader, out io.Writer, ev *engine.Evaluator, renderErr error) {
+	fmt.Fprintf(out, "Rendering failed:\n%s\n\nEntering the template debugger. Type :help for help, :quit to stop.\n", renderErr)
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "debug> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return
+		}
+		line := strings.TrimSpace(scanner.Text())
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch cmd {
+		case "":
+		case ":quit", ":q":
+			return
+		case ":help":
+			fmt.Fprint(out, templateDebugHelp)
+		case ":error":
+			fmt.Fprintln(out, renderErr)
+		case ":templates":
+			for _, name := range ev.Templates() {
+				fmt.Fprintln(out, name)
+			}
+		case ":render":
+			result, err := ev.Render(strings.TrimSpace(arg))
+			printDebugResult(out, result, err)
+		default:
+			if strings.HasPrefix(cmd, ":") {
+				fmt.Fprintf(out, "Error: unknown command %s, type :help for help\n", cmd)
+				continue
+			}
+			if !strings.Contains(line, "{{") {
+				line = "{{ " + line + " | toYaml }}"
+			}
+			result, err := ev.Eval(line)
+			printDebugResult(out, result, err)
+		}
+	}
+}
+
+func printDebugResult(out io.Writer, result string, err error) {
+	if err != nil {
+		fmt.Fprintf(out, "Error: %s\n", err)
+		return
+	}
+	fmt.Fprintln(out, strings.TrimRight(result, "\n"))
+}
@@ -0,0 +1,144 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/internal/diff"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// sourcePrefix prefixes the comment naming the template of a rendered
+// document.
+const sourcePrefix = "# Source: "
+
+// checkTemplateSnapshot compares the rendered manifests of a release with
+// its snapshot in dir, writing the snapshot if there is none yet or if
+// update is set. The differences with the snapshot are printed to out.
+func checkTemplateSnapshot(out io.Writer, dir, releaseName, manifests string, update bool) error {
+	path := filepath.Join(dir, releaseName+".yaml")
+	rendered := canonicalManifests(manifests)
+
+	stored, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if err := writeTemplateSnapshot(path, rendered); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Snapshot written: %s\n", path)
+		return nil
+	case err != nil:
+		return fmt.Errorf("unable to read snapshot: %w", err)
+	}
+
+	changes := diff.Manifests(string(stored), rendered)
+	if len(changes) == 0 {
+		if string(stored) != rendered {
+			// Only the order or the formatting of the documents changed.
+			if err := writeTemplateSnapshot(path, rendered); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "Snapshot matches: %s\n", path)
+		return nil
+	}
+	if update {
+		if err := writeTemplateSnapshot(path, rendered); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Snapshot updated: %s\n", path)
+		return nil
+	}
+
+	if err := diff.Write(out, changes, diff.Options{Context: diff.DefaultContext, NoColor: settings.ShouldDisableColor()}); err != nil {
+		return err
+	}
+	return fmt.Errorf("the rendered manifests do not match the snapshot %s: use --update-snapshots to accept the changes", path)
+}
+
+// writeTemplateSnapshot writes a snapshot, creating its directory.
+func writeTemplateSnapshot(path, manifests string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("unable to write snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(manifests), 0644); err != nil {
+		return fmt.Errorf("unable to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// canonicalManifests returns the documents of rendered manifests in a
+// canonical form, so that snapshots only change when the resources do: the
+// fields of each document sorted, formatted alike, and the documents sorted
+// by template and content. Documents that are not valid YAML are kept as is,
+// and empty documents are left out.
+func canonicalManifests(manifests string) string {
+	type document struct {
+		source  string
+		content string
+	}
+
+	var docs []document
+	for _, m := range releaseutil.SplitManifests(manifests) {
+		m = strings.TrimSpace(m)
+		var source string
+		if first, _, _ := strings.Cut(m, "\n"); strings.HasPrefix(first, sourcePrefix) {
+			source = strings.TrimPrefix(first, sourcePrefix)
+		}
+
+		var content any
+		if err := yaml.Unmarshal([]byte(m), &content); err != nil {
+			docs = append(docs, document{source: source, content: m + "\n"})
+			continue
+		}
+		if content == nil {
+			continue
+		}
+		data, err := yaml.Marshal(content)
+		if err != nil {
+			docs = append(docs, document{source: source, content: m + "\n"})
+			continue
+		}
+		docs = append(docs, document{source: source, content: string(data)})
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].source != docs[j].source {
+			return docs[i].source < docs[j].source
+		}
+		return docs[i].content < docs[j].content
+	})
+
+	var b strings.Builder
+	for _, d := range docs {
+		b.WriteString("---\n")
+		if d.source != "" && !strings.HasPrefix(d.content, sourcePrefix) {
+			b.WriteString(sourcePrefix + d.source + "\n")
+		}
+		b.WriteString(d.content)
+	}
+	return b.String()
+}
@@ -18,8 +18,13 @@ package cmd
 
 import (
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var chartPath = "testdata/testcharts/subchart"
@@ -113,6 +118,84 @@ func TestTemplateCmd(t *testing.T) {
 			// Repeat to ensure manifest ordering regressions are caught
 			repeat: 10,
 		},
+		{
+			name:   "template with show-only directory",
+			cmd:    fmt.Sprintf("template '%s' --show-only templates/subdir", chartPath),
+			golden: "output/template-show-only-directory.txt",
+		},
+		{
+			name:   "template with show-only-regex",
+			cmd:    fmt.Sprintf("template '%s' --show-only-regex 'role(binding)?\\.yaml$'", chartPath),
+			golden: "output/template-show-only-glob.txt",
+		},
+		{
+			name:   "template with show-kind",
+			cmd:    fmt.Sprintf("template '%s' --show-kind service/subchart* --show-kind ServiceAccount", chartPath),
+			golden: "output/template-show-kind.txt",
+		},
+		{
+			name:      "template with show-kind not found",
+			cmd:       fmt.Sprintf("template '%s' --show-kind Deployment/web", chartPath),
+			wantError: true,
+			golden:    "output/template-show-kind-not-found.txt",
+		},
+		{
+			name:      "check strict values",
+			cmd:       fmt.Sprintf("template '%s' --strict-values --set replicaCont=3", chartPath),
+			wantError: true,
+			golden:    "output/template-strict-values.txt",
+		},
+		{
+			name:   "check changed values only",
+			cmd:    fmt.Sprintf("template '%s' --changed-values-only --set subcharta.service.type=NodePort", chartPath),
+			golden: "output/template-changed-values-only.txt",
+		},
+		{
+			name:   "check lookup fixtures",
+			cmd:    "template testdata/testcharts/chart-with-lookup --namespace default --lookup-fixtures testdata/lookup-fixtures",
+			golden: "output/template-lookup-fixtures.txt",
+		},
+		{
+			name:   "check lookup without fixtures",
+			cmd:    "template testdata/testcharts/chart-with-lookup --namespace default",
+			golden: "output/template-lookup-no-fixtures.txt",
+		},
+		{
+			name:   "check api resources",
+			cmd:    "template testdata/testcharts/chart-with-api-resources --namespace monitoring --api-resources testdata/api-resources.yaml",
+			golden: "output/template-api-resources.txt",
+		},
+		{
+			name:   "check without api resources",
+			cmd:    "template testdata/testcharts/chart-with-api-resources",
+			golden: "output/template-no-api-resources.txt",
+		},
+		{
+			name:   "check template warnings",
+			cmd:    "template testdata/testcharts/chart-with-warnings --set tag=1.26",
+			golden: "output/template-warnings.txt",
+		},
+		{
+			name:   "check template with traced values",
+			cmd:    "template testdata/testcharts/issue-9027 --set global.hash.key3=9 --trace-values",
+			golden: "output/template-trace-values.txt",
+		},
+		{
+			name:   "check template with a values profile",
+			cmd:    "template testdata/testcharts/chart-with-profiles --profile prod",
+			golden: "output/template-profile.txt",
+		},
+		{
+			name:   "check template with a values profile overridden by user values",
+			cmd:    "template testdata/testcharts/chart-with-profiles --profile prod --set replicas=5",
+			golden: "output/template-profile-override.txt",
+		},
+		{
+			name:      "check template with an unknown values profile",
+			cmd:       "template testdata/testcharts/chart-with-profiles --profile staging",
+			wantError: true,
+			golden:    "output/template-profile-unknown.txt",
+		},
 		{
 			name:   "sorted output of manifests (order of filenames, then order of objects within each YAML file)",
 			cmd:    fmt.Sprintf("template '%s'", "testdata/testcharts/object-order"),
@@ -139,6 +222,16 @@ func TestTemplateCmd(t *testing.T) {
 			wantError: true,
 			golden:    "output/template-with-invalid-template-expr-debug-show-only.txt",
 		},
+		{
+			name:   "chart with local dependencies from charts/",
+			cmd:    fmt.Sprintf("template '%s'", "testdata/testcharts/dev-deps/parent"),
+			golden: "output/template-dev-deps-charts.txt",
+		},
+		{
+			name:   "chart with local dependencies from their directory (--dev-deps)",
+			cmd:    fmt.Sprintf("template '%s' --dev-deps", "testdata/testcharts/dev-deps/parent"),
+			golden: "output/template-dev-deps.txt",
+		},
 		{
 			name:   "template skip-tests",
 			cmd:    fmt.Sprintf(`template '%s' --skip-tests`, chartPath),
@@ -212,3 +305,185 @@ func TestTemplateFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "template myname", true)
 	checkFileCompletion(t, "template myname mychart", false)
 }
+
+func TestTemplateOutputDirLayout(t *testing.T) {
+	tests := []struct {
+		layout string
+		files  []string
+	}{
+		{
+			layout: "source",
+			files: []string{
+				"subchart/charts/subcharta/templates/service.yaml",
+				"subchart/charts/subchartb/templates/service.yaml",
+				"subchart/templates/service.yaml",
+				"subchart/templates/subdir/role.yaml",
+				"subchart/templates/subdir/rolebinding.yaml",
+				"subchart/templates/subdir/serviceaccount.yaml",
+				"subchart/templates/tests/test-config.yaml",
+				"subchart/templates/tests/test-nothing.yaml",
+			},
+		},
+		{
+			layout: "name",
+			files: []string{
+				"subchart/charts/subcharta/service-subcharta.yaml",
+				"subchart/charts/subchartb/service-subchartb.yaml",
+				"subchart/configmap-release-name-testconfig.yaml",
+				"subchart/pod-release-name-test.yaml",
+				"subchart/role-subchart-role.yaml",
+				"subchart/rolebinding-subchart-binding.yaml",
+				"subchart/service-subchart.yaml",
+				"subchart/serviceaccount-subchart-sa.yaml",
+			},
+		},
+		{
+			layout: "kind",
+			files: []string{
+				"configmap/release-name-testconfig.yaml",
+				"pod/release-name-test.yaml",
+				"role/subchart-role.yaml",
+				"rolebinding/subchart-binding.yaml",
+				"service/subchart.yaml",
+				"service/subcharta.yaml",
+				"service/subchartb.yaml",
+				"serviceaccount/subchart-sa.yaml",
+			},
+		},
+		{
+			layout: "flat",
+			files: []string{
+				"configmap-release-name-testconfig.yaml",
+				"pod-release-name-test.yaml",
+				"role-subchart-role.yaml",
+				"rolebinding-subchart-binding.yaml",
+				"service-subchart.yaml",
+				"service-subcharta.yaml",
+				"service-subchartb.yaml",
+				"serviceaccount-subchart-sa.yaml",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			dir := t.TempDir()
+			_, _, err := executeActionCommandC(storageFixture(), fmt.Sprintf("template '%s' --output-dir '%s' --output-dir-layout %s", chartPath, dir, tt.layout))
+			require.NoError(t, err)
+
+			var written []string
+			err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(dir, p)
+				written = append(written, filepath.ToSlash(rel))
+				return err
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.files, written)
+		})
+	}
+
+	_, _, err := executeActionCommandC(storageFixture(), fmt.Sprintf("template '%s' --output-dir-layout resource", chartPath))
+	assert.ErrorContains(t, err, "invalid output directory layout")
+}
+
+func TestTemplateDebugInteractive(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "input")
+	require.NoError(t, os.WriteFile(input, []byte(`.Values.Name
+{{ .Release.Name }}
+:templates
+:frobnicate
+:quit
+`), 0o644))
+	in, err := os.Open(input)
+	require.NoError(t, err)
+	defer in.Close()
+
+	chart := "testdata/testcharts/chart-with-template-with-invalid-template-expr"
+	_, out, err := executeActionCommandStdinC(storageFixture(), in, fmt.Sprintf("template '%s' --debug-interactive", chart))
+	require.Error(t, err)
+
+	assert.Contains(t, out, "Rendering failed:\n")
+	assert.Contains(t, out, "debug> my-alpine\n")
+	assert.Contains(t, out, "debug> release-name\n")
+	assert.Contains(t, out, "debug> chart-with-template-with-invalid-template-expr/templates/alpine-pod.yaml\n")
+	assert.Contains(t, out, "Error: unknown command :frobnicate")
+}
+
+func TestTemplateProfileRender(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "profile.folded")
+	cmd := fmt.Sprintf("template '%s' --profile-render %s", chartPath, file)
+	_, out, err := executeActionCommandC(storageFixture(), cmd)
+	require.NoError(t, err)
+	assert.Contains(t, out, "TEMPLATE")
+	assert.Contains(t, out, "FUNCTION")
+
+	folded, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(folded), "subchart/templates/service.yaml ")
+	assert.Contains(t, string(folded), "subchart/charts/subcharta/templates/service.yaml ")
+}
+
+func TestTemplateSnapshot(t *testing.T) {
+	snapshotDir := t.TempDir()
+	snapshot := filepath.Join(snapshotDir, "snap.yaml")
+	cmd := fmt.Sprintf("template snap '%s' --snapshot-dir %s", chartPath, snapshotDir)
+
+	_, out, err := executeActionCommand(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "Snapshot written: "+snapshot+"\n", out)
+	data, err := os.ReadFile(snapshot)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "---\n# Source: subchart/templates/service.yaml\napiVersion: v1\n")
+
+	_, out, err = executeActionCommand(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "Snapshot matches: "+snapshot+"\n", out)
+
+	_, out, err = executeActionCommand(cmd + " --set service.externalPort=8080")
+	require.ErrorContains(t, err, "the rendered manifests do not match the snapshot "+snapshot)
+	assert.Contains(t, out, "-, subchart, Service has been modified:\n")
+	assert.Contains(t, out, "   - name: nginx\n-    port: 80\n+    port: 8080\n")
+
+	_, out, err = executeActionCommand(cmd + " --set service.externalPort=8080 --update-snapshots")
+	require.NoError(t, err)
+	assert.Equal(t, "Snapshot updated: "+snapshot+"\n", out)
+	_, _, err = executeActionCommand(cmd + " --set service.externalPort=8080")
+	require.NoError(t, err)
+
+	_, _, err = executeActionCommand(fmt.Sprintf("template '%s' --update-snapshots", chartPath))
+	assert.EqualError(t, err, "--update-snapshots requires --snapshot-dir")
+}
+
+func TestCanonicalManifests(t *testing.T) {
+	manifests := `---
+# Source: chart/templates/b.yaml
+kind: ConfigMap
+metadata:
+  name: b
+data: {z: "1", a: "2"}
+---
+# Source: chart/templates/a.yaml
+metadata:
+    name: a
+kind: ConfigMap
+---
+# Source: chart/templates/empty.yaml
+`
+	expected := `---
+# Source: chart/templates/a.yaml
+kind: ConfigMap
+metadata:
+  name: a
+---
+# Source: chart/templates/b.yaml
+data:
+  a: "2"
+  z: "1"
+kind: ConfigMap
+metadata:
+  name: b
+`
+	assert.Equal(t, expected, canonicalManifests(manifests))
+}
@@ -21,10 +21,13 @@ import (
 	"io"
 	"time"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/release"
 )
 
 const uninstallDesc = `
@@ -34,14 +37,28 @@ It removes all of the resources associated with the last release of the chart
 as well as the release history, freeing it up for future use.
 
 Use the '--dry-run' flag to see which releases will be uninstalled without actually
-uninstalling them.
+uninstalling them. It lists the resources that would be deleted, kept because
+of their resource policy, or skipped because they are not owned by the
+release. Use '--output json' or '--output yaml' to print this list in a
+machine-readable format.
 
 Use '--cascade foreground' with '--wait' to ensure resources with finalizers
-are fully deleted before the command returns.
+are fully deleted before the command returns. Use '--cascade-for' to choose
+the strategy of the resources of a kind, e.g. '--cascade-for StatefulSet=orphan'
+to keep the pods of the StatefulSets running.
+
+The PersistentVolumeClaims created by StatefulSets are kept by Kubernetes, so
+that their data outlives the release. Use '--delete-pvcs' to delete them along
+with their StatefulSets.
+
+Releases protected with 'helm release protect', and releases with resources
+annotated with 'helm.sh/resource-policy: protected', are not uninstalled unless
+'--force-protected' is set.
 `
 
 func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewUninstall(cfg)
+	var outfmt output.Format
 
 	cmd := &cobra.Command{
 		Use:        "uninstall RELEASE_NAME [...]",
@@ -58,18 +75,30 @@ func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			if validationErr != nil {
 				return validationErr
 			}
+			var results uninstallResults
 			for i := range args {
 				res, err := client.Run(args[i])
 				if err != nil {
 					return err
 				}
-				if res != nil && res.Info != "" {
-					fmt.Fprintln(out, res.Info)
+				result := uninstallResult{Name: args[i]}
+				if res != nil {
+					result.Info = res.Info
+					result.Resources = res.Resources
 				}
-
-				fmt.Fprintf(out, "release \"%s\" uninstalled\n", args[i])
+				// Table output is written as releases are uninstalled.
+				if outfmt == output.Table {
+					if err := outfmt.Write(out, uninstallResults{result}); err != nil {
+						return err
+					}
+					continue
+				}
+				results = append(results, result)
+			}
+			if outfmt == output.Table {
+				return nil
 			}
-			return nil
+			return outfmt.Write(out, results)
 		},
 	}
 
@@ -79,16 +108,66 @@ func newUninstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.IgnoreNotFound, "ignore-not-found", false, `Treat "release not found" as a successful uninstall`)
 	f.BoolVar(&client.KeepHistory, "keep-history", false, "remove all associated resources and mark the release as deleted, but retain the release history")
 	f.StringVar(&client.DeletionPropagation, "cascade", "background", "Must be \"background\", \"orphan\", or \"foreground\". Selects the deletion cascading strategy for the dependents. Defaults to background. Use \"foreground\" with --wait to ensure resources with finalizers are fully deleted before returning.")
+	f.StringToStringVar(&client.KindDeletionPropagation, "cascade-for", nil, "override the deletion cascading strategy for the resources of a kind, e.g. StatefulSet=orphan. Can be specified multiple times or as comma-separated pairs")
+	f.BoolVar(&client.DeleteVolumeClaims, "delete-pvcs", false, "delete the PersistentVolumeClaims created by the StatefulSets of the release")
+	f.BoolVar(&client.ForceProtected, "force-protected", false, "uninstall the release even if it, or one of its resources, is protected against uninstall")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	addLockTimeoutFlag(f, &client.LockTimeout)
 	f.StringVar(&client.Description, "description", "", "add a custom description")
 	AddWaitFlag(cmd, &client.WaitStrategy)
+	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
 }
 
+// uninstallResult is the outcome of the uninstall of a release.
+type uninstallResult struct {
+	Name      string                      `json:"name"`
+	Info      string                      `json:"info,omitempty"`
+	Resources []release.UninstallResource `json:"resources,omitempty"`
+}
+
+type uninstallResults []uninstallResult
+
+func (r uninstallResults) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, r)
+}
+
+func (r uninstallResults) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, r)
+}
+
+func (r uninstallResults) WriteTable(out io.Writer) error {
+	for _, result := range r {
+		if len(result.Resources) > 0 {
+			tbl := uitable.New()
+			tbl.AddRow("ACTION", "KIND", "NAME", "NAMESPACE", "CASCADE", "REASON")
+			for _, res := range result.Resources {
+				tbl.AddRow(res.Action, res.Kind, res.Name, res.Namespace, res.Cascade, res.Reason)
+			}
+			if err := output.EncodeTable(out, tbl); err != nil {
+				return err
+			}
+		}
+		if result.Info != "" {
+			fmt.Fprintln(out, result.Info)
+		}
+		fmt.Fprintf(out, "release \"%s\" uninstalled\n", result.Name)
+	}
+	return nil
+}
+
 func validateCascadeFlag(client *action.Uninstall) error {
 	if client.DeletionPropagation != "background" && client.DeletionPropagation != "foreground" && client.DeletionPropagation != "orphan" {
 		return fmt.Errorf("invalid cascade value (%s). Must be \"background\", \"foreground\", or \"orphan\"", client.DeletionPropagation)
 	}
+	for kind, policy := range client.KindDeletionPropagation {
+		if kind == "" {
+			return fmt.Errorf("invalid cascade-for value (=%s). Must be KIND=STRATEGY", policy)
+		}
+		if policy != "background" && policy != "foreground" && policy != "orphan" {
+			return fmt.Errorf("invalid cascade-for value for %s (%s). Must be \"background\", \"foreground\", or \"orphan\"", kind, policy)
+		}
+	}
 	return nil
 }
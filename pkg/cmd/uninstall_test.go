@@ -73,6 +73,31 @@ func TestUninstall(t *testing.T) {
 			golden: "output/uninstall-wait.txt",
 			rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "aeneas"})},
 		},
+		{
+			name:   "cascade for a kind",
+			cmd:    "uninstall aeneas --cascade-for StatefulSet=orphan --delete-pvcs",
+			golden: "output/uninstall.txt",
+			rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "aeneas"})},
+		},
+		{
+			name:      "invalid cascade for a kind",
+			cmd:       "uninstall aeneas --cascade-for StatefulSet=never",
+			golden:    "output/uninstall-invalid-cascade-for.txt",
+			rels:      []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "aeneas"})},
+			wantError: true,
+		},
+		{
+			name:   "dry-run",
+			cmd:    "uninstall aeneas --dry-run",
+			golden: "output/uninstall-dry-run.txt",
+			rels:   []*release.Release{keptRelease()},
+		},
+		{
+			name:   "dry-run with json output",
+			cmd:    "uninstall aeneas --dry-run --output json",
+			golden: "output/uninstall-dry-run.json",
+			rels:   []*release.Release{keptRelease()},
+		},
 		{
 			name:      "uninstall without release",
 			cmd:       "uninstall",
@@ -83,6 +108,19 @@ func TestUninstall(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+// keptRelease returns a release with a resource kept by its resource policy.
+func keptRelease() *release.Release {
+	rel := release.Mock(&release.MockReleaseOptions{Name: "aeneas"})
+	rel.Manifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  annotations:
+    helm.sh/resource-policy: keep
+`
+	return rel
+}
+
 func TestUninstallCompletion(t *testing.T) {
 	checkReleaseCompletion(t, "uninstall", true)
 }
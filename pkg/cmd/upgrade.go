@@ -30,6 +30,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v4/internal/diff"
 	"helm.sh/helm/v4/pkg/action"
 	ci "helm.sh/helm/v4/pkg/chart"
 	"helm.sh/helm/v4/pkg/chart/loader"
@@ -37,6 +38,7 @@ import (
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
 	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/engine"
 	"helm.sh/helm/v4/pkg/getter"
 	ri "helm.sh/helm/v4/pkg/release"
 	"helm.sh/helm/v4/pkg/release/common"
@@ -77,9 +79,58 @@ or '--set' flags. Priority is given to new values.
 
     $ helm upgrade --reuse-values --set foo=bar --set foo=newbar redis ./redis
 
+By default, nested maps of the existing values are merged with the new ones.
+Use '--reuse-values-strategy=shallow' to replace each top-level key that is set
+again instead, or '--reuse-values-strategy=deep' to keep keys set to null so
+that they also remove the chart's defaults.
+
 The --dry-run flag will output all generated chart manifests, including Secrets
 which can contain sensitive values. To hide Kubernetes Secrets use the
 --hide-secret flag. Please carefully consider how and when these flags are used.
+
+The --diff flag prints the changes the upgrade makes to the manifest of the
+deployed release, resource by resource, before performing it. The values of
+Secrets are redacted, and Secrets are left out with --hide-secret. To only
+preview the changes without upgrading, use '--dry-run=diff':
+
+    $ helm upgrade --dry-run=diff redis ./redis
+
+The --only flag restricts an upgrade to the rendered resources matching a
+selector, leaving all other resources of the release as they are. This is
+useful to roll out a single change, such as a ConfigMap, in an emergency:
+
+    $ helm upgrade --only kind=ConfigMap,name=redis-config redis ./redis
+
+The --rollout-strategy=canary flag updates the Deployments and StatefulSets of
+the release gradually. StatefulSets are updated by partitioning their rolling
+update. The new version of a Deployment runs in a canary Deployment named
+'<name>-canary', while the Deployment is scaled down by as many replicas, and
+the canary Deployment is deleted by the last step. At each step, the given
+percentage of replicas is updated and Helm waits for them to become ready
+before continuing. If a step fails, the resources of the release are reverted
+to the previous version. Other workloads, such as DaemonSets, cannot be split
+this way, so the upgrade fails if their pod template changes:
+
+    $ helm upgrade --rollout-strategy=canary --rollout-steps=10,50,100 redis ./redis
+
+The --prune flag deletes resources labeled with 'app.kubernetes.io/managed-by=Helm'
+that carry the ownership annotations of the release but are no longer rendered
+by the chart. Resources owned by other resources, such as the Pods of a
+Deployment, are never pruned. Only resources of common built-in kinds are
+pruned, use '--prune-allowlist' to choose the kinds instead:
+
+    $ helm upgrade --prune --prune-allowlist=core/v1/ConfigMap,batch/v1/Job redis ./redis
+
+When waiting on resources, the --timeout-per-resource flag overrides --timeout
+for resources of the given kinds. Each group of resources is waited on in turn
+with its own timeout:
+
+    $ helm upgrade --wait --timeout-per-resource=StatefulSet=20m,ConfigMap=30s redis ./redis
+
+Before upgrading, Helm checks the rendered manifests for Kubernetes APIs that
+are deprecated or removed in the version of the cluster and warns about them.
+Use '--api-deprecation-check=error' to fail the upgrade when an API has been
+removed, or '--api-deprecation-check=off' to skip the check.
 `
 
 func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -87,6 +138,11 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	valueOpts := &values.Options{}
 	var outfmt output.Format
 	var createNamespace bool
+	var parallelism int
+	var showDiff bool
+	var waitFor []string
+	var only []string
+	var pruneAllowlist []string
 
 	cmd := &cobra.Command{
 		Use:   "upgrade [RELEASE] [CHART]",
@@ -105,6 +161,9 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client.Namespace = settings.Namespace()
 
+			cfg.RenderWarnings = &engine.RenderWarnings{}
+			defer printRenderWarnings(cmd.ErrOrStderr(), cfg.RenderWarnings)
+
 			registryClient, err := newRegistryClient(out, client.CertFile, client.KeyFile, client.CaFile,
 				client.InsecureSkipTLSVerify, client.PlainHTTP, client.Username, client.Password)
 			if err != nil {
@@ -112,11 +171,43 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			}
 			client.SetRegistryClient(registryClient)
 
-			dryRunStrategy, err := cmdGetDryRunFlagStrategy(cmd, false)
+			// '--dry-run=diff' is a server-side dry run that only prints the diff
+			diffOnly := cmd.Flag("dry-run").Value.String() == "diff"
+			if diffOnly {
+				client.DryRunStrategy = action.DryRunServer
+			} else {
+				dryRunStrategy, err := cmdGetDryRunFlagStrategy(cmd, false)
+				if err != nil {
+					return err
+				}
+				client.DryRunStrategy = dryRunStrategy
+			}
+			waitOpts, err := waitForOptions(cmd, waitFor, &client.WaitStrategy)
 			if err != nil {
 				return err
 			}
-			client.DryRunStrategy = dryRunStrategy
+			client.WaitOptions = append(client.WaitOptions, waitOpts...)
+
+			for _, s := range only {
+				sel, err := action.ParseResourceSelector(s)
+				if err != nil {
+					return err
+				}
+				client.Only = append(client.Only, sel)
+			}
+
+			for _, s := range pruneAllowlist {
+				gvk, err := action.ParseGroupVersionKind(s)
+				if err != nil {
+					return err
+				}
+				client.PruneAllowlist = append(client.PruneAllowlist, gvk)
+			}
+
+			if showDiff || diffOnly {
+				client.DiffOutput = out
+				client.DiffNoColor = settings.ShouldDisableColor()
+			}
 
 			// Fixes #7002 - Support reading values from STDIN for `upgrade` command
 			// Must load values AFTER determining if we have to call install so that values loaded from stdin are not read twice
@@ -126,19 +217,25 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				histClient.Max = 1
 				versions, err := histClient.Run(args[0])
 				if errors.Is(err, driver.ErrReleaseNotFound) || isReleaseUninstalled(versions) {
+					if len(client.Only) > 0 {
+						return fmt.Errorf("release %q does not exist, --only can only be used to upgrade a deployed release", args[0])
+					}
 					// Only print this to stdout for table output
-					if outfmt == output.Table {
+					if outfmt == output.Table && !diffOnly {
 						fmt.Fprintf(out, "Release %q does not exist. Installing it now.\n", args[0])
 					}
 					instClient := action.NewInstall(cfg)
 					instClient.CreateNamespace = createNamespace
+					instClient.Parallelism = parallelism
 					instClient.ChartPathOptions = client.ChartPathOptions
 					instClient.ForceReplace = client.ForceReplace
 					instClient.DryRunStrategy = client.DryRunStrategy
 					instClient.DisableHooks = client.DisableHooks
 					instClient.SkipCRDs = client.SkipCRDs
 					instClient.Timeout = client.Timeout
+					instClient.LockTimeout = client.LockTimeout
 					instClient.WaitStrategy = client.WaitStrategy
+					instClient.WaitOptions = client.WaitOptions
 					instClient.WaitForJobs = client.WaitForJobs
 					instClient.Devel = client.Devel
 					instClient.Namespace = client.Namespace
@@ -148,14 +245,18 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					instClient.SubNotes = client.SubNotes
 					instClient.HideNotes = client.HideNotes
 					instClient.SkipSchemaValidation = client.SkipSchemaValidation
+					instClient.StrictValues = client.StrictValues
+					instClient.Profile = client.Profile
 					instClient.Description = client.Description
 					instClient.DependencyUpdate = client.DependencyUpdate
+					instClient.Locked = client.Locked
 					instClient.Labels = client.Labels
 					instClient.EnableDNS = client.EnableDNS
 					instClient.HideSecret = client.HideSecret
 					instClient.TakeOwnership = client.TakeOwnership
 					instClient.ForceConflicts = client.ForceConflicts
 					instClient.ServerSideApply = client.ServerSideApply != "false"
+					instClient.Preflight = client.Preflight
 
 					if isReleaseUninstalled(versions) {
 						instClient.Replace = true
@@ -163,7 +264,12 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 					rel, err := runInstall(args, instClient, valueOpts, out)
 					if err != nil {
-						return err
+						return withForceConflictsHint(err, instClient.ForceConflicts)
+					}
+					// All the resources of a new release are added
+					if diffOnly {
+						changes := diff.Manifests("", rel.Manifest)
+						return diff.Write(out, changes, diff.Options{Context: client.DiffContext, NoColor: client.DiffNoColor, HideSecrets: client.HideSecret})
 					}
 					return outfmt.Write(out, &statusPrinter{
 						release:      rel,
@@ -188,7 +294,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			}
 
 			p := getter.All(settings)
-			vals, err := valueOpts.MergeValues(p)
+			vals, valuesTrace, err := valueOpts.MergeValuesWithTrace(p)
 			if err != nil {
 				return err
 			}
@@ -199,6 +305,12 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				return err
 			}
 
+			if client.Locked {
+				if err := verifyLock(chartPath); err != nil {
+					return err
+				}
+			}
+
 			ac, err := ci.NewAccessor(ch)
 			if err != nil {
 				return err
@@ -235,6 +347,14 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				slog.Warn("this chart is deprecated")
 			}
 
+			if vals, err = valueOpts.ApplyJSONPatches(ch, vals, p, valuesTrace); err != nil {
+				return err
+			}
+			client.ValuesTrace = valuesTrace
+			if client.SecretProviders, client.SecretReferences, err = secretResolution(valueOpts); err != nil {
+				return err
+			}
+
 			// Create context and prepare the handle of SIGTERM
 			ctx := context.Background()
 			ctx, cancel := context.WithCancel(ctx)
@@ -252,7 +372,11 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 			rel, err := client.RunWithContext(ctx, args[0], ch, vals)
 			if err != nil {
-				return fmt.Errorf("UPGRADE FAILED: %w", err)
+				return fmt.Errorf("UPGRADE FAILED: %w", withForceConflictsHint(err, client.ForceConflicts))
+			}
+
+			if diffOnly {
+				return nil
 			}
 
 			if outfmt == output.Table {
@@ -271,6 +395,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	f.BoolVar(&createNamespace, "create-namespace", false, "if --install is set, create the release namespace if not present")
+	f.IntVar(&parallelism, "parallelism", 0, "if --install is set and the release is installed, maximum number of resources created concurrently. 0 means no limit")
 	f.BoolVarP(&client.Install, "install", "i", false, "if a release by this name doesn't already exist, run an install")
 	f.BoolVar(&client.Devel, "devel", false, "use development versions, too. Equivalent to version '>0.0.0-0'. If --version is set, this is ignored")
 	f.BoolVar(&client.HideSecret, "hide-secret", false, "hide Kubernetes Secrets when also using the --dry-run flag")
@@ -279,35 +404,59 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.MarkDeprecated("force", "use --force-replace instead")
 	f.BoolVar(&client.ForceConflicts, "force-conflicts", false, "if set server-side apply will force changes against conflicts")
 	f.StringVar(&client.ServerSideApply, "server-side", "auto", "must be \"true\", \"false\" or \"auto\". Object updates run in the server instead of the client (\"auto\" defaults the value from the previous chart release's method)")
+	addApplyMethodFlagWithAuto(cmd, &client.ServerSideApply)
+	addPreflightFlag(cmd, &client.Preflight)
+	addRolloutFlags(cmd, &client.RolloutStrategy, &client.RolloutSteps)
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "disable pre/post upgrade hooks")
 	f.BoolVar(&client.DisableOpenAPIValidation, "disable-openapi-validation", false, "if set, the upgrade process will not validate rendered templates against the Kubernetes OpenAPI Schema")
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed when an upgrade is performed with install flag enabled. By default, CRDs are installed if not already present, when an upgrade is performed with install flag enabled")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	addLockTimeoutFlag(f, &client.LockTimeout)
+	addTimeoutPerResourceFlag(cmd, &client.TimeoutPerKind)
+	addAPIDeprecationCheckFlag(cmd, &client.APIDeprecationCheck)
 	f.BoolVar(&client.ResetValues, "reset-values", false, "when upgrading, reset the values to the ones built into the chart")
 	f.BoolVar(&client.ReuseValues, "reuse-values", false, "when upgrading, reuse the last release's values and merge in any overrides from the command line via --set and -f. If '--reset-values' is specified, this is ignored")
 	f.BoolVar(&client.ResetThenReuseValues, "reset-then-reuse-values", false, "when upgrading, reset the values to the ones built into the chart, apply the last release's values and merge in any overrides from the command line via --set and -f. If '--reset-values' or '--reuse-values' is specified, this is ignored")
+	addReuseValuesStrategyFlag(cmd, &client.ReuseValuesStrategy)
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.RollbackOnFailure, "rollback-on-failure", false, "if set, Helm will rollback the upgrade to previous success release upon failure. The --wait flag will be defaulted to \"watcher\" if --rollback-on-failure is set")
 	f.BoolVar(&client.RollbackOnFailure, "atomic", false, "deprecated")
 	f.MarkDeprecated("atomic", "use --rollback-on-failure instead")
 	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	addHistoryRetentionFlags(f, &client.HistoryRetention)
+	f.BoolVar(&client.AtomicHooks, "atomic-hooks", false, "if set, Helm will snapshot the live release resources before upgrading, and restore them and delete the resources of the upgrade hooks if the upgrade or any of its hooks fails")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this upgrade when upgrade fails")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
 	f.BoolVar(&client.HideNotes, "hide-notes", false, "if set, do not show notes in upgrade output. Does not affect presence in chart metadata")
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
+	f.BoolVar(&client.StrictValues, "strict-values", false, "if set, fail when the supplied values contain keys that are neither used by a template nor declared in values.schema.json")
+	f.StringVar(&client.Profile, "profile", "", "apply the named values profile of the chart, such as 'prod' for profiles/prod.yaml, over its default values")
 	f.StringToStringVarP(&client.Labels, "labels", "l", nil, "Labels that would be added to release metadata. Should be separated by comma. Original release labels will be merged with upgrade labels. You can unset label using null.")
 	f.StringVar(&client.Description, "description", "", "add a custom description")
 	f.BoolVar(&client.DependencyUpdate, "dependency-update", false, "update dependencies if they are missing before installing the chart")
+	f.BoolVar(&client.Locked, "locked", false, "fail if the lock file is missing or out of sync with Chart.yaml, or if the charts in charts/ do not match the lock file")
 	f.BoolVar(&client.EnableDNS, "enable-dns", false, "enable DNS lookups when rendering templates")
 	f.BoolVar(&client.TakeOwnership, "take-ownership", false, "if set, upgrade will ignore the check for helm annotations and take ownership of the existing resources")
+	f.BoolVar(&showDiff, "diff", false, "print a diff of the manifest changes before upgrading. Use '--dry-run=diff' to only print the diff")
+	f.IntVar(&client.DiffContext, "diff-context", client.DiffContext, "number of unchanged lines to show around each change in the diff")
 	addDryRunFlag(cmd)
+	f.Lookup("dry-run").Usage += ` Upgrade also accepts "diff": '--dry-run=diff' simulates the upgrade on the server and only prints a diff of the manifest changes, with the values of Secrets redacted.`
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
 	addValueOptionsFlags(f, valueOpts)
+	addJSONPatchFlags(f, valueOpts)
+	addSecretFlags(f, valueOpts)
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer, settings)
 	AddWaitFlag(cmd, &client.WaitStrategy)
+	addWaitForFlag(cmd, &waitFor)
+	f.StringArrayVar(&only, "only", nil, "only apply the rendered resources matching the selector, e.g. 'kind=Deployment,name=web'. Selectors can match on kind, name and namespace. All other resources are left unchanged. Can be specified multiple times")
+	f.BoolVar(&client.Prune, "prune", false, "delete the resources of the release that are no longer rendered by the chart")
+	f.StringSliceVar(&pruneAllowlist, "prune-allowlist", nil, "limit --prune to resources of the given kinds, in the form GROUP/VERSION/KIND, e.g. 'core/v1/ConfigMap' or 'apps/v1/Deployment'. Can be specified multiple times")
 	cmd.MarkFlagsMutuallyExclusive("force-replace", "force-conflicts")
+	cmd.MarkFlagsMutuallyExclusive("dependency-update", "locked")
 	cmd.MarkFlagsMutuallyExclusive("force", "force-conflicts")
+	cmd.MarkFlagsMutuallyExclusive("atomic-hooks", "rollback-on-failure")
+	cmd.MarkFlagsMutuallyExclusive("atomic-hooks", "atomic")
 
 	err := cmd.RegisterFlagCompletionFunc("version", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) != 2 {
@@ -596,6 +596,70 @@ func TestUpgradeWithDryRun(t *testing.T) {
 	}
 }
 
+func TestUpgradeWithDiff(t *testing.T) {
+	releaseName := "funny-bunny-diff"
+	relMock, ch, chartPath := prepareMockRelease(t, releaseName)
+
+	defer resetEnv()()
+
+	store := storageFixture()
+	rel := relMock(releaseName, 1, ch)
+	rel.Manifest = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: previous\n"
+	if err := store.Create(rel); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := fmt.Sprintf("upgrade %s --dry-run=diff '%s'", releaseName, chartPath)
+	_, out, err := executeActionCommandC(store, cmd)
+	if err != nil {
+		t.Fatalf("unexpected error, got '%v'", err)
+	}
+
+	if !strings.Contains(out, "-, previous, ConfigMap has been removed:") {
+		t.Errorf("expected removed ConfigMap in diff output, got %q", out)
+	}
+	if strings.Contains(out, "has been upgraded") {
+		t.Errorf("expected only the diff in output from --dry-run=diff, got %q", out)
+	}
+
+	// No second release should be stored because this is a dry run.
+	if _, err := store.Get(releaseName, 2); err == nil {
+		t.Error("expected error as there should be no new release but got none")
+	}
+
+	cmd = fmt.Sprintf("upgrade %s --diff '%s'", releaseName, chartPath)
+	_, out, err = executeActionCommandC(store, cmd)
+	if err != nil {
+		t.Fatalf("unexpected error, got '%v'", err)
+	}
+	if !strings.Contains(out, "-, previous, ConfigMap has been removed:") || !strings.Contains(out, "has been upgraded") {
+		t.Errorf("expected diff followed by upgrade output, got %q", out)
+	}
+}
+
+func TestUpgradeInstallWithDiff(t *testing.T) {
+	releaseName := "funny-bunny-new"
+	_, _, chartPath := prepareMockRelease(t, releaseName)
+
+	defer resetEnv()()
+
+	store := storageFixture()
+	cmd := fmt.Sprintf("upgrade %s --install --dry-run=diff '%s'", releaseName, chartPath)
+	_, out, err := executeActionCommandC(store, cmd)
+	if err != nil {
+		t.Fatalf("unexpected error, got '%v'", err)
+	}
+	if !strings.Contains(out, "has been added:") {
+		t.Errorf("expected added resources in diff output, got %q", out)
+	}
+	if strings.Contains(out, "Installing it now") || strings.Contains(out, "STATUS:") {
+		t.Errorf("expected only the diff in output from --dry-run=diff, got %q", out)
+	}
+	if _, err := store.Get(releaseName, 1); err == nil {
+		t.Error("expected error as there should be no release but got none")
+	}
+}
+
 func TestUpgradeInstallServerSideApply(t *testing.T) {
 	_, _, chartPath := prepareMockRelease(t, "ssa-test")
 
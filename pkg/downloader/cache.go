@@ -16,14 +16,19 @@ limitations under the License.
 package downloader
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"helm.sh/helm/v4/internal/fileutil"
 )
@@ -44,6 +49,33 @@ var CacheChart = ".chart"
 // CacheProv specifies the content is a provenance file
 var CacheProv = ".prov"
 
+// RefCache is implemented by caches that can remember which content a chart
+// reference was resolved to, so it can be reused without resolving the
+// reference again.
+type RefCache interface {
+	// GetRef returns the key of the content the given reference was last resolved to.
+	GetRef(ref string) ([sha256.Size]byte, error)
+	// PutRef records that the given reference resolved to the content with the given key.
+	PutRef(ref string, key [sha256.Size]byte) error
+}
+
+// CacheMode controls how a ChartDownloader uses its cache.
+type CacheMode int
+
+const (
+	// CacheDigest reads a chart from the cache only when its digest is known
+	// before downloading, e.g. from a repository index. This is the default.
+	CacheDigest CacheMode = iota
+	// CachePreferred additionally reuses a chart previously downloaded for the
+	// same reference and version, without contacting the repository or registry.
+	CachePreferred
+	// CacheBypass never reads from the cache; the chart is always downloaded.
+	CacheBypass
+)
+
+// refsDir is the directory within a DiskCache holding the reference mappings.
+const refsDir = "refs"
+
 // TODO: The cache assumes files because much of Helm assumes files. Convert
 // Helm to pass content around instead of file locations.
 
@@ -90,3 +122,138 @@ func (c *DiskCache) Put(key [sha256.Size]byte, data io.Reader, cacheType string)
 func (c *DiskCache) fileName(id [sha256.Size]byte, cacheType string) string {
 	return filepath.Join(c.Root, fmt.Sprintf("%02x", id[0]), hex.EncodeToString(id[:])+cacheType)
 }
+
+// GetRef returns the key of the content the given reference was last resolved to.
+func (c *DiskCache) GetRef(ref string) ([sha256.Size]byte, error) {
+	var key [sha256.Size]byte
+	data, err := os.ReadFile(c.refFileName(ref))
+	if err != nil {
+		return key, err
+	}
+	digest, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return key, fmt.Errorf("corrupt cache reference for %q: %w", ref, err)
+	}
+	if len(digest) != sha256.Size {
+		return key, fmt.Errorf("corrupt cache reference for %q: invalid digest length %d", ref, len(digest))
+	}
+	copy(key[:], digest)
+	return key, nil
+}
+
+// PutRef records that the given reference resolved to the content with the given key.
+func (c *DiskCache) PutRef(ref string, key [sha256.Size]byte) error {
+	p := c.refFileName(ref)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return fileutil.AtomicWriteFile(p, strings.NewReader(hex.EncodeToString(key[:])), 0644)
+}
+
+func (c *DiskCache) refFileName(ref string) string {
+	id := sha256.Sum256([]byte(ref))
+	return filepath.Join(c.Root, refsDir, hex.EncodeToString(id[:]))
+}
+
+// CacheEntry describes a file stored in a DiskCache.
+type CacheEntry struct {
+	// Digest is the hex encoded sha256 digest of the content
+	Digest string
+	// Type is the type of content, e.g. CacheChart or CacheProv
+	Type string
+	// Path is the location of the file on disk
+	Path string
+	// Size is the size of the file in bytes
+	Size int64
+	// ModTime is when the file was stored in the cache
+	ModTime time.Time
+}
+
+// Entries returns the content stored in the cache, ordered by digest and type.
+func (c *DiskCache) Entries() ([]CacheEntry, error) {
+	var entries []CacheEntry
+	err := filepath.WalkDir(c.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == c.Root {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == refsDir && filepath.Dir(p) == c.Root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(p)
+		if ext != CacheChart && ext != CacheProv {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, CacheEntry{
+			Digest:  strings.TrimSuffix(d.Name(), ext),
+			Type:    ext,
+			Path:    p,
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
+		})
+		return nil
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Digest == entries[j].Digest {
+			return entries[i].Type < entries[j].Type
+		}
+		return entries[i].Digest < entries[j].Digest
+	})
+	return entries, err
+}
+
+// Prune removes the content stored in the cache before the given time, along
+// with any references to content that no longer exists. It returns the
+// entries that were removed.
+func (c *DiskCache) Prune(before time.Time) ([]CacheEntry, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var removed []CacheEntry
+	for _, e := range entries {
+		if !e.ModTime.Before(before) {
+			continue
+		}
+		if err := os.Remove(e.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		removed = append(removed, e)
+	}
+
+	refs, err := os.ReadDir(filepath.Join(c.Root, refsDir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return removed, nil
+		}
+		return removed, err
+	}
+	for _, r := range refs {
+		p := filepath.Join(c.Root, refsDir, r.Name())
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return removed, err
+		}
+		digest, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+		var key [sha256.Size]byte
+		copy(key[:], digest)
+		if err == nil && len(digest) == sha256.Size {
+			if _, err := c.Get(key, CacheChart); err == nil {
+				continue
+			}
+		}
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
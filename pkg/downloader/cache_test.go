@@ -22,6 +22,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -120,3 +121,66 @@ func TestDiskCache_fileName(t *testing.T) {
 	assert.Equal(t, filepath.Join("/tmp/cache", "13", "1307990e6ba5ca145eb35e99182a9bec46531bc54ddf656a602c780fa0240dee.chart"), cache.fileName(key, CacheChart))
 	assert.Equal(t, filepath.Join("/tmp/cache", "13", "1307990e6ba5ca145eb35e99182a9bec46531bc54ddf656a602c780fa0240dee.prov"), cache.fileName(key, CacheProv))
 }
+
+func TestDiskCache_Refs(t *testing.T) {
+	cache := &DiskCache{Root: t.TempDir()}
+	key := sha256.Sum256([]byte("chart data"))
+
+	_, err := cache.GetRef("repo/chart@1.0.0")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, cache.PutRef("repo/chart@1.0.0", key))
+	got, err := cache.GetRef("repo/chart@1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	// Refs are not content and must not be listed as entries
+	entries, err := cache.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDiskCache_EntriesAndPrune(t *testing.T) {
+	cache := &DiskCache{Root: t.TempDir()}
+
+	oldData := []byte("old chart")
+	oldKey := sha256.Sum256(oldData)
+	oldPath, err := cache.Put(oldKey, bytes.NewReader(oldData), CacheChart)
+	require.NoError(t, err)
+	require.NoError(t, cache.PutRef("repo/old@1.0.0", oldKey))
+	longAgo := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(oldPath, longAgo, longAgo))
+
+	newData := []byte("new chart")
+	newKey := sha256.Sum256(newData)
+	_, err = cache.Put(newKey, bytes.NewReader(newData), CacheChart)
+	require.NoError(t, err)
+	_, err = cache.Put(newKey, bytes.NewReader([]byte("prov")), CacheProv)
+	require.NoError(t, err)
+	require.NoError(t, cache.PutRef("repo/new@1.0.0", newKey))
+
+	entries, err := cache.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	removed, err := cache.Prune(time.Now().Add(-24 * time.Hour))
+	require.NoError(t, err)
+	require.Len(t, removed, 1)
+	assert.Equal(t, oldPath, removed[0].Path)
+
+	_, err = cache.GetRef("repo/old@1.0.0")
+	assert.ErrorIs(t, err, os.ErrNotExist, "references to pruned content should be removed")
+	_, err = cache.GetRef("repo/new@1.0.0")
+	assert.NoError(t, err)
+
+	entries, err = cache.Entries()
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestDiskCache_EntriesMissingRoot(t *testing.T) {
+	cache := &DiskCache{Root: filepath.Join(t.TempDir(), "missing")}
+	entries, err := cache.Entries()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
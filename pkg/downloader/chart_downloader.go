@@ -37,6 +37,7 @@ import (
 	"helm.sh/helm/v4/pkg/provenance"
 	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/repo/v1"
+	"helm.sh/helm/v4/pkg/signing"
 )
 
 // VerificationStrategy describes a strategy for determining whether to verify a chart.
@@ -70,6 +71,9 @@ type ChartDownloader struct {
 	Verify VerificationStrategy
 	// Keyring is the keyring file used for verification.
 	Keyring string
+	// KeylessVerifier, when set, verifies the Sigstore keyless signature of
+	// the chart, fetched from the .sigstore.json bundle next to it.
+	KeylessVerifier *signing.Verifier
 	// Getter collection for the operation
 	Getters getter.Providers
 	// Options provide parameters to be passed along to the Getter being initialized.
@@ -85,6 +89,9 @@ type ChartDownloader struct {
 
 	// Cache specifies the cache implementation to use.
 	Cache Cache
+
+	// CacheMode controls when DownloadToCache reads charts from the cache.
+	CacheMode CacheMode
 }
 
 // DownloadTo retrieves a chart. Depending on the settings, it may also download a provenance file.
@@ -116,9 +123,10 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 		return "", nil, err
 	}
 
-	// Check the cache for the content. Otherwise download it.
-	// Note, this process will pull from the cache but does not automatically populate
-	// the cache with the file it downloads.
+	// Check the cache for the content. Otherwise download it. A downloaded
+	// chart is added to the cache if it matches its digest, so charts shared
+	// by several charts, or downloaded by an interrupted run, are downloaded
+	// once.
 	var data *bytes.Buffer
 	var found bool
 	var digest []byte
@@ -152,6 +160,11 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 		if err != nil {
 			return "", nil, err
 		}
+		if hash != "" && sha256.Sum256(data.Bytes()) == digest32 {
+			if _, err := c.Cache.Put(digest32, bytes.NewReader(data.Bytes()), CacheChart); err != nil {
+				slog.Debug("failed to put downloaded chart in cache", "id", hash, "error", err)
+			}
+		}
 	}
 
 	name := filepath.Base(u.Path)
@@ -169,6 +182,12 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 		return destfile, nil, err
 	}
 
+	if c.KeylessVerifier != nil {
+		if err := c.verifyKeyless(g, u, destfile); err != nil {
+			return destfile, nil, err
+		}
+	}
+
 	// If provenance is requested, verify it.
 	ver := &provenance.Verification{}
 	if c.Verify > VerifyNever {
@@ -213,6 +232,30 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 	return destfile, ver, nil
 }
 
+// verifyKeyless fetches the Sigstore bundle of the chart at u, and verifies
+// the chart downloaded to chartPath with it.
+func (c *ChartDownloader) verifyKeyless(g getter.Getter, u *url.URL, chartPath string) error {
+	bundleURL := u.String() + signing.BundleExt
+	body, err := g.Get(bundleURL, c.Options...)
+	if err != nil {
+		return fmt.Errorf("failed to fetch Sigstore bundle %q: %w", bundleURL, err)
+	}
+	b, err := signing.ParseBundle(body.Bytes())
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(chartPath)
+	if err != nil {
+		return err
+	}
+	ver, err := c.KeylessVerifier.Verify(data, b)
+	if err != nil {
+		return fmt.Errorf("keyless verification of %s failed: %w", u, err)
+	}
+	slog.Debug("verified keyless signature", "chart", u, "identity", ver.Identity, "issuer", ver.Issuer, "logIndex", ver.LogIndex)
+	return nil
+}
+
 // DownloadToCache retrieves resources while using a content based cache.
 func (c *ChartDownloader) DownloadToCache(ref, version string) (string, *provenance.Verification, error) {
 	if c.Cache == nil {
@@ -223,6 +266,17 @@ func (c *ChartDownloader) DownloadToCache(ref, version string) (string, *provena
 		slog.Debug("set up default downloader cache")
 	}
 
+	refCache, _ := c.Cache.(RefCache)
+	refKey := ref + "@" + version
+	if refCache != nil && c.CacheMode == CachePreferred && c.Verify == VerifyNever && c.KeylessVerifier == nil {
+		if key, err := refCache.GetRef(refKey); err == nil {
+			if pth, err := c.Cache.Get(key, CacheChart); err == nil {
+				slog.Debug("found chart reference in cache", "ref", ref, "version", version, "id", hex.EncodeToString(key[:]))
+				return pth, &provenance.Verification{}, nil
+			}
+		}
+	}
+
 	digestString, u, err := c.ResolveChartVersion(ref, version)
 	if err != nil {
 		return "", nil, err
@@ -249,13 +303,13 @@ func (c *ChartDownloader) DownloadToCache(ref, version string) (string, *provena
 
 	var pth string
 	// only fetch from the cache if we have a digest
-	if len(digest) > 0 {
+	if len(digest) > 0 && c.CacheMode != CacheBypass {
 		pth, err = c.Cache.Get(digest32, CacheChart)
 		if err == nil {
 			slog.Debug("found chart in cache", "id", digestString)
 		}
 	}
-	if len(digest) == 0 || err != nil {
+	if len(digest) == 0 || c.CacheMode == CacheBypass || err != nil {
 		slog.Debug("attempting to download chart", "ref", ref, "version", version)
 		if err != nil && !os.IsNotExist(err) {
 			return "", nil, err
@@ -278,6 +332,17 @@ func (c *ChartDownloader) DownloadToCache(ref, version string) (string, *provena
 		}
 		slog.Debug("put downloaded chart in cache", "id", hex.EncodeToString(digest32[:]))
 	}
+	if refCache != nil {
+		if err := refCache.PutRef(refKey, digest32); err != nil {
+			slog.Debug("failed to record chart reference in cache", "ref", ref, "error", err)
+		}
+	}
+
+	if c.KeylessVerifier != nil {
+		if err := c.verifyKeyless(g, u, pth); err != nil {
+			return pth, nil, err
+		}
+	}
 
 	// If provenance is requested, verify it.
 	ver := &provenance.Verification{}
@@ -389,7 +454,7 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (string, *url
 		// we want to find the repo in case we have special SSL cert config
 		// for that repo.
 
-		rc, err := c.scanReposForURL(ref, rf)
+		rc, digest, err := c.scanReposForURL(ref, rf)
 		if err != nil {
 			// If there is no special config, return the default HTTP client and
 			// swallow the error.
@@ -417,7 +482,7 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (string, *url
 				getter.WithPassCredentialsAll(rc.PassCredentialsAll),
 			)
 		}
-		return "", u, nil
+		return digest, u, nil
 	}
 
 	// See if it's of the form: repo/path_to_chart
@@ -541,7 +606,8 @@ func pickChartRepositoryConfigByName(name string, cfgs []*repo.Entry) (*repo.Ent
 //
 // This will attempt to find the given URL in all of the known repositories files.
 //
-// If the URL is found, this will return the repo entry that contained that URL.
+// If the URL is found, this will return the repo entry that contained that URL
+// along with the digest the repository index records for it.
 //
 // If all of the repos are checked, but the URL is not found, an ErrNoOwnerRepo
 // error is returned.
@@ -555,33 +621,33 @@ func pickChartRepositoryConfigByName(name string, cfgs []*repo.Entry) (*repo.Ent
 // The same URL can technically exist in two or more repositories. This algorithm
 // will return the first one it finds. Order is determined by the order of repositories
 // in the repositories.yaml file.
-func (c *ChartDownloader) scanReposForURL(u string, rf *repo.File) (*repo.Entry, error) {
+func (c *ChartDownloader) scanReposForURL(u string, rf *repo.File) (*repo.Entry, string, error) {
 	// FIXME: This is far from optimal. Larger installations and index files will
 	// incur a performance hit for this type of scanning.
 	for _, rc := range rf.Repositories {
 		r, err := repo.NewChartRepository(rc, c.Getters)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		idxFile := filepath.Join(c.RepositoryCache, helmpath.CacheIndexFile(r.Config.Name))
 		i, err := repo.LoadIndexFile(idxFile)
 		if err != nil {
-			return nil, fmt.Errorf("no cached repo found. (try 'helm repo update'): %w", err)
+			return nil, "", fmt.Errorf("no cached repo found. (try 'helm repo update'): %w", err)
 		}
 
 		for _, entry := range i.Entries {
 			for _, ver := range entry {
 				for _, dl := range ver.URLs {
 					if urlutil.Equal(u, dl) {
-						return rc, nil
+						return rc, ver.Digest, nil
 					}
 				}
 			}
 		}
 	}
 	// This means that there is no repo file for the given URL.
-	return nil, ErrNoOwnerRepo
+	return nil, "", ErrNoOwnerRepo
 }
 
 func loadRepoConfig(file string) (*repo.File, error) {
@@ -367,7 +367,7 @@ func TestScanReposForURL(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	entry, err := c.scanReposForURL(u, rf)
+	entry, _, err := c.scanReposForURL(u, rf)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -378,7 +378,7 @@ func TestScanReposForURL(t *testing.T) {
 
 	// A lookup failure should produce an ErrNoOwnerRepo
 	u = "https://no.such.repo/foo/bar-1.23.4.tgz"
-	if _, err = c.scanReposForURL(u, rf); !errors.Is(err, ErrNoOwnerRepo) {
+	if _, _, err = c.scanReposForURL(u, rf); !errors.Is(err, ErrNoOwnerRepo) {
 		t.Fatalf("expected ErrNoOwnerRepo, got %v", err)
 	}
 }
@@ -488,6 +488,64 @@ func TestDownloadToCache(t *testing.T) {
 	})
 }
 
+func TestDownloadToCache_CacheMode(t *testing.T) {
+	srv := repotest.NewTempServer(t,
+		repotest.WithChartSourceGlob("testdata/*.tgz*"),
+	)
+	defer srv.Stop()
+	if err := srv.CreateIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+
+	repoFile := filepath.Join(srv.Root(), "repositories.yaml")
+	repoCache := srv.Root()
+	contentCache := t.TempDir()
+
+	newDownloader := func(repoFile string, mode CacheMode) *ChartDownloader {
+		return &ChartDownloader{
+			Out:              os.Stderr,
+			Verify:           VerifyNever,
+			RepositoryConfig: repoFile,
+			RepositoryCache:  repoCache,
+			Getters: getter.All(&cli.EnvSettings{
+				RepositoryConfig: repoFile,
+				RepositoryCache:  repoCache,
+				ContentCache:     contentCache,
+			}),
+			Cache:     &DiskCache{Root: contentCache},
+			CacheMode: mode,
+		}
+	}
+
+	pth, _, err := newDownloader(repoFile, CacheDigest).DownloadToCache("test/signtest", "0.1.0")
+	require.NoError(t, err)
+
+	// Without the repository configuration the reference can no longer be
+	// resolved, so only the cached reference can satisfy the request.
+	missingRepoFile := filepath.Join(t.TempDir(), "repositories.yaml")
+
+	_, _, err = newDownloader(missingRepoFile, CacheDigest).DownloadToCache("test/signtest", "0.1.0")
+	require.Error(t, err, "the default mode must resolve the reference")
+
+	cached, _, err := newDownloader(missingRepoFile, CachePreferred).DownloadToCache("test/signtest", "0.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, pth, cached)
+
+	_, _, err = newDownloader(missingRepoFile, CachePreferred).DownloadToCache("test/signtest", "0.2.0")
+	require.Error(t, err, "a different version must not be served from the cache")
+
+	// Bypassing the cache downloads the chart again, replacing the cached copy
+	require.NoError(t, os.WriteFile(pth, []byte("corrupt"), 0644))
+	pth, _, err = newDownloader(repoFile, CacheBypass).DownloadToCache("test/signtest", "0.1.0")
+	require.NoError(t, err)
+	data, err := os.ReadFile(pth)
+	require.NoError(t, err)
+	assert.NotEqual(t, "corrupt", string(data))
+}
+
 func TestStripDigestAlgorithm(t *testing.T) {
 	tests := map[string]struct {
 		input    string
@@ -0,0 +1,198 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v4/internal/resolver"
+	"helm.sh/helm/v4/internal/urlutil"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/helmpath"
+	"helm.sh/helm/v4/pkg/provenance"
+	"helm.sh/helm/v4/pkg/registry"
+	"helm.sh/helm/v4/pkg/repo/v1"
+)
+
+// VerifyLock checks that the dependencies of the chart at ChartPath match its
+// lock file, so that the chart is installed or packaged as it was locked.
+//
+// The lock file must exist and be in sync with the dependencies in Chart.yaml,
+// and every locked dependency must be in charts/ at its locked version. When
+// ChartPath is a directory, the archives in charts/ must also match the digest
+// of the chart in the cached index of their repository. The charts of
+// repositories without a cached index, of OCI registries and of local
+// directories are only checked by version.
+func (m *Manager) VerifyLock() error {
+	fi, err := os.Stat(m.ChartPath)
+	if err != nil {
+		return fmt.Errorf("could not find %s: %w", m.ChartPath, err)
+	}
+	c, err := loader.Load(m.ChartPath)
+	if err != nil {
+		return err
+	}
+
+	lockfile, depsfile := "Chart.lock", "Chart.yaml"
+	if c.Metadata.APIVersion == chart.APIVersionV1 {
+		lockfile, depsfile = "requirements.lock", "requirements.yaml"
+	}
+	if c.Lock == nil {
+		if len(c.Metadata.Dependencies) == 0 {
+			return nil
+		}
+		return fmt.Errorf("the lock file (%s) is missing. Please update the dependencies with 'helm dependency update'", lockfile)
+	}
+	if inSync, err := m.lockInSync(c); err != nil {
+		return err
+	} else if !inSync {
+		return fmt.Errorf("the lock file (%s) is out of sync with the dependencies file (%s). Please update the dependencies with 'helm dependency update'", lockfile, depsfile)
+	}
+
+	var problems []string
+	for _, d := range c.Lock.Dependencies {
+		if problem := m.checkLockedDependency(c, d, fi.IsDir()); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("the charts/ directory does not match the lock file (%s):\n- %s", lockfile, strings.Join(problems, "\n- "))
+	}
+	return nil
+}
+
+// lockInSync reports whether the digest of the lock file of c matches its
+// dependencies.
+func (m *Manager) lockInSync(c *chart.Chart) (bool, error) {
+	rf, err := loadRepoConfig(m.RepositoryConfig)
+	if err != nil {
+		return false, err
+	}
+
+	// The digest is computed with the repository aliases replaced by their
+	// URL, as 'helm dependency update' does.
+	req := make([]*chart.Dependency, len(c.Metadata.Dependencies))
+	for i, d := range c.Metadata.Dependencies {
+		dep := *d
+		alias, ok := strings.CutPrefix(d.Repository, "@")
+		if !ok {
+			alias, ok = strings.CutPrefix(d.Repository, "alias:")
+		}
+		if ok {
+			found := false
+			for _, re := range rf.Repositories {
+				if re.Name == alias {
+					dep.Repository = re.URL
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false, fmt.Errorf("no repository definition for %s. Please add it via 'helm repo add'", d.Repository)
+			}
+		}
+		req[i] = &dep
+	}
+
+	if sum, err := resolver.HashReq(req, c.Lock.Dependencies); err == nil && sum == c.Lock.Digest {
+		return true, nil
+	}
+	// Locks of apiVersion v1 charts may have been generated by Helm 2.
+	if c.Metadata.APIVersion == chart.APIVersionV1 {
+		if sum, err := resolver.HashV2Req(c.Metadata.Dependencies); err == nil && sum == c.Lock.Digest {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkLockedDependency checks the chart in charts/ for a locked dependency,
+// returning the problem found, if any.
+func (m *Manager) checkLockedDependency(c *chart.Chart, d *chart.Dependency, isDir bool) string {
+	var found []*chart.Chart
+	for _, sub := range c.Dependencies() {
+		if sub.Name() == d.Name {
+			found = append(found, sub)
+		}
+	}
+	switch {
+	case len(found) == 0:
+		return fmt.Sprintf("%s %s is missing", d.Name, d.Version)
+	case len(found) > 1:
+		return fmt.Sprintf("%s is in charts/ %d times", d.Name, len(found))
+	}
+
+	// Charts kept in charts/ are locked with their version constraint.
+	if d.Repository == "" {
+		return ""
+	}
+	if v := found[0].Metadata.Version; !versionEquals(v, d.Version) {
+		return fmt.Sprintf("%s is at version %s instead of %s", d.Name, v, d.Version)
+	}
+
+	if !isDir || strings.HasPrefix(d.Repository, "file://") || registry.IsOCI(d.Repository) {
+		return ""
+	}
+	archive := filepath.Join(m.ChartPath, "charts", fmt.Sprintf("%s-%s.tgz", d.Name, d.Version))
+	if _, err := os.Stat(archive); err != nil {
+		return ""
+	}
+	expected := m.indexDigest(d)
+	if expected == "" {
+		return ""
+	}
+	digest, err := provenance.DigestFile(archive)
+	if err != nil {
+		return fmt.Sprintf("%s: %s", filepath.Base(archive), err)
+	}
+	if digest != stripDigestAlgorithm(expected) {
+		return fmt.Sprintf("%s does not match the digest of %s %s in %s", filepath.Base(archive), d.Name, d.Version, d.Repository)
+	}
+	return ""
+}
+
+// indexDigest returns the digest of the locked version of a dependency in the
+// cached index of its repository, or "" if there is none.
+func (m *Manager) indexDigest(d *chart.Dependency) string {
+	var names []string
+	if rf, err := loadRepoConfig(m.RepositoryConfig); err == nil {
+		for _, re := range rf.Repositories {
+			if urlutil.Equal(re.URL, d.Repository) {
+				names = append(names, re.Name)
+			}
+		}
+	}
+	// Repositories not known to Helm are cached under a generated name.
+	if k, err := key(d.Repository); err == nil {
+		names = append(names, managerKeyPrefix+k)
+	}
+
+	for _, name := range names {
+		index, err := repo.LoadIndexFile(filepath.Join(m.RepositoryCache, helmpath.CacheIndexFile(name)))
+		if err != nil {
+			continue
+		}
+		if cv, err := index.Get(d.Name, d.Version); err == nil {
+			return cv.Digest
+		}
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/internal/resolver"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestVerifyLock(t *testing.T) {
+	dir := t.TempDir()
+	req := []*chart.Dependency{
+		{Name: "alpine", Version: "^0.1.0", Repository: "@testing"},
+		{Name: "local", Version: "1.0.0"},
+	}
+	// The digest is computed with the repository alias replaced by its URL.
+	resolved := []*chart.Dependency{
+		{Name: "alpine", Version: "^0.1.0", Repository: "http://example.com"},
+		{Name: "local", Version: "1.0.0"},
+	}
+	locked := []*chart.Dependency{
+		{Name: "alpine", Version: "0.1.0", Repository: "http://example.com"},
+		{Name: "local", Version: "1.0.0"},
+	}
+	digest, err := resolver.HashReq(resolved, locked)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "locked", Version: "0.1.0", Dependencies: req},
+	}
+	c.SetDependencies(
+		&chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "alpine", Version: "0.1.0"}},
+		&chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "local", Version: "1.0.0"}},
+	)
+	if err := chartutil.SaveDir(c, dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeLock(filepath.Join(dir, "locked"), &chart.Lock{Digest: digest, Dependencies: locked}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{
+		ChartPath:        filepath.Join(dir, "locked"),
+		RepositoryConfig: repoConfig,
+		RepositoryCache:  t.TempDir(),
+	}
+	if err := m.VerifyLock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A locked dependency at another version.
+	if err := os.Remove(filepath.Join(m.ChartPath, "charts", "alpine-0.1.0.tgz")); err != nil {
+		t.Fatal(err)
+	}
+	alpine := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "alpine", Version: "0.1.1"}}
+	if _, err := chartutil.Save(alpine, filepath.Join(m.ChartPath, "charts")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.VerifyLock(); err == nil || !strings.Contains(err.Error(), "alpine is at version 0.1.1 instead of 0.1.0") {
+		t.Errorf("expected a version mismatch, got %v", err)
+	}
+
+	// Dependencies changed since the lock file was generated.
+	c.Metadata.Dependencies[1].Version = "2.0.0"
+	if err := chartutil.SaveChartfile(filepath.Join(m.ChartPath, "Chart.yaml"), c.Metadata); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.VerifyLock(); err == nil || !strings.Contains(err.Error(), "out of sync") {
+		t.Errorf("expected an out of sync lock file, got %v", err)
+	}
+
+	// The repository of the alias is unknown.
+	m.RepositoryConfig = filepath.Join(dir, "repositories.yaml")
+	if err := m.VerifyLock(); err == nil || !strings.Contains(err.Error(), "no repository definition for @testing") {
+		t.Errorf("expected an unknown repository, got %v", err)
+	}
+}
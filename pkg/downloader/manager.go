@@ -78,6 +78,9 @@ type Manager struct {
 
 	// ContentCache is a location where a cache of charts can be stored
 	ContentCache string
+	// Parallel is the number of charts downloaded at the same time. Charts
+	// are downloaded one at a time if it is less than 2.
+	Parallel int
 }
 
 // Build rebuilds a local charts directory from a lockfile.
@@ -272,6 +275,7 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 
 	fmt.Fprintf(m.Out, "Saving %d charts\n", len(deps))
 	var saveError error
+	var downloads []chartDownload
 	churls := make(map[string]struct{})
 	for _, dep := range deps {
 		// No repository means the chart is in charts directory
@@ -326,9 +330,7 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 			continue
 		}
 
-		fmt.Fprintf(m.Out, "Downloading %s from repo %s\n", dep.Name, dep.Repository)
-
-		dl := ChartDownloader{
+		dl := &ChartDownloader{
 			Out:              m.Out,
 			Verify:           m.Verify,
 			Keyring:          m.Keyring,
@@ -356,13 +358,12 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 				getter.WithTagName(version))
 		}
 
-		if _, _, err = dl.DownloadTo(churl, version, tmpPath); err != nil {
-			saveError = fmt.Errorf("could not download %s: %w", churl, err)
-			break
-		}
-
+		downloads = append(downloads, chartDownload{dep: dep, url: churl, version: version, downloader: dl})
 		churls[churl] = struct{}{}
 	}
+	if saveError == nil {
+		saveError = m.downloadCharts(downloads, tmpPath)
+	}
 
 	// TODO: this should probably be refactored to be a []error, so we can capture and provide more information rather than "last error wins".
 	if saveError == nil {
@@ -377,6 +378,70 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 	return nil
 }
 
+// chartDownload is a chart to download for a dependency.
+type chartDownload struct {
+	dep        *chart.Dependency
+	url        string
+	version    string
+	downloader *ChartDownloader
+}
+
+// downloadCharts downloads charts into dest, up to m.Parallel at the same
+// time. Once a download fails, the charts not yet downloaded are skipped.
+func (m *Manager) downloadCharts(downloads []chartDownload, dest string) error {
+	workers := min(max(m.Parallel, 1), len(downloads))
+	out := m.Out
+	if workers > 1 {
+		out = &syncWriter{w: m.Out}
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) > 0
+	}
+	jobs := make(chan chartDownload)
+	for range workers {
+		wg.Go(func() {
+			for d := range jobs {
+				if failed() {
+					continue
+				}
+				fmt.Fprintf(out, "Downloading %s from repo %s\n", d.dep.Name, d.dep.Repository)
+				d.downloader.Out = out
+				if _, _, err := d.downloader.DownloadTo(d.url, d.version, dest); err != nil {
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("could not download %s: %w", d.url, err))
+					mu.Unlock()
+				}
+			}
+		})
+	}
+	for _, d := range downloads {
+		jobs <- d
+	}
+	close(jobs)
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// syncWriter serializes the writes of concurrent downloads.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
 func parseOCIRef(chartRef string) (string, string, error) {
 	refTagRegexp := regexp.MustCompile(`^(oci://[^:]+(:[0-9]{1,5})?[^:]+):(.*)$`)
 	caps := refTagRegexp.FindStringSubmatch(chartRef)
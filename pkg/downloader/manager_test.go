@@ -437,6 +437,74 @@ func TestUpdateWithNoRepo(t *testing.T) {
 	}
 }
 
+// TestUpdateParallel downloads the dependencies at the same time, and checks
+// that they are kept in the content cache for later updates.
+func TestUpdateParallel(t *testing.T) {
+	srv := repotest.NewTempServer(
+		t,
+		repotest.WithChartSourceGlob("testdata/*.tgz*"),
+	)
+	defer srv.Stop()
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+	dir := func(p ...string) string {
+		return filepath.Join(append([]string{srv.Root()}, p...)...)
+	}
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "with-dependencies",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+			Dependencies: []*chart.Dependency{
+				{Name: "local-subchart", Version: "0.1.0", Repository: srv.URL()},
+				{Name: "signtest", Version: "0.1.0", Repository: srv.URL()},
+			},
+		},
+	}
+	if err := chartutil.SaveDir(c, dir()); err != nil {
+		t.Fatal(err)
+	}
+
+	g := getter.Providers{getter.Provider{
+		Schemes: []string{"http", "https"},
+		New:     getter.NewHTTPGetter,
+	}}
+	m := &Manager{
+		ChartPath:        dir(c.Metadata.Name),
+		Out:              bytes.NewBuffer(nil),
+		Getters:          g,
+		RepositoryConfig: dir("repositories.yaml"),
+		RepositoryCache:  dir(),
+		ContentCache:     t.TempDir(),
+		Parallel:         2,
+	}
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"local-subchart-0.1.0.tgz", "signtest-0.1.0.tgz"} {
+		if _, err := os.Stat(dir(c.Metadata.Name, "charts", name)); err != nil {
+			t.Errorf("Expected dependency %s: %s", name, err)
+		}
+	}
+
+	// The charts are now downloaded from the content cache.
+	srv.Stop()
+	if err := os.RemoveAll(dir(c.Metadata.Name, "charts")); err != nil {
+		t.Fatal(err)
+	}
+	m.SkipUpdate = true
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"local-subchart-0.1.0.tgz", "signtest-0.1.0.tgz"} {
+		if _, err := os.Stat(dir(c.Metadata.Name, "charts", name)); err != nil {
+			t.Errorf("Expected dependency %s: %s", name, err)
+		}
+	}
+}
+
 // This function is the skeleton test code of failing tests for #6416 and #6871 and bugs due to #5874.
 //
 // This function is used by below tests that ensures success of build operation
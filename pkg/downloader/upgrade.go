@@ -0,0 +1,321 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	yamlv3 "go.yaml.in/yaml/v3"
+
+	"helm.sh/helm/v4/internal/resolver"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// DependencyVersions describes the versions of a dependency of a chart.
+type DependencyVersions struct {
+	Name       string `json:"name"`
+	Repository string `json:"repository"`
+	// Constraint is the version or version range of the dependency in Chart.yaml.
+	Constraint string `json:"constraint"`
+	// Current is the version in the lock file, or the version in charts/ when
+	// the chart has no lock file.
+	Current string `json:"current,omitempty"`
+	// Wanted is the newest version satisfying Constraint.
+	Wanted string `json:"wanted,omitempty"`
+	// Latest is the newest version available. Pre-releases are only
+	// considered when no stable version is available.
+	Latest string `json:"latest,omitempty"`
+
+	index int
+}
+
+// Outdated reports whether a newer version than the current one is available.
+func (d *DependencyVersions) Outdated() bool {
+	if d.Latest == "" {
+		return false
+	}
+	return versionLess(d.Current, d.Latest) || versionLess(d.Current, d.Wanted)
+}
+
+// Target returns the version the dependency is upgraded to. Unless latest is
+// set, the dependency is only upgraded to versions satisfying its constraint.
+func (d *DependencyVersions) Target(latest bool) string {
+	if latest && d.Latest != "" {
+		return d.Latest
+	}
+	if d.Wanted != "" {
+		return d.Wanted
+	}
+	return d.Current
+}
+
+// Upgradable reports whether the dependency is upgraded by Upgrade.
+func (d *DependencyVersions) Upgradable(latest bool) bool {
+	return versionLess(d.Current, d.Target(latest)) || d.UpgradeConstraint(latest) != d.Constraint
+}
+
+// UpgradeConstraint returns the constraint in Chart.yaml after upgrading the
+// dependency to Target(latest). The constraint is kept when it is satisfied
+// by the target. Otherwise, exact versions are replaced by the target, and
+// simple ranges like "^1.2.0" or "~1.2.0" are moved to the target.
+func (d *DependencyVersions) UpgradeConstraint(latest bool) string {
+	target := d.Target(latest)
+	if target == "" {
+		return d.Constraint
+	}
+	v, err := semver.NewVersion(target)
+	if err != nil {
+		return d.Constraint
+	}
+	if c, err := semver.NewConstraint(d.Constraint); err == nil && c.Check(v) {
+		return d.Constraint
+	}
+
+	constraint := strings.TrimSpace(d.Constraint)
+	for _, op := range []string{"^", "~>", "~", ">=", "="} {
+		rest, ok := strings.CutPrefix(constraint, op)
+		if !ok {
+			continue
+		}
+		if _, err := semver.NewVersion(strings.TrimSpace(rest)); err == nil {
+			return op + target
+		}
+		break
+	}
+	return target
+}
+
+// versionLess reports whether version a is older than version b. A missing
+// version is older than any other version.
+func versionLess(a, b string) bool {
+	if b == "" {
+		return false
+	}
+	vb, err := semver.NewVersion(b)
+	if err != nil {
+		return false
+	}
+	if a == "" {
+		return true
+	}
+	va, err := semver.NewVersion(a)
+	if err != nil {
+		return false
+	}
+	return va.LessThan(vb)
+}
+
+// Outdated looks up the versions available for the dependencies of a chart.
+//
+// It updates the repositories unless SkipUpdate is true, and reports for
+// every dependency the current version along with the newest versions
+// satisfying its constraint and overall. It does not change the chart.
+func (m *Manager) Outdated() ([]*DependencyVersions, error) {
+	c, err := m.loadChartDir()
+	if err != nil {
+		return nil, err
+	}
+
+	req := c.Metadata.Dependencies
+	if req == nil {
+		return nil, nil
+	}
+
+	// resolveRepoNames replaces repository aliases with their URL, so keep
+	// the repositories as they are written in Chart.yaml.
+	deps := make([]*DependencyVersions, len(req))
+	for i, d := range req {
+		deps[i] = &DependencyVersions{
+			Name:       d.Name,
+			Repository: d.Repository,
+			Constraint: d.Version,
+			Current:    currentVersion(c, i, d),
+			index:      i,
+		}
+	}
+
+	repoNames, err := m.resolveRepoNames(req)
+	if err != nil {
+		return nil, err
+	}
+	repoNames, err = m.ensureMissingRepos(repoNames, req)
+	if err != nil {
+		return nil, err
+	}
+	if !m.SkipUpdate {
+		if err := m.UpdateRepositories(); err != nil {
+			return nil, err
+		}
+	}
+
+	res := resolver.New(m.ChartPath, m.RepositoryCache, m.RegistryClient)
+	for i, d := range req {
+		constraint, err := semver.NewConstraint(d.Version)
+		if err != nil {
+			return nil, fmt.Errorf("dependency %q has an invalid version/constraint format: %w", d.Name, err)
+		}
+		versions, err := res.Versions(d, repoNames[d.Name])
+		if err != nil {
+			return nil, err
+		}
+
+		dep := deps[i]
+		for _, v := range versions {
+			if dep.Wanted == "" && constraint.Check(v) {
+				dep.Wanted = v.Original()
+			}
+			if dep.Latest == "" && v.Prerelease() == "" {
+				dep.Latest = v.Original()
+			}
+		}
+		if dep.Latest == "" && len(versions) > 0 {
+			dep.Latest = versions[0].Original()
+		}
+	}
+	return deps, nil
+}
+
+// currentVersion returns the version of the i-th dependency of c in the lock
+// file or, when the chart has no lock file, in charts/.
+func currentVersion(c *chart.Chart, i int, d *chart.Dependency) string {
+	if c.Lock != nil {
+		locked := c.Lock.Dependencies
+		if i < len(locked) && locked[i].Name == d.Name {
+			return locked[i].Version
+		}
+		for _, l := range locked {
+			if l.Name == d.Name {
+				return l.Version
+			}
+		}
+		return ""
+	}
+	for _, sub := range c.Dependencies() {
+		if sub.Name() == d.Name {
+			return sub.Metadata.Version
+		}
+	}
+	return ""
+}
+
+// Upgrade upgrades dependencies to the versions reported by Outdated.
+//
+// Each dependency is upgraded to Target(latest). Constraints in Chart.yaml
+// that the new version does not satisfy are rewritten, leaving the rest of
+// the file untouched, and then Chart.lock and charts/ are updated like
+// Update does, without updating the repositories again.
+func (m *Manager) Upgrade(deps []*DependencyVersions, latest bool) error {
+	c, err := m.loadChartDir()
+	if err != nil {
+		return err
+	}
+
+	constraints := make(map[int]string)
+	for _, d := range deps {
+		if constraint := d.UpgradeConstraint(latest); constraint != d.Constraint {
+			constraints[d.index] = constraint
+		}
+	}
+	if len(constraints) > 0 {
+		name := "Chart.yaml"
+		if c.Metadata.APIVersion == chart.APIVersionV1 {
+			if _, err := os.Stat(filepath.Join(m.ChartPath, "requirements.yaml")); err == nil {
+				name = "requirements.yaml"
+			}
+		}
+		if err := writeDependencyVersions(filepath.Join(m.ChartPath, name), constraints); err != nil {
+			return err
+		}
+	}
+
+	skipUpdate := m.SkipUpdate
+	m.SkipUpdate = true
+	defer func() { m.SkipUpdate = skipUpdate }()
+	return m.Update()
+}
+
+// writeDependencyVersions sets the versions of the dependencies at the given
+// indexes in a Chart.yaml or requirements.yaml file.
+//
+// Only the versions are replaced, so comments and formatting are preserved.
+func writeDependencyVersions(path string, versions map[int]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return fmt.Errorf("%s has no dependencies", path)
+	}
+	deps := mappingValue(doc.Content[0], "dependencies")
+	if deps == nil || deps.Kind != yamlv3.SequenceNode {
+		return fmt.Errorf("%s has no dependencies", path)
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	for i, version := range versions {
+		if i >= len(deps.Content) {
+			return fmt.Errorf("%s has no dependency %d", path, i)
+		}
+		node := mappingValue(deps.Content[i], "version")
+		if node == nil || node.Kind != yamlv3.ScalarNode {
+			return fmt.Errorf("dependency %d in %s has no version", i, path)
+		}
+
+		old, replacement := node.Value, version
+		switch node.Style {
+		case yamlv3.DoubleQuotedStyle:
+			old, replacement = `"`+old+`"`, `"`+replacement+`"`
+		case yamlv3.SingleQuotedStyle:
+			old, replacement = "'"+old+"'", "'"+replacement+"'"
+		}
+		line := []rune(string(lines[node.Line-1]))
+		start, end := node.Column-1, node.Column-1+len([]rune(old))
+		if end > len(line) || string(line[start:end]) != old {
+			return fmt.Errorf("unable to rewrite the version of dependency %d in %s", i, path)
+		}
+		lines[node.Line-1] = []byte(string(line[:start]) + replacement + string(line[end:]))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, bytes.Join(lines, []byte("\n")), info.Mode())
+}
+
+// mappingValue returns the value of key in a YAML mapping node.
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
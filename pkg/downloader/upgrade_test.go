@@ -0,0 +1,145 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpgradeConstraint(t *testing.T) {
+	tests := []struct {
+		name       string
+		dep        DependencyVersions
+		latest     bool
+		constraint string
+		upgradable bool
+	}{
+		{
+			name:       "up to date",
+			dep:        DependencyVersions{Constraint: "1.0.0", Current: "1.0.0", Wanted: "1.0.0", Latest: "1.0.0"},
+			latest:     true,
+			constraint: "1.0.0",
+		},
+		{
+			name:       "within constraint",
+			dep:        DependencyVersions{Constraint: "^1.0.0", Current: "1.0.0", Wanted: "1.2.0", Latest: "2.0.0"},
+			constraint: "^1.0.0",
+			upgradable: true,
+		},
+		{
+			name:       "exact version",
+			dep:        DependencyVersions{Constraint: "1.0.0", Current: "1.0.0", Wanted: "1.0.0", Latest: "2.0.0"},
+			latest:     true,
+			constraint: "2.0.0",
+			upgradable: true,
+		},
+		{
+			name:       "caret range",
+			dep:        DependencyVersions{Constraint: "^1.0.0", Current: "1.2.0", Wanted: "1.2.0", Latest: "2.0.0"},
+			latest:     true,
+			constraint: "^2.0.0",
+			upgradable: true,
+		},
+		{
+			name:       "tilde range",
+			dep:        DependencyVersions{Constraint: "~1.0.0", Current: "1.0.0", Wanted: "1.0.1", Latest: "1.1.0"},
+			latest:     true,
+			constraint: "~1.1.0",
+			upgradable: true,
+		},
+		{
+			name:       "range satisfied by latest",
+			dep:        DependencyVersions{Constraint: ">=1.0.0", Current: "1.0.0", Wanted: "2.0.0", Latest: "2.0.0"},
+			latest:     true,
+			constraint: ">=1.0.0",
+			upgradable: true,
+		},
+		{
+			name:       "compound range",
+			dep:        DependencyVersions{Constraint: ">=1.0.0 <2.0.0", Current: "1.0.0", Wanted: "1.0.0", Latest: "2.0.0"},
+			latest:     true,
+			constraint: "2.0.0",
+			upgradable: true,
+		},
+		{
+			name:       "no versions",
+			dep:        DependencyVersions{Constraint: "1.0.0", Current: "1.0.0"},
+			latest:     true,
+			constraint: "1.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c := tt.dep.UpgradeConstraint(tt.latest); c != tt.constraint {
+				t.Errorf("expected constraint %q, got %q", tt.constraint, c)
+			}
+			if u := tt.dep.Upgradable(tt.latest); u != tt.upgradable {
+				t.Errorf("expected upgradable %t, got %t", tt.upgradable, u)
+			}
+		})
+	}
+}
+
+func TestWriteDependencyVersions(t *testing.T) {
+	chartfile := `# The chart
+apiVersion: v2
+name: upgrade
+version: 1.2.3
+dependencies:
+  - name: plain
+    version: 1.0.0 # pinned
+    repository: https://example.com/charts
+  - name: quoted
+    repository: https://example.com/charts
+    version: "^1.0.0"
+  - {name: flow, version: '~1.0.0', repository: "@example"}
+`
+	path := filepath.Join(t.TempDir(), "Chart.yaml")
+	if err := os.WriteFile(path, []byte(chartfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeDependencyVersions(path, map[int]string{0: "1.10.0", 1: "^2.0.0", 2: "~1.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# The chart
+apiVersion: v2
+name: upgrade
+version: 1.2.3
+dependencies:
+  - name: plain
+    version: 1.10.0 # pinned
+    repository: https://example.com/charts
+  - name: quoted
+    repository: https://example.com/charts
+    version: "^2.0.0"
+  - {name: flow, version: '~1.1.0', repository: "@example"}
+`
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+	}
+
+	if err := writeDependencyVersions(path, map[int]string{3: "1.0.0"}); err == nil {
+		t.Error("expected an error for a missing dependency")
+	}
+}
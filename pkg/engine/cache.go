@@ -0,0 +1,176 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// renderCacheVersion is part of every cache key, so that entries written by
+// an engine rendering differently are not reused.
+const renderCacheVersion = "v1"
+
+// RenderCache stores rendered templates in a directory, so that rendering a
+// chart again with the same values is skipped.
+//
+// Entries are keyed by a digest of the chart, a hash of the values and a hash
+// of the capabilities. Changing any of them changes the key, so entries never
+// need to be invalidated. Templates using random or time functions render the
+// same output for as long as the entry is cached.
+type RenderCache struct {
+	// Dir is the directory the rendered templates are stored in.
+	Dir string
+}
+
+// NewRenderCache creates a render cache storing rendered templates in dir.
+func NewRenderCache(dir string) *RenderCache {
+	return &RenderCache{Dir: dir}
+}
+
+// get returns the rendered templates stored for key. A missing or unreadable
+// entry is a cache miss.
+func (c *RenderCache) get(key string) (map[string]string, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var rendered map[string]string
+	if err := json.Unmarshal(data, &rendered); err != nil {
+		return nil, false
+	}
+	return rendered, true
+}
+
+// put stores the rendered templates for key.
+func (c *RenderCache) put(key string, rendered map[string]string) error {
+	data, err := json.Marshal(rendered)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that concurrent renders never read a
+	// partial entry
+	f, err := os.CreateTemp(c.Dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path(key))
+}
+
+func (c *RenderCache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
+
+// renderCacheKey returns the key of the rendered templates of a chart. The
+// options of the engine are part of the key, as they change the output.
+func (e Engine) renderCacheKey(chrt ci.Charter, values common.Values) (string, error) {
+	chartDigest := sha256.New()
+	if err := digestChart(chartDigest, chrt); err != nil {
+		return "", err
+	}
+
+	vals := maps.Clone(values)
+	capabilities := vals["Capabilities"]
+	delete(vals, "Capabilities")
+
+	valuesHash, err := hashJSON(vals)
+	if err != nil {
+		return "", err
+	}
+	capabilitiesHash, err := hashJSON(capabilities)
+	if err != nil {
+		return "", err
+	}
+
+	key := sha256.New()
+	fmt.Fprintf(key, "%s\n%x\n%s\n%s\nstrict=%t lint=%t\n",
+		renderCacheVersion, chartDigest.Sum(nil), valuesHash, capabilitiesHash, e.Strict, e.LintMode)
+	return hex.EncodeToString(key.Sum(nil)), nil
+}
+
+// digestChart writes the content of a chart and its dependencies to h.
+func digestChart(h hash.Hash, chrt ci.Charter) error {
+	accessor, err := ci.NewAccessor(chrt)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(accessor.MetadataAsMap())
+	if err != nil {
+		return err
+	}
+	values, err := json.Marshal(accessor.Values())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(h, "chart %s\n%s\n%s\n", accessor.Name(), metadata, values)
+	writeBytes(h, accessor.Schema())
+	for _, f := range accessor.Templates() {
+		fmt.Fprintf(h, "template %s\n", f.Name)
+		writeBytes(h, f.Data)
+	}
+	for _, f := range accessor.Files() {
+		fmt.Fprintf(h, "file %s\n", f.Name)
+		writeBytes(h, f.Data)
+	}
+
+	for _, dep := range accessor.Dependencies() {
+		if err := digestChart(h, dep); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(h, "end")
+	return nil
+}
+
+// writeBytes writes data to w, prefixed by its length so that the content of
+// consecutive files cannot be confused.
+func writeBytes(w io.Writer, data []byte) {
+	fmt.Fprintf(w, "%d\n", len(data))
+	w.Write(data)
+}
+
+func hashJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func cacheEntries(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	return entries
+}
+
+func TestRenderCache(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*common.File{
+			{Name: "templates/test1", ModTime: time.Now(), Data: []byte("{{ .Values.whale }} {{ .Capabilities.KubeVersion.Version }}")},
+		},
+	}
+	values := func(whale, kubeVersion string) common.Values {
+		return common.Values{
+			"Values":       map[string]any{"whale": whale},
+			"Capabilities": map[string]any{"KubeVersion": map[string]any{"Version": kubeVersion}},
+		}
+	}
+
+	dir := t.TempDir()
+	e := Engine{Cache: NewRenderCache(dir)}
+
+	out, err := e.Render(c, values("moby", "v1.30.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "moby v1.30.0", out["moby/templates/test1"])
+	entries := cacheEntries(t, dir)
+	require.Len(t, entries, 1)
+
+	// A cached entry is used instead of rendering again
+	require.NoError(t, os.WriteFile(entries[0], []byte(`{"moby/templates/test1":"cached"}`), 0644))
+	out, err = e.Render(c, values("moby", "v1.30.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "cached", out["moby/templates/test1"])
+
+	// Changing the values, the capabilities or the chart changes the key
+	out, err = e.Render(c, values("dick", "v1.30.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "dick v1.30.0", out["moby/templates/test1"])
+	out, err = e.Render(c, values("moby", "v1.31.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "moby v1.31.0", out["moby/templates/test1"])
+	c.Templates[0].Data = []byte("{{ .Values.whale | upper }}")
+	out, err = e.Render(c, values("moby", "v1.30.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "MOBY", out["moby/templates/test1"])
+	assert.Len(t, cacheEntries(t, dir), 4)
+
+	// Templates doing DNS lookups are not cached
+	e.EnableDNS = true
+	_, err = e.Render(c, values("ahab", "v1.30.0"))
+	require.NoError(t, err)
+	assert.Len(t, cacheEntries(t, dir), 4)
+}
+
+func TestRenderCacheErrorsNotCached(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*common.File{
+			{Name: "templates/test1", ModTime: time.Now(), Data: []byte(`{{ required "whale is required" .Values.whale }}`)},
+		},
+	}
+
+	dir := t.TempDir()
+	e := Engine{Cache: NewRenderCache(dir)}
+	_, err := e.Render(c, common.Values{"Values": map[string]any{}})
+	require.Error(t, err)
+	assert.Empty(t, cacheEntries(t, dir))
+}
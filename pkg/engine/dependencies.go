@@ -0,0 +1,255 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+)
+
+// treeRefs holds the values references of a template or a named template.
+type treeRefs struct {
+	// values are the paths referenced below .Values, such as ["image", "tag"]
+	// for `.Values.image.tag`. An empty path references all values.
+	values [][]string
+	// includes are the named templates included or called
+	includes []string
+}
+
+// chartTemplate is a template of a chart or one of its dependencies.
+type chartTemplate struct {
+	// prefix is the path of the values of the chart in the values of the root
+	// chart.
+	prefix []string
+	data   string
+}
+
+// ValuesReferences returns the values paths referenced by each template of a
+// chart and its dependencies, keyed by the name of the template, such as
+// "mychart/charts/db/templates/service.yaml".
+//
+// Paths are relative to the values of the root chart: a subchart "db"
+// referencing `.Values.port` references ["db", "port"]. The references of the
+// named templates a template includes are part of its references. An empty
+// path means that the template may use any value, such as when it calls `tpl`
+// or includes a template whose name is computed. Partials are not rendered, so
+// they have no entry.
+func ValuesReferences(chrt ci.Charter) (map[string][][]string, error) {
+	tpls := map[string]chartTemplate{}
+	collectChartTemplates(chrt, nil, tpls)
+
+	// Parse templates in the same order as the engine, so that a named template
+	// defined twice resolves to the same definition
+	names := slices.Collect(maps.Keys(tpls))
+	sort.Sort(sort.Reverse(byPathLen(names)))
+
+	files := map[string]treeRefs{}
+	defines := map[string]treeRefs{}
+	for _, name := range names {
+		trees := map[string]*parse.Tree{}
+		tree := parse.New(name)
+		tree.Mode = parse.SkipFuncCheck
+		if _, err := tree.Parse(tpls[name].data, "", "", trees); err != nil {
+			return nil, cleanupParseError(name, err)
+		}
+		for defName, t := range trees {
+			if defName != name {
+				defines[defName] = templateRefs(t.Root)
+			}
+		}
+		files[name] = templateRefs(tree.Root)
+	}
+
+	refs := make(map[string][][]string, len(files))
+	for name, r := range files {
+		if strings.HasPrefix(path.Base(name), "_") {
+			continue
+		}
+		prefix := tpls[name].prefix
+		seen := map[string]bool{}
+		var add func(r treeRefs)
+		add = func(r treeRefs) {
+			for _, v := range r.values {
+				refs[name] = append(refs[name], append(slices.Clone(prefix), v...))
+				// Globals are copied to the values of every subchart
+				if len(prefix) > 0 && len(v) > 0 && v[0] == "global" {
+					refs[name] = append(refs[name], slices.Clone(v))
+				}
+			}
+			for _, inc := range r.includes {
+				if d, ok := defines[inc]; ok && !seen[inc] {
+					seen[inc] = true
+					add(d)
+				}
+			}
+		}
+		add(r)
+		if _, ok := refs[name]; !ok {
+			refs[name] = [][]string{}
+		}
+	}
+	return refs, nil
+}
+
+// AffectedTemplates returns the sorted names of the templates of a chart and
+// its dependencies whose output may change when the given values change. The
+// changed values are relative to the values of the root chart, like values
+// files passed to an install.
+func AffectedTemplates(chrt ci.Charter, changed map[string]any) ([]string, error) {
+	refs, err := ValuesReferences(chrt)
+	if err != nil {
+		return nil, err
+	}
+	changedPaths := leafPaths(changed, nil)
+
+	affected := []string{}
+	for name, paths := range refs {
+		if slices.ContainsFunc(paths, func(ref []string) bool {
+			return slices.ContainsFunc(changedPaths, func(c []string) bool {
+				return isPrefix(ref, c) || isPrefix(c, ref)
+			})
+		}) {
+			affected = append(affected, name)
+		}
+	}
+	sort.Strings(affected)
+	return affected, nil
+}
+
+// collectChartTemplates adds the templates of a chart, whose values are found
+// at prefix, and of its dependencies.
+func collectChartTemplates(chrt ci.Charter, prefix []string, tpls map[string]chartTemplate) {
+	accessor, err := ci.NewAccessor(chrt)
+	if err != nil {
+		return
+	}
+	for _, child := range accessor.Dependencies() {
+		sub, err := ci.NewAccessor(child)
+		if err != nil {
+			continue
+		}
+		collectChartTemplates(child, append(slices.Clone(prefix), sub.Name()), tpls)
+	}
+	for _, t := range accessor.Templates() {
+		if t == nil || !isTemplateValid(accessor, t.Name) {
+			continue
+		}
+		tpls[path.Join(accessor.ChartFullPath(), t.Name)] = chartTemplate{prefix: prefix, data: string(t.Data)}
+	}
+}
+
+// templateRefs returns the values references and includes of a parsed
+// template.
+func templateRefs(root *parse.ListNode) treeRefs {
+	var r treeRefs
+	// Fields already recorded as part of an `index` call
+	handled := map[parse.Node]bool{}
+	walkNodes(root, func(node parse.Node) bool {
+		switch n := node.(type) {
+		case *parse.TemplateNode:
+			r.includes = append(r.includes, n.Name)
+		case *parse.CommandNode:
+			ident, ok := n.Args[0].(*parse.IdentifierNode)
+			if !ok {
+				break
+			}
+			switch ident.Ident {
+			case "include", "includeWith":
+				if len(n.Args) > 1 {
+					if s, ok := n.Args[1].(*parse.StringNode); ok {
+						r.includes = append(r.includes, s.Text)
+						break
+					}
+				}
+				r.values = append(r.values, []string{})
+			case "tpl":
+				r.values = append(r.values, []string{})
+			case "index":
+				if len(n.Args) < 2 {
+					break
+				}
+				ref, ok := valuesRef(n.Args[1])
+				if !ok {
+					break
+				}
+				for _, arg := range n.Args[2:] {
+					s, ok := arg.(*parse.StringNode)
+					if !ok {
+						break
+					}
+					ref = append(ref, s.Text)
+				}
+				r.values = append(r.values, ref)
+				handled[n.Args[1]] = true
+			}
+		case *parse.FieldNode, *parse.VariableNode:
+			if handled[node] {
+				break
+			}
+			if ref, ok := valuesRef(n); ok {
+				r.values = append(r.values, ref)
+			}
+		}
+		return true
+	})
+	return r
+}
+
+// valuesRef returns the values path of a `.Values` or `$.Values` field.
+func valuesRef(node parse.Node) ([]string, bool) {
+	var ident []string
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		ident = n.Ident
+	case *parse.VariableNode:
+		if len(n.Ident) == 0 || n.Ident[0] != "$" {
+			return nil, false
+		}
+		ident = n.Ident[1:]
+	default:
+		return nil, false
+	}
+	if len(ident) == 0 || ident[0] != "Values" {
+		return nil, false
+	}
+	return slices.Clone(ident[1:]), true
+}
+
+// leafPaths returns the paths of the values that are not maps.
+func leafPaths(vals map[string]any, prefix []string) [][]string {
+	var paths [][]string
+	for k, v := range vals {
+		p := append(slices.Clone(prefix), k)
+		if sub, ok := v.(map[string]any); ok && len(sub) > 0 {
+			paths = append(paths, leafPaths(sub, p)...)
+			continue
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// isPrefix returns whether prefix is a prefix of p.
+func isPrefix(prefix, p []string) bool {
+	return len(prefix) <= len(p) && slices.Equal(prefix, p[:len(prefix)])
+}
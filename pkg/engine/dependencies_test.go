@@ -0,0 +1,110 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func dependenciesChart() *chart.Chart {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "parent"},
+		Templates: []*common.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "parent.image" }}{{ .Values.image.repository }}:{{ index .Values "image" "tag" }}{{ end }}`)},
+			{Name: "templates/deployment.yaml", Data: []byte(`image: {{ include "parent.image" . }}
+replicas: {{ .Values.replicaCount }}`)},
+			{Name: "templates/service.yaml", Data: []byte(`port: {{ .Values.service.port }}`)},
+			{Name: "templates/config.yaml", Data: []byte(`{{ tpl .Values.config . }}`)},
+		},
+	}
+	c.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "db"},
+		Templates: []*common.File{
+			{Name: "templates/statefulset.yaml", Data: []byte(`storage: {{ .Values.storage }}
+debug: {{ $.Values.global.debug }}`)},
+		},
+	})
+	return c
+}
+
+func TestValuesReferences(t *testing.T) {
+	refs, err := ValuesReferences(dependenciesChart())
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][][]string{
+		"parent/templates/deployment.yaml":            {{"replicaCount"}, {"image", "repository"}, {"image", "tag"}},
+		"parent/templates/service.yaml":               {{"service", "port"}},
+		"parent/templates/config.yaml":                {nil, {"config"}},
+		"parent/charts/db/templates/statefulset.yaml": {{"db", "storage"}, {"db", "global", "debug"}, {"global", "debug"}},
+	}, refs)
+}
+
+func TestAffectedTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		changed map[string]any
+		want    []string
+	}{
+		{
+			name:    "value of a named template",
+			changed: map[string]any{"image": map[string]any{"tag": "2.0"}},
+			want:    []string{"parent/templates/config.yaml", "parent/templates/deployment.yaml"},
+		},
+		{
+			name:    "parent of referenced values",
+			changed: map[string]any{"service": map[string]any{"port": 80, "type": "NodePort"}},
+			want:    []string{"parent/templates/config.yaml", "parent/templates/service.yaml"},
+		},
+		{
+			name:    "subchart value",
+			changed: map[string]any{"db": map[string]any{"storage": "10Gi"}},
+			want:    []string{"parent/charts/db/templates/statefulset.yaml", "parent/templates/config.yaml"},
+		},
+		{
+			name:    "global value",
+			changed: map[string]any{"global": map[string]any{"debug": true}},
+			want:    []string{"parent/charts/db/templates/statefulset.yaml", "parent/templates/config.yaml"},
+		},
+		{
+			name:    "no values",
+			changed: map[string]any{},
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AffectedTemplates(dependenciesChart(), tt.changed)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRenderTemplatesSubset(t *testing.T) {
+	e := Engine{Templates: []string{"parent/templates/service.yaml"}}
+	vals := common.Values{"Values": map[string]any{"service": map[string]any{"port": 80}}}
+
+	out, err := e.Render(dependenciesChart(), vals)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"parent/templates/service.yaml": "port: 80"}, out)
+}
@@ -25,6 +25,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"text/template"
@@ -48,6 +49,28 @@ type Engine struct {
 	EnableDNS bool
 	// CustomTemplateFuncs is defined by users to provide custom template funcs
 	CustomTemplateFuncs template.FuncMap
+	// Cache optionally stores rendered templates. It is not used when the
+	// output can depend on more than the chart and values: when templates can
+	// talk to the Kubernetes API, do DNS lookups or call custom functions.
+	Cache *RenderCache
+	// Templates, if not nil, limits rendering to the named templates, such as
+	// "mychart/templates/service.yaml". All templates are still parsed, so
+	// that the rendered ones can include named templates defined anywhere.
+	Templates []string
+	// Profile optionally records the time spent rendering templates and
+	// calling expensive template functions.
+	Profile *RenderProfile
+	// LookupFixtures optionally backs the `lookup` function with local objects
+	// when the engine does not talk to a cluster.
+	LookupFixtures *LookupFixtures
+	// Warnings optionally collects the warnings emitted by templates with the
+	// `warn` function. Without it, warnings are logged.
+	Warnings *RenderWarnings
+	// funcs, if not nil, are the names of the functions of the function sets
+	// declared by the rendered chart
+	funcs map[string]bool
+	// warned, if not nil, counts the warnings emitted while rendering
+	warned *int
 }
 
 // New creates a new instance of Engine using the passed in rest config.
@@ -58,6 +81,19 @@ func New(config *rest.Config) Engine {
 	}
 }
 
+// WithFuncs returns a copy of the engine with additional template functions,
+// which take precedence over the built in functions of the same name. They are
+// available to charts whatever function set they declare.
+func (e Engine) WithFuncs(funcs map[string]any) Engine {
+	custom := maps.Clone(e.CustomTemplateFuncs)
+	if custom == nil {
+		custom = make(template.FuncMap, len(funcs))
+	}
+	maps.Copy(custom, funcs)
+	e.CustomTemplateFuncs = custom
+	return e
+}
+
 // Render takes a chart, optional values, and value overrides, and attempts to render the Go templates.
 //
 // Render can be called repeatedly on the same engine.
@@ -103,8 +139,43 @@ func (e Engine) Render(chrt ci.Charter, values common.Values) (map[string]string
 // section contains a value named "bar", that value will be passed on to the
 // bar chart during render time.
 func (e Engine) RenderWithContext(ctx context.Context, chrt ci.Charter, values common.Values) (map[string]string, error) {
-	tmap := allTemplates(chrt, values)
-	return e.render(ctx, tmap)
+	funcs, err := chartFunctions(chrt)
+	if err != nil {
+		return nil, err
+	}
+	e.funcs = funcs
+
+	if !e.cacheable() {
+		return e.render(ctx, allTemplates(chrt, values))
+	}
+
+	key, err := e.renderCacheKey(chrt, values)
+	if err != nil {
+		slog.Debug("unable to compute render cache key", slog.Any("error", err))
+		return e.render(ctx, allTemplates(chrt, values))
+	}
+	if rendered, ok := e.Cache.get(key); ok {
+		slog.Debug("using cached rendered templates", slog.String("key", key))
+		return rendered, nil
+	}
+
+	// Renders emitting warnings are not cached, so that the warnings are
+	// emitted again when rendering the chart again
+	e.warned = new(int)
+	rendered, err := e.render(ctx, allTemplates(chrt, values))
+	if err != nil || *e.warned > 0 {
+		return rendered, err
+	}
+	if err := e.Cache.put(key, rendered); err != nil {
+		slog.Warn("unable to cache rendered templates", slog.Any("error", err))
+	}
+	return rendered, nil
+}
+
+// cacheable returns whether the rendered templates can be cached, which
+// requires the output to depend only on the chart and values.
+func (e Engine) cacheable() bool {
+	return e.Cache != nil && e.clientProvider == nil && !e.EnableDNS && len(e.CustomTemplateFuncs) == 0 && e.Templates == nil && e.Profile == nil && e.LookupFixtures == nil
 }
 
 // Render takes a chart, optional values, and value overrides, and attempts to
@@ -227,6 +298,7 @@ func (e Engine) initFunMap(ctx context.Context, t *template.Template) {
 
 	// Add the template-rendering functions here so we can close over t.
 	funcMap["include"] = includeFun(t, includedNames)
+	funcMap["includeWith"] = includeWithFun(includeFun(t, includedNames))
 	funcMap["tpl"] = tplFun(t, includedNames, e.Strict)
 
 	// Add the `required` function here so we can use lintMode
@@ -260,11 +332,15 @@ func (e Engine) initFunMap(ctx context.Context, t *template.Template) {
 		}
 		return "", errors.New(warnWrap(msg))
 	}
+	funcMap["failf"] = failfFun(e.LintMode)
+	funcMap["warn"] = warnFun(e.Warnings, e.warned)
 
 	// If we are not linting and have a cluster connection, provide a Kubernetes-backed
 	// implementation.
 	if !e.LintMode && e.clientProvider != nil {
 		funcMap["lookup"] = newLookupFunction(ctx, *e.clientProvider)
+	} else if e.LookupFixtures != nil {
+		funcMap["lookup"] = e.LookupFixtures.lookup
 	}
 
 	// When DNS lookups are not enabled override the sprig function and return
@@ -275,9 +351,16 @@ func (e Engine) initFunMap(ctx context.Context, t *template.Template) {
 		}
 	}
 
+	// Leave out the functions of newer function sets than the chart declares
+	restrictFuncs(funcMap, e.funcs)
+
 	// Set custom template funcs
 	maps.Copy(funcMap, e.CustomTemplateFuncs)
 
+	if e.Profile != nil {
+		e.Profile.wrap(funcMap)
+	}
+
 	t.Funcs(funcMap)
 }
 
@@ -295,26 +378,9 @@ func (e Engine) render(ctx context.Context, tpls map[string]renderable) (rendere
 			err = fmt.Errorf("rendering template failed: %v", r)
 		}
 	}()
-	t := template.New("gotpl")
-	if e.Strict {
-		t.Option("missingkey=error")
-	} else {
-		// Not that zero will attempt to add default values for types it knows,
-		// but will still emit <no value> for others. We mitigate that later.
-		t.Option("missingkey=zero")
-	}
-
-	e.initFunMap(ctx, t)
-
-	// We want to parse the templates in a predictable order. The order favors
-	// higher-level (in file system) templates over deeply nested templates.
-	keys := sortTemplates(tpls)
-
-	for _, filename := range keys {
-		r := tpls[filename]
-		if _, err := t.New(filename).Parse(r.tpl); err != nil {
-			return map[string]string{}, cleanupParseError(filename, err)
-		}
+	t, keys, err := e.parse(ctx, tpls)
+	if err != nil {
+		return map[string]string{}, err
 	}
 
 	rendered = make(map[string]string, len(keys))
@@ -324,12 +390,23 @@ func (e Engine) render(ctx context.Context, tpls map[string]renderable) (rendere
 		if strings.HasPrefix(path.Base(filename), "_") {
 			continue
 		}
+		if e.Templates != nil && !slices.Contains(e.Templates, filename) {
+			continue
+		}
 		// At render time, add information about the template that is being rendered.
 		vals := tpls[filename].vals
 		vals["Template"] = common.Values{"Name": filename, "BasePath": tpls[filename].basePath}
 		var buf strings.Builder
-		if err := t.ExecuteTemplate(&buf, filename, vals); err != nil {
-			return map[string]string{}, reformatExecErrorMsg(filename, err)
+		var stop func()
+		if e.Profile != nil {
+			stop = e.Profile.template(filename)
+		}
+		err := t.ExecuteTemplate(&buf, filename, vals)
+		if stop != nil {
+			stop()
+		}
+		if err != nil {
+			return map[string]string{}, reformatExecErrorMsg(t, filename, err)
 		}
 
 		// Work around the issue where Go will emit "<no value>" even if Options(missing=zero)
@@ -341,6 +418,33 @@ func (e Engine) render(ctx context.Context, tpls map[string]renderable) (rendere
 	return rendered, nil
 }
 
+// parse parses all templates into a single template set, and returns it along
+// with the names of the templates in parse order.
+func (e Engine) parse(ctx context.Context, tpls map[string]renderable) (*template.Template, []string, error) {
+	t := template.New("gotpl")
+	if e.Strict {
+		t.Option("missingkey=error")
+	} else {
+		// Not that zero will attempt to add default values for types it knows,
+		// but will still emit <no value> for others. We mitigate that later.
+		t.Option("missingkey=zero")
+	}
+
+	e.initFunMap(ctx, t)
+
+	// We want to parse the templates in a predictable order. The order favors
+	// higher-level (in file system) templates over deeply nested templates.
+	keys := sortTemplates(tpls)
+
+	for _, filename := range keys {
+		r := tpls[filename]
+		if _, err := t.New(filename).Parse(r.tpl); err != nil {
+			return nil, nil, cleanupParseError(filename, err)
+		}
+	}
+	return t, keys, nil
+}
+
 func cleanupParseError(filename string, err error) error {
 	tokens := strings.Split(err.Error(), ": ")
 	if len(tokens) == 1 {
@@ -359,6 +463,9 @@ type TraceableError struct {
 	location         string
 	message          string
 	executedFunction string
+	// template is the name of the template being executed, used to find the
+	// node the error occurred at.
+	template string
 }
 
 func (t TraceableError) String() string {
@@ -468,14 +575,16 @@ func parseTemplateExecutingAtErrorType(remainder string) (TraceableError, bool)
 			location:         templateName,
 			message:          errMsg,
 			executedFunction: "executing \"" + functionName + "\" at <" + locationName + ">:",
+			template:         functionName,
 		}, true
 	}
 	return TraceableError{}, false
 }
 
 // reformatExecErrorMsg takes an error message for template rendering and formats it into a formatted
-// multi-line error string
-func reformatExecErrorMsg(filename string, err error) error {
+// multi-line error string. Errors evaluating values are described with the
+// values path involved, looked up in the templates of t.
+func reformatExecErrorMsg(t *template.Template, filename string, err error) error {
 	// This function parses the error message produced by text/template package.
 	// If it can parse out details from that error message such as the line number, template it failed on,
 	// and error description, then it will construct a new error that displays these details in a structured way.
@@ -512,6 +621,10 @@ func reformatExecErrorMsg(filename string, err error) error {
 		current = errors.Unwrap(current)
 	}
 
+	if last := len(fileLocations) - 1; last >= 0 {
+		fileLocations[last].message = describeValuesError(t, fileLocations[last])
+	}
+
 	var finalErrorString strings.Builder
 	for _, fileLocation := range fileLocations {
 		_, _ = fmt.Fprintf(&finalErrorString, "%s", fileLocation.String())
@@ -17,6 +17,7 @@ limitations under the License.
 package engine
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"path"
@@ -1343,7 +1344,7 @@ NestedHelperFunctions/templates/_helpers_1.tpl:1:39
     error calling include:
 NestedHelperFunctions/charts/common/templates/_helpers_2.tpl:1:49
   executing "common.names.get_name" at <.Values.nonexistant.key>:
-    nil pointer evaluating interface {}.key`
+    .Values.nonexistant is nil (evaluating .Values.nonexistant.key)`
 
 	v := common.Values{}
 
@@ -1563,3 +1564,134 @@ func TestRenderSubchartDefaultNilNoStringify(t *testing.T) {
 		t.Errorf("Expected %q, got %q", expected, rendered)
 	}
 }
+
+func TestRenderValuesErrorContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		tpl     string
+		strict  bool
+		wantErr string
+	}{
+		{
+			name:    "nil parent",
+			tpl:     "image: {{ .Values.image.tag }}",
+			wantErr: ".Values.image is nil (evaluating .Values.image.tag)",
+		},
+		{
+			name:    "long path",
+			tpl:     "\n\nport: {{ .Values.service.ports.http.targetPort }}",
+			wantErr: "ErrorContext/templates/manifest:3:16\n  executing \"ErrorContext/templates/manifest\" at <.Values.service.ports.http.targetPort>:\n    .Values.service.ports is nil (evaluating .Values.service.ports.http)",
+		},
+		{
+			name:    "variable",
+			tpl:     "{{ $svc := .Values.service }}port: {{ $svc.ports.http }}",
+			wantErr: "$svc.ports is nil (evaluating $svc.ports.http)",
+		},
+		{
+			name:    "missing key in strict mode",
+			tpl:     "image: {{ .Values.service.tag }}",
+			strict:  true,
+			wantErr: `.Values.service.tag is not set (map has no entry for key "tag")`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &chart.Chart{
+				Metadata: &chart.Metadata{Name: "ErrorContext"},
+				Templates: []*common.File{
+					{Name: "templates/manifest", ModTime: time.Now(), Data: []byte(tt.tpl)},
+				},
+			}
+			vals := common.Values{"Values": map[string]any{"service": map[string]any{}}}
+
+			_, err := Engine{Strict: tt.strict}.Render(c, vals)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestEvaluator(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "Evaluator"},
+		Templates: []*common.File{
+			{Name: "templates/_helpers.tpl", ModTime: time.Now(), Data: []byte(`{{ define "evaluator.name" }}{{ .Release.Name }}-{{ .Chart.Name }}{{ end }}`)},
+			{Name: "templates/manifest", ModTime: time.Now(), Data: []byte(`name: {{ include "evaluator.name" . }}`)},
+		},
+	}
+	vals := common.Values{
+		"Values":  map[string]any{"image": map[string]any{"tag": "1.0"}},
+		"Release": common.Values{"Name": "rel"},
+	}
+
+	ev, err := Engine{}.NewEvaluator(context.Background(), c, vals)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Evaluator/templates/_helpers.tpl", "Evaluator/templates/manifest"}, ev.Templates())
+
+	out, err := ev.Eval(`{{ .Values.image.tag }} {{ include "evaluator.name" . }}`)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0 rel-Evaluator", out)
+
+	out, err = ev.Render("Evaluator/templates/manifest")
+	require.NoError(t, err)
+	assert.Equal(t, "name: rel-Evaluator", out)
+
+	// Snippets do not redefine the templates of the chart
+	_, err = ev.Eval(`{{ define "evaluator.name" }}changed{{ end }}`)
+	require.NoError(t, err)
+	out, err = ev.Eval(`{{ include "evaluator.name" . }}`)
+	require.NoError(t, err)
+	assert.Equal(t, "rel-Evaluator", out)
+
+	_, err = ev.Eval(`{{ .Values.missing.key }}`)
+	assert.ErrorContains(t, err, ".Values.missing is nil")
+
+	_, err = ev.Render("Evaluator/templates/missing")
+	assert.EqualError(t, err, "template Evaluator/templates/missing not found")
+}
+
+func TestRenderProfile(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "profiled"},
+		Templates: []*common.File{
+			{Name: "templates/_helpers.tpl", ModTime: time.Now(), Data: []byte(`{{ define "profiled.labels" }}{{ toYaml .Values.labels }}{{ end }}`)},
+			{Name: "templates/a.yaml", ModTime: time.Now(), Data: []byte(`labels: {{ include "profiled.labels" . }}
+name: {{ tpl "{{ .Values.name }}" . }}`)},
+			{Name: "templates/b.yaml", ModTime: time.Now(), Data: []byte(`data: {{ .Values.labels | toJson }}`)},
+		},
+	}
+	vals := common.Values{"Values": map[string]any{"name": "x", "labels": map[string]any{"app": "x"}}}
+
+	profile := NewRenderProfile()
+	out, err := Engine{Profile: profile}.Render(c, vals)
+	require.NoError(t, err)
+	assert.Equal(t, "labels: app: x\nname: x", out["profiled/templates/a.yaml"])
+
+	var templates []string
+	for _, e := range profile.Templates() {
+		templates = append(templates, e.Name)
+		assert.Equal(t, 1, e.Calls)
+	}
+	assert.ElementsMatch(t, []string{"profiled/templates/a.yaml", "profiled/templates/b.yaml"}, templates)
+
+	calls := map[string]int{}
+	for _, e := range profile.Functions() {
+		calls[e.Name] = e.Calls
+	}
+	assert.Equal(t, map[string]int{"include": 1, "tpl": 1, "toYaml": 1, "toJson": 1}, calls)
+
+	var folded strings.Builder
+	require.NoError(t, profile.WriteFolded(&folded))
+	var stacks []string
+	for _, line := range strings.Split(strings.TrimSpace(folded.String()), "\n") {
+		stacks = append(stacks, line[:strings.LastIndex(line, " ")])
+	}
+	assert.Equal(t, []string{
+		"profiled/templates/a.yaml",
+		"profiled/templates/a.yaml;include profiled.labels",
+		"profiled/templates/a.yaml;include profiled.labels;toYaml",
+		"profiled/templates/a.yaml;tpl",
+		"profiled/templates/b.yaml",
+		"profiled/templates/b.yaml;toJson",
+	}, stacks)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+const (
+	nilPointerPrefix = "nil pointer evaluating "
+	missingKeyPrefix = "map has no entry for key "
+)
+
+// describeValuesError returns the message of an error evaluating a field,
+// rewritten to name the values path involved. text/template only reports the
+// type and the last field, as in "nil pointer evaluating interface {}.tag", so
+// the expression is looked up in the parsed template at the location of the
+// error. Other messages are returned unchanged.
+func describeValuesError(t *template.Template, tr TraceableError) string {
+	var key string
+	switch {
+	case strings.HasPrefix(tr.message, nilPointerPrefix):
+		rest := tr.message[len(nilPointerPrefix):]
+		key = rest[strings.LastIndex(rest, ".")+1:]
+	case strings.HasPrefix(tr.message, missingKeyPrefix):
+		k, err := strconv.Unquote(tr.message[len(missingKeyPrefix):])
+		if err != nil {
+			return tr.message
+		}
+		key = k
+	default:
+		return tr.message
+	}
+
+	expr := fieldAt(t, tr.template, tr.location)
+	idx := strings.LastIndex(expr, "."+key)
+	if idx <= 0 {
+		return tr.message
+	}
+	field := expr[:idx+len(key)+1]
+	if strings.HasPrefix(tr.message, missingKeyPrefix) {
+		return fmt.Sprintf("%s is not set (%s)", field, tr.message)
+	}
+	return fmt.Sprintf("%s is nil (evaluating %s)", expr[:idx], field)
+}
+
+// fieldAt returns the field expression, such as ".Values.image.tag", of the
+// template tplName at location, formatted as "file:line:col".
+func fieldAt(t *template.Template, tplName, location string) string {
+	if t == nil || tplName == "" {
+		return ""
+	}
+	tpl := t.Lookup(tplName)
+	if tpl == nil || tpl.Tree == nil {
+		return ""
+	}
+	var found string
+	walkNodes(tpl.Root, func(n parse.Node) bool {
+		switch n.(type) {
+		case *parse.FieldNode, *parse.VariableNode, *parse.ChainNode:
+			if loc, _ := tpl.ErrorContext(n); loc == location {
+				found = n.String()
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// walkNodes calls fn for each node of a template tree until fn returns false.
+func walkNodes(node parse.Node, fn func(parse.Node) bool) bool {
+	if node == nil {
+		return true
+	}
+	if !fn(node) {
+		return false
+	}
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return true
+		}
+		for _, c := range n.Nodes {
+			if !walkNodes(c, fn) {
+				return false
+			}
+		}
+	case *parse.ActionNode:
+		return walkNodes(n.Pipe, fn)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			return walkNodes(n.Pipe, fn)
+		}
+	case *parse.IfNode:
+		return walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		return walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		return walkBranch(&n.BranchNode, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return true
+		}
+		for _, c := range n.Cmds {
+			if !walkNodes(c, fn) {
+				return false
+			}
+		}
+	case *parse.CommandNode:
+		for _, c := range n.Args {
+			if !walkNodes(c, fn) {
+				return false
+			}
+		}
+	case *parse.ChainNode:
+		return walkNodes(n.Node, fn)
+	}
+	return true
+}
+
+func walkBranch(n *parse.BranchNode, fn func(parse.Node) bool) bool {
+	if !walkNodes(n.Pipe, fn) || !walkNodes(n.List, fn) {
+		return false
+	}
+	if n.ElseList != nil {
+		return walkNodes(n.ElseList, fn)
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// Evaluator evaluates template snippets against the templates and values of a
+// chart, for inspecting a chart interactively.
+type Evaluator struct {
+	t     *template.Template
+	tpls  map[string]renderable
+	names []string
+	// scope is the top level object of the templates of the root chart, which
+	// snippets are evaluated against
+	scope map[string]any
+}
+
+// NewEvaluator parses the templates of a chart and its dependencies, and
+// returns an Evaluator using the values to evaluate snippets.
+func (e Engine) NewEvaluator(ctx context.Context, chrt ci.Charter, values common.Values) (*Evaluator, error) {
+	funcs, err := chartFunctions(chrt)
+	if err != nil {
+		return nil, err
+	}
+	e.funcs = funcs
+
+	tpls := make(map[string]renderable)
+	scope := recAllTpls(chrt, tpls, values)
+	t, _, err := e.parse(ctx, tpls)
+	if err != nil {
+		return nil, err
+	}
+	return &Evaluator{t: t, tpls: tpls, names: slices.Sorted(maps.Keys(tpls)), scope: scope}, nil
+}
+
+// Templates returns the sorted names of the templates of the chart and its
+// dependencies.
+func (ev *Evaluator) Templates() []string {
+	return ev.names
+}
+
+// Eval renders a template snippet, such as `{{ .Values.image | toYaml }}`,
+// which can use the named templates of the chart.
+func (ev *Evaluator) Eval(snippet string) (out string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("evaluating snippet failed: %v", r)
+		}
+	}()
+	// Snippets are parsed into a copy so that they cannot redefine the named
+	// templates of the chart
+	t, err := ev.t.Clone()
+	if err != nil {
+		return "", err
+	}
+	const name = "snippet"
+	if _, err := t.New(name).Parse(snippet); err != nil {
+		return "", cleanupParseError(name, err)
+	}
+	var buf strings.Builder
+	if err := t.ExecuteTemplate(&buf, name, ev.scope); err != nil {
+		return "", reformatExecErrorMsg(t, name, err)
+	}
+	return strings.ReplaceAll(buf.String(), "<no value>", ""), nil
+}
+
+// Render renders a single template of the chart with the values of its scope.
+func (ev *Evaluator) Render(name string) (out string, err error) {
+	r, ok := ev.tpls[name]
+	if !ok {
+		return "", fmt.Errorf("template %s not found", name)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("rendering template failed: %v", r)
+		}
+	}()
+	vals := r.vals
+	vals["Template"] = common.Values{"Name": name, "BasePath": r.basePath}
+	var buf strings.Builder
+	if err := ev.t.ExecuteTemplate(&buf, name, vals); err != nil {
+		return "", reformatExecErrorMsg(ev.t, name, err)
+	}
+	return strings.ReplaceAll(buf.String(), "<no value>", ""), nil
+}
@@ -17,10 +17,12 @@ limitations under the License.
 package engine
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"maps"
 	"math"
 	"reflect"
@@ -31,6 +33,7 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/Masterminds/sprig/v3"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"sigs.k8s.io/yaml"
 	goYaml "sigs.k8s.io/yaml/goyaml.v3"
 )
@@ -44,6 +47,7 @@ import (
 // Known late-bound functions:
 //
 //   - "include"
+//   - "includeWith"
 //   - "tpl"
 //
 // These are late-bound in Engine.Render().  The
@@ -61,8 +65,11 @@ func funcMap() template.FuncMap {
 		"toYaml":        toYAML,
 		"mustToYaml":    mustToYAML,
 		"toYamlPretty":  toYAMLPretty,
+		"toYamlIndent":  toYAMLIndent,
 		"fromYaml":      fromYAML,
 		"fromYamlArray": fromYAMLArray,
+		"fromYamlAll":   fromYAMLAll,
+		"formatYaml":    formatYAML,
 		"toJson":        toJSON,
 		"mustToJson":    mustToJSON,
 		"fromJson":      fromJSON,
@@ -81,12 +88,18 @@ func funcMap() template.FuncMap {
 		"durationRoundTo":      durationRoundTo,
 		"durationTruncateTo":   durationTruncateTo,
 
+		"mergeOverwriteDeep": mergeOverwriteDeep,
+		"mergeDeepWith":      mergeDeepWith,
+
 		// This is a placeholder for the "include" function, which is
 		// late-bound to a template. By declaring it here, we preserve the
 		// integrity of the linter.
-		"include":  func(string, any) string { return "not implemented" },
-		"tpl":      func(string, any) any { return "not implemented" },
-		"required": func(string, any) (any, error) { return "not implemented", nil },
+		"include":     func(string, any) string { return "not implemented" },
+		"includeWith": func(string, any, any) string { return "not implemented" },
+		"tpl":         func(string, any) any { return "not implemented" },
+		"required":    func(string, any) (any, error) { return "not implemented", nil },
+		"failf":       func(string, string, ...any) (string, error) { return "not implemented", nil },
+		"warn":        func(string, string) string { return "" },
 		// Provide a placeholder for the "lookup" function, which requires a kubernetes
 		// connection.
 		"lookup": func(string, string, string, string) (map[string]any, error) {
@@ -138,6 +151,59 @@ func toYAMLPretty(v any) string {
 	return strings.TrimSuffix(data.String(), "\n")
 }
 
+// toYAMLIndent takes an interface, marshals it to yaml indented by the given
+// number of spaces, and returns a string. Like toYAMLPretty, lists are indented
+// under their key. It will always return a string, even on marshal error
+// (empty string).
+//
+// This is designed to be called from a template.
+func toYAMLIndent(indent int, v any) string {
+	if indent < 1 {
+		return ""
+	}
+	var data bytes.Buffer
+	encoder := goYaml.NewEncoder(&data)
+	encoder.SetIndent(indent)
+	if err := encoder.Encode(v); err != nil {
+		// Swallow errors inside of a template.
+		return ""
+	}
+	return strings.TrimSuffix(data.String(), "\n")
+}
+
+// formatYAML reindents YAML documents by the given number of spaces, keeping
+// the order of keys and the comments, so that a YAML file of the chart can be
+// embedded in a manifest without losing its layout. Documents are separated by
+// "---".
+//
+// This is designed to be called from a template. It fails the template when the
+// YAML is invalid.
+func formatYAML(indent int, str string) (string, error) {
+	if indent < 1 {
+		return "", fmt.Errorf("formatYaml: indent must be positive, got %d", indent)
+	}
+	var data bytes.Buffer
+	encoder := goYaml.NewEncoder(&data)
+	encoder.SetIndent(indent)
+	decoder := goYaml.NewDecoder(strings.NewReader(str))
+	for {
+		var doc goYaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", fmt.Errorf("formatYaml: %w", err)
+		}
+		if err := encoder.Encode(&doc); err != nil {
+			return "", fmt.Errorf("formatYaml: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("formatYaml: %w", err)
+	}
+	return strings.TrimSuffix(data.String(), "\n"), nil
+}
+
 // fromYAML converts a YAML document into a map[string]interface{}.
 //
 // This is not a general-purpose YAML parser, and will not parse all valid
@@ -168,6 +234,33 @@ func fromYAMLArray(str string) []any {
 	return a
 }
 
+// fromYAMLAll converts a stream of YAML documents separated by "---" into a
+// []interface{} holding each document. Empty documents are left out.
+//
+// Like fromYAMLArray, it tolerates errors, returning the error message string
+// as the first and only item in the returned array.
+func fromYAMLAll(str string) []any {
+	a := []any{}
+
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(str)))
+	for {
+		doc, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return a
+			}
+			return []any{err.Error()}
+		}
+		var v any
+		if err := yaml.Unmarshal(doc, &v); err != nil {
+			return []any{err.Error()}
+		}
+		if v != nil {
+			a = append(a, v)
+		}
+	}
+}
+
 // toTOML takes an interface, marshals it to toml, and returns a string.
 // On marshal error it returns the error string.
 //
@@ -40,6 +40,10 @@ func TestFuncs(t *testing.T) {
 		tpl:    `{{ toYamlPretty . }}`,
 		expect: "baz:\n  - 1\n  - 2\n  - 3",
 		vars:   map[string]any{"baz": []int{1, 2, 3}},
+	}, {
+		tpl:    `{{ toYamlIndent 4 . }}`,
+		expect: "a:\n    b: c\nbaz:\n    - 1\n    - qux: true",
+		vars:   map[string]any{"baz": []any{1, map[string]any{"qux": true}}, "a": map[string]any{"b": "c"}},
 	}, {
 		tpl:    `{{ toToml . }}`,
 		expect: "foo = \"bar\"\n",
@@ -84,6 +88,14 @@ keyInElement1 = "valueInElement1"`,
 		tpl:    `{{ fromYamlArray . }}`,
 		expect: "[one 2 map[name:helm]]",
 		vars:   `["one", 2, { "name": "helm" }]`,
+	}, {
+		tpl:    `{{ fromYamlAll . }}`,
+		expect: "[map[name:one] [two] three]",
+		vars:   "name: one\n---\n# empty\n---\n- two\n---\nthree\n",
+	}, {
+		tpl:    `{{ fromYamlAll . }}`,
+		expect: "[error converting YAML to JSON: yaml: line 1: did not find expected ',' or ']']",
+		vars:   "[one\n",
 	}, {
 		// Regression for https://github.com/helm/helm/issues/2271
 		tpl:    `{{ toToml . }}`,
@@ -176,6 +188,16 @@ keyInElement1 = "valueInElement1"`,
 	}, {
 		tpl:  `{{ mustToToml . }}`,
 		vars: map[int]string{1: "one"}, // non-string key is invalid in TOML
+	}, {
+		tpl:    `{{ formatYaml 4 . }}`,
+		expect: "# config\nz: 1\na:\n    - b # keep\n---\nc: 2",
+		vars:   "# config\nz: 1\na:\n  - b # keep\n---\nc: 2\n",
+	}, {
+		tpl:  `{{ formatYaml 2 . }}`,
+		vars: "a: [",
+	}, {
+		tpl:  `{{ formatYaml 0 . }}`,
+		vars: "a: 1",
 	}, {
 		tpl:    `{{ mustToToml . }}`,
 		expect: "foo = \"bar\"\n", // should succeed and return TOML string
@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"text/template"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+)
+
+const (
+	// FunctionSetV1 is the set of template functions of Helm 4.0.
+	FunctionSetV1 = "v1"
+	// FunctionSetV2 adds the deep merge, YAML formatting and warning functions
+	// to FunctionSetV1.
+	FunctionSetV2 = "v2"
+)
+
+// functionSets are the names of the template functions of each function set a
+// chart can declare with `functionSet` in its Chart.yaml. A function set never
+// changes once released: functions added to Helm later only become available
+// to charts through a new function set, so that a chart declaring a set
+// renders with the same functions on every version of Helm supporting it.
+var functionSets = map[string][]string{
+	FunctionSetV1: functionSetV1,
+	FunctionSetV2: append(slices.Clone(functionSetV1),
+		"includeWith", "mergeDeepWith", "mergeOverwriteDeep",
+		"formatYaml", "fromYamlAll", "toYamlIndent",
+		"failf", "warn",
+	),
+}
+
+var functionSetV1 = []string{
+	"abbrev", "abbrevboth", "add", "add1", "add1f", "addf", "adler32sum",
+	"ago", "all", "any", "append", "atoi", "b32dec", "b32enc", "b64dec",
+	"b64enc", "base", "bcrypt", "biggest", "buildCustomCert", "camelcase",
+	"cat", "ceil", "chunk", "clean", "coalesce", "compact", "concat",
+	"contains", "date", "dateInZone", "dateModify", "date_in_zone",
+	"date_modify", "decryptAES", "deepCopy", "deepEqual", "default",
+	"derivePassword", "dict", "dig", "dir", "div", "divf", "duration",
+	"durationDays", "durationHours", "durationMicroseconds",
+	"durationMilliseconds", "durationMinutes", "durationNanoseconds",
+	"durationRound", "durationRoundTo", "durationSeconds",
+	"durationTruncateTo", "durationWeeks", "empty", "encryptAES", "ext",
+	"fail", "first", "float64", "floor", "fromJson", "fromJsonArray",
+	"fromToml", "fromYaml", "fromYamlArray", "genCA", "genCAWithKey",
+	"genPrivateKey", "genSelfSignedCert", "genSelfSignedCertWithKey",
+	"genSignedCert", "genSignedCertWithKey", "get", "getHostByName", "has",
+	"hasKey", "hasPrefix", "hasSuffix", "hello", "htmlDate", "htmlDateInZone",
+	"htpasswd", "include", "indent", "initial", "initials", "int", "int64",
+	"isAbs", "join", "kebabcase", "keys", "kindIs", "kindOf", "last", "list",
+	"lookup", "lower", "max", "maxf", "merge", "mergeOverwrite", "min", "minf",
+	"mod", "mul", "mulf", "mustAppend", "mustChunk", "mustCompact",
+	"mustDateModify", "mustDeepCopy", "mustFirst", "mustFromJson", "mustHas",
+	"mustInitial", "mustLast", "mustMerge", "mustMergeOverwrite",
+	"mustPrepend", "mustPush", "mustRegexFind", "mustRegexFindAll",
+	"mustRegexMatch", "mustRegexReplaceAll", "mustRegexReplaceAllLiteral",
+	"mustRegexSplit", "mustRest", "mustReverse", "mustSlice", "mustToDate",
+	"mustToDuration", "mustToJson", "mustToPrettyJson", "mustToRawJson",
+	"mustToToml", "mustToYaml", "mustUniq", "mustWithout", "must_date_modify",
+	"nindent", "nospace", "now", "omit", "osBase", "osClean", "osDir", "osExt",
+	"osIsAbs", "pick", "pluck", "plural", "prepend", "push", "quote",
+	"randAlpha", "randAlphaNum", "randAscii", "randBytes", "randInt",
+	"randNumeric", "regexFind", "regexFindAll", "regexMatch", "regexQuoteMeta",
+	"regexReplaceAll", "regexReplaceAllLiteral", "regexSplit", "repeat",
+	"replace", "required", "rest", "reverse", "round", "semver",
+	"semverCompare", "seq", "set", "sha1sum", "sha256sum", "sha512sum",
+	"shuffle", "slice", "snakecase", "sortAlpha", "split", "splitList",
+	"splitn", "squote", "sub", "subf", "substr", "swapcase", "ternary",
+	"title", "toDate", "toDecimal", "toJson", "toPrettyJson", "toRawJson",
+	"toString", "toStrings", "toToml", "toYaml", "toYamlPretty", "tpl", "trim",
+	"trimAll", "trimPrefix", "trimSuffix", "trimall", "trunc", "tuple",
+	"typeIs", "typeIsLike", "typeOf", "uniq", "unixEpoch", "unset", "until",
+	"untilStep", "untitle", "upper", "urlJoin", "urlParse", "uuidv4", "values",
+	"without", "wrap", "wrapWith",
+}
+
+// FunctionSets returns the sorted names of the function sets charts can
+// declare.
+func FunctionSets() []string {
+	return slices.Sorted(maps.Keys(functionSets))
+}
+
+// chartFunctions returns the names of the template functions available to the
+// templates of a chart and its dependencies, or nil if none of them declares a
+// function set. As all templates are parsed together, when several charts
+// declare a function set, only the functions of every declared set are
+// available.
+func chartFunctions(chrt ci.Charter) (map[string]bool, error) {
+	var funcs map[string]bool
+	err := walkCharts(chrt, func(accessor ci.Accessor) error {
+		set, _ := accessor.MetadataAsMap()["FunctionSet"].(string)
+		if set == "" {
+			return nil
+		}
+		names, ok := functionSets[set]
+		if !ok {
+			return fmt.Errorf("chart %s requires template function set %q, supported function sets are %v", accessor.Name(), set, FunctionSets())
+		}
+		declared := make(map[string]bool, len(names))
+		for _, name := range names {
+			if funcs == nil || funcs[name] {
+				declared[name] = true
+			}
+		}
+		funcs = declared
+		return nil
+	})
+	return funcs, err
+}
+
+// walkCharts calls fn for a chart and each of its dependencies.
+func walkCharts(chrt ci.Charter, fn func(ci.Accessor) error) error {
+	accessor, err := ci.NewAccessor(chrt)
+	if err != nil {
+		return err
+	}
+	if err := fn(accessor); err != nil {
+		return err
+	}
+	for _, dep := range accessor.Dependencies() {
+		if err := walkCharts(dep, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restrictFuncs removes the functions of a function map that are not in funcs,
+// unless funcs is nil.
+func restrictFuncs(funcMap template.FuncMap, funcs map[string]bool) {
+	if funcs == nil {
+		return
+	}
+	maps.DeleteFunc(funcMap, func(name string, _ any) bool {
+		return !funcs[name]
+	})
+}
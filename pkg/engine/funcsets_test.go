@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestFunctionSetsExist(t *testing.T) {
+	funcs := funcMap()
+	for _, set := range FunctionSets() {
+		for _, name := range functionSets[set] {
+			assert.Contains(t, funcs, name, "function %s of function set %s is not defined", name, set)
+		}
+	}
+}
+
+func TestRenderFunctionSet(t *testing.T) {
+	// A function set without the functions added after it
+	functionSets["test"] = []string{"include", "upper"}
+	t.Cleanup(func() { delete(functionSets, "test") })
+
+	tests := []struct {
+		name        string
+		rootSet     string
+		depSet      string
+		template    string
+		custom      map[string]any
+		expect      string
+		expectError string
+	}{
+		{
+			name:     "no function set",
+			template: `{{ "a" | upper | lower }}`,
+			expect:   "a",
+		},
+		{
+			name:     "function of the function set",
+			rootSet:  "test",
+			template: `{{ "a" | upper }}`,
+			expect:   "A",
+		},
+		{
+			name:        "function outside of the function set",
+			rootSet:     "test",
+			template:    `{{ "a" | upper | lower }}`,
+			expectError: `function "lower" not defined`,
+		},
+		{
+			name:        "function outside of the function set of a dependency",
+			depSet:      "test",
+			template:    `{{ "a" | lower }}`,
+			expectError: `function "lower" not defined`,
+		},
+		{
+			name:     "custom function outside of the function set",
+			rootSet:  "test",
+			template: `{{ "a" | shout }}`,
+			custom:   map[string]any{"shout": func(s string) string { return strings.ToUpper(s) + "!" }},
+			expect:   "A!",
+		},
+		{
+			name:        "unknown function set",
+			rootSet:     "v99",
+			template:    `{{ "a" }}`,
+			expectError: `chart moby requires template function set "v99"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &chart.Chart{
+				Metadata: &chart.Metadata{Name: "dep", Version: "1.0.0", FunctionSet: tt.depSet},
+			}
+			c := &chart.Chart{
+				Metadata:  &chart.Metadata{Name: "moby", Version: "1.0.0", FunctionSet: tt.rootSet},
+				Templates: []*common.File{{Name: "templates/test", Data: []byte(tt.template)}},
+			}
+			c.AddDependency(dep)
+
+			out, err := new(Engine).WithFuncs(tt.custom).Render(c, common.Values{})
+			if tt.expectError != "" {
+				require.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, out["moby/templates/test"])
+		})
+	}
+}
+
+func TestWithFuncs(t *testing.T) {
+	e := Engine{CustomTemplateFuncs: map[string]any{"first": func() string { return "1" }}}
+	with := e.WithFuncs(map[string]any{"second": func() string { return "2" }})
+
+	assert.Len(t, e.CustomTemplateFuncs, 1, "WithFuncs must not change the original engine")
+	assert.Contains(t, with.CustomTemplateFuncs, "first")
+	assert.Contains(t, with.CustomTemplateFuncs, "second")
+}
@@ -0,0 +1,143 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// LookupFixtures backs the `lookup` template function with objects read from
+// files rather than a cluster, so that charts using `lookup` render the same
+// way without one.
+type LookupFixtures struct {
+	objects []*unstructured.Unstructured
+}
+
+// LoadLookupFixtures reads the objects of the YAML and JSON files of a
+// directory and its subdirectories. Files can hold several documents, and
+// lists of objects such as the output of `kubectl get -o yaml`.
+func LoadLookupFixtures(dir string) (*LookupFixtures, error) {
+	f := &LookupFixtures{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		if err := f.load(path); err != nil {
+			return fmt.Errorf("unable to load lookup fixtures from %s: %w", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(f.objects, func(a, b *unstructured.Unstructured) int {
+		if c := strings.Compare(a.GetNamespace(), b.GetNamespace()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+	return f, nil
+}
+
+// load adds the objects of a file.
+func (f *LookupFixtures) load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(file, 4096)
+	for {
+		var doc map[string]any
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: doc}
+		if obj.IsList() {
+			if err := obj.EachListItem(func(item runtime.Object) error {
+				f.objects = append(f.objects, item.(*unstructured.Unstructured))
+				return nil
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			return fmt.Errorf("object %q has no apiVersion or kind", obj.GetName())
+		}
+		f.objects = append(f.objects, obj)
+	}
+}
+
+// lookup implements the `lookup` template function. Like a lookup in a
+// cluster, it returns an empty object when the object is not found, and a list
+// when name is empty. An empty namespace matches objects of all namespaces.
+func (f *LookupFixtures) lookup(apiversion string, kind string, namespace string, name string) (map[string]any, error) {
+	var items []any
+	for _, obj := range f.objects {
+		if obj.GetAPIVersion() != apiversion || obj.GetKind() != kind {
+			continue
+		}
+		if namespace != "" && obj.GetNamespace() != namespace {
+			continue
+		}
+		if name == "" {
+			items = append(items, obj.DeepCopy().Object)
+		} else if obj.GetName() == name {
+			return obj.DeepCopy().Object, nil
+		}
+	}
+	if name != "" {
+		return map[string]any{}, nil
+	}
+	if items == nil {
+		items = []any{}
+	}
+	return map[string]any{
+		"apiVersion": apiversion,
+		"kind":       kind + "List",
+		"metadata":   map[string]any{},
+		"items":      items,
+	}, nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupFixtures(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "configmaps.yaml"), []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+  namespace: one
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: two
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "ns.json"), []byte(`{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "one"}}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a fixture"), 0o644))
+
+	f, err := LoadLookupFixtures(dir)
+	require.NoError(t, err)
+
+	obj, err := f.lookup("v1", "ConfigMap", "one", "b")
+	require.NoError(t, err)
+	assert.Equal(t, "b", obj["metadata"].(map[string]any)["name"])
+
+	obj, err = f.lookup("v1", "ConfigMap", "two", "b")
+	require.NoError(t, err)
+	assert.Empty(t, obj)
+
+	obj, err = f.lookup("v1", "Namespace", "", "one")
+	require.NoError(t, err)
+	assert.Equal(t, "Namespace", obj["kind"])
+
+	list, err := f.lookup("v1", "ConfigMap", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "ConfigMapList", list["kind"])
+	var names []string
+	for _, item := range list["items"].([]any) {
+		names = append(names, item.(map[string]any)["metadata"].(map[string]any)["name"].(string))
+	}
+	assert.Equal(t, []string{"b", "a"}, names)
+
+	list, err = f.lookup("apps/v1", "Deployment", "one", "")
+	require.NoError(t, err)
+	assert.Equal(t, []any{}, list["items"])
+}
+
+func TestLoadLookupFixturesInvalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte("metadata:\n  name: a\n"), 0o644))
+
+	_, err := LoadLookupFixtures(dir)
+	assert.ErrorContains(t, err, `object "a" has no apiVersion or kind`)
+}
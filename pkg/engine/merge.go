@@ -0,0 +1,201 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// How lists found at the same key of merged maps are merged.
+const (
+	// listsReplace replaces the list with the later one.
+	listsReplace = "replace"
+	// listsAppend appends the items of the later list.
+	listsAppend = "append"
+	// listsMerge deep merges the items of the lists having the same value for
+	// a key, such as the containers of a pod by name, and appends the others.
+	listsMerge = "merge"
+)
+
+// mergeOptions control a deep merge.
+type mergeOptions struct {
+	lists string
+	// key identifies the items of lists merged with listsMerge
+	key string
+}
+
+// mergeOverwriteDeep deep merges maps, the values of later maps overwriting
+// the values of earlier ones. A null value deletes the key, and lists are
+// replaced. The maps are left unchanged.
+//
+//	{{ mergeOverwriteDeep .Values.defaults .Values.overrides | toYaml }}
+func mergeOverwriteDeep(dst any, srcs ...any) (map[string]any, error) {
+	return mergeOptions{lists: listsReplace}.mergeAll(dst, srcs)
+}
+
+// mergeDeepWith deep merges maps like mergeOverwriteDeep, merging lists as
+// set by the options: "lists" is one of "replace" (the default), "append" or
+// "merge", and "key" the key identifying the items of merged lists, "name" by
+// default.
+//
+//	{{ mergeDeepWith (dict "lists" "merge" "key" "name") .Values.containers .Values.extraContainers }}
+func mergeDeepWith(options map[string]any, dst any, srcs ...any) (map[string]any, error) {
+	o := mergeOptions{lists: listsReplace, key: "name"}
+	for k, v := range options {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("mergeDeepWith: option %q must be a string, got %T", k, v)
+		}
+		switch k {
+		case "lists":
+			switch s {
+			case listsReplace, listsAppend, listsMerge:
+				o.lists = s
+			default:
+				return nil, fmt.Errorf("mergeDeepWith: unknown lists strategy %q, must be one of %s, %s or %s", s, listsReplace, listsAppend, listsMerge)
+			}
+		case "key":
+			o.key = s
+		default:
+			return nil, fmt.Errorf("mergeDeepWith: unknown option %q", k)
+		}
+	}
+	return o.mergeAll(dst, srcs)
+}
+
+// includeWithFun returns the `includeWith` function, which includes a named
+// template with its data deep merged with overrides, such as the top level
+// object with a few values changed:
+//
+//	{{ includeWith "mychart.container" . (dict "Values" (dict "image" .Values.sidecar.image)) }}
+func includeWithFun(include func(string, any) (string, error)) func(string, any, any) (string, error) {
+	return func(name string, data any, overrides any) (string, error) {
+		merged, err := mergeOverwriteDeep(data, overrides)
+		if err != nil {
+			return "", fmt.Errorf("includeWith %q: %w", name, err)
+		}
+		return include(name, merged)
+	}
+}
+
+// mergeAll merges srcs into dst in order.
+func (o mergeOptions) mergeAll(dst any, srcs []any) (map[string]any, error) {
+	out, ok := toMap(dst)
+	if !ok {
+		return nil, fmt.Errorf("cannot merge %T, only maps can be merged", dst)
+	}
+	out = maps.Clone(out)
+	if out == nil {
+		out = map[string]any{}
+	}
+	for _, src := range srcs {
+		m, ok := toMap(src)
+		if !ok {
+			return nil, fmt.Errorf("cannot merge %T, only maps can be merged", src)
+		}
+		var err error
+		if out, err = o.merge(out, m); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// merge returns dst deep merged with src, without changing either of them.
+func (o mergeOptions) merge(dst, src map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(dst)+len(src))
+	maps.Copy(out, dst)
+	for k, sv := range src {
+		if sv == nil {
+			delete(out, k)
+			continue
+		}
+		if sm, ok := toMap(sv); ok {
+			dm, _ := toMap(out[k])
+			m, err := o.merge(dm, sm)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = m
+			continue
+		}
+		if sl, ok := sv.([]any); ok {
+			if dl, ok := out[k].([]any); ok {
+				l, err := o.mergeLists(dl, sl)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", k, err)
+				}
+				out[k] = l
+				continue
+			}
+		}
+		out[k] = sv
+	}
+	return out, nil
+}
+
+// mergeLists merges the lists found at the same key.
+func (o mergeOptions) mergeLists(dst, src []any) ([]any, error) {
+	switch o.lists {
+	case listsAppend:
+		return append(slices.Clone(dst), src...), nil
+	case listsMerge:
+		out := slices.Clone(dst)
+		for _, item := range src {
+			m, ok := toMap(item)
+			if !ok || m[o.key] == nil {
+				out = append(out, item)
+				continue
+			}
+			n := slices.IndexFunc(out, func(existing any) bool {
+				em, ok := toMap(existing)
+				return ok && reflect.DeepEqual(em[o.key], m[o.key])
+			})
+			if n < 0 {
+				out = append(out, item)
+				continue
+			}
+			em, _ := toMap(out[n])
+			merged, err := o.merge(em, m)
+			if err != nil {
+				return nil, err
+			}
+			out[n] = merged
+		}
+		return out, nil
+	default:
+		return slices.Clone(src), nil
+	}
+}
+
+// toMap returns v as a map if it is one, such as the values or the top level
+// object of a template.
+func toMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case map[string]any:
+		return m, true
+	case common.Values:
+		return m, true
+	default:
+		return nil, false
+	}
+}
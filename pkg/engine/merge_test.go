@@ -0,0 +1,176 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestMergeDeepWith(t *testing.T) {
+	containers := func() map[string]any {
+		return map[string]any{"containers": []any{
+			map[string]any{"name": "app", "image": "app:1", "env": []any{"A"}},
+			map[string]any{"name": "proxy", "image": "proxy:1"},
+		}}
+	}
+
+	tests := []struct {
+		name        string
+		options     map[string]any
+		dst         any
+		srcs        []any
+		expect      map[string]any
+		expectError string
+	}{
+		{
+			name: "nested maps",
+			dst:  map[string]any{"image": map[string]any{"repository": "nginx", "tag": "1.0"}, "replicas": 1},
+			srcs: []any{common.Values{"image": map[string]any{"tag": "2.0"}}},
+			expect: map[string]any{
+				"image":    map[string]any{"repository": "nginx", "tag": "2.0"},
+				"replicas": 1,
+			},
+		},
+		{
+			name: "later maps win",
+			dst:  map[string]any{"a": 1},
+			srcs: []any{map[string]any{"a": 2}, map[string]any{"a": 3}},
+			expect: map[string]any{
+				"a": 3,
+			},
+		},
+		{
+			name: "null deletes",
+			dst:  map[string]any{"a": 1, "b": map[string]any{"c": 2, "d": 3}},
+			srcs: []any{map[string]any{"a": nil, "b": map[string]any{"c": nil}, "e": map[string]any{"f": nil}}},
+			expect: map[string]any{
+				"b": map[string]any{"d": 3},
+				"e": map[string]any{},
+			},
+		},
+		{
+			name: "lists are replaced by default",
+			dst:  containers(),
+			srcs: []any{map[string]any{"containers": []any{map[string]any{"name": "app", "image": "app:2"}}}},
+			expect: map[string]any{"containers": []any{
+				map[string]any{"name": "app", "image": "app:2"},
+			}},
+		},
+		{
+			name:    "append lists",
+			options: map[string]any{"lists": "append"},
+			dst:     map[string]any{"args": []any{"-a"}},
+			srcs:    []any{map[string]any{"args": []any{"-b"}}},
+			expect:  map[string]any{"args": []any{"-a", "-b"}},
+		},
+		{
+			name:    "merge lists by key",
+			options: map[string]any{"lists": "merge"},
+			dst:     containers(),
+			srcs: []any{map[string]any{"containers": []any{
+				map[string]any{"name": "app", "image": "app:2", "env": []any{"B"}},
+				map[string]any{"name": "sidecar", "image": "sidecar:1"},
+			}}},
+			expect: map[string]any{"containers": []any{
+				map[string]any{"name": "app", "image": "app:2", "env": []any{"A", "B"}},
+				map[string]any{"name": "proxy", "image": "proxy:1"},
+				map[string]any{"name": "sidecar", "image": "sidecar:1"},
+			}},
+		},
+		{
+			name:    "merge lists by custom key",
+			options: map[string]any{"lists": "merge", "key": "port"},
+			dst:     map[string]any{"ports": []any{map[string]any{"port": 80, "name": "http"}}},
+			srcs:    []any{map[string]any{"ports": []any{map[string]any{"port": 80, "protocol": "TCP"}, "extra"}}},
+			expect: map[string]any{"ports": []any{
+				map[string]any{"port": 80, "name": "http", "protocol": "TCP"},
+				"extra",
+			}},
+		},
+		{
+			name:        "unknown lists strategy",
+			options:     map[string]any{"lists": "zip"},
+			dst:         map[string]any{},
+			expectError: `unknown lists strategy "zip"`,
+		},
+		{
+			name:        "unknown option",
+			options:     map[string]any{"depth": "1"},
+			dst:         map[string]any{},
+			expectError: `unknown option "depth"`,
+		},
+		{
+			name:        "not a map",
+			dst:         map[string]any{},
+			srcs:        []any{"a"},
+			expectError: "cannot merge string, only maps can be merged",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := mergeDeepWith(tt.options, tt.dst, tt.srcs...)
+			if tt.expectError != "" {
+				require.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, out)
+		})
+	}
+}
+
+func TestMergeOverwriteDeepLeavesMapsUnchanged(t *testing.T) {
+	dst := map[string]any{"a": map[string]any{"b": 1}, "l": []any{1}}
+	src := map[string]any{"a": map[string]any{"b": 2, "c": nil}, "l": []any{2}}
+
+	out, err := mergeOverwriteDeep(dst, src)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": map[string]any{"b": 2}, "l": []any{2}}, out)
+	assert.Equal(t, map[string]any{"a": map[string]any{"b": 1}, "l": []any{1}}, dst)
+	assert.Equal(t, map[string]any{"a": map[string]any{"b": 2, "c": nil}, "l": []any{2}}, src)
+}
+
+func TestRenderMergeFunctions(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.0.0", FunctionSet: FunctionSetV2},
+		Templates: []*common.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "image" }}{{ .Values.image.repository }}:{{ .Values.image.tag }}{{ end }}`)},
+			{Name: "templates/merge", Data: []byte(`{{ mergeOverwriteDeep .Values.defaults .Values.overrides | toJson }}`)},
+			{Name: "templates/include", Data: []byte(`{{ include "image" . }} {{ includeWith "image" . (dict "Values" (dict "image" (dict "tag" "2.0"))) }}`)},
+		},
+	}
+	vals := common.Values{"Values": map[string]any{
+		"image":     map[string]any{"repository": "nginx", "tag": "1.0"},
+		"defaults":  map[string]any{"a": 1, "b": map[string]any{"c": 2}},
+		"overrides": map[string]any{"a": nil, "b": map[string]any{"d": 3}},
+	}}
+
+	out, err := new(Engine).Render(c, vals)
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":{"c":2,"d":3}}`, out["moby/templates/merge"])
+	assert.Equal(t, "nginx:1.0 nginx:2.0", out["moby/templates/include"])
+
+	c.Metadata.FunctionSet = FunctionSetV1
+	_, err = new(Engine).Render(c, vals)
+	require.ErrorContains(t, err, `function "mergeOverwriteDeep" not defined`)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// profiledFuncs are the template functions whose calls are timed by a
+// RenderProfile, as they render templates, talk to the cluster or serialize
+// possibly large values.
+var profiledFuncs = []string{
+	"include", "includeWith", "tpl", "lookup", "getHostByName",
+	"toYaml", "toYamlPretty", "toYamlIndent", "formatYaml",
+	"fromYaml", "fromYamlArray", "fromYamlAll",
+	"toJson", "mustToJson", "fromJson", "fromJsonArray", "toToml",
+}
+
+// ProfileEntry is the time spent rendering a template or calling a function.
+type ProfileEntry struct {
+	// Name is the name of the template or function.
+	Name string
+	// Calls is how many times the template was rendered or the function called.
+	Calls int
+	// Duration is the total time spent, including nested calls.
+	Duration time.Duration
+}
+
+// RenderProfile records the time spent rendering each template, and calling
+// the expensive template functions. A profile is not safe for concurrent
+// renders.
+type RenderProfile struct {
+	stack []*profileFrame
+	// samples is the time spent in each stack of frames, excluding nested
+	// frames, keyed by the frames joined with ';'
+	samples   map[string]time.Duration
+	templates map[string]*ProfileEntry
+	funcs     map[string]*ProfileEntry
+}
+
+type profileFrame struct {
+	name     string
+	start    time.Time
+	children time.Duration
+}
+
+// NewRenderProfile creates an empty render profile.
+func NewRenderProfile() *RenderProfile {
+	return &RenderProfile{
+		samples:   map[string]time.Duration{},
+		templates: map[string]*ProfileEntry{},
+		funcs:     map[string]*ProfileEntry{},
+	}
+}
+
+// push starts timing a frame.
+func (p *RenderProfile) push(name string) {
+	p.stack = append(p.stack, &profileFrame{name: name, start: time.Now()})
+}
+
+// pop stops timing the current frame and records it in entries.
+func (p *RenderProfile) pop(entries map[string]*ProfileEntry, name string) {
+	frame := p.stack[len(p.stack)-1]
+	elapsed := time.Since(frame.start)
+
+	names := make([]string, len(p.stack))
+	for n, f := range p.stack {
+		names[n] = strings.ReplaceAll(f.name, ";", ":")
+	}
+	p.samples[strings.Join(names, ";")] += elapsed - frame.children
+
+	p.stack = p.stack[:len(p.stack)-1]
+	if len(p.stack) > 0 {
+		p.stack[len(p.stack)-1].children += elapsed
+	}
+
+	entry, ok := entries[name]
+	if !ok {
+		entry = &ProfileEntry{Name: name}
+		entries[name] = entry
+	}
+	entry.Calls++
+	entry.Duration += elapsed
+}
+
+// template times the rendering of a template until the returned function is
+// called.
+func (p *RenderProfile) template(name string) func() {
+	p.push(name)
+	return func() { p.pop(p.templates, name) }
+}
+
+// wrap replaces the profiled functions of a function map with functions
+// timing their calls.
+func (p *RenderProfile) wrap(funcMap template.FuncMap) {
+	for _, name := range profiledFuncs {
+		fn, ok := funcMap[name]
+		if !ok {
+			continue
+		}
+		fv := reflect.ValueOf(fn)
+		if fv.Kind() != reflect.Func {
+			continue
+		}
+		funcMap[name] = reflect.MakeFunc(fv.Type(), func(args []reflect.Value) []reflect.Value {
+			frame := name
+			// Name the template included, which is what a flame graph needs
+			if (name == "include" || name == "includeWith") && len(args) > 0 && args[0].Kind() == reflect.String {
+				frame = name + " " + args[0].String()
+			}
+			p.push(frame)
+			defer p.pop(p.funcs, name)
+			if fv.Type().IsVariadic() {
+				return fv.CallSlice(args)
+			}
+			return fv.Call(args)
+		}).Interface()
+	}
+}
+
+// Templates returns the time spent rendering each template, slowest first.
+func (p *RenderProfile) Templates() []ProfileEntry {
+	return sortedEntries(p.templates)
+}
+
+// Functions returns the time spent calling each profiled function, slowest
+// first.
+func (p *RenderProfile) Functions() []ProfileEntry {
+	return sortedEntries(p.funcs)
+}
+
+// WriteFolded writes the profile as folded stacks, one line per stack of
+// templates and function calls followed by the microseconds spent in it. The
+// output can be turned into a flame graph with tools such as flamegraph.pl,
+// speedscope or inferno.
+func (p *RenderProfile) WriteFolded(w io.Writer) error {
+	for _, stack := range slices.Sorted(maps.Keys(p.samples)) {
+		if _, err := fmt.Fprintf(w, "%s %d\n", stack, p.samples[stack].Microseconds()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedEntries(entries map[string]*ProfileEntry) []ProfileEntry {
+	sorted := make([]ProfileEntry, 0, len(entries))
+	for _, e := range entries {
+		sorted = append(sorted, *e)
+	}
+	slices.SortFunc(sorted, func(a, b ProfileEntry) int {
+		if c := cmp.Compare(b.Duration, a.Duration); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return sorted
+}
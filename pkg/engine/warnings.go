@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// RenderWarning is a warning emitted by a template with the `warn` function,
+// such as about a deprecated value.
+type RenderWarning struct {
+	// Code identifies the warning, such as "DEPRECATED_VALUE", so that tools
+	// can act on it without parsing the message.
+	Code string `json:"code"`
+	// Message describes the warning.
+	Message string `json:"message"`
+}
+
+func (w RenderWarning) String() string {
+	return fmt.Sprintf("[%s] %s", w.Code, w.Message)
+}
+
+// RenderWarnings collects the warnings emitted by templates. The zero value is
+// ready to use.
+type RenderWarnings struct {
+	mu       sync.Mutex
+	warnings []RenderWarning
+}
+
+// Warnings returns the warnings emitted so far, in the order they were first
+// emitted. A warning emitted several times, such as by a named template
+// included by many templates, is only returned once.
+func (w *RenderWarnings) Warnings() []RenderWarning {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.warnings)
+}
+
+func (w *RenderWarnings) add(warning RenderWarning) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !slices.Contains(w.warnings, warning) {
+		w.warnings = append(w.warnings, warning)
+	}
+}
+
+// warnFun returns the `warn` function, which records a warning with a code and
+// renders nothing:
+//
+//	{{- if .Values.tag }}{{ warn "DEPRECATED_VALUE" ".Values.tag is deprecated, use .Values.image.tag" }}{{ end }}
+//
+// Without a collector, warnings are logged. emitted, if not nil, counts the
+// warnings.
+func warnFun(warnings *RenderWarnings, emitted *int) func(string, string) string {
+	return func(code, msg string) string {
+		if emitted != nil {
+			*emitted++
+		}
+		warning := RenderWarning{Code: code, Message: msg}
+		if warnings == nil {
+			slog.Warn("template warning", slog.String("code", code), slog.String("message", msg))
+			return ""
+		}
+		warnings.add(warning)
+		return ""
+	}
+}
+
+// failfFun returns the `failf` function, which fails rendering like `fail`
+// with a code and a formatted message, such as "[UNSUPPORTED_VALUE] mode
+// "legacy" is not supported":
+//
+//	{{ failf "UNSUPPORTED_VALUE" "mode %q is not supported" .Values.mode }}
+func failfFun(lintMode bool) func(string, string, ...any) (string, error) {
+	return func(code, format string, args ...any) (string, error) {
+		msg := fmt.Sprintf("[%s] %s", code, fmt.Sprintf(format, args...))
+		if lintMode {
+			// Don't fail when linting
+			slog.Info("funcMap failf", "message", msg)
+			return "", nil
+		}
+		return "", errors.New(warnWrap(msg))
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestRenderWarnings(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.0.0"},
+		Templates: []*common.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "tag" }}{{ if .Values.tag }}{{ warn "DEPRECATED_VALUE" "tag is deprecated, use image.tag" }}{{ end }}{{ end }}`)},
+			{Name: "templates/a", Data: []byte(`a{{ include "tag" . }}`)},
+			{Name: "templates/b", Data: []byte(`b{{ include "tag" . }}{{ warn "NO_RESOURCES" "no resources are set" }}`)},
+		},
+	}
+	vals := common.Values{"Values": map[string]any{"tag": "1.0"}}
+
+	e := Engine{Warnings: &RenderWarnings{}, Cache: NewRenderCache(t.TempDir())}
+	out, err := e.Render(c, vals)
+	require.NoError(t, err)
+	assert.Equal(t, "a", out["moby/templates/a"])
+	assert.Equal(t, "b", out["moby/templates/b"])
+	assert.Equal(t, []RenderWarning{
+		{Code: "DEPRECATED_VALUE", Message: "tag is deprecated, use image.tag"},
+		{Code: "NO_RESOURCES", Message: "no resources are set"},
+	}, e.Warnings.Warnings())
+
+	entries, err := os.ReadDir(e.Cache.Dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "renders emitting warnings must not be cached")
+}
+
+func TestRenderFailf(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.0.0"},
+		Templates: []*common.File{
+			{Name: "templates/a", Data: []byte(`{{ failf "UNSUPPORTED_VALUE" "mode %q is not supported" .Values.mode }}`)},
+		},
+	}
+	vals := common.Values{"Values": map[string]any{"mode": "legacy"}}
+
+	_, err := new(Engine).Render(c, vals)
+	require.ErrorContains(t, err, `[UNSUPPORTED_VALUE] mode "legacy" is not supported`)
+
+	_, err = Engine{LintMode: true}.Render(c, vals)
+	require.NoError(t, err, "failf must not fail when linting")
+}
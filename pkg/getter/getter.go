@@ -18,6 +18,7 @@ package getter
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
 	"slices"
@@ -49,8 +50,14 @@ type getterOptions struct {
 	timeout               time.Duration
 	transport             *http.Transport
 	artifactType          string
+	etag                  *string
+	lastModified          *string
 }
 
+// ErrNotModified is returned by getters that support conditional requests when
+// the content has not changed since it was last fetched.
+var ErrNotModified = errors.New("not modified")
+
 // Option allows specifying various settings configurable by the user for overriding the defaults
 // used when performing Get operations with the Getter.
 type Option func(*getterOptions)
@@ -145,13 +152,32 @@ func WithTransport(transport *http.Transport) Option {
 	}
 }
 
-// WithArtifactType sets the type of OCI artifact ("chart" or "plugin")
+// WithArtifactType sets the type of OCI artifact ("chart", "plugin" or "values")
 func WithArtifactType(artifactType string) Option {
 	return func(opts *getterOptions) {
 		opts.artifactType = artifactType
 	}
 }
 
+// WithETag makes the request conditional on the content having changed since
+// it was fetched with the given entity tag, in which case ErrNotModified is
+// returned. The entity tag of the fetched content is stored back into etag.
+func WithETag(etag *string) Option {
+	return func(opts *getterOptions) {
+		opts.etag = etag
+	}
+}
+
+// WithLastModified makes the request conditional on the content having been
+// modified since the given time, in the format of the Last-Modified header, in
+// which case ErrNotModified is returned. The modification time of the fetched
+// content is stored back into lastModified.
+func WithLastModified(lastModified *string) Option {
+	return func(opts *getterOptions) {
+		opts.lastModified = lastModified
+	}
+}
+
 // Getter is an interface to support GET to the specified URL.
 type Getter interface {
 	// Get file content by url string
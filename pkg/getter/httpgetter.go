@@ -59,6 +59,13 @@ func (g *HTTPGetter) get(href string, opts getterOptions) (*bytes.Buffer, error)
 		req.Header.Set("Accept", opts.acceptHeader)
 	}
 
+	if opts.etag != nil && *opts.etag != "" {
+		req.Header.Set("If-None-Match", *opts.etag)
+	}
+	if opts.lastModified != nil && *opts.lastModified != "" {
+		req.Header.Set("If-Modified-Since", *opts.lastModified)
+	}
+
 	req.Header.Set("User-Agent", version.GetUserAgent())
 	if opts.userAgent != "" {
 		req.Header.Set("User-Agent", opts.userAgent)
@@ -96,9 +103,18 @@ func (g *HTTPGetter) get(href string, opts getterOptions) (*bytes.Buffer, error)
 	}
 	defer resp.Body.Close()
 	slog.Debug("fetch complete", "url", href, "status", resp.Status, "content-length", resp.ContentLength)
+	if resp.StatusCode == http.StatusNotModified && (req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "") {
+		return nil, ErrNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("failed to fetch %s : %s", href, resp.Status)
 	}
+	if opts.etag != nil {
+		*opts.etag = resp.Header.Get("ETag")
+	}
+	if opts.lastModified != nil {
+		*opts.lastModified = resp.Header.Get("Last-Modified")
+	}
 
 	buf := bytes.NewBuffer(nil)
 	_, err = io.Copy(buf, resp.Body)
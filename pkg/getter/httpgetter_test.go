@@ -16,6 +16,7 @@ limitations under the License.
 package getter
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -548,6 +549,72 @@ func TestHTTPGetterTarDownload(t *testing.T) {
 	}
 }
 
+func TestHTTPGetterETag(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, "content")
+	}))
+	defer srv.Close()
+
+	g, err := NewHTTPGetter(WithURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var etag string
+	data, err := g.Get(srv.URL, WithETag(&etag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.String() != "content" {
+		t.Errorf("Expected content, got %q", data.String())
+	}
+	if etag != `"v1"` {
+		t.Errorf("Expected the entity tag to be stored, got %q", etag)
+	}
+
+	if _, err := g.Get(srv.URL, WithETag(&etag)); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Expected ErrNotModified, got %v", err)
+	}
+
+	etag = `"v0"`
+	if _, err := g.Get(srv.URL, WithETag(&etag)); err != nil {
+		t.Fatal(err)
+	}
+	if etag != `"v1"` {
+		t.Errorf("Expected the entity tag to be updated, got %q", etag)
+	}
+}
+
+func TestHTTPGetterLastModified(t *testing.T) {
+	modTime := time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "index.yaml", modTime, strings.NewReader("content"))
+	}))
+	defer srv.Close()
+
+	g, err := NewHTTPGetter(WithURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lastModified string
+	if _, err := g.Get(srv.URL, WithLastModified(&lastModified)); err != nil {
+		t.Fatal(err)
+	}
+	if lastModified != modTime.Format(http.TimeFormat) {
+		t.Errorf("Expected the modification time to be stored, got %q", lastModified)
+	}
+
+	if _, err := g.Get(srv.URL, WithLastModified(&lastModified)); !errors.Is(err, ErrNotModified) {
+		t.Errorf("Expected ErrNotModified, got %v", err)
+	}
+}
+
 func TestHttpClientInsecureSkipVerify(t *testing.T) {
 	g := HTTPGetter{}
 	g.opts.url = "https://localhost"
@@ -29,6 +29,7 @@ import (
 	"helm.sh/helm/v4/internal/tlsutil"
 	"helm.sh/helm/v4/internal/urlutil"
 	"helm.sh/helm/v4/pkg/registry"
+	"helm.sh/helm/v4/pkg/signing"
 )
 
 // OCIGetter is the default HTTP(/S) backend handler
@@ -67,6 +68,25 @@ func (g *OCIGetter) get(href string) (*bytes.Buffer, error) {
 	if g.opts.artifactType == "plugin" {
 		return g.getPlugin(client, ref)
 	}
+	if g.opts.artifactType == "values" {
+		result, err := client.PullValues(ref)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewBuffer(result.Data), nil
+	}
+
+	// Keyless signatures are attached to the chart as referrers
+	if chartRef, ok := strings.CutSuffix(ref, signing.BundleExt); ok {
+		referrers, err := client.Referrers(chartRef, signing.BundleMediaType)
+		if err != nil {
+			return nil, err
+		}
+		if len(referrers) == 0 {
+			return nil, fmt.Errorf("no Sigstore bundle is attached to %s", chartRef)
+		}
+		return bytes.NewBuffer(referrers[0].Data), nil
+	}
 
 	// Default to chart behavior for backward compatibility
 	var pullOpts []registry.PullOption
@@ -75,7 +95,8 @@ func (g *OCIGetter) get(href string) (*bytes.Buffer, error) {
 		ref = strings.TrimSuffix(ref, ".prov")
 		pullOpts = append(pullOpts,
 			registry.PullOptWithChart(false),
-			registry.PullOptWithProv(true))
+			registry.PullOptWithProv(true),
+			registry.PullOptWithReferrers(true))
 	}
 
 	result, err := client.Pull(ref, pullOpts...)
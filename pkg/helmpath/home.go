@@ -42,3 +42,21 @@ func CacheChartsFile(name string) string {
 	}
 	return name + "charts.txt"
 }
+
+// CacheIndexV2Dir returns the path to the directory holding the sharded index
+// of the given named repository.
+func CacheIndexV2Dir(name string) string {
+	if name != "" {
+		name += "-"
+	}
+	return name + "index-v2"
+}
+
+// CacheIndexHeadersFile returns the path to the caching headers of the index
+// of the given named repository.
+func CacheIndexHeadersFile(name string) string {
+	if name != "" {
+		name += "-"
+	}
+	return name + "index-headers.json"
+}
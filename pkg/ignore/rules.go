@@ -50,6 +50,16 @@ func (r *Rules) AddDefaults() {
 	r.parseRule(`templates/.?*`)
 }
 
+// AddRules adds rules, in the helmignore syntax, after the existing rules.
+func (r *Rules) AddRules(rules ...string) error {
+	for _, rule := range rules {
+		if err := r.parseRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ParseFile parses a helmignore file and returns the *Rules.
 func ParseFile(file string) (*Rules, error) {
 	f, err := os.Open(file)
@@ -129,6 +129,14 @@ func TestAddDefaults(t *testing.T) {
 	assert.Len(t, r.patterns, 1)
 }
 
+func TestAddRules(t *testing.T) {
+	r := Empty()
+	require.NoError(t, r.AddRules("*.md", "tests/", ""))
+	assert.Len(t, r.patterns, 2)
+
+	require.Error(t, r.AddRules("docs/**"))
+}
+
 func parseString(str string) (*Rules, error) {
 	b := bytes.NewBuffer([]byte(str))
 	return Parse(b)
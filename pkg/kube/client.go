@@ -46,6 +46,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
 	"k8s.io/apimachinery/pkg/util/mergepatch"
@@ -166,6 +167,10 @@ func (c *Client) newStatusWatcher(opts ...WaitOption) (*statusWaiter, error) {
 	if waitContext == nil {
 		waitContext = c.WaitContext
 	}
+	readers, err := withReadinessExpressions(restMapper, o.statusReaders, o.readinessExpressions)
+	if err != nil {
+		return nil, err
+	}
 	sw := &statusWaiter{
 		restMapper:         restMapper,
 		client:             dynamicClient,
@@ -174,7 +179,7 @@ func (c *Client) newStatusWatcher(opts ...WaitOption) (*statusWaiter, error) {
 		waitCtx:            o.waitCtx,
 		waitWithJobsCtx:    o.waitWithJobsCtx,
 		waitForDeleteCtx:   o.waitForDeleteCtx,
-		readers:            o.statusReaders,
+		readers:            readers,
 	}
 	sw.SetLogger(c.Logger().Handler())
 	return sw, nil
@@ -268,6 +273,7 @@ type clientCreateOptions struct {
 	forceConflicts           bool
 	dryRun                   bool
 	fieldValidationDirective FieldValidationDirective
+	parallelism              int
 }
 
 type ClientCreateOption func(*clientCreateOptions) error
@@ -299,6 +305,20 @@ func ClientCreateOptionDryRun(dryRun bool) ClientCreateOption {
 	}
 }
 
+// ClientCreateOptionParallelism limits the number of resources created
+// concurrently. Resources are created in waves of consecutive resources of the
+// same kind, so the install order of kinds is respected. Zero means no limit.
+func ClientCreateOptionParallelism(parallelism int) ClientCreateOption {
+	return func(o *clientCreateOptions) error {
+		if parallelism < 0 {
+			return fmt.Errorf("invalid parallelism %d: must not be negative", parallelism)
+		}
+		o.parallelism = parallelism
+
+		return nil
+	}
+}
+
 // ClientCreateOptionFieldValidationDirective specifies how API operations validate object's schema
 //   - For client-side apply: this is ignored
 //   - For server-side apply: the directive is sent to the server to perform the validation
@@ -364,7 +384,7 @@ func (c *Client) Create(resources ResourceList, options ...ClientCreateOption) (
 		createOptions.forceConflicts,
 		createOptions.dryRun,
 		createOptions.fieldValidationDirective)
-	if err := perform(resources, createApplyFunc); err != nil {
+	if err := performWithParallelism(resources, createApplyFunc, createOptions.parallelism); err != nil {
 		return nil, err
 	}
 	return &Result{Created: resources}, nil
@@ -505,6 +525,156 @@ func getResource(info *resource.Info) (runtime.Object, error) {
 	return obj, nil
 }
 
+// Snapshot returns the live state of the fields Helm manages in the given
+// resources, ready to be passed to Update as targets to restore it. Fields
+// managed only by other field managers, such as the replicas of a Deployment
+// scaled by a HorizontalPodAutoscaler, are left out so that restoring the
+// snapshot does not take them over. Resources that do not exist are omitted.
+func (c *Client) Snapshot(resources ResourceList) (ResourceList, error) {
+	var snapshot ResourceList
+	for _, info := range resources {
+		obj, err := getResource(info)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("unable to snapshot %s %q: %w", info.Mapping.GroupVersionKind.Kind, info.Name, err)
+		}
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		live, err := managedFieldsOf(&unstructured.Unstructured{Object: u}, getManagedFieldsManager())
+		if err != nil {
+			return nil, fmt.Errorf("unable to snapshot %s %q: %w", info.Mapping.GroupVersionKind.Kind, info.Name, err)
+		}
+
+		restored := *info
+		restored.Object = live
+		snapshot = append(snapshot, &restored)
+	}
+	return snapshot, nil
+}
+
+// PatchMetadata merges the given labels and annotations into the metadata of
+// the live resources with a merge patch, leaving the rest of the resources
+// unchanged.
+func (c *Client) PatchMetadata(resources ResourceList, labels, annotations map[string]string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"labels":      labels,
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		obj, err := resource.NewHelper(info.Client, info.Mapping).
+			WithFieldManager(getManagedFieldsManager()).
+			Patch(info.Namespace, info.Name, types.MergePatchType, patch, nil)
+		if err != nil {
+			return fmt.Errorf("unable to patch metadata of %s %q: %w", info.Mapping.GroupVersionKind.Kind, info.Name, err)
+		}
+		return info.Refresh(obj, true)
+	})
+}
+
+// managedFieldsOf returns the fields of obj managed by the given field manager,
+// along with the identity of obj. Lists are atomic: a list with any field
+// managed by the manager is returned whole.
+func managedFieldsOf(obj *unstructured.Unstructured, manager string) (*unstructured.Unstructured, error) {
+	fields := map[string]any{}
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager != manager || entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		var entryFields map[string]any
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &entryFields); err != nil {
+			return nil, fmt.Errorf("unable to parse managed fields: %w", err)
+		}
+		mergeFields(fields, entryFields)
+	}
+
+	managed := &unstructured.Unstructured{Object: selectFields(obj.Object, fields)}
+	managed.SetAPIVersion(obj.GetAPIVersion())
+	managed.SetKind(obj.GetKind())
+	managed.SetName(obj.GetName())
+	managed.SetNamespace(obj.GetNamespace())
+	return managed, nil
+}
+
+// mergeFields adds the managed fields of src to dst. Both are in the FieldsV1
+// format of managed fields entries.
+func mergeFields(dst, src map[string]any) {
+	for k, v := range src {
+		child, isMap := v.(map[string]any)
+		if existing, ok := dst[k].(map[string]any); ok && isMap {
+			mergeFields(existing, child)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// selectFields returns the fields of object that are part of the managed
+// fields, given in the FieldsV1 format of managed fields entries.
+func selectFields(object, fields map[string]any) map[string]any {
+	selected := make(map[string]any)
+	for k, v := range object {
+		f, ok := fields["f:"+k]
+		if !ok {
+			continue
+		}
+		children, _ := f.(map[string]any)
+		if nested, isMap := v.(map[string]any); isMap && len(children) > 0 {
+			selected[k] = selectFields(nested, children)
+			continue
+		}
+		selected[k] = v
+	}
+	return selected
+}
+
+// WatchStatus watches the given resources and calls fn with the status of a
+// resource every time it changes, until ctx is done. The status is computed
+// the same way as when waiting for resources with the watcher strategy.
+func (c *Client) WatchStatus(ctx context.Context, resources ResourceList, fn func(ResourceStatus)) error {
+	sw, err := c.newStatusWatcher()
+	if err != nil {
+		return err
+	}
+	return sw.watchStatus(ctx, resources, fn)
+}
+
+// ListResources returns the resources of the given kinds in namespace matching
+// the label selector. Resources of cluster scoped kinds are listed regardless
+// of the namespace. Kinds unknown to the cluster are skipped.
+func (c *Client) ListResources(gvks []schema.GroupVersionKind, namespace, selector string) (ResourceList, error) {
+	var resources ResourceList
+	for _, gvk := range gvks {
+		infos, err := c.Factory.NewBuilder().
+			Unstructured().
+			NamespaceParam(namespace).DefaultNamespace().
+			ResourceTypeOrNameArgs(true, fmt.Sprintf("%s.%s.%s", gvk.Kind, gvk.Version, gvk.Group)).
+			LabelSelectorParam(selector).
+			Flatten().
+			Do().Infos()
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				c.Logger().Debug("skipping kind unknown to the cluster", "kind", gvk.String())
+				continue
+			}
+			return nil, fmt.Errorf("unable to list %s: %w", gvk.Kind, err)
+		}
+		resources = append(resources, infos...)
+	}
+	return resources, nil
+}
+
 func (c *Client) namespace() string {
 	if c.Namespace != "" {
 		return c.Namespace
@@ -903,12 +1073,29 @@ func (c *Client) Update(originals, targets ResourceList, options ...ClientUpdate
 // if one or more fail and collect any errors. All successfully deleted items
 // will be returned in the `Deleted` ResourceList that is part of the result.
 func (c *Client) Delete(resources ResourceList, policy metav1.DeletionPropagation) (*Result, []error) {
+	return c.DeleteWithOptions(resources, ClientDeleteOptionPropagationPolicy(policy))
+}
+
+// DeleteWithOptions deletes Kubernetes resources like Delete, with the
+// deletion propagation policy and the cleanup of the resources chosen by the
+// options.
+func (c *Client) DeleteWithOptions(resources ResourceList, options ...ClientDeleteOption) (*Result, []error) {
+	opts := clientDeleteOptions{policy: metav1.DeletePropagationBackground}
+	for _, o := range options {
+		if err := o(&opts); err != nil {
+			return nil, []error{err}
+		}
+	}
+
 	var errs []error
 	res := &Result{}
 	mtx := sync.Mutex{}
 	err := perform(resources, func(target *resource.Info) error {
 		c.Logger().Debug("starting delete resource", "namespace", target.Namespace, "name", target.Name, "kind", target.Mapping.GroupVersionKind.Kind)
-		err := deleteResource(target, policy)
+		err := deleteResource(target, opts.policyFor(target.Mapping.GroupVersionKind.Kind))
+		if err == nil && opts.deletesVolumeClaims(target) {
+			err = c.deleteVolumeClaims(target)
+		}
 		if err == nil || apierrors.IsNotFound(err) {
 			if err != nil {
 				c.Logger().Debug(
@@ -972,6 +1159,12 @@ func getManagedFieldsManager() string {
 }
 
 func perform(infos ResourceList, fn func(*resource.Info) error) error {
+	return performWithParallelism(infos, fn, 0)
+}
+
+// performWithParallelism calls fn for each resource, running at most
+// parallelism calls at a time, or all calls of a wave at once if it is zero.
+func performWithParallelism(infos ResourceList, fn func(*resource.Info) error, parallelism int) error {
 	var result error
 
 	if len(infos) == 0 {
@@ -979,7 +1172,7 @@ func perform(infos ResourceList, fn func(*resource.Info) error) error {
 	}
 
 	errs := make(chan error)
-	go batchPerform(infos, fn, errs)
+	go batchPerform(infos, fn, parallelism, errs)
 
 	for range infos {
 		err := <-errs
@@ -991,11 +1184,16 @@ func perform(infos ResourceList, fn func(*resource.Info) error) error {
 	return result
 }
 
-func batchPerform(infos ResourceList, fn func(*resource.Info) error, errs chan<- error) {
+func batchPerform(infos ResourceList, fn func(*resource.Info) error, parallelism int, errs chan<- error) {
 	var kind string
 	var wg sync.WaitGroup
 	defer wg.Wait()
 
+	var slots chan struct{}
+	if parallelism > 0 {
+		slots = make(chan struct{}, parallelism)
+	}
+
 	for _, info := range infos {
 		currentKind := info.Object.GetObjectKind().GroupVersionKind().Kind
 		if kind != currentKind {
@@ -1003,9 +1201,16 @@ func batchPerform(infos ResourceList, fn func(*resource.Info) error, errs chan<-
 			kind = currentKind
 		}
 
+		if slots != nil {
+			slots <- struct{}{}
+		}
 		wg.Add(1)
 		go func(info *resource.Info) {
-			errs <- fn(info)
+			err := fn(info)
+			if slots != nil {
+				<-slots
+			}
+			errs <- err
 			wg.Done()
 		}(info)
 	}
@@ -702,6 +702,71 @@ func TestPerform(t *testing.T) {
 	}
 }
 
+func TestPerformWithParallelism(t *testing.T) {
+	info := func(kind, name string) *resource.Info {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind(kind)
+		obj.SetName(name)
+		return &resource.Info{Name: name, Object: obj}
+	}
+	var infos ResourceList
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		infos = append(infos, info("ConfigMap", name))
+	}
+	infos = append(infos, info("Secret", "g"), info("Secret", "h"))
+
+	tests := []struct {
+		name        string
+		parallelism int
+		maxRunning  int
+	}{
+		{name: "limited", parallelism: 2, maxRunning: 2},
+		{name: "sequential", parallelism: 1, maxRunning: 1},
+		{name: "unlimited waves", parallelism: 0, maxRunning: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var running, maxRunning, configMapsDone int
+			var order []string
+
+			fn := func(info *resource.Info) error {
+				mu.Lock()
+				running++
+				maxRunning = max(maxRunning, running)
+				kind := info.Object.GetObjectKind().GroupVersionKind().Kind
+				if kind == "Secret" && configMapsDone != 6 {
+					t.Errorf("secret %s created before all config maps", info.Name)
+				}
+				mu.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mu.Lock()
+				running--
+				if kind == "ConfigMap" {
+					configMapsDone++
+				}
+				order = append(order, info.Name)
+				mu.Unlock()
+				return nil
+			}
+
+			require.NoError(t, performWithParallelism(infos, fn, tt.parallelism))
+			assert.Len(t, order, len(infos))
+			assert.LessOrEqual(t, maxRunning, tt.maxRunning)
+		})
+	}
+}
+
+func TestClientCreateOptionParallelism(t *testing.T) {
+	opts := clientCreateOptions{}
+	require.NoError(t, ClientCreateOptionParallelism(4)(&opts))
+	assert.Equal(t, 4, opts.parallelism)
+	assert.Error(t, ClientCreateOptionParallelism(-1)(&opts))
+}
+
 func TestWait(t *testing.T) {
 	podList := newPodList("starfish", "otter", "squid")
 
@@ -2385,3 +2450,104 @@ func createManifest(t *testing.T, manifest string,
 	err = fakeClient.Tracker().Create(mapping.Resource, obj, obj.GetNamespace())
 	require.NoError(t, err)
 }
+
+func TestManagedFieldsOf(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name":            "web",
+			"namespace":       "default",
+			"resourceVersion": "42",
+			"labels":          map[string]any{"app": "web", "injected": "true"},
+		},
+		"spec": map[string]any{
+			"replicas": int64(5),
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{map[string]any{"name": "web", "image": "web:1"}},
+				},
+			},
+		},
+		"status": map[string]any{"replicas": int64(5)},
+	}}
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{
+			Manager:    "helm",
+			Operation:  metav1.ManagedFieldsOperationApply,
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:app":{}}}}`)},
+		},
+		{
+			Manager:    "helm",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"web\"}":{"f:image":{}}}}}}}`)},
+		},
+		{
+			Manager:    "kube-controller-manager",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}},"f:metadata":{"f:labels":{"f:injected":{}}}}`)},
+		},
+		{
+			Manager:     "helm",
+			Operation:   metav1.ManagedFieldsOperationUpdate,
+			FieldsType:  "FieldsV1",
+			FieldsV1:    &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:replicas":{}}}`)},
+			Subresource: "status",
+		},
+	})
+
+	managed, err := managedFieldsOf(obj, "helm")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name":      "web",
+			"namespace": "default",
+			"labels":    map[string]any{"app": "web"},
+		},
+		"spec": map[string]any{
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{map[string]any{"name": "web", "image": "web:1"}},
+				},
+			},
+		},
+	}, managed.Object)
+}
+
+func TestPatchMetadata(t *testing.T) {
+	podList := newPodList("starfish", "otter")
+
+	var patched []string
+	c := newTestClient(t)
+	c.Factory.(*cmdtesting.TestFactory).Client = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			p, m := req.URL.Path, req.Method
+			if m != http.MethodPatch {
+				t.Fatalf("unexpected request: %s %s", m, p)
+			}
+			assert.Equal(t, string(types.MergePatchType), req.Header.Get("Content-Type"))
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"metadata":{"labels":{"app.kubernetes.io/managed-by":"Helm"},"annotations":{"meta.helm.sh/release-name":"sea"}}}`, string(body))
+			patched = append(patched, p)
+			for i := range podList.Items {
+				if strings.HasSuffix(p, "/pods/"+podList.Items[i].Name) {
+					return newResponse(http.StatusOK, &podList.Items[i])
+				}
+			}
+			return newResponse(http.StatusNotFound, notFoundBody())
+		}),
+	}
+	resources, err := c.Build(objBody(&podList), false)
+	require.NoError(t, err)
+
+	err = c.PatchMetadata(resources, map[string]string{"app.kubernetes.io/managed-by": "Helm"}, map[string]string{"meta.helm.sh/release-name": "sea"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/namespaces/default/pods/starfish", "/namespaces/default/pods/otter"}, patched)
+}
@@ -0,0 +1,190 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+type clientDeleteOptions struct {
+	policy       metav1.DeletionPropagation
+	kindPolicies map[string]metav1.DeletionPropagation
+	volumeClaims bool
+}
+
+// policyFor returns the deletion propagation policy of the resources of kind.
+func (o clientDeleteOptions) policyFor(kind string) metav1.DeletionPropagation {
+	if policy, ok := o.kindPolicies[strings.ToLower(kind)]; ok {
+		return policy
+	}
+	return o.policy
+}
+
+// ClientDeleteOption configures how Client.DeleteWithOptions deletes resources.
+type ClientDeleteOption func(*clientDeleteOptions) error
+
+// ClientDeleteOptionPropagationPolicy sets the deletion propagation policy
+// of the dependents of the deleted resources. Defaults to background.
+func ClientDeleteOptionPropagationPolicy(policy metav1.DeletionPropagation) ClientDeleteOption {
+	return func(o *clientDeleteOptions) error {
+		if err := validatePropagationPolicy(policy); err != nil {
+			return err
+		}
+		o.policy = policy
+
+		return nil
+	}
+}
+
+// ClientDeleteOptionKindPropagationPolicy sets the deletion propagation policy
+// of the resources of the given kind, overriding the policy set with
+// ClientDeleteOptionPropagationPolicy. Kinds are matched case-insensitively.
+func ClientDeleteOptionKindPropagationPolicy(kind string, policy metav1.DeletionPropagation) ClientDeleteOption {
+	return func(o *clientDeleteOptions) error {
+		if kind == "" {
+			return fmt.Errorf("invalid deletion propagation policy %q: the kind is empty", policy)
+		}
+		if err := validatePropagationPolicy(policy); err != nil {
+			return err
+		}
+		if o.kindPolicies == nil {
+			o.kindPolicies = map[string]metav1.DeletionPropagation{}
+		}
+		o.kindPolicies[strings.ToLower(kind)] = policy
+
+		return nil
+	}
+}
+
+// ClientDeleteOptionVolumeClaims deletes the PersistentVolumeClaims created
+// from the volume claim templates of the deleted StatefulSets. Kubernetes
+// keeps them by default, so that the data outlives the StatefulSet.
+// StatefulSets deleted with the orphan policy keep their claims, as their
+// pods keep using them.
+func ClientDeleteOptionVolumeClaims(deleteVolumeClaims bool) ClientDeleteOption {
+	return func(o *clientDeleteOptions) error {
+		o.volumeClaims = deleteVolumeClaims
+
+		return nil
+	}
+}
+
+// deletesVolumeClaims returns whether the volume claims of a deleted resource
+// have to be deleted.
+func (o clientDeleteOptions) deletesVolumeClaims(info *resource.Info) bool {
+	return o.volumeClaims && isStatefulSet(info) &&
+		o.policyFor(info.Mapping.GroupVersionKind.Kind) != metav1.DeletePropagationOrphan
+}
+
+func validatePropagationPolicy(policy metav1.DeletionPropagation) error {
+	switch policy {
+	case metav1.DeletePropagationBackground, metav1.DeletePropagationForeground, metav1.DeletePropagationOrphan:
+		return nil
+	}
+	return fmt.Errorf("invalid deletion propagation policy %q: must be %q, %q or %q", policy,
+		metav1.DeletePropagationBackground, metav1.DeletePropagationForeground, metav1.DeletePropagationOrphan)
+}
+
+func isStatefulSet(info *resource.Info) bool {
+	gvk := info.Mapping.GroupVersionKind
+	return gvk.Group == "apps" && gvk.Kind == "StatefulSet"
+}
+
+// deleteVolumeClaims deletes the PersistentVolumeClaims of a deleted
+// StatefulSet.
+func (c *Client) deleteVolumeClaims(info *resource.Info) error {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+	if err != nil {
+		return err
+	}
+	var sts appsv1.StatefulSet
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u, &sts); err != nil {
+		return err
+	}
+	if sts.Namespace == "" {
+		sts.Namespace = info.Namespace
+	}
+	kc, err := c.Factory.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	deleted, err := deleteVolumeClaims(context.Background(), kc, &sts)
+	for _, name := range deleted {
+		c.Logger().Debug("deleted volume claim of StatefulSet", "namespace", sts.Namespace, "name", name, "statefulset", sts.Name)
+	}
+	return err
+}
+
+// deleteVolumeClaims deletes the PersistentVolumeClaims created from the
+// volume claim templates of a StatefulSet, which are named
+// <template>-<statefulset>-<ordinal>, and returns the names of the deleted
+// claims.
+func deleteVolumeClaims(ctx context.Context, kc kubernetes.Interface, sts *appsv1.StatefulSet) ([]string, error) {
+	if len(sts.Spec.VolumeClaimTemplates) == 0 {
+		return nil, nil
+	}
+	listOpts := metav1.ListOptions{}
+	if sts.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		listOpts.LabelSelector = selector.String()
+	}
+	claims, err := kc.CoreV1().PersistentVolumeClaims(sts.Namespace).List(ctx, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list volume claims of StatefulSet %q: %w", sts.Name, err)
+	}
+
+	var deleted []string
+	for _, claim := range claims.Items {
+		if !isVolumeClaimOf(claim.Name, sts) {
+			continue
+		}
+		err := kc.CoreV1().PersistentVolumeClaims(sts.Namespace).Delete(ctx, claim.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return deleted, fmt.Errorf("unable to delete volume claim %q of StatefulSet %q: %w", claim.Name, sts.Name, err)
+		}
+		deleted = append(deleted, claim.Name)
+	}
+	return deleted, nil
+}
+
+// isVolumeClaimOf returns whether a PersistentVolumeClaim was created from a
+// volume claim template of the StatefulSet.
+func isVolumeClaimOf(name string, sts *appsv1.StatefulSet) bool {
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		ordinal, ok := strings.CutPrefix(name, template.Name+"-"+sts.Name+"-")
+		if !ok {
+			continue
+		}
+		if _, err := strconv.Atoi(ordinal); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestClientDeleteOptions(t *testing.T) {
+	opts := clientDeleteOptions{policy: metav1.DeletePropagationBackground}
+	for _, o := range []ClientDeleteOption{
+		ClientDeleteOptionPropagationPolicy(metav1.DeletePropagationForeground),
+		ClientDeleteOptionKindPropagationPolicy("StatefulSet", metav1.DeletePropagationOrphan),
+	} {
+		require.NoError(t, o(&opts))
+	}
+
+	assert.Equal(t, metav1.DeletePropagationForeground, opts.policyFor("Deployment"))
+	assert.Equal(t, metav1.DeletePropagationOrphan, opts.policyFor("StatefulSet"))
+	assert.Equal(t, metav1.DeletePropagationOrphan, opts.policyFor("statefulset"))
+
+	assert.ErrorContains(t, ClientDeleteOptionPropagationPolicy("never")(&opts), `invalid deletion propagation policy "never"`)
+	assert.ErrorContains(t, ClientDeleteOptionKindPropagationPolicy("", metav1.DeletePropagationOrphan)(&opts), "the kind is empty")
+	assert.ErrorContains(t, ClientDeleteOptionKindPropagationPolicy("Job", "never")(&opts), `invalid deletion propagation policy "never"`)
+}
+
+func newVolumeClaim(name string, labels map[string]string) runtime.Object {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+	}
+}
+
+func TestDeleteVolumeClaims(t *testing.T) {
+	labels := map[string]string{"app": "db"}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			VolumeClaimTemplates: []v1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			},
+		},
+	}
+	kc := k8sfake.NewClientset(
+		newVolumeClaim("data-db-0", labels),
+		newVolumeClaim("data-db-1", labels),
+		// Claims of another StatefulSet sharing the labels.
+		newVolumeClaim("data-db-backup-0", labels),
+		// Claims not created from a template.
+		newVolumeClaim("data-db-extra", labels),
+		newVolumeClaim("data-db-2", map[string]string{"app": "other"}),
+	)
+
+	deleted, err := deleteVolumeClaims(context.Background(), kc, sts)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"data-db-0", "data-db-1"}, deleted)
+
+	claims, err := kc.CoreV1().PersistentVolumeClaims("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	var names []string
+	for _, claim := range claims.Items {
+		names = append(names, claim.Name)
+	}
+	assert.ElementsMatch(t, []string{"data-db-backup-0", "data-db-extra", "data-db-2"}, names)
+}
+
+func TestDeleteVolumeClaimsWithoutTemplates(t *testing.T) {
+	kc := k8sfake.NewClientset(newVolumeClaim("data-db-0", nil))
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}}
+
+	deleted, err := deleteVolumeClaims(context.Background(), kc, sts)
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+}
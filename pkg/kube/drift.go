@@ -0,0 +1,243 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The ways a field of a resource can drift from the manifest.
+const (
+	// FieldAdded means the field is not in the manifest and was set by
+	// another field manager.
+	FieldAdded = "Added"
+	// FieldRemoved means the field is in the manifest but not in the cluster.
+	FieldRemoved = "Removed"
+	// FieldModified means the field has another value in the cluster.
+	FieldModified = "Modified"
+)
+
+// driftIgnoredManagers are the field managers of the Kubernetes components,
+// which set fields on their own, such as the revision annotation of a
+// Deployment. Fields they add are not reported as drift.
+var driftIgnoredManagers = []string{
+	"kube-controller-manager",
+	"kube-scheduler",
+	"kubelet",
+}
+
+// FieldDrift is a field of a resource that drifted from the manifest.
+type FieldDrift struct {
+	// Path is the path of the field, e.g. spec.template.spec.containers[0].image.
+	Path string `json:"path"`
+	// Type is how the field drifted: Added, Removed or Modified.
+	Type string `json:"type"`
+	// Desired is the value of the field in the manifest.
+	Desired any `json:"desired,omitempty"`
+	// Live is the value of the field in the cluster.
+	Live any `json:"live,omitempty"`
+	// Manager is the field manager that added the field.
+	Manager string `json:"manager,omitempty"`
+}
+
+// ResourceDrift is the drift of a resource from the manifest.
+type ResourceDrift struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Missing means the resource does not exist in the cluster.
+	Missing bool         `json:"missing,omitempty"`
+	Fields  []FieldDrift `json:"fields,omitempty"`
+}
+
+// Drifted reports whether the resource drifted from the manifest.
+func (d ResourceDrift) Drifted() bool {
+	return d.Missing || len(d.Fields) > 0
+}
+
+// Drift compares the given resources, as rendered in a manifest, with their
+// live state. Fields set by the API server, such as defaults and the status,
+// are not reported: only the fields of the manifest are compared, along with
+// the fields other field managers added, according to the managed fields of
+// the resources.
+func (c *Client) Drift(resources ResourceList) ([]ResourceDrift, error) {
+	manager := getManagedFieldsManager()
+	drift := make([]ResourceDrift, 0, len(resources))
+	for _, info := range resources {
+		rd := ResourceDrift{
+			Kind:      info.Mapping.GroupVersionKind.Kind,
+			Namespace: info.Namespace,
+			Name:      info.Name,
+		}
+		obj, err := getResource(info)
+		if apierrors.IsNotFound(err) {
+			rd.Missing = true
+			drift = append(drift, rd)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to get %s %q: %w", rd.Kind, rd.Name, err)
+		}
+		live, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		desired, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+		if err != nil {
+			return nil, err
+		}
+		rd.Fields, err = driftFields(desired, &unstructured.Unstructured{Object: live}, manager)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compare %s %q: %w", rd.Kind, rd.Name, err)
+		}
+		drift = append(drift, rd)
+	}
+	return drift, nil
+}
+
+// driftFields returns the fields of live that drifted from desired. manager
+// is the field manager of Helm, the fields other managers added to live are
+// reported as added.
+func driftFields(desired map[string]any, live *unstructured.Unstructured, manager string) ([]FieldDrift, error) {
+	desired = maps.Clone(desired)
+	delete(desired, "status")
+
+	var fields []FieldDrift
+	diffFields("", desired, live.Object, &fields)
+
+	added := map[string]bool{}
+	for _, entry := range live.GetManagedFields() {
+		if entry.Manager == manager || entry.Subresource != "" || entry.FieldsV1 == nil ||
+			slices.Contains(driftIgnoredManagers, entry.Manager) {
+			continue
+		}
+		var entryFields map[string]any
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &entryFields); err != nil {
+			return nil, fmt.Errorf("unable to parse managed fields: %w", err)
+		}
+		var found []FieldDrift
+		addedFields("", desired, selectFields(live.Object, entryFields), &found)
+		for _, f := range found {
+			if added[f.Path] {
+				continue
+			}
+			added[f.Path] = true
+			f.Manager = entry.Manager
+			fields = append(fields, f)
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].Path < fields[j].Path
+	})
+	return fields, nil
+}
+
+// diffFields adds to fields the fields of desired that are missing from live
+// or have another value. Fields only in live are ignored, they are defaults
+// or were set by other field managers.
+func diffFields(path string, desired, live any, fields *[]FieldDrift) {
+	switch d := desired.(type) {
+	case map[string]any:
+		l, ok := live.(map[string]any)
+		if !ok {
+			*fields = append(*fields, FieldDrift{Path: path, Type: FieldModified, Desired: desired, Live: live})
+			return
+		}
+		for _, k := range sortedKeys(d) {
+			if d[k] == nil {
+				continue
+			}
+			lv, ok := l[k]
+			if !ok {
+				*fields = append(*fields, FieldDrift{Path: joinFieldPath(path, k), Type: FieldRemoved, Desired: d[k]})
+				continue
+			}
+			diffFields(joinFieldPath(path, k), d[k], lv, fields)
+		}
+	case []any:
+		l, ok := live.([]any)
+		if !ok || len(l) != len(d) {
+			*fields = append(*fields, FieldDrift{Path: path, Type: FieldModified, Desired: desired, Live: live})
+			return
+		}
+		for i := range d {
+			diffFields(path+"["+strconv.Itoa(i)+"]", d[i], l[i], fields)
+		}
+	default:
+		if !equalValues(desired, live) {
+			*fields = append(*fields, FieldDrift{Path: path, Type: FieldModified, Desired: desired, Live: live})
+		}
+	}
+}
+
+// addedFields adds to fields the fields of live that are not in desired.
+func addedFields(path string, desired any, live map[string]any, fields *[]FieldDrift) {
+	d, _ := desired.(map[string]any)
+	for _, k := range sortedKeys(live) {
+		dv, ok := d[k]
+		if !ok {
+			*fields = append(*fields, FieldDrift{Path: joinFieldPath(path, k), Type: FieldAdded, Live: live[k]})
+			continue
+		}
+		if nested, isMap := live[k].(map[string]any); isMap {
+			addedFields(joinFieldPath(path, k), dv, nested, fields)
+		}
+	}
+}
+
+// equalValues reports whether two scalar values are equal, regardless of the
+// type numbers were decoded to.
+func equalValues(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	switch a.(type) {
+	case int64, float64:
+		switch b.(type) {
+		case int64, float64:
+			return fmt.Sprint(a) == fmt.Sprint(b)
+		}
+	}
+	return false
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,151 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const driftDesiredManifest = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+    tier: frontend
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.25
+`
+
+const driftLiveManifest = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  uid: 8e2c6c1e-9f1b-4f0e-b3c2-7f3c1a2b4d5e
+  annotations:
+    deployment.kubernetes.io/revision: "2"
+    team: payments
+  labels:
+    app: web
+  managedFields:
+  - manager: helm
+    operation: Update
+    apiVersion: apps/v1
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:metadata:
+        f:labels:
+          f:app: {}
+      f:spec:
+        f:replicas: {}
+        f:template:
+          f:spec:
+            f:containers: {}
+  - manager: kube-controller-manager
+    operation: Update
+    apiVersion: apps/v1
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:metadata:
+        f:annotations:
+          f:deployment.kubernetes.io/revision: {}
+  - manager: kubectl-edit
+    operation: Update
+    apiVersion: apps/v1
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:metadata:
+        f:annotations:
+          f:team: {}
+      f:spec:
+        f:template:
+          f:spec:
+            f:containers: {}
+  - manager: kube-controller-manager
+    operation: Update
+    apiVersion: apps/v1
+    subresource: status
+    fieldsType: FieldsV1
+    fieldsV1:
+      f:status:
+        f:replicas: {}
+spec:
+  replicas: 5
+  progressDeadlineSeconds: 600
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.26
+        imagePullPolicy: IfNotPresent
+status:
+  replicas: 5
+`
+
+func TestDriftFields(t *testing.T) {
+	var desired, live map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(driftDesiredManifest), &desired))
+	require.NoError(t, yaml.Unmarshal([]byte(driftLiveManifest), &live))
+
+	fields, err := driftFields(desired, &unstructured.Unstructured{Object: live}, "helm")
+	require.NoError(t, err)
+	assert.Equal(t, []FieldDrift{{
+		Path:    "metadata.annotations",
+		Type:    FieldAdded,
+		Live:    map[string]any{"team": "payments"},
+		Manager: "kubectl-edit",
+	}, {
+		Path:    "metadata.labels.tier",
+		Type:    FieldRemoved,
+		Desired: "frontend",
+	}, {
+		Path:    "spec.replicas",
+		Type:    FieldModified,
+		Desired: float64(3),
+		Live:    float64(5),
+	}, {
+		Path:    "spec.template.spec.containers[0].image",
+		Type:    FieldModified,
+		Desired: "nginx:1.25",
+		Live:    "nginx:1.26",
+	}}, fields)
+}
+
+func TestDriftFieldsNoDrift(t *testing.T) {
+	var desired, live map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(driftDesiredManifest), &desired))
+	require.NoError(t, yaml.Unmarshal([]byte(driftDesiredManifest), &live))
+	live["status"] = map[string]any{"replicas": int64(3)}
+	live["spec"].(map[string]any)["replicas"] = int64(3)
+
+	fields, err := driftFields(desired, &unstructured.Unstructured{Object: live}, "helm")
+	require.NoError(t, err)
+	assert.Empty(t, fields)
+}
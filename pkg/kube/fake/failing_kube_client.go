@@ -18,12 +18,14 @@ limitations under the License.
 package fake
 
 import (
+	"context"
 	"io"
 	"sync"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
 
 	"helm.sh/helm/v4/pkg/kube"
@@ -38,11 +40,21 @@ type FailingKubeClient struct {
 	GetError               error
 	DeleteError            error
 	UpdateError            error
+	SnapshotError          error
+	ListResourcesError     error
 	BuildError             error
 	BuildTableError        error
 	ConnectionError        error
 	BuildDummy             bool
 	DummyResources         kube.ResourceList
+	ListedResources        kube.ResourceList
+	WatchStatusError       error
+	WatchedStatuses        []kube.ResourceStatus
+	HealthError            error
+	ResourceHealth         []kube.ResourceHealth
+	DriftError             error
+	ResourceDrift          []kube.ResourceDrift
+	PatchMetadataError     error
 	BuildUnstructuredError error
 	WaitError              error
 	WaitForDeleteError     error
@@ -115,6 +127,15 @@ func (f *FailingKubeClient) Delete(resources kube.ResourceList, deletionPropagat
 	return f.PrintingKubeClient.Delete(resources, deletionPropagation)
 }
 
+// DeleteWithOptions returns the configured error if set or prints
+func (f *FailingKubeClient) DeleteWithOptions(resources kube.ResourceList, options ...kube.ClientDeleteOption) (*kube.Result, []error) {
+	if f.DeleteError != nil {
+		return nil, []error{f.DeleteError}
+	}
+
+	return f.PrintingKubeClient.DeleteWithOptions(resources, options...)
+}
+
 // WatchUntilReady returns the configured error if set or prints
 func (f *FailingKubeWaiter) WatchUntilReady(resources kube.ResourceList, d time.Duration) error {
 	if f.watchUntilReadyError != nil {
@@ -131,6 +152,71 @@ func (f *FailingKubeClient) Update(r, modified kube.ResourceList, options ...kub
 	return f.PrintingKubeClient.Update(r, modified, options...)
 }
 
+// Snapshot returns the configured error if set or prints
+func (f *FailingKubeClient) Snapshot(resources kube.ResourceList) (kube.ResourceList, error) {
+	if f.SnapshotError != nil {
+		return nil, f.SnapshotError
+	}
+	return f.PrintingKubeClient.Snapshot(resources)
+}
+
+// ListResources returns the configured error if set, or the configured listed resources
+func (f *FailingKubeClient) ListResources(gvks []schema.GroupVersionKind, namespace, selector string) (kube.ResourceList, error) {
+	if f.ListResourcesError != nil {
+		return nil, f.ListResourcesError
+	}
+	if f.ListedResources != nil {
+		return f.ListedResources, nil
+	}
+	return f.PrintingKubeClient.ListResources(gvks, namespace, selector)
+}
+
+// WatchStatus returns the configured error if set, or reports the configured
+// watched statuses
+func (f *FailingKubeClient) WatchStatus(ctx context.Context, resources kube.ResourceList, fn func(kube.ResourceStatus)) error {
+	if f.WatchStatusError != nil {
+		return f.WatchStatusError
+	}
+	if f.WatchedStatuses != nil {
+		for _, rs := range f.WatchedStatuses {
+			fn(rs)
+		}
+		return nil
+	}
+	return f.PrintingKubeClient.WatchStatus(ctx, resources, fn)
+}
+
+// Health returns the configured error if set, or the configured resource
+// health
+func (f *FailingKubeClient) Health(resources kube.ResourceList) ([]kube.ResourceHealth, error) {
+	if f.HealthError != nil {
+		return nil, f.HealthError
+	}
+	if f.ResourceHealth != nil {
+		return f.ResourceHealth, nil
+	}
+	return f.PrintingKubeClient.Health(resources)
+}
+
+// Drift returns the configured error if set, or the configured resource drift
+func (f *FailingKubeClient) Drift(resources kube.ResourceList) ([]kube.ResourceDrift, error) {
+	if f.DriftError != nil {
+		return nil, f.DriftError
+	}
+	if f.ResourceDrift != nil {
+		return f.ResourceDrift, nil
+	}
+	return f.PrintingKubeClient.Drift(resources)
+}
+
+// PatchMetadata returns the configured error if set or prints
+func (f *FailingKubeClient) PatchMetadata(resources kube.ResourceList, labels, annotations map[string]string) error {
+	if f.PatchMetadataError != nil {
+		return f.PatchMetadataError
+	}
+	return f.PrintingKubeClient.PatchMetadata(resources, labels, annotations)
+}
+
 // Build returns the configured error if set or prints
 func (f *FailingKubeClient) Build(r io.Reader, _ bool) (kube.ResourceList, error) {
 	if f.BuildError != nil {
@@ -17,6 +17,7 @@ limitations under the License.
 package fake
 
 import (
+	"context"
 	"io"
 	"strings"
 	"time"
@@ -24,6 +25,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
 
 	"helm.sh/helm/v4/pkg/kube"
@@ -43,6 +45,13 @@ type PrintingKubeWaiter struct {
 }
 
 var _ kube.Interface = &PrintingKubeClient{}
+var _ kube.InterfaceSnapshot = &PrintingKubeClient{}
+var _ kube.InterfaceListResources = &PrintingKubeClient{}
+var _ kube.InterfaceWatchStatus = &PrintingKubeClient{}
+var _ kube.InterfaceHealth = &PrintingKubeClient{}
+var _ kube.InterfaceDrift = &PrintingKubeClient{}
+var _ kube.InterfacePatchMetadata = &PrintingKubeClient{}
+var _ kube.InterfaceDeleteWithOptions = &PrintingKubeClient{}
 
 // IsReachable checks if the cluster is reachable
 func (p *PrintingKubeClient) IsReachable() error {
@@ -98,6 +107,11 @@ func (p *PrintingKubeClient) Delete(resources kube.ResourceList, _ metav1.Deleti
 	return &kube.Result{Deleted: resources}, nil
 }
 
+// DeleteWithOptions implements KubeClient DeleteWithOptions.
+func (p *PrintingKubeClient) DeleteWithOptions(resources kube.ResourceList, _ ...kube.ClientDeleteOption) (*kube.Result, []error) {
+	return p.Delete(resources, metav1.DeletePropagationBackground)
+}
+
 // Update implements KubeClient Update.
 func (p *PrintingKubeClient) Update(_, modified kube.ResourceList, _ ...kube.ClientUpdateOption) (*kube.Result, error) {
 	_, err := io.Copy(p.Out, bufferize(modified))
@@ -110,6 +124,90 @@ func (p *PrintingKubeClient) Update(_, modified kube.ResourceList, _ ...kube.Cli
 	return &kube.Result{Updated: modified}, nil
 }
 
+// Snapshot implements KubeClient Snapshot.
+//
+// It prints the resources and returns them unchanged.
+func (p *PrintingKubeClient) Snapshot(resources kube.ResourceList) (kube.ResourceList, error) {
+	_, err := io.Copy(p.Out, bufferize(resources))
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// ListResources implements KubeClient ListResources.
+//
+// It returns an empty list, as there are no resources in the cluster.
+func (p *PrintingKubeClient) ListResources(_ []schema.GroupVersionKind, _, _ string) (kube.ResourceList, error) {
+	return []*resource.Info{}, nil
+}
+
+// WatchStatus implements KubeClient WatchStatus.
+//
+// It reports every resource as current and returns.
+func (p *PrintingKubeClient) WatchStatus(_ context.Context, resources kube.ResourceList, fn func(kube.ResourceStatus)) error {
+	for _, info := range resources {
+		var kind string
+		if info.Mapping != nil {
+			kind = info.Mapping.GroupVersionKind.Kind
+		}
+		fn(kube.ResourceStatus{
+			Kind:      kind,
+			Namespace: info.Namespace,
+			Name:      info.Name,
+			Status:    "Current",
+			Message:   "Resource is current",
+		})
+	}
+	return nil
+}
+
+// Health implements KubeClient Health.
+//
+// It reports every resource as ready.
+func (p *PrintingKubeClient) Health(resources kube.ResourceList) ([]kube.ResourceHealth, error) {
+	health := make([]kube.ResourceHealth, 0, len(resources))
+	for _, info := range resources {
+		var kind string
+		if info.Mapping != nil {
+			kind = info.Mapping.GroupVersionKind.Kind
+		}
+		health = append(health, kube.ResourceHealth{
+			Kind:      kind,
+			Namespace: info.Namespace,
+			Name:      info.Name,
+			Health:    kube.HealthReady,
+			Message:   "Resource is current",
+		})
+	}
+	return health, nil
+}
+
+// Drift implements KubeClient Drift.
+//
+// It reports no drift for every resource.
+func (p *PrintingKubeClient) Drift(resources kube.ResourceList) ([]kube.ResourceDrift, error) {
+	drift := make([]kube.ResourceDrift, 0, len(resources))
+	for _, info := range resources {
+		var kind string
+		if info.Mapping != nil {
+			kind = info.Mapping.GroupVersionKind.Kind
+		}
+		drift = append(drift, kube.ResourceDrift{
+			Kind:      kind,
+			Namespace: info.Namespace,
+			Name:      info.Name,
+		})
+	}
+	return drift, nil
+}
+
+// PatchMetadata implements KubeClient PatchMetadata.
+func (p *PrintingKubeClient) PatchMetadata(resources kube.ResourceList, _, _ map[string]string) error {
+	_, err := io.Copy(p.Out, bufferize(resources))
+	return err
+}
+
 // Build implements KubeClient Build.
 func (p *PrintingKubeClient) Build(_ io.Reader, _ bool) (kube.ResourceList, error) {
 	return []*resource.Info{}, nil
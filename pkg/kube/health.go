@@ -0,0 +1,139 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"fmt"
+
+	"github.com/fluxcd/cli-utils/pkg/kstatus/status"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The health of a resource, computed from its live state.
+const (
+	// HealthReady means the resource is fully reconciled.
+	HealthReady = "Ready"
+	// HealthProgressing means the resource is being reconciled, or deleted.
+	HealthProgressing = "Progressing"
+	// HealthDegraded means reconciling the resource failed.
+	HealthDegraded = "Degraded"
+	// HealthMissing means the resource does not exist in the cluster.
+	HealthMissing = "Missing"
+	// HealthUnknown means the health of the resource could not be computed.
+	HealthUnknown = "Unknown"
+)
+
+// ResourceHealth is the health of a resource, along with the conditions
+// reported in its status.
+type ResourceHealth struct {
+	Kind       string              `json:"kind"`
+	Namespace  string              `json:"namespace,omitempty"`
+	Name       string              `json:"name"`
+	Health     string              `json:"health"`
+	Message    string              `json:"message,omitempty"`
+	Conditions []ResourceCondition `json:"conditions,omitempty"`
+}
+
+// ResourceCondition is a condition reported in the status of a resource.
+type ResourceCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Health returns the health of the given resources, computed from their live
+// state the same way as when waiting for resources with the watcher strategy.
+func (c *Client) Health(resources ResourceList) ([]ResourceHealth, error) {
+	health := make([]ResourceHealth, 0, len(resources))
+	for _, info := range resources {
+		rh := ResourceHealth{
+			Kind:      info.Mapping.GroupVersionKind.Kind,
+			Namespace: info.Namespace,
+			Name:      info.Name,
+		}
+		obj, err := getResource(info)
+		if apierrors.IsNotFound(err) {
+			rh.Health = HealthMissing
+			rh.Message = "Resource does not exist"
+			health = append(health, rh)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to get %s %q: %w", rh.Kind, rh.Name, err)
+		}
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		rh.Health, rh.Message, rh.Conditions = computeHealth(&unstructured.Unstructured{Object: u})
+		health = append(health, rh)
+	}
+	return health, nil
+}
+
+// computeHealth returns the health of obj, a message describing it and the
+// conditions reported in the status of obj.
+func computeHealth(obj *unstructured.Unstructured) (string, string, []ResourceCondition) {
+	conditions := statusConditions(obj)
+	result, err := status.Compute(obj)
+	if err != nil {
+		return HealthUnknown, err.Error(), conditions
+	}
+
+	var health string
+	switch result.Status {
+	case status.CurrentStatus:
+		health = HealthReady
+	case status.InProgressStatus, status.TerminatingStatus:
+		health = HealthProgressing
+	case status.FailedStatus:
+		health = HealthDegraded
+	case status.NotFoundStatus:
+		health = HealthMissing
+	default:
+		health = HealthUnknown
+	}
+	return health, result.Message, conditions
+}
+
+// statusConditions returns the conditions found in the status of obj.
+func statusConditions(obj *unstructured.Unstructured) []ResourceCondition {
+	items, found, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil || !found {
+		return nil
+	}
+	var conditions []ResourceCondition
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		c := ResourceCondition{}
+		c.Type, _, _ = unstructured.NestedString(m, "type")
+		c.Status, _, _ = unstructured.NestedString(m, "status")
+		c.Reason, _, _ = unstructured.NestedString(m, "reason")
+		c.Message, _, _ = unstructured.NestedString(m, "message")
+		if c.Type == "" {
+			continue
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions
+}
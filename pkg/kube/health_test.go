@@ -0,0 +1,66 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestComputeHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		manifest   string
+		health     string
+		conditions []ResourceCondition
+	}{{
+		name:       "ready pod",
+		manifest:   podCurrentManifest,
+		health:     HealthReady,
+		conditions: []ResourceCondition{{Type: "Ready", Status: "True"}},
+	}, {
+		name:     "pod without status",
+		manifest: podNoStatusManifest,
+		health:   HealthProgressing,
+	}, {
+		name:     "failed job",
+		manifest: jobFailedManifest,
+		health:   HealthDegraded,
+		conditions: []ResourceCondition{{
+			Type:    "Failed",
+			Status:  "True",
+			Reason:  "BackoffLimitExceeded",
+			Message: "Job has reached the specified backoff limit",
+		}},
+	}, {
+		name:     "deployment not ready",
+		manifest: notReadyDeploymentManifest,
+		health:   HealthProgressing,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := getRuntimeObjFromManifests(t, []string{tt.manifest})
+			health, message, conditions := computeHealth(objs[0].(*unstructured.Unstructured))
+			assert.Equal(t, tt.health, health)
+			assert.NotEmpty(t, message)
+			assert.Equal(t, tt.conditions, conditions)
+		})
+	}
+}
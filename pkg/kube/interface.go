@@ -17,12 +17,14 @@ limitations under the License.
 package kube
 
 import (
+	"context"
 	"io"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // Interface represents a client capable of communicating with the Kubernetes API.
@@ -110,3 +112,89 @@ type InterfaceWaitOptions interface {
 }
 
 var _ InterfaceWaitOptions = (*Client)(nil)
+
+// InterfaceSnapshot defines an interface that extends Interface with a
+// method to capture the live state of resources, so it can be restored later.
+//
+// TODO Helm 5: Remove InterfaceSnapshot and integrate its method(s) into the Interface.
+type InterfaceSnapshot interface {
+	// Snapshot returns the live state of the fields Helm manages in the given
+	// resources, ready to be passed to Update as targets to restore it.
+	// Resources that do not exist are omitted.
+	Snapshot(resources ResourceList) (ResourceList, error)
+}
+
+var _ InterfaceSnapshot = (*Client)(nil)
+
+// InterfaceListResources defines an interface that extends Interface with a
+// method to list the resources of given kinds in the cluster.
+//
+// TODO Helm 5: Remove InterfaceListResources and integrate its method(s) into the Interface.
+type InterfaceListResources interface {
+	// ListResources returns the resources of the given kinds in namespace
+	// matching the label selector. Resources of cluster scoped kinds are listed
+	// regardless of the namespace. Kinds unknown to the cluster are skipped.
+	ListResources(gvks []schema.GroupVersionKind, namespace, selector string) (ResourceList, error)
+}
+
+var _ InterfaceListResources = (*Client)(nil)
+
+// InterfaceWatchStatus defines an interface that extends Interface with a
+// method to stream the status of resources as it changes.
+//
+// TODO Helm 5: Remove InterfaceWatchStatus and integrate its method(s) into the Interface.
+type InterfaceWatchStatus interface {
+	// WatchStatus watches the given resources and calls fn with the status of
+	// a resource every time it changes, until ctx is done.
+	WatchStatus(ctx context.Context, resources ResourceList, fn func(ResourceStatus)) error
+}
+
+var _ InterfaceWatchStatus = (*Client)(nil)
+
+// InterfaceHealth defines an interface that extends Interface with a method
+// to compute the health of resources from their live state.
+//
+// TODO Helm 5: Remove InterfaceHealth and integrate its method(s) into the Interface.
+type InterfaceHealth interface {
+	// Health returns the health of the given resources, in the same order.
+	// Resources missing from the cluster are reported as such.
+	Health(resources ResourceList) ([]ResourceHealth, error)
+}
+
+var _ InterfaceHealth = (*Client)(nil)
+
+// InterfaceDrift defines an interface that extends Interface with a method
+// to compare resources with their live state.
+//
+// TODO Helm 5: Remove InterfaceDrift and integrate its method(s) into the Interface.
+type InterfaceDrift interface {
+	// Drift compares the given resources with their live state and returns
+	// their drift, in the same order.
+	Drift(resources ResourceList) ([]ResourceDrift, error)
+}
+
+var _ InterfaceDrift = (*Client)(nil)
+
+// InterfacePatchMetadata defines an interface that extends Interface with a
+// method to label and annotate live resources.
+//
+// TODO Helm 5: Remove InterfacePatchMetadata and integrate its method(s) into the Interface.
+type InterfacePatchMetadata interface {
+	// PatchMetadata merges the given labels and annotations into the metadata
+	// of the live resources, leaving the rest of them unchanged.
+	PatchMetadata(resources ResourceList, labels, annotations map[string]string) error
+}
+
+var _ InterfacePatchMetadata = (*Client)(nil)
+
+// InterfaceDeleteWithOptions defines an interface that extends Interface with
+// a method to delete resources with per-kind deletion propagation policies.
+//
+// TODO Helm 5: Remove InterfaceDeleteWithOptions and integrate its method(s) into the Interface.
+type InterfaceDeleteWithOptions interface {
+	// DeleteWithOptions deletes the given resources like Delete, configured
+	// by the options.
+	DeleteWithOptions(resources ResourceList, options ...ClientDeleteOption) (*Result, []error)
+}
+
+var _ InterfaceDeleteWithOptions = (*Client)(nil)
@@ -72,6 +72,16 @@ func WithKStatusReaders(readers ...engine.StatusReader) WaitOption {
 	}
 }
 
+// WithReadinessExpressions sets custom readiness conditions for resources of
+// specific kinds, replacing the built-in readiness checks for those kinds. See
+// statusreaders.ReadinessExpression for the syntax. Readiness expressions are
+// only used by the watcher and hookOnly wait strategies.
+func WithReadinessExpressions(expressions ...string) WaitOption {
+	return func(wo *waitOptions) {
+		wo.readinessExpressions = append(wo.readinessExpressions, expressions...)
+	}
+}
+
 type waitOptions struct {
 	ctx                context.Context
 	watchUntilReadyCtx context.Context
@@ -79,4 +89,6 @@ type waitOptions struct {
 	waitWithJobsCtx    context.Context
 	waitForDeleteCtx   context.Context
 	statusReaders      []engine.StatusReader
+
+	readinessExpressions []string
 }
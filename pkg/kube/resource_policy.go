@@ -25,3 +25,9 @@ const ResourcePolicyAnno = "helm.sh/resource-policy"
 //
 //	during an uninstallRelease action.
 const KeepPolicy = "keep"
+
+// ProtectedPolicy is the resource policy type for protected
+//
+// This resource policy type makes an uninstallRelease action refuse to delete
+// the release of the resource, unless the protection is explicitly overridden.
+const ProtectedPolicy = "protected"
@@ -68,6 +68,25 @@ func alwaysReady(_ *unstructured.Unstructured) (*status.Result, error) {
 	}, nil
 }
 
+// withReadinessExpressions puts a status reader for the given readiness
+// expressions in front of the readers, so they take precedence over any other
+// status reader for the kinds they apply to.
+func withReadinessExpressions(mapper meta.RESTMapper, readers []engine.StatusReader, exprs []string) ([]engine.StatusReader, error) {
+	if len(exprs) == 0 {
+		return readers, nil
+	}
+	expressions := make([]*helmStatusReaders.ReadinessExpression, 0, len(exprs))
+	for _, expr := range exprs {
+		e, err := helmStatusReaders.ParseReadinessExpression(expr)
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, e)
+	}
+	sr := helmStatusReaders.NewExpressionStatusReader(mapper, expressions...)
+	return append([]engine.StatusReader{sr}, readers...), nil
+}
+
 func getStatusWatcher(dynamicClient dynamic.Interface, mapper meta.RESTMapper) *watcher.DefaultStatusWatcher {
 	sw := watcher.NewDefaultStatusWatcher(dynamicClient, mapper)
 	sw.ResyncPeriod = 3 * time.Minute
@@ -222,6 +241,61 @@ func (w *statusWaiter) wait(ctx context.Context, resourceList ResourceList, sw w
 	return nil
 }
 
+// ResourceStatus is the status of a resource computed from its live state.
+type ResourceStatus struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Status is the kstatus status of the resource, such as Current,
+	// InProgress, Failed or NotFound.
+	Status string `json:"status"`
+	// Message describes the status, e.g. how many replicas are ready.
+	Message string `json:"message,omitempty"`
+}
+
+// watchStatus calls fn with the status of a resource every time it changes,
+// until ctx is done.
+func (w *statusWaiter) watchStatus(ctx context.Context, resourceList ResourceList, fn func(ResourceStatus)) error {
+	resources := make([]object.ObjMetadata, 0, len(resourceList))
+	for _, resource := range resourceList {
+		obj, err := object.RuntimeToObjMeta(resource.Object)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, obj)
+	}
+
+	sw := getStatusWatcher(w.client, w.restMapper)
+	sw.StatusReader = statusreaders.NewStatusReader(w.restMapper, w.readers...)
+	eventCh := sw.Watch(ctx, resources, watcher.Options{
+		RESTScopeStrategy: watcher.RESTScopeNamespace,
+	})
+
+	// the watcher reports every update of the resources, only the changes of
+	// their status are of interest
+	last := make(map[object.ObjMetadata]ResourceStatus, len(resources))
+	for e := range eventCh {
+		switch e.Type {
+		case event.ErrorEvent:
+			return e.Error
+		case event.ResourceUpdateEvent:
+			rs := ResourceStatus{
+				Kind:      e.Resource.Identifier.GroupKind.Kind,
+				Namespace: e.Resource.Identifier.Namespace,
+				Name:      e.Resource.Identifier.Name,
+				Status:    e.Resource.Status.String(),
+				Message:   e.Resource.Message,
+			}
+			if prev, ok := last[e.Resource.Identifier]; ok && prev == rs {
+				continue
+			}
+			last[e.Resource.Identifier] = rs
+			fn(rs)
+		}
+	}
+	return nil
+}
+
 func (w *statusWaiter) contextWithTimeout(methodCtx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
 	if methodCtx == nil {
 		methodCtx = w.ctx
@@ -450,6 +450,44 @@ func TestStatusWait(t *testing.T) {
 	}
 }
 
+func TestStatusWatchStatus(t *testing.T) {
+	t.Parallel()
+	c := newTestClient(t)
+	fakeClient := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+	fakeMapper := testutil.NewFakeRESTMapper(
+		v1.SchemeGroupVersion.WithKind("Pod"),
+	)
+	statusWaiter := statusWaiter{
+		client:     fakeClient,
+		restMapper: fakeMapper,
+	}
+	statusWaiter.SetLogger(slog.Default().Handler())
+	objs := getRuntimeObjFromManifests(t, []string{podCurrentManifest, podNoStatusManifest})
+	for _, obj := range objs {
+		u := obj.(*unstructured.Unstructured)
+		gvr := getGVR(t, fakeMapper, u)
+		require.NoError(t, fakeClient.Tracker().Create(gvr, u, u.GetNamespace()))
+	}
+	resourceList := getResourceListFromRuntimeObjs(t, c, objs)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 3*time.Second)
+	defer cancel()
+	statuses := map[string]string{}
+	err := statusWaiter.watchStatus(ctx, resourceList, func(rs ResourceStatus) {
+		assert.Equal(t, "Pod", rs.Kind)
+		assert.Equal(t, "ns", rs.Namespace)
+		statuses[rs.Name] = rs.Status
+		if len(statuses) == len(objs) {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"current-pod":     "Current",
+		"in-progress-pod": "InProgress",
+	}, statuses)
+}
+
 func TestWaitForJobComplete(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
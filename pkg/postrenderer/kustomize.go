@@ -0,0 +1,130 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrenderer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// KustomizeName is the name selecting the built-in kustomize post-renderer
+// when no post-renderer plugin with this name is installed.
+const KustomizeName = "kustomize"
+
+// kustomizeRoot is where the kustomization directory is loaded in memory.
+const kustomizeRoot = "/kustomization"
+
+// renderedManifestsFile is the file the rendered manifests are added to the
+// kustomization as.
+const renderedManifestsFile = "helm-rendered-manifests.yaml"
+
+// NewKustomizePostRenderer returns a post-renderer building the kustomization
+// in dir, with the rendered manifests added to its resources. The kustomization
+// must not refer to files outside of dir.
+func NewKustomizePostRenderer(dir string) PostRenderer {
+	return &kustomizePostRenderer{dir: dir}
+}
+
+type kustomizePostRenderer struct {
+	dir string
+}
+
+func (k *kustomizePostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	if k.dir == "" {
+		return nil, errors.New("the kustomize post-renderer requires the kustomization directory as argument")
+	}
+
+	fSys := filesys.MakeFsInMemory()
+	if err := copyToFs(k.dir, fSys); err != nil {
+		return nil, fmt.Errorf("unable to load kustomization %s: %w", k.dir, err)
+	}
+	if err := addResource(fSys, renderedManifestsFile, renderedManifests.Bytes()); err != nil {
+		return nil, fmt.Errorf("unable to load kustomization %s: %w", k.dir, err)
+	}
+
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, kustomizeRoot)
+	if err != nil {
+		return nil, fmt.Errorf("kustomize post-renderer failed: %w", err)
+	}
+	out, err := resources.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("kustomize post-renderer failed: %w", err)
+	}
+	return bytes.NewBuffer(out), nil
+}
+
+// copyToFs copies the regular files of dir to kustomizeRoot in fSys.
+func copyToFs(dir string, fSys filesys.FileSystem) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return fSys.WriteFile(path.Join(kustomizeRoot, filepath.ToSlash(rel)), data)
+	})
+}
+
+// addResource writes data to a file in kustomizeRoot, and adds the file to the
+// resources of the kustomization.
+func addResource(fSys filesys.FileSystem, name string, data []byte) error {
+	var kustomizationFile string
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		if fSys.Exists(path.Join(kustomizeRoot, n)) {
+			kustomizationFile = path.Join(kustomizeRoot, n)
+			break
+		}
+	}
+	if kustomizationFile == "" {
+		return errors.New("no kustomization file found")
+	}
+
+	content, err := fSys.ReadFile(kustomizationFile)
+	if err != nil {
+		return err
+	}
+	var kustomization types.Kustomization
+	if err := yaml.Unmarshal(content, &kustomization); err != nil {
+		return fmt.Errorf("invalid kustomization file: %w", err)
+	}
+	kustomization.Resources = append(kustomization.Resources, name)
+	if content, err = yaml.Marshal(&kustomization); err != nil {
+		return err
+	}
+
+	if err := fSys.WriteFile(kustomizationFile, content); err != nil {
+		return err
+	}
+	return fSys.WriteFile(path.Join(kustomizeRoot, name), data)
+}
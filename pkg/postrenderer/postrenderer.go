@@ -16,6 +16,7 @@ package postrenderer
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 
@@ -35,6 +36,41 @@ type PostRenderer interface {
 }
 
 // NewPostRendererPlugin creates a PostRenderer that uses the plugin's Runtime
+// Chain is a PostRenderer running post-renderers in order, each one receiving
+// the manifests modified by the previous one.
+type Chain []PostRenderer
+
+// Run runs the post-renderers of the chain in order.
+func (c Chain) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	manifests := renderedManifests
+	for _, r := range c {
+		var err error
+		if manifests, err = r.Run(manifests); err != nil {
+			return nil, err
+		}
+	}
+	return manifests, nil
+}
+
+// NewPostRenderer returns the post-renderer plugin with the given name. When
+// no such plugin is installed and the name is KustomizeName, the built-in
+// kustomize post-renderer is returned, taking the kustomization directory as
+// its only argument.
+func NewPostRenderer(settings *cli.EnvSettings, name string, args ...string) (PostRenderer, error) {
+	pr, err := NewPostRendererPlugin(settings, name, args...)
+	if err == nil || name != KustomizeName {
+		return pr, err
+	}
+	switch len(args) {
+	case 0:
+		return NewKustomizePostRenderer(""), nil
+	case 1:
+		return NewKustomizePostRenderer(args[0]), nil
+	default:
+		return nil, errors.New("the kustomize post-renderer takes a single argument, the kustomization directory")
+	}
+}
+
 func NewPostRendererPlugin(settings *cli.EnvSettings, pluginName string, args ...string) (PostRenderer, error) {
 	descriptor := plugin.Descriptor{
 		Name: pluginName,
@@ -53,6 +89,21 @@ func NewPostRendererPlugin(settings *cli.EnvSettings, pluginName string, args ..
 }
 
 // postRendererPlugin implements PostRenderer by delegating to the plugin's Runtime
+// NewPostRendererWasm returns a post-renderer running a standalone Wasm module
+// in a sandbox, without access to the network or the host filesystem. The
+// module is called like the Wasm module of a postrenderer/v1 plugin.
+func NewPostRendererWasm(wasmFile string, args ...string) (PostRenderer, error) {
+	p, err := plugin.LoadWasmFile(wasmFile, "postrenderer/v1")
+	if err != nil {
+		return nil, err
+	}
+
+	return &postRendererPlugin{
+		plugin: p,
+		args:   args,
+	}, nil
+}
+
 type postRendererPlugin struct {
 	plugin   plugin.Plugin
 	args     []string
@@ -18,6 +18,9 @@ package postrenderer
 
 import (
 	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"runtime"
 	"testing"
 
@@ -79,3 +82,70 @@ func TestNewPostRenderPluginWithTwoArgsRun(t *testing.T) {
 	is.NoError(err)
 	is.Contains(output.String(), "ARG1 ARG2")
 }
+
+func TestChainRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// the actual Run test uses a basic sed example, so skip this test on windows
+		t.Skip("skipping on windows")
+	}
+	s := cli.New()
+	s.PluginsDirectory = "testdata/plugins"
+
+	// The first post-renderer keeps FOOTEST for the second one to replace
+	first, err := NewPostRendererPlugin(s, "postrenderer-v1", "FOOTEST", "ARG1")
+	require.NoError(t, err)
+	second, err := NewPostRendererPlugin(s, "postrenderer-v1", "ARG2")
+	require.NoError(t, err)
+
+	output, err := Chain{first, second}.Run(bytes.NewBufferString("FOOTEST"))
+	require.NoError(t, err)
+	assert.Contains(t, output.String(), "ARG2 ARG1")
+
+	_, err = Chain{first, NewKustomizePostRenderer("")}.Run(bytes.NewBufferString("FOOTEST"))
+	assert.Error(t, err)
+}
+
+func TestKustomizePostRenderer(t *testing.T) {
+	manifests := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  env: dev
+  level: debug
+`
+	s := cli.New()
+	s.PluginsDirectory = "testdata/plugins"
+	renderer, err := NewPostRenderer(s, KustomizeName, "testdata/kustomize")
+	require.NoError(t, err)
+
+	output, err := renderer.Run(bytes.NewBufferString(manifests))
+	require.NoError(t, err)
+	assert.Contains(t, output.String(), "name: prod-settings")
+	assert.Contains(t, output.String(), "env: prod")
+	assert.Contains(t, output.String(), "level: debug")
+
+	_, err = NewKustomizePostRenderer(t.TempDir()).Run(bytes.NewBufferString(manifests))
+	assert.ErrorContains(t, err, "no kustomization file found")
+
+	_, err = NewPostRenderer(s, KustomizeName, "a", "b")
+	assert.Error(t, err)
+}
+
+func TestNewPostRendererWasmRun(t *testing.T) {
+	dir := "testdata/src/postrenderer-wasm"
+	cmd := exec.CommandContext(t.Context(), "make", "-C", dir)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	require.NoError(t, cmd.Run(), "failed to build wasm module in %q", dir)
+
+	renderer, err := NewPostRendererWasm(filepath.Join(dir, "plugin.wasm"), "ARG1", "ARG2")
+	require.NoError(t, err)
+
+	output, err := renderer.Run(bytes.NewBufferString("FOOTEST"))
+	require.NoError(t, err)
+	assert.Contains(t, output.String(), "ARG1 ARG2")
+
+	_, err = NewPostRendererWasm(filepath.Join(dir, "missing.wasm"))
+	assert.Error(t, err)
+}
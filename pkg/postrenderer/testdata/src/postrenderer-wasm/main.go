@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	pdk "github.com/extism/go-pdk"
+)
+
+type InputMessagePostRendererV1 struct {
+	Manifests string   `json:"manifests"`
+	ExtraArgs []string `json:"extraArgs"`
+}
+
+type OutputMessagePostRendererV1 struct {
+	Manifests string `json:"manifests"`
+}
+
+// replace replaces FOOTEST in the manifests with the arguments.
+func replace(input InputMessagePostRendererV1) *OutputMessagePostRendererV1 {
+	return &OutputMessagePostRendererV1{
+		Manifests: strings.ReplaceAll(input.Manifests, "FOOTEST", strings.Join(input.ExtraArgs, " ")),
+	}
+}
+
+//go:wasmexport helm_plugin_main
+func HelmPlugin() uint32 {
+	var input InputMessagePostRendererV1
+	if err := pdk.InputJSON(&input); err != nil {
+		pdk.SetError(fmt.Errorf("failed to parse input json: %w", err))
+		return 1
+	}
+
+	if err := pdk.OutputJSON(replace(input)); err != nil {
+		pdk.SetError(fmt.Errorf("failed to write output json: %w", err))
+		return 1
+	}
+
+	return 0
+}
+
+func main() {}
@@ -29,6 +29,7 @@ import (
 	"helm.sh/helm/v4/internal/tlsutil"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	"helm.sh/helm/v4/pkg/registry"
+	"helm.sh/helm/v4/pkg/signing"
 )
 
 // OCIPusher is the default OCI backend handler
@@ -82,7 +83,23 @@ func (pusher *OCIPusher) push(chartRef, href string) error {
 		if err != nil {
 			return err
 		}
-		pushOpts = append(pushOpts, registry.PushOptProvData(provBytes))
+		pushOpts = append(pushOpts,
+			registry.PushOptProvData(provBytes),
+			registry.PushOptProvAsReferrer(pusher.opts.provAsReferrer))
+	} else if pusher.opts.provAsReferrer {
+		return fmt.Errorf("%s: no such file", provRef)
+	}
+	referrers := pusher.opts.referrers
+	bundleRef := chartRef + signing.BundleExt
+	if _, err := os.Stat(bundleRef); err == nil {
+		bundleBytes, err := os.ReadFile(bundleRef)
+		if err != nil {
+			return err
+		}
+		referrers = append(referrers, registry.Referrer{ArtifactType: signing.BundleMediaType, Data: bundleBytes})
+	}
+	if len(referrers) > 0 {
+		pushOpts = append(pushOpts, registry.PushOptReferrers(referrers...))
 	}
 
 	ref := fmt.Sprintf("%s:%s",
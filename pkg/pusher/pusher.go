@@ -34,6 +34,8 @@ type options struct {
 	caFile                string
 	insecureSkipTLSVerify bool
 	plainHTTP             bool
+	referrers             []registry.Referrer
+	provAsReferrer        bool
 }
 
 // Option allows specifying various settings configurable by the user for overriding the defaults
@@ -69,6 +71,21 @@ func WithPlainHTTP(plainHTTP bool) Option {
 	}
 }
 
+// WithReferrers attaches artifacts such as SBOMs and attestations to the pushed chart.
+func WithReferrers(referrers ...registry.Referrer) Option {
+	return func(opts *options) {
+		opts.referrers = append(opts.referrers, referrers...)
+	}
+}
+
+// WithProvAsReferrer attaches the provenance file to the pushed chart as a
+// referrer rather than storing it in the chart manifest.
+func WithProvAsReferrer(provAsReferrer bool) Option {
+	return func(opts *options) {
+		opts.provAsReferrer = provAsReferrer
+	}
+}
+
 // Pusher is an interface to support upload to the specified URL.
 type Pusher interface {
 	// Push file content by url string
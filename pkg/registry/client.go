@@ -32,9 +32,11 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/opencontainers/go-digest"
 	"github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/memory"
 	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
@@ -76,6 +78,9 @@ type (
 		credentialsStore   credentials.Store
 		httpClient         *http.Client
 		plainHTTP          bool
+		registriesConfig   *RegistriesConfig
+		hosts              *registryHosts
+		cloudAuth          bool
 	}
 
 	// ClientOption allows specifying various settings configurable by the user for overriding the defaults
@@ -130,6 +135,9 @@ func NewClient(options ...ClientOption) (*Client, error) {
 			}
 		} else {
 			authorizer.Credential = credentials.Credential(client.credentialsStore)
+			if client.cloudAuth {
+				authorizer.Credential = newCloudAuth().credential(authorizer.Credential)
+			}
 		}
 
 		if client.enableCache {
@@ -138,6 +146,13 @@ func NewClient(options ...ClientOption) (*Client, error) {
 		client.authorizer = &authorizer
 	}
 
+	if client.registriesConfig != nil {
+		client.hosts, err = newRegistryHosts(client.registriesConfig, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return client, nil
 }
 
@@ -415,6 +430,9 @@ type (
 		Chart    *DescriptorPullSummaryWithMeta `json:"chart"`
 		Prov     *DescriptorPullSummary         `json:"prov"`
 		Ref      string                         `json:"ref"`
+		// Referrers are the artifacts attached to the chart manifest. They
+		// are only fetched with PullOptWithReferrers.
+		Referrers []*Referrer `json:"referrers,omitempty"`
 	}
 
 	DescriptorPullSummary struct {
@@ -432,6 +450,7 @@ type (
 		withChart         bool
 		withProv          bool
 		ignoreMissingProv bool
+		withReferrers     bool
 	}
 )
 
@@ -483,8 +502,29 @@ func (c *Client) processChartPull(genericResult *GenericPullResult, operation *p
 			ChartLayerMediaType)
 	}
 
-	var provMissing bool
+	// A chart signed after it was pushed has its prov attached as a referrer
+	var referrers []*Referrer
+	if operation.withReferrers || operation.withProv && provDescriptor == nil {
+		artifactType := ProvLayerMediaType
+		if operation.withReferrers {
+			artifactType = ""
+		}
+		repository, err := c.remoteRepository(genericResult.Ref)
+		if err != nil {
+			return nil, err
+		}
+		referrers, err = fetchReferrers(context.Background(), repository, genericResult.Manifest, artifactType)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var provData []byte
 	if operation.withProv && provDescriptor == nil {
+		provData = provReferrer(referrers)
+	}
+
+	var provMissing bool
+	if operation.withProv && provDescriptor == nil && provData == nil {
 		if operation.ignoreMissingProv {
 			provMissing = true
 		} else {
@@ -507,6 +547,9 @@ func (c *Client) processChartPull(genericResult *GenericPullResult, operation *p
 		Prov:  &DescriptorPullSummary{},
 		Ref:   genericResult.Ref,
 	}
+	if operation.withReferrers {
+		result.Referrers = referrers
+	}
 
 	// Fetch data using generic client
 	genericClient := c.Generic()
@@ -534,7 +577,11 @@ func (c *Client) processChartPull(genericResult *GenericPullResult, operation *p
 		result.Chart.Size = chartDescriptor.Size
 	}
 
-	if operation.withProv && !provMissing {
+	if provData != nil {
+		result.Prov.Data = provData
+		result.Prov.Digest = digest.FromBytes(provData).String()
+		result.Prov.Size = int64(len(provData))
+	} else if operation.withProv && !provMissing {
 		result.Prov.Data, err = genericClient.GetDescriptorData(genericResult.MemoryStore, *provDescriptor)
 		if err != nil {
 			return nil, fmt.Errorf("unable to retrieve blob with digest %s: %w", provDescriptor.Digest, err)
@@ -545,6 +592,9 @@ func (c *Client) processChartPull(genericResult *GenericPullResult, operation *p
 
 	_, _ = fmt.Fprintf(c.out, "Pulled: %s\n", result.Ref)
 	_, _ = fmt.Fprintf(c.out, "Digest: %s\n", result.Manifest.Digest)
+	for _, referrer := range result.Referrers {
+		_, _ = fmt.Fprintf(c.out, "Verified referrer: %s %s\n", referrer.ArtifactType, referrer.Digest)
+	}
 
 	if strings.Contains(result.Ref, "_") {
 		_, _ = fmt.Fprintf(c.out, "%s contains an underscore.\n", result.Ref)
@@ -582,8 +632,10 @@ func (c *Client) Pull(ref string, options ...PullOption) (*PullResult, error) {
 
 	// Use generic client for the pull operation
 	genericClient := c.Generic()
-	genericResult, err := genericClient.PullGeneric(ref, GenericPullOptions{
-		AllowedMediaTypes: allowedMediaTypes,
+	genericResult, err := readMirrored(c.hosts, ref, func(ref string) (*GenericPullResult, error) {
+		return genericClient.PullGeneric(ref, GenericPullOptions{
+			AllowedMediaTypes: allowedMediaTypes,
+		})
 	})
 	if err != nil {
 		return nil, err
@@ -625,6 +677,8 @@ type (
 		Chart    *descriptorPushSummaryWithMeta `json:"chart"`
 		Prov     *descriptorPushSummary         `json:"prov"`
 		Ref      string                         `json:"ref"`
+		// Referrers are the artifacts attached to the chart manifest.
+		Referrers []*Referrer `json:"referrers,omitempty"`
 	}
 
 	descriptorPushSummary struct {
@@ -638,9 +692,11 @@ type (
 	}
 
 	pushOperation struct {
-		provData     []byte
-		strictMode   bool
-		creationTime string
+		provData       []byte
+		strictMode     bool
+		creationTime   string
+		provAsReferrer bool
+		referrers      []Referrer
 	}
 )
 
@@ -688,7 +744,10 @@ func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResu
 
 	layers := []ocispec.Descriptor{chartDescriptor}
 	var provDescriptor ocispec.Descriptor
-	if operation.provData != nil {
+	referrers := operation.referrers
+	if operation.provData != nil && operation.provAsReferrer {
+		referrers = append([]Referrer{{ArtifactType: ProvLayerMediaType, Data: operation.provData}}, referrers...)
+	} else if operation.provData != nil {
 		provDescriptor, err = oras.PushBytes(ctx, memoryStore, ProvLayerMediaType, operation.provData)
 		if err != nil {
 			return nil, err
@@ -710,13 +769,17 @@ func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResu
 		return nil, err
 	}
 
-	repository, err := remote.NewRepository(parsedRef.String())
+	referrerDescriptors, err := packReferrers(ctx, memoryStore, manifestDescriptor, referrers)
+	if err != nil {
+		return nil, err
+	}
+
+	repository, err := c.remoteRepository(parsedRef.String())
 	if err != nil {
 		return nil, err
 	}
-	repository.PlainHTTP = c.plainHTTP
-	repository.Client = c.authorizer
 
+	// ExtendedCopy also copies the referrers of the chart manifest
 	manifestDescriptor, err = oras.ExtendedCopy(ctx, memoryStore, parsedRef.String(), repository, parsedRef.String(), oras.DefaultExtendedCopyOptions)
 	if err != nil {
 		return nil, err
@@ -740,7 +803,13 @@ func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResu
 		Prov:  &descriptorPushSummary{}, // prevent nil references
 		Ref:   parsedRef.String(),
 	}
-	if operation.provData != nil {
+	for i, desc := range referrerDescriptors {
+		result.Referrers = append(result.Referrers, &Referrer{
+			ArtifactType: referrers[i].ArtifactType,
+			Digest:       desc.Digest.String(),
+		})
+	}
+	if operation.provData != nil && !operation.provAsReferrer {
 		result.Prov = &descriptorPushSummary{
 			Digest: provDescriptor.Digest.String(),
 			Size:   provDescriptor.Size,
@@ -748,6 +817,9 @@ func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResu
 	}
 	_, _ = fmt.Fprintf(c.out, "Pushed: %s\n", result.Ref)
 	_, _ = fmt.Fprintf(c.out, "Digest: %s\n", result.Manifest.Digest)
+	for _, referrer := range result.Referrers {
+		_, _ = fmt.Fprintf(c.out, "Attached: %s %s\n", referrer.ArtifactType, referrer.Digest)
+	}
 	if strings.Contains(parsedRef.orasReference.Reference, "_") {
 		_, _ = fmt.Fprintf(c.out, "%s contains an underscore.\n", result.Ref)
 		_, _ = fmt.Fprint(c.out, registryUnderscoreMessage+"\n")
@@ -785,25 +857,26 @@ func (c *Client) Tags(ref string) ([]string, error) {
 	}
 
 	ctx := context.Background()
-	repository, err := remote.NewRepository(parsedReference.String())
-	if err != nil {
-		return nil, err
-	}
-	repository.PlainHTTP = c.plainHTTP
-	repository.Client = c.authorizer
-
-	var tagVersions []*semver.Version
-	err = repository.Tags(ctx, "", func(tags []string) error {
-		for _, tag := range tags {
-			// Change underscore (_) back to plus (+) for Helm
-			// See https://github.com/helm/helm/issues/10166
-			tagVersion, err := semver.StrictNewVersion(strings.ReplaceAll(tag, "_", "+"))
-			if err == nil {
-				tagVersions = append(tagVersions, tagVersion)
-			}
+	tagVersions, err := readMirrored(c.hosts, parsedReference.String(), func(ref string) ([]*semver.Version, error) {
+		repository, err := c.remoteRepository(ref)
+		if err != nil {
+			return nil, err
 		}
 
-		return nil
+		var tagVersions []*semver.Version
+		err = repository.Tags(ctx, "", func(tags []string) error {
+			for _, tag := range tags {
+				// Change underscore (_) back to plus (+) for Helm
+				// See https://github.com/helm/helm/issues/10166
+				tagVersion, err := semver.StrictNewVersion(strings.ReplaceAll(tag, "_", "+"))
+				if err == nil {
+					tagVersions = append(tagVersions, tagVersion)
+				}
+			}
+
+			return nil
+		})
+		return tagVersions, err
 	})
 	if err != nil {
 		return nil, err
@@ -821,23 +894,107 @@ func (c *Client) Tags(ref string) ([]string, error) {
 	return tags, nil
 }
 
-// Resolve a reference to a descriptor.
-func (c *Client) Resolve(ref string) (desc ocispec.Descriptor, err error) {
-	remoteRepository, err := remote.NewRepository(ref)
+// ErrNotChart is returned when a reference does not point to a Helm chart.
+var ErrNotChart = errors.New("not a Helm chart")
+
+// Repositories lists the repositories of a registry using the catalog API,
+// sorted by name. The host may be followed by a namespace, such as
+// "ghcr.io/org", to only list the repositories under that namespace.
+//
+// The returned names do not include the host. Registries that do not
+// implement the catalog API return an error.
+func (c *Client) Repositories(host string) ([]string, error) {
+	host, namespace, _ := strings.Cut(strings.TrimPrefix(host, OCIScheme+"://"), "/")
+	namespace = strings.Trim(namespace, "/")
+
+	reg, err := remote.NewRegistry(host)
 	if err != nil {
-		return desc, err
+		return nil, err
+	}
+	reg.Client, reg.PlainHTTP = c.hosts.client(host, c.authorizer, c.plainHTTP)
+
+	var repositories []string
+	err = reg.Repositories(context.Background(), "", func(repos []string) error {
+		for _, repo := range repos {
+			if namespace == "" || strings.HasPrefix(repo, namespace+"/") {
+				repositories = append(repositories, repo)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the repositories of %s (the registry may not support the catalog API): %w", host, err)
+	}
+
+	sort.Strings(repositories)
+	return repositories, nil
+}
+
+// ChartMetadata fetches the metadata of the chart at ref from its manifest
+// config, without downloading the chart archive. ErrNotChart is returned if
+// ref does not point to a Helm chart.
+func (c *Client) ChartMetadata(ref string) (*chart.Metadata, error) {
+	parsedRef, err := newReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	var repository *remote.Repository
+	var desc ocispec.Descriptor
+	manifestData, err := readMirrored(c.hosts, parsedRef.String(), func(ref string) ([]byte, error) {
+		mirrorRepository, err := c.remoteRepository(ref)
+		if err != nil {
+			return nil, err
+		}
+		mirrorDesc, manifestData, err := oras.FetchBytes(ctx, mirrorRepository, ref, oras.DefaultFetchBytesOptions)
+		if err != nil {
+			return nil, err
+		}
+		repository, desc = mirrorRepository, mirrorDesc
+		return manifestData, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if desc.MediaType != ocispec.MediaTypeImageManifest {
+		return nil, fmt.Errorf("%s: %w: unexpected media type %q", ref, ErrNotChart, desc.MediaType)
 	}
-	remoteRepository.PlainHTTP = c.plainHTTP
-	remoteRepository.Client = c.authorizer
 
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: unable to parse manifest: %w", ref, err)
+	}
+	if manifest.Config.MediaType != ConfigMediaType {
+		return nil, fmt.Errorf("%s: %w: unexpected config media type %q", ref, ErrNotChart, manifest.Config.MediaType)
+	}
+
+	configData, err := content.FetchAll(ctx, repository, manifest.Config)
+	if err != nil {
+		return nil, err
+	}
+	var meta chart.Metadata
+	if err := json.Unmarshal(configData, &meta); err != nil {
+		return nil, fmt.Errorf("%s: unable to parse chart metadata: %w", ref, err)
+	}
+	return &meta, nil
+}
+
+// Resolve a reference to a descriptor.
+func (c *Client) Resolve(ref string) (desc ocispec.Descriptor, err error) {
 	parsedReference, err := newReference(ref)
 	if err != nil {
 		return desc, err
 	}
 
 	ctx := context.Background()
-	parsedString := parsedReference.String()
-	return remoteRepository.Resolve(ctx, parsedString)
+	return readMirrored(c.hosts, parsedReference.String(), func(ref string) (ocispec.Descriptor, error) {
+		remoteRepository, err := c.remoteRepository(ref)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		return remoteRepository.Resolve(ctx, ref)
+	})
 }
 
 // ValidateReference for path and version
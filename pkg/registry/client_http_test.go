@@ -78,6 +78,22 @@ func (suite *HTTPRegistryClientTestSuite) Test_5_ImageIndex() {
 	suite.Require().NoError(err)
 }
 
+func (suite *HTTPRegistryClientTestSuite) Test_6_PullValues() {
+	testPullValues(&suite.TestRegistry)
+}
+
+func (suite *HTTPRegistryClientTestSuite) Test_7_Repositories() {
+	testRepositories(&suite.TestRegistry)
+}
+
+func (suite *HTTPRegistryClientTestSuite) Test_8_ChartMetadata() {
+	testChartMetadata(&suite.TestRegistry)
+}
+
+func (suite *HTTPRegistryClientTestSuite) Test_9_Referrers() {
+	testReferrers(&suite.TestRegistry)
+}
+
 func TestHTTPRegistryClientTestSuite(t *testing.T) {
 	suite.Run(t, new(HTTPRegistryClientTestSuite))
 }
@@ -0,0 +1,171 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// ClientOptCloudAuth returns a function that sets whether the client gets
+// the credentials of the Amazon ECR, Google Artifact Registry and Azure
+// Container Registry registries from the ambient credentials of their cloud,
+// when none are stored for them.
+func ClientOptCloudAuth(enabled bool) ClientOption {
+	return func(client *Client) {
+		client.cloudAuth = enabled
+	}
+}
+
+// cloudCredentialFunc exchanges ambient cloud credentials for the credential
+// of a registry, valid until the returned time.
+type cloudCredentialFunc func(ctx context.Context, a *cloudAuth, host string) (auth.Credential, time.Time, error)
+
+// cloudCredentialProvider returns the function getting the credential of
+// host from its cloud, or nil if host is not a cloud registry.
+func cloudCredentialProvider(host string) cloudCredentialFunc {
+	hostname := hostWithoutPort(host)
+	switch {
+	case ecrHostPattern.MatchString(hostname):
+		return ecrCredential
+	case hostname == "gcr.io" || strings.HasSuffix(hostname, ".gcr.io") || strings.HasSuffix(hostname, "-docker.pkg.dev"):
+		return gcrCredential
+	case strings.HasSuffix(hostname, ".azurecr.io") || strings.HasSuffix(hostname, ".azurecr.cn") || strings.HasSuffix(hostname, ".azurecr.us"):
+		return acrCredential
+	}
+	return nil
+}
+
+// cloudAuth gets and caches the credentials of cloud registries.
+type cloudAuth struct {
+	// httpClient calls the cloud APIs.
+	httpClient *http.Client
+	// metadataClient calls the metadata services of the cloud instances,
+	// which are only reachable from the instances themselves.
+	metadataClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cloudCredential
+}
+
+type cloudCredential struct {
+	credential auth.Credential
+	expiry     time.Time
+}
+
+func newCloudAuth() *cloudAuth {
+	return &cloudAuth{
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+		},
+		metadataClient: &http.Client{
+			Timeout:   2 * time.Second,
+			Transport: &http.Transport{},
+		},
+		cache: map[string]cloudCredential{},
+	}
+}
+
+// credential returns a credential function which uses the credential of
+// fallback if there is one, or else the credential of the cloud of the
+// registry. Failing to get the credential of a cloud is not an error, as the
+// registry may allow anonymous access.
+func (a *cloudAuth) credential(fallback auth.CredentialFunc) auth.CredentialFunc {
+	return func(ctx context.Context, host string) (auth.Credential, error) {
+		cred, err := fallback(ctx, host)
+		if err != nil || cred != auth.EmptyCredential {
+			return cred, err
+		}
+		provider := cloudCredentialProvider(host)
+		if provider == nil {
+			return cred, nil
+		}
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		// Renew the credentials a minute before they expire
+		if cached, ok := a.cache[host]; ok && time.Now().Add(time.Minute).Before(cached.expiry) {
+			return cached.credential, nil
+		}
+		cred, expiry, err := provider(ctx, a, host)
+		if err != nil {
+			slog.Debug("unable to get the cloud credentials of the registry", "host", host, "error", err)
+			return auth.EmptyCredential, nil
+		}
+		a.cache[host] = cloudCredential{credential: cred, expiry: expiry}
+		return cred, nil
+	}
+}
+
+// postForm posts form to endpoint, and decodes the JSON response into v.
+func (a *cloudAuth) postForm(ctx context.Context, endpoint string, form url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doJSON(a.httpClient, req, v)
+}
+
+// doJSON sends req with client, and decodes the JSON response into v.
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	body, err := doText(client, req)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(body), v); err != nil {
+		return fmt.Errorf("%s %s: unable to parse the response: %w", req.Method, req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+// doText sends req with client, and returns the response body.
+func doText(client *http.Client, req *http.Request) (string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s: unexpected status %s: %s", req.Method, req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return string(body), nil
+}
+
+// hostWithoutPort returns the hostname of host, a host with an optional port.
+func hostWithoutPort(host string) string {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		return hostname
+	}
+	return host
+}
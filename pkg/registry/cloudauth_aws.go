@@ -0,0 +1,392 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// ecrHostPattern matches the hostnames of the Amazon ECR private registries,
+// capturing their region and the partition suffix.
+var ecrHostPattern = regexp.MustCompile(`^\d{12}\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(\.cn)?$`)
+
+// awsCredentials are AWS security credentials, as returned by the container
+// and instance metadata services and by STS.
+type awsCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId" xml:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey" xml:"SecretAccessKey"`
+	SessionToken    string    `json:"Token" xml:"SessionToken"`
+	Expiration      time.Time `json:"Expiration" xml:"Expiration"`
+}
+
+// ecrCredential gets the credential of an Amazon ECR registry with
+// GetAuthorizationToken.
+func ecrCredential(ctx context.Context, a *cloudAuth, host string) (auth.Credential, time.Time, error) {
+	match := ecrHostPattern.FindStringSubmatch(hostWithoutPort(host))
+	if match == nil {
+		return auth.EmptyCredential, time.Time{}, fmt.Errorf("%s is not an Amazon ECR registry", host)
+	}
+	region, partition := match[1], match[2]
+	creds, err := a.awsCredentials(ctx, region)
+	if err != nil {
+		return auth.EmptyCredential, time.Time{}, err
+	}
+
+	body := []byte("{}")
+	endpoint := awsEndpoint("ECR", "https://api.ecr."+region+".amazonaws.com"+partition)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return auth.EmptyCredential, time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken")
+	signAWSRequest(req, body, creds, region, "ecr", time.Now())
+
+	var resp struct {
+		AuthorizationData []struct {
+			AuthorizationToken string  `json:"authorizationToken"`
+			ExpiresAt          float64 `json:"expiresAt"`
+		} `json:"authorizationData"`
+	}
+	if err := doJSON(a.httpClient, req, &resp); err != nil {
+		return auth.EmptyCredential, time.Time{}, err
+	}
+	if len(resp.AuthorizationData) == 0 {
+		return auth.EmptyCredential, time.Time{}, errors.New("no authorization data in the ECR response")
+	}
+	token, err := base64.StdEncoding.DecodeString(resp.AuthorizationData[0].AuthorizationToken)
+	if err != nil {
+		return auth.EmptyCredential, time.Time{}, fmt.Errorf("invalid ECR authorization token: %w", err)
+	}
+	username, password, ok := strings.Cut(string(token), ":")
+	if !ok {
+		return auth.EmptyCredential, time.Time{}, errors.New("invalid ECR authorization token")
+	}
+	expiry := time.Unix(int64(resp.AuthorizationData[0].ExpiresAt), 0)
+	if !creds.Expiration.IsZero() && creds.Expiration.Before(expiry) {
+		expiry = creds.Expiration
+	}
+	return auth.Credential{Username: username, Password: password}, expiry, nil
+}
+
+// awsEndpoint returns the endpoint of service, which can be overridden with
+// the AWS_ENDPOINT_URL_<SERVICE> and AWS_ENDPOINT_URL environment variables.
+func awsEndpoint(service, defaultEndpoint string) string {
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL_" + service); endpoint != "" {
+		return endpoint
+	}
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		return endpoint
+	}
+	return defaultEndpoint
+}
+
+// awsCredentials returns the first AWS credentials found in the environment,
+// a web identity token file, the shared credentials file, the container
+// credentials service and the instance metadata service.
+func (a *cloudAuth) awsCredentials(ctx context.Context, region string) (*awsCredentials, error) {
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return &awsCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+	if tokenFile, role := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), os.Getenv("AWS_ROLE_ARN"); tokenFile != "" && role != "" {
+		return a.awsWebIdentityCredentials(ctx, region, tokenFile, role)
+	}
+	if creds, err := awsSharedCredentials(); err != nil || creds != nil {
+		return creds, err
+	}
+	if os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI") != "" || os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI") != "" {
+		return a.awsContainerCredentials(ctx)
+	}
+	if disabled := os.Getenv("AWS_EC2_METADATA_DISABLED"); strings.EqualFold(disabled, "true") {
+		return nil, errors.New("no AWS credentials found")
+	}
+	return a.awsInstanceCredentials(ctx)
+}
+
+// awsWebIdentityCredentials exchanges the web identity token in tokenFile,
+// such as a Kubernetes service account token, for the credentials of role.
+func (a *cloudAuth) awsWebIdentityCredentials(ctx context.Context, region, tokenFile, role string) (*awsCredentials, error) {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return nil, err
+	}
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = "helm"
+	}
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {role},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	domain := "amazonaws.com"
+	if strings.HasPrefix(region, "cn-") {
+		domain = "amazonaws.com.cn"
+	}
+	endpoint := awsEndpoint("STS", "https://sts."+region+"."+domain)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := doText(a.httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Credentials awsCredentials `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal([]byte(body), &resp); err != nil {
+		return nil, fmt.Errorf("unable to parse the STS response: %w", err)
+	}
+	return &resp.Credentials, nil
+}
+
+// awsSharedCredentials reads the static credentials of the AWS_PROFILE, or
+// default, profile of the shared credentials file. It returns no credentials
+// if the file or the profile do not exist.
+func awsSharedCredentials() (*awsCredentials, error) {
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+	var creds awsCredentials
+	var section string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != profile {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, nil
+	}
+	return &creds, nil
+}
+
+// awsContainerCredentials gets the credentials of the ECS task or EKS pod
+// from the container credentials service.
+func (a *cloudAuth) awsContainerCredentials(ctx context.Context) (*awsCredentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if endpoint == "" {
+		endpoint = "http://169.254.170.2" + os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	var creds awsCredentials
+	if err := doJSON(a.metadataClient, req, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+// awsInstanceCredentials gets the credentials of the EC2 instance role from
+// the instance metadata service, with IMDSv2.
+func (a *cloudAuth) awsInstanceCredentials(ctx context.Context) (*awsCredentials, error) {
+	endpoint := os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "http://169.254.169.254"
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	token, err := doText(a.metadataClient, req)
+	if err != nil {
+		return nil, err
+	}
+
+	get := func(path string) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-aws-ec2-metadata-token", token)
+		return req, nil
+	}
+	req, err = get("/latest/meta-data/iam/security-credentials/")
+	if err != nil {
+		return nil, err
+	}
+	roles, err := doText(a.metadataClient, req)
+	if err != nil {
+		return nil, err
+	}
+	role, _, _ := strings.Cut(strings.TrimSpace(roles), "\n")
+	if role == "" {
+		return nil, errors.New("no IAM role is attached to the instance")
+	}
+	req, err = get("/latest/meta-data/iam/security-credentials/" + role)
+	if err != nil {
+		return nil, err
+	}
+	var creds awsCredentials
+	if err := doJSON(a.metadataClient, req, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+// signAWSRequest signs req, whose body is body, with AWS Signature Version 4.
+func signAWSRequest(req *http.Request, body []byte, creds *awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, awsEscape(key)+"="+awsEscape(value))
+		}
+	}
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.Join(params, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := strings.Join([]string{amzDate[:8], region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(canonicalRequestHash[:])}, "\n")
+
+	key := []byte("AWS4" + creds.SecretAccessKey)
+	for _, part := range []string{amzDate[:8], region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// awsEscape escapes s as required by AWS Signature Version 4.
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,119 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+const (
+	// acrUsername is the username of the ACR refresh tokens, used as
+	// passwords.
+	acrUsername = "00000000-0000-0000-0000-000000000000"
+	// azureResource is the resource of the Microsoft Entra ID access tokens
+	// exchanged for ACR refresh tokens.
+	azureResource = "https://management.azure.com/"
+)
+
+// azureIMDSEndpoint is the managed identity endpoint of the Azure instance
+// metadata service.
+var azureIMDSEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// acrCredential gets the credential of an Azure Container Registry registry
+// by exchanging a Microsoft Entra ID access token for an ACR refresh token.
+func acrCredential(ctx context.Context, a *cloudAuth, host string) (auth.Credential, time.Time, error) {
+	now := time.Now()
+	token, err := a.azureAccessToken(ctx)
+	if err != nil {
+		return auth.EmptyCredential, time.Time{}, err
+	}
+	form := url.Values{
+		"grant_type":   {"access_token"},
+		"service":      {host},
+		"access_token": {token.AccessToken},
+	}
+	if tenant := os.Getenv("AZURE_TENANT_ID"); tenant != "" {
+		form.Set("tenant", tenant)
+	}
+	var exchanged oauthToken
+	if err := a.postForm(ctx, "https://"+host+"/oauth2/exchange", form, &exchanged); err != nil {
+		return auth.EmptyCredential, time.Time{}, err
+	}
+	if exchanged.RefreshToken == "" {
+		return auth.EmptyCredential, time.Time{}, errors.New("no refresh token in the ACR exchange response")
+	}
+	// The refresh token is valid as long as the access token it comes from
+	return auth.Credential{Username: acrUsername, Password: exchanged.RefreshToken}, token.expiry(now), nil
+}
+
+// azureAccessToken gets a Microsoft Entra ID access token with the workload
+// identity of the pod, the client secret of the service principal of the
+// environment, or the managed identity of the instance.
+func (a *cloudAuth) azureAccessToken(ctx context.Context) (*oauthToken, error) {
+	clientID, tenantID := os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_TENANT_ID")
+	tokenFile, secret := os.Getenv("AZURE_FEDERATED_TOKEN_FILE"), os.Getenv("AZURE_CLIENT_SECRET")
+	if clientID != "" && tenantID != "" && (tokenFile != "" || secret != "") {
+		form := url.Values{
+			"grant_type": {"client_credentials"},
+			"client_id":  {clientID},
+			"scope":      {azureResource + ".default"},
+		}
+		if tokenFile != "" {
+			assertion, err := os.ReadFile(tokenFile)
+			if err != nil {
+				return nil, err
+			}
+			form.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+			form.Set("client_assertion", strings.TrimSpace(string(assertion)))
+		} else {
+			form.Set("client_secret", secret)
+		}
+		authority := os.Getenv("AZURE_AUTHORITY_HOST")
+		if authority == "" {
+			authority = "https://login.microsoftonline.com/"
+		}
+		endpoint := strings.TrimSuffix(authority, "/") + "/" + url.PathEscape(tenantID) + "/oauth2/v2.0/token"
+		var token oauthToken
+		if err := a.postForm(ctx, endpoint, form, &token); err != nil {
+			return nil, err
+		}
+		return &token, nil
+	}
+
+	query := url.Values{"api-version": {"2018-02-01"}, "resource": {azureResource}}
+	if clientID != "" {
+		query.Set("client_id", clientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, azureIMDSEndpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+	var token oauthToken
+	if err := doJSON(a.metadataClient, req, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,229 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+const (
+	// googleTokenURL is the default OAuth 2.0 token endpoint of Google.
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+	// googleScope is the OAuth 2.0 scope of the access tokens of registries.
+	googleScope = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// oauthToken is an OAuth 2.0 token response. The expiry is a number of
+// seconds, which some servers send as a string.
+type oauthToken struct {
+	AccessToken  string      `json:"access_token"`
+	RefreshToken string      `json:"refresh_token"`
+	ExpiresIn    json.Number `json:"expires_in"`
+}
+
+// expiry returns the expiry of the token obtained at now.
+func (t *oauthToken) expiry(now time.Time) time.Time {
+	seconds, err := t.ExpiresIn.Int64()
+	if err != nil || seconds <= 0 {
+		// Assume the shortest lifetime of the access tokens of the clouds
+		seconds = 300
+	}
+	return now.Add(time.Duration(seconds) * time.Second)
+}
+
+// gcrCredential gets the credential of a Google Artifact Registry or
+// Container Registry registry from a Google OAuth 2.0 access token.
+func gcrCredential(ctx context.Context, a *cloudAuth, _ string) (auth.Credential, time.Time, error) {
+	now := time.Now()
+	token, err := a.googleAccessToken(ctx)
+	if err != nil {
+		return auth.EmptyCredential, time.Time{}, err
+	}
+	return auth.Credential{Username: "oauth2accesstoken", Password: token.AccessToken}, token.expiry(now), nil
+}
+
+// googleAccessToken gets an access token with the application default
+// credentials: the file of GOOGLE_APPLICATION_CREDENTIALS, the file of
+// gcloud auth application-default login, or the service account of the
+// instance from the metadata server.
+func (a *cloudAuth) googleAccessToken(ctx context.Context) (*oauthToken, error) {
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		if wellKnown := googleWellKnownCredentialsFile(); wellKnown != "" {
+			if _, err := os.Stat(wellKnown); err == nil {
+				path = wellKnown
+			}
+		}
+	}
+	if path != "" {
+		return a.googleFileAccessToken(ctx, path)
+	}
+
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = "metadata.google.internal"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var token oauthToken
+	if err := doJSON(a.metadataClient, req, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// googleWellKnownCredentialsFile returns the path of the application default
+// credentials written by gcloud.
+func googleWellKnownCredentialsFile() string {
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return filepath.Join(dir, "application_default_credentials.json")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return filepath.Join(dir, "gcloud", "application_default_credentials.json")
+		}
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+// googleFileAccessToken gets an access token with the service account key
+// or the user refresh token in the credentials file at path.
+func (a *cloudAuth) googleFileAccessToken(ctx context.Context, path string) (*oauthToken, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKeyID string `json:"private_key_id"`
+		PrivateKey   string `json:"private_key"`
+		TokenURI     string `json:"token_uri"`
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("unable to parse the Google credentials file %s: %w", path, err)
+	}
+	if file.TokenURI == "" {
+		file.TokenURI = googleTokenURL
+	}
+
+	var form url.Values
+	switch file.Type {
+	case "service_account":
+		assertion, err := googleJWTAssertion(file.ClientEmail, file.PrivateKeyID, file.PrivateKey, file.TokenURI, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		form = url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		}
+	case "authorized_user":
+		form = url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {file.ClientID},
+			"client_secret": {file.ClientSecret},
+			"refresh_token": {file.RefreshToken},
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported Google credentials type %q", path, file.Type)
+	}
+	var token oauthToken
+	if err := a.postForm(ctx, file.TokenURI, form, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// googleJWTAssertion returns the JWT of the service account email, signed
+// with its PEM private key, to exchange for an access token at tokenURI.
+func googleJWTAssertion(email, keyID, privateKey, tokenURI string, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return "", errors.New("invalid service account private key")
+	}
+	var key *rsa.PrivateKey
+	if parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return "", errors.New("the service account private key is not an RSA key")
+		}
+		key = rsaKey
+	} else if key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return "", fmt.Errorf("invalid service account private key: %w", err)
+	}
+
+	encode := func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	}
+	header, err := encode(map[string]string{"alg": "RS256", "typ": "JWT", "kid": keyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := encode(map[string]any{
+		"iss":   email,
+		"scope": googleScope,
+		"aud":   tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := header + "." + claims
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{signed, base64.RawURLEncoding.EncodeToString(signature)}, "."), nil
+}
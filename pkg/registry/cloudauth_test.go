@@ -0,0 +1,326 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// newTestCloudAuth returns a cloudAuth calling all the services with client.
+func newTestCloudAuth(client *http.Client) *cloudAuth {
+	a := newCloudAuth()
+	a.httpClient = client
+	a.metadataClient = client
+	return a
+}
+
+func TestCloudCredentialProvider(t *testing.T) {
+	tests := []struct {
+		host string
+		want cloudCredentialFunc
+	}{
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com", ecrCredential},
+		{"123456789012.dkr.ecr-fips.us-gov-west-1.amazonaws.com", ecrCredential},
+		{"123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn:443", ecrCredential},
+		{"public.ecr.aws", nil},
+		{"gcr.io", gcrCredential},
+		{"eu.gcr.io", gcrCredential},
+		{"europe-west1-docker.pkg.dev", gcrCredential},
+		{"myregistry.azurecr.io", acrCredential},
+		{"ghcr.io", nil},
+		{"localhost:5000", nil},
+	}
+	for _, tt := range tests {
+		got := cloudCredentialProvider(tt.host)
+		if (got == nil) != (tt.want == nil) || got != nil && reflect.ValueOf(got).Pointer() != reflect.ValueOf(tt.want).Pointer() {
+			t.Errorf("unexpected credential provider for %s", tt.host)
+		}
+	}
+}
+
+func TestSignAWSRequest(t *testing.T) {
+	// The examples of the AWS Signature Version 4 documentation
+	creds := &awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	req.Header = http.Header{}
+	signAWSRequest(req, nil, creds, "us-east-1", "service", now)
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("unexpected authorization\n got: %s\nwant: %s", got, want)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header = http.Header{"Content-Type": {"application/x-www-form-urlencoded; charset=utf-8"}}
+	signAWSRequest(req, nil, creds, "us-east-1", "iam", now)
+	want = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("unexpected authorization\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestECRCredential(t *testing.T) {
+	const host = "123456789012.dkr.ecr.us-west-2.amazonaws.com"
+	expiresAt := time.Now().Add(12 * time.Hour).Truncate(time.Second)
+	var accessKeys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/creds":
+			if r.Header.Get("Authorization") != "pod-token" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"AccessKeyId":"CONTAINERKEY","SecretAccessKey":"secret","Token":"session"}`)
+		case "/":
+			if r.Header.Get("X-Amz-Target") != "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken" {
+				http.Error(w, "unexpected target", http.StatusBadRequest)
+				return
+			}
+			authorization := r.Header.Get("Authorization")
+			key, _, _ := strings.Cut(strings.TrimPrefix(authorization, "AWS4-HMAC-SHA256 Credential="), "/")
+			if !strings.Contains(authorization, "/us-west-2/ecr/aws4_request") {
+				http.Error(w, "unexpected scope", http.StatusForbidden)
+				return
+			}
+			accessKeys = append(accessKeys, key+":"+r.Header.Get("X-Amz-Security-Token"))
+			fmt.Fprintf(w, `{"authorizationData":[{"authorizationToken":%q,"expiresAt":%d}]}`,
+				base64.StdEncoding.EncodeToString([]byte("AWS:ecr-password")), expiresAt.Unix())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("AWS_ENDPOINT_URL_ECR", srv.URL)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "ENVKEY")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	a := newTestCloudAuth(srv.Client())
+	cred, expiry, err := ecrCredential(context.Background(), a, host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred != (auth.Credential{Username: "AWS", Password: "ecr-password"}) {
+		t.Errorf("unexpected credential %+v", cred)
+	}
+	if !expiry.Equal(expiresAt) {
+		t.Errorf("expected the credential to expire at %v, got %v", expiresAt, expiry)
+	}
+
+	// The credentials of the container are used when the environment has none
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", srv.URL+"/creds")
+	t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "pod-token")
+	if _, _, err := ecrCredential(context.Background(), a, host); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ENVKEY:", "CONTAINERKEY:session"}; !reflect.DeepEqual(accessKeys, want) {
+		t.Errorf("expected the requests to be signed with %v, got %v", want, accessKeys)
+	}
+
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", "")
+	if _, _, err := ecrCredential(context.Background(), a, host); err == nil || !strings.Contains(err.Error(), "no AWS credentials found") {
+		t.Errorf("expected a missing credentials error, got %v", err)
+	}
+}
+
+func TestGCRCredential(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/service-accounts/default/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				http.Error(w, "missing metadata flavor", http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"metadata-token","expires_in":3599,"token_type":"Bearer"}`)
+		case "/token":
+			parts := strings.Split(r.FormValue("assertion"), ".")
+			if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || len(parts) != 3 {
+				http.Error(w, "invalid grant", http.StatusBadRequest)
+				return
+			}
+			signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+				http.Error(w, "invalid assertion", http.StatusUnauthorized)
+				return
+			}
+			claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+			var c struct {
+				Iss, Scope string
+			}
+			_ = json.Unmarshal(claims, &c)
+			fmt.Fprintf(w, `{"access_token":"token-of-%s","expires_in":3599}`, c.Iss)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("CLOUDSDK_CONFIG", t.TempDir())
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(srv.URL, "http://"))
+	a := newTestCloudAuth(srv.Client())
+	cred, expiry, err := gcrCredential(context.Background(), a, "europe-docker.pkg.dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred != (auth.Credential{Username: "oauth2accesstoken", Password: "metadata-token"}) {
+		t.Errorf("unexpected credential %+v", cred)
+	}
+	if time.Until(expiry) < 59*time.Minute {
+		t.Errorf("expected the credential to expire in an hour, got %v", expiry)
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "helm@project.iam.gserviceaccount.com",
+		"private_key_id": "1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})),
+		"token_uri":      srv.URL + "/token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(path, keyFile, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+	cred, _, err = gcrCredential(context.Background(), a, "gcr.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "token-of-helm@project.iam.gserviceaccount.com"; cred.Password != want {
+		t.Errorf("expected the token of the service account %q, got %q", want, cred.Password)
+	}
+}
+
+func TestACRCredential(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tenant-id/oauth2/v2.0/token":
+			if r.FormValue("client_assertion") != "federated-token" || r.FormValue("client_id") != "client-id" {
+				http.Error(w, "invalid client", http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"entra-token","expires_in":3599}`)
+		case "/oauth2/exchange":
+			if r.FormValue("access_token") != "entra-token" || r.FormValue("service") != r.Host || r.FormValue("tenant") != "tenant-id" {
+				http.Error(w, "invalid exchange", http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"refresh_token":"acr-refresh-token"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("federated-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AZURE_CLIENT_ID", "client-id")
+	t.Setenv("AZURE_TENANT_ID", "tenant-id")
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", tokenFile)
+	t.Setenv("AZURE_AUTHORITY_HOST", srv.URL+"/")
+
+	a := newTestCloudAuth(srv.Client())
+	cred, _, err := acrCredential(context.Background(), a, strings.TrimPrefix(srv.URL, "https://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred != (auth.Credential{Username: acrUsername, Password: "acr-refresh-token"}) {
+		t.Errorf("unexpected credential %+v", cred)
+	}
+}
+
+func TestCloudAuthCredential(t *testing.T) {
+	const host = "123456789012.dkr.ecr.us-east-1.amazonaws.com"
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		fmt.Fprintf(w, `{"authorizationData":[{"authorizationToken":%q,"expiresAt":%d}]}`,
+			base64.StdEncoding.EncodeToString([]byte("AWS:ecr-password")), time.Now().Add(time.Hour).Unix())
+	}))
+	defer srv.Close()
+	t.Setenv("AWS_ENDPOINT_URL_ECR", srv.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	stored := auth.Credential{Username: "stored", Password: "password"}
+	credential := newTestCloudAuth(srv.Client()).credential(func(_ context.Context, hostport string) (auth.Credential, error) {
+		if hostport == "stored.example.com" {
+			return stored, nil
+		}
+		return auth.EmptyCredential, nil
+	})
+
+	ctx := context.Background()
+	if cred, err := credential(ctx, "stored.example.com"); err != nil || cred != stored {
+		t.Errorf("expected the stored credential, got %+v, %v", cred, err)
+	}
+	if cred, err := credential(ctx, "ghcr.io"); err != nil || cred != auth.EmptyCredential {
+		t.Errorf("expected no credential for a registry outside of the clouds, got %+v, %v", cred, err)
+	}
+	for range 2 {
+		if cred, err := credential(ctx, host); err != nil || cred.Password != "ecr-password" {
+			t.Errorf("expected the ECR credential, got %+v, %v", cred, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected the ECR credential to be cached, got %d requests", requests)
+	}
+
+	t.Setenv("AWS_ENDPOINT_URL_ECR", srv.URL+"/unreachable\x7f")
+	if cred, err := credential(ctx, "210987654321.dkr.ecr.us-east-1.amazonaws.com"); err != nil || cred != auth.EmptyCredential {
+		t.Errorf("expected no credential when the cloud credentials are unavailable, got %+v, %v", cred, err)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+)
+
+type (
+	// CopyOption allows specifying various settings on copy
+	CopyOption func(*copyOperation)
+
+	// CopyResult is the result returned upon successful copy.
+	CopyResult struct {
+		Ref    string `json:"ref"`
+		Digest string `json:"digest"`
+		// Referrers are the artifacts attached to the chart that were
+		// copied along, such as signatures and SBOMs
+		Referrers []*Referrer `json:"referrers,omitempty"`
+		// Mounted is the number of blobs mounted from the source repository
+		// instead of being transferred
+		Mounted int `json:"mounted"`
+	}
+
+	copyOperation struct {
+		withReferrers bool
+	}
+)
+
+// CopyOptWithReferrers returns a function that sets whether the artifacts
+// attached to the chart are copied along
+func CopyOptWithReferrers(withReferrers bool) CopyOption {
+	return func(operation *copyOperation) {
+		operation.withReferrers = withReferrers
+	}
+}
+
+// Copy copies the chart at src to dst, along with the artifacts attached to
+// it, without storing it locally. The chart is streamed from the source
+// registry to the destination, unless both are the same registry, in which
+// case its blobs are mounted across repositories when the registry supports
+// it. The destination defaults to the tag of the source.
+func (c *Client) Copy(src, dst string, options ...CopyOption) (*CopyResult, error) {
+	operation := &copyOperation{
+		withReferrers: true,
+	}
+	for _, option := range options {
+		option(operation)
+	}
+
+	srcRef, err := newReference(src)
+	if err != nil {
+		return nil, err
+	}
+	if srcRef.Tag == "" && srcRef.Digest == "" {
+		return nil, fmt.Errorf("%s: the version of the chart to copy is required", src)
+	}
+	dstRef, err := newReference(dst)
+	if err != nil {
+		return nil, err
+	}
+	if dstRef.Digest != "" {
+		return nil, fmt.Errorf("%s: the destination of a copy cannot be a digest", dst)
+	}
+	if dstRef.Tag == "" {
+		if srcRef.Tag == "" {
+			return nil, fmt.Errorf("%s: the destination of a copy by digest must have a tag", dst)
+		}
+		dstRef.Tag = srcRef.Tag
+		dstRef.orasReference.Reference = srcRef.Tag
+	}
+
+	if _, err := c.ChartMetadata(srcRef.String()); err != nil {
+		return nil, err
+	}
+	srcRepository, err := c.remoteRepository(srcRef.String())
+	if err != nil {
+		return nil, err
+	}
+	dstRepository, err := c.remoteRepository(dstRef.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	result := &CopyResult{Ref: dstRef.String()}
+	// Referrers already in the destination are skipped, but still reported
+	addReferrer := func(_ context.Context, desc ocispec.Descriptor) error {
+		if desc.ArtifactType != "" {
+			mu.Lock()
+			result.Referrers = append(result.Referrers, &Referrer{ArtifactType: desc.ArtifactType, Digest: desc.Digest.String()})
+			mu.Unlock()
+		}
+		return nil
+	}
+	graphOptions := oras.CopyGraphOptions{
+		PostCopy:      addReferrer,
+		OnCopySkipped: addReferrer,
+	}
+	if srcRef.Registry == dstRef.Registry && srcRef.Repository != dstRef.Repository {
+		graphOptions.MountFrom = func(context.Context, ocispec.Descriptor) ([]string, error) {
+			return []string{srcRef.Repository}, nil
+		}
+		graphOptions.OnMounted = func(context.Context, ocispec.Descriptor) error {
+			mu.Lock()
+			result.Mounted++
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	ctx := context.Background()
+	var desc ocispec.Descriptor
+	if operation.withReferrers {
+		desc, err = oras.ExtendedCopy(ctx, srcRepository, srcRef.String(), dstRepository, dstRef.Tag, oras.ExtendedCopyOptions{
+			ExtendedCopyGraphOptions: oras.ExtendedCopyGraphOptions{CopyGraphOptions: graphOptions},
+		})
+	} else {
+		desc, err = oras.Copy(ctx, srcRepository, srcRef.String(), dstRepository, dstRef.Tag, oras.CopyOptions{
+			CopyGraphOptions: graphOptions,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to copy %s to %s: %w", srcRef.String(), dstRef.String(), err)
+	}
+	if desc.MediaType != ocispec.MediaTypeImageManifest {
+		return nil, errors.New("unexpected media type of the copied chart")
+	}
+
+	result.Digest = desc.Digest.String()
+	referrers := result.Referrers[:0]
+	for _, referrer := range result.Referrers {
+		if referrer.Digest != result.Digest {
+			referrers = append(referrers, referrer)
+		}
+	}
+	sort.Slice(referrers, func(i, j int) bool {
+		if referrers[i].ArtifactType != referrers[j].ArtifactType {
+			return referrers[i].ArtifactType < referrers[j].ArtifactType
+		}
+		return referrers[i].Digest < referrers[j].Digest
+	})
+	result.Referrers = referrers
+
+	_, _ = fmt.Fprintf(c.out, "Copied: %s to %s\n", srcRef.String(), result.Ref)
+	_, _ = fmt.Fprintf(c.out, "Digest: %s\n", result.Digest)
+	for _, referrer := range result.Referrers {
+		_, _ = fmt.Fprintf(c.out, "Copied referrer: %s %s\n", referrer.ArtifactType, referrer.Digest)
+	}
+	if result.Mounted > 0 {
+		_, _ = fmt.Fprintf(c.out, "Mounted: %d blobs from %s\n", result.Mounted, srcRef.Repository)
+	}
+	return result, nil
+}
@@ -28,7 +28,6 @@ import (
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/memory"
-	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 	"oras.land/oras-go/v2/registry/remote/credentials"
 )
@@ -46,6 +45,7 @@ type GenericClient struct {
 	credentialsStore   credentials.Store
 	httpClient         *http.Client
 	plainHTTP          bool
+	hosts              *registryHosts
 }
 
 // GenericPullOptions configures a generic pull operation
@@ -80,6 +80,7 @@ func NewGenericClient(client *Client) *GenericClient {
 		credentialsStore:   client.credentialsStore,
 		httpClient:         client.httpClient,
 		plainHTTP:          client.plainHTTP,
+		hosts:              client.hosts,
 	}
 }
 
@@ -94,12 +95,10 @@ func (c *GenericClient) PullGeneric(ref string, options GenericPullOptions) (*Ge
 	var descriptors []ocispec.Descriptor
 
 	// Set up a repository with authentication and configuration
-	repository, err := remote.NewRepository(parsedRef.String())
+	repository, err := c.hosts.repository(parsedRef.String(), c.authorizer, c.plainHTTP)
 	if err != nil {
 		return nil, err
 	}
-	repository.PlainHTTP = c.plainHTTP
-	repository.Client = c.authorizer
 
 	ctx := context.Background()
 
@@ -0,0 +1,192 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry/remote"
+)
+
+const (
+	// SPDXArtifactType is the artifact type of SPDX JSON SBOMs
+	SPDXArtifactType = "application/spdx+json"
+
+	// CycloneDXArtifactType is the artifact type of CycloneDX JSON SBOMs
+	CycloneDXArtifactType = "application/vnd.cyclonedx+json"
+)
+
+// Referrer is an artifact attached to a chart manifest with the OCI referrers
+// API, such as a provenance file, an SBOM or an attestation. It is stored as a
+// manifest whose subject is the chart manifest and whose single layer holds
+// Data with ArtifactType as media type.
+type Referrer struct {
+	ArtifactType string `json:"artifactType"`
+	Digest       string `json:"digest"`
+	Data         []byte `json:"-"`
+}
+
+// SBOMArtifactType detects the artifact type of an SBOM from its content.
+// SPDX and CycloneDX JSON documents are supported.
+func SBOMArtifactType(data []byte) (string, error) {
+	var doc struct {
+		SPDXVersion string `json:"spdxVersion"`
+		BOMFormat   string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("unable to parse SBOM: %w", err)
+	}
+	switch {
+	case doc.SPDXVersion != "":
+		return SPDXArtifactType, nil
+	case doc.BOMFormat == "CycloneDX":
+		return CycloneDXArtifactType, nil
+	}
+	return "", errors.New("unknown SBOM format: only SPDX and CycloneDX JSON documents are supported")
+}
+
+// PushOptReferrers returns a function that attaches referrers to the pushed chart
+func PushOptReferrers(referrers ...Referrer) PushOption {
+	return func(operation *pushOperation) {
+		operation.referrers = append(operation.referrers, referrers...)
+	}
+}
+
+// PushOptProvAsReferrer returns a function that sets whether the prov data is
+// attached as a referrer of the chart manifest rather than stored as a layer
+func PushOptProvAsReferrer(provAsReferrer bool) PushOption {
+	return func(operation *pushOperation) {
+		operation.provAsReferrer = provAsReferrer
+	}
+}
+
+// PullOptWithReferrers returns a function that sets whether all the referrers
+// of the chart manifest are fetched and validated on pull
+func PullOptWithReferrers(withReferrers bool) PullOption {
+	return func(operation *pullOperation) {
+		operation.withReferrers = withReferrers
+	}
+}
+
+// Referrers fetches the artifacts attached to the manifest at ref. Only the
+// artifacts of the given type are returned, unless artifactType is empty.
+func (c *Client) Referrers(ref, artifactType string) ([]*Referrer, error) {
+	parsedRef, err := newReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	return readMirrored(c.hosts, parsedRef.String(), func(ref string) ([]*Referrer, error) {
+		repository, err := c.remoteRepository(ref)
+		if err != nil {
+			return nil, err
+		}
+		subject, err := repository.Resolve(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		return fetchReferrers(ctx, repository, subject, artifactType)
+	})
+}
+
+// remoteRepository returns the repository of ref, configured for the client.
+func (c *Client) remoteRepository(ref string) (*remote.Repository, error) {
+	return c.hosts.repository(ref, c.authorizer, c.plainHTTP)
+}
+
+// packReferrers stores the referrers in memoryStore as manifests of subject,
+// so that copying subject with oras.ExtendedCopy pushes them along.
+func packReferrers(ctx context.Context, memoryStore *memory.Store, subject ocispec.Descriptor, referrers []Referrer) ([]ocispec.Descriptor, error) {
+	descriptors := make([]ocispec.Descriptor, 0, len(referrers))
+	for _, referrer := range referrers {
+		if referrer.ArtifactType == "" {
+			return nil, errors.New("referrers must have an artifact type")
+		}
+		layer, err := oras.PushBytes(ctx, memoryStore, referrer.ArtifactType, referrer.Data)
+		if err != nil {
+			return nil, err
+		}
+		desc, err := oras.PackManifest(ctx, memoryStore, oras.PackManifestVersion1_1, referrer.ArtifactType,
+			oras.PackManifestOptions{
+				Subject: &subject,
+				Layers:  []ocispec.Descriptor{layer},
+			})
+		if err != nil {
+			return nil, err
+		}
+		descriptors = append(descriptors, desc)
+	}
+	return descriptors, nil
+}
+
+// fetchReferrers fetches the referrers of subject and checks that they are
+// well-formed. Their content is verified against its digest as it is fetched.
+func fetchReferrers(ctx context.Context, repository *remote.Repository, subject ocispec.Descriptor, artifactType string) ([]*Referrer, error) {
+	var descriptors []ocispec.Descriptor
+	err := repository.Referrers(ctx, subject, artifactType, func(referrers []ocispec.Descriptor) error {
+		descriptors = append(descriptors, referrers...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the referrers of %s: %w", subject.Digest, err)
+	}
+
+	referrers := make([]*Referrer, 0, len(descriptors))
+	for _, desc := range descriptors {
+		manifestData, err := content.FetchAll(ctx, repository, desc)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch referrer %s: %w", desc.Digest, err)
+		}
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(manifestData, &manifest); err != nil {
+			return nil, fmt.Errorf("unable to parse referrer %s: %w", desc.Digest, err)
+		}
+		if manifest.Subject == nil || manifest.Subject.Digest != subject.Digest {
+			return nil, fmt.Errorf("referrer %s does not refer to %s", desc.Digest, subject.Digest)
+		}
+		if len(manifest.Layers) != 1 {
+			return nil, fmt.Errorf("referrer %s has %d layers, expected 1", desc.Digest, len(manifest.Layers))
+		}
+		data, err := content.FetchAll(ctx, repository, manifest.Layers[0])
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch referrer %s: %w", desc.Digest, err)
+		}
+		referrers = append(referrers, &Referrer{
+			ArtifactType: manifest.ArtifactType,
+			Digest:       desc.Digest.String(),
+			Data:         data,
+		})
+	}
+	return referrers, nil
+}
+
+// provReferrer returns the data of the first provenance referrer, if any.
+func provReferrer(referrers []*Referrer) []byte {
+	for _, referrer := range referrers {
+		if referrer.ArtifactType == ProvLayerMediaType {
+			return referrer.Data
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSBOMArtifactType(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		expect string
+		err    string
+	}{
+		{
+			name:   "spdx",
+			data:   `{"spdxVersion":"SPDX-2.3","SPDXID":"SPDXRef-DOCUMENT"}`,
+			expect: SPDXArtifactType,
+		},
+		{
+			name:   "cyclonedx",
+			data:   `{"bomFormat":"CycloneDX","specVersion":"1.5"}`,
+			expect: CycloneDXArtifactType,
+		},
+		{
+			name: "unknown format",
+			data: `{"name":"sbom"}`,
+			err:  "unknown SBOM format",
+		},
+		{
+			name: "not json",
+			data: "SPDXVersion: SPDX-2.3",
+			err:  "unable to parse SBOM",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifactType, err := SBOMArtifactType([]byte(tt.data))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if artifactType != tt.expect {
+				t.Errorf("expected %s, got %s", tt.expect, artifactType)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+	"oras.land/oras-go/v2/registry/remote/retry"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/internal/tlsutil"
+	"helm.sh/helm/v4/internal/version"
+)
+
+// RegistriesConfig configures how registries are reached, in the format of
+// the registries.yaml file of containerd-based Kubernetes distributions:
+//
+//	mirrors:
+//	  docker.io:
+//	    endpoint:
+//	      - https://mirror.example.com/dockerhub
+//	configs:
+//	  mirror.example.com:
+//	    auth:
+//	      username: helm
+//	      password: secret
+//	    tls:
+//	      ca_file: /etc/ssl/mirror-ca.pem
+//	  123456789012.dkr.ecr.us-east-1.amazonaws.com:
+//	    auth:
+//	      helper: ecr-login
+type RegistriesConfig struct {
+	// Mirrors maps registry hosts to the mirrors serving their repositories.
+	// The "*" host applies to the registries without mirrors of their own.
+	Mirrors map[string]RegistryMirror `json:"mirrors,omitempty"`
+	// Configs maps registry hosts, mirrors included, to their connection
+	// settings.
+	Configs map[string]RegistryConfig `json:"configs,omitempty"`
+}
+
+// RegistryMirror lists the mirrors of a registry.
+type RegistryMirror struct {
+	// Endpoints are tried in order before the registry itself. An endpoint is
+	// a host, with an optional http:// or https:// scheme, followed by an
+	// optional path under which the repositories of the registry are served.
+	Endpoints []string `json:"endpoint"`
+}
+
+// RegistryConfig holds the connection settings of a registry.
+type RegistryConfig struct {
+	Auth *RegistryAuthConfig `json:"auth,omitempty"`
+	TLS  *RegistryTLSConfig  `json:"tls,omitempty"`
+}
+
+// RegistryAuthConfig holds the credentials of a registry, used instead of
+// those of the credentials file.
+type RegistryAuthConfig struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Helper is the name of the Docker credential helper, such as
+	// "ecr-login" for docker-credential-ecr-login, giving the credentials.
+	Helper string `json:"helper,omitempty"`
+}
+
+// RegistryTLSConfig holds the TLS client settings of a registry.
+type RegistryTLSConfig struct {
+	CertFile           string `json:"cert_file,omitempty"`
+	KeyFile            string `json:"key_file,omitempty"`
+	CAFile             string `json:"ca_file,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+}
+
+// LoadRegistriesConfig reads the registries file at path. A missing file is
+// an empty configuration.
+func LoadRegistriesConfig(path string) (*RegistriesConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &RegistriesConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var config RegistriesConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	for host, mirror := range config.Mirrors {
+		for _, endpoint := range mirror.Endpoints {
+			if _, err := parseMirrorEndpoint(endpoint); err != nil {
+				return nil, fmt.Errorf("%s: invalid mirror of %s: %w", path, host, err)
+			}
+		}
+	}
+	for host, hostConfig := range config.Configs {
+		if hostConfig.Auth != nil && hostConfig.Auth.Helper != "" && hostConfig.Auth.Username != "" {
+			return nil, fmt.Errorf("%s: the auth of %s has both a username and a credential helper", path, host)
+		}
+	}
+	return &config, nil
+}
+
+// ClientOptRegistriesConfig returns a function that sets the mirrors and
+// the per-registry settings of the client.
+func ClientOptRegistriesConfig(config *RegistriesConfig) ClientOption {
+	return func(client *Client) {
+		client.registriesConfig = config
+	}
+}
+
+// mirrorEndpoint is a registry serving the repositories of another under
+// a path prefix.
+type mirrorEndpoint struct {
+	host      string
+	prefix    string
+	plainHTTP bool
+}
+
+func parseMirrorEndpoint(endpoint string) (mirrorEndpoint, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return mirrorEndpoint{}, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return mirrorEndpoint{}, fmt.Errorf("%s: unsupported scheme %q", endpoint, u.Scheme)
+	}
+	if u.Host == "" {
+		return mirrorEndpoint{}, fmt.Errorf("%s: missing host", endpoint)
+	}
+	return mirrorEndpoint{
+		host:      u.Host,
+		prefix:    strings.Trim(u.Path, "/"),
+		plainHTTP: u.Scheme == "http",
+	}, nil
+}
+
+// registryHosts resolves the mirrors and the connection settings of
+// registries from a RegistriesConfig.
+type registryHosts struct {
+	mirrors   map[string][]mirrorEndpoint
+	clients   map[string]*auth.Client
+	plainHTTP map[string]bool
+}
+
+// newRegistryHosts builds the clients of the registries configured in config,
+// falling back to the settings of client.
+func newRegistryHosts(config *RegistriesConfig, client *Client) (*registryHosts, error) {
+	hosts := &registryHosts{
+		mirrors:   map[string][]mirrorEndpoint{},
+		clients:   map[string]*auth.Client{},
+		plainHTTP: map[string]bool{},
+	}
+	for host, mirror := range config.Mirrors {
+		for _, endpoint := range mirror.Endpoints {
+			e, err := parseMirrorEndpoint(endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mirror of %s: %w", host, err)
+			}
+			hosts.mirrors[host] = append(hosts.mirrors[host], e)
+			if e.plainHTTP {
+				hosts.plainHTTP[e.host] = true
+			}
+		}
+	}
+	for host, hostConfig := range config.Configs {
+		authorizer := &auth.Client{
+			Client:     client.httpClient,
+			Credential: client.authorizer.Credential,
+		}
+		authorizer.SetUserAgent(version.GetUserAgent())
+		if client.enableCache {
+			authorizer.Cache = auth.NewCache()
+		}
+		switch {
+		case hostConfig.Auth != nil && hostConfig.Auth.Helper != "":
+			authorizer.Credential = credentials.Credential(credentials.NewNativeStore(hostConfig.Auth.Helper))
+		case hostConfig.Auth != nil:
+			cred := auth.Credential{Username: hostConfig.Auth.Username, Password: hostConfig.Auth.Password}
+			authorizer.Credential = auth.StaticCredential(host, cred)
+		}
+		if hostConfig.TLS != nil {
+			tlsConf, err := tlsutil.NewTLSConfig(
+				tlsutil.WithInsecureSkipVerify(hostConfig.TLS.InsecureSkipVerify),
+				tlsutil.WithCertKeyPairFiles(hostConfig.TLS.CertFile, hostConfig.TLS.KeyFile),
+				tlsutil.WithCAFile(hostConfig.TLS.CAFile),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("can't create TLS config for %s: %w", host, err)
+			}
+			transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+			if t, ok := http.DefaultTransport.(*http.Transport); ok {
+				transport = t.Clone()
+			}
+			transport.TLSClientConfig = tlsConf
+			var roundTripper http.RoundTripper = transport
+			if client.debug {
+				roundTripper = &LoggingTransport{RoundTripper: roundTripper}
+			}
+			authorizer.Client = &http.Client{Transport: retry.NewTransport(roundTripper)}
+		}
+		hosts.clients[host] = authorizer
+	}
+	return hosts, nil
+}
+
+// client returns the client and the plain HTTP setting of host, defaulting
+// to authorizer and plainHTTP.
+func (h *registryHosts) client(host string, authorizer *auth.Client, plainHTTP bool) (*auth.Client, bool) {
+	if h == nil {
+		return authorizer, plainHTTP
+	}
+	if hostClient, ok := h.clients[host]; ok {
+		authorizer = hostClient
+	}
+	return authorizer, plainHTTP || h.plainHTTP[host]
+}
+
+// repository returns the remote repository of ref, configured for its host.
+func (h *registryHosts) repository(ref string, authorizer *auth.Client, plainHTTP bool) (*remote.Repository, error) {
+	repository, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, err
+	}
+	repository.Client, repository.PlainHTTP = h.client(repository.Reference.Registry, authorizer, plainHTTP)
+	return repository, nil
+}
+
+// mirrorRefs returns the references ref is read from, in order: ref on each
+// of the mirrors of its registry, then ref itself.
+func (h *registryHosts) mirrorRefs(ref string) []string {
+	if h == nil {
+		return []string{ref}
+	}
+	parsed, err := registry.ParseReference(ref)
+	if err != nil {
+		return []string{ref}
+	}
+	endpoints, ok := h.mirrors[parsed.Registry]
+	if !ok {
+		endpoints = h.mirrors["*"]
+	}
+	refs := make([]string, 0, len(endpoints)+1)
+	for _, endpoint := range endpoints {
+		if endpoint.host == parsed.Registry {
+			continue
+		}
+		mirrored := parsed
+		mirrored.Registry = endpoint.host
+		mirrored.Repository = path.Join(endpoint.prefix, parsed.Repository)
+		refs = append(refs, mirrored.String())
+	}
+	return append(refs, ref)
+}
+
+// readMirrored reads ref from the first of its mirrors that serves it, or
+// else from its registry.
+func readMirrored[T any](h *registryHosts, ref string, read func(ref string) (T, error)) (T, error) {
+	refs := h.mirrorRefs(ref)
+	for _, mirrorRef := range refs[:len(refs)-1] {
+		result, err := read(mirrorRef)
+		if err == nil {
+			return result, nil
+		}
+		slog.Debug("unable to read from registry mirror", "ref", mirrorRef, "error", err)
+	}
+	return read(ref)
+}
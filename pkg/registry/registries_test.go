@@ -0,0 +1,168 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadRegistriesConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	config, err := LoadRegistriesConfig(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Mirrors) != 0 || len(config.Configs) != 0 {
+		t.Errorf("expected an empty config for a missing file, got %+v", config)
+	}
+
+	config, err = LoadRegistriesConfig(write("registries.yaml", `
+mirrors:
+  docker.io:
+    endpoint:
+      - http://mirror.example.com:5000/dockerhub
+configs:
+  mirror.example.com:5000:
+    auth:
+      username: helm
+      password: secret
+    tls:
+      insecure_skip_verify: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := config.Mirrors["docker.io"].Endpoints; !reflect.DeepEqual(got, []string{"http://mirror.example.com:5000/dockerhub"}) {
+		t.Errorf("unexpected endpoints %v", got)
+	}
+	hostConfig := config.Configs["mirror.example.com:5000"]
+	if hostConfig.Auth == nil || hostConfig.Auth.Username != "helm" || hostConfig.TLS == nil || !hostConfig.TLS.InsecureSkipVerify {
+		t.Errorf("unexpected registry config %+v", hostConfig)
+	}
+
+	for name, data := range map[string]string{
+		"unsupported scheme":  "mirrors:\n  docker.io:\n    endpoint: [ftp://mirror.example.com]\n",
+		"unknown field":       "mirror:\n  docker.io: {}\n",
+		"helper and username": "configs:\n  ghcr.io:\n    auth: {username: helm, helper: pass}\n",
+	} {
+		if _, err := LoadRegistriesConfig(write("invalid.yaml", data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestMirrorRefs(t *testing.T) {
+	hosts, err := newRegistryHosts(&RegistriesConfig{
+		Mirrors: map[string]RegistryMirror{
+			"docker.io": {Endpoints: []string{"https://mirror.example.com/dockerhub/", "http://localhost:5000"}},
+			"*":         {Endpoints: []string{"cache.example.com"}},
+		},
+	}, &Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ref  string
+		want []string
+	}{
+		{
+			ref: "docker.io/bitnamicharts/nginx:1.0.0",
+			want: []string{
+				"mirror.example.com/dockerhub/bitnamicharts/nginx:1.0.0",
+				"localhost:5000/bitnamicharts/nginx:1.0.0",
+				"docker.io/bitnamicharts/nginx:1.0.0",
+			},
+		},
+		{
+			ref:  "ghcr.io/org/chart@sha256:" + strings.Repeat("a", 64),
+			want: []string{"cache.example.com/org/chart@sha256:" + strings.Repeat("a", 64), "ghcr.io/org/chart@sha256:" + strings.Repeat("a", 64)},
+		},
+		{
+			ref:  "cache.example.com/org/chart:1.0.0",
+			want: []string{"cache.example.com/org/chart:1.0.0"},
+		},
+		{
+			ref:  "not a reference",
+			want: []string{"not a reference"},
+		},
+	}
+	for _, tt := range tests {
+		if got := hosts.mirrorRefs(tt.ref); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mirrorRefs(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+	if client, plainHTTP := hosts.client("localhost:5000", nil, false); client != nil || !plainHTTP {
+		t.Errorf("expected plain HTTP for an http:// mirror")
+	}
+
+	var nilHosts *registryHosts
+	if got := nilHosts.mirrorRefs("docker.io/org/chart:1.0.0"); !reflect.DeepEqual(got, []string{"docker.io/org/chart:1.0.0"}) {
+		t.Errorf("expected no mirrors without a config, got %v", got)
+	}
+}
+
+func TestReadMirrored(t *testing.T) {
+	hosts, err := newRegistryHosts(&RegistriesConfig{
+		Mirrors: map[string]RegistryMirror{
+			"docker.io": {Endpoints: []string{"down.example.com", "up.example.com"}},
+		},
+	}, &Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tried []string
+	read := func(ref string) (string, error) {
+		tried = append(tried, ref)
+		if strings.HasPrefix(ref, "down.example.com/") {
+			return "", errors.New("unavailable")
+		}
+		return ref, nil
+	}
+	got, err := readMirrored(hosts, "docker.io/org/chart:1.0.0", read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "up.example.com/org/chart:1.0.0" {
+		t.Errorf("expected to read from the second mirror, got %s", got)
+	}
+	if len(tried) != 2 {
+		t.Errorf("expected two attempts, got %v", tried)
+	}
+
+	_, err = readMirrored(hosts, "docker.io/org/chart:1.0.0", func(ref string) (string, error) {
+		return "", errors.New("unavailable: " + ref)
+	})
+	if err == nil || err.Error() != "unavailable: docker.io/org/chart:1.0.0" {
+		t.Errorf("expected the error of the registry itself, got %v", err)
+	}
+}
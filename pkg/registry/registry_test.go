@@ -18,6 +18,7 @@ package registry
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
@@ -38,6 +39,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/crypto/bcrypt"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry/remote"
 
 	"helm.sh/helm/v4/internal/tlsutil"
 )
@@ -138,6 +142,7 @@ func setup(suite *TestRegistry, tlsEnabled, insecure bool) {
 	config.HTTP.Addr = ln.Addr().String()
 	config.HTTP.DrainTimeout = time.Duration(10) * time.Second
 	config.Storage = map[string]configuration.Parameters{"inmemory": map[string]any{}}
+	config.Catalog.MaxEntries = 1000
 
 	config.Auth = configuration.Auth{
 		"htpasswd": configuration.Parameters{
@@ -557,3 +562,125 @@ func testTags(suite *TestRegistry) {
 	suite.Require().NoError(err, "no error retrieving tags")
 	suite.Len(tags, 1)
 }
+
+func testRepositories(suite *TestRegistry) {
+	repositories, err := suite.RegistryClient.Repositories(suite.DockerRegistryHost)
+	suite.Require().NoError(err, "no error listing repositories")
+	suite.Contains(repositories, "testrepo/local-subchart")
+	suite.Contains(repositories, "testrepo/signtest")
+	suite.IsIncreasing(repositories)
+
+	// Namespace filter, with the oci:// prefix
+	repositories, err = suite.RegistryClient.Repositories(fmt.Sprintf("oci://%s/testrepo/", suite.DockerRegistryHost))
+	suite.Require().NoError(err, "no error listing repositories of a namespace")
+	suite.Contains(repositories, "testrepo/local-subchart")
+	repositories, err = suite.RegistryClient.Repositories(suite.DockerRegistryHost + "/test")
+	suite.Require().NoError(err, "no error listing repositories of a namespace")
+	suite.Empty(repositories)
+}
+
+func testChartMetadata(suite *TestRegistry) {
+	meta, err := suite.RegistryClient.ChartMetadata(suite.DockerRegistryHost + "/testrepo/signtest:0.1.0")
+	suite.Require().NoError(err, "no error fetching chart metadata")
+	suite.Equal("signtest", meta.Name)
+	suite.Equal("0.1.0", meta.Version)
+	suite.Equal("A Helm chart for Kubernetes", meta.Description)
+
+	_, err = suite.RegistryClient.ChartMetadata(suite.DockerRegistryHost + "/testrepo/values:1.0.0")
+	suite.Require().ErrorIs(err, ErrNotChart)
+
+	_, err = suite.RegistryClient.ChartMetadata(suite.DockerRegistryHost + "/testrepo/no-existy:1.2.3")
+	suite.Require().Error(err, "error on bad/missing ref")
+}
+
+func testReferrers(suite *TestRegistry) {
+	chartData, err := os.ReadFile("../downloader/testdata/signtest-0.1.0.tgz")
+	suite.Require().NoError(err, "no error loading test chart")
+	provData, err := os.ReadFile("../downloader/testdata/signtest-0.1.0.tgz.prov")
+	suite.Require().NoError(err, "no error loading test prov")
+	sbom := []byte(`{"spdxVersion":"SPDX-2.3","name":"signtest"}`)
+	sbomType, err := SBOMArtifactType(sbom)
+	suite.Require().NoError(err)
+
+	// push with the prov and an SBOM attached as referrers
+	ref := suite.DockerRegistryHost + "/testrepo/referrers/signtest:0.1.0"
+	pushResult, err := suite.RegistryClient.Push(chartData, ref,
+		PushOptProvData(provData),
+		PushOptProvAsReferrer(true),
+		PushOptReferrers(Referrer{ArtifactType: sbomType, Data: sbom}))
+	suite.Require().NoError(err, "no error pushing with referrers")
+	suite.Require().Len(pushResult.Referrers, 2)
+	suite.Equal(ProvLayerMediaType, pushResult.Referrers[0].ArtifactType)
+	suite.Equal(SPDXArtifactType, pushResult.Referrers[1].ArtifactType)
+	suite.Empty(pushResult.Prov.Digest, "prov is not a layer of the chart manifest")
+
+	// the prov is found among the referrers
+	result, err := suite.RegistryClient.Pull(ref, PullOptWithChart(false), PullOptWithProv(true))
+	suite.Require().NoError(err, "no error pulling the prov from the referrers")
+	suite.Equal(provData, result.Prov.Data)
+	suite.Empty(result.Referrers, "referrers are only returned when requested")
+
+	result, err = suite.RegistryClient.Pull(ref, PullOptWithReferrers(true))
+	suite.Require().NoError(err, "no error pulling with referrers")
+	suite.Len(result.Referrers, 2)
+
+	referrers, err := suite.RegistryClient.Referrers(ref, SPDXArtifactType)
+	suite.Require().NoError(err, "no error listing referrers")
+	suite.Require().Len(referrers, 1)
+	suite.Equal(sbom, referrers[0].Data)
+	suite.Equal(pushResult.Referrers[1].Digest, referrers[0].Digest)
+
+	// a chart without prov nor referrers
+	ref = fmt.Sprintf("%s/testrepo/local-subchart:0.1.0", suite.DockerRegistryHost)
+	_, err = suite.RegistryClient.Pull(ref, PullOptWithProv(true))
+	suite.Require().ErrorContains(err, "manifest does not contain a layer with mediatype")
+}
+
+// pushValues pushes an artifact with a single layer holding data.
+func pushValues(suite *TestRegistry, ref, artifactType, mediaType string, data []byte) {
+	ctx := context.Background()
+	memoryStore := memory.New()
+	layer, err := oras.PushBytes(ctx, memoryStore, mediaType, data)
+	suite.Require().NoError(err)
+	manifest, err := oras.PackManifest(ctx, memoryStore, oras.PackManifestVersion1_1, artifactType,
+		oras.PackManifestOptions{Layers: []ocispec.Descriptor{layer}})
+	suite.Require().NoError(err)
+
+	parsedRef, err := newReference(ref)
+	suite.Require().NoError(err)
+	suite.Require().NoError(memoryStore.Tag(ctx, manifest, parsedRef.String()))
+	repository, err := remote.NewRepository(parsedRef.String())
+	suite.Require().NoError(err)
+	repository.PlainHTTP = suite.RegistryClient.plainHTTP
+	repository.Client = suite.RegistryClient.authorizer
+	_, err = oras.Copy(ctx, memoryStore, parsedRef.String(), repository, parsedRef.String(), oras.DefaultCopyOptions)
+	suite.Require().NoError(err)
+}
+
+func testPullValues(suite *TestRegistry) {
+	data := []byte("replicaCount: 3\n")
+
+	// Values artifact
+	ref := suite.DockerRegistryHost + "/testrepo/values:1.0.0"
+	pushValues(suite, ref, ValuesArtifactType, ValuesLayerMediaType, data)
+	result, err := suite.RegistryClient.PullValues(ref)
+	suite.Require().NoError(err, "no error pulling values")
+	suite.Equal(ref, result.Ref)
+	suite.Equal(data, result.Data)
+
+	// Artifact pushed with the oras CLI
+	ref = suite.DockerRegistryHost + "/testrepo/oras-values:1.0.0"
+	pushValues(suite, ref, "application/vnd.unknown.artifact.v1", "application/vnd.oci.image.layer.v1.tar", data)
+	result, err = suite.RegistryClient.PullValues(ref)
+	suite.Require().NoError(err, "no error pulling values pushed with oras")
+	suite.Equal(data, result.Data)
+
+	// Chart
+	chartData, err := os.ReadFile("../downloader/testdata/local-subchart-0.1.0.tgz")
+	suite.Require().NoError(err, "no error loading test chart")
+	meta, err := extractChartMeta(chartData)
+	suite.Require().NoError(err, "no error extracting chart meta")
+	ref = fmt.Sprintf("%s/testrepo/%s:%s", suite.DockerRegistryHost, meta.Name, meta.Version)
+	_, err = suite.RegistryClient.PullValues(ref)
+	suite.Require().ErrorContains(err, "the artifact is a chart, not a values file")
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Values-specific constants
+const (
+	// ValuesArtifactType is the artifact type for Helm values files
+	ValuesArtifactType = "application/vnd.cncf.helm.values.v1"
+
+	// ValuesLayerMediaType is the reserved media type for Helm values file content
+	ValuesLayerMediaType = "application/vnd.cncf.helm.values.v1+yaml"
+
+	// genericLayerMediaType is the media type of the files pushed with the
+	// oras CLI when no media type is given
+	genericLayerMediaType = "application/vnd.oci.image.layer.v1.tar"
+)
+
+// ValuesPullResult contains the result of a values pull operation
+type ValuesPullResult struct {
+	Manifest ocispec.Descriptor
+	Data     []byte
+	Ref      string
+}
+
+// PullValues downloads a values file from an OCI registry.
+//
+// The values file is the layer with the ValuesLayerMediaType media type or,
+// for artifacts pushed with generic tools such as the oras CLI, the only layer
+// of the artifact.
+func (c *Client) PullValues(ref string) (*ValuesPullResult, error) {
+	genericClient := c.Generic()
+	genericResult, err := genericClient.PullGeneric(ref, GenericPullOptions{
+		AllowedMediaTypes: []string{
+			ocispec.MediaTypeImageManifest,
+			ValuesLayerMediaType,
+			genericLayerMediaType,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manifestData, err := genericClient.GetDescriptorData(genericResult.MemoryStore, genericResult.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve manifest: %w", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse manifest: %w", err)
+	}
+
+	layer, err := valuesLayer(manifest)
+	if err != nil {
+		return nil, err
+	}
+	data, err := genericClient.GetDescriptorData(genericResult.MemoryStore, layer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve values data with digest %s: %w", layer.Digest, err)
+	}
+
+	return &ValuesPullResult{
+		Manifest: genericResult.Manifest,
+		Data:     data,
+		Ref:      genericResult.Ref,
+	}, nil
+}
+
+// valuesLayer returns the layer of a manifest holding the values file.
+func valuesLayer(manifest ocispec.Manifest) (ocispec.Descriptor, error) {
+	if manifest.Config.MediaType == ConfigMediaType {
+		return ocispec.Descriptor{}, errors.New("the artifact is a chart, not a values file")
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == ValuesLayerMediaType {
+			return layer, nil
+		}
+	}
+	if len(manifest.Layers) == 1 && manifest.Layers[0].MediaType == genericLayerMediaType {
+		return manifest.Layers[0], nil
+	}
+	return ocispec.Descriptor{}, fmt.Errorf("required layer with media type %s not found in manifest", ValuesLayerMediaType)
+}
@@ -21,4 +21,34 @@ type UninstallReleaseResponse struct {
 	Release Releaser `json:"release,omitempty"`
 	// Info is an uninstall message
 	Info string `json:"info,omitempty"`
+	// Resources lists what a dry-run uninstall would do with each resource of
+	// the release.
+	Resources []UninstallResource `json:"resources,omitempty"`
+}
+
+// UninstallResourceAction is what an uninstall does with a resource.
+type UninstallResourceAction string
+
+const (
+	// UninstallDelete deletes the resource.
+	UninstallDelete UninstallResourceAction = "delete"
+	// UninstallKeep keeps the resource because of its resource policy.
+	UninstallKeep UninstallResourceAction = "keep"
+	// UninstallSkip leaves the resource untouched because it is not owned by
+	// the release, or its ownership could not be verified.
+	UninstallSkip UninstallResourceAction = "skip"
+)
+
+// UninstallResource describes what an uninstall does with a resource of a
+// release.
+type UninstallResource struct {
+	Kind      string                  `json:"kind"`
+	Name      string                  `json:"name"`
+	Namespace string                  `json:"namespace,omitempty"`
+	Action    UninstallResourceAction `json:"action"`
+	// Cascade is the deletion propagation policy of deleted resources. The
+	// dependents of resources deleted with the "orphan" policy are orphaned.
+	Cascade string `json:"cascade,omitempty"`
+	// Reason explains why a resource is kept or skipped.
+	Reason string `json:"reason,omitempty"`
 }
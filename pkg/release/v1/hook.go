@@ -62,6 +62,22 @@ const (
 
 func (x HookOutputLogPolicy) String() string { return string(x) }
 
+// HookUpgradePolicy specifies how the resources of a hook left from a
+// previous run are handled when the hook runs again
+type HookUpgradePolicy string
+
+// Hook upgrade policy types
+const (
+	// HookUpgradeRecreate deletes and recreates the resources of the hook
+	// according to its delete policies. This is the default.
+	HookUpgradeRecreate HookUpgradePolicy = "recreate"
+	// HookUpgradePatch updates the resources of the hook in place, creating
+	// them if they do not exist.
+	HookUpgradePatch HookUpgradePolicy = "patch"
+)
+
+func (x HookUpgradePolicy) String() string { return string(x) }
+
 // HookAnnotation is the label name for a hook
 const HookAnnotation = "helm.sh/hook"
 
@@ -74,6 +90,21 @@ const HookDeleteAnnotation = "helm.sh/hook-delete-policy"
 // HookOutputLogAnnotation is the label name for the output log policy for a hook
 const HookOutputLogAnnotation = "helm.sh/hook-output-log-policy"
 
+// HookDependsOnAnnotation is the label name for the names of the hooks a hook
+// depends on
+const HookDependsOnAnnotation = "helm.sh/hook-depends-on"
+
+// HookTimeoutAnnotation is the label name for the timeout of a hook
+const HookTimeoutAnnotation = "helm.sh/hook-timeout"
+
+// HookRetriesAnnotation is the label name for the number of retries of a
+// failed hook
+const HookRetriesAnnotation = "helm.sh/hook-retries"
+
+// HookUpgradePolicyAnnotation is the label name for the upgrade policy for a
+// hook
+const HookUpgradePolicyAnnotation = "helm.sh/hook-upgrade-policy"
+
 // Hook defines a hook object.
 type Hook struct {
 	Name string `json:"name,omitempty"`
@@ -93,6 +124,17 @@ type Hook struct {
 	DeletePolicies []HookDeletePolicy `json:"delete_policies,omitempty"`
 	// OutputLogPolicies defines whether we should copy hook logs back to main process
 	OutputLogPolicies []HookOutputLogPolicy `json:"output_log_policies,omitempty"`
+	// DependsOn are the names of the hooks of the same event that have to
+	// succeed before this hook runs
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Timeout overrides the timeout of the operation for this hook. Zero means
+	// the timeout of the operation.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Retries is how many times the hook is run again after failing
+	Retries int `json:"retries,omitempty"`
+	// UpgradePolicy defines whether the resources of the hook are recreated
+	// or updated in place when the hook runs again. Empty means recreate.
+	UpgradePolicy HookUpgradePolicy `json:"upgrade_policy,omitempty"`
 }
 
 // A HookExecution records the result for the last execution of a hook for a given release.
@@ -42,6 +42,39 @@ type Info struct {
 	Notes string `json:"notes,omitempty"`
 	// Contains the deployed resources information
 	Resources map[string][]runtime.Object `json:"resources,omitempty"`
+	// Checkpoint records the progress of an install that has not completed.
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty"`
+	// Contains the health of the deployed resources, computed from their
+	// live state
+	Health []ResourceHealth `json:"health,omitempty"`
+}
+
+// Checkpoint records the progress of an install so that a failed install can
+// be resumed without starting over.
+type Checkpoint struct {
+	// PreInstallHooks is whether the pre-install hooks have completed.
+	PreInstallHooks bool `json:"pre_install_hooks,omitempty"`
+	// Applied lists the resources that have been applied, as kind/namespace/name.
+	Applied []string `json:"applied,omitempty"`
+}
+
+// ResourceHealth is the health of a resource of a release: Ready,
+// Progressing, Degraded, Missing or Unknown.
+type ResourceHealth struct {
+	Kind       string              `json:"kind"`
+	Namespace  string              `json:"namespace,omitempty"`
+	Name       string              `json:"name"`
+	Health     string              `json:"health"`
+	Message    string              `json:"message,omitempty"`
+	Conditions []ResourceCondition `json:"conditions,omitempty"`
+}
+
+// ResourceCondition is a condition reported in the status of a resource.
+type ResourceCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 // infoJSON is used for custom JSON marshaling/unmarshaling
@@ -54,6 +87,8 @@ type infoJSON struct {
 	RollbackRevision int                         `json:"rollback_revision,omitempty"`
 	Notes            string                      `json:"notes,omitempty"`
 	Resources        map[string][]runtime.Object `json:"resources,omitempty"`
+	Checkpoint       *Checkpoint                 `json:"checkpoint,omitempty"`
+	Health           []ResourceHealth            `json:"health,omitempty"`
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
@@ -101,6 +136,8 @@ func (i *Info) UnmarshalJSON(data []byte) error {
 	i.RollbackRevision = tmp.RollbackRevision
 	i.Notes = tmp.Notes
 	i.Resources = tmp.Resources
+	i.Checkpoint = tmp.Checkpoint
+	i.Health = tmp.Health
 
 	return nil
 }
@@ -114,6 +151,8 @@ func (i Info) MarshalJSON() ([]byte, error) {
 		RollbackRevision: i.RollbackRevision,
 		Notes:            i.Notes,
 		Resources:        i.Resources,
+		Checkpoint:       i.Checkpoint,
+		Health:           i.Health,
 	}
 
 	if !i.FirstDeployed.IsZero() {
@@ -350,6 +350,32 @@ func TestInfoRollbackRevisionRoundTrip(t *testing.T) {
 	}
 }
 
+func TestInfoCheckpointRoundTrip(t *testing.T) {
+	original := Info{
+		Description: "Initial install underway",
+		Status:      common.StatusFailed,
+		Checkpoint: &Checkpoint{
+			PreInstallHooks: true,
+			Applied:         []string{"ConfigMap/default/config", "Deployment/default/web"},
+		},
+	}
+
+	data, err := json.Marshal(&original)
+	require.NoError(t, err)
+
+	var decoded Info
+	err = json.Unmarshal(data, &decoded)
+	require.NoError(t, err)
+	assert.Equal(t, original.Checkpoint, decoded.Checkpoint)
+
+	// A completed release has no checkpoint
+	data, err = json.Marshal(&Info{Status: common.StatusDeployed})
+	require.NoError(t, err)
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(data, &raw))
+	assert.NotContains(t, raw, "checkpoint")
+}
+
 func TestInfoEmptyStringRoundTrip(t *testing.T) {
 	// This test specifically verifies that empty string time fields
 	// are handled correctly during parsing
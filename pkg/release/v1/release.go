@@ -37,6 +37,9 @@ type Release struct {
 	// Config is the set of extra Values added to the chart.
 	// These values override the default values inside of the chart.
 	Config map[string]any `json:"config,omitempty"`
+	// ValuesTrace records which file or flag set each of the values in Config,
+	// keyed by the dot separated path of the value.
+	ValuesTrace map[string]string `json:"values_trace,omitempty"`
 	// Manifest is the string representation of the rendered template.
 	Manifest string `json:"manifest,omitempty"`
 	// Hooks are all of the hooks declared for this release.
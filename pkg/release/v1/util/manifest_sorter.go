@@ -23,6 +23,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/yaml"
 
@@ -209,6 +210,16 @@ func (file *manifestFile) sort(result *result) error {
 		operateAnnotationValues(entry, release.HookOutputLogAnnotation, func(value string) {
 			h.OutputLogPolicies = append(h.OutputLogPolicies, release.HookOutputLogPolicy(value))
 		})
+
+		operateAnnotationValues(entry, release.HookDependsOnAnnotation, func(value string) {
+			if value != "" {
+				h.DependsOn = append(h.DependsOn, value)
+			}
+		})
+
+		h.Timeout = calculateHookTimeout(entry)
+		h.Retries = calculateHookRetries(entry)
+		h.UpgradePolicy = calculateHookUpgradePolicy(entry)
 	}
 
 	return nil
@@ -233,6 +244,46 @@ func calculateHookWeight(entry SimpleHead) int {
 	return hw
 }
 
+// calculateHookTimeout finds the timeout in the hook timeout annotation.
+//
+// If no valid timeout is found, the assigned timeout is 0
+func calculateHookTimeout(entry SimpleHead) time.Duration {
+	hts := entry.Metadata.Annotations[release.HookTimeoutAnnotation]
+	ht, err := time.ParseDuration(hts)
+	if err != nil || ht < 0 {
+		return 0
+	}
+	return ht
+}
+
+// calculateHookRetries finds the number of retries in the hook retries
+// annotation.
+//
+// If no valid number is found, the assigned number of retries is 0
+func calculateHookRetries(entry SimpleHead) int {
+	hrs := entry.Metadata.Annotations[release.HookRetriesAnnotation]
+	hr, err := strconv.Atoi(hrs)
+	if err != nil || hr < 0 {
+		return 0
+	}
+	return hr
+}
+
+// calculateHookUpgradePolicy finds the policy in the hook upgrade policy
+// annotation.
+//
+// If no known policy is found, no policy is assigned and the hook is
+// recreated
+func calculateHookUpgradePolicy(entry SimpleHead) release.HookUpgradePolicy {
+	hup := release.HookUpgradePolicy(strings.ToLower(strings.TrimSpace(entry.Metadata.Annotations[release.HookUpgradePolicyAnnotation])))
+	switch hup {
+	case release.HookUpgradeRecreate, release.HookUpgradePatch:
+		return hup
+	default:
+		return ""
+	}
+}
+
 // operateAnnotationValues finds the given annotation and runs the operate function with the value of that annotation
 func operateAnnotationValues(entry SimpleHead, annotation string, operate func(p string)) {
 	if dps, ok := entry.Metadata.Annotations[annotation]; ok {
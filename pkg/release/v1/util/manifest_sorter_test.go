@@ -18,6 +18,7 @@ package util
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -206,3 +207,43 @@ metadata:
 		assert.Equal(t, m.Content, sorted[i].Content)
 	}
 }
+
+func TestSortManifestsHookDependencies(t *testing.T) {
+	manifest := `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: seed
+  annotations:
+    "helm.sh/hook": pre-install
+    "helm.sh/hook-depends-on": "migrate, create-db"
+    "helm.sh/hook-timeout": 2m
+    "helm.sh/hook-retries": "3"
+    "helm.sh/hook-upgrade-policy": Patch
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  annotations:
+    "helm.sh/hook": pre-install
+    "helm.sh/hook-timeout": soon
+    "helm.sh/hook-retries": "-1"
+    "helm.sh/hook-upgrade-policy": merge
+`
+	hooks, _, err := SortManifests(map[string]string{"templates/jobs.yaml": manifest}, nil, InstallOrder)
+	require.NoError(t, err)
+	require.Len(t, hooks, 2)
+
+	assert.Equal(t, "seed", hooks[0].Name)
+	assert.Equal(t, []string{"migrate", "create-db"}, hooks[0].DependsOn)
+	assert.Equal(t, 2*time.Minute, hooks[0].Timeout)
+	assert.Equal(t, 3, hooks[0].Retries)
+	assert.Equal(t, release.HookUpgradePatch, hooks[0].UpgradePolicy)
+
+	// Invalid timeouts, retries and upgrade policies are ignored.
+	assert.Equal(t, "migrate", hooks[1].Name)
+	assert.Empty(t, hooks[1].DependsOn)
+	assert.Zero(t, hooks[1].Timeout)
+	assert.Zero(t, hooks[1].Retries)
+	assert.Empty(t, hooks[1].UpgradePolicy)
+}
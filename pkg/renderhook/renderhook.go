@@ -0,0 +1,299 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package renderhook runs the render hooks configured for Helm.
+
+Render hooks let organizations enforce transformations and policies on every
+chart Helm renders, without passing a post-renderer to each command. They are
+configured in a file listing the hooks, each run either as a local command
+receiving its input on stdin, or as an HTTP endpoint receiving its input in a
+POST request:
+
+	hooks:
+	- name: policy
+	  events: [pre-render]
+	  command: /usr/local/bin/check-values
+	- name: labels
+	  events: [post-render]
+	  url: https://render-hooks.example.com/labels
+	  timeout: 10s
+
+pre-render hooks receive the JSON-encoded PreRenderInput, and may print values
+replacing the values of the chart. post-render hooks receive the rendered
+manifests, and may print manifests replacing them. Hooks printing nothing leave
+their input unchanged, and hooks failing abort the rendering.
+*/
+package renderhook // import "helm.sh/helm/v4/pkg/renderhook"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// Event is the event a render hook runs on.
+type Event string
+
+const (
+	// PreRender hooks run before the templates of a chart are rendered.
+	PreRender Event = "pre-render"
+	// PostRender hooks run on the rendered manifests, after the
+	// post-renderer.
+	PostRender Event = "post-render"
+)
+
+// DefaultTimeout is how long a render hook may run when it sets no timeout.
+const DefaultTimeout = 30 * time.Second
+
+// Hook is a render hook.
+type Hook struct {
+	// Name identifies the hook in errors.
+	Name string `json:"name"`
+	// Events are the events the hook runs on.
+	Events []Event `json:"events"`
+	// Command is the command run by the hook. Exclusive with URL.
+	Command string `json:"command,omitempty"`
+	// Args are the arguments of the command.
+	Args []string `json:"args,omitempty"`
+	// URL is the endpoint the input of the hook is posted to. Exclusive
+	// with Command.
+	URL string `json:"url,omitempty"`
+	// Timeout is how long the hook may run, such as "10s". Defaults to
+	// DefaultTimeout.
+	Timeout string `json:"timeout,omitempty"`
+
+	timeout time.Duration
+}
+
+// Hooks are the render hooks configured for Helm.
+type Hooks struct {
+	Hooks []Hook `json:"hooks"`
+
+	// HTTPClient is the client of the hooks run as HTTP requests. Defaults
+	// to http.DefaultClient.
+	HTTPClient *http.Client `json:"-"`
+}
+
+// PreRenderInput is the input of pre-render hooks.
+type PreRenderInput struct {
+	Event   Event           `json:"event"`
+	Release string          `json:"release"`
+	Chart   *chart.Metadata `json:"chart"`
+	Values  map[string]any  `json:"values"`
+}
+
+// Load loads the render hooks configured in a file. It returns nil if the
+// file does not exist.
+func Load(path string) (*Hooks, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid render hooks file %s: %w", path, err)
+	}
+	return hooks, nil
+}
+
+// Parse parses and validates a render hooks configuration.
+func Parse(data []byte) (*Hooks, error) {
+	var hooks Hooks
+	if err := yaml.UnmarshalStrict(data, &hooks); err != nil {
+		return nil, err
+	}
+	for i := range hooks.Hooks {
+		if err := hooks.Hooks[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &hooks, nil
+}
+
+func (h *Hook) validate() error {
+	if h.Name == "" {
+		return errors.New("render hook without a name")
+	}
+	if (h.Command == "") == (h.URL == "") {
+		return fmt.Errorf("render hook %s must set either a command or a url", h.Name)
+	}
+	for _, e := range h.Events {
+		if e != PreRender && e != PostRender {
+			return fmt.Errorf("render hook %s has an unknown event %q", h.Name, e)
+		}
+	}
+	h.timeout = DefaultTimeout
+	if h.Timeout != "" {
+		timeout, err := time.ParseDuration(h.Timeout)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("render hook %s has an invalid timeout %q", h.Name, h.Timeout)
+		}
+		h.timeout = timeout
+	}
+	return nil
+}
+
+// Has returns whether a hook runs on the event.
+func (h *Hooks) Has(event Event) bool {
+	if h == nil {
+		return false
+	}
+	for _, hook := range h.Hooks {
+		if slices.Contains(hook.Events, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunPreRender runs the pre-render hooks in order, each one receiving the
+// values returned by the previous one, and returns the resulting values.
+func (h *Hooks) RunPreRender(ctx context.Context, release string, md *chart.Metadata, values map[string]any) (map[string]any, error) {
+	if !h.Has(PreRender) {
+		return values, nil
+	}
+	for _, hook := range h.Hooks {
+		if !slices.Contains(hook.Events, PreRender) {
+			continue
+		}
+		input, err := json.Marshal(PreRenderInput{Event: PreRender, Release: release, Chart: md, Values: values})
+		if err != nil {
+			return nil, err
+		}
+		out, err := h.run(ctx, hook, PreRender, release, "application/json", input)
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(out)) == 0 {
+			continue
+		}
+		values = map[string]any{}
+		if err := yaml.Unmarshal(out, &values); err != nil {
+			return nil, fmt.Errorf("%s hook %s returned invalid values: %w", PreRender, hook.Name, err)
+		}
+	}
+	return values, nil
+}
+
+// RunPostRender runs the post-render hooks in order, each one receiving the
+// manifests returned by the previous one, and returns the resulting
+// manifests.
+func (h *Hooks) RunPostRender(ctx context.Context, release string, manifests *bytes.Buffer) (*bytes.Buffer, error) {
+	if !h.Has(PostRender) {
+		return manifests, nil
+	}
+	for _, hook := range h.Hooks {
+		if !slices.Contains(hook.Events, PostRender) {
+			continue
+		}
+		out, err := h.run(ctx, hook, PostRender, release, "application/yaml", manifests.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(out)) == 0 {
+			continue
+		}
+		manifests = bytes.NewBuffer(out)
+	}
+	return manifests, nil
+}
+
+// run runs a hook with the input and returns its output.
+func (h *Hooks) run(ctx context.Context, hook Hook, event Event, release, contentType string, input []byte) ([]byte, error) {
+	timeout := hook.timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var out []byte
+	var err error
+	if hook.Command != "" {
+		out, err = runCommand(ctx, hook, event, release, input)
+	} else {
+		out, err = h.post(ctx, hook, event, release, contentType, input)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s hook %s failed: %w", event, hook.Name, err)
+	}
+	return out, nil
+}
+
+func runCommand(ctx context.Context, hook Hook, event Event, release string, input []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, hook.Command, hook.Args...)
+	cmd.Env = append(os.Environ(), "HELM_RENDER_EVENT="+string(event), "HELM_RELEASE_NAME="+release)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+func (h *Hooks) post(ctx context.Context, hook Hook, event Event, release, contentType string, input []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(input))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Helm-Render-Event", string(event))
+	req.Header.Set("X-Helm-Release-Name", release)
+
+	client := h.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if msg := strings.TrimSpace(string(body)); msg != "" {
+			return nil, fmt.Errorf("%s: %s", resp.Status, msg)
+		}
+		return nil, errors.New(resp.Status)
+	}
+	return body, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package renderhook
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestParse(t *testing.T) {
+	hooks, err := Parse([]byte(`hooks:
+- name: policy
+  events: [pre-render]
+  command: check
+  timeout: 5s
+`))
+	require.NoError(t, err)
+	assert.True(t, hooks.Has(PreRender))
+	assert.False(t, hooks.Has(PostRender))
+
+	for _, tc := range []struct {
+		config    string
+		wantError string
+	}{
+		{"hooks:\n- events: [pre-render]\n  command: check\n", "render hook without a name"},
+		{"hooks:\n- name: policy\n  events: [pre-render]\n", "must set either a command or a url"},
+		{"hooks:\n- name: policy\n  events: [pre-render]\n  command: check\n  url: http://localhost\n", "must set either a command or a url"},
+		{"hooks:\n- name: policy\n  events: [pre-install]\n  command: check\n", `unknown event "pre-install"`},
+		{"hooks:\n- name: policy\n  events: [pre-render]\n  command: check\n  timeout: soon\n", `invalid timeout "soon"`},
+		{"hooks:\n- name: policy\n  event: pre-render\n", `unknown field "event"`},
+	} {
+		_, err := Parse([]byte(tc.config))
+		assert.ErrorContains(t, err, tc.wantError, tc.config)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	hooks, err := Load(t.TempDir() + "/render-hooks.yaml")
+	require.NoError(t, err)
+	assert.Nil(t, hooks)
+	assert.False(t, hooks.Has(PreRender))
+}
+
+func TestRunPreRenderCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+	hooks, err := Parse([]byte(`hooks:
+- name: replace
+  events: [pre-render]
+  command: sh
+  args: ["-c", "cat > /dev/null; echo replicas: 3"]
+- name: check
+  events: [pre-render]
+  command: sh
+  args: ["-c", "grep -q '\"replicas\":3' && test \"$HELM_RELEASE_NAME\" = web"]
+`))
+	require.NoError(t, err)
+
+	values, err := hooks.RunPreRender(t.Context(), "web", &chart.Metadata{Name: "app"}, map[string]any{"replicas": 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"replicas": float64(3)}, values)
+
+	_, err = hooks.RunPreRender(t.Context(), "api", &chart.Metadata{Name: "app"}, nil)
+	assert.ErrorContains(t, err, "pre-render hook check failed: exit status 1")
+}
+
+func TestRunPostRenderHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "post-render", r.Header.Get("X-Helm-Render-Event"))
+		assert.Equal(t, "application/yaml", r.Header.Get("Content-Type"))
+		if r.Header.Get("X-Helm-Release-Name") == "denied" {
+			http.Error(w, "privileged containers are not allowed", http.StatusForbidden)
+			return
+		}
+		w.Write(append([]byte("# checked\n"), body...))
+	}))
+	defer srv.Close()
+
+	hooks, err := Parse([]byte("hooks:\n- name: policy\n  events: [post-render]\n  url: " + srv.URL + "\n"))
+	require.NoError(t, err)
+
+	out, err := hooks.RunPostRender(t.Context(), "web", bytes.NewBufferString("kind: ConfigMap\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "# checked\nkind: ConfigMap\n", out.String())
+
+	_, err = hooks.RunPostRender(t.Context(), "denied", bytes.NewBufferString("kind: Pod\n"))
+	assert.EqualError(t, err, "post-render hook policy failed: 403 Forbidden: privileged containers are not allowed")
+}
@@ -21,12 +21,15 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"helm.sh/helm/v4/internal/fileutil"
@@ -76,24 +79,24 @@ func NewChartRepository(cfg *Entry, getters getter.Providers) (*ChartRepository,
 }
 
 // DownloadIndexFile fetches the index from a repository.
+//
+// The index is fetched with a conditional request, so that nothing is
+// transferred when it did not change since it was cached. When the repository
+// serves a sharded index, only the parts of it that changed are fetched. Cache
+// files are only rewritten when their content changed.
 func (r *ChartRepository) DownloadIndexFile() (string, error) {
-	indexURL, err := ResolveReferenceURL(r.Config.URL, "index.yaml")
-	if err != nil {
-		return "", err
-	}
+	fname := filepath.Join(r.CachePath, helmpath.CacheIndexFile(r.Config.Name))
+	headersFile := filepath.Join(r.CachePath, helmpath.CacheIndexHeadersFile(r.Config.Name))
 
-	resp, err := r.Client.Get(indexURL,
-		getter.WithURL(r.Config.URL),
-		getter.WithInsecureSkipVerifyTLS(r.Config.InsecureSkipTLSVerify),
-		getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
-		getter.WithBasicAuth(r.Config.Username, r.Config.Password),
-		getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
-	)
-	if err != nil {
-		return "", err
+	var headers *cacheHeaders
+	index, err := r.downloadIndexV2(fname)
+	if errors.Is(err, errNoIndexV2) {
+		headers = &cacheHeaders{}
+		index, err = r.downloadIndex(fname, headersFile, headers)
+	}
+	if errors.Is(err, errIndexNotModified) {
+		return fname, nil
 	}
-
-	index, err := io.ReadAll(resp)
 	if err != nil {
 		return "", err
 	}
@@ -105,18 +108,66 @@ func (r *ChartRepository) DownloadIndexFile() (string, error) {
 
 	// Create the chart list file in the cache directory
 	var charts strings.Builder
-	for name := range indexFile.Entries {
+	for _, name := range slices.Sorted(maps.Keys(indexFile.Entries)) {
 		fmt.Fprintln(&charts, name)
 	}
 	chartsFile := filepath.Join(r.CachePath, helmpath.CacheChartsFile(r.Config.Name))
 	os.MkdirAll(filepath.Dir(chartsFile), 0755)
 
-	fileutil.AtomicWriteFile(chartsFile, bytes.NewReader([]byte(charts.String())), 0644)
+	writeFileIfChanged(chartsFile, []byte(charts.String()))
 
 	// Create the index file in the cache directory
-	fname := filepath.Join(r.CachePath, helmpath.CacheIndexFile(r.Config.Name))
 	os.MkdirAll(filepath.Dir(fname), 0755)
-	return fname, fileutil.AtomicWriteFile(fname, bytes.NewReader(index), 0644)
+	if err := writeFileIfChanged(fname, index); err != nil {
+		return fname, err
+	}
+
+	// The caching headers are only kept once the index they were sent with
+	// is cached. An index assembled from a sharded index keeps them with it.
+	if headers == nil {
+		return fname, os.RemoveAll(headersFile)
+	}
+	if err := os.RemoveAll(filepath.Join(r.CachePath, helmpath.CacheIndexV2Dir(r.Config.Name))); err != nil {
+		return fname, err
+	}
+	return fname, headers.write(headersFile)
+}
+
+// downloadIndex fetches the index.yaml file of the repository.
+//
+// The request is conditional on the caching headers stored in headersFile,
+// when the index is cached at fname, and returns errIndexNotModified when it
+// did not change. The caching headers of the fetched index are stored into
+// headers.
+func (r *ChartRepository) downloadIndex(fname, headersFile string, headers *cacheHeaders) ([]byte, error) {
+	indexURL, err := ResolveReferenceURL(r.Config.URL, "index.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	if fileExists(fname) {
+		*headers = loadCacheHeaders(headersFile)
+	}
+	resp, err := r.Client.Get(indexURL, append(r.getterOptions(), headers.getterOptions()...)...)
+	if errors.Is(err, getter.ErrNotModified) {
+		return nil, errIndexNotModified
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(resp)
+}
+
+// getterOptions returns the options to fetch files from the repository.
+func (r *ChartRepository) getterOptions() []getter.Option {
+	return []getter.Option{
+		getter.WithURL(r.Config.URL),
+		getter.WithInsecureSkipVerifyTLS(r.Config.InsecureSkipTLSVerify),
+		getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
+		getter.WithBasicAuth(r.Config.Username, r.Config.Password),
+		getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
+	}
 }
 
 type findChartInRepoURLOptions struct {
@@ -205,6 +256,8 @@ func FindChartInRepoURL(repoURL string, chartName string, getters getter.Provide
 	defer func() {
 		os.RemoveAll(filepath.Join(r.CachePath, helmpath.CacheChartsFile(r.Config.Name)))
 		os.RemoveAll(filepath.Join(r.CachePath, helmpath.CacheIndexFile(r.Config.Name)))
+		os.RemoveAll(filepath.Join(r.CachePath, helmpath.CacheIndexHeadersFile(r.Config.Name)))
+		os.RemoveAll(filepath.Join(r.CachePath, helmpath.CacheIndexV2Dir(r.Config.Name)))
 	}()
 
 	// Read the index file for the repository to get chart information and return chart URL
@@ -265,6 +318,69 @@ func ResolveReferenceURL(baseURL, refURL string) (string, error) {
 	return resolvedURL.String(), nil
 }
 
+// errIndexNotModified indicates that the index of a repository did not change
+// since it was cached.
+var errIndexNotModified = errors.New("index not modified")
+
+// cacheHeaders are the caching headers of a cached file, sent back to make
+// conditional requests for it.
+type cacheHeaders struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// loadCacheHeaders reads the caching headers stored at the given path. No
+// caching headers are returned when they can't be read.
+func loadCacheHeaders(path string) cacheHeaders {
+	var headers cacheHeaders
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return headers
+	}
+	if err := json.Unmarshal(b, &headers); err != nil {
+		slog.Debug("ignoring invalid caching headers", "file", path, "error", err)
+		return cacheHeaders{}
+	}
+	return headers
+}
+
+// getterOptions returns the options making a request conditional on the
+// caching headers, and storing those of the response back into them.
+func (h *cacheHeaders) getterOptions() []getter.Option {
+	return []getter.Option{
+		getter.WithETag(&h.ETag),
+		getter.WithLastModified(&h.LastModified),
+	}
+}
+
+// write stores the caching headers at the given path, or removes the file
+// when there are none.
+func (h *cacheHeaders) write(path string) error {
+	if *h == (cacheHeaders{}) {
+		return os.RemoveAll(path)
+	}
+	b, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return writeFileIfChanged(path, b)
+}
+
+// writeFileIfChanged atomically writes data to the given path, unless the file
+// already has that content.
+func writeFileIfChanged(path string, data []byte) error {
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	return fileutil.AtomicWriteFile(path, bytes.NewReader(data), 0644)
+}
+
+// fileExists returns whether a file exists at the given path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 func (e *Entry) String() string {
 	buf, err := json.Marshal(e)
 	if err != nil {
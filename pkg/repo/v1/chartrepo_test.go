@@ -24,6 +24,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -143,6 +144,75 @@ func TestConcurrencyDownloadIndex(t *testing.T) {
 	wg.Wait()
 }
 
+func TestDownloadIndexFileNotModified(t *testing.T) {
+	dir := t.TempDir()
+	i, err := LoadIndexFile("testdata/local-index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := i.WriteFile(filepath.Join(dir, "index.yaml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv, fetched := startETagServerForTests(t, dir)
+
+	r, err := NewChartRepository(&Entry{
+		Name: "test",
+		URL:  srv.URL,
+	}, getter.All(&cli.EnvSettings{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.CachePath = t.TempDir()
+
+	idx, err := r.DownloadIndexFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths := fetched(); !slices.Contains(paths, "/index.yaml") {
+		t.Fatalf("Expected index.yaml to be fetched, got %v", paths)
+	}
+	if _, err := os.Stat(filepath.Join(r.CachePath, helmpath.CacheIndexHeadersFile("test"))); err != nil {
+		t.Fatalf("Expected the caching headers of the index to be stored: %v", err)
+	}
+
+	// Mark the cached files so that rewriting them can be detected.
+	chartsFile := filepath.Join(r.CachePath, helmpath.CacheChartsFile("test"))
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, f := range []string{idx, chartsFile} {
+		if err := os.Chtimes(f, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := r.DownloadIndexFile(); err != nil {
+		t.Fatal(err)
+	}
+	if paths := fetched(); slices.Contains(paths, "/index.yaml") {
+		t.Errorf("Expected index.yaml not to be fetched again, got %v", paths)
+	}
+
+	// Dropping the caching headers fetches the index again, but leaves the
+	// cached files alone as their content did not change.
+	if err := os.Remove(filepath.Join(r.CachePath, helmpath.CacheIndexHeadersFile("test"))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.DownloadIndexFile(); err != nil {
+		t.Fatal(err)
+	}
+	if paths := fetched(); !slices.Contains(paths, "/index.yaml") {
+		t.Errorf("Expected index.yaml to be fetched again, got %v", paths)
+	}
+	for _, f := range []string{idx, chartsFile} {
+		fi, err := os.Stat(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fi.ModTime().Equal(old) {
+			t.Errorf("Expected %s not to be rewritten", f)
+		}
+	}
+}
+
 // startLocalServerForTests Start the local helm server
 func startLocalServerForTests(handler http.Handler) (*httptest.Server, error) {
 	if handler == nil {
@@ -67,6 +67,20 @@ The first is the 'index.yaml' format, which is expressed like this:
 An index.yaml file contains the necessary descriptive information about what
 charts are available in a repository, and how to get them.
 
+A repository may also serve the same information as a sharded index, so that
+clients only transfer what changed since their last update. Its root,
+'index-v2.yaml', references pages by digest. Each page references the shards
+of some of the charts, and each shard holds the versions of one chart:
+
+	apiVersion: v2
+	generated: 2016-09-29T12:14:34.829721375-06:00
+	pages:
+	- digest: sha256:2a1f4e3c...
+	  path: index-v2/2a1f4e3c....yaml
+
+Clients fetch the root with a conditional request and keep pages and shards
+across updates, falling back to index.yaml when there is no sharded index.
+
 The second file format is the repositories.yaml file format. This file is for
 facilitating local cached copies of one or more chart repositories.
 
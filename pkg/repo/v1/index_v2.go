@@ -0,0 +1,369 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/internal/fileutil"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/helmpath"
+)
+
+// APIVersionV2 is the v2 API version for sharded index files.
+const APIVersionV2 = "v2"
+
+const (
+	// IndexV2FileName is the name of the root of a sharded index, relative to
+	// the repository URL.
+	IndexV2FileName = "index-v2.yaml"
+	// IndexV2Dir is the directory, relative to the repository URL, holding the
+	// pages and shards of a sharded index.
+	IndexV2Dir = "index-v2"
+	// DefaultIndexV2PageSize is the number of charts listed on each page of a
+	// sharded index.
+	DefaultIndexV2PageSize = 500
+)
+
+// indexV2Concurrency is the number of pages or shards fetched at the same time.
+const indexV2Concurrency = 8
+
+// indexV2HeadersFile is the file, in the cache of a sharded index, holding the
+// caching headers of the cached root.
+const indexV2HeadersFile = "headers.json"
+
+// errNoIndexV2 indicates that a repository does not serve a sharded index.
+var errNoIndexV2 = errors.New("no sharded index")
+
+// IndexV2File is the root of a sharded index.
+//
+// A sharded index holds the same information as an index.yaml file, split so
+// that clients only transfer what changed since their last update. The root
+// references pages, each page references the shards of a subset of the
+// charts, and each shard holds the versions of a single chart. Pages and
+// shards are referenced by digest, so clients keep them across updates, and
+// the root is fetched with a conditional request.
+type IndexV2File struct {
+	APIVersion  string            `json:"apiVersion"`
+	Generated   time.Time         `json:"generated"`
+	Pages       []IndexV2Ref      `json:"pages"`
+	PublicKeys  []string          `json:"publicKeys,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// IndexV2Ref references a page or a shard of a sharded index by its path,
+// relative to the repository URL, and the digest of its content.
+type IndexV2Ref struct {
+	Path   string `json:"path"`
+	Digest string `json:"digest"`
+}
+
+// IndexV2Page references the shards of a subset of the charts of a sharded
+// index, by chart name.
+type IndexV2Page struct {
+	APIVersion string                `json:"apiVersion"`
+	Entries    map[string]IndexV2Ref `json:"entries"`
+}
+
+// IndexV2Shard holds the versions of a single chart of a sharded index.
+type IndexV2Shard struct {
+	APIVersion string        `json:"apiVersion"`
+	Name       string        `json:"name"`
+	Versions   ChartVersions `json:"versions"`
+}
+
+// WriteV2 writes the index as a sharded index into the given directory: the
+// root to IndexV2FileName, and the pages and shards to IndexV2Dir, named after
+// their digest. Pages and shards that are no longer referenced are removed.
+//
+// Charts are spread over pages by the hash of their name, so that a change
+// to a chart only changes its own shard and page. The number of pages
+// doubles whenever a page would list more than pageSize charts.
+//
+// The mode on the files is set to 'mode'.
+func (i IndexFile) WriteV2(dir string, pageSize int, mode os.FileMode) error {
+	if pageSize <= 0 {
+		pageSize = DefaultIndexV2PageSize
+	}
+	names := slices.Sorted(maps.Keys(i.Entries))
+	pageCount := 1
+	for pageCount*pageSize < len(names) {
+		pageCount *= 2
+	}
+
+	shardDir := filepath.Join(dir, IndexV2Dir)
+	if err := os.MkdirAll(shardDir, 0755); err != nil {
+		return err
+	}
+	written := map[string]bool{}
+	write := func(v any) (IndexV2Ref, error) {
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return IndexV2Ref{}, err
+		}
+		sum := sha256.Sum256(b)
+		name := hex.EncodeToString(sum[:]) + ".yaml"
+		written[name] = true
+		ref := IndexV2Ref{Path: path.Join(IndexV2Dir, name), Digest: "sha256:" + hex.EncodeToString(sum[:])}
+		// Unchanged pages and shards are left alone, so that servers keep
+		// serving them as unmodified.
+		if _, err := os.Stat(filepath.Join(shardDir, name)); err == nil {
+			return ref, nil
+		}
+		return ref, fileutil.AtomicWriteFile(filepath.Join(shardDir, name), bytes.NewReader(b), mode)
+	}
+
+	pages := make([]IndexV2Page, pageCount)
+	for p := range pages {
+		pages[p] = IndexV2Page{APIVersion: APIVersionV2, Entries: map[string]IndexV2Ref{}}
+	}
+	for _, name := range names {
+		ref, err := write(IndexV2Shard{APIVersion: APIVersionV2, Name: name, Versions: i.Entries[name]})
+		if err != nil {
+			return err
+		}
+		pages[indexV2PageOf(name, pageCount)].Entries[name] = ref
+	}
+
+	root := IndexV2File{
+		APIVersion:  APIVersionV2,
+		Generated:   i.Generated,
+		Pages:       []IndexV2Ref{},
+		PublicKeys:  i.PublicKeys,
+		Annotations: i.Annotations,
+	}
+	for _, page := range pages {
+		if len(page.Entries) == 0 {
+			continue
+		}
+		ref, err := write(page)
+		if err != nil {
+			return err
+		}
+		root.Pages = append(root.Pages, ref)
+	}
+	b, err := yaml.Marshal(root)
+	if err != nil {
+		return err
+	}
+	if err := fileutil.AtomicWriteFile(filepath.Join(dir, IndexV2FileName), bytes.NewReader(b), mode); err != nil {
+		return err
+	}
+
+	// Only remove the previous pages and shards once the root no longer
+	// references them.
+	files, err := os.ReadDir(shardDir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if !f.IsDir() && filepath.Ext(f.Name()) == ".yaml" && !written[f.Name()] {
+			if err := os.Remove(filepath.Join(shardDir, f.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// indexV2PageOf returns the page listing the chart with the given name.
+func indexV2PageOf(name string, pageCount int) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int(h.Sum32() % uint32(pageCount))
+}
+
+// downloadIndexV2 fetches the sharded index of the repository and returns it
+// in the format of an index.yaml file.
+//
+// The root is fetched with a conditional request, and only the pages and
+// shards missing from the cache of previous downloads are fetched. It returns
+// errIndexNotModified when the root did not change since the index was cached
+// at fname, and errNoIndexV2 when the repository does not serve a sharded
+// index. This is only probed for over HTTP, as other getters don't support
+// conditional requests.
+func (r *ChartRepository) downloadIndexV2(fname string) ([]byte, error) {
+	u, err := url.Parse(r.Config.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, errNoIndexV2
+	}
+	rootURL, err := ResolveReferenceURL(r.Config.URL, IndexV2FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheDir := filepath.Join(r.CachePath, helmpath.CacheIndexV2Dir(r.Config.Name))
+	rootFile := filepath.Join(cacheDir, IndexV2FileName)
+	headersFile := filepath.Join(cacheDir, indexV2HeadersFile)
+	var headers cacheHeaders
+	if fileExists(rootFile) && fileExists(fname) {
+		headers = loadCacheHeaders(headersFile)
+	}
+
+	resp, err := r.Client.Get(rootURL, append(r.getterOptions(), headers.getterOptions()...)...)
+	if errors.Is(err, getter.ErrNotModified) {
+		return nil, errIndexNotModified
+	}
+	if err != nil {
+		slog.Debug("repository does not serve a sharded index", "url", rootURL, "error", err)
+		return nil, errNoIndexV2
+	}
+	data := resp.Bytes()
+
+	root := &IndexV2File{}
+	if err := jsonOrYamlUnmarshal(data, root); err != nil || root.APIVersion != APIVersionV2 {
+		slog.Debug("repository does not serve a sharded index", "url", rootURL, "error", err)
+		return nil, errNoIndexV2
+	}
+
+	used := map[string]bool{IndexV2FileName: true, indexV2HeadersFile: true}
+	pageData, err := r.fetchIndexV2Refs(cacheDir, root.Pages, used)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	var shardRefs []IndexV2Ref
+	for idx, b := range pageData {
+		page := &IndexV2Page{}
+		if err := jsonOrYamlUnmarshal(b, page); err != nil {
+			return nil, fmt.Errorf("error loading %s: %w", root.Pages[idx].Path, err)
+		}
+		for _, name := range slices.Sorted(maps.Keys(page.Entries)) {
+			names = append(names, name)
+			shardRefs = append(shardRefs, page.Entries[name])
+		}
+	}
+	shardData, err := r.fetchIndexV2Refs(cacheDir, shardRefs, used)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &IndexFile{
+		APIVersion:  APIVersionV1,
+		Generated:   root.Generated,
+		Entries:     make(map[string]ChartVersions, len(names)),
+		PublicKeys:  root.PublicKeys,
+		Annotations: root.Annotations,
+	}
+	for idx, b := range shardData {
+		shard := &IndexV2Shard{}
+		if err := jsonOrYamlUnmarshal(b, shard); err != nil {
+			return nil, fmt.Errorf("error loading %s: %w", shardRefs[idx].Path, err)
+		}
+		if shard.Name != names[idx] {
+			return nil, fmt.Errorf("error loading %s: expected versions of chart %q, got %q", shardRefs[idx].Path, names[idx], shard.Name)
+		}
+		index.Entries[shard.Name] = shard.Versions
+	}
+	b, err := yaml.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := writeFileIfChanged(rootFile, data); err != nil {
+		return nil, err
+	}
+	if err := headers.write(headersFile); err != nil {
+		return nil, err
+	}
+	// Drop the pages and shards of previous versions of the index.
+	if files, err := os.ReadDir(cacheDir); err == nil {
+		for _, f := range files {
+			if !used[f.Name()] {
+				os.Remove(filepath.Join(cacheDir, f.Name()))
+			}
+		}
+	}
+	return b, nil
+}
+
+// fetchIndexV2Refs returns the content of the given pages or shards, fetching
+// those missing from the cache directory. The names of their cached files are
+// added to used.
+func (r *ChartRepository) fetchIndexV2Refs(cacheDir string, refs []IndexV2Ref, used map[string]bool) ([][]byte, error) {
+	for _, ref := range refs {
+		sum, ok := strings.CutPrefix(ref.Digest, "sha256:")
+		if _, err := hex.DecodeString(sum); !ok || err != nil || len(sum) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid digest %q of %s", ref.Digest, ref.Path)
+		}
+		used[sum+".yaml"] = true
+	}
+
+	results := make([][]byte, len(refs))
+	errs := make([]error, len(refs))
+	sem := make(chan struct{}, indexV2Concurrency)
+	var wg sync.WaitGroup
+	for idx, ref := range refs {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[idx], errs[idx] = r.fetchIndexV2Ref(cacheDir, ref)
+		})
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// fetchIndexV2Ref returns the content of a page or a shard from the cache
+// directory, or fetches it and verifies its digest.
+func (r *ChartRepository) fetchIndexV2Ref(cacheDir string, ref IndexV2Ref) ([]byte, error) {
+	sum := strings.TrimPrefix(ref.Digest, "sha256:")
+	cached := filepath.Join(cacheDir, sum+".yaml")
+	if b, err := os.ReadFile(cached); err == nil {
+		return b, nil
+	}
+
+	refURL, err := ResolveReferenceURL(r.Config.URL, ref.Path)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.Client.Get(refURL, r.getterOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	actual := sha256.Sum256(resp.Bytes())
+	if hex.EncodeToString(actual[:]) != sum {
+		return nil, fmt.Errorf("digest of %s does not match: expected %s, got sha256:%x", refURL, ref.Digest, actual)
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, err
+	}
+	return resp.Bytes(), fileutil.AtomicWriteFile(cached, bytes.NewReader(resp.Bytes()), 0644)
+}
@@ -0,0 +1,304 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/helmpath"
+)
+
+// startETagServerForTests serves the given directory with entity tags, and
+// records the paths of the requests that were not answered with 304.
+func startETagServerForTests(t *testing.T, dir string) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var fetched []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path.Clean("/"+r.URL.Path)))); err == nil {
+			etag := fmt.Sprintf(`"%x"`, sha256.Sum256(data))
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		mu.Lock()
+		fetched = append(fetched, r.URL.Path)
+		mu.Unlock()
+		http.FileServer(http.Dir(dir)).ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		paths := fetched
+		fetched = nil
+		slices.Sort(paths)
+		return paths
+	}
+}
+
+func TestIndexFileWriteV2(t *testing.T) {
+	dir := t.TempDir()
+	i := NewIndexFile()
+	for _, name := range []string{"alpine", "clipper", "nginx", "zeta"} {
+		if err := i.MustAdd(&chart.Metadata{APIVersion: "v2", Name: name, Version: "1.0.0"}, name+"-1.0.0.tgz", "http://example.com/charts", "sha256:1234567890"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := i.WriteV2(dir, 1, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := &IndexV2File{}
+	readIndexV2Root(t, dir, root)
+	if root.APIVersion != APIVersionV2 {
+		t.Errorf("Expected API version %s, got %s", APIVersionV2, root.APIVersion)
+	}
+	if len(root.Pages) == 0 || len(root.Pages) > 4 {
+		t.Fatalf("Expected between 1 and 4 pages, got %d", len(root.Pages))
+	}
+
+	charts := map[string]IndexV2Ref{}
+	for _, ref := range root.Pages {
+		page := &IndexV2Page{}
+		readIndexV2Ref(t, dir, ref, page)
+		for name, shardRef := range page.Entries {
+			charts[name] = shardRef
+		}
+	}
+	if len(charts) != 4 {
+		t.Fatalf("Expected 4 charts over all pages, got %d", len(charts))
+	}
+	shard := &IndexV2Shard{}
+	readIndexV2Ref(t, dir, charts["nginx"], shard)
+	if shard.Name != "nginx" || len(shard.Versions) != 1 || shard.Versions[0].Version != "1.0.0" {
+		t.Errorf("Unexpected shard of nginx: %+v", shard)
+	}
+
+	// Writing the index again without a chart removes its shard, and keeps
+	// the shards of the other charts.
+	delete(i.Entries, "zeta")
+	if err := i.WriteV2(dir, 1, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(charts["zeta"].Path))); !os.IsNotExist(err) {
+		t.Errorf("Expected the shard of the removed chart to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(charts["nginx"].Path))); err != nil {
+		t.Errorf("Expected the shard of an unchanged chart to be kept, got %v", err)
+	}
+}
+
+func readIndexV2Root(t *testing.T, dir string, root *IndexV2File) {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, IndexV2FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.UnmarshalStrict(b, root); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readIndexV2Ref(t *testing.T, dir string, ref IndexV2Ref, v any) {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref.Path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest := fmt.Sprintf("sha256:%x", sha256.Sum256(b)); digest != ref.Digest {
+		t.Fatalf("Expected digest %s of %s, got %s", ref.Digest, ref.Path, digest)
+	}
+	if err := yaml.UnmarshalStrict(b, v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDownloadIndexV2(t *testing.T) {
+	dir := t.TempDir()
+	i, err := LoadIndexFile("testdata/local-index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := i.WriteV2(dir, 1, 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv, fetched := startETagServerForTests(t, dir)
+
+	r, err := NewChartRepository(&Entry{
+		Name: "test",
+		URL:  srv.URL,
+	}, getter.All(&cli.EnvSettings{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.CachePath = t.TempDir()
+
+	download := func() *IndexFile {
+		t.Helper()
+		idx, err := r.DownloadIndexFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		downloaded, err := LoadIndexFile(idx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return downloaded
+	}
+
+	// The first download fetches the whole index.
+	downloaded := download()
+	if len(downloaded.Entries) != len(i.Entries) {
+		t.Fatalf("Expected %d charts, got %d", len(i.Entries), len(downloaded.Entries))
+	}
+	for name, versions := range i.Entries {
+		if len(downloaded.Entries[name]) != len(versions) {
+			t.Errorf("Expected %d versions of %s, got %d", len(versions), name, len(downloaded.Entries[name]))
+		}
+	}
+	paths := fetched()
+	if slices.Contains(paths, "/index.yaml") {
+		t.Errorf("Expected index.yaml not to be fetched, got %v", paths)
+	}
+	root := &IndexV2File{}
+	readIndexV2Root(t, dir, root)
+	if want := 1 + len(root.Pages) + len(i.Entries); len(paths) != want {
+		t.Errorf("Expected the root, the pages and the shards to be fetched, got %v", paths)
+	}
+
+	// Nothing is transferred when the index did not change.
+	download()
+	if paths := fetched(); len(paths) != 0 {
+		t.Errorf("Expected nothing to be fetched, got %v", paths)
+	}
+
+	// Only the root, and the page and shard of the changed chart, are
+	// transferred when the index changed.
+	if err := i.MustAdd(&chart.Metadata{APIVersion: "v2", Name: "nginx", Version: "0.3.0"}, "nginx-0.3.0.tgz", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	i.SortEntries()
+	if err := i.WriteV2(dir, 1, 0644); err != nil {
+		t.Fatal(err)
+	}
+	downloaded = download()
+	if !downloaded.Has("nginx", "0.3.0") {
+		t.Error("Expected the downloaded index to have nginx 0.3.0")
+	}
+	if paths := fetched(); len(paths) != 3 || paths[0] != "/"+IndexV2FileName {
+		t.Errorf("Expected the root, a page and a shard to be fetched, got %v", paths)
+	}
+
+	// Pages and shards of previous versions are dropped from the cache.
+	cached, err := os.ReadDir(filepath.Join(r.CachePath, helmpath.CacheIndexV2Dir("test")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	readIndexV2Root(t, dir, root)
+	if want := 2 + len(root.Pages) + len(i.Entries); len(cached) != want {
+		t.Errorf("Expected the cache to hold the root, its entity tag, the pages and the shards, got %d files", len(cached))
+	}
+}
+
+func TestDownloadIndexV2DigestMismatch(t *testing.T) {
+	dir := t.TempDir()
+	i, err := LoadIndexFile("testdata/local-index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := i.WriteV2(dir, 1, 0644); err != nil {
+		t.Fatal(err)
+	}
+	shards, err := filepath.Glob(filepath.Join(dir, IndexV2Dir, "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, shard := range shards {
+		if err := os.WriteFile(shard, []byte("apiVersion: v2\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv, _ := startETagServerForTests(t, dir)
+
+	r, err := NewChartRepository(&Entry{
+		Name: "test",
+		URL:  srv.URL,
+	}, getter.All(&cli.EnvSettings{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.CachePath = t.TempDir()
+
+	_, err = r.DownloadIndexFile()
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected a digest mismatch error, got %v", err)
+	}
+}
+
+func TestDownloadIndexV2Fallback(t *testing.T) {
+	dir := t.TempDir()
+	i, err := LoadIndexFile("testdata/local-index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := i.WriteFile(filepath.Join(dir, "index.yaml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srv, fetched := startETagServerForTests(t, dir)
+
+	r, err := NewChartRepository(&Entry{
+		Name: "test",
+		URL:  srv.URL,
+	}, getter.All(&cli.EnvSettings{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.CachePath = t.TempDir()
+
+	idx, err := r.DownloadIndexFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	downloaded, err := LoadIndexFile(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(downloaded.Entries) != len(i.Entries) {
+		t.Errorf("Expected %d charts, got %d", len(i.Entries), len(downloaded.Entries))
+	}
+	if paths := fetched(); !slices.Equal(paths, []string{"/" + IndexV2FileName, "/index.yaml"}) {
+		t.Errorf("Expected the sharded index to be probed before index.yaml, got %v", paths)
+	}
+}
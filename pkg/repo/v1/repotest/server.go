@@ -16,12 +16,14 @@ limitations under the License.
 package repotest
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"path/filepath"
 	"testing"
 	"time"
@@ -71,6 +73,14 @@ func WithChartSourceGlob(glob string) ServerOption {
 	}
 }
 
+// WithIndexV2 makes the server serve a sharded index next to index.yaml,
+// with the given number of charts per page.
+func WithIndexV2(pageSize int) ServerOption {
+	return func(_ *testing.T, server *Server) {
+		server.indexV2PageSize = pageSize
+	}
+}
+
 // Server is an implementation of a repository server for testing.
 type Server struct {
 	docroot         string
@@ -78,6 +88,7 @@ type Server struct {
 	middleware      http.HandlerFunc
 	tlsConfig       *tls.Config
 	chartSourceGlob string
+	indexV2PageSize int
 }
 
 // NewTempServer creates a server inside of a temp dir.
@@ -123,6 +134,11 @@ func newServer(t *testing.T, docroot string, options ...ServerOption) *Server {
 		if s.middleware != nil {
 			s.middleware.ServeHTTP(w, r)
 		}
+		// Files are served with an entity tag, so that conditional requests
+		// are answered with 304 Not Modified.
+		if data, err := os.ReadFile(filepath.Join(s.Root(), filepath.FromSlash(path.Clean("/"+r.URL.Path)))); err == nil {
+			w.Header().Set("ETag", fmt.Sprintf(`"%x"`, sha256.Sum256(data)))
+		}
 		http.FileServer(http.Dir(s.Root())).ServeHTTP(w, r)
 	}))
 
@@ -181,6 +197,7 @@ func NewOCIServer(t *testing.T, dir string) (*OCIServer, error) {
 	config.HTTP.Addr = ln.Addr().String()
 	config.HTTP.DrainTimeout = time.Duration(10) * time.Second
 	config.Storage = map[string]configuration.Parameters{"inmemory": map[string]any{}}
+	config.Catalog.MaxEntries = 1000
 	config.Auth = configuration.Auth{
 		"htpasswd": configuration.Parameters{
 			"realm": "localhost",
@@ -337,7 +354,13 @@ func (s *Server) CreateIndex() error {
 	}
 
 	ifile := filepath.Join(s.docroot, "index.yaml")
-	return os.WriteFile(ifile, d, 0o644)
+	if err := os.WriteFile(ifile, d, 0o644); err != nil {
+		return err
+	}
+	if s.indexV2PageSize > 0 {
+		return index.WriteV2(s.docroot, s.indexV2PageSize, 0o644)
+	}
+	return nil
 }
 
 func (s *Server) start() {
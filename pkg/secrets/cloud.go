@@ -0,0 +1,121 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// AWSSecretsManagerScheme is the scheme of references to AWS Secrets
+	// Manager secrets.
+	AWSSecretsManagerScheme = "awssm"
+	// GCPSecretManagerScheme is the scheme of references to Google Cloud
+	// Secret Manager secrets.
+	GCPSecretManagerScheme = "gcpsm"
+)
+
+// runFunc runs a command and returns its standard output.
+type runFunc func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// runCommand runs a command line tool, with the credentials and configuration
+// the tool finds in the environment.
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// AWSSecretsManagerResolver resolves references to AWS Secrets Manager secrets
+// with the aws CLI, such as awssm://prod/db#password for the password field of
+// the JSON secret prod/db. The region can be set with a region query parameter,
+// as in awssm://prod/db?region=eu-west-1, and the field may be omitted for the
+// whole secret string.
+type AWSSecretsManagerResolver struct {
+	run runFunc
+}
+
+// Resolve returns the secret ref points to.
+func (r *AWSSecretsManagerResolver) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	id := strings.Trim(ref.Host+ref.Path, "/")
+	if id == "" {
+		return "", errors.New("missing secret ID")
+	}
+	args := []string{"secretsmanager", "get-secret-value", "--secret-id", id, "--query", "SecretString", "--output", "text"}
+	if region := ref.Query().Get("region"); region != "" {
+		args = append(args, "--region", region)
+	}
+	out, err := r.runner()(ctx, "aws", args...)
+	if err != nil {
+		return "", err
+	}
+	return secretField(strings.TrimSuffix(string(out), "\n"), ref.Fragment)
+}
+
+func (r *AWSSecretsManagerResolver) runner() runFunc {
+	if r.run != nil {
+		return r.run
+	}
+	return runCommand
+}
+
+// GCPSecretManagerResolver resolves references to Google Cloud Secret Manager
+// secrets with the gcloud CLI, such as gcpsm://my-project/db#password for the
+// password field of the JSON secret db of the project my-project. A version can
+// follow the name of the secret, as in gcpsm://my-project/db/3, and the field
+// may be omitted for the whole secret.
+type GCPSecretManagerResolver struct {
+	run runFunc
+}
+
+// Resolve returns the secret ref points to.
+func (r *GCPSecretManagerResolver) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	parts := strings.Split(strings.Trim(ref.Path, "/"), "/")
+	if ref.Host == "" || parts[0] == "" || len(parts) > 2 {
+		return "", errors.New("expected a reference such as gcpsm://PROJECT/SECRET[/VERSION][#FIELD]")
+	}
+	version := "latest"
+	if len(parts) == 2 {
+		version = parts[1]
+	}
+	out, err := r.runner()(ctx, "gcloud", "secrets", "versions", "access", version, "--secret", parts[0], "--project", ref.Host)
+	if err != nil {
+		return "", err
+	}
+	return secretField(string(out), ref.Fragment)
+}
+
+func (r *GCPSecretManagerResolver) runner() runFunc {
+	if r.run != nil {
+		return r.run
+	}
+	return runCommand
+}
@@ -0,0 +1,106 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRun records the command it runs and prints out.
+func fakeRun(out string, cmd *string) runFunc {
+	return func(_ context.Context, name string, args ...string) ([]byte, error) {
+		*cmd = strings.Join(append([]string{name}, args...), " ")
+		return []byte(out), nil
+	}
+}
+
+func TestCloudResolvers(t *testing.T) {
+	tests := []struct {
+		name      string
+		ref       string
+		out       string
+		expectCmd string
+		expect    string
+		wantErr   string
+	}{
+		{
+			name:      "aws secret string",
+			ref:       "awssm://prod/db",
+			out:       "s3cr3t\n",
+			expectCmd: "aws secretsmanager get-secret-value --secret-id prod/db --query SecretString --output text",
+			expect:    "s3cr3t",
+		},
+		{
+			name:      "aws field in region",
+			ref:       "awssm://prod/db?region=eu-west-1#password",
+			out:       `{"user":"admin","password":"s3cr3t"}` + "\n",
+			expectCmd: "aws secretsmanager get-secret-value --secret-id prod/db --query SecretString --output text --region eu-west-1",
+			expect:    "s3cr3t",
+		},
+		{
+			name:    "aws missing secret ID",
+			ref:     "awssm://",
+			wantErr: "missing secret ID",
+		},
+		{
+			name:      "gcp latest version",
+			ref:       "gcpsm://my-project/db",
+			out:       "s3cr3t",
+			expectCmd: "gcloud secrets versions access latest --secret db --project my-project",
+			expect:    "s3cr3t",
+		},
+		{
+			name:      "gcp field of a version",
+			ref:       "gcpsm://my-project/db/3#password",
+			out:       `{"password":"s3cr3t"}`,
+			expectCmd: "gcloud secrets versions access 3 --secret db --project my-project",
+			expect:    "s3cr3t",
+		},
+		{
+			name:    "gcp missing secret",
+			ref:     "gcpsm://my-project",
+			wantErr: "expected a reference such as gcpsm://PROJECT/SECRET[/VERSION][#FIELD]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cmd string
+			providers := Providers{
+				{Schemes: []string{AWSSecretsManagerScheme}, Resolver: &AWSSecretsManagerResolver{run: fakeRun(tt.out, &cmd)}},
+				{Schemes: []string{GCPSecretManagerScheme}, Resolver: &GCPSecretManagerResolver{run: fakeRun(tt.out, &cmd)}},
+			}
+			ref, err := url.Parse(tt.ref)
+			require.NoError(t, err)
+			resolver, err := providers.ByScheme(ref.Scheme)
+			require.NoError(t, err)
+			got, err := resolver.Resolve(t.Context(), ref)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectCmd, cmd)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
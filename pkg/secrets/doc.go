@@ -0,0 +1,27 @@
+/*
+Copyright The Helm Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package secrets resolves references to secrets in values, such as
+vault://secret/data/db#password, by URI scheme.
+
+Values hold the references, which are stored with releases, and the secrets
+they point to are only resolved for rendering. Resolving is opt-in: without
+providers no reference is resolved, and ResolveValuesAt only resolves the
+references at given keys, such as those set with --set-secret. Resolvers for other secret
+stores can be added by implementing the Resolver interface and adding a
+Provider for their schemes.
+*/
+package secrets
@@ -0,0 +1,244 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Resolver resolves references to the secrets of a secret store.
+type Resolver interface {
+	// Resolve returns the secret ref points to, such as the password field of
+	// the secret secret/data/db for vault://secret/data/db#password.
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+// Provider represents a resolver and the schemes of the references it resolves.
+type Provider struct {
+	Schemes  []string
+	Resolver Resolver
+}
+
+// Provides returns true if the given scheme is supported by this Provider.
+func (p Provider) Provides(scheme string) bool {
+	return slices.Contains(p.Schemes, scheme)
+}
+
+// Providers is a collection of Provider objects.
+type Providers []Provider
+
+// Default returns the providers of the secret stores supported by Helm:
+// HashiCorp Vault (vault://), AWS Secrets Manager (awssm://) and Google Cloud
+// Secret Manager (gcpsm://).
+func Default() Providers {
+	return Providers{
+		{Schemes: []string{VaultScheme}, Resolver: &VaultResolver{}},
+		{Schemes: []string{AWSSecretsManagerScheme}, Resolver: &AWSSecretsManagerResolver{}},
+		{Schemes: []string{GCPSecretManagerScheme}, Resolver: &GCPSecretManagerResolver{}},
+	}
+}
+
+// ByScheme returns the Resolver of the given scheme.
+//
+// If no provider handles this scheme, this will return an error.
+func (p Providers) ByScheme(scheme string) (Resolver, error) {
+	for _, pp := range p {
+		if pp.Provides(scheme) {
+			return pp.Resolver, nil
+		}
+	}
+	return nil, fmt.Errorf("secret scheme %q not supported", scheme)
+}
+
+// ParseReference parses s as a reference to a secret of one of the schemes of
+// the providers. It returns false if s is not such a reference.
+func (p Providers) ParseReference(s string) (*url.URL, bool) {
+	scheme, _, ok := strings.Cut(s, "://")
+	if !ok {
+		return nil, false
+	}
+	if _, err := p.ByScheme(scheme); err != nil {
+		return nil, false
+	}
+	ref, err := url.Parse(s)
+	if err != nil {
+		return nil, false
+	}
+	return ref, true
+}
+
+// ResolveValues returns a copy of vals with the references to secrets of the
+// schemes of the providers replaced with the secrets. vals is not modified.
+func (p Providers) ResolveValues(ctx context.Context, vals map[string]any) (map[string]any, error) {
+	if len(p) == 0 {
+		return vals, nil
+	}
+	r := &valuesResolver{providers: p, secrets: map[string]string{}}
+	resolved, err := r.resolve(ctx, vals)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := resolved.(map[string]any)
+	return m, nil
+}
+
+// ResolveValuesAt returns a copy of vals with only the references to secrets
+// that refs holds at the same keys, such as the values set with --set-secret,
+// replaced with the secrets. Other references in vals are left as they are.
+// vals is not modified.
+func (p Providers) ResolveValuesAt(ctx context.Context, vals, refs map[string]any) (map[string]any, error) {
+	if len(p) == 0 || len(refs) == 0 {
+		return vals, nil
+	}
+	r := &valuesResolver{providers: p, secrets: map[string]string{}}
+	resolved, err := r.resolve(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := replaceReferences(vals, refs, resolved).(map[string]any)
+	return m, nil
+}
+
+// replaceReferences returns a copy of v with the values equal to the
+// references at the same keys of refs replaced with the resolved ones.
+func replaceReferences(v, refs, resolved any) any {
+	switch refs := refs.(type) {
+	case map[string]any:
+		vm, ok := v.(map[string]any)
+		if !ok || vm == nil {
+			return v
+		}
+		rm, _ := resolved.(map[string]any)
+		m := maps.Clone(vm)
+		for key, ref := range refs {
+			if val, ok := vm[key]; ok {
+				m[key] = replaceReferences(val, ref, rm[key])
+			}
+		}
+		return m
+	case []any:
+		vl, ok := v.([]any)
+		if !ok {
+			return v
+		}
+		rl, _ := resolved.([]any)
+		l := slices.Clone(vl)
+		for n, ref := range refs {
+			if n < len(l) && n < len(rl) {
+				l[n] = replaceReferences(vl[n], ref, rl[n])
+			}
+		}
+		return l
+	case string:
+		if s, ok := v.(string); ok && s == refs {
+			return resolved
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// valuesResolver resolves the references in values, each reference once.
+type valuesResolver struct {
+	providers Providers
+	secrets   map[string]string
+}
+
+func (r *valuesResolver) resolve(ctx context.Context, v any) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		if v == nil {
+			return v, nil
+		}
+		m := make(map[string]any, len(v))
+		for key, val := range v {
+			resolved, err := r.resolve(ctx, val)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = resolved
+		}
+		return m, nil
+	case []any:
+		l := make([]any, len(v))
+		for n, val := range v {
+			resolved, err := r.resolve(ctx, val)
+			if err != nil {
+				return nil, err
+			}
+			l[n] = resolved
+		}
+		return l, nil
+	case string:
+		ref, ok := r.providers.ParseReference(v)
+		if !ok {
+			return v, nil
+		}
+		if secret, ok := r.secrets[v]; ok {
+			return secret, nil
+		}
+		resolver, err := r.providers.ByScheme(ref.Scheme)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := resolver.Resolve(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve secret %s: %w", v, err)
+		}
+		r.secrets[v] = secret
+		return secret, nil
+	default:
+		return v, nil
+	}
+}
+
+// secretField returns the field of a secret holding a JSON object, or the
+// whole secret if field is empty. Fields that are not strings are returned as
+// JSON.
+func secretField(secret, field string) (string, error) {
+	if field == "" {
+		return secret, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("the secret is not a JSON object with field %q", field)
+	}
+	return fieldValue(fields, field)
+}
+
+// fieldValue returns the field of the fields of a secret as a string.
+func fieldValue(fields map[string]any, field string) (string, error) {
+	v, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("the secret has no field %q", field)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapResolver resolves references from a map, counting the lookups.
+type mapResolver struct {
+	secrets map[string]string
+	lookups int
+}
+
+func (m *mapResolver) Resolve(_ context.Context, ref *url.URL) (string, error) {
+	m.lookups++
+	secret, ok := m.secrets[ref.String()]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return secret, nil
+}
+
+func TestResolveValues(t *testing.T) {
+	resolver := &mapResolver{secrets: map[string]string{
+		"test://db#password": "s3cr3t",
+		"test://api#token":   "t0k3n",
+	}}
+	providers := Providers{{Schemes: []string{"test"}, Resolver: resolver}}
+
+	vals := map[string]any{
+		"db": map[string]any{
+			"password": "test://db#password",
+			"host":     "https://db.example.com",
+			"port":     5432,
+		},
+		"tokens":  []any{"test://api#token", "plain"},
+		"replica": map[string]any{"password": "test://db#password"},
+		"none":    nil,
+	}
+	resolved, err := providers.ResolveValues(t.Context(), vals)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"db": map[string]any{
+			"password": "s3cr3t",
+			"host":     "https://db.example.com",
+			"port":     5432,
+		},
+		"tokens":  []any{"t0k3n", "plain"},
+		"replica": map[string]any{"password": "s3cr3t"},
+		"none":    nil,
+	}, resolved)
+	assert.Equal(t, 2, resolver.lookups, "each reference is resolved once")
+
+	// The values keep the references
+	assert.Equal(t, "test://db#password", vals["db"].(map[string]any)["password"])
+	assert.Equal(t, []any{"test://api#token", "plain"}, vals["tokens"])
+
+	_, err = providers.ResolveValues(t.Context(), map[string]any{"missing": "test://missing"})
+	assert.EqualError(t, err, "failed to resolve secret test://missing: not found")
+
+	// Without providers, no value is a reference
+	resolved, err = Providers(nil).ResolveValues(t.Context(), vals)
+	require.NoError(t, err)
+	assert.Equal(t, vals, resolved)
+}
+
+func TestResolveValuesAt(t *testing.T) {
+	resolver := &mapResolver{secrets: map[string]string{
+		"test://db#password": "s3cr3t",
+		"test://api#token":   "t0k3n",
+	}}
+	providers := Providers{{Schemes: []string{"test"}, Resolver: resolver}}
+
+	vals := map[string]any{
+		"db": map[string]any{
+			"password": "test://db#password",
+			"host":     "https://db.example.com",
+		},
+		"api":     map[string]any{"token": "test://api#token"},
+		"replica": map[string]any{"password": "test://db#password"},
+	}
+	refs := map[string]any{
+		"db": map[string]any{"password": "test://db#password"},
+	}
+	resolved, err := providers.ResolveValuesAt(t.Context(), vals, refs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"db": map[string]any{
+			"password": "s3cr3t",
+			"host":     "https://db.example.com",
+		},
+		"api":     map[string]any{"token": "test://api#token"},
+		"replica": map[string]any{"password": "test://db#password"},
+	}, resolved, "only the references at the keys of refs are resolved")
+	assert.Equal(t, 1, resolver.lookups)
+	assert.Equal(t, "test://db#password", vals["db"].(map[string]any)["password"])
+
+	// A value overriding the reference is kept
+	resolved, err = providers.ResolveValuesAt(t.Context(), map[string]any{"db": map[string]any{"password": "plain"}}, refs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"db": map[string]any{"password": "plain"}}, resolved)
+
+	// Without references, nothing is resolved
+	resolved, err = providers.ResolveValuesAt(t.Context(), vals, nil)
+	require.NoError(t, err)
+	assert.Equal(t, vals, resolved)
+}
+
+func TestParseReference(t *testing.T) {
+	providers := Default()
+	tests := []struct {
+		value string
+		ok    bool
+	}{
+		{"vault://secret/data/db#password", true},
+		{"awssm://prod/db?region=eu-west-1#password", true},
+		{"gcpsm://my-project/db", true},
+		{"https://example.com", false},
+		{"vault:secret", false},
+		{"plain", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ref, ok := providers.ParseReference(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, tt.value, ref.String())
+			}
+		})
+	}
+}
+
+func TestSecretField(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		field   string
+		expect  string
+		wantErr string
+	}{
+		{name: "whole secret", secret: "s3cr3t", expect: "s3cr3t"},
+		{name: "string field", secret: `{"user":"admin","password":"s3cr3t"}`, field: "password", expect: "s3cr3t"},
+		{name: "number field", secret: `{"port":5432}`, field: "port", expect: "5432"},
+		{name: "object field", secret: `{"tls":{"ca":"x"}}`, field: "tls", expect: `{"ca":"x"}`},
+		{name: "missing field", secret: `{"user":"admin"}`, field: "password", wantErr: `the secret has no field "password"`},
+		{name: "not an object", secret: "s3cr3t", field: "password", wantErr: `the secret is not a JSON object with field "password"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := secretField(tt.secret, tt.field)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
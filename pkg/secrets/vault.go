@@ -0,0 +1,141 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VaultScheme is the scheme of references to HashiCorp Vault secrets.
+const VaultScheme = "vault"
+
+// VaultResolver resolves references to the secrets of a HashiCorp Vault server,
+// such as vault://secret/data/db#password for the password field of the secret
+// read from the API path secret/data/db. Both versions of the key/value secrets
+// engine are supported. The field may be omitted for secrets with one field.
+type VaultResolver struct {
+	// Address is the address of the Vault server. If empty, the VAULT_ADDR
+	// environment variable is used.
+	Address string
+	// Token authenticates to the Vault server. If empty, the VAULT_TOKEN
+	// environment variable is used, or the ~/.vault-token file the vault CLI
+	// writes on login.
+	Token string
+	// Namespace is the Vault Enterprise namespace of the secrets. If empty, the
+	// VAULT_NAMESPACE environment variable is used.
+	Namespace string
+	// Client is the HTTP client to send requests with. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Resolve returns the secret ref points to.
+func (v *VaultResolver) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	address := v.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return "", errors.New("the address of the Vault server is not set: set VAULT_ADDR")
+	}
+	token, err := v.token()
+	if err != nil {
+		return "", err
+	}
+
+	secretPath := strings.Trim(ref.Host+ref.Path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(address, "/")+"/v1/"+secretPath, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	namespace := v.Namespace
+	if namespace == "" {
+		namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("reading %s from Vault failed with status %s", secretPath, resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("unable to parse the Vault secret %s: %w", secretPath, err)
+	}
+	fields := secret.Data
+	// Secrets of the version 2 of the key/value engine are wrapped with their metadata
+	if data, ok := fields["data"].(map[string]any); ok {
+		if _, ok := fields["metadata"]; ok {
+			fields = data
+		}
+	}
+
+	field := ref.Fragment
+	if field == "" {
+		if len(fields) != 1 {
+			return "", fmt.Errorf("the Vault secret %s has %d fields: select one with #field", secretPath, len(fields))
+		}
+		for f := range fields {
+			field = f
+		}
+	}
+	return fieldValue(fields, field)
+}
+
+// token returns the token authenticating to Vault.
+func (v *VaultResolver) token() (string, error) {
+	if v.Token != "" {
+		return v.Token, nil
+	}
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	home, err := os.UserHomeDir()
+	if err == nil {
+		if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+	}
+	return "", errors.New("no Vault token: set VAULT_TOKEN or log in with the vault CLI")
+}
@@ -0,0 +1,106 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/db":
+			fmt.Fprint(w, `{"data":{"data":{"user":"admin","password":"s3cr3t"},"metadata":{"version":3}}}`)
+		case "/v1/kv/api":
+			fmt.Fprint(w, `{"data":{"token":"t0k3n"}}`)
+		case "/v1/team/db":
+			if r.Header.Get("X-Vault-Namespace") != "team" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"password":"n5s3cr3t"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		ref       string
+		token     string
+		namespace string
+		expect    string
+		wantErr   string
+	}{
+		{name: "key/value version 2", ref: "vault://secret/data/db#password", expect: "s3cr3t"},
+		{name: "key/value version 1", ref: "vault://kv/api#token", expect: "t0k3n"},
+		{name: "single field", ref: "vault://kv/api", expect: "t0k3n"},
+		{name: "namespace", ref: "vault://team/db#password", namespace: "team", expect: "n5s3cr3t"},
+		{name: "several fields", ref: "vault://secret/data/db", wantErr: "the Vault secret secret/data/db has 2 fields: select one with #field"},
+		{name: "missing field", ref: "vault://secret/data/db#token", wantErr: `the secret has no field "token"`},
+		{name: "missing secret", ref: "vault://secret/data/missing#password", wantErr: "reading secret/data/missing from Vault failed with status 404 Not Found"},
+		{name: "bad token", ref: "vault://kv/api", token: "bad", wantErr: "reading kv/api from Vault failed with status 403 Forbidden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := tt.token
+			if token == "" {
+				token = "root"
+			}
+			resolver := &VaultResolver{Address: srv.URL, Token: token, Namespace: tt.namespace}
+			ref, err := url.Parse(tt.ref)
+			require.NoError(t, err)
+			got, err := resolver.Resolve(t.Context(), ref)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("VAULT_ADDR", srv.URL)
+		t.Setenv("VAULT_TOKEN", "root")
+		ref, err := url.Parse("vault://kv/api#token")
+		require.NoError(t, err)
+		got, err := (&VaultResolver{}).Resolve(t.Context(), ref)
+		require.NoError(t, err)
+		assert.Equal(t, "t0k3n", got)
+	})
+
+	t.Run("no address", func(t *testing.T) {
+		t.Setenv("VAULT_ADDR", "")
+		ref, err := url.Parse("vault://kv/api#token")
+		require.NoError(t, err)
+		_, err = (&VaultResolver{Token: "root"}).Resolve(t.Context(), ref)
+		assert.EqualError(t, err, "the address of the Vault server is not set: set VAULT_ADDR")
+	})
+}
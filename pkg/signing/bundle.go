@@ -0,0 +1,139 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// BundleMediaType is the media type of the bundles written by Helm. It is
+	// also the artifact type of the bundles attached to charts in registries.
+	BundleMediaType = "application/vnd.dev.sigstore.bundle.v0.3+json"
+
+	// BundleExt is the extension of the bundle stored next to a chart archive.
+	BundleExt = ".sigstore.json"
+
+	bundleMediaTypePrefix = "application/vnd.dev.sigstore.bundle"
+)
+
+// Bundle is a Sigstore bundle holding a keyless signature of a chart archive
+// along with the material needed to verify it.
+//
+// Only the fields used by Helm are modeled. Bundles are read and written in
+// the protobuf JSON encoding used by the other Sigstore clients.
+type Bundle struct {
+	MediaType            string               `json:"mediaType"`
+	VerificationMaterial VerificationMaterial `json:"verificationMaterial"`
+	MessageSignature     MessageSignature     `json:"messageSignature"`
+}
+
+// VerificationMaterial holds the signing certificate and the transparency log
+// entries of a signature.
+type VerificationMaterial struct {
+	// Certificate is the signing certificate, in bundles since v0.3.
+	Certificate *Certificate `json:"certificate,omitempty"`
+	// X509CertificateChain is the certificate chain, in older bundles.
+	X509CertificateChain *CertificateChain `json:"x509CertificateChain,omitempty"`
+	TlogEntries          []TlogEntry       `json:"tlogEntries"`
+}
+
+// Certificate is a DER encoded X.509 certificate.
+type Certificate struct {
+	RawBytes []byte `json:"rawBytes"`
+}
+
+// CertificateChain is a certificate chain, starting with the leaf certificate.
+type CertificateChain struct {
+	Certificates []Certificate `json:"certificates"`
+}
+
+// TlogEntry is an entry of a transparency log.
+type TlogEntry struct {
+	LogIndex          int64             `json:"logIndex,string"`
+	LogID             LogID             `json:"logId"`
+	KindVersion       KindVersion       `json:"kindVersion"`
+	IntegratedTime    int64             `json:"integratedTime,string"`
+	InclusionPromise  *InclusionPromise `json:"inclusionPromise,omitempty"`
+	CanonicalizedBody []byte            `json:"canonicalizedBody"`
+}
+
+// LogID identifies a transparency log by the digest of its public key.
+type LogID struct {
+	KeyID []byte `json:"keyId"`
+}
+
+// KindVersion is the type of a transparency log entry.
+type KindVersion struct {
+	Kind    string `json:"kind"`
+	Version string `json:"version"`
+}
+
+// InclusionPromise is the signed entry timestamp returned by the transparency
+// log, promising that the entry is included in the log.
+type InclusionPromise struct {
+	SignedEntryTimestamp []byte `json:"signedEntryTimestamp"`
+}
+
+// MessageSignature is the signature of the digest of a chart archive.
+type MessageSignature struct {
+	MessageDigest MessageDigest `json:"messageDigest"`
+	Signature     []byte        `json:"signature"`
+}
+
+// MessageDigest is the digest of a chart archive.
+type MessageDigest struct {
+	Algorithm string `json:"algorithm"`
+	Digest    []byte `json:"digest"`
+}
+
+// ParseBundle parses a Sigstore bundle.
+func ParseBundle(data []byte) (*Bundle, error) {
+	b := &Bundle{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("unable to parse Sigstore bundle: %w", err)
+	}
+	if !strings.HasPrefix(b.MediaType, bundleMediaTypePrefix) {
+		return nil, fmt.Errorf("unsupported Sigstore bundle media type %q", b.MediaType)
+	}
+	return b, nil
+}
+
+// LoadBundle reads a Sigstore bundle file.
+func LoadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBundle(data)
+}
+
+// certificate returns the signing certificate of the bundle.
+func (b *Bundle) certificate() ([]byte, error) {
+	vm := b.VerificationMaterial
+	switch {
+	case vm.Certificate != nil:
+		return vm.Certificate.RawBytes, nil
+	case vm.X509CertificateChain != nil && len(vm.X509CertificateChain.Certificates) > 0:
+		return vm.X509CertificateChain.Certificates[0].RawBytes, nil
+	}
+	return nil, errors.New("the Sigstore bundle has no signing certificate")
+}
@@ -0,0 +1,33 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package signing implements Sigstore keyless signing and verification of charts.
+
+Keyless signing does not need a long-lived key. The signer proves its identity
+with an OIDC token, such as the ones issued to CI workflows, and Fulcio issues
+a short-lived certificate for that identity to an ephemeral key. The signature
+is then recorded in the Rekor transparency log, so that it can be verified
+after the certificate has expired.
+
+The certificate, the signature and the transparency log entry are stored in a
+Sigstore bundle, next to the chart archive with the .sigstore.json extension,
+or attached to the chart in OCI registries. Verifying a chart checks the
+bundle against the certificate authorities and transparency logs of a Sigstore
+trusted root, and that the certificate was issued to the expected identity by
+the expected OIDC issuer.
+*/
+package signing // import "helm.sh/helm/v4/pkg/signing"
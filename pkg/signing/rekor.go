@@ -0,0 +1,120 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	hashedRekordKind    = "hashedrekord"
+	hashedRekordVersion = "0.0.1"
+)
+
+// hashedRekord is a Rekor entry recording the signature of a digest.
+type hashedRekord struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Spec       hashedRekordSpec `json:"spec"`
+}
+
+type hashedRekordSpec struct {
+	Signature hashedRekordSignature `json:"signature"`
+	Data      hashedRekordData      `json:"data"`
+}
+
+type hashedRekordSignature struct {
+	Content   []byte `json:"content"`
+	PublicKey struct {
+		// Content is the PEM encoded signing certificate.
+		Content []byte `json:"content"`
+	} `json:"publicKey"`
+}
+
+type hashedRekordData struct {
+	Hash struct {
+		Algorithm string `json:"algorithm"`
+		Value     string `json:"value"`
+	} `json:"hash"`
+}
+
+// rekorLogEntry is an entry returned by the Rekor API.
+type rekorLogEntry struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+	Verification   struct {
+		SignedEntryTimestamp []byte `json:"signedEntryTimestamp"`
+	} `json:"verification"`
+}
+
+// inclusionPromisePayload returns the payload signed by Rekor in the signed
+// entry timestamp of an entry. It is the canonical JSON encoding of the entry,
+// with its keys in lexical order.
+func inclusionPromisePayload(entry *TlogEntry) ([]byte, error) {
+	return json.Marshal(struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{
+		Body:           base64.StdEncoding.EncodeToString(entry.CanonicalizedBody),
+		IntegratedTime: entry.IntegratedTime,
+		LogID:          hex.EncodeToString(entry.LogID.KeyID),
+		LogIndex:       entry.LogIndex,
+	})
+}
+
+// verifyInclusionPromise checks that the entry was signed by a transparency
+// log of the trusted root.
+func (r *TrustedRoot) verifyInclusionPromise(entry *TlogEntry) error {
+	if entry.InclusionPromise == nil {
+		return errors.New("the transparency log entry has no inclusion promise")
+	}
+	key, ok := r.tlogKeys[string(entry.LogID.KeyID)]
+	if !ok {
+		return fmt.Errorf("the transparency log %x is not trusted", entry.LogID.KeyID)
+	}
+	payload, err := inclusionPromisePayload(entry)
+	if err != nil {
+		return err
+	}
+
+	sig := entry.InclusionPromise.SignedEntryTimestamp
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		if ecdsa.VerifyASN1(key, digest[:], sig) {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(key, payload, sig) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported transparency log key type %T", key)
+	}
+	return errors.New("invalid signed entry timestamp of the transparency log entry")
+}
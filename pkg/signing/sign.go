@@ -0,0 +1,275 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signing
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	// DefaultFulcioURL is the URL of the public Sigstore certificate authority.
+	DefaultFulcioURL = "https://fulcio.sigstore.dev"
+
+	// DefaultRekorURL is the URL of the public Sigstore transparency log.
+	DefaultRekorURL = "https://rekor.sigstore.dev"
+
+	// IdentityTokenEnvVar is the environment variable holding the OIDC token
+	// used for keyless signing, as with the other Sigstore clients.
+	IdentityTokenEnvVar = "SIGSTORE_ID_TOKEN"
+)
+
+// Signer signs chart archives with a short-lived certificate issued by Fulcio
+// to an OIDC identity, and records the signatures in Rekor.
+type Signer struct {
+	// IdentityToken is the OIDC token proving the identity of the signer.
+	IdentityToken string
+	// FulcioURL is the URL of the certificate authority.
+	FulcioURL string
+	// RekorURL is the URL of the transparency log.
+	RekorURL string
+	// HTTPClient is the client used to reach Fulcio and Rekor. The default
+	// client is used when nil.
+	HTTPClient *http.Client
+}
+
+// Sign signs data and returns the bundle of the signature.
+func (s *Signer) Sign(data []byte) (*Bundle, error) {
+	subject, err := tokenSubject(s.IdentityToken)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM, err := s.signingCertificate(key, subject)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("unable to decode the signing certificate issued by Fulcio")
+	}
+
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.recordSignature(certPEM, sig, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bundle{
+		MediaType: BundleMediaType,
+		VerificationMaterial: VerificationMaterial{
+			Certificate: &Certificate{RawBytes: block.Bytes},
+			TlogEntries: []TlogEntry{*entry},
+		},
+		MessageSignature: MessageSignature{
+			MessageDigest: MessageDigest{Algorithm: "SHA2_256", Digest: digest[:]},
+			Signature:     sig,
+		},
+	}, nil
+}
+
+// SignFile signs the file at path and writes the bundle next to it. It
+// returns the path of the bundle.
+func (s *Signer) SignFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	b, err := s.Sign(data)
+	if err != nil {
+		return "", err
+	}
+	out, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	bundlePath := path + BundleExt
+	return bundlePath, os.WriteFile(bundlePath, append(out, '\n'), 0644)
+}
+
+// tokenSubject returns the identity an OIDC token was issued to. The token is
+// not verified: Fulcio does so before issuing a certificate.
+func tokenSubject(token string) (string, error) {
+	if token == "" {
+		return "", errors.New("an OIDC identity token is required for keyless signing")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("invalid OIDC identity token: expected a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid OIDC identity token: %w", err)
+	}
+	var claims struct {
+		Email   string `json:"email"`
+		Subject string `json:"sub"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("invalid OIDC identity token: %w", err)
+	}
+	if claims.Email != "" {
+		return claims.Email, nil
+	}
+	if claims.Subject != "" {
+		return claims.Subject, nil
+	}
+	return "", errors.New("invalid OIDC identity token: no subject")
+}
+
+type fulcioRequest struct {
+	Credentials struct {
+		OIDCIdentityToken string `json:"oidcIdentityToken"`
+	} `json:"credentials"`
+	PublicKeyRequest struct {
+		PublicKey struct {
+			Algorithm string `json:"algorithm"`
+			Content   string `json:"content"`
+		} `json:"publicKey"`
+		ProofOfPossession []byte `json:"proofOfPossession"`
+	} `json:"publicKeyRequest"`
+}
+
+type fulcioChain struct {
+	Chain struct {
+		Certificates []string `json:"certificates"`
+	} `json:"chain"`
+}
+
+type fulcioResponse struct {
+	SignedCertificateEmbeddedSct *fulcioChain `json:"signedCertificateEmbeddedSct"`
+	SignedCertificateDetachedSct *fulcioChain `json:"signedCertificateDetachedSct"`
+}
+
+// signingCertificate requests a certificate for key from Fulcio, and returns
+// it PEM encoded.
+func (s *Signer) signingCertificate(key *ecdsa.PrivateKey, subject string) ([]byte, error) {
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	// Prove the possession of the key by signing the subject of the token
+	subjectDigest := sha256.Sum256([]byte(subject))
+	proof, err := ecdsa.SignASN1(rand.Reader, key, subjectDigest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	var req fulcioRequest
+	req.Credentials.OIDCIdentityToken = s.IdentityToken
+	req.PublicKeyRequest.PublicKey.Algorithm = "ECDSA"
+	req.PublicKeyRequest.PublicKey.Content = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}))
+	req.PublicKeyRequest.ProofOfPossession = proof
+
+	var resp fulcioResponse
+	if err := s.post(strings.TrimSuffix(s.FulcioURL, "/")+"/api/v2/signingCert", req, &resp); err != nil {
+		return nil, fmt.Errorf("unable to get a signing certificate from Fulcio: %w", err)
+	}
+	chain := resp.SignedCertificateEmbeddedSct
+	if chain == nil {
+		chain = resp.SignedCertificateDetachedSct
+	}
+	if chain == nil || len(chain.Chain.Certificates) == 0 {
+		return nil, errors.New("unable to get a signing certificate from Fulcio: empty response")
+	}
+	return []byte(chain.Chain.Certificates[0]), nil
+}
+
+// recordSignature adds the signature of digest to Rekor and returns the entry.
+func (s *Signer) recordSignature(certPEM, sig, digest []byte) (*TlogEntry, error) {
+	rekord := hashedRekord{
+		APIVersion: hashedRekordVersion,
+		Kind:       hashedRekordKind,
+	}
+	rekord.Spec.Signature.Content = sig
+	rekord.Spec.Signature.PublicKey.Content = certPEM
+	rekord.Spec.Data.Hash.Algorithm = "sha256"
+	rekord.Spec.Data.Hash.Value = hex.EncodeToString(digest)
+
+	var resp map[string]rekorLogEntry
+	if err := s.post(strings.TrimSuffix(s.RekorURL, "/")+"/api/v1/log/entries", rekord, &resp); err != nil {
+		return nil, fmt.Errorf("unable to record the signature in Rekor: %w", err)
+	}
+	for _, e := range resp {
+		body, err := base64.StdEncoding.DecodeString(e.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Rekor entry: %w", err)
+		}
+		logID, err := hex.DecodeString(e.LogID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Rekor entry: %w", err)
+		}
+		return &TlogEntry{
+			LogIndex:          e.LogIndex,
+			LogID:             LogID{KeyID: logID},
+			KindVersion:       KindVersion{Kind: hashedRekordKind, Version: hashedRekordVersion},
+			IntegratedTime:    e.IntegratedTime,
+			InclusionPromise:  &InclusionPromise{SignedEntryTimestamp: e.Verification.SignedEntryTimestamp},
+			CanonicalizedBody: body,
+		}, nil
+	}
+	return nil, errors.New("unable to record the signature in Rekor: empty response")
+}
+
+// post sends v as JSON to url and decodes the response into out.
+func (s *Signer) post(url string, v, out any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, out)
+}
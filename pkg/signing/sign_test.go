@@ -0,0 +1,210 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/signing/signingtest"
+)
+
+const (
+	testIdentity = "helm-testing@helm.sh"
+	testIssuer   = "https://issuer.example.com"
+)
+
+func newTestSigner(srv *signingtest.Server) *Signer {
+	return &Signer{
+		IdentityToken: signingtest.Token(testIdentity, testIssuer),
+		FulcioURL:     srv.URL,
+		RekorURL:      srv.URL,
+	}
+}
+
+func TestSignAndVerifyFile(t *testing.T) {
+	srv := signingtest.NewServer(t)
+	chart := filepath.Join(t.TempDir(), "signtest-0.1.0.tgz")
+	if err := os.WriteFile(chart, []byte("chart archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundlePath, err := newTestSigner(srv).SignFile(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundlePath != chart+BundleExt {
+		t.Errorf("expected the bundle next to the chart, got %s", bundlePath)
+	}
+
+	root, err := LoadTrustedRoot(srv.TrustedRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := &Verifier{TrustedRoot: root, CertificateIdentity: testIdentity, CertificateOIDCIssuer: testIssuer}
+	ver, err := verifier.VerifyFile(chart, bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ver.Identity != testIdentity || ver.Issuer != testIssuer {
+		t.Errorf("unexpected verification %+v", ver)
+	}
+	if ver.IntegratedTime.IsZero() {
+		t.Error("expected the time the signature was recorded")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	srv := signingtest.NewServer(t)
+	data := []byte("chart archive")
+	root, err := LoadTrustedRoot(srv.TrustedRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRoot, err := LoadTrustedRoot(signingtest.NewServer(t).TrustedRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		verifier Verifier
+		data     []byte
+		tamper   func(*Bundle)
+		err      string
+	}{
+		{
+			name: "valid signature",
+		},
+		{
+			name:     "other identity",
+			verifier: Verifier{CertificateIdentity: "someone@example.com"},
+			err:      "the chart was signed by [helm-testing@helm.sh], not someone@example.com",
+		},
+		{
+			name:     "other issuer",
+			verifier: Verifier{CertificateOIDCIssuer: "https://other.example.com"},
+			err:      "issued by https://issuer.example.com, not https://other.example.com",
+		},
+		{
+			name: "modified chart",
+			data: []byte("modified chart archive"),
+			err:  "digest mismatch",
+		},
+		{
+			name:     "untrusted signer",
+			verifier: Verifier{TrustedRoot: otherRoot},
+			err:      "is not trusted",
+		},
+		{
+			name: "forged log entry",
+			tamper: func(b *Bundle) {
+				b.VerificationMaterial.TlogEntries[0].IntegratedTime++
+			},
+			err: "invalid signed entry timestamp",
+		},
+		{
+			name: "not recorded in the log",
+			tamper: func(b *Bundle) {
+				b.VerificationMaterial.TlogEntries = nil
+			},
+			err: "not recorded in a transparency log",
+		},
+		{
+			name: "forged signature",
+			tamper: func(b *Bundle) {
+				b.MessageSignature.Signature[len(b.MessageSignature.Signature)-1] ^= 1
+			},
+			err: "invalid signature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := newTestSigner(srv).Sign(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.tamper != nil {
+				tt.tamper(b)
+			}
+			v := Verifier{TrustedRoot: root, CertificateIdentity: testIdentity, CertificateOIDCIssuer: testIssuer}
+			if tt.verifier.TrustedRoot != nil {
+				v.TrustedRoot = tt.verifier.TrustedRoot
+			}
+			if tt.verifier.CertificateIdentity != "" {
+				v.CertificateIdentity = tt.verifier.CertificateIdentity
+			}
+			if tt.verifier.CertificateOIDCIssuer != "" {
+				v.CertificateOIDCIssuer = tt.verifier.CertificateOIDCIssuer
+			}
+			signed := data
+			if tt.data != nil {
+				signed = tt.data
+			}
+
+			_, err = v.Verify(signed, b)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestSignInvalidToken(t *testing.T) {
+	srv := signingtest.NewServer(t)
+	for token, expect := range map[string]string{
+		"":          "an OIDC identity token is required",
+		"not-a-jwt": "expected a JWT",
+	} {
+		s := newTestSigner(srv)
+		s.IdentityToken = token
+		if _, err := s.Sign([]byte("data")); err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("expected error containing %q, got %v", expect, err)
+		}
+	}
+}
+
+func TestParseBundle(t *testing.T) {
+	if _, err := ParseBundle([]byte(`{"mediaType":"application/json"}`)); err == nil {
+		t.Error("expected an error for an unknown media type")
+	}
+	b, err := ParseBundle([]byte(`{"mediaType":"application/vnd.dev.sigstore.bundle+json;version=0.2",
+		"verificationMaterial":{"x509CertificateChain":{"certificates":[{"rawBytes":"AQI="}]},
+		"tlogEntries":[{"logIndex":"42","integratedTime":"1700000000","logId":{"keyId":"AQ=="}}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := b.certificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cert) != "\x01\x02" {
+		t.Errorf("unexpected certificate %v", cert)
+	}
+	if entry := b.VerificationMaterial.TlogEntries[0]; entry.LogIndex != 42 || entry.IntegratedTime != 1700000000 {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
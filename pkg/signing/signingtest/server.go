@@ -0,0 +1,279 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package signingtest provides a fake Sigstore certificate authority and
+transparency log for testing keyless signing.
+*/
+package signingtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// oidIssuerV2 is the Fulcio extension holding the OIDC issuer.
+var oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+
+// Server is a fake Fulcio certificate authority and Rekor transparency log,
+// serving both APIs from the same URL.
+type Server struct {
+	*httptest.Server
+	// TrustedRoot is the path of a trusted_root.json trusting the server.
+	TrustedRoot string
+
+	ca       *x509.Certificate
+	caKey    *ecdsa.PrivateKey
+	logKey   *ecdsa.PrivateKey
+	logID    []byte
+	mu       sync.Mutex
+	logIndex int64
+}
+
+// NewServer starts a server, stopped at the end of the test.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+	s := &Server{}
+
+	var err error
+	s.caKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signingtest"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &s.caKey.PublicKey, s.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ca, err = x509.ParseCertificate(caDER); err != nil {
+		t.Fatal(err)
+	}
+
+	s.logKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logPub, err := x509.MarshalPKIXPublicKey(&s.logKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logID := sha256.Sum256(logPub)
+	s.logID = logID[:]
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/signingCert", s.signingCert)
+	mux.HandleFunc("POST /api/v1/log/entries", s.logEntry)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	trustedRoot, err := json.Marshal(map[string]any{
+		"mediaType": "application/vnd.dev.sigstore.trustedroot+json;version=0.1",
+		"tlogs": []any{map[string]any{
+			"baseUrl":       s.URL,
+			"hashAlgorithm": "SHA2_256",
+			"publicKey": map[string]any{
+				"rawBytes":   logPub,
+				"keyDetails": "PKIX_ECDSA_P256_SHA_256",
+			},
+			"logId": map[string]any{"keyId": s.logID},
+		}},
+		"certificateAuthorities": []any{map[string]any{
+			"uri":       s.URL,
+			"certChain": map[string]any{"certificates": []any{map[string]any{"rawBytes": caDER}}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.TrustedRoot = filepath.Join(t.TempDir(), "trusted_root.json")
+	if err := os.WriteFile(s.TrustedRoot, trustedRoot, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// Token returns an unsigned OIDC identity token for email, issued by issuer.
+func Token(email, issuer string) string {
+	encode := func(v any) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	return strings.Join([]string{
+		encode(map[string]string{"alg": "none"}),
+		encode(map[string]string{"iss": issuer, "sub": email, "email": email}),
+		"",
+	}, ".")
+}
+
+func (s *Server) signingCert(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Credentials struct {
+			OIDCIdentityToken string `json:"oidcIdentityToken"`
+		} `json:"credentials"`
+		PublicKeyRequest struct {
+			PublicKey struct {
+				Content string `json:"content"`
+			} `json:"publicKey"`
+			ProofOfPossession []byte `json:"proofOfPossession"`
+		} `json:"publicKeyRequest"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	parts := strings.Split(req.Credentials.OIDCIdentityToken, ".")
+	if len(parts) != 3 {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+		Email  string `json:"email"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Email == "" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	block, _ := pem.Decode([]byte(req.PublicKeyRequest.PublicKey.Content))
+	if block == nil {
+		http.Error(w, "invalid public key", http.StatusBadRequest)
+		return
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ecPub, ok := pub.(*ecdsa.PublicKey)
+	digest := sha256.Sum256([]byte(claims.Email))
+	if !ok || !ecdsa.VerifyASN1(ecPub, digest[:], req.PublicKeyRequest.ProofOfPossession) {
+		http.Error(w, "invalid proof of possession", http.StatusBadRequest)
+		return
+	}
+
+	issuer, err := asn1.MarshalWithParams(claims.Issuer, "utf8")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(time.Now().UnixNano()),
+		NotBefore:       time.Now().Add(-time.Minute),
+		NotAfter:        time.Now().Add(10 * time.Minute),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		EmailAddresses:  []string{claims.Email},
+		ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: issuer}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, s.ca, pub, s.caKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	encode := func(der []byte) string {
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"signedCertificateEmbeddedSct": map[string]any{
+			"chain": map[string]any{"certificates": []string{encode(der), encode(s.ca.Raw)}},
+		},
+	})
+}
+
+func (s *Server) logEntry(w http.ResponseWriter, r *http.Request) {
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	logIndex := s.logIndex
+	s.logIndex++
+	s.mu.Unlock()
+
+	entry := struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{
+		Body:           base64.StdEncoding.EncodeToString(body),
+		IntegratedTime: time.Now().Unix(),
+		LogID:          hex.EncodeToString(s.logID),
+		LogIndex:       logIndex,
+	}
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	digest := sha256.Sum256(payload)
+	set, err := ecdsa.SignASN1(rand.Reader, s.logKey, digest[:])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		fmt.Sprintf("%x", digest): map[string]any{
+			"body":           entry.Body,
+			"integratedTime": entry.IntegratedTime,
+			"logID":          entry.LogID,
+			"logIndex":       entry.LogIndex,
+			"verification":   map[string]any{"signedEntryTimestamp": set},
+		},
+	})
+}
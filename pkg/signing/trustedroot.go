@@ -0,0 +1,103 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signing
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// TrustedRoot holds the certificate authorities and the transparency logs
+// trusted to verify keyless signatures.
+type TrustedRoot struct {
+	roots         *x509.CertPool
+	intermediates *x509.CertPool
+	// tlogKeys are the public keys of the transparency logs, by log ID
+	tlogKeys map[string]any
+}
+
+// trustedRootJSON is the subset of the Sigstore trusted root used by Helm.
+type trustedRootJSON struct {
+	Tlogs []struct {
+		PublicKey struct {
+			RawBytes []byte `json:"rawBytes"`
+		} `json:"publicKey"`
+		LogID LogID `json:"logId"`
+	} `json:"tlogs"`
+	CertificateAuthorities []struct {
+		CertChain CertificateChain `json:"certChain"`
+	} `json:"certificateAuthorities"`
+}
+
+// ParseTrustedRoot parses a Sigstore trusted root, in the trusted_root.json
+// format distributed by the Sigstore TUF repositories.
+func ParseTrustedRoot(data []byte) (*TrustedRoot, error) {
+	var tr trustedRootJSON
+	if err := json.Unmarshal(data, &tr); err != nil {
+		return nil, fmt.Errorf("unable to parse trusted root: %w", err)
+	}
+
+	root := &TrustedRoot{
+		roots:         x509.NewCertPool(),
+		intermediates: x509.NewCertPool(),
+		tlogKeys:      map[string]any{},
+	}
+	for _, tlog := range tr.Tlogs {
+		key, err := x509.ParsePKIXPublicKey(tlog.PublicKey.RawBytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse transparency log key: %w", err)
+		}
+		root.tlogKeys[string(tlog.LogID.KeyID)] = key
+	}
+	var roots int
+	for _, ca := range tr.CertificateAuthorities {
+		for _, c := range ca.CertChain.Certificates {
+			cert, err := x509.ParseCertificate(c.RawBytes)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse certificate authority: %w", err)
+			}
+			// The chains end with a self-signed root certificate
+			if bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(cert) == nil {
+				root.roots.AddCert(cert)
+				roots++
+			} else {
+				root.intermediates.AddCert(cert)
+			}
+		}
+	}
+
+	if len(root.tlogKeys) == 0 {
+		return nil, errors.New("the trusted root has no transparency log")
+	}
+	if roots == 0 {
+		return nil, errors.New("the trusted root has no root certificate authority")
+	}
+	return root, nil
+}
+
+// LoadTrustedRoot reads a Sigstore trusted root file.
+func LoadTrustedRoot(path string) (*TrustedRoot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTrustedRoot(data)
+}
@@ -0,0 +1,203 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package signing
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+var (
+	// oidIssuerV2 is the Fulcio extension holding the OIDC issuer, as a DER
+	// encoded string.
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+	// oidIssuer is the deprecated Fulcio extension holding the OIDC issuer.
+	oidIssuer = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+)
+
+// Verifier verifies keyless signatures.
+type Verifier struct {
+	// TrustedRoot holds the trusted certificate authorities and transparency logs.
+	TrustedRoot *TrustedRoot
+	// CertificateIdentity is the expected identity of the signer, such as an
+	// email address or the URI of a CI workflow.
+	CertificateIdentity string
+	// CertificateOIDCIssuer is the expected OIDC issuer of the identity.
+	CertificateOIDCIssuer string
+}
+
+// Verification describes a verified keyless signature.
+type Verification struct {
+	// Identity is the identity of the signer.
+	Identity string
+	// Issuer is the OIDC issuer of the identity.
+	Issuer string
+	// LogIndex is the index of the signature in the transparency log.
+	LogIndex int64
+	// IntegratedTime is when the signature was added to the transparency log.
+	IntegratedTime time.Time
+}
+
+// Verify checks that b is a valid signature of data by the expected identity.
+//
+// The signature must have been recorded in a trusted transparency log while
+// the signing certificate, issued by a trusted certificate authority, was
+// valid.
+func (v *Verifier) Verify(data []byte, b *Bundle) (*Verification, error) {
+	if v.TrustedRoot == nil {
+		return nil, errors.New("a trusted root is required to verify keyless signatures")
+	}
+	if v.CertificateIdentity == "" || v.CertificateOIDCIssuer == "" {
+		return nil, errors.New("the certificate identity and OIDC issuer are required to verify keyless signatures")
+	}
+
+	der, err := b.certificate()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the signing certificate: %w", err)
+	}
+
+	if len(b.VerificationMaterial.TlogEntries) == 0 {
+		return nil, errors.New("the signature was not recorded in a transparency log")
+	}
+	entry := &b.VerificationMaterial.TlogEntries[0]
+	if err := v.TrustedRoot.verifyInclusionPromise(entry); err != nil {
+		return nil, err
+	}
+	integratedTime := time.Unix(entry.IntegratedTime, 0)
+
+	// The certificate is short-lived: it must have been valid when the
+	// signature was recorded, not now.
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         v.TrustedRoot.roots,
+		Intermediates: v.TrustedRoot.intermediates,
+		CurrentTime:   integratedTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return nil, fmt.Errorf("the signing certificate is not trusted: %w", err)
+	}
+
+	identities := certificateIdentities(cert)
+	if !slices.Contains(identities, v.CertificateIdentity) {
+		return nil, fmt.Errorf("the chart was signed by %v, not %s", identities, v.CertificateIdentity)
+	}
+	issuer, err := certificateIssuer(cert)
+	if err != nil {
+		return nil, err
+	}
+	if issuer != v.CertificateOIDCIssuer {
+		return nil, fmt.Errorf("the signer identity was issued by %s, not %s", issuer, v.CertificateOIDCIssuer)
+	}
+
+	digest := sha256.Sum256(data)
+	sig := b.MessageSignature.Signature
+	if !bytes.Equal(b.MessageSignature.MessageDigest.Digest, digest[:]) {
+		return nil, errors.New("the signature does not match the chart: digest mismatch")
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key type %T", cert.PublicKey)
+	}
+	if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+		return nil, errors.New("invalid signature of the chart")
+	}
+
+	if err := checkLogEntry(entry, cert, sig, digest[:]); err != nil {
+		return nil, err
+	}
+
+	return &Verification{
+		Identity:       v.CertificateIdentity,
+		Issuer:         issuer,
+		LogIndex:       entry.LogIndex,
+		IntegratedTime: integratedTime,
+	}, nil
+}
+
+// VerifyFile verifies the file at path with the bundle at bundlePath.
+func (v *Verifier) VerifyFile(path, bundlePath string) (*Verification, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := LoadBundle(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	return v.Verify(data, b)
+}
+
+// checkLogEntry checks that the transparency log entry records the signature.
+func checkLogEntry(entry *TlogEntry, cert *x509.Certificate, sig, digest []byte) error {
+	var rekord hashedRekord
+	if err := json.Unmarshal(entry.CanonicalizedBody, &rekord); err != nil {
+		return fmt.Errorf("unable to parse the transparency log entry: %w", err)
+	}
+	if rekord.Kind != hashedRekordKind {
+		return fmt.Errorf("unsupported transparency log entry kind %q", rekord.Kind)
+	}
+	block, _ := pem.Decode(rekord.Spec.Signature.PublicKey.Content)
+	if block == nil || !bytes.Equal(block.Bytes, cert.Raw) ||
+		!bytes.Equal(rekord.Spec.Signature.Content, sig) ||
+		rekord.Spec.Data.Hash.Value != hex.EncodeToString(digest) {
+		return errors.New("the transparency log entry does not match the signature")
+	}
+	return nil
+}
+
+// certificateIdentities returns the identities a Fulcio certificate was issued to.
+func certificateIdentities(cert *x509.Certificate) []string {
+	identities := slices.Clone(cert.EmailAddresses)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	return identities
+}
+
+// certificateIssuer returns the OIDC issuer of the identity of a Fulcio
+// certificate.
+func certificateIssuer(cert *x509.Certificate) (string, error) {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidIssuerV2) {
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err != nil {
+				return "", fmt.Errorf("invalid OIDC issuer extension: %w", err)
+			}
+			return issuer, nil
+		}
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidIssuer) {
+			return string(ext.Value), nil
+		}
+	}
+	return "", errors.New("the signing certificate has no OIDC issuer")
+}
@@ -45,6 +45,9 @@ const ConfigMapsDriverName = "ConfigMap"
 // ConfigMapsInterface.
 type ConfigMaps struct {
 	impl corev1.ConfigMapInterface
+	// listChunkSize is the maximum number of ConfigMaps retrieved per
+	// request when listing releases, zero retrieves them all at once.
+	listChunkSize int64
 
 	// Embed a LogHolder to provide logger functionality
 	logging.LogHolder
@@ -65,6 +68,12 @@ func (cfgmaps *ConfigMaps) Name() string {
 	return ConfigMapsDriverName
 }
 
+// SetListChunkSize sets the maximum number of ConfigMaps retrieved per
+// request when listing or querying releases.
+func (cfgmaps *ConfigMaps) SetListChunkSize(size int64) {
+	cfgmaps.listChunkSize = size
+}
+
 // Get fetches the release named by key. The corresponding release is returned
 // or error if not found.
 func (cfgmaps *ConfigMaps) Get(key string) (release.Releaser, error) {
@@ -96,21 +105,15 @@ func (cfgmaps *ConfigMaps) List(filter func(release.Releaser) bool) ([]release.R
 	lsel := kblabels.Set{"owner": "helm"}.AsSelector()
 	opts := metav1.ListOptions{LabelSelector: lsel.String()}
 
-	list, err := cfgmaps.impl.List(context.Background(), opts)
-	if err != nil {
-		cfgmaps.Logger().Debug("failed to list releases", slog.Any("error", err))
-		return nil, err
-	}
-
 	var results []release.Releaser
 
-	// iterate over the configmaps object list
-	// and decode each release
-	for _, item := range list.Items {
+	// decode each release as the chunks of the list are retrieved, so that
+	// only the matching ones are kept in memory
+	err := cfgmaps.list(opts, func(item *v1.ConfigMap) {
 		rls, err := decodeRelease(item.Data["release"])
 		if err != nil {
 			cfgmaps.Logger().Debug("failed to decode release", slog.Any("item", item), slog.Any("error", err))
-			continue
+			return
 		}
 
 		rls.Labels = item.Labels
@@ -118,6 +121,10 @@ func (cfgmaps *ConfigMaps) List(filter func(release.Releaser) bool) ([]release.R
 		if filter(rls) {
 			results = append(results, rls)
 		}
+	})
+	if err != nil {
+		cfgmaps.Logger().Debug("failed to list releases", slog.Any("error", err))
+		return nil, err
 	}
 	return results, nil
 }
@@ -135,27 +142,49 @@ func (cfgmaps *ConfigMaps) Query(labels map[string]string) ([]release.Releaser,
 
 	opts := metav1.ListOptions{LabelSelector: ls.AsSelector().String()}
 
-	list, err := cfgmaps.impl.List(context.Background(), opts)
+	var (
+		found   bool
+		results []release.Releaser
+	)
+	err := cfgmaps.list(opts, func(item *v1.ConfigMap) {
+		found = true
+		rls, err := decodeRelease(item.Data["release"])
+		if err != nil {
+			cfgmaps.Logger().Debug("failed to decode release", slog.Any("error", err))
+			return
+		}
+		rls.Labels = item.Labels
+		results = append(results, rls)
+	})
 	if err != nil {
 		cfgmaps.Logger().Debug("failed to query with labels", slog.Any("error", err))
 		return nil, err
 	}
 
-	if len(list.Items) == 0 {
+	if !found {
 		return nil, ErrReleaseNotFound
 	}
+	return results, nil
+}
 
-	var results []release.Releaser
-	for _, item := range list.Items {
-		rls, err := decodeRelease(item.Data["release"])
+// list lists the ConfigMaps matching opts and calls fn for each of them. The
+// ConfigMaps are retrieved in chunks of listChunkSize, following the continue
+// token of each chunk.
+func (cfgmaps *ConfigMaps) list(opts metav1.ListOptions, fn func(*v1.ConfigMap)) error {
+	opts.Limit = cfgmaps.listChunkSize
+	for {
+		list, err := cfgmaps.impl.List(context.Background(), opts)
 		if err != nil {
-			cfgmaps.Logger().Debug("failed to decode release", slog.Any("error", err))
-			continue
+			return err
 		}
-		rls.Labels = item.Labels
-		results = append(results, rls)
+		for i := range list.Items {
+			fn(&list.Items[i])
+		}
+		if list.Continue == "" {
+			return nil
+		}
+		opts.Continue = list.Continue
 	}
-	return results, nil
 }
 
 // Create creates a new ConfigMap holding the release. If the
@@ -161,6 +161,50 @@ func TestConfigMapList(t *testing.T) {
 	}
 }
 
+func TestConfigMapListChunks(t *testing.T) {
+	cfgmaps := newTestFixtureCfgMaps(t, []*rspb.Release{
+		releaseStub("key-1", 1, "default", common.StatusDeployed),
+		releaseStub("key-2", 1, "default", common.StatusDeployed),
+		releaseStub("key-3", 1, "default", common.StatusDeployed),
+		releaseStub("key-4", 1, "default", common.StatusSuperseded),
+		releaseStub("key-5", 1, "default", common.StatusSuperseded),
+	}...)
+	mock := cfgmaps.impl.(*MockConfigMapsInterface)
+	cfgmaps.SetListChunkSize(2)
+
+	all, err := cfgmaps.List(func(release.Releaser) bool { return true })
+	if err != nil {
+		t.Fatalf("Failed to list: %s", err)
+	}
+	if len(all) != 5 {
+		t.Errorf("Expected 5 releases, got %d", len(all))
+	}
+	if mock.lists != 3 {
+		t.Errorf("Expected 3 list requests, got %d", mock.lists)
+	}
+
+	mock.lists = 0
+	dpl, err := cfgmaps.Query(map[string]string{"status": "deployed"})
+	if err != nil {
+		t.Fatalf("Failed to query: %s", err)
+	}
+	if len(dpl) != 3 {
+		t.Errorf("Expected 3 deployed, got %d", len(dpl))
+	}
+	if mock.lists != 2 {
+		t.Errorf("Expected 2 list requests, got %d", mock.lists)
+	}
+
+	mock.lists = 0
+	cfgmaps.SetListChunkSize(0)
+	if _, err := cfgmaps.List(func(release.Releaser) bool { return true }); err != nil {
+		t.Fatalf("Failed to list: %s", err)
+	}
+	if mock.lists != 1 {
+		t.Errorf("Expected a single list request, got %d", mock.lists)
+	}
+}
+
 func TestConfigMapQuery(t *testing.T) {
 	cfgmaps := newTestFixtureCfgMaps(t, []*rspb.Release{
 		releaseStub("key-1", 1, "default", common.StatusUninstalled),
@@ -104,6 +104,14 @@ type Driver interface {
 	Name() string
 }
 
+// Compactor is the interface that wraps the Compact method.
+//
+// Compact rewrites the releases stored in an older format of the
+// driver and returns their keys.
+type Compactor interface {
+	Compact() ([]string, error)
+}
+
 // releaserToV1Release is a helper function to convert a v1 release passed by interface
 // into the type object.
 func releaserToV1Release(rel release.Releaser) (*rspb.Release, error) {
@@ -118,3 +126,12 @@ func releaserToV1Release(rel release.Releaser) (*rspb.Release, error) {
 		return nil, fmt.Errorf("unsupported release type: %T", rel)
 	}
 }
+
+// ChunkedLister is the interface that wraps the SetListChunkSize method.
+//
+// SetListChunkSize sets the maximum number of stored releases the driver
+// retrieves per request when listing or querying releases. Zero retrieves
+// them all in a single request.
+type ChunkedLister interface {
+	SetListChunkSize(size int64)
+}
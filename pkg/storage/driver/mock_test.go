@@ -19,6 +19,8 @@ package driver // import "helm.sh/helm/v4/pkg/storage/driver"
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strconv"
 	"testing"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
@@ -82,6 +84,24 @@ func tsFixtureMemory(t *testing.T) *Memory {
 	return mem
 }
 
+// paginate sorts names and returns the page of them selected by the limit
+// and continue token of opts, along with the continue token of the next page.
+func paginate(names []string, opts metav1.ListOptions) ([]string, string, error) {
+	slices.Sort(names)
+	start := 0
+	if opts.Continue != "" {
+		var err error
+		if start, err = strconv.Atoi(opts.Continue); err != nil {
+			return nil, "", apierrors.NewBadRequest("invalid continue token")
+		}
+	}
+	names = names[start:]
+	if opts.Limit > 0 && int64(len(names)) > opts.Limit {
+		return names[:opts.Limit], strconv.Itoa(start + int(opts.Limit)), nil
+	}
+	return names, "", nil
+}
+
 // newTestFixtureCfgMaps initializes a MockConfigMapsInterface.
 // ConfigMaps are created for each release provided.
 func newTestFixtureCfgMaps(t *testing.T, releases ...*rspb.Release) *ConfigMaps {
@@ -97,6 +117,7 @@ type MockConfigMapsInterface struct {
 	corev1.ConfigMapInterface
 
 	objects map[string]*v1.ConfigMap
+	lists   int
 }
 
 // Init initializes the MockConfigMapsInterface with the set of releases.
@@ -124,7 +145,8 @@ func (mock *MockConfigMapsInterface) Get(_ context.Context, name string, _ metav
 	return object, nil
 }
 
-// List returns all ConfigMaps.
+// List returns the ConfigMaps matching the label selector, at most opts.Limit
+// of them starting at opts.Continue.
 func (mock *MockConfigMapsInterface) List(_ context.Context, opts metav1.ListOptions) (*v1.ConfigMapList, error) {
 	var list v1.ConfigMapList
 
@@ -133,11 +155,20 @@ func (mock *MockConfigMapsInterface) List(_ context.Context, opts metav1.ListOpt
 		return nil, err
 	}
 
-	for _, cfgmap := range mock.objects {
+	var names []string
+	for name, cfgmap := range mock.objects {
 		if labelSelector.Matches(kblabels.Set(cfgmap.Labels)) {
-			list.Items = append(list.Items, *cfgmap)
+			names = append(names, name)
 		}
 	}
+	names, list.Continue, err = paginate(names, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		list.Items = append(list.Items, *mock.objects[name])
+	}
+	mock.lists++
 	return &list, nil
 }
 
@@ -185,6 +216,7 @@ type MockSecretsInterface struct {
 	corev1.SecretInterface
 
 	objects map[string]*v1.Secret
+	lists   int
 }
 
 // Init initializes the MockSecretsInterface with the set of releases.
@@ -195,11 +227,14 @@ func (mock *MockSecretsInterface) Init(t *testing.T, releases ...*rspb.Release)
 	for _, rls := range releases {
 		objkey := testKey(rls.Name, rls.Version)
 
-		secret, err := newSecretsObject(objkey, rls, nil)
+		secret, chunks, err := newSecretsObject(objkey, rls, nil, false)
 		if err != nil {
 			t.Fatalf("Failed to create secret: %s", err)
 		}
 		mock.objects[objkey] = secret
+		for _, chunk := range chunks {
+			mock.objects[chunk.Name] = chunk
+		}
 	}
 }
 
@@ -212,7 +247,8 @@ func (mock *MockSecretsInterface) Get(_ context.Context, name string, _ metav1.G
 	return object, nil
 }
 
-// List returns all Secrets.
+// List returns the Secrets matching the label selector, at most opts.Limit
+// of them starting at opts.Continue.
 func (mock *MockSecretsInterface) List(_ context.Context, opts metav1.ListOptions) (*v1.SecretList, error) {
 	var list v1.SecretList
 
@@ -221,11 +257,20 @@ func (mock *MockSecretsInterface) List(_ context.Context, opts metav1.ListOption
 		return nil, err
 	}
 
-	for _, secret := range mock.objects {
+	var names []string
+	for name, secret := range mock.objects {
 		if labelSelector.Matches(kblabels.Set(secret.Labels)) {
-			list.Items = append(list.Items, *secret)
+			names = append(names, name)
 		}
 	}
+	names, list.Continue, err = paginate(names, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		list.Items = append(list.Items, *mock.objects[name])
+	}
+	mock.lists++
 	return &list, nil
 }
 
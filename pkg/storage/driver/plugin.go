@@ -0,0 +1,320 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver // import "helm.sh/helm/v4/pkg/storage/driver"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"helm.sh/helm/v4/internal/logging"
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/pkg/release"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	storageplugin "helm.sh/helm/v4/pkg/storage/driver/plugin"
+)
+
+var _ Driver = (*Plugin)(nil)
+
+// PluginDriverName is the string name of the driver.
+const PluginDriverName = "Plugin"
+
+// Plugin is the storage driver delegating to a storage/v1 plugin, so that
+// releases can be stored in backends Helm has no driver for.
+//
+// The plugin is started on the first operation of the driver and serves all
+// its operations until the driver is closed, following the protocol of the
+// helm.sh/helm/v4/pkg/storage/driver/plugin package.
+type Plugin struct {
+	name      string
+	server    plugin.PluginServer
+	namespace string
+
+	mu     sync.Mutex
+	conn   io.ReadWriteCloser
+	client *storageplugin.Client
+	// Embed a LogHolder to provide logger functionality
+	logging.LogHolder
+}
+
+// NewPlugin initializes a new Plugin driver using the storage/v1 plugin named
+// name, found in pluginsDirs, and storing releases of namespace.
+func NewPlugin(pluginsDirs []string, name, namespace string) (*Plugin, error) {
+	p, err := plugin.FindPlugin(pluginsDirs, plugin.Descriptor{
+		Name: name,
+		Type: "storage/v1",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage plugin %q: %w", name, err)
+	}
+	server, ok := p.(plugin.PluginServer)
+	if !ok {
+		return nil, fmt.Errorf("storage plugin %q: runtime %q does not support long-lived plugins", name, p.Metadata().Runtime)
+	}
+	d := &Plugin{
+		name:      name,
+		server:    server,
+		namespace: namespace,
+	}
+	d.SetLogger(slog.Default().Handler())
+	return d, nil
+}
+
+// Close stops the plugin, if started. The plugin is started again by the next
+// operation of the driver.
+func (p *Plugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stop()
+}
+
+// Name returns the name of the driver.
+func (p *Plugin) Name() string {
+	return PluginDriverName
+}
+
+// Get fetches the release named by key. The corresponding release is returned
+// or error if not found.
+func (p *Plugin) Get(key string) (release.Releaser, error) {
+	out, err := p.invoke(storageplugin.Request{Operation: storageplugin.OperationGet, Key: key})
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+	if len(out.Records) == 0 {
+		return nil, ErrReleaseNotFound
+	}
+	rls, err := decodePluginRecord(out.Records[0])
+	if err != nil {
+		return nil, fmt.Errorf("get: failed to decode data %q: %w", key, err)
+	}
+	rls.Labels = filterSystemLabels(out.Records[0].Labels)
+	return rls, nil
+}
+
+// List fetches all releases and returns the list releases such
+// that filter(release) == true. An error is returned if the
+// plugin fails to retrieve the releases.
+func (p *Plugin) List(filter func(release.Releaser) bool) ([]release.Releaser, error) {
+	out, err := p.invoke(storageplugin.Request{Operation: storageplugin.OperationList})
+	if err != nil {
+		return nil, fmt.Errorf("list: %w", err)
+	}
+
+	var results []release.Releaser
+	for _, record := range out.Records {
+		rls, err := decodePluginRecord(record)
+		if err != nil {
+			p.Logger().Debug(
+				"list failed to decode release", slog.String("key", record.Key),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		rls.Labels = record.Labels
+		if filter(rls) {
+			results = append(results, rls)
+		}
+	}
+	return results, nil
+}
+
+// Query fetches all releases that match the provided map of labels.
+// An error is returned if the plugin fails to retrieve the releases.
+func (p *Plugin) Query(labels map[string]string) ([]release.Releaser, error) {
+	for _, v := range labels {
+		if errs := validation.IsValidLabelValue(v); len(errs) != 0 {
+			return nil, fmt.Errorf("invalid label value: %q: %s", v, strings.Join(errs, "; "))
+		}
+	}
+
+	out, err := p.invoke(storageplugin.Request{Operation: storageplugin.OperationQuery, Labels: labels})
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+	if len(out.Records) == 0 {
+		return nil, ErrReleaseNotFound
+	}
+
+	var results []release.Releaser
+	for _, record := range out.Records {
+		rls, err := decodePluginRecord(record)
+		if err != nil {
+			p.Logger().Debug(
+				"failed to decode release",
+				slog.String("key", record.Key),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		rls.Labels = record.Labels
+		results = append(results, rls)
+	}
+	return results, nil
+}
+
+// Create stores the release. If a release is already stored with the key,
+// ErrReleaseExists is returned.
+func (p *Plugin) Create(key string, rel release.Releaser) error {
+	record, err := newPluginRecord(key, rel, "createdAt")
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	if _, err := p.invoke(storageplugin.Request{Operation: storageplugin.OperationCreate, Record: record}); err != nil {
+		if errors.Is(err, ErrReleaseExists) {
+			return err
+		}
+		return fmt.Errorf("create: %w", err)
+	}
+	return nil
+}
+
+// Update updates the stored release.
+func (p *Plugin) Update(key string, rel release.Releaser) error {
+	record, err := newPluginRecord(key, rel, "modifiedAt")
+	if err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+	if _, err := p.invoke(storageplugin.Request{Operation: storageplugin.OperationUpdate, Record: record}); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes the release named by key.
+func (p *Plugin) Delete(key string) (release.Releaser, error) {
+	// fetch the release to check existence
+	rls, err := p.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.invoke(storageplugin.Request{Operation: storageplugin.OperationDelete, Key: key}); err != nil {
+		return nil, fmt.Errorf("delete: %w", err)
+	}
+	return rls, nil
+}
+
+// invoke sends an operation to the plugin in the namespace of the driver,
+// starting the plugin if needed, and translates the errors reported by the
+// plugin.
+func (p *Plugin) invoke(req storageplugin.Request) (*storageplugin.Response, error) {
+	req.Namespace = p.namespace
+	res, err := p.do(&req)
+	if err != nil {
+		return nil, fmt.Errorf("storage plugin %q: %w", p.name, err)
+	}
+	switch res.Error {
+	case "":
+		return res, nil
+	case storageplugin.ErrorNotFound:
+		return nil, ErrReleaseNotFound
+	case storageplugin.ErrorExists:
+		return nil, ErrReleaseExists
+	default:
+		return nil, fmt.Errorf("storage plugin %q: %s", p.name, res.Error)
+	}
+}
+
+func (p *Plugin) do(req *storageplugin.Request) (*storageplugin.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client == nil {
+		conn, err := p.server.Start(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		p.conn = conn
+		p.client = storageplugin.NewClient(conn)
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		// the plugin can no longer be relied on, restart it on the next
+		// operation
+		if serr := p.stop(); serr != nil {
+			p.Logger().Debug("failed to stop storage plugin", slog.String("plugin", p.name), slog.Any("error", serr))
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// stop closes the connection to the plugin, waiting for it to exit.
+func (p *Plugin) stop() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	p.client = nil
+	return err
+}
+
+// newPluginRecord encodes a release for a storage plugin. The timestamp of
+// the operation is set as label timestampLabel.
+//
+// The following labels are set on each record:
+//
+//	"modifiedAt"    - timestamp indicating when this record was last modified. (set in Update)
+//	"createdAt"     - timestamp indicating when this record was created. (set in Create)
+//	"version"        - version of the release.
+//	"status"         - status of the release (see pkg/release/status.go for variants)
+//	"owner"          - owner of the record, currently "helm".
+//	"name"           - name of the release.
+func newPluginRecord(key string, rel release.Releaser, timestampLabel string) (*storageplugin.Record, error) {
+	rls, err := releaserToV1Release(rel)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(rls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode release %q: %w", rls.Name, err)
+	}
+
+	var lbs labels
+	lbs.init()
+	lbs.fromMap(rls.Labels)
+	lbs.set(timestampLabel, strconv.FormatInt(time.Now().Unix(), 10))
+	lbs.set("name", rls.Name)
+	lbs.set("owner", "helm")
+	lbs.set("status", rls.Info.Status.String())
+	lbs.set("version", strconv.Itoa(rls.Version))
+
+	return &storageplugin.Record{
+		Key:     key,
+		Release: b,
+		Labels:  lbs.toMap(),
+	}, nil
+}
+
+// decodePluginRecord decodes the release stored in a record of a storage
+// plugin.
+func decodePluginRecord(record storageplugin.Record) (*rspb.Release, error) {
+	var rls rspb.Release
+	if err := json.Unmarshal(record.Release, &rls); err != nil {
+		return nil, err
+	}
+	return &rls, nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Client sends requests to a running storage plugin.
+type Client struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	dec *json.Decoder
+}
+
+// NewClient returns a Client writing requests to the stdin of a storage
+// plugin and reading responses from its stdout, both provided by conn.
+func NewClient(conn io.ReadWriter) *Client {
+	return &Client{
+		enc: json.NewEncoder(conn),
+		dec: json.NewDecoder(conn),
+	}
+}
+
+// Do sends the request to the plugin and waits for its response. It is safe
+// to call Do concurrently, requests are sent one at a time.
+func (c *Client) Do(req *Request) (*Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.enc.Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	var res Response
+	if err := c.dec.Decode(&res); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return &res, nil
+}
+
+// Serve answers the requests read from r with handle, writing the responses
+// to w, until r is closed. It is used by storage plugins to serve the
+// requests of Helm on their stdin and stdout.
+func Serve(r io.Reader, w io.Writer, handle func(*Request) *Response) error {
+	dec := json.NewDecoder(r)
+	enc := json.NewEncoder(w)
+	for {
+		var req Request
+		if err := dec.Decode(&req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+		res := handle(&req)
+		if res == nil {
+			res = &Response{}
+		}
+		if err := enc.Encode(res); err != nil {
+			return fmt.Errorf("failed to send response: %w", err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestClientServe(t *testing.T) {
+	client, server := net.Pipe()
+
+	var requests []Request
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(server, server, func(req *Request) *Response {
+			requests = append(requests, *req)
+			if req.Operation == OperationDelete {
+				return nil
+			}
+			return &Response{Records: []Record{{Key: req.Key, Release: []byte(`{}`)}}}
+		})
+	}()
+
+	c := NewClient(client)
+	res, err := c.Do(&Request{Operation: OperationGet, Namespace: "default", Key: "a"})
+	if err != nil {
+		t.Fatalf("Failed to send request: %s", err)
+	}
+	if len(res.Records) != 1 || res.Records[0].Key != "a" {
+		t.Errorf("Expected the record with key a, got %+v", res.Records)
+	}
+	res, err = c.Do(&Request{Operation: OperationDelete, Namespace: "default", Key: "b"})
+	if err != nil {
+		t.Fatalf("Failed to send request: %s", err)
+	}
+	if !reflect.DeepEqual(&Response{}, res) {
+		t.Errorf("Expected an empty response, got %+v", res)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected Serve to return once the client is closed, got %v", err)
+	}
+
+	expected := []Request{
+		{Operation: OperationGet, Namespace: "default", Key: "a"},
+		{Operation: OperationDelete, Namespace: "default", Key: "b"},
+	}
+	if !reflect.DeepEqual(expected, requests) {
+		t.Errorf("Expected requests %+v, got %+v", expected, requests)
+	}
+}
+
+func TestClientClosedPlugin(t *testing.T) {
+	client, server := net.Pipe()
+	go func() {
+		// a plugin exiting without answering
+		var b [1]byte
+		server.Read(b[:])
+		server.Close()
+	}()
+
+	c := NewClient(client)
+	if _, err := c.Do(&Request{Operation: OperationList}); !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Expected the request to fail, got %v", err)
+	}
+}
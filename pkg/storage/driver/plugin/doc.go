@@ -0,0 +1,35 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package plugin defines the protocol between Helm and storage/v1 plugins.
+
+A storage/v1 plugin is started once by the Plugin storage driver and serves
+the operations of the driver until its stdin is closed. Each operation is a
+JSON encoded Request written on a line of the stdin of the plugin, answered
+by a JSON encoded Response written on a line of its stdout. Requests are sent
+one at a time, and every request is answered, even if with an empty response.
+
+Plugins written in Go can serve requests with Serve:
+
+	plugin.Serve(os.Stdin, os.Stdout, func(req *plugin.Request) *plugin.Response {
+		switch req.Operation {
+		case plugin.OperationGet:
+			...
+		}
+	})
+*/
+package plugin // import "helm.sh/helm/v4/pkg/storage/driver/plugin"
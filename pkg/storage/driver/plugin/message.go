@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"encoding/json"
+)
+
+// Operations of storage plugins
+const (
+	OperationGet    = "get"
+	OperationList   = "list"
+	OperationQuery  = "query"
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+// Errors storage plugins report in Response.Error, other errors are reported
+// as is
+const (
+	// ErrorNotFound reports that no release is stored with the key
+	ErrorNotFound = "notFound"
+	// ErrorExists reports that a release is already stored with the key on
+	// create
+	ErrorExists = "exists"
+)
+
+// Record is a release stored by a storage plugin
+type Record struct {
+	Key string `json:"key"`
+	// Release is the JSON encoded release
+	Release json.RawMessage `json:"release"`
+	// Labels of the release, including the "name", "owner", "status" and
+	// "version" labels releases are queried by
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Request is an operation Helm sends to a storage plugin
+type Request struct {
+	// Operation is one of the Operation constants
+	Operation string `json:"operation"`
+	// Namespace of the releases, empty to list and query releases of all
+	// namespaces
+	Namespace string `json:"namespace"`
+	// Key of the release to get or delete
+	Key string `json:"key,omitempty"`
+	// Record to create or update
+	Record *Record `json:"record,omitempty"`
+	// Labels the queried releases must have
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Response is the answer of a storage plugin to a Request
+type Response struct {
+	// Records found by get, list and query
+	Records []Record `json:"records,omitempty"`
+	// Error is one of the Error constants, or any other message
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,251 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/release/common"
+	storageplugin "helm.sh/helm/v4/pkg/storage/driver/plugin"
+)
+
+// mockStoragePlugin is a storage/v1 plugin storing records in memory.
+type mockStoragePlugin struct {
+	records map[string]storageplugin.Record
+}
+
+func (m *mockStoragePlugin) handle(req *storageplugin.Request) *storageplugin.Response {
+	var res storageplugin.Response
+	switch req.Operation {
+	case storageplugin.OperationGet:
+		if r, ok := m.records[req.Key]; ok {
+			res.Records = append(res.Records, r)
+		}
+	case storageplugin.OperationList, storageplugin.OperationQuery:
+	records:
+		for _, r := range m.records {
+			for k, v := range req.Labels {
+				if r.Labels[k] != v {
+					continue records
+				}
+			}
+			res.Records = append(res.Records, r)
+		}
+	case storageplugin.OperationCreate:
+		if _, ok := m.records[req.Record.Key]; ok {
+			res.Error = storageplugin.ErrorExists
+			break
+		}
+		m.records[req.Record.Key] = *req.Record
+	case storageplugin.OperationUpdate:
+		m.records[req.Record.Key] = *req.Record
+	case storageplugin.OperationDelete:
+		if _, ok := m.records[req.Key]; !ok {
+			res.Error = storageplugin.ErrorNotFound
+			break
+		}
+		delete(m.records, req.Key)
+	default:
+		res.Error = "unknown operation " + req.Operation
+	}
+	return &res
+}
+
+// mockPluginServer starts a mockStoragePlugin serving a pipe.
+type mockPluginServer struct {
+	mock *mockStoragePlugin
+}
+
+func (s *mockPluginServer) Start(context.Context) (io.ReadWriteCloser, error) {
+	conn, server := net.Pipe()
+	go storageplugin.Serve(server, server, s.mock.handle)
+	return conn, nil
+}
+
+func newTestFixturePlugin(t *testing.T) *Plugin {
+	t.Helper()
+	p := &Plugin{
+		name:      "mock",
+		server:    &mockPluginServer{mock: &mockStoragePlugin{records: map[string]storageplugin.Record{}}},
+		namespace: "default",
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+func TestPluginName(t *testing.T) {
+	p := newTestFixturePlugin(t)
+	if p.Name() != PluginDriverName {
+		t.Errorf("Expected name to be %s, got %s", PluginDriverName, p.Name())
+	}
+}
+
+func TestPluginCRUD(t *testing.T) {
+	p := newTestFixturePlugin(t)
+
+	rel1 := releaseStub("smug-pigeon", 1, "default", common.StatusSuperseded)
+	rel2 := releaseStub("smug-pigeon", 2, "default", common.StatusDeployed)
+	key1 := testKey(rel1.Name, rel1.Version)
+	key2 := testKey(rel2.Name, rel2.Version)
+
+	if err := p.Create(key1, rel1); err != nil {
+		t.Fatalf("Failed to create release: %s", err)
+	}
+	if err := p.Create(key2, rel2); err != nil {
+		t.Fatalf("Failed to create release: %s", err)
+	}
+	if err := p.Create(key2, rel2); !errors.Is(err, ErrReleaseExists) {
+		t.Errorf("Expected ErrReleaseExists, got %v", err)
+	}
+
+	got, err := p.Get(key1)
+	if err != nil {
+		t.Fatalf("Failed to get release: %s", err)
+	}
+	if !reflect.DeepEqual(rel1, got) {
+		t.Errorf("Expected {%v}, got {%v}", rel1, got)
+	}
+	if _, err := p.Get("nonexistent"); !errors.Is(err, ErrReleaseNotFound) {
+		t.Errorf("Expected ErrReleaseNotFound, got %v", err)
+	}
+
+	rels, err := p.List(func(release.Releaser) bool { return true })
+	if err != nil {
+		t.Fatalf("Failed to list releases: %s", err)
+	}
+	if len(rels) != 2 {
+		t.Errorf("Expected 2 releases, got %d", len(rels))
+	}
+
+	rels, err = p.Query(map[string]string{"name": "smug-pigeon", "owner": "helm", "status": "deployed"})
+	if err != nil {
+		t.Fatalf("Failed to query releases: %s", err)
+	}
+	if len(rels) != 1 {
+		t.Errorf("Expected 1 deployed release, got %d", len(rels))
+	}
+	if _, err := p.Query(map[string]string{"name": "other"}); !errors.Is(err, ErrReleaseNotFound) {
+		t.Errorf("Expected ErrReleaseNotFound, got %v", err)
+	}
+
+	rel2.Info.Status = common.StatusSuperseded
+	if err := p.Update(key2, rel2); err != nil {
+		t.Fatalf("Failed to update release: %s", err)
+	}
+	got, err = p.Get(key2)
+	if err != nil {
+		t.Fatalf("Failed to get release: %s", err)
+	}
+	if rel := convertReleaserToV1(t, got); rel.Info.Status != common.StatusSuperseded {
+		t.Errorf("Expected status %s, got %s", common.StatusSuperseded, rel.Info.Status)
+	}
+
+	if _, err := p.Delete(key1); err != nil {
+		t.Fatalf("Failed to delete release: %s", err)
+	}
+	if _, err := p.Get(key1); !errors.Is(err, ErrReleaseNotFound) {
+		t.Errorf("Expected ErrReleaseNotFound, got %v", err)
+	}
+}
+
+func TestNewPlugin(t *testing.T) {
+	if _, err := NewPlugin([]string{t.TempDir()}, "missing", "default"); err == nil {
+		t.Error("Expected an error for a missing plugin")
+	}
+
+	// a subprocess plugin recording its starts and requests, and reporting
+	// that no release was found
+	pluginsDir := t.TempDir()
+	pluginDir := filepath.Join(pluginsDir, "recorder")
+	if err := os.MkdirAll(pluginDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := `echo started >> "$HELM_PLUGIN_DIR/starts"
+while read -r line; do
+	echo "$line" >> "$HELM_PLUGIN_DIR/requests"
+	echo '{"error": "notFound"}'
+done
+`
+	if err := os.WriteFile(filepath.Join(pluginDir, "storage.sh"), []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	metadata := `apiVersion: v1
+name: recorder
+version: 0.1.0
+type: storage/v1
+runtime: subprocess
+runtimeConfig:
+  platformCommand:
+    - command: sh
+      args: ["$HELM_PLUGIN_DIR/storage.sh"]
+`
+	if err := os.WriteFile(filepath.Join(pluginDir, "plugin.yaml"), []byte(metadata), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPlugin([]string{pluginsDir}, "recorder", "team-a")
+	if err != nil {
+		t.Fatalf("Failed to create plugin driver: %s", err)
+	}
+	for range 2 {
+		if _, err := p.Get("sh.helm.release.v1.smug-pigeon.v1"); !errors.Is(err, ErrReleaseNotFound) {
+			t.Errorf("Expected ErrReleaseNotFound, got %v", err)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Failed to close plugin driver: %s", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(pluginDir, "starts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "started\n" {
+		t.Errorf("Expected the plugin to be started once, got %q", b)
+	}
+
+	b, err = os.ReadFile(filepath.Join(pluginDir, "requests"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 requests, got %q", b)
+	}
+	var req storageplugin.Request
+	if err := json.Unmarshal([]byte(lines[1]), &req); err != nil {
+		t.Fatalf("Failed to decode plugin request %q: %s", lines[1], err)
+	}
+	expected := storageplugin.Request{
+		Operation: storageplugin.OperationGet,
+		Namespace: "team-a",
+		Key:       "sh.helm.release.v1.smug-pigeon.v1",
+	}
+	if !reflect.DeepEqual(expected, req) {
+		t.Errorf("Expected plugin request %+v, got %+v", expected, req)
+	}
+}
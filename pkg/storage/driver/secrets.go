@@ -18,8 +18,10 @@ package driver // import "helm.sh/helm/v4/pkg/storage/driver"
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -41,10 +43,24 @@ var _ Driver = (*Secrets)(nil)
 // SecretsDriverName is the string name of the driver.
 const SecretsDriverName = "Secret"
 
+// secretChunkSize is the maximum size of the encoded release stored in a
+// single Secret. Kubernetes limits Secrets to 1MiB, larger releases are
+// compressed with zstd and split across several Secrets.
+var secretChunkSize = 1000 * 1024
+
 // Secrets is a wrapper around an implementation of a kubernetes
 // SecretsInterface.
 type Secrets struct {
 	impl corev1.SecretInterface
+	// Zstd compresses every release with zstd rather than gzip, which
+	// otherwise is only used for releases too large for a single Secret.
+	//
+	// Releases compressed with zstd or split across several Secrets are
+	// stored in a format that earlier versions of Helm cannot read.
+	Zstd bool
+	// listChunkSize is the maximum number of Secrets retrieved per request
+	// when listing releases, zero retrieves them all at once.
+	listChunkSize int64
 	// Embed a LogHolder to provide logger functionality
 	logging.LogHolder
 }
@@ -64,24 +80,44 @@ func (secrets *Secrets) Name() string {
 	return SecretsDriverName
 }
 
+// SetListChunkSize sets the maximum number of Secrets retrieved per request
+// when listing or querying releases.
+func (secrets *Secrets) SetListChunkSize(size int64) {
+	secrets.listChunkSize = size
+}
+
 // Get fetches the release named by key. The corresponding release is returned
 // or error if not found.
 func (secrets *Secrets) Get(key string) (release.Releaser, error) {
+	_, r, err := secrets.get(key)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// get fetches the Secret holding the release named by key and decodes the
+// release.
+func (secrets *Secrets) get(key string) (*v1.Secret, *rspb.Release, error) {
 	// fetch the secret holding the release named by key
 	obj, err := secrets.impl.Get(context.Background(), key, metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			return nil, ErrReleaseNotFound
+			return nil, nil, ErrReleaseNotFound
 		}
-		return nil, fmt.Errorf("get: failed to get %q: %w", key, err)
+		return nil, nil, fmt.Errorf("get: failed to get %q: %w", key, err)
+	}
+	data, err := secrets.releaseData(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get: failed to get data %q: %w", key, err)
 	}
 	// found the secret, decode the base64 data string
-	r, err := decodeRelease(string(obj.Data["release"]))
+	r, err := decodeRelease(data)
 	if err != nil {
-		return r, fmt.Errorf("get: failed to decode data %q: %w", key, err)
+		return nil, nil, fmt.Errorf("get: failed to decode data %q: %w", key, err)
 	}
 	r.Labels = filterSystemLabels(obj.Labels)
-	return r, nil
+	return obj, r, nil
 }
 
 // List fetches all releases and returns the list releases such
@@ -91,23 +127,18 @@ func (secrets *Secrets) List(filter func(release.Releaser) bool) ([]release.Rele
 	lsel := kblabels.Set{"owner": "helm"}.AsSelector()
 	opts := metav1.ListOptions{LabelSelector: lsel.String()}
 
-	list, err := secrets.impl.List(context.Background(), opts)
-	if err != nil {
-		return nil, fmt.Errorf("list: failed to list: %w", err)
-	}
-
 	var results []release.Releaser
 
-	// iterate over the secrets object list
-	// and decode each release
-	for _, item := range list.Items {
-		rls, err := decodeRelease(string(item.Data["release"]))
+	// decode each release as the chunks of the list are retrieved, so that
+	// only the matching ones are kept in memory
+	err := secrets.list(opts, func(item *v1.Secret) {
+		rls, err := secrets.decodeSecret(item)
 		if err != nil {
 			secrets.Logger().Debug(
 				"list failed to decode release", slog.String("key", item.Name),
 				slog.Any("error", err),
 			)
-			continue
+			return
 		}
 
 		rls.Labels = item.Labels
@@ -115,6 +146,9 @@ func (secrets *Secrets) List(filter func(release.Releaser) bool) ([]release.Rele
 		if filter(rls) {
 			results = append(results, rls)
 		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list: failed to list: %w", err)
 	}
 	return results, nil
 }
@@ -132,28 +166,30 @@ func (secrets *Secrets) Query(labels map[string]string) ([]release.Releaser, err
 
 	opts := metav1.ListOptions{LabelSelector: ls.AsSelector().String()}
 
-	list, err := secrets.impl.List(context.Background(), opts)
-	if err != nil {
-		return nil, fmt.Errorf("query: failed to query with labels: %w", err)
-	}
-
-	if len(list.Items) == 0 {
-		return nil, ErrReleaseNotFound
-	}
-
-	var results []release.Releaser
-	for _, item := range list.Items {
-		rls, err := decodeRelease(string(item.Data["release"]))
+	var (
+		found   bool
+		results []release.Releaser
+	)
+	err := secrets.list(opts, func(item *v1.Secret) {
+		found = true
+		rls, err := secrets.decodeSecret(item)
 		if err != nil {
 			secrets.Logger().Debug(
 				"failed to decode release",
 				slog.String("key", item.Name),
 				slog.Any("error", err),
 			)
-			continue
+			return
 		}
 		rls.Labels = item.Labels
 		results = append(results, rls)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query: failed to query with labels: %w", err)
+	}
+
+	if !found {
+		return nil, ErrReleaseNotFound
 	}
 	return results, nil
 }
@@ -174,7 +210,7 @@ func (secrets *Secrets) Create(key string, rel release.Releaser) error {
 	lbs.set("createdAt", strconv.FormatInt(time.Now().Unix(), 10))
 
 	// create a new secret to hold the release
-	obj, err := newSecretsObject(key, rls, lbs)
+	obj, chunks, err := newSecretsObject(key, rls, lbs, secrets.Zstd)
 	if err != nil {
 		return fmt.Errorf("create: failed to encode release %q: %w", rls.Name, err)
 	}
@@ -186,6 +222,13 @@ func (secrets *Secrets) Create(key string, rel release.Releaser) error {
 
 		return fmt.Errorf("create: failed to create: %w", err)
 	}
+	if err := secrets.writeChunks(chunks); err != nil {
+		// do not leave a release behind that cannot be read
+		if derr := secrets.impl.Delete(context.Background(), key, metav1.DeleteOptions{}); derr != nil {
+			secrets.Logger().Debug("failed to delete incomplete release", slog.String("key", key), slog.Any("error", derr))
+		}
+		return fmt.Errorf("create: failed to create chunks: %w", err)
+	}
 	return nil
 }
 
@@ -205,13 +248,11 @@ func (secrets *Secrets) Update(key string, rel release.Releaser) error {
 	lbs.set("modifiedAt", strconv.FormatInt(time.Now().Unix(), 10))
 
 	// create a new secret object to hold the release
-	obj, err := newSecretsObject(key, rls, lbs)
+	obj, chunks, err := newSecretsObject(key, rls, lbs, secrets.Zstd)
 	if err != nil {
 		return fmt.Errorf("update: failed to encode release %q: %w", rls.Name, err)
 	}
-	// push the secret object out into the kubiverse
-	_, err = secrets.impl.Update(context.Background(), obj, metav1.UpdateOptions{})
-	if err != nil {
+	if err := secrets.replace(obj, chunks); err != nil {
 		return fmt.Errorf("update: failed to update: %w", err)
 	}
 	return nil
@@ -220,7 +261,8 @@ func (secrets *Secrets) Update(key string, rel release.Releaser) error {
 // Delete deletes the Secret holding the release named by key.
 func (secrets *Secrets) Delete(key string) (rls release.Releaser, err error) {
 	// fetch the release to check existence
-	if rls, err = secrets.Get(key); err != nil {
+	obj, r, err := secrets.get(key)
+	if err != nil {
 		return nil, err
 	}
 	// delete the release
@@ -228,12 +270,187 @@ func (secrets *Secrets) Delete(key string) (rls release.Releaser, err error) {
 	if err != nil {
 		return nil, err
 	}
-	return rls, nil
+	n, _ := secretChunks(obj)
+	if err := secrets.deleteChunks(key, 2, n); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Compact rewrites the releases not compressed with zstd, splitting them
+// across several Secrets when too large. It requires Zstd: with it set, Helm
+// rewrites releases whenever they are updated, Compact migrates the remaining
+// ones. It returns the keys of the rewritten releases.
+//
+// Earlier versions of Helm cannot read the rewritten releases.
+func (secrets *Secrets) Compact() ([]string, error) {
+	if !secrets.Zstd {
+		return nil, errors.New("compact: zstd compression is not enabled")
+	}
+	lsel := kblabels.Set{"owner": "helm"}.AsSelector()
+	opts := metav1.ListOptions{LabelSelector: lsel.String()}
+
+	list, err := secrets.impl.List(context.Background(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("compact: failed to list: %w", err)
+	}
+
+	var keys []string
+	for _, item := range list.Items {
+		data, err := secrets.releaseData(&item)
+		if err != nil {
+			return keys, fmt.Errorf("compact: failed to get data %q: %w", item.Name, err)
+		}
+		if isZstdEncoded(data) {
+			continue
+		}
+		rls, err := decodeRelease(data)
+		if err != nil {
+			return keys, fmt.Errorf("compact: failed to decode data %q: %w", item.Name, err)
+		}
+
+		// keep the labels as they are, the release itself did not change
+		var lbs labels
+		lbs.init()
+		lbs.fromMap(item.Labels)
+		rls.Labels = filterSystemLabels(item.Labels)
+
+		obj, chunks, err := newSecretsObject(item.Name, rls, lbs, true)
+		if err != nil {
+			return keys, fmt.Errorf("compact: failed to encode release %q: %w", rls.Name, err)
+		}
+		obj.ResourceVersion = item.ResourceVersion
+		if err := secrets.replace(obj, chunks); err != nil {
+			return keys, fmt.Errorf("compact: failed to update %q: %w", item.Name, err)
+		}
+		keys = append(keys, item.Name)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// decodeSecret decodes the release stored in obj.
+func (secrets *Secrets) decodeSecret(obj *v1.Secret) (*rspb.Release, error) {
+	data, err := secrets.releaseData(obj)
+	if err != nil {
+		return nil, err
+	}
+	return decodeRelease(data)
+}
+
+// list lists the Secrets matching opts and calls fn for each of them. The
+// Secrets are retrieved in chunks of listChunkSize, following the continue
+// token of each chunk.
+func (secrets *Secrets) list(opts metav1.ListOptions, fn func(*v1.Secret)) error {
+	opts.Limit = secrets.listChunkSize
+	for {
+		list, err := secrets.impl.List(context.Background(), opts)
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			fn(&list.Items[i])
+		}
+		if list.Continue == "" {
+			return nil
+		}
+		opts.Continue = list.Continue
+	}
+}
+
+// releaseData returns the encoded release stored in obj, joining the chunks
+// of a release split across several Secrets.
+func (secrets *Secrets) releaseData(obj *v1.Secret) (string, error) {
+	n, err := secretChunks(obj)
+	if err != nil {
+		return "", err
+	}
+	var data strings.Builder
+	data.Write(obj.Data["release"])
+	for i := 2; i <= n; i++ {
+		chunk, err := secrets.impl.Get(context.Background(), secretChunkKey(obj.Name, i), metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to get chunk %d of %d: %w", i, n, err)
+		}
+		data.Write(chunk.Data["release"])
+	}
+	return data.String(), nil
+}
+
+// replace updates the Secret holding a release and its chunks, deleting
+// the chunks the release no longer needs.
+func (secrets *Secrets) replace(obj *v1.Secret, chunks []*v1.Secret) error {
+	current, err := secrets.impl.Get(context.Background(), obj.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	previous, _ := secretChunks(current)
+	// write the chunks first, so that the updated Secret never refers to
+	// missing chunks
+	if err := secrets.writeChunks(chunks); err != nil {
+		return err
+	}
+	// push the secret object out into the kubiverse
+	if _, err := secrets.impl.Update(context.Background(), obj, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	return secrets.deleteChunks(obj.Name, len(chunks)+2, previous)
+}
+
+// writeChunks creates or updates the Secrets holding chunks of a release.
+func (secrets *Secrets) writeChunks(chunks []*v1.Secret) error {
+	for _, chunk := range chunks {
+		_, err := secrets.impl.Create(context.Background(), chunk, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			_, err = secrets.impl.Update(context.Background(), chunk, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %q: %w", chunk.Name, err)
+		}
+	}
+	return nil
+}
+
+// deleteChunks deletes the chunks from to to of the release named by key.
+func (secrets *Secrets) deleteChunks(key string, from, to int) error {
+	for i := from; i <= to; i++ {
+		err := secrets.impl.Delete(context.Background(), secretChunkKey(key, i), metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// secretChunks returns the number of Secrets the release stored in obj is
+// split across.
+func secretChunks(obj *v1.Secret) (int, error) {
+	c, ok := obj.Data["chunks"]
+	if !ok {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(string(c))
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid number of chunks %q", c)
+	}
+	return n, nil
+}
+
+// secretChunkKey returns the name of the Secret holding chunk i of the
+// release named by key. The first chunk is stored in the Secret named key.
+func secretChunkKey(key string, i int) string {
+	return fmt.Sprintf("%s.chunk%d", key, i)
 }
 
 // newSecretsObject constructs a kubernetes Secret object
 // to store a release. Each secret data entry is the base64
-// encoded gzipped string of a release.
+// encoded gzipped string of a release, or the zstd compressed
+// one with zstd or if the gzipped one is larger than
+// secretChunkSize. Releases still larger than secretChunkSize
+// are split, the remaining chunks are returned as separate
+// Secrets, and the number of chunks is stored in the "chunks"
+// data entry. Earlier versions of Helm only read the gzipped
+// releases stored in a single Secret.
 //
 // The following labels are used within each secret:
 //
@@ -243,13 +460,19 @@ func (secrets *Secrets) Delete(key string) (rls release.Releaser, err error) {
 //	"status"         - status of the release (see pkg/release/status.go for variants)
 //	"owner"          - owner of the secret, currently "helm".
 //	"name"           - name of the release.
-func newSecretsObject(key string, rls *rspb.Release, lbs labels) (*v1.Secret, error) {
+//
+// The chunks are only labeled with the name and version of the release and
+// the "chunk" number, so that they are not listed as releases.
+func newSecretsObject(key string, rls *rspb.Release, lbs labels, zstd bool) (*v1.Secret, []*v1.Secret, error) {
 	const owner = "helm"
 
 	// encode the release
 	s, err := encodeRelease(rls)
+	if err == nil && (zstd || len(s) > secretChunkSize) {
+		s, err = encodeReleaseZstd(rls)
+	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if lbs == nil {
@@ -265,6 +488,33 @@ func newSecretsObject(key string, rls *rspb.Release, lbs labels) (*v1.Secret, er
 	lbs.set("status", rls.Info.Status.String())
 	lbs.set("version", strconv.Itoa(rls.Version))
 
+	var parts []string
+	for len(s) > secretChunkSize {
+		parts = append(parts, s[:secretChunkSize])
+		s = s[secretChunkSize:]
+	}
+	parts = append(parts, s)
+
+	data := map[string][]byte{"release": []byte(parts[0])}
+	var chunks []*v1.Secret
+	if len(parts) > 1 {
+		data["chunks"] = []byte(strconv.Itoa(len(parts)))
+		for i, part := range parts[1:] {
+			chunks = append(chunks, &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: secretChunkKey(key, i+2),
+					Labels: map[string]string{
+						"name":    rls.Name,
+						"version": strconv.Itoa(rls.Version),
+						"chunk":   strconv.Itoa(i + 2),
+					},
+				},
+				Type: "helm.sh/release-chunk.v1",
+				Data: map[string][]byte{"release": []byte(part)},
+			})
+		}
+	}
+
 	// create and return secret object.
 	// Helm 3 introduced setting the 'Type' field
 	// in the Kubernetes storage object.
@@ -282,6 +532,6 @@ func newSecretsObject(key string, rls *rspb.Release, lbs labels) (*v1.Secret, er
 			Labels: lbs.toMap(),
 		},
 		Type: "helm.sh/release.v1",
-		Data: map[string][]byte{"release": []byte(s)},
-	}, nil
+		Data: data,
+	}, chunks, nil
 }
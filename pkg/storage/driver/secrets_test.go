@@ -17,7 +17,9 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"maps"
 	"reflect"
+	"strings"
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
@@ -62,7 +64,7 @@ func TestUNcompressedSecretGet(t *testing.T) {
 	rel := releaseStub(name, vers, namespace, common.StatusDeployed)
 
 	// Create a test fixture which contains an uncompressed release
-	secret, err := newSecretsObject(key, rel, nil)
+	secret, _, err := newSecretsObject(key, rel, nil, false)
 	if err != nil {
 		t.Fatalf("Failed to create secret: %s", err)
 	}
@@ -146,6 +148,50 @@ func TestSecretList(t *testing.T) {
 	}
 }
 
+func TestSecretListChunks(t *testing.T) {
+	secrets := newTestFixtureSecrets(t, []*rspb.Release{
+		releaseStub("key-1", 1, "default", common.StatusDeployed),
+		releaseStub("key-2", 1, "default", common.StatusDeployed),
+		releaseStub("key-3", 1, "default", common.StatusDeployed),
+		releaseStub("key-4", 1, "default", common.StatusSuperseded),
+		releaseStub("key-5", 1, "default", common.StatusSuperseded),
+	}...)
+	mock := secrets.impl.(*MockSecretsInterface)
+	secrets.SetListChunkSize(2)
+
+	all, err := secrets.List(func(release.Releaser) bool { return true })
+	if err != nil {
+		t.Fatalf("Failed to list: %s", err)
+	}
+	if len(all) != 5 {
+		t.Errorf("Expected 5 releases, got %d", len(all))
+	}
+	if mock.lists != 3 {
+		t.Errorf("Expected 3 list requests, got %d", mock.lists)
+	}
+
+	mock.lists = 0
+	dpl, err := secrets.Query(map[string]string{"status": "deployed"})
+	if err != nil {
+		t.Fatalf("Failed to query: %s", err)
+	}
+	if len(dpl) != 3 {
+		t.Errorf("Expected 3 deployed, got %d", len(dpl))
+	}
+	if mock.lists != 2 {
+		t.Errorf("Expected 2 list requests, got %d", mock.lists)
+	}
+
+	mock.lists = 0
+	secrets.SetListChunkSize(0)
+	if _, err := secrets.List(func(release.Releaser) bool { return true }); err != nil {
+		t.Fatalf("Failed to list: %s", err)
+	}
+	if mock.lists != 1 {
+		t.Errorf("Expected a single list request, got %d", mock.lists)
+	}
+}
+
 func TestSecretQuery(t *testing.T) {
 	secrets := newTestFixtureSecrets(t, []*rspb.Release{
 		releaseStub("key-1", 1, "default", common.StatusUninstalled),
@@ -254,3 +300,144 @@ func TestSecretDelete(t *testing.T) {
 		t.Errorf("Expected {%v}, got {%v}", ErrReleaseNotFound, err)
 	}
 }
+
+func TestSecretChunks(t *testing.T) {
+	defer func(size int) { secretChunkSize = size }(secretChunkSize)
+	secretChunkSize = 100
+
+	vers := 1
+	name := "smug-pigeon"
+	namespace := "default"
+	key := testKey(name, vers)
+	rel := releaseStub(name, vers, namespace, common.StatusDeployed)
+	rel.Manifest = strings.Repeat("kind: ConfigMap\n", 100)
+
+	var mock MockSecretsInterface
+	mock.Init(t)
+	secrets := NewSecrets(&mock)
+
+	if err := secrets.Create(key, rel); err != nil {
+		t.Fatalf("Failed to create release with key %q: %s", key, err)
+	}
+	n, err := secretChunks(mock.objects[key])
+	if err != nil || n < 2 {
+		t.Fatalf("Expected the release to be split, got %d chunks: %v", n, err)
+	}
+	if !isZstdEncoded(string(mock.objects[key].Data["release"])) {
+		t.Error("Expected the release too large for a Secret to be compressed with zstd")
+	}
+	if len(mock.objects) != n {
+		t.Errorf("Expected %d Secrets, got %d", n, len(mock.objects))
+	}
+
+	got, err := secrets.Get(key)
+	if err != nil {
+		t.Fatalf("Failed to get release with key %q: %s", key, err)
+	}
+	if !reflect.DeepEqual(rel, got) {
+		t.Errorf("Expected {%v}, got {%v}", rel, got)
+	}
+
+	// chunks are not listed as releases
+	rels, err := secrets.List(func(release.Releaser) bool { return true })
+	if err != nil {
+		t.Fatalf("Failed to list releases: %s", err)
+	}
+	if len(rels) != 1 {
+		t.Errorf("Expected 1 release, got %d", len(rels))
+	}
+
+	// a smaller release deletes the chunks it no longer needs
+	rel.Manifest = ""
+	if err := secrets.Update(key, rel); err != nil {
+		t.Fatalf("Failed to update release: %s", err)
+	}
+	n, _ = secretChunks(mock.objects[key])
+	if len(mock.objects) != n {
+		t.Errorf("Expected %d Secrets, got %d", n, len(mock.objects))
+	}
+
+	if _, err := secrets.Delete(key); err != nil {
+		t.Fatalf("Failed to delete release with key %q: %s", key, err)
+	}
+	if len(mock.objects) != 0 {
+		t.Errorf("Expected all Secrets to be deleted, got %d", len(mock.objects))
+	}
+}
+
+func TestSecretCompression(t *testing.T) {
+	rel := releaseStub("smug-pigeon", 1, "default", common.StatusDeployed)
+	key := testKey(rel.Name, rel.Version)
+
+	for _, zstd := range []bool{false, true} {
+		var mock MockSecretsInterface
+		mock.Init(t)
+		secrets := NewSecrets(&mock)
+		secrets.Zstd = zstd
+
+		if err := secrets.Create(key, rel); err != nil {
+			t.Fatalf("Failed to create release with key %q: %s", key, err)
+		}
+		obj := mock.objects[key]
+		if got := isZstdEncoded(string(obj.Data["release"])); got != zstd {
+			t.Errorf("Expected zstd compression %t, got %t", zstd, got)
+		}
+		if _, ok := obj.Data["chunks"]; ok {
+			t.Error("Expected the release to be stored in a single Secret")
+		}
+		got, err := secrets.Get(key)
+		if err != nil {
+			t.Fatalf("Failed to get release with key %q: %s", key, err)
+		}
+		if !reflect.DeepEqual(rel, got) {
+			t.Errorf("Expected {%v}, got {%v}", rel, got)
+		}
+	}
+}
+
+func TestSecretCompact(t *testing.T) {
+	rel1 := releaseStub("smug-pigeon", 1, "default", common.StatusSuperseded)
+	rel2 := releaseStub("smug-pigeon", 2, "default", common.StatusDeployed)
+	secrets := newTestFixtureSecrets(t, rel1, rel2)
+	mock := secrets.impl.(*MockSecretsInterface)
+
+	// releases are stored gzipped by default, the way earlier versions of
+	// Helm did
+	key1 := testKey(rel1.Name, rel1.Version)
+	key2 := testKey(rel2.Name, rel2.Version)
+	if isZstdEncoded(string(mock.objects[key1].Data["release"])) {
+		t.Fatal("Expected the release to be gzipped")
+	}
+	labels := maps.Clone(mock.objects[key1].Labels)
+
+	if _, err := secrets.Compact(); err == nil {
+		t.Fatal("Expected compaction to require zstd compression")
+	}
+
+	secrets.Zstd = true
+	keys, err := secrets.Compact()
+	if err != nil {
+		t.Fatalf("Failed to compact releases: %s", err)
+	}
+	if !reflect.DeepEqual(keys, []string{key1, key2}) {
+		t.Errorf("Expected %v to be compacted, got %v", []string{key1, key2}, keys)
+	}
+	if !isZstdEncoded(string(mock.objects[key1].Data["release"])) {
+		t.Error("Expected the release to be compressed with zstd")
+	}
+	if !reflect.DeepEqual(labels, mock.objects[key1].Labels) {
+		t.Errorf("Expected labels %v, got %v", labels, mock.objects[key1].Labels)
+	}
+	got, err := secrets.Get(key1)
+	if err != nil {
+		t.Fatalf("Failed to get release with key %q: %s", key1, err)
+	}
+	if !reflect.DeepEqual(rel1, got) {
+		t.Errorf("Expected {%v}, got {%v}", rel1, got)
+	}
+
+	keys, err = secrets.Compact()
+	if err != nil || len(keys) != 0 {
+		t.Errorf("Expected nothing to compact, got %v: %v", keys, err)
+	}
+}
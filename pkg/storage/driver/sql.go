@@ -17,9 +17,11 @@ limitations under the License.
 package driver // import "helm.sh/helm/v4/pkg/storage/driver"
 
 import (
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"maps"
+	"slices"
 	"sort"
 	"strconv"
 	"time"
@@ -48,7 +50,14 @@ var labelMap = map[string]struct{}{
 	"name":       {},
 }
 
-const postgreSQLDialect = "postgres"
+const (
+	// SQLDialectPostgres stores releases in a PostgreSQL database. This is the default.
+	SQLDialectPostgres = "postgres"
+	// SQLDialectMySQL stores releases in a MySQL database. The MySQL
+	// database/sql driver is not part of Helm and must be registered by the
+	// program, for example by importing github.com/go-sql-driver/mysql.
+	SQLDialectMySQL = "mysql"
+)
 
 // SQLDriverName is the string name of this driver.
 const SQLDriverName = "SQL"
@@ -88,6 +97,7 @@ const (
 // SQL is the sql storage driver implementation.
 type SQL struct {
 	db               *sqlx.DB
+	dialect          string
 	namespace        string
 	statementBuilder sq.StatementBuilderType
 	// Embed a LogHolder to provide logger functionality
@@ -99,6 +109,23 @@ func (s *SQL) Name() string {
 	return SQLDriverName
 }
 
+// sqlDialect returns the dialect of the driver, defaulting to PostgreSQL.
+func (s *SQL) sqlDialect() string {
+	if s.dialect == "" {
+		return SQLDialectPostgres
+	}
+	return s.dialect
+}
+
+// col returns a column name as used in queries. Some column names, such as
+// key, are reserved words in MySQL and are quoted.
+func (s *SQL) col(name string) string {
+	if s.sqlDialect() == SQLDialectMySQL {
+		return "`" + name + "`"
+	}
+	return name
+}
+
 // Check if all migrations al
 func (s *SQL) checkAlreadyApplied(migrations []*migrate.Migration) bool {
 	// make map (set) of ids for fast search
@@ -109,7 +136,7 @@ func (s *SQL) checkAlreadyApplied(migrations []*migrate.Migration) bool {
 
 	// get list of applied migrations
 	migrate.SetDisableCreateTable(true)
-	records, err := migrate.GetMigrationRecords(s.db.DB, postgreSQLDialect)
+	records, err := migrate.GetMigrationRecords(s.db.DB, s.sqlDialect())
 	migrate.SetDisableCreateTable(false)
 	if err != nil {
 		s.Logger().Debug("failed to get migration records", slog.Any("error", err))
@@ -135,11 +162,30 @@ func (s *SQL) checkAlreadyApplied(migrations []*migrate.Migration) bool {
 
 func (s *SQL) ensureDBSetup() error {
 	migrations := &migrate.MemoryMigrationSource{
-		Migrations: []*migrate.Migration{
-			{
-				Id: "init",
-				Up: []string{
-					fmt.Sprintf(`
+		Migrations: postgresMigrations(),
+	}
+	if s.sqlDialect() == SQLDialectMySQL {
+		migrations.Migrations = mysqlMigrations()
+	}
+
+	// Check that init migration already applied
+	if s.checkAlreadyApplied(migrations.Migrations) {
+		return nil
+	}
+
+	// Populate the database with the relations we need if they don't exist yet
+	_, err := migrate.Exec(s.db.DB, s.sqlDialect(), migrations, migrate.Up)
+	return err
+}
+
+// postgresMigrations returns the migrations creating the tables of the
+// PostgreSQL dialect.
+func postgresMigrations() []*migrate.Migration {
+	return []*migrate.Migration{
+		{
+			Id: "init",
+			Up: []string{
+				fmt.Sprintf(`
 						CREATE TABLE %s (
 							%s VARCHAR(90),
 							%s VARCHAR(64) NOT NULL,
@@ -164,46 +210,46 @@ func (s *SQL) ensureDBSetup() error {
 
 						ALTER TABLE %s ENABLE ROW LEVEL SECURITY;
 					`,
-						sqlReleaseTableName,
-						sqlReleaseTableKeyColumn,
-						sqlReleaseTableTypeColumn,
-						sqlReleaseTableBodyColumn,
-						sqlReleaseTableNameColumn,
-						sqlReleaseTableNamespaceColumn,
-						sqlReleaseTableVersionColumn,
-						sqlReleaseTableStatusColumn,
-						sqlReleaseTableOwnerColumn,
-						sqlReleaseTableCreatedAtColumn,
-						sqlReleaseTableModifiedAtColumn,
-						sqlReleaseTableKeyColumn,
-						sqlReleaseTableNamespaceColumn,
-						sqlReleaseTableName,
-						sqlReleaseTableKeyColumn,
-						sqlReleaseTableNamespaceColumn,
-						sqlReleaseTableName,
-						sqlReleaseTableVersionColumn,
-						sqlReleaseTableName,
-						sqlReleaseTableStatusColumn,
-						sqlReleaseTableName,
-						sqlReleaseTableOwnerColumn,
-						sqlReleaseTableName,
-						sqlReleaseTableCreatedAtColumn,
-						sqlReleaseTableName,
-						sqlReleaseTableModifiedAtColumn,
-						sqlReleaseTableName,
-						sqlReleaseTableName,
-					),
-				},
-				Down: []string{
-					fmt.Sprintf(`
+					sqlReleaseTableName,
+					sqlReleaseTableKeyColumn,
+					sqlReleaseTableTypeColumn,
+					sqlReleaseTableBodyColumn,
+					sqlReleaseTableNameColumn,
+					sqlReleaseTableNamespaceColumn,
+					sqlReleaseTableVersionColumn,
+					sqlReleaseTableStatusColumn,
+					sqlReleaseTableOwnerColumn,
+					sqlReleaseTableCreatedAtColumn,
+					sqlReleaseTableModifiedAtColumn,
+					sqlReleaseTableKeyColumn,
+					sqlReleaseTableNamespaceColumn,
+					sqlReleaseTableName,
+					sqlReleaseTableKeyColumn,
+					sqlReleaseTableNamespaceColumn,
+					sqlReleaseTableName,
+					sqlReleaseTableVersionColumn,
+					sqlReleaseTableName,
+					sqlReleaseTableStatusColumn,
+					sqlReleaseTableName,
+					sqlReleaseTableOwnerColumn,
+					sqlReleaseTableName,
+					sqlReleaseTableCreatedAtColumn,
+					sqlReleaseTableName,
+					sqlReleaseTableModifiedAtColumn,
+					sqlReleaseTableName,
+					sqlReleaseTableName,
+				),
+			},
+			Down: []string{
+				fmt.Sprintf(`
 						DROP TABLE %s;
 					`, sqlReleaseTableName),
-				},
 			},
-			{
-				Id: "custom_labels",
-				Up: []string{
-					fmt.Sprintf(`
+		},
+		{
+			Id: "custom_labels",
+			Up: []string{
+				fmt.Sprintf(`
 						CREATE TABLE %s (
 							%s VARCHAR(64),
 							%s VARCHAR(67),
@@ -215,37 +261,101 @@ func (s *SQL) ensureDBSetup() error {
 						GRANT ALL ON %s TO PUBLIC;
 						ALTER TABLE %s ENABLE ROW LEVEL SECURITY;
 					`,
-						sqlCustomLabelsTableName,
-						sqlCustomLabelsTableReleaseKeyColumn,
-						sqlCustomLabelsTableReleaseNamespaceColumn,
-						sqlCustomLabelsTableKeyColumn,
-						sqlCustomLabelsTableKeyMaxLength,
-						sqlCustomLabelsTableValueColumn,
-						sqlCustomLabelsTableValueMaxLength,
-						sqlCustomLabelsTableName,
-						sqlCustomLabelsTableReleaseKeyColumn,
-						sqlCustomLabelsTableReleaseNamespaceColumn,
-						sqlCustomLabelsTableName,
-						sqlCustomLabelsTableName,
-					),
-				},
-				Down: []string{
-					fmt.Sprintf(`
+					sqlCustomLabelsTableName,
+					sqlCustomLabelsTableReleaseKeyColumn,
+					sqlCustomLabelsTableReleaseNamespaceColumn,
+					sqlCustomLabelsTableKeyColumn,
+					sqlCustomLabelsTableKeyMaxLength,
+					sqlCustomLabelsTableValueColumn,
+					sqlCustomLabelsTableValueMaxLength,
+					sqlCustomLabelsTableName,
+					sqlCustomLabelsTableReleaseKeyColumn,
+					sqlCustomLabelsTableReleaseNamespaceColumn,
+					sqlCustomLabelsTableName,
+					sqlCustomLabelsTableName,
+				),
+			},
+			Down: []string{
+				fmt.Sprintf(`
 						DELETE TABLE %s;
 					`, sqlCustomLabelsTableName),
-				},
 			},
 		},
 	}
+}
 
-	// Check that init migration already applied
-	if s.checkAlreadyApplied(migrations.Migrations) {
-		return nil
+// mysqlMigrations returns the migrations creating the tables of the MySQL
+// dialect. Release bodies are stored as LONGTEXT, as TEXT is limited to 64KB
+// in MySQL, and each statement is run on its own as MySQL connections do not
+// allow multiple statements by default.
+func mysqlMigrations() []*migrate.Migration {
+	return []*migrate.Migration{
+		{
+			Id: "init",
+			Up: []string{
+				fmt.Sprintf("CREATE TABLE %s ("+
+					"`%s` VARCHAR(90), "+
+					"`%s` VARCHAR(64) NOT NULL, "+
+					"`%s` LONGTEXT NOT NULL, "+
+					"`%s` VARCHAR(64) NOT NULL, "+
+					"`%s` VARCHAR(64) NOT NULL, "+
+					"`%s` INTEGER NOT NULL, "+
+					"`%s` VARCHAR(64) NOT NULL, "+
+					"`%s` VARCHAR(64) NOT NULL, "+
+					"`%s` INTEGER NOT NULL, "+
+					"`%s` INTEGER NOT NULL DEFAULT 0, "+
+					"PRIMARY KEY(`%s`, `%s`), "+
+					"INDEX (`%s`), INDEX (`%s`), INDEX (`%s`), INDEX (`%s`), INDEX (`%s`), INDEX (`%s`))",
+					sqlReleaseTableName,
+					sqlReleaseTableKeyColumn,
+					sqlReleaseTableTypeColumn,
+					sqlReleaseTableBodyColumn,
+					sqlReleaseTableNameColumn,
+					sqlReleaseTableNamespaceColumn,
+					sqlReleaseTableVersionColumn,
+					sqlReleaseTableStatusColumn,
+					sqlReleaseTableOwnerColumn,
+					sqlReleaseTableCreatedAtColumn,
+					sqlReleaseTableModifiedAtColumn,
+					sqlReleaseTableKeyColumn,
+					sqlReleaseTableNamespaceColumn,
+					sqlReleaseTableNameColumn,
+					sqlReleaseTableVersionColumn,
+					sqlReleaseTableStatusColumn,
+					sqlReleaseTableOwnerColumn,
+					sqlReleaseTableCreatedAtColumn,
+					sqlReleaseTableModifiedAtColumn,
+				),
+			},
+			Down: []string{
+				fmt.Sprintf("DROP TABLE %s", sqlReleaseTableName),
+			},
+		},
+		{
+			Id: "custom_labels",
+			Up: []string{
+				fmt.Sprintf("CREATE TABLE %s ("+
+					"`%s` VARCHAR(64), "+
+					"`%s` VARCHAR(67), "+
+					"`%s` VARCHAR(%d), "+
+					"`%s` VARCHAR(%d), "+
+					"INDEX (`%s`, `%s`))",
+					sqlCustomLabelsTableName,
+					sqlCustomLabelsTableReleaseKeyColumn,
+					sqlCustomLabelsTableReleaseNamespaceColumn,
+					sqlCustomLabelsTableKeyColumn,
+					sqlCustomLabelsTableKeyMaxLength,
+					sqlCustomLabelsTableValueColumn,
+					sqlCustomLabelsTableValueMaxLength,
+					sqlCustomLabelsTableReleaseKeyColumn,
+					sqlCustomLabelsTableReleaseNamespaceColumn,
+				),
+			},
+			Down: []string{
+				fmt.Sprintf("DROP TABLE %s", sqlCustomLabelsTableName),
+			},
+		},
 	}
-
-	// Populate the database with the relations we need if they don't exist yet
-	_, err := migrate.Exec(s.db.DB, postgreSQLDialect, migrations, migrate.Up)
-	return err
 }
 
 // SQLReleaseWrapper describes how Helm releases are stored in an SQL database
@@ -278,16 +388,36 @@ type SQLReleaseCustomLabelWrapper struct {
 	Value            string `db:"value"`
 }
 
-// NewSQL initializes a new sql driver.
+// NewSQL initializes a new sql driver storing releases in PostgreSQL.
 func NewSQL(connectionString string, namespace string) (*SQL, error) {
-	db, err := sqlx.Connect(postgreSQLDialect, connectionString)
+	return NewSQLWithDialect(SQLDialectPostgres, connectionString, namespace)
+}
+
+// NewSQLWithDialect initializes a new sql driver storing releases in a
+// database of the given dialect, either SQLDialectPostgres or SQLDialectMySQL.
+// The database/sql driver of the dialect must be registered.
+func NewSQLWithDialect(dialect, connectionString, namespace string) (*SQL, error) {
+	var placeholders sq.PlaceholderFormat = sq.Dollar
+	switch dialect {
+	case SQLDialectPostgres:
+	case SQLDialectMySQL:
+		placeholders = sq.Question
+	default:
+		return nil, fmt.Errorf("unknown SQL dialect %q: must be one of %s, %s", dialect, SQLDialectPostgres, SQLDialectMySQL)
+	}
+	if !slices.Contains(sql.Drivers(), dialect) {
+		return nil, fmt.Errorf("no database driver registered for SQL dialect %q", dialect)
+	}
+
+	db, err := sqlx.Connect(dialect, connectionString)
 	if err != nil {
 		return nil, err
 	}
 
 	driver := &SQL{
 		db:               db,
-		statementBuilder: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		dialect:          dialect,
+		statementBuilder: sq.StatementBuilder.PlaceholderFormat(placeholders),
 	}
 
 	if err := driver.ensureDBSetup(); err != nil {
@@ -305,10 +435,10 @@ func (s *SQL) Get(key string) (release.Releaser, error) {
 	var record SQLReleaseWrapper
 
 	qb := s.statementBuilder.
-		Select(sqlReleaseTableBodyColumn).
+		Select(s.col(sqlReleaseTableBodyColumn)).
 		From(sqlReleaseTableName).
-		Where(sq.Eq{sqlReleaseTableKeyColumn: key}).
-		Where(sq.Eq{sqlReleaseTableNamespaceColumn: s.namespace})
+		Where(sq.Eq{s.col(sqlReleaseTableKeyColumn): key}).
+		Where(sq.Eq{s.col(sqlReleaseTableNamespaceColumn): s.namespace})
 
 	query, args, err := qb.ToSql()
 	if err != nil {
@@ -344,13 +474,13 @@ func (s *SQL) Get(key string) (release.Releaser, error) {
 // List returns the list of all releases such that filter(release) == true
 func (s *SQL) List(filter func(release.Releaser) bool) ([]release.Releaser, error) {
 	sb := s.statementBuilder.
-		Select(sqlReleaseTableKeyColumn, sqlReleaseTableNamespaceColumn, sqlReleaseTableBodyColumn).
+		Select(s.col(sqlReleaseTableKeyColumn), s.col(sqlReleaseTableNamespaceColumn), s.col(sqlReleaseTableBodyColumn)).
 		From(sqlReleaseTableName).
-		Where(sq.Eq{sqlReleaseTableOwnerColumn: sqlReleaseDefaultOwner})
+		Where(sq.Eq{s.col(sqlReleaseTableOwnerColumn): sqlReleaseDefaultOwner})
 
 	// If a namespace was specified, we only list releases from that namespace
 	if s.namespace != "" {
-		sb = sb.Where(sq.Eq{sqlReleaseTableNamespaceColumn: s.namespace})
+		sb = sb.Where(sq.Eq{s.col(sqlReleaseTableNamespaceColumn): s.namespace})
 	}
 
 	query, args, err := sb.ToSql()
@@ -395,7 +525,7 @@ func (s *SQL) List(filter func(release.Releaser) bool) ([]release.Releaser, erro
 // Query returns the set of releases that match the provided set of labels.
 func (s *SQL) Query(labels map[string]string) ([]release.Releaser, error) {
 	sb := s.statementBuilder.
-		Select(sqlReleaseTableKeyColumn, sqlReleaseTableNamespaceColumn, sqlReleaseTableBodyColumn).
+		Select(s.col(sqlReleaseTableKeyColumn), s.col(sqlReleaseTableNamespaceColumn), s.col(sqlReleaseTableBodyColumn)).
 		From(sqlReleaseTableName)
 
 	keys := make([]string, 0, len(labels))
@@ -405,7 +535,7 @@ func (s *SQL) Query(labels map[string]string) ([]release.Releaser, error) {
 	sort.Strings(keys)
 	for _, key := range keys {
 		if _, ok := labelMap[key]; ok {
-			sb = sb.Where(sq.Eq{key: labels[key]})
+			sb = sb.Where(sq.Eq{s.col(key): labels[key]})
 		} else {
 			s.Logger().Debug("unknown label", "key", key)
 			return nil, fmt.Errorf("unknown label %s", key)
@@ -414,7 +544,7 @@ func (s *SQL) Query(labels map[string]string) ([]release.Releaser, error) {
 
 	// If a namespace was specified, we only list releases from that namespace
 	if s.namespace != "" {
-		sb = sb.Where(sq.Eq{sqlReleaseTableNamespaceColumn: s.namespace})
+		sb = sb.Where(sq.Eq{s.col(sqlReleaseTableNamespaceColumn): s.namespace})
 	}
 
 	// Build our query
@@ -490,15 +620,15 @@ func (s *SQL) Create(key string, rel release.Releaser) error {
 	insertQuery, args, err := s.statementBuilder.
 		Insert(sqlReleaseTableName).
 		Columns(
-			sqlReleaseTableKeyColumn,
-			sqlReleaseTableTypeColumn,
-			sqlReleaseTableBodyColumn,
-			sqlReleaseTableNameColumn,
-			sqlReleaseTableNamespaceColumn,
-			sqlReleaseTableVersionColumn,
-			sqlReleaseTableStatusColumn,
-			sqlReleaseTableOwnerColumn,
-			sqlReleaseTableCreatedAtColumn,
+			s.col(sqlReleaseTableKeyColumn),
+			s.col(sqlReleaseTableTypeColumn),
+			s.col(sqlReleaseTableBodyColumn),
+			s.col(sqlReleaseTableNameColumn),
+			s.col(sqlReleaseTableNamespaceColumn),
+			s.col(sqlReleaseTableVersionColumn),
+			s.col(sqlReleaseTableStatusColumn),
+			s.col(sqlReleaseTableOwnerColumn),
+			s.col(sqlReleaseTableCreatedAtColumn),
 		).
 		Values(
 			key,
@@ -520,10 +650,10 @@ func (s *SQL) Create(key string, rel release.Releaser) error {
 		defer transaction.Rollback()
 
 		selectQuery, args, buildErr := s.statementBuilder.
-			Select(sqlReleaseTableKeyColumn).
+			Select(s.col(sqlReleaseTableKeyColumn)).
 			From(sqlReleaseTableName).
-			Where(sq.Eq{sqlReleaseTableKeyColumn: key}).
-			Where(sq.Eq{sqlReleaseTableNamespaceColumn: s.namespace}).
+			Where(sq.Eq{s.col(sqlReleaseTableKeyColumn): key}).
+			Where(sq.Eq{s.col(sqlReleaseTableNamespaceColumn): s.namespace}).
 			ToSql()
 		if buildErr != nil {
 			s.Logger().Debug("failed to build select query", "error", buildErr)
@@ -545,10 +675,10 @@ func (s *SQL) Create(key string, rel release.Releaser) error {
 		insertLabelsQuery, args, err := s.statementBuilder.
 			Insert(sqlCustomLabelsTableName).
 			Columns(
-				sqlCustomLabelsTableReleaseKeyColumn,
-				sqlCustomLabelsTableReleaseNamespaceColumn,
-				sqlCustomLabelsTableKeyColumn,
-				sqlCustomLabelsTableValueColumn,
+				s.col(sqlCustomLabelsTableReleaseKeyColumn),
+				s.col(sqlCustomLabelsTableReleaseNamespaceColumn),
+				s.col(sqlCustomLabelsTableKeyColumn),
+				s.col(sqlCustomLabelsTableValueColumn),
 			).
 			Values(
 				key,
@@ -594,14 +724,14 @@ func (s *SQL) Update(key string, rel release.Releaser) error {
 
 	query, args, err := s.statementBuilder.
 		Update(sqlReleaseTableName).
-		Set(sqlReleaseTableBodyColumn, body).
-		Set(sqlReleaseTableNameColumn, rls.Name).
-		Set(sqlReleaseTableVersionColumn, int(rls.Version)).
-		Set(sqlReleaseTableStatusColumn, rls.Info.Status.String()).
-		Set(sqlReleaseTableOwnerColumn, sqlReleaseDefaultOwner).
-		Set(sqlReleaseTableModifiedAtColumn, int(time.Now().Unix())).
-		Where(sq.Eq{sqlReleaseTableKeyColumn: key}).
-		Where(sq.Eq{sqlReleaseTableNamespaceColumn: namespace}).
+		Set(s.col(sqlReleaseTableBodyColumn), body).
+		Set(s.col(sqlReleaseTableNameColumn), rls.Name).
+		Set(s.col(sqlReleaseTableVersionColumn), int(rls.Version)).
+		Set(s.col(sqlReleaseTableStatusColumn), rls.Info.Status.String()).
+		Set(s.col(sqlReleaseTableOwnerColumn), sqlReleaseDefaultOwner).
+		Set(s.col(sqlReleaseTableModifiedAtColumn), int(time.Now().Unix())).
+		Where(sq.Eq{s.col(sqlReleaseTableKeyColumn): key}).
+		Where(sq.Eq{s.col(sqlReleaseTableNamespaceColumn): namespace}).
 		ToSql()
 
 	if err != nil {
@@ -626,10 +756,10 @@ func (s *SQL) Delete(key string) (release.Releaser, error) {
 	}
 
 	selectQuery, args, err := s.statementBuilder.
-		Select(sqlReleaseTableBodyColumn).
+		Select(s.col(sqlReleaseTableBodyColumn)).
 		From(sqlReleaseTableName).
-		Where(sq.Eq{sqlReleaseTableKeyColumn: key}).
-		Where(sq.Eq{sqlReleaseTableNamespaceColumn: s.namespace}).
+		Where(sq.Eq{s.col(sqlReleaseTableKeyColumn): key}).
+		Where(sq.Eq{s.col(sqlReleaseTableNamespaceColumn): s.namespace}).
 		ToSql()
 	if err != nil {
 		s.Logger().Debug("failed to build select query", slog.Any("error", err))
@@ -653,8 +783,8 @@ func (s *SQL) Delete(key string) (release.Releaser, error) {
 
 	deleteQuery, args, err := s.statementBuilder.
 		Delete(sqlReleaseTableName).
-		Where(sq.Eq{sqlReleaseTableKeyColumn: key}).
-		Where(sq.Eq{sqlReleaseTableNamespaceColumn: s.namespace}).
+		Where(sq.Eq{s.col(sqlReleaseTableKeyColumn): key}).
+		Where(sq.Eq{s.col(sqlReleaseTableNamespaceColumn): s.namespace}).
 		ToSql()
 	if err != nil {
 		s.Logger().Debug("failed to build delete query", slog.Any("error", err))
@@ -678,8 +808,8 @@ func (s *SQL) Delete(key string) (release.Releaser, error) {
 
 	deleteCustomLabelsQuery, args, err := s.statementBuilder.
 		Delete(sqlCustomLabelsTableName).
-		Where(sq.Eq{sqlCustomLabelsTableReleaseKeyColumn: key}).
-		Where(sq.Eq{sqlCustomLabelsTableReleaseNamespaceColumn: s.namespace}).
+		Where(sq.Eq{s.col(sqlCustomLabelsTableReleaseKeyColumn): key}).
+		Where(sq.Eq{s.col(sqlCustomLabelsTableReleaseNamespaceColumn): s.namespace}).
 		ToSql()
 
 	if err != nil {
@@ -693,10 +823,10 @@ func (s *SQL) Delete(key string) (release.Releaser, error) {
 // Get release custom labels from database
 func (s *SQL) getReleaseCustomLabels(key string, _ string) (map[string]string, error) {
 	query, args, err := s.statementBuilder.
-		Select(sqlCustomLabelsTableKeyColumn, sqlCustomLabelsTableValueColumn).
+		Select(s.col(sqlCustomLabelsTableKeyColumn), s.col(sqlCustomLabelsTableValueColumn)).
 		From(sqlCustomLabelsTableName).
-		Where(sq.Eq{sqlCustomLabelsTableReleaseKeyColumn: key,
-			sqlCustomLabelsTableReleaseNamespaceColumn: s.namespace}).
+		Where(sq.Eq{s.col(sqlCustomLabelsTableReleaseKeyColumn): key,
+			s.col(sqlCustomLabelsTableReleaseNamespaceColumn): s.namespace}).
 		ToSql()
 	if err != nil {
 		return nil, err
@@ -19,10 +19,12 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
 	migrate "github.com/rubenv/sql-migrate"
 
 	"helm.sh/helm/v4/pkg/release"
@@ -110,6 +112,67 @@ func TestSQLGet(t *testing.T) {
 	}
 }
 
+func TestSQLGetMySQL(t *testing.T) {
+	vers := int(1)
+	name := "smug-pigeon"
+	namespace := "default"
+	key := testKey(name, vers)
+	rel := releaseStub(name, vers, namespace, common.StatusDeployed)
+
+	body, _ := encodeRelease(rel)
+
+	sqlDriver, mock := newTestFixtureSQL(t)
+	sqlDriver.dialect = SQLDialectMySQL
+	sqlDriver.statementBuilder = sq.StatementBuilder.PlaceholderFormat(sq.Question)
+
+	mock.
+		ExpectQuery(regexp.QuoteMeta("SELECT `body` FROM releases_v1 WHERE `key` = ? AND `namespace` = ?")).
+		WithArgs(key, namespace).
+		WillReturnRows(mock.NewRows([]string{sqlReleaseTableBodyColumn}).AddRow(body)).
+		RowsWillBeClosed()
+
+	labelRows := mock.NewRows([]string{sqlCustomLabelsTableKeyColumn, sqlCustomLabelsTableValueColumn})
+	for k, v := range rel.Labels {
+		labelRows.AddRow(k, v)
+	}
+	mock.
+		ExpectQuery(regexp.QuoteMeta("SELECT `key`, `value` FROM custom_labels_v1 WHERE `releaseKey` = ? AND `releaseNamespace` = ?")).
+		WithArgs(key, namespace).
+		WillReturnRows(labelRows).
+		RowsWillBeClosed()
+
+	got, err := sqlDriver.Get(key)
+	if err != nil {
+		t.Fatalf("Failed to get release: %v", err)
+	}
+	if !reflect.DeepEqual(rel, got) {
+		t.Errorf("Expected release {%v}, got {%v}", rel, got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("sql expectations weren't met: %v", err)
+	}
+}
+
+func TestNewSQLWithDialectErrors(t *testing.T) {
+	if _, err := NewSQLWithDialect("sqlite", "", "default"); err == nil || !strings.Contains(err.Error(), "unknown SQL dialect") {
+		t.Errorf("Expected an unknown dialect error, got %v", err)
+	}
+	if _, err := NewSQLWithDialect(SQLDialectMySQL, "", "default"); err == nil || !strings.Contains(err.Error(), "no database driver registered") {
+		t.Errorf("Expected a missing driver error, got %v", err)
+	}
+}
+
+func TestMySQLMigrations(t *testing.T) {
+	for _, m := range mysqlMigrations() {
+		for _, up := range m.Up {
+			if strings.Contains(up, ";") || strings.Contains(up, "ROW LEVEL SECURITY") || strings.Contains(up, "GRANT") {
+				t.Errorf("migration %s is not a single MySQL statement: %s", m.Id, up)
+			}
+		}
+	}
+}
+
 func TestSQLList(t *testing.T) {
 	releases := []*rspb.Release{}
 	releases = append(releases, releaseStub("key-1", 1, "default", common.StatusUninstalled))
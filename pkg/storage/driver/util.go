@@ -24,6 +24,8 @@ import (
 	"io"
 	"slices"
 
+	"github.com/klauspost/compress/zstd"
+
 	rspb "helm.sh/helm/v4/pkg/release/v1"
 )
 
@@ -31,6 +33,8 @@ var b64 = base64.StdEncoding
 
 var magicGzip = []byte{0x1f, 0x8b, 0x08}
 
+var magicZstd = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
 var systemLabels = []string{"name", "owner", "status", "version", "createdAt", "modifiedAt"}
 
 // encodeRelease encodes a release returning a base64 encoded
@@ -56,9 +60,36 @@ func encodeRelease(rls *rspb.Release) (string, error) {
 	return b64.EncodeToString(buf.Bytes()), nil
 }
 
+// encodeReleaseZstd encodes a release returning a base64 encoded
+// zstd compressed string representation, or error.
+func encodeReleaseZstd(rls *rspb.Release) (string, error) {
+	b, err := json.Marshal(rls)
+	if err != nil {
+		return "", err
+	}
+	w, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return "", err
+	}
+	defer w.Close()
+
+	return b64.EncodeToString(w.EncodeAll(b, nil)), nil
+}
+
+// isZstdEncoded reports whether data is a release encoded by encodeReleaseZstd.
+func isZstdEncoded(data string) bool {
+	// 8 base64 characters hold the first 6 bytes, enough for the magic header
+	if len(data) < 8 {
+		return false
+	}
+	b, err := b64.DecodeString(data[:8])
+	return err == nil && bytes.HasPrefix(b, magicZstd)
+}
+
 // decodeRelease decodes the bytes of data into a release
-// type. Data must contain a base64 encoded gzipped string of a
-// valid release, otherwise an error is returned.
+// type. Data must contain a base64 encoded gzipped or zstd
+// compressed string of a valid release, otherwise an error
+// is returned.
 func decodeRelease(data string) (*rspb.Release, error) {
 	// base64 decode string
 	b, err := b64.DecodeString(data)
@@ -66,6 +97,18 @@ func decodeRelease(data string) (*rspb.Release, error) {
 		return nil, err
 	}
 
+	if len(b) > 4 && bytes.Equal(b[0:4], magicZstd) {
+		r, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		b, err = r.DecodeAll(b, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// For backwards compatibility with releases that were stored before
 	// compression was introduced we skip decompression if the
 	// gzip magic header is not found
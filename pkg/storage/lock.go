@@ -0,0 +1,291 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"helm.sh/helm/v4/internal/logging"
+)
+
+// HelmLockType is the prefix of the names of the Leases that lock releases.
+const HelmLockType = "sh.helm.release.lock"
+
+// operationAnnotation records the operation holding a release lock.
+const operationAnnotation = "helm.sh/operation"
+
+// DefaultLeaseDuration is how long a release lock is valid without being
+// renewed. The lock of an interrupted operation expires after this duration.
+const DefaultLeaseDuration = 60 * time.Second
+
+// lockRetryInterval is how often a held lock is checked while waiting for it.
+var lockRetryInterval = time.Second
+
+// ErrReleaseLocked is matched by the errors returned when a release is locked
+// by another operation.
+var ErrReleaseLocked = errors.New("release locked")
+
+// ErrLockLost is the cause of the cancellation of the context of an operation
+// whose release lock could not be renewed.
+var ErrLockLost = errors.New("release lock lost")
+
+// Lock describes the lock of a release held by an operation.
+type Lock struct {
+	// Release is the name of the locked release.
+	Release string
+	// Operation is the operation holding the lock, such as "upgrade".
+	Operation string
+	// Holder identifies the Helm process holding the lock.
+	Holder string
+	// Acquired is when the lock was acquired.
+	Acquired time.Time
+}
+
+// LockedError is returned when a release is locked by another operation.
+type LockedError struct {
+	Lock Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("release %s locked by operation %s started at %s", e.Lock.Release, e.Lock.Operation, e.Lock.Acquired.Format(time.RFC3339))
+}
+
+// Is makes LockedError match ErrReleaseLocked.
+func (e *LockedError) Is(target error) bool {
+	return target == ErrReleaseLocked
+}
+
+// Locker locks releases so that only one operation at a time modifies a release.
+type Locker interface {
+	// Lock acquires the lock of a release for an operation, waiting up to
+	// timeout for another operation to release it. It returns a context
+	// derived from ctx, which is canceled if the lock is lost while it is
+	// held, and a function releasing the lock.
+	Lock(ctx context.Context, release, operation string, timeout time.Duration) (context.Context, func() error, error)
+	// ForceUnlock removes the lock of a release regardless of its holder. It
+	// returns the removed lock, or nil if the release was not locked.
+	ForceUnlock(ctx context.Context, release string) (*Lock, error)
+}
+
+// LeaseClient is the subset of the Kubernetes Lease client used by LeaseLocker.
+type LeaseClient interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*coordinationv1.Lease, error)
+	Create(ctx context.Context, lease *coordinationv1.Lease, opts metav1.CreateOptions) (*coordinationv1.Lease, error)
+	Update(ctx context.Context, lease *coordinationv1.Lease, opts metav1.UpdateOptions) (*coordinationv1.Lease, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+// LeaseLocker locks releases with Kubernetes Leases. A lock is renewed while
+// it is held, so that the lock of an interrupted operation expires. Releases
+// are not locked if the user is not allowed to manage Leases.
+type LeaseLocker struct {
+	client   LeaseClient
+	identity string
+
+	// LeaseDuration is how long a lock is valid without being renewed.
+	LeaseDuration time.Duration
+
+	// Embed a LogHolder to provide logger functionality
+	logging.LogHolder
+}
+
+// NewLeaseLocker initializes a new LeaseLocker using the Lease client.
+func NewLeaseLocker(client LeaseClient) *LeaseLocker {
+	hostname, _ := os.Hostname()
+	return &LeaseLocker{
+		client:        client,
+		identity:      fmt.Sprintf("%s_%s", hostname, rand.String(8)),
+		LeaseDuration: DefaultLeaseDuration,
+	}
+}
+
+// Lock acquires the lock of a release for an operation.
+func (l *LeaseLocker) Lock(ctx context.Context, release, operation string, timeout time.Duration) (context.Context, func() error, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		lease, err := l.acquire(ctx, release, operation)
+		if err == nil {
+			lockCtx, unlock := l.hold(ctx, lease)
+			return lockCtx, unlock, nil
+		}
+		if apierrors.IsForbidden(err) {
+			l.Logger().Warn("not locking release: Leases are forbidden", "release", release, "error", err)
+			return ctx, func() error { return nil }, nil
+		}
+		if !errors.Is(err, ErrReleaseLocked) || !time.Now().Before(deadline) {
+			return nil, nil, err
+		}
+		l.Logger().Debug("waiting for release lock", "release", release, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// acquire creates the Lease of a release, or takes it over if it has expired.
+func (l *LeaseLocker) acquire(ctx context.Context, release, operation string) (*coordinationv1.Lease, error) {
+	name := lockName(release)
+	for {
+		now := metav1.NewMicroTime(time.Now())
+		lease, err := l.client.Get(ctx, name, metav1.GetOptions{})
+		exists := err == nil
+		if apierrors.IsNotFound(err) {
+			lease = &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: map[string]string{"owner": "helm", "name": release},
+				},
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to get lock of release %s: %w", release, err)
+		} else if !l.expired(lease, now.Time) {
+			return nil, &LockedError{Lock: leaseLock(release, lease)}
+		}
+
+		seconds := int32(l.LeaseDuration.Seconds())
+		if lease.Annotations == nil {
+			lease.Annotations = map[string]string{}
+		}
+		lease.Annotations[operationAnnotation] = operation
+		lease.Spec.HolderIdentity = &l.identity
+		lease.Spec.LeaseDurationSeconds = &seconds
+		lease.Spec.AcquireTime = &now
+		lease.Spec.RenewTime = &now
+
+		if exists {
+			lease, err = l.client.Update(ctx, lease, metav1.UpdateOptions{})
+		} else {
+			lease, err = l.client.Create(ctx, lease, metav1.CreateOptions{})
+		}
+		// Another operation acquired the lock in the meantime
+		if apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to lock release %s: %w", release, err)
+		}
+		return lease, nil
+	}
+}
+
+// expired returns whether a Lease is no longer held.
+func (l *LeaseLocker) expired(lease *coordinationv1.Lease, now time.Time) bool {
+	spec := lease.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || spec.RenewTime == nil {
+		return true
+	}
+	duration := l.LeaseDuration
+	if spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*spec.LeaseDurationSeconds) * time.Second
+	}
+	return spec.RenewTime.Add(duration).Before(now)
+}
+
+// hold renews a Lease until the returned function is called, which deletes it.
+// The returned context is canceled with ErrLockLost if the Lease cannot be
+// renewed, since another operation may take it over once it expires.
+func (l *LeaseLocker) hold(ctx context.Context, lease *coordinationv1.Lease) (context.Context, func() error) {
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	var mu sync.Mutex
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(l.LeaseDuration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				mu.Lock()
+				renewed := lease.DeepCopy()
+				now := metav1.NewMicroTime(time.Now())
+				renewed.Spec.RenewTime = &now
+				updated, err := l.client.Update(context.Background(), renewed, metav1.UpdateOptions{})
+				if err != nil {
+					mu.Unlock()
+					l.Logger().Warn("failed to renew release lock", "lease", lease.Name, "error", err)
+					cancel(fmt.Errorf("%w: %w", ErrLockLost, err))
+					return
+				}
+				lease = updated
+				mu.Unlock()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return lockCtx, func() error {
+		var err error
+		once.Do(func() {
+			close(done)
+			cancel(nil)
+			mu.Lock()
+			defer mu.Unlock()
+			err = l.client.Delete(context.Background(), lease.Name, metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{UID: &lease.UID},
+			})
+			if apierrors.IsNotFound(err) {
+				err = nil
+			}
+		})
+		return err
+	}
+}
+
+// ForceUnlock removes the lock of a release regardless of its holder.
+func (l *LeaseLocker) ForceUnlock(ctx context.Context, release string) (*Lock, error) {
+	lease, err := l.client.Get(ctx, lockName(release), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := l.client.Delete(ctx, lease.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	lock := leaseLock(release, lease)
+	return &lock, nil
+}
+
+// lockName returns the name of the Lease locking a release.
+func lockName(release string) string {
+	return HelmLockType + "." + release
+}
+
+func leaseLock(release string, lease *coordinationv1.Lease) Lock {
+	lock := Lock{Release: release, Operation: lease.Annotations[operationAnnotation]}
+	if lease.Spec.HolderIdentity != nil {
+		lock.Holder = *lease.Spec.HolderIdentity
+	}
+	if lease.Spec.AcquireTime != nil {
+		lock.Acquired = lease.Spec.AcquireTime.Time
+	}
+	return lock
+}
@@ -0,0 +1,207 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func newTestLeaseLocker(t *testing.T, objects ...*coordinationv1.Lease) (*LeaseLocker, LeaseClient) {
+	t.Helper()
+	clientset := fake.NewClientset()
+	client := clientset.CoordinationV1().Leases("default")
+	for _, obj := range objects {
+		_, err := client.Create(t.Context(), obj, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	return NewLeaseLocker(client), client
+}
+
+func TestLeaseLockerLock(t *testing.T) {
+	locker, client := newTestLeaseLocker(t)
+
+	_, unlock, err := locker.Lock(t.Context(), "angry-beaver", "upgrade", 0)
+	require.NoError(t, err)
+
+	lease, err := client.Get(t.Context(), "sh.helm.release.lock.angry-beaver", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "upgrade", lease.Annotations["helm.sh/operation"])
+
+	// A second operation fails fast
+	other := NewLeaseLocker(client)
+	_, _, err = other.Lock(t.Context(), "angry-beaver", "rollback", 0)
+	require.ErrorIs(t, err, ErrReleaseLocked)
+	assert.Contains(t, err.Error(), "release angry-beaver locked by operation upgrade started at")
+
+	// Other releases are not affected
+	_, unlockOther, err := other.Lock(t.Context(), "happy-panda", "install", 0)
+	require.NoError(t, err)
+	require.NoError(t, unlockOther())
+
+	require.NoError(t, unlock())
+	_, unlock, err = other.Lock(t.Context(), "angry-beaver", "rollback", 0)
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
+
+func TestLeaseLockerWaitsForLock(t *testing.T) {
+	defer func(interval time.Duration) { lockRetryInterval = interval }(lockRetryInterval)
+	lockRetryInterval = 10 * time.Millisecond
+
+	locker, client := newTestLeaseLocker(t)
+	_, unlock, err := locker.Lock(t.Context(), "angry-beaver", "upgrade", 0)
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		unlock()
+	}()
+
+	_, unlock, err = NewLeaseLocker(client).Lock(t.Context(), "angry-beaver", "rollback", 5*time.Second)
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
+
+func TestLeaseLockerTakesOverExpiredLock(t *testing.T) {
+	holder := "crashed"
+	seconds := int32(60)
+	acquired := metav1.NewMicroTime(time.Now().Add(-time.Hour))
+	locker, _ := newTestLeaseLocker(t, &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.lock.angry-beaver"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &seconds,
+			AcquireTime:          &acquired,
+			RenewTime:            &acquired,
+		},
+	})
+
+	_, unlock, err := locker.Lock(t.Context(), "angry-beaver", "upgrade", 0)
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
+
+func TestLeaseLockerForbidden(t *testing.T) {
+	clientset := fake.NewClientset()
+	clientset.PrependReactor("*", "leases", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, "", errors.New("no RBAC"))
+	})
+	locker := NewLeaseLocker(clientset.CoordinationV1().Leases("default"))
+
+	// Releases are not locked without the permission to manage Leases
+	ctx, unlock, err := locker.Lock(t.Context(), "angry-beaver", "upgrade", 0)
+	require.NoError(t, err)
+	assert.Equal(t, t.Context(), ctx)
+	require.NoError(t, unlock())
+}
+
+func TestLeaseLockerLost(t *testing.T) {
+	clientset := fake.NewClientset()
+	locker := NewLeaseLocker(clientset.CoordinationV1().Leases("default"))
+	locker.LeaseDuration = 30 * time.Millisecond
+
+	ctx, unlock, err := locker.Lock(t.Context(), "angry-beaver", "upgrade", 0)
+	require.NoError(t, err)
+	defer unlock()
+
+	// The lock is lost when it cannot be renewed
+	clientset.PrependReactor("update", "leases", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+	select {
+	case <-ctx.Done():
+		assert.ErrorIs(t, context.Cause(ctx), ErrLockLost)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the context was not canceled when the lock was lost")
+	}
+}
+
+func TestLeaseLockerForceUnlock(t *testing.T) {
+	locker, client := newTestLeaseLocker(t)
+
+	lock, err := locker.ForceUnlock(t.Context(), "angry-beaver")
+	require.NoError(t, err)
+	assert.Nil(t, lock)
+
+	_, _, err = locker.Lock(t.Context(), "angry-beaver", "upgrade", 0)
+	require.NoError(t, err)
+
+	lock, err = NewLeaseLocker(client).ForceUnlock(t.Context(), "angry-beaver")
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+	assert.Equal(t, "upgrade", lock.Operation)
+
+	_, unlock, err := locker.Lock(t.Context(), "angry-beaver", "rollback", 0)
+	require.NoError(t, err)
+	require.NoError(t, unlock())
+}
+
+type failingLocker struct{ err error }
+
+func (f failingLocker) Lock(_ context.Context, _, _ string, _ time.Duration) (context.Context, func() error, error) {
+	return nil, nil, f.err
+}
+
+func (f failingLocker) ForceUnlock(_ context.Context, _ string) (*Lock, error) {
+	return nil, f.err
+}
+
+func TestStorageLock(t *testing.T) {
+	storage := Init(driver.NewMemory())
+	_, unlock, err := storage.Lock(t.Context(), "angry-beaver", "upgrade", 0)
+	require.NoError(t, err)
+	unlock()
+
+	storage.Locker = failingLocker{err: errors.New("locked")}
+	_, _, err = storage.Lock(t.Context(), "angry-beaver", "upgrade", 0)
+	assert.EqualError(t, err, "locked")
+}
+
+func TestStorageLockIsShared(t *testing.T) {
+	locker, client := newTestLeaseLocker(t)
+	storage := Init(driver.NewMemory())
+	storage.Locker = locker
+
+	_, unlock, err := storage.Lock(t.Context(), "angry-beaver", "upgrade", 0)
+	require.NoError(t, err)
+	// The rollback of a failed upgrade shares its lock
+	_, unlockNested, err := storage.Lock(t.Context(), "angry-beaver", "rollback", 0)
+	require.NoError(t, err)
+	unlockNested()
+
+	_, _, err = NewLeaseLocker(client).Lock(t.Context(), "angry-beaver", "upgrade", 0)
+	require.ErrorIs(t, err, ErrReleaseLocked)
+
+	unlock()
+	_, unlockOther, err := NewLeaseLocker(client).Lock(t.Context(), "angry-beaver", "upgrade", 0)
+	require.NoError(t, err)
+	require.NoError(t, unlockOther())
+}
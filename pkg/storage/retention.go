@@ -0,0 +1,159 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v4/pkg/release"
+	"helm.sh/helm/v4/pkg/release/common"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	relutil "helm.sh/helm/v4/pkg/release/v1/util"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// RetentionPolicy selects the revisions to delete from the history of a
+// release.
+//
+// Keep and KeepPerStatus limit the number of revisions, the revisions exceeding
+// a limit are deleted. When neither is set, all revisions are deleted. OlderThan
+// restricts the deletion to revisions older than a duration. The most recent
+// revision and the last deployed revision are never deleted.
+type RetentionPolicy struct {
+	// Keep is the number of most recent revisions to keep. Zero sets no limit.
+	Keep int
+	// KeepPerStatus is the number of most recent revisions of a status to
+	// keep, e.g. 2 failed revisions. Statuses without an entry have no limit.
+	KeepPerStatus map[common.Status]int
+	// OlderThan only deletes revisions deployed more than this long ago. Zero
+	// deletes revisions regardless of their age.
+	OlderThan time.Duration
+}
+
+// IsZero reports whether the policy has no rules.
+func (p RetentionPolicy) IsZero() bool {
+	return p.Keep == 0 && len(p.KeepPerStatus) == 0 && p.OlderThan == 0
+}
+
+// Validate checks that the policy has rules and that they are valid.
+func (p RetentionPolicy) Validate() error {
+	if p.IsZero() {
+		return errors.New("the retention policy has no rules")
+	}
+	if p.Keep < 0 {
+		return fmt.Errorf("invalid number of revisions to keep %d: must not be negative", p.Keep)
+	}
+	for status, n := range p.KeepPerStatus {
+		if n < 0 {
+			return fmt.Errorf("invalid number of %s revisions to keep %d: must not be negative", status, n)
+		}
+	}
+	if p.OlderThan < 0 {
+		return fmt.Errorf("invalid age %s: must not be negative", p.OlderThan)
+	}
+	return nil
+}
+
+// prunable returns the revisions of history the policy deletes. History must
+// be sorted from the most recent revision to the oldest one, deployed is the
+// version of the last deployed revision.
+func (p RetentionPolicy) prunable(history []*rspb.Release, deployed int, now time.Time) []*rspb.Release {
+	limited := p.Keep > 0 || len(p.KeepPerStatus) > 0
+	perStatus := map[common.Status]int{}
+
+	var prune []*rspb.Release
+	for i, rel := range history {
+		status := rel.Info.Status
+		perStatus[status]++
+
+		if i == 0 || rel.Version == deployed {
+			continue
+		}
+		limit, hasLimit := p.KeepPerStatus[status]
+		exceeds := !limited ||
+			(p.Keep > 0 && i >= p.Keep) ||
+			(hasLimit && perStatus[status] > limit)
+		if !exceeds {
+			continue
+		}
+		if p.OlderThan > 0 && now.Sub(revisionTime(rel)) < p.OlderThan {
+			continue
+		}
+		prune = append(prune, rel)
+	}
+	return prune
+}
+
+// revisionTime returns when a revision was deployed.
+func revisionTime(rel *rspb.Release) time.Time {
+	if rel.Info.LastDeployed.IsZero() {
+		return rel.Info.FirstDeployed
+	}
+	return rel.Info.LastDeployed
+}
+
+// Prune deletes the revisions of the named release that the retention policy
+// does not keep. It returns the deleted revisions, from the most recent to the
+// oldest one.
+func (s *Storage) Prune(name string, policy RetentionPolicy) ([]release.Releaser, error) {
+	return s.prune(name, policy, nil)
+}
+
+// prune deletes the revisions of the named release that the retention policy
+// does not keep, counting incoming, if set, as the most recent revision so
+// that room is made for it.
+func (s *Storage) prune(name string, policy RetentionPolicy, incoming *rspb.Release) ([]release.Releaser, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	h, err := s.History(name)
+	if err != nil && (incoming == nil || !errors.Is(err, driver.ErrReleaseNotFound)) {
+		return nil, err
+	}
+	rls, err := releaseListToV1List(h)
+	if err != nil {
+		return nil, err
+	}
+	relutil.Reverse(rls, relutil.SortByRevision)
+	if incoming != nil {
+		rls = append([]*rspb.Release{incoming}, rls...)
+	}
+
+	deployed := 0
+	lastDeployed, err := s.Deployed(name)
+	if err != nil && !errors.Is(err, driver.ErrNoDeployedReleases) {
+		return nil, err
+	}
+	if lastDeployed != nil {
+		ldac, err := release.NewAccessor(lastDeployed)
+		if err != nil {
+			return nil, err
+		}
+		deployed = ldac.Version()
+	}
+
+	var pruned []release.Releaser
+	for _, rel := range policy.prunable(rls, deployed, time.Now()) {
+		if rel == incoming {
+			continue
+		}
+		pruned = append(pruned, rel)
+	}
+	return pruned, s.deleteRevisions(name, pruned)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/release/common"
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func TestRetentionPolicyValidate(t *testing.T) {
+	assert.ErrorContains(t, RetentionPolicy{}.Validate(), "no rules")
+	assert.ErrorContains(t, RetentionPolicy{Keep: -1}.Validate(), "must not be negative")
+	assert.ErrorContains(t, RetentionPolicy{KeepPerStatus: map[common.Status]int{common.StatusFailed: -1}}.Validate(), "failed revisions")
+	assert.NoError(t, RetentionPolicy{Keep: 5, OlderThan: time.Hour}.Validate())
+}
+
+func TestRetentionPolicyPrunable(t *testing.T) {
+	now := time.Now()
+	// revisions from the most recent one, a day apart
+	statuses := []common.Status{
+		common.StatusFailed,
+		common.StatusDeployed,
+		common.StatusFailed,
+		common.StatusSuperseded,
+		common.StatusFailed,
+		common.StatusSuperseded,
+		common.StatusSuperseded,
+	}
+	var history []*rspb.Release
+	for i, status := range statuses {
+		rel := ReleaseTestData{Name: "angry-bird", Version: len(statuses) - i, Status: status}.ToRelease()
+		rel.Info.LastDeployed = now.Add(-time.Duration(i) * 24 * time.Hour)
+		history = append(history, rel)
+	}
+
+	versions := func(rls []*rspb.Release) []int {
+		var v []int
+		for _, rel := range rls {
+			v = append(v, rel.Version)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		expect []int
+	}{
+		{
+			name:   "keep",
+			policy: RetentionPolicy{Keep: 4},
+			expect: []int{3, 2, 1},
+		},
+		{
+			name:   "keep per status",
+			policy: RetentionPolicy{KeepPerStatus: map[common.Status]int{common.StatusFailed: 1}},
+			expect: []int{5, 3},
+		},
+		{
+			name:   "keep and keep per status",
+			policy: RetentionPolicy{Keep: 5, KeepPerStatus: map[common.Status]int{common.StatusSuperseded: 0}},
+			expect: []int{4, 2, 1},
+		},
+		{
+			name:   "older than",
+			policy: RetentionPolicy{OlderThan: 84 * time.Hour},
+			expect: []int{3, 2, 1},
+		},
+		{
+			name:   "keep and older than",
+			policy: RetentionPolicy{Keep: 2, OlderThan: 108 * time.Hour},
+			expect: []int{2, 1},
+		},
+		{
+			name:   "never the most recent and last deployed revisions",
+			policy: RetentionPolicy{KeepPerStatus: map[common.Status]int{common.StatusFailed: 0, common.StatusDeployed: 0}},
+			expect: []int{5, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, versions(tt.policy.prunable(history, 6, now)))
+		})
+	}
+}
+
+func TestStoragePrune(t *testing.T) {
+	storage := Init(driver.NewMemory())
+
+	const name = "angry-bird"
+	for v, status := range []common.Status{common.StatusSuperseded, common.StatusFailed, common.StatusSuperseded, common.StatusFailed, common.StatusDeployed} {
+		rls := ReleaseTestData{Name: name, Version: v + 1, Status: status}.ToRelease()
+		assertErrNil(t.Fatal, storage.Create(rls), "Storing release 'angry-bird'")
+	}
+
+	_, err := storage.Prune(name, RetentionPolicy{})
+	assert.ErrorContains(t, err, "no rules")
+
+	pruned, err := storage.Prune(name, RetentionPolicy{KeepPerStatus: map[common.Status]int{common.StatusFailed: 1}})
+	require.NoError(t, err)
+	require.Len(t, pruned, 1)
+	rls, err := releaseListToV1List(pruned)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rls[0].Version)
+
+	// the retention policy is applied when creating revisions
+	storage.Retention = RetentionPolicy{Keep: 2}
+	rls6 := ReleaseTestData{Name: name, Version: 6, Status: common.StatusDeployed}.ToRelease()
+	assertErrNil(t.Fatal, storage.Create(rls6), "Storing release 'angry-bird' (v6)")
+
+	assert.ElementsMatch(t, []int{5, 6}, historyVersions(t, storage, name))
+
+	// the limit of a status applies once a revision has it
+	storage.Retention = RetentionPolicy{KeepPerStatus: map[common.Status]int{common.StatusFailed: 1}}
+	for _, v := range []int{7, 8} {
+		rls := ReleaseTestData{Name: name, Version: v, Status: common.StatusPendingUpgrade}.ToRelease()
+		assertErrNil(t.Fatal, storage.Create(rls), "Storing release 'angry-bird'")
+		rls.Info.Status = common.StatusFailed
+		assertErrNil(t.Fatal, storage.Update(rls), "Updating release 'angry-bird'")
+	}
+	assert.ElementsMatch(t, []int{5, 6, 8}, historyVersions(t, storage, name))
+}
+
+func historyVersions(t *testing.T, storage *Storage, name string) []int {
+	t.Helper()
+	hist, err := storage.History(name)
+	require.NoError(t, err)
+	rhist, err := releaseListToV1List(hist)
+	require.NoError(t, err)
+	var versions []int
+	for _, rel := range rhist {
+		versions = append(versions, rel.Version)
+	}
+	return versions
+}
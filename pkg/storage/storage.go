@@ -17,10 +17,13 @@ limitations under the License.
 package storage // import "helm.sh/helm/v4/pkg/storage"
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
+	"time"
 
 	"helm.sh/helm/v4/internal/logging"
 	"helm.sh/helm/v4/pkg/release"
@@ -46,6 +49,21 @@ type Storage struct {
 	// ignored (meaning no limits are imposed).
 	MaxHistory int
 
+	// Retention deletes the revisions of a release its rules do not keep when
+	// a revision is created, after MaxHistory is applied and counting the new
+	// revision, and again once its status is no longer pending. Zero values
+	// delete no revisions.
+	Retention RetentionPolicy
+
+	// Locker locks releases during operations. Releases are not locked if it
+	// is nil.
+	Locker Locker
+
+	// held counts the operations of this process holding the lock of a
+	// release, such as an uninstall run by a failed install.
+	held   map[string]*heldLock
+	heldMu sync.Mutex
+
 	// Embed a LogHolder to provide logger functionality
 	logging.LogHolder
 }
@@ -74,6 +92,17 @@ func (s *Storage) Create(rls release.Releaser) error {
 			return err
 		}
 	}
+	if !s.Retention.IsZero() {
+		// Count the new revision, like MaxHistory makes space for it
+		rel, err := releaserToV1Release(rls)
+		if err != nil {
+			return err
+		}
+		if _, err := s.prune(rac.Name(), s.Retention, rel); err != nil &&
+			!errors.Is(err, driver.ErrReleaseNotFound) {
+			return err
+		}
+	}
 	return s.Driver.Create(makeKey(rac.Name(), rac.Version()), rls)
 }
 
@@ -86,7 +115,18 @@ func (s *Storage) Update(rls release.Releaser) error {
 		return err
 	}
 	s.Logger().Debug("updating release", "key", makeKey(rac.Name(), rac.Version()))
-	return s.Driver.Update(makeKey(rac.Name(), rac.Version()), rls)
+	if err := s.Driver.Update(makeKey(rac.Name(), rac.Version()), rls); err != nil {
+		return err
+	}
+	// The status of a revision is only known once it is no longer pending,
+	// apply Retention again so that it counts toward the limit of its status
+	if !s.Retention.IsZero() && !common.Status(rac.Status()).IsPending() {
+		if _, err := s.Prune(rac.Name(), s.Retention); err != nil &&
+			!errors.Is(err, driver.ErrReleaseNotFound) {
+			return err
+		}
+	}
+	return nil
 }
 
 // Delete deletes the release from storage. An error is returned if
@@ -263,10 +303,15 @@ func (s *Storage) removeLeastRecent(name string, maximum int) error {
 		}
 	}
 
+	return s.deleteRevisions(name, toDelete)
+}
+
+// deleteRevisions deletes revisions of the named release.
+func (s *Storage) deleteRevisions(name string, revisions []release.Releaser) error {
 	// Delete as many as possible. In the case of API throughput limitations,
 	// multiple invocations of this function will eventually delete them all.
 	errs := []error{}
-	for _, rel := range toDelete {
+	for _, rel := range revisions {
 		rac, err := release.NewAccessor(rel)
 		if err != nil {
 			errs = append(errs, err)
@@ -278,7 +323,7 @@ func (s *Storage) removeLeastRecent(name string, maximum int) error {
 		}
 	}
 
-	s.Logger().Debug("pruned records", "count", len(toDelete), "release", name, "errors", len(errs))
+	s.Logger().Debug("pruned records", "count", len(revisions), "release", name, "errors", len(errs))
 	switch c := len(errs); c {
 	case 0:
 		return nil
@@ -318,6 +363,65 @@ func (s *Storage) Last(name string) (release.Releaser, error) {
 	return rls[0], nil
 }
 
+// Lock acquires the lock of a release for an operation, waiting up to timeout
+// for another operation to release it. It returns a context derived from ctx,
+// which is canceled if the lock is lost, and a function releasing the lock.
+// Operations nested in an operation holding the lock, such as the rollback of
+// a failed upgrade, share its lock. Releases are not locked if the storage has
+// no Locker.
+func (s *Storage) Lock(ctx context.Context, name, operation string, timeout time.Duration) (context.Context, func(), error) {
+	if s.Locker == nil {
+		return ctx, func() {}, nil
+	}
+
+	s.heldMu.Lock()
+	defer s.heldMu.Unlock()
+	if h, ok := s.held[name]; ok {
+		h.count++
+		nestedCtx, cancel := context.WithCancelCause(ctx)
+		stop := context.AfterFunc(h.ctx, func() { cancel(context.Cause(h.ctx)) })
+		return nestedCtx, func() {
+			stop()
+			cancel(nil)
+			s.releaseLock(name)
+		}, nil
+	}
+
+	s.Logger().Debug("locking release", "name", name, "operation", operation)
+	lockCtx, unlock, err := s.Locker.Lock(ctx, name, operation, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.held == nil {
+		s.held = map[string]*heldLock{}
+	}
+	s.held[name] = &heldLock{count: 1, ctx: lockCtx, unlock: unlock}
+	return lockCtx, func() { s.releaseLock(name) }, nil
+}
+
+// heldLock is a release lock held by operations of this process.
+type heldLock struct {
+	count  int
+	ctx    context.Context
+	unlock func() error
+}
+
+func (s *Storage) releaseLock(name string) {
+	s.heldMu.Lock()
+	defer s.heldMu.Unlock()
+	h, ok := s.held[name]
+	if !ok {
+		return
+	}
+	if h.count--; h.count > 0 {
+		return
+	}
+	delete(s.held, name)
+	if err := h.unlock(); err != nil {
+		s.Logger().Warn("failed to unlock release", "name", name, slog.Any("error", err))
+	}
+}
+
 // makeKey concatenates the Kubernetes storage object type, a release name and version
 // into a string with format:```<helm_storage_type>.<release_name>.v<release_version>```.
 // The storage type is prepended to keep name uniqueness between different